@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"flow-run/internal/lib/logger"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// commands are maintenance and reporting tasks run instead of the server, as
+// "flowrun <command> [args...]".
+var commands = map[string]func(ctx context.Context, args []string) error{
+	"estimate":    estimate,
+	"migrate":     migrate,
+	"regression":  regression,
+	"rotate-keys": rotateKeys,
+	"sync-prices": syncPrices,
+}
+
+func runCommand(name string, args []string) {
+	command, ok := commands[name]
+	if !ok {
+		names := make([]string, 0, len(commands))
+		for n := range commands {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		logger.Log.Fatalf("Unknown command %q, available: %s", name, strings.Join(names, ", "))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := command(ctx, args); err != nil {
+		logger.Log.WithError(err).Errorf("Command %s failed", name)
+		stop()
+		os.Exit(1)
+	}
+}
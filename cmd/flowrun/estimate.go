@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"flow-run/pkg/flowrunclient"
+	"flow-run/pkg/flowrunclient/model"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/uuid"
+)
+
+// estimate prints what a run of a flow would cost, without running it:
+//
+//	flowrun estimate -account ID -flow ID -inputs '{"text": "..."}' \
+//	    -completion-tokens 512 -step-completion-tokens summarize=100,research.search=50
+func estimate(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("estimate", flag.ContinueOnError)
+	server := flags.String("server", serverURL(), "FlowRun server URL, defaults to $FLOWRUN_URL")
+	account := flags.String("account", "", "account ID")
+	flow := flags.String("flow", "", "flow ID")
+	inputs := flags.String("inputs", "{}", "flow inputs as a JSON object")
+	modelName := flags.String("model", "", "model every prompt step runs, defaults to the steps' own")
+	completionTokens := flags.Int("completion-tokens", 0,
+		"completion length of prompt steps without max_tokens, defaults to 256")
+	stepTokens := flags.String("step-completion-tokens", "",
+		"completion lengths of given steps, as comma-separated step=tokens")
+	format := flags.String("format", "text", "output format, json or text")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *format != "json" && *format != "text" {
+		return fmt.Errorf("unknown format %q, use json or text", *format) //nolint:err113
+	}
+
+	accountID, err := uuid.Parse(*account)
+	if err != nil {
+		return fmt.Errorf("invalid -account: %w", err)
+	}
+	flowID, err := uuid.Parse(*flow)
+	if err != nil {
+		return fmt.Errorf("invalid -flow: %w", err)
+	}
+	req := model.EstimateFlowRequest{Model: *modelName, CompletionTokens: *completionTokens}
+	if err := json.Unmarshal([]byte(*inputs), &req.Inputs); err != nil {
+		return fmt.Errorf("invalid -inputs: %w", err)
+	}
+	if req.StepCompletionTokens, err = parseStepTokens(*stepTokens); err != nil {
+		return fmt.Errorf("invalid -step-completion-tokens: %w", err)
+	}
+
+	result, err := flowrunclient.NewFlowRunClient(*server).EstimateFlow(ctx, accountID, flowID, req)
+	if err != nil {
+		return err
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+	return writeEstimate(os.Stdout, result)
+}
+
+func parseStepTokens(s string) (map[string]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	tokens := map[string]int{}
+	for _, pair := range strings.Split(s, ",") {
+		step, n, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q is not step=tokens", pair) //nolint:err113
+		}
+		count, err := strconv.Atoi(n)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", pair, err)
+		}
+		tokens[strings.TrimSpace(step)] = count
+	}
+	return tokens, nil
+}
+
+// writeEstimate prints a table of prompt steps, marking approximated token
+// counts with "~" and prompts counted from their template with "*".
+func writeEstimate(w io.Writer, e *model.FlowEstimateResponse) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Step\tModel\tPrompt\tCompletion\tCost\t")
+	for _, step := range e.Steps {
+		prompt := strconv.Itoa(step.Usage.PromptTokens)
+		if step.Encoding == "" {
+			prompt = "~" + prompt
+		}
+		if !step.Rendered {
+			prompt += "*"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t$%s\t\n",
+			step.StepID, step.Model, prompt, step.Usage.CompletionTokens, step.Cost)
+	}
+	fmt.Fprintf(tw, "Total\t\t%d\t%d\t$%s\t\n", e.Usage.PromptTokens, e.Usage.CompletionTokens, e.Cost)
+	return tw.Flush()
+}
@@ -16,6 +16,11 @@ const (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1], os.Args[2:])
+		return
+	}
+
 	fr, err := flowrun.NewFlowRun()
 	if err != nil {
 		logger.Log.WithError(err).Fatal("Failed to create FlowRun instance")
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"flow-run/internal/flowrun/config"
+	"flow-run/internal/flowrun/infra/database"
+	"flow-run/internal/lib/envelope"
+	"flow-run/internal/lib/logger"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+var errMigrateUsage = errors.New("usage: flowrun migrate up|down|status")
+
+// migrate manages the database schema, which the server refuses to start
+// against while migrations are pending:
+//
+//	flowrun migrate up               apply pending migrations
+//	flowrun migrate down [-steps N]  roll back the last N migrations, 1 by default
+//	flowrun migrate status           list migrations and when they were applied
+func migrate(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+
+	db, err := database.NewDatabase(config.DatabaseFromEnv())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Stop(ctx) }()
+
+	// Migrating a database AutoMigrate created seals the API keys it holds in
+	// plaintext, which takes the master keys.
+	var keyring *envelope.Keyring
+	if keyringConfig := config.KeyringFromEnv(); keyringConfig.MasterKeys != "" {
+		keyring, err = envelope.NewKeyring(keyringConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	migrator, err := database.NewMigrator(db, keyring)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			logger.Log.WithField("version", m.Version).WithField("name", m.Name).Info("Applied migration")
+		}
+		if err == nil && len(applied) == 0 {
+			logger.Log.Info("Schema is up to date")
+		}
+		return err
+	case "down":
+		flags := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+		steps := flags.Int("steps", 1, "number of migrations to roll back")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *steps < 1 {
+			return fmt.Errorf("invalid -steps %d, must be at least 1", *steps) //nolint:err113
+		}
+		rolledBack, err := migrator.Down(ctx, *steps)
+		for _, m := range rolledBack {
+			logger.Log.WithField("version", m.Version).WithField("name", m.Name).Info("Rolled back migration")
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return writeMigrationStatus(os.Stdout, statuses)
+	default:
+		return errMigrateUsage
+	}
+}
+
+func writeMigrationStatus(w io.Writer, statuses []database.MigrationStatus) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Version\tName\tApplied at\t")
+	for _, s := range statuses {
+		applied := "pending"
+		if s.AppliedAt != nil {
+			applied = s.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		switch {
+		case s.Changed:
+			applied += " (changed since applied)"
+		case s.Unknown:
+			applied += " (not in this build)"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t\n", s.Version, s.Name, applied)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"flow-run/pkg/flowrunclient"
+	"flow-run/pkg/flowrunclient/model"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	errRegressions       = errors.New("the candidate regressed")
+	errRegressionTargets = errors.New("pass -baseline-run and -candidate-run or -baseline-model and -candidate-model")
+)
+
+// regression compares two runs of a test suite and prints the report as JSON
+// or Markdown, e.g. to post it on a pull request. It either compares two
+// finished runs:
+//
+//	flowrun regression -account ID -suite ID -baseline-run ID -candidate-run ID
+//
+// or runs the suite for both sides first, waiting for both runs to finish:
+//
+//	flowrun regression -account ID -suite ID -baseline-model gpt-4o -candidate-model gpt-4o \
+//	    -baseline-revision 3 -candidate-revision 4 -format markdown
+//
+// With -fail-on-regression the command exits non-zero when a case that
+// passed in the baseline fails in the candidate.
+func regression(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("regression", flag.ContinueOnError)
+	server := flags.String("server", serverURL(), "FlowRun server URL, defaults to $FLOWRUN_URL")
+	account := flags.String("account", "", "account ID")
+	suite := flags.String("suite", "", "test suite ID")
+	baselineRun := flags.String("baseline-run", "", "ID of a finished baseline run")
+	candidateRun := flags.String("candidate-run", "", "ID of a finished candidate run")
+	var baseline, candidate model.RunTestSuiteRequest
+	targetFlags(flags, "baseline", &baseline)
+	targetFlags(flags, "candidate", &candidate)
+	datasetVersion := flags.Int("dataset-version", 0, "dataset version both sides run, defaults to the latest")
+	format := flags.String("format", "markdown", "output format, json or markdown")
+	failOnRegression := flags.Bool("fail-on-regression", false, "exit non-zero if any case regressed")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *format != "json" && *format != "markdown" {
+		return fmt.Errorf("unknown format %q, use json or markdown", *format) //nolint:err113
+	}
+
+	accountID, err := uuid.Parse(*account)
+	if err != nil {
+		return fmt.Errorf("invalid -account: %w", err)
+	}
+	suiteID, err := uuid.Parse(*suite)
+	if err != nil {
+		return fmt.Errorf("invalid -suite: %w", err)
+	}
+
+	client := flowrunclient.NewFlowRunClient(*server)
+	var report *model.RegressionReportResponse
+	switch {
+	case *baselineRun != "" && *candidateRun != "":
+		baselineRunID, err := uuid.Parse(*baselineRun)
+		if err != nil {
+			return fmt.Errorf("invalid -baseline-run: %w", err)
+		}
+		candidateRunID, err := uuid.Parse(*candidateRun)
+		if err != nil {
+			return fmt.Errorf("invalid -candidate-run: %w", err)
+		}
+		report, err = client.GetRegressionReport(ctx, accountID, suiteID, baselineRunID, candidateRunID)
+		if err != nil {
+			return err
+		}
+	case baseline.Model != "" && candidate.Model != "":
+		baseline.DatasetVersion, candidate.DatasetVersion = *datasetVersion, *datasetVersion
+		report, err = runRegression(ctx, client, accountID, suiteID, model.RunRegressionRequest{
+			Baseline:  baseline,
+			Candidate: candidate,
+		})
+		if err != nil {
+			return err
+		}
+	default:
+		return errRegressionTargets
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		err = writeRegressionMarkdown(os.Stdout, report)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *failOnRegression {
+		for _, c := range report.Flipped {
+			if c.Change == "regressed" {
+				return errRegressions
+			}
+		}
+	}
+	return nil
+}
+
+// runRegression queues runs of the suite for both sides of req, waits until
+// both have finished and compares them.
+func runRegression(
+	ctx context.Context, client flowrunclient.FlowRunClient, accountID, suiteID uuid.UUID, req model.RunRegressionRequest,
+) (*model.RegressionReportResponse, error) {
+	runs, err := client.RunRegression(ctx, accountID, suiteID, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range []model.TestSuiteRunResponse{runs.Baseline, runs.Candidate} {
+		if _, err := client.WaitTestSuiteRun(ctx, accountID, suiteID, run.ID); err != nil {
+			return nil, err
+		}
+	}
+	return client.GetRegressionReport(ctx, accountID, suiteID, runs.Baseline.ID, runs.Candidate.ID)
+}
+
+func targetFlags(flags *flag.FlagSet, side string, target *model.RunTestSuiteRequest) {
+	flags.StringVar(&target.Model, side+"-model", "", "model the "+side+" runs")
+	flags.IntVar(&target.Revision, side+"-revision", 0, "prompt revision the "+side+" runs")
+	flags.StringVar(&target.Label, side+"-label", "", "prompt label the "+side+" runs")
+}
+
+func serverURL() string {
+	if url := os.Getenv("FLOWRUN_URL"); url != "" {
+		return url
+	}
+	return "http://localhost:8080"
+}
+
+func writeRegressionMarkdown(w io.Writer, r *model.RegressionReportResponse) error {
+	var b strings.Builder
+	b.WriteString("## Regression report\n\n")
+	b.WriteString("| | Baseline | Candidate | Delta |\n|---|---|---|---|\n")
+	fmt.Fprintf(&b, "| Run | %s | %s | |\n", r.Baseline.RunID, r.Candidate.RunID)
+	fmt.Fprintf(&b, "| Model | %s | %s | |\n", r.Baseline.Model, r.Candidate.Model)
+	if r.Baseline.PromptRevision != 0 || r.Candidate.PromptRevision != 0 {
+		fmt.Fprintf(&b, "| Prompt revision | %d | %d | |\n", r.Baseline.PromptRevision, r.Candidate.PromptRevision)
+	}
+	fmt.Fprintf(&b, "| Passed | %d/%d | %d/%d | %+d |\n",
+		r.Baseline.Passed, r.Baseline.Cases, r.Candidate.Passed, r.Candidate.Cases, r.Delta.Passed)
+	fmt.Fprintf(&b, "| Pass rate | %.1f%% | %.1f%% | %+.1f%% |\n",
+		100*r.Baseline.PassRate, 100*r.Candidate.PassRate, 100*r.Delta.PassRate)
+	fmt.Fprintf(&b, "| Score | %.3f | %.3f | %+.3f |\n", r.Baseline.Score, r.Candidate.Score, r.Delta.Score)
+	fmt.Fprintf(&b, "| Tokens | %d | %d | %+d |\n",
+		r.Baseline.Usage.TotalTokens, r.Candidate.Usage.TotalTokens, r.Delta.Tokens)
+	fmt.Fprintf(&b, "| Cost | $%s | $%s | %s |\n", r.Baseline.Cost, r.Candidate.Cost, signed(r.Delta.Cost))
+	fmt.Fprintf(&b, "| Mean latency | %d ms | %d ms | %+d ms |\n",
+		r.Baseline.MeanLatencyMS, r.Candidate.MeanLatencyMS, r.Delta.MeanLatencyMS)
+
+	if len(r.Flipped) == 0 {
+		b.WriteString("\nNo case flipped.\n")
+	} else {
+		b.WriteString("\n### Flipped cases\n\n| Case | Change | Candidate failures |\n|---|---|---|\n")
+		for _, c := range r.Flipped {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", markdownCell(c.Name), c.Change, markdownCell(failures(c.Candidate)))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// signed prefixes non-negative dollar amounts with "+", matching the sign
+// Money prints for negative ones.
+func signed(cost string) string {
+	if strings.HasPrefix(cost, "-") {
+		return "-$" + cost[1:]
+	}
+	return "+$" + cost
+}
+
+func failures(outcome *model.CaseOutcome) string {
+	switch {
+	case outcome == nil:
+		return ""
+	case outcome.Error != "":
+		return outcome.Error
+	default:
+		return strings.Join(outcome.Failures, "; ")
+	}
+}
+
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"flow-run/internal/flowrun/config"
+	"flow-run/internal/flowrun/infra/database"
+	"flow-run/internal/lib/envelope"
+	"flow-run/internal/lib/logger"
+)
+
+// rotateKeys re-encrypts provider API keys with the first master key in
+// ENCRYPTION_MASTER_KEYS. Rotate by prepending a new "version:key" entry,
+// running this command, then removing the old entry.
+func rotateKeys(ctx context.Context, _ []string) error {
+	cfg, err := config.FromEnv()
+	if err != nil {
+		return err
+	}
+
+	keyring, err := envelope.NewKeyring(cfg.KeyringConfig)
+	if err != nil {
+		return err
+	}
+
+	db, err := database.NewDatabase(cfg.DatabaseConfig)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Stop(ctx) }()
+
+	rotated, err := database.NewProviderRepository(db, keyring).RotateKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	logger.Log.
+		WithField("providers", rotated).
+		WithField("key_version", keyring.ActiveVersion()).
+		Info("Re-encrypted provider API keys")
+	return nil
+}
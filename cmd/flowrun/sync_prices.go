@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"flow-run/internal/core/pricing"
+	"flow-run/internal/flowrun/config"
+	"flow-run/internal/flowrun/infra/database"
+	"flow-run/internal/flowrun/infra/llm"
+	"flow-run/internal/lib/envelope"
+	"flow-run/internal/lib/logger"
+)
+
+// syncPrices syncs the prices and context windows of models served by
+// OpenRouter providers once, as the server does every PRICE_SYNC_INTERVAL.
+func syncPrices(ctx context.Context, _ []string) error {
+	cfg, err := config.FromEnv()
+	if err != nil {
+		return err
+	}
+
+	keyring, err := envelope.NewKeyring(cfg.KeyringConfig)
+	if err != nil {
+		return err
+	}
+
+	db, err := database.NewDatabase(cfg.DatabaseConfig)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Stop(ctx) }()
+
+	syncer := pricing.NewSyncer(
+		database.NewProviderRepository(db, keyring), database.NewModelRepository(db), llm.NewFactory(keyring), 0,
+	)
+	result, err := syncer.Sync(ctx)
+	logger.Log.
+		WithField("models", result.Models).
+		WithField("repriced", result.Repriced).
+		Info("Synced model prices")
+	return err
+}
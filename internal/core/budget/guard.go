@@ -0,0 +1,146 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"flow-run/internal/lib/logger"
+	"time"
+)
+
+// ReservationTTL is how long a call may hold its reservations. It is well
+// above the timeout of a provider call; the engine cuts off calls that reach
+// it all the same, so that no call runs on once its reservations expired.
+const ReservationTTL = 10 * time.Minute
+
+// Guard enforces budgets. Before a call it reserves the call's estimated
+// cost against every budget that applies, so concurrent calls cannot
+// overshoot a hard limit together; after the call it settles the
+// reservation, the ledger having taken over, and emits the events of limits
+// the call crossed.
+type Guard struct {
+	budgets port.BudgetRepository
+	now     func() time.Time
+}
+
+func NewGuard(budgets port.BudgetRepository) *Guard {
+	return &Guard{budgets: budgets, now: time.Now}
+}
+
+// Start settles the reservations that expired, left by processes that
+// stopped mid-call. Those of calls still in flight, in any process, are left
+// alone.
+func (g *Guard) Start(ctx context.Context) error {
+	settled, err := g.budgets.SettleExpired(ctx, g.now())
+	if err != nil {
+		return err
+	}
+	if settled > 0 {
+		logger.Log.WithField("reservations", settled).Warn("Settled budget reservations of interrupted calls")
+	}
+	return nil
+}
+
+// CheckRun rejects a run once a hard limit that applies to it is reached.
+// Only limits are checked here; what the run's calls may spend is settled
+// call by call.
+func (g *Guard) CheckRun(ctx context.Context, target domain.BudgetTarget) error {
+	budgets, err := g.budgets.ListApplicable(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	now := g.now()
+	for _, b := range budgets {
+		if b.HardLimit == 0 {
+			continue
+		}
+		spent, err := g.budgets.Spent(ctx, b, now)
+		if err != nil {
+			return err
+		}
+		if !b.Allows(spent, 0) {
+			return g.reject(ctx, &domain.BudgetExceededError{Budget: b, Spent: spent})
+		}
+	}
+	return nil
+}
+
+// Reserve holds amount of every budget that applies to target for
+// ReservationTTL. It returns a nil hold when none applies.
+func (g *Guard) Reserve(
+	ctx context.Context, target domain.BudgetTarget, amount domain.Money,
+) (*domain.BudgetHold, error) {
+	budgets, err := g.budgets.ListApplicable(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if len(budgets) == 0 {
+		return nil, nil
+	}
+
+	now := g.now()
+	hold := domain.NewBudgetHold(budgets, amount, now, now.Add(ReservationTTL))
+	if err := g.budgets.Reserve(ctx, hold, now); err != nil {
+		var exceeded *domain.BudgetExceededError
+		if errors.As(err, &exceeded) {
+			return nil, g.reject(ctx, exceeded)
+		}
+		return nil, err
+	}
+	return hold, nil
+}
+
+// Settle releases hold and emits a soft limit event for each of its budgets
+// that has now spent its soft limit.
+func (g *Guard) Settle(ctx context.Context, hold *domain.BudgetHold) error {
+	if hold == nil {
+		return nil
+	}
+	if err := g.budgets.Settle(ctx, hold); err != nil {
+		return err
+	}
+
+	now := g.now()
+	for _, b := range hold.Budgets {
+		if b.SoftLimit == 0 {
+			continue
+		}
+		spent, err := g.budgets.Spent(ctx, b, now)
+		if err != nil {
+			return err
+		}
+		if spent >= b.SoftLimit {
+			if err := g.emit(ctx, domain.NewBudgetEvent(b, domain.BudgetEventSoftLimitCrossed, spent, now)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reject emits the hard limit event of a rejection and returns it. Failing
+// to record the event does not hide the rejection.
+func (g *Guard) reject(ctx context.Context, exceeded *domain.BudgetExceededError) error {
+	event := domain.NewBudgetEvent(exceeded.Budget, domain.BudgetEventHardLimitReached, exceeded.Spent, g.now())
+	if err := g.emit(ctx, event); err != nil {
+		logger.Log.WithError(err).Warn("Failed to record budget event")
+	}
+	return exceeded
+}
+
+// emit stores e and logs it the first time it happens in its period.
+func (g *Guard) emit(ctx context.Context, e *domain.BudgetEvent) error {
+	added, err := g.budgets.AddEvent(ctx, e)
+	if err != nil || !added {
+		return err
+	}
+	logger.Log.
+		WithField("budget_id", e.BudgetID).
+		WithField("account_id", e.AccountID).
+		WithField("spent", e.Spent.String()).
+		WithField("limit", e.Limit.String()).
+		Warnf("Budget event %s", e.Type)
+	return nil
+}
@@ -0,0 +1,225 @@
+package budget
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReserve(t *testing.T) {
+	t.Parallel()
+
+	accountID := uuid.New()
+	b := newBudget(t, accountID, 50, 100)
+	repo := &fakeBudgets{budgets: []*domain.Budget{b}, spent: map[uuid.UUID]domain.Money{b.ID: 40}}
+	guard := newGuard(repo)
+	target := domain.BudgetTarget{AccountID: accountID}
+
+	hold, err := guard.Reserve(context.Background(), target, 30)
+	require.NoError(t, err)
+	require.Len(t, hold.Reservations, 1)
+	assert.Equal(t, domain.Money(30), hold.Reservations[0].Amount)
+	assert.Equal(t, domain.Money(70), repo.spentBy(b.ID))
+
+	// The call crossed the soft limit once settled; a second settle in the
+	// same period emits nothing new.
+	repo.record(b.ID, 20)
+	require.NoError(t, guard.Settle(context.Background(), hold))
+	assert.Equal(t, domain.Money(60), repo.spentBy(b.ID))
+	require.NoError(t, guard.Settle(context.Background(), &domain.BudgetHold{Budgets: []*domain.Budget{b}}))
+	require.Len(t, repo.events, 1)
+	assert.Equal(t, domain.BudgetEventSoftLimitCrossed, repo.events[0].Type)
+	assert.Equal(t, domain.Money(60), repo.events[0].Spent)
+	assert.Equal(t, domain.Money(50), repo.events[0].Limit)
+
+	_, err = guard.Reserve(context.Background(), target, 41)
+	require.ErrorIs(t, err, domain.ErrBudgetExceeded)
+	assert.Equal(t, domain.Money(60), repo.spentBy(b.ID))
+	require.Len(t, repo.events, 2)
+	assert.Equal(t, domain.BudgetEventHardLimitReached, repo.events[1].Type)
+}
+
+func TestReserveWithoutBudgets(t *testing.T) {
+	t.Parallel()
+
+	guard := newGuard(&fakeBudgets{})
+
+	hold, err := guard.Reserve(context.Background(), domain.BudgetTarget{AccountID: uuid.New()}, 30)
+	require.NoError(t, err)
+	assert.Nil(t, hold)
+	require.NoError(t, guard.Settle(context.Background(), hold))
+}
+
+func TestCheckRun(t *testing.T) {
+	t.Parallel()
+
+	accountID := uuid.New()
+	softOnly := newBudget(t, accountID, 10, 0)
+	hard := newBudget(t, accountID, 0, 100)
+	repo := &fakeBudgets{
+		budgets: []*domain.Budget{softOnly, hard},
+		spent:   map[uuid.UUID]domain.Money{softOnly.ID: 500, hard.ID: 99},
+	}
+	guard := newGuard(repo)
+	target := domain.BudgetTarget{AccountID: accountID}
+
+	require.NoError(t, guard.CheckRun(context.Background(), target))
+
+	repo.record(hard.ID, 1)
+	err := guard.CheckRun(context.Background(), target)
+	require.ErrorIs(t, err, domain.ErrBudgetExceeded)
+	require.Len(t, repo.events, 1)
+	assert.Equal(t, hard.ID, repo.events[0].BudgetID)
+}
+
+func TestStart(t *testing.T) {
+	t.Parallel()
+
+	accountID := uuid.New()
+	b := newBudget(t, accountID, 0, 100)
+	repo := &fakeBudgets{budgets: []*domain.Budget{b}, spent: map[uuid.UUID]domain.Money{}}
+	guard := newGuard(repo)
+
+	target := domain.BudgetTarget{AccountID: accountID}
+	_, err := guard.Reserve(context.Background(), target, 30)
+	require.NoError(t, err)
+	later := guard.now().Add(ReservationTTL)
+	guard.now = func() time.Time { return later }
+	_, err = guard.Reserve(context.Background(), target, 20)
+	require.NoError(t, err)
+	require.NoError(t, guard.Start(context.Background()))
+
+	// The call of the second reservation may be in flight in another process.
+	assert.Equal(t, domain.Money(20), repo.spentBy(b.ID))
+}
+
+func newGuard(repo *fakeBudgets) *Guard {
+	g := NewGuard(repo)
+	g.now = func() time.Time { return time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC) }
+	return g
+}
+
+func newBudget(t *testing.T, accountID uuid.UUID, soft, hard domain.Money) *domain.Budget {
+	t.Helper()
+
+	b, err := domain.NewBudget(
+		domain.WithBudgetID(uuid.New()),
+		domain.WithBudgetAccountID(accountID),
+		domain.WithBudgetName("monthly"),
+		domain.WithBudgetScope(domain.BudgetScopeAccount, nil),
+		domain.WithBudgetPeriod(domain.BudgetPeriodMonthly),
+		domain.WithBudgetLimits(soft, hard),
+	)
+	require.NoError(t, err)
+	return b
+}
+
+// fakeBudgets keeps the recorded spend of each budget and its reservations
+// apart, as the ledger and the reservation table do.
+type fakeBudgets struct {
+	budgets []*domain.Budget
+
+	mu           sync.Mutex
+	spent        map[uuid.UUID]domain.Money
+	reservations map[uuid.UUID]*domain.BudgetReservation
+	events       []*domain.BudgetEvent
+}
+
+func (f *fakeBudgets) ListApplicable(_ context.Context, target domain.BudgetTarget) ([]*domain.Budget, error) {
+	var budgets []*domain.Budget
+	for _, b := range f.budgets {
+		if b.Applies(target) {
+			budgets = append(budgets, b)
+		}
+	}
+	return budgets, nil
+}
+
+func (f *fakeBudgets) Spent(_ context.Context, b *domain.Budget, _ time.Time) (domain.Money, error) {
+	return f.spentBy(b.ID), nil
+}
+
+func (f *fakeBudgets) Reserve(_ context.Context, hold *domain.BudgetHold, _ time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, b := range hold.Budgets {
+		spent := f.spentLocked(b.ID)
+		if amount := hold.Reservations[i].Amount; !b.Allows(spent, amount) {
+			return &domain.BudgetExceededError{Budget: b, Spent: spent, Amount: amount}
+		}
+	}
+	if f.reservations == nil {
+		f.reservations = map[uuid.UUID]*domain.BudgetReservation{}
+	}
+	for _, r := range hold.Reservations {
+		f.reservations[r.ID] = r
+	}
+	return nil
+}
+
+func (f *fakeBudgets) Settle(_ context.Context, hold *domain.BudgetHold) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, r := range hold.Reservations {
+		delete(f.reservations, r.ID)
+	}
+	return nil
+}
+
+func (f *fakeBudgets) SettleExpired(_ context.Context, now time.Time) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := len(f.reservations)
+	maps.DeleteFunc(f.reservations, func(_ uuid.UUID, r *domain.BudgetReservation) bool {
+		return !r.ExpiresAt.After(now)
+	})
+	return n - len(f.reservations), nil
+}
+
+func (f *fakeBudgets) AddEvent(_ context.Context, e *domain.BudgetEvent) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, existing := range f.events {
+		if existing.BudgetID == e.BudgetID && existing.Type == e.Type && existing.PeriodStart.Equal(e.PeriodStart) {
+			return false, nil
+		}
+	}
+	f.events = append(f.events, e)
+	return true, nil
+}
+
+// record adds amount to the ledger of a budget.
+func (f *fakeBudgets) record(budgetID uuid.UUID, amount domain.Money) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.spent[budgetID] += amount
+}
+
+func (f *fakeBudgets) spentBy(budgetID uuid.UUID) domain.Money {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.spentLocked(budgetID)
+}
+
+func (f *fakeBudgets) spentLocked(budgetID uuid.UUID) domain.Money {
+	spent := f.spent[budgetID]
+	for _, r := range f.reservations {
+		if r.BudgetID == budgetID {
+			spent += r.Amount
+		}
+	}
+	return spent
+}
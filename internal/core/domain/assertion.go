@@ -0,0 +1,335 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+var (
+	ErrInvalidAssertion = errors.New("invalid assertion")
+	ErrOutputNotJSON    = errors.New("output is not JSON")
+)
+
+type AssertionType string
+
+const (
+	AssertionTypeEquals      = AssertionType("equals")
+	AssertionTypeContains    = AssertionType("contains")
+	AssertionTypeNotContains = AssertionType("not_contains")
+	AssertionTypeRegex       = AssertionType("regex")
+	AssertionTypeJSONSchema  = AssertionType("json_schema")
+	AssertionTypeJSONPath    = AssertionType("json_path")
+	AssertionTypeMaxTokens   = AssertionType("max_tokens")
+	AssertionTypeMaxLatency  = AssertionType("max_latency")
+	AssertionTypeMaxCost     = AssertionType("max_cost")
+	AssertionTypeJudge       = AssertionType("judge")
+)
+
+// Assertion checks one property of a test case's output. Value holds the
+// operand of every type but json_schema and json_path:
+//
+//   - equals, contains, not_contains: text compared with the output;
+//   - regex: a Go regular expression the output must match;
+//   - max_tokens: the most tokens, prompt and completion, the case may use;
+//   - max_latency: the longest the case may take, e.g. "2s" or "500ms";
+//   - max_cost: the most the case may cost in dollars, e.g. "0.01".
+//
+// json_schema validates the output, parsed as JSON, against Schema.
+// json_path reads Path (e.g. "$.items[0].name") from the parsed output and
+// compares it with Expected. Text and json_path assertions without an
+// operand compare with the expected output of their case.
+//
+// judge asks the judge Model to grade the output against Rubric. The judge
+// answers with a score between 0 and 1 or a pass/fail verdict, which counts
+// as 1 or 0; the assertion passes when the score reaches Threshold, 0.5 when
+// unset.
+type Assertion struct {
+	Type      AssertionType   `json:"type" validate:"oneof=equals contains not_contains regex json_schema json_path max_tokens max_latency max_cost judge"` //nolint:lll
+	Value     string          `json:"value,omitempty"`
+	Path      string          `json:"path,omitempty"`
+	Expected  any             `json:"expected,omitempty"`
+	Schema    json.RawMessage `json:"schema,omitempty"`
+	Model     string          `json:"model,omitempty"`
+	Rubric    string          `json:"rubric,omitempty"`
+	Threshold float64         `json:"threshold,omitempty" validate:"min=0,max=1"`
+}
+
+// CaseOutput is what a test case produced, as seen by assertions. Output is
+// the completion text of a prompt, or the outputs of a flow. Judgements
+// holds the grading of the case's judge assertions, by assertion index.
+type CaseOutput struct {
+	Output     any
+	Usage      TokenUsage
+	Latency    time.Duration
+	Cost       Money
+	Judgements map[int]*Judgement
+}
+
+// AssertionResult is the outcome of one assertion. Judge assertions also
+// record the judge's score and reasoning.
+type AssertionResult struct {
+	Type      AssertionType `json:"type"`
+	Passed    bool          `json:"passed"`
+	Message   string        `json:"message,omitempty"`
+	Score     *float64      `json:"score,omitempty"`
+	Reasoning string        `json:"reasoning,omitempty"`
+}
+
+// check validates the operands of the assertion for its type. expected
+// tells whether the case may supply the operand of text and json_path
+// assertions with its expected output.
+func (a *Assertion) check(c *fieldChecker, field string, expected bool) {
+	var err error
+	switch a.Type {
+	case AssertionTypeEquals, AssertionTypeContains, AssertionTypeNotContains:
+		if a.Value == "" && !expected {
+			c.fail(field+".value", "required", "%s needs a value", a.Type)
+		}
+		return
+	case AssertionTypeRegex:
+		_, err = regexp.Compile(a.Value)
+	case AssertionTypeJSONSchema:
+		_, err = compileJSONSchema(a.Schema)
+		if err != nil {
+			c.fail(field+".schema", "json_schema", "%s", err)
+		}
+		return
+	case AssertionTypeJSONPath:
+		if _, err := ParseJSONPath(a.Path); err != nil {
+			c.fail(field+".path", "json_path", "%s", err)
+		}
+		if a.Expected == nil && !expected {
+			c.fail(field+".expected", "required", "json_path needs an expected value")
+		}
+		return
+	case AssertionTypeMaxTokens:
+		_, err = parseMaxTokens(a.Value)
+	case AssertionTypeMaxLatency:
+		_, err = parseMaxLatency(a.Value)
+	case AssertionTypeMaxCost:
+		_, err = parseMaxCost(a.Value)
+	case AssertionTypeJudge:
+		if a.Model == "" {
+			c.fail(field+".model", "required", "judge needs a model")
+		}
+		if strings.TrimSpace(a.Rubric) == "" {
+			c.fail(field+".rubric", "required", "judge needs a rubric")
+		}
+		return
+	}
+	if err != nil {
+		c.fail(field+".value", string(a.Type), "%s", err)
+	}
+}
+
+// Evaluate checks out against the assertion. Text assertions compare the
+// output with surrounding whitespace trimmed; outputs that are not strings
+// are compared as JSON.
+func (a *Assertion) Evaluate(out *CaseOutput) AssertionResult {
+	passed, message := a.evaluate(out)
+	return AssertionResult{Type: a.Type, Passed: passed, Message: message}
+}
+
+func (a *Assertion) evaluate(out *CaseOutput) (bool, string) {
+	text := strings.TrimSpace(outputText(out.Output))
+
+	switch a.Type {
+	case AssertionTypeEquals, AssertionTypeContains, AssertionTypeNotContains:
+		if a.Value == "" {
+			return false, "the case has no expected output to compare with"
+		}
+	case AssertionTypeJSONPath:
+		if a.Expected == nil {
+			return false, "the case has no expected output to compare with"
+		}
+	}
+
+	switch a.Type {
+	case AssertionTypeEquals:
+		if text != strings.TrimSpace(a.Value) {
+			return false, fmt.Sprintf("expected %q, got %q", a.Value, text)
+		}
+	case AssertionTypeContains:
+		if !strings.Contains(text, a.Value) {
+			return false, fmt.Sprintf("output does not contain %q", a.Value)
+		}
+	case AssertionTypeNotContains:
+		if strings.Contains(text, a.Value) {
+			return false, fmt.Sprintf("output contains %q", a.Value)
+		}
+	case AssertionTypeRegex:
+		re, err := regexp.Compile(a.Value)
+		if err != nil {
+			return false, err.Error()
+		}
+		if !re.MatchString(text) {
+			return false, fmt.Sprintf("output does not match %q", a.Value)
+		}
+	case AssertionTypeJSONSchema:
+		return evaluateJSONSchema(a.Schema, out.Output)
+	case AssertionTypeJSONPath:
+		return evaluateJSONPath(a.Path, a.Expected, out.Output)
+	case AssertionTypeMaxTokens:
+		limit, err := parseMaxTokens(a.Value)
+		if err != nil {
+			return false, err.Error()
+		}
+		if out.Usage.TotalTokens > limit {
+			return false, fmt.Sprintf("used %d tokens, more than %d", out.Usage.TotalTokens, limit)
+		}
+	case AssertionTypeMaxLatency:
+		limit, err := parseMaxLatency(a.Value)
+		if err != nil {
+			return false, err.Error()
+		}
+		if out.Latency > limit {
+			return false, fmt.Sprintf("took %s, longer than %s", out.Latency.Round(time.Millisecond), limit)
+		}
+	case AssertionTypeMaxCost:
+		limit, err := parseMaxCost(a.Value)
+		if err != nil {
+			return false, err.Error()
+		}
+		if out.Cost > limit {
+			return false, fmt.Sprintf("cost $%s, more than $%s", out.Cost, limit)
+		}
+	case AssertionTypeJudge:
+		return false, "judge assertions are checked with Grade"
+	default:
+		return false, fmt.Sprintf("unsupported assertion type %q", a.Type)
+	}
+	return true, ""
+}
+
+func evaluateJSONSchema(schema json.RawMessage, output any) (bool, string) {
+	compiled, err := compileJSONSchema(schema)
+	if err != nil {
+		return false, err.Error()
+	}
+	document, err := outputJSON(output)
+	if err != nil {
+		return false, err.Error()
+	}
+	if err := compiled.Validate(document); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+func evaluateJSONPath(path string, expected, output any) (bool, string) {
+	parsed, err := ParseJSONPath(path)
+	if err != nil {
+		return false, err.Error()
+	}
+	document, err := outputJSON(output)
+	if err != nil {
+		return false, err.Error()
+	}
+
+	actual, found := parsed.Lookup(document)
+	if !found {
+		return false, fmt.Sprintf("%s not found in output", path)
+	}
+	// Round-tripping the expected value gives both sides the types
+	// encoding/json decodes to, e.g. float64 for every number. Expected is
+	// itself decoded JSON, so this cannot fail.
+	var want any
+	encoded, _ := json.Marshal(expected)
+	_ = json.Unmarshal(encoded, &want)
+	if !reflect.DeepEqual(actual, want) {
+		return false, fmt.Sprintf("%s is %s, expected %s", path, outputText(actual), outputText(want))
+	}
+	return true, ""
+}
+
+// outputText renders an output for text comparison: strings as they are,
+// anything else as JSON.
+func outputText(output any) string {
+	if text, ok := output.(string); ok {
+		return text
+	}
+	// Outputs are decoded JSON values, which always marshal.
+	encoded, _ := json.Marshal(output)
+	return string(encoded)
+}
+
+// outputJSON returns output as a decoded JSON document. Strings are parsed,
+// after stripping a Markdown code fence models like to wrap JSON in.
+func outputJSON(output any) (any, error) {
+	text, ok := output.(string)
+	if !ok {
+		text = outputText(output)
+	}
+	text = stripCodeFence(strings.TrimSpace(text))
+
+	var document any
+	if err := json.Unmarshal([]byte(text), &document); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOutputNotJSON, err)
+	}
+	return document, nil
+}
+
+func stripCodeFence(text string) string {
+	body, ok := strings.CutPrefix(text, "```")
+	if !ok {
+		return text
+	}
+	body, ok = strings.CutSuffix(body, "```")
+	if !ok {
+		return text
+	}
+	// Drop the info string of the opening fence, e.g. "json".
+	if _, rest, found := strings.Cut(body, "\n"); found {
+		body = rest
+	}
+	return strings.TrimSpace(body)
+}
+
+func compileJSONSchema(schema json.RawMessage) (*jsonschema.Schema, error) {
+	if len(schema) == 0 {
+		return nil, fmt.Errorf("%w: json_schema needs a schema", ErrInvalidAssertion)
+	}
+	document, err := jsonschema.UnmarshalJSON(bytes.NewReader(schema))
+	if err != nil {
+		return nil, fmt.Errorf("%w: schema is not JSON: %w", ErrInvalidAssertion, err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("assertion.json", document); err != nil {
+		return nil, err
+	}
+	return compiler.Compile("assertion.json")
+}
+
+func parseMaxTokens(value string) (int, error) {
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 {
+		return 0, fmt.Errorf("%w: max_tokens must be a positive integer, got %q", ErrInvalidAssertion, value)
+	}
+	return limit, nil
+}
+
+func parseMaxLatency(value string) (time.Duration, error) {
+	limit, err := time.ParseDuration(value)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("%w: max_latency must be a positive duration such as 2s, got %q", ErrInvalidAssertion, value)
+	}
+	return limit, nil
+}
+
+func parseMaxCost(value string) (Money, error) {
+	limit, err := ParseMoney(value)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("%w: max_cost must be a positive dollar amount such as 0.01, got %q", ErrInvalidAssertion, value)
+	}
+	return limit, nil
+}
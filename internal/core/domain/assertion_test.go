@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssertionEvaluate(t *testing.T) {
+	t.Parallel()
+
+	schema := json.RawMessage(`{"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}}`)
+	text := &CaseOutput{Output: "  Paris is the capital.\n"}
+	fenced := &CaseOutput{Output: "```json\n{\"name\": \"Ada\", \"tags\": [\"x\", 2]}\n```"}
+	object := &CaseOutput{Output: map[string]any{"name": 1.0}}
+	measured := &CaseOutput{
+		Output:  "ok",
+		Usage:   TokenUsage{PromptTokens: 80, CompletionTokens: 20, TotalTokens: 100},
+		Latency: 1500 * time.Millisecond,
+		Cost:    Money(10_000_000),
+	}
+
+	tests := []struct {
+		a      Assertion
+		out    *CaseOutput
+		passed bool
+	}{
+		{a: Assertion{Type: AssertionTypeEquals, Value: "Paris is the capital."}, out: text, passed: true},
+		{a: Assertion{Type: AssertionTypeEquals, Value: "Paris"}, out: text, passed: false},
+		{a: Assertion{Type: AssertionTypeEquals, Value: `{"name":1}`}, out: object, passed: true},
+		{a: Assertion{Type: AssertionTypeContains, Value: "capital"}, out: text, passed: true},
+		{a: Assertion{Type: AssertionTypeContains, Value: "Rome"}, out: text, passed: false},
+		{a: Assertion{Type: AssertionTypeNotContains, Value: "Rome"}, out: text, passed: true},
+		{a: Assertion{Type: AssertionTypeNotContains, Value: "Paris"}, out: text, passed: false},
+		{a: Assertion{Type: AssertionTypeRegex, Value: `^Paris\b`}, out: text, passed: true},
+		{a: Assertion{Type: AssertionTypeRegex, Value: `^Rome`}, out: text, passed: false},
+		{a: Assertion{Type: AssertionTypeJSONSchema, Schema: schema}, out: fenced, passed: true},
+		{a: Assertion{Type: AssertionTypeJSONSchema, Schema: schema}, out: object, passed: false},
+		{a: Assertion{Type: AssertionTypeJSONSchema, Schema: schema}, out: text, passed: false},
+		{a: Assertion{Type: AssertionTypeJSONPath, Path: "$.tags[1]", Expected: 2}, out: fenced, passed: true},
+		{a: Assertion{Type: AssertionTypeJSONPath, Path: "$.name", Expected: "Ada"}, out: fenced, passed: true},
+		{a: Assertion{Type: AssertionTypeJSONPath, Path: "$.name", Expected: "Bob"}, out: fenced, passed: false},
+		{a: Assertion{Type: AssertionTypeJSONPath, Path: "$.age", Expected: 1}, out: fenced, passed: false},
+		{a: Assertion{Type: AssertionTypeMaxTokens, Value: "100"}, out: measured, passed: true},
+		{a: Assertion{Type: AssertionTypeMaxTokens, Value: "99"}, out: measured, passed: false},
+		{a: Assertion{Type: AssertionTypeMaxLatency, Value: "2s"}, out: measured, passed: true},
+		{a: Assertion{Type: AssertionTypeMaxLatency, Value: "1s"}, out: measured, passed: false},
+		{a: Assertion{Type: AssertionTypeMaxCost, Value: "0.01"}, out: measured, passed: true},
+		{a: Assertion{Type: AssertionTypeMaxCost, Value: "0.005"}, out: measured, passed: false},
+	}
+
+	for _, tt := range tests {
+		result := tt.a.Evaluate(tt.out)
+		assert.Equal(t, tt.a.Type, result.Type)
+		assert.Equal(t, tt.passed, result.Passed, "%+v: %s", tt.a, result.Message)
+		if !tt.passed {
+			assert.NotEmpty(t, result.Message, "%+v", tt.a)
+		}
+	}
+}
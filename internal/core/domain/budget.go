@@ -0,0 +1,271 @@
+package domain
+
+import (
+	"errors"
+	"flow-run/internal/lib/validator"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrBudgetExceeded is matched by BudgetExceededError.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// BudgetScope is what a budget caps the spend of.
+type BudgetScope string
+
+const (
+	BudgetScopeAccount  = BudgetScope("account")
+	BudgetScopeFlow     = BudgetScope("flow")
+	BudgetScopeProvider = BudgetScope("provider")
+)
+
+// BudgetPeriod is how often a budget starts over. Periods start on UTC
+// boundaries.
+type BudgetPeriod string
+
+const (
+	BudgetPeriodDaily   = BudgetPeriod("daily")
+	BudgetPeriodMonthly = BudgetPeriod("monthly")
+)
+
+// defaultReservedCompletionTokens is reserved for the completion of calls
+// that do not set max_tokens.
+const defaultReservedCompletionTokens = 1024
+
+// Budget caps what an account, one of its flows or one of its providers
+// spends per period, as recorded in the usage ledger. Crossing SoftLimit
+// emits a BudgetEvent; calls that would cross HardLimit are rejected before
+// they reach the provider, and so are new runs once it is reached. A zero
+// limit is no limit. TargetID is the flow or provider of flow and provider
+// budgets.
+type Budget struct {
+	ID        uuid.UUID    `json:"id" validate:"required"`
+	AccountID uuid.UUID    `json:"account_id" validate:"required"`
+	Name      string       `json:"name" validate:"required,max=100"`
+	Scope     BudgetScope  `json:"scope" validate:"oneof=account flow provider"`
+	TargetID  *uuid.UUID   `json:"target_id,omitempty"`
+	Period    BudgetPeriod `json:"period" validate:"oneof=daily monthly"`
+	SoftLimit Money        `json:"soft_limit" validate:"min=0"`
+	HardLimit Money        `json:"hard_limit" validate:"min=0"`
+}
+
+type BudgetOpt func(*Budget)
+
+func WithBudgetID(id uuid.UUID) BudgetOpt {
+	return func(b *Budget) {
+		b.ID = id
+	}
+}
+
+func WithBudgetAccountID(accountID uuid.UUID) BudgetOpt {
+	return func(b *Budget) {
+		b.AccountID = accountID
+	}
+}
+
+func WithBudgetName(name string) BudgetOpt {
+	return func(b *Budget) {
+		b.Name = name
+	}
+}
+
+// WithBudgetScope sets the scope and, for flow and provider budgets, the
+// flow or provider.
+func WithBudgetScope(scope BudgetScope, targetID *uuid.UUID) BudgetOpt {
+	return func(b *Budget) {
+		b.Scope = scope
+		b.TargetID = targetID
+	}
+}
+
+func WithBudgetPeriod(period BudgetPeriod) BudgetOpt {
+	return func(b *Budget) {
+		b.Period = period
+	}
+}
+
+func WithBudgetLimits(soft, hard Money) BudgetOpt {
+	return func(b *Budget) {
+		b.SoftLimit = soft
+		b.HardLimit = hard
+	}
+}
+
+func NewBudget(opts ...BudgetOpt) (*Budget, error) {
+	b := &Budget{}
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	b, err := validator.Struct(b)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &fieldChecker{}
+	if b.Scope == BudgetScopeAccount && b.TargetID != nil {
+		c.fail("target_id", "excluded_if", "account budgets have no target")
+	}
+	if b.Scope != BudgetScopeAccount && b.TargetID == nil {
+		c.fail("target_id", "required_unless", "%s budgets need the %s as target", b.Scope, b.Scope)
+	}
+	if b.SoftLimit == 0 && b.HardLimit == 0 {
+		c.fail("hard_limit", "required_without", "set a soft limit, a hard limit or both")
+	}
+	if b.SoftLimit > 0 && b.HardLimit > 0 && b.SoftLimit > b.HardLimit {
+		c.fail("soft_limit", "ltefield", "soft limit %s is above hard limit %s", b.SoftLimit, b.HardLimit)
+	}
+	if err := c.err(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// PeriodStart returns the start of the period t falls in.
+func (b *Budget) PeriodStart(t time.Time) time.Time {
+	t = t.UTC()
+	if b.Period == BudgetPeriodDaily {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Allows reports whether a call estimated at amount may be made when the
+// budget has spent spent this period: the hard limit must neither be
+// reached already nor be crossed by the call.
+func (b *Budget) Allows(spent, amount Money) bool {
+	return b.HardLimit == 0 || (spent < b.HardLimit && spent+amount <= b.HardLimit)
+}
+
+// Applies reports whether calls made for target count against the budget.
+func (b *Budget) Applies(target BudgetTarget) bool {
+	if b.AccountID != target.AccountID {
+		return false
+	}
+	switch b.Scope {
+	case BudgetScopeFlow:
+		return target.FlowID != nil && *b.TargetID == *target.FlowID
+	case BudgetScopeProvider:
+		return target.ProviderID != nil && *b.TargetID == *target.ProviderID
+	default:
+		return true
+	}
+}
+
+// BudgetTarget is what a call or run spends for. FlowID is nil outside flow
+// runs, ProviderID before the provider is known.
+type BudgetTarget struct {
+	AccountID  uuid.UUID
+	FlowID     *uuid.UUID
+	ProviderID *uuid.UUID
+}
+
+// BudgetReservation holds Amount of a budget for a call in flight, so
+// concurrent calls see each other's spend before it reaches the ledger. It
+// is deleted once the call is settled, and stops counting at ExpiresAt, by
+// which time its call is over: a process that stops mid-call cannot hold the
+// budget forever.
+type BudgetReservation struct {
+	ID        uuid.UUID `json:"id"`
+	BudgetID  uuid.UUID `json:"budget_id"`
+	Amount    Money     `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// BudgetHold is what a call reserved, against every budget that applies to
+// it. Its call must be over by ExpiresAt. A nil hold reserves nothing.
+type BudgetHold struct {
+	Budgets      []*Budget
+	Reservations []*BudgetReservation
+	ExpiresAt    time.Time
+}
+
+// NewBudgetHold reserves amount of each budget until expiresAt.
+func NewBudgetHold(budgets []*Budget, amount Money, now, expiresAt time.Time) *BudgetHold {
+	hold := &BudgetHold{Budgets: budgets, ExpiresAt: expiresAt}
+	for _, b := range budgets {
+		hold.Reservations = append(hold.Reservations, &BudgetReservation{
+			ID:        uuid.New(),
+			BudgetID:  b.ID,
+			Amount:    amount,
+			CreatedAt: now,
+			ExpiresAt: expiresAt,
+		})
+	}
+	return hold
+}
+
+// EstimateCost bounds what a call to model may cost, for reservations:
+// prompt tokens are taken as one per four bytes of content, completion tokens
+// as MaxTokens or, without it, defaultReservedCompletionTokens.
+func EstimateCost(model *Model, messages []Message, params CompletionParameters) Money {
+	var size int
+	for _, m := range messages {
+		size += len(m.Content)
+	}
+	completion := defaultReservedCompletionTokens
+	if params.MaxTokens != nil {
+		completion = *params.MaxTokens
+	}
+	return model.Cost(TokenUsage{PromptTokens: (size + 3) / 4, CompletionTokens: completion})
+}
+
+// BudgetExceededError rejects a run or call: the budget had already Spent
+// this period, and Amount more would cross its hard limit.
+type BudgetExceededError struct {
+	Budget *Budget
+	Spent  Money
+	Amount Money
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("%s: budget %q has spent $%s of its %s limit of $%s",
+		ErrBudgetExceeded, e.Budget.Name, e.Spent, e.Budget.Period, e.Budget.HardLimit)
+}
+
+func (e *BudgetExceededError) Is(target error) bool {
+	return target == ErrBudgetExceeded
+}
+
+// BudgetEventType is what happened to a budget.
+type BudgetEventType string
+
+const (
+	BudgetEventSoftLimitCrossed = BudgetEventType("soft_limit_crossed")
+	BudgetEventHardLimitReached = BudgetEventType("hard_limit_reached")
+)
+
+// BudgetEvent records that a budget crossed a limit. Each type is emitted
+// at most once per budget and period.
+type BudgetEvent struct {
+	ID          uuid.UUID       `json:"id"`
+	BudgetID    uuid.UUID       `json:"budget_id"`
+	AccountID   uuid.UUID       `json:"account_id"`
+	Type        BudgetEventType `json:"type"`
+	PeriodStart time.Time       `json:"period_start"`
+	Spent       Money           `json:"spent"`
+	Limit       Money           `json:"limit"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// NewBudgetEvent records that b, having spent spent, crossed the limit of
+// type t in the period of now.
+func NewBudgetEvent(b *Budget, t BudgetEventType, spent Money, now time.Time) *BudgetEvent {
+	limit := b.HardLimit
+	if t == BudgetEventSoftLimitCrossed {
+		limit = b.SoftLimit
+	}
+	return &BudgetEvent{
+		ID:          uuid.New(),
+		BudgetID:    b.ID,
+		AccountID:   b.AccountID,
+		Type:        t,
+		PeriodStart: b.PeriodStart(now),
+		Spent:       spent,
+		Limit:       limit,
+		CreatedAt:   now,
+	}
+}
@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"flow-run/internal/lib/validator"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBudget(t *testing.T) {
+	t.Parallel()
+
+	flowID := uuid.New()
+	tests := []struct {
+		name     string
+		scope    BudgetScope
+		targetID *uuid.UUID
+		period   BudgetPeriod
+		soft     Money
+		hard     Money
+		field    string
+	}{
+		{name: "account", scope: BudgetScopeAccount, period: BudgetPeriodMonthly, soft: 5, hard: 10},
+		{name: "flow_soft_only", scope: BudgetScopeFlow, targetID: &flowID, period: BudgetPeriodDaily, soft: 5},
+		{
+			name:     "account_with_target",
+			scope:    BudgetScopeAccount,
+			targetID: &flowID,
+			period:   BudgetPeriodDaily,
+			hard:     1,
+			field:    "target_id",
+		},
+		{name: "flow_without_target", scope: BudgetScopeFlow, period: BudgetPeriodDaily, hard: 1, field: "target_id"},
+		{name: "no_limit", scope: BudgetScopeAccount, period: BudgetPeriodDaily, field: "hard_limit"},
+		{name: "soft_above_hard", scope: BudgetScopeAccount, period: "daily", soft: 2, hard: 1, field: "soft_limit"},
+		{name: "negative_limit", scope: BudgetScopeAccount, period: BudgetPeriodDaily, hard: -1, field: "hard_limit"},
+		{name: "unknown_scope", scope: "model", period: BudgetPeriodDaily, hard: 1, field: "scope"},
+		{name: "unknown_period", scope: BudgetScopeAccount, period: "weekly", hard: 1, field: "period"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := NewBudget(
+				WithBudgetID(uuid.New()),
+				WithBudgetAccountID(uuid.New()),
+				WithBudgetName("monthly"),
+				WithBudgetScope(tt.scope, tt.targetID),
+				WithBudgetPeriod(tt.period),
+				WithBudgetLimits(tt.soft, tt.hard),
+			)
+			if tt.field == "" {
+				require.NoError(t, err)
+				assert.Equal(t, tt.scope, b.Scope)
+				return
+			}
+			fields, ok := validator.FieldErrors(err)
+			require.True(t, ok, err)
+			assert.Equal(t, tt.field, fields[0].Field)
+		})
+	}
+}
+
+func TestBudgetPeriodStart(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 15, 22, 30, 0, 0, time.FixedZone("UTC-5", -5*3600))
+
+	daily := &Budget{Period: BudgetPeriodDaily}
+	monthly := &Budget{Period: BudgetPeriodMonthly}
+
+	assert.Equal(t, time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC), daily.PeriodStart(now))
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), monthly.PeriodStart(now))
+}
+
+func TestBudgetAllows(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		hard   Money
+		spent  Money
+		amount Money
+		want   bool
+	}{
+		{name: "no_hard_limit", spent: 100, amount: 100, want: true},
+		{name: "fits", hard: 10, spent: 4, amount: 6, want: true},
+		{name: "crosses", hard: 10, spent: 4, amount: 7},
+		{name: "reached", hard: 10, spent: 10},
+		{name: "run_below_limit", hard: 10, spent: 9, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := &Budget{HardLimit: tt.hard}
+			assert.Equal(t, tt.want, b.Allows(tt.spent, tt.amount))
+		})
+	}
+}
+
+func TestBudgetApplies(t *testing.T) {
+	t.Parallel()
+
+	accountID := uuid.New()
+	flowID := uuid.New()
+	providerID := uuid.New()
+	other := uuid.New()
+
+	account := &Budget{AccountID: accountID, Scope: BudgetScopeAccount}
+	flow := &Budget{AccountID: accountID, Scope: BudgetScopeFlow, TargetID: &flowID}
+	provider := &Budget{AccountID: accountID, Scope: BudgetScopeProvider, TargetID: &providerID}
+
+	call := BudgetTarget{AccountID: accountID, FlowID: &flowID, ProviderID: &providerID}
+	assert.True(t, account.Applies(call))
+	assert.True(t, flow.Applies(call))
+	assert.True(t, provider.Applies(call))
+
+	// A run is checked before its provider is known.
+	run := BudgetTarget{AccountID: accountID, FlowID: &flowID}
+	assert.True(t, flow.Applies(run))
+	assert.False(t, provider.Applies(run))
+
+	assert.False(t, flow.Applies(BudgetTarget{AccountID: accountID, FlowID: &other}))
+	assert.False(t, account.Applies(BudgetTarget{AccountID: other}))
+}
+
+func TestEstimateCost(t *testing.T) {
+	t.Parallel()
+
+	model := &Model{InputPrice: 1_000_000_000, OutputPrice: 2_000_000_000}
+	messages := []Message{{Role: MessageRoleUser, Content: "12345678"}, {Role: MessageRoleSystem, Content: "1"}}
+	maxTokens := 10
+
+	assert.Equal(t, Money(3*1000+10*2000), EstimateCost(model, messages, CompletionParameters{MaxTokens: &maxTokens}))
+	assert.Equal(t, Money(3*1000+1024*2000), EstimateCost(model, messages, CompletionParameters{}))
+}
+
+func TestBudgetExceededError(t *testing.T) {
+	t.Parallel()
+
+	err := &BudgetExceededError{
+		Budget: &Budget{Name: "monthly", Period: BudgetPeriodMonthly, HardLimit: 10_000_000_000},
+		Spent:  10_500_000_000,
+	}
+
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+	assert.Equal(t, `budget exceeded: budget "monthly" has spent $10.5 of its monthly limit of $10`, err.Error())
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"encoding/json"
+	"flow-run/internal/lib/validator"
+)
+
+type MessageRole string
+
+const (
+	MessageRoleSystem    = MessageRole("system")
+	MessageRoleUser      = MessageRole("user")
+	MessageRoleAssistant = MessageRole("assistant")
+	MessageRoleTool      = MessageRole("tool")
+)
+
+type FinishReason string
+
+const (
+	FinishReasonStop          = FinishReason("stop")
+	FinishReasonLength        = FinishReason("length")
+	FinishReasonToolCalls     = FinishReason("tool_calls")
+	FinishReasonContentFilter = FinishReason("content_filter")
+	FinishReasonError         = FinishReason("error")
+	FinishReasonUnknown       = FinishReason("unknown")
+)
+
+// Message is one conversation turn. Assistant turns may carry ToolCalls; the
+// results are sent back as tool messages referencing ToolCallID. Cacheable
+// asks providers with explicit prompt caching to cache the prefix ending at
+// this message.
+type Message struct {
+	Role       MessageRole `json:"role" validate:"oneof=system user assistant tool"`
+	Content    string      `json:"content"`
+	ToolCalls  []ToolCall  `json:"tool_calls,omitempty" validate:"dive"`
+	ToolCallID string      `json:"tool_call_id,omitempty" validate:"required_if=Role tool"`
+	Cacheable  bool        `json:"cacheable,omitempty"`
+}
+
+type ToolCall struct {
+	ID        string          `json:"id" validate:"required"`
+	Name      string          `json:"name" validate:"required"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// Tool describes a function the model may call. InputSchema is a JSON schema
+// object.
+type Tool struct {
+	Name        string          `json:"name" validate:"required"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"input_schema" validate:"required"`
+}
+
+// CompletionParameters holds optional sampling settings. Nil fields are left
+// to the provider default.
+type CompletionParameters struct {
+	Temperature *float64 `json:"temperature,omitempty" validate:"omitempty,min=0,max=2"`
+	TopP        *float64 `json:"top_p,omitempty" validate:"omitempty,min=0,max=1"`
+	MaxTokens   *int     `json:"max_tokens,omitempty" validate:"omitempty,min=1"`
+	Stop        []string `json:"stop,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
+}
+
+type CompletionRequest struct {
+	Model      string               `json:"model" validate:"required"`
+	Messages   []Message            `json:"messages" validate:"required,min=1,dive"`
+	Tools      []Tool               `json:"tools,omitempty" validate:"dive"`
+	Parameters CompletionParameters `json:"parameters"`
+}
+
+// TokenUsage counts tokens for one or more calls. PromptTokens includes the
+// cached portion; CacheReadTokens and CacheWriteTokens break it down for
+// providers that price cache hits and cache writes differently.
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+	CacheReadTokens  int `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int `json:"cache_write_tokens,omitempty"`
+}
+
+type CompletionResponse struct {
+	ID           string       `json:"id"`
+	Model        string       `json:"model"`
+	Message      Message      `json:"message"`
+	FinishReason FinishReason `json:"finish_reason"`
+	Usage        TokenUsage   `json:"usage"`
+}
+
+type CompletionRequestOpt func(*CompletionRequest)
+
+func WithCompletionTools(tools ...Tool) CompletionRequestOpt {
+	return func(r *CompletionRequest) {
+		r.Tools = tools
+	}
+}
+
+func NewCompletionRequest(
+	model string, messages []Message, params CompletionParameters, opts ...CompletionRequestOpt,
+) (*CompletionRequest, error) {
+	r := &CompletionRequest{
+		Model:      model,
+		Messages:   messages,
+		Parameters: params,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return validator.Struct(r)
+}
+
+// Add accumulates usage from another call.
+func (u TokenUsage) Add(other TokenUsage) TokenUsage {
+	return TokenUsage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+		TotalTokens:      u.TotalTokens + other.TotalTokens,
+		CacheReadTokens:  u.CacheReadTokens + other.CacheReadTokens,
+		CacheWriteTokens: u.CacheWriteTokens + other.CacheWriteTokens,
+	}
+}
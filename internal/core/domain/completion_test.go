@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCompletionRequestIfValidInput(t *testing.T) {
+	t.Parallel()
+
+	maxTokens := 64
+	req, err := NewCompletionRequest("openai/gpt-4o-mini", []Message{
+		{Role: MessageRoleSystem, Content: "Be brief"},
+		{Role: MessageRoleUser, Content: "Hi"},
+	}, CompletionParameters{MaxTokens: &maxTokens})
+
+	require.NoError(t, err)
+	assert.Equal(t, "openai/gpt-4o-mini", req.Model)
+	assert.Len(t, req.Messages, 2)
+}
+
+func TestNewCompletionRequestIfInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	zero := 0
+	tooHot := 3.0
+	user := []Message{{Role: MessageRoleUser, Content: "Hi"}}
+
+	tests := []struct {
+		name     string
+		model    string
+		messages []Message
+		params   CompletionParameters
+	}{
+		{name: "missing_model", messages: user},
+		{name: "no_messages", model: "m"},
+		{name: "unknown_role", model: "m", messages: []Message{{Role: "function", Content: "x"}}},
+		{name: "tool_result_without_call_id", model: "m", messages: []Message{{Role: MessageRoleTool, Content: "x"}}},
+		{name: "zero_max_tokens", model: "m", messages: user, params: CompletionParameters{MaxTokens: &zero}},
+		{name: "temperature_too_high", model: "m", messages: user, params: CompletionParameters{Temperature: &tooHot}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := NewCompletionRequest(tt.model, tt.messages, tt.params)
+
+			assert.Error(t, err)
+			assert.Nil(t, req)
+		})
+	}
+}
+
+func TestNewCompletionRequestWithTools(t *testing.T) {
+	t.Parallel()
+
+	weather := Tool{Name: "get_weather", InputSchema: json.RawMessage(`{"type":"object"}`)}
+	messages := []Message{
+		{Role: MessageRoleUser, Content: "Weather in Kyiv?"},
+		{Role: MessageRoleAssistant, ToolCalls: []ToolCall{
+			{ID: "call_1", Name: "get_weather", Arguments: json.RawMessage(`{"city":"Kyiv"}`)},
+		}},
+		{Role: MessageRoleTool, ToolCallID: "call_1", Content: `{"temp":21}`},
+	}
+
+	req, err := NewCompletionRequest("m", messages, CompletionParameters{}, WithCompletionTools(weather))
+
+	require.NoError(t, err)
+	assert.Equal(t, []Tool{weather}, req.Tools)
+
+	_, err = NewCompletionRequest("m", messages, CompletionParameters{}, WithCompletionTools(Tool{Name: "no_schema"}))
+
+	assert.Error(t, err)
+}
+
+func TestTokenUsageAdd(t *testing.T) {
+	t.Parallel()
+
+	total := TokenUsage{PromptTokens: 1, CompletionTokens: 2, TotalTokens: 3, CacheReadTokens: 1}.
+		Add(TokenUsage{PromptTokens: 10, CompletionTokens: 20, TotalTokens: 30, CacheWriteTokens: 5})
+
+	assert.Equal(t, TokenUsage{
+		PromptTokens:     11,
+		CompletionTokens: 22,
+		TotalTokens:      33,
+		CacheReadTokens:  1,
+		CacheWriteTokens: 5,
+	}, total)
+}
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"flow-run/internal/lib/validator"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxCostBuckets bounds the time buckets of a cost report, e.g. a bit over a
+// year of hourly buckets.
+const maxCostBuckets = 10_000
+
+// CostBucket is the width of the time buckets of a cost report. Buckets
+// start on UTC boundaries.
+type CostBucket string
+
+const (
+	CostBucketHour  = CostBucket("hour")
+	CostBucketDay   = CostBucket("day")
+	CostBucketMonth = CostBucket("month")
+)
+
+// CostDimension is what a cost report groups the usage ledger by, besides
+// time.
+type CostDimension string
+
+const (
+	CostByAccount  = CostDimension("account")
+	CostByFlow     = CostDimension("flow")
+	CostByModel    = CostDimension("model")
+	CostByProvider = CostDimension("provider")
+	// CostByPromptRevision groups calls by the stored prompt revision that
+	// made them, or that a prompt suite evaluated with them, judges included.
+	// Calls of inline prompts and of flow suites' judges have none.
+	CostByPromptRevision = CostDimension("prompt_revision")
+	// CostByPurpose sets the calls made for test suites, by their cases and
+	// judges, apart from those of flows.
+	CostByPurpose = CostDimension("purpose")
+)
+
+// CostReportQuery selects the usage a cost report covers: calls made in
+// [From, To) by the account, or by every account when AccountID is nil.
+type CostReportQuery struct {
+	AccountID *uuid.UUID      `json:"account_id"`
+	From      time.Time       `json:"from" validate:"required"`
+	To        time.Time       `json:"to" validate:"required,gtfield=From"`
+	Bucket    CostBucket      `json:"bucket" validate:"oneof=hour day month"`
+	GroupBy   []CostDimension `json:"group_by" validate:"unique,dive,oneof=account flow model provider prompt_revision purpose"` //nolint:lll
+}
+
+// NewCostReportQuery checks a report query. It rejects ranges spanning more
+// than maxCostBuckets buckets.
+func NewCostReportQuery(
+	accountID *uuid.UUID, from, to time.Time, bucket CostBucket, groupBy []CostDimension,
+) (*CostReportQuery, error) {
+	q, err := validator.Struct(&CostReportQuery{
+		AccountID: accountID, From: from.UTC(), To: to.UTC(), Bucket: bucket, GroupBy: groupBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c := &fieldChecker{}
+	if n := q.buckets(); n > maxCostBuckets {
+		c.fail("to", "max", "the range spans %d %s buckets, at most %d are allowed", n, q.Bucket, maxCostBuckets)
+	}
+	if err := c.err(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Groups reports whether the query groups by d.
+func (q *CostReportQuery) Groups(d CostDimension) bool {
+	return slices.Contains(q.GroupBy, d)
+}
+
+// buckets counts the buckets the range touches.
+func (q *CostReportQuery) buckets() int {
+	switch q.Bucket {
+	case CostBucketHour:
+		return int(q.To.Truncate(time.Hour).Sub(q.From.Truncate(time.Hour))/time.Hour) + 1
+	case CostBucketDay:
+		return int(q.To.Truncate(24*time.Hour).Sub(q.From.Truncate(24*time.Hour))/(24*time.Hour)) + 1
+	default:
+		months := (q.To.Year()-q.From.Year())*12 + int(q.To.Month()-q.From.Month())
+		return months + 1
+	}
+}
+
+// CostReportRow aggregates the calls of one time bucket and group. Only the
+// fields of the query's dimensions are set.
+type CostReportRow struct {
+	Bucket         time.Time
+	AccountID      *uuid.UUID
+	FlowID         *uuid.UUID
+	ModelID        *uuid.UUID
+	Model          string
+	ProviderID     *uuid.UUID
+	PromptID       *uuid.UUID
+	PromptRevision int
+	Purpose        UsagePurpose
+	Calls          int
+	Usage          TokenUsage
+	Cost           Money
+}
+
+// CostReport is the usage of a query, aggregated per bucket and group, with
+// the totals of the whole range.
+type CostReport struct {
+	Query *CostReportQuery
+	Calls int
+	Usage TokenUsage
+	Cost  Money
+	Rows  []*CostReportRow
+}
+
+// NewCostReport totals rows, which are ordered by bucket.
+func NewCostReport(q *CostReportQuery, rows []*CostReportRow) *CostReport {
+	r := &CostReport{Query: q, Rows: rows}
+	for _, row := range rows {
+		r.Calls += row.Calls
+		r.Usage = r.Usage.Add(row.Usage)
+		r.Cost += row.Cost
+	}
+	return r
+}
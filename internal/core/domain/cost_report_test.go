@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"flow-run/internal/lib/validator"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCostReportQuery(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		to      time.Time
+		bucket  CostBucket
+		groupBy []CostDimension
+		field   string
+	}{
+		{name: "daily", to: from.AddDate(0, 1, 0), bucket: CostBucketDay, groupBy: []CostDimension{CostByModel}},
+		{name: "hourly_over_a_year", to: from.AddDate(1, 0, 0), bucket: CostBucketHour},
+		{name: "hourly_over_two_years", to: from.AddDate(2, 0, 0), bucket: CostBucketHour, field: "to"},
+		{name: "monthly_over_a_century", to: from.AddDate(100, 0, 0), bucket: CostBucketMonth},
+		{name: "empty_range", to: from, bucket: CostBucketDay, field: "to"},
+		{name: "unknown_bucket", to: from.AddDate(0, 0, 1), bucket: "week", field: "bucket"},
+		{
+			name:    "unknown_dimension",
+			to:      from.AddDate(0, 0, 1),
+			bucket:  CostBucketDay,
+			groupBy: []CostDimension{"step"},
+			field:   "group_by[0]",
+		},
+		{
+			name:    "repeated_dimension",
+			to:      from.AddDate(0, 0, 1),
+			bucket:  CostBucketDay,
+			groupBy: []CostDimension{CostByFlow, CostByFlow},
+			field:   "group_by",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			q, err := NewCostReportQuery(nil, from, tt.to, tt.bucket, tt.groupBy)
+			if tt.field == "" {
+				require.NoError(t, err)
+				assert.Equal(t, tt.bucket, q.Bucket)
+				return
+			}
+			fields, ok := validator.FieldErrors(err)
+			require.True(t, ok, err)
+			assert.Equal(t, tt.field, fields[0].Field)
+		})
+	}
+}
+
+func TestNewCostReport(t *testing.T) {
+	t.Parallel()
+
+	rows := []*CostReportRow{
+		{Calls: 2, Usage: TokenUsage{PromptTokens: 10, TotalTokens: 10}, Cost: 5},
+		{Calls: 1, Usage: TokenUsage{CompletionTokens: 4, TotalTokens: 4, CacheReadTokens: 2}, Cost: 7},
+	}
+
+	report := NewCostReport(&CostReportQuery{}, rows)
+
+	assert.Equal(t, 3, report.Calls)
+	assert.Equal(t, TokenUsage{PromptTokens: 10, CompletionTokens: 4, TotalTokens: 14, CacheReadTokens: 2}, report.Usage)
+	assert.Equal(t, Money(12), report.Cost)
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"flow-run/internal/lib/validator"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DatasetFormat is the encoding of an imported dataset.
+type DatasetFormat string
+
+const (
+	// DatasetFormatCSV has a header row naming the columns. Every column is
+	// an input variable but "name" and "expected", which hold the row name
+	// and its expected output.
+	DatasetFormatCSV DatasetFormat = "csv"
+	// DatasetFormatJSONL has one JSON object per line with "inputs" and
+	// optional "name" and "expected" members.
+	DatasetFormatJSONL DatasetFormat = "jsonl"
+)
+
+// Dataset is a named collection of rows that test suites run over. Every
+// import adds a version; Version and RowCount describe the latest one and
+// are zero until the first import.
+type Dataset struct {
+	ID          uuid.UUID `json:"id" validate:"required"`
+	AccountID   uuid.UUID `json:"account_id" validate:"required"`
+	Name        string    `json:"name" validate:"required,max=100"`
+	Description string    `json:"description,omitempty"`
+	Version     int       `json:"version"`
+	RowCount    int       `json:"row_count"`
+}
+
+// DatasetVersion is an immutable snapshot of a dataset's rows. Versions are
+// numbered from 1 per dataset.
+type DatasetVersion struct {
+	ID        uuid.UUID     `json:"id" validate:"required"`
+	DatasetID uuid.UUID     `json:"dataset_id" validate:"required"`
+	AccountID uuid.UUID     `json:"account_id" validate:"required"`
+	Number    int           `json:"version" validate:"min=1"`
+	Format    DatasetFormat `json:"format" validate:"oneof=csv jsonl"`
+	RowCount  int           `json:"row_count"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// DatasetRow is one row of a dataset version. Inputs bind the prompt's
+// variables or the flow's inputs; Expected, when set, is the output the row
+// should produce.
+type DatasetRow struct {
+	VersionID uuid.UUID      `json:"version_id"`
+	Position  int            `json:"position"`
+	Name      string         `json:"name,omitempty"`
+	Inputs    map[string]any `json:"inputs"`
+	Expected  any            `json:"expected,omitempty"`
+}
+
+type DatasetOpt func(*Dataset)
+
+func WithDatasetID(id uuid.UUID) DatasetOpt {
+	return func(d *Dataset) {
+		d.ID = id
+	}
+}
+
+func WithDatasetAccountID(accountID uuid.UUID) DatasetOpt {
+	return func(d *Dataset) {
+		d.AccountID = accountID
+	}
+}
+
+func WithDatasetName(name string) DatasetOpt {
+	return func(d *Dataset) {
+		d.Name = name
+	}
+}
+
+func WithDatasetDescription(description string) DatasetOpt {
+	return func(d *Dataset) {
+		d.Description = description
+	}
+}
+
+func NewDataset(opts ...DatasetOpt) (*Dataset, error) {
+	d := &Dataset{}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	d, err := validator.Struct(d)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &fieldChecker{}
+	if !flowNamePattern.MatchString(d.Name) {
+		c.fail("name", "dataset_name", "name %q may contain only letters, digits, '_', '.' and '-'", d.Name)
+	}
+	if err := c.err(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// NewDatasetVersion starts version number of d, imported from format. Its
+// row count is filled in as the rows are read.
+func NewDatasetVersion(d *Dataset, number int, format DatasetFormat, now time.Time) (*DatasetVersion, error) {
+	return validator.Struct(&DatasetVersion{
+		ID:        uuid.New(),
+		DatasetID: d.ID,
+		AccountID: d.AccountID,
+		Number:    number,
+		Format:    format,
+		CreatedAt: now,
+	})
+}
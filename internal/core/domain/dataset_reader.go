@@ -0,0 +1,181 @@
+package domain
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flow-run/internal/lib/validator"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// datasetNameColumn and datasetExpectedColumn are the CSV columns that
+	// hold a row's name and expected output rather than an input.
+	datasetNameColumn     = "name"
+	datasetExpectedColumn = "expected"
+
+	// maxDatasetLineSize bounds one JSONL line, so a malformed upload cannot
+	// make the reader buffer it whole.
+	maxDatasetLineSize = 4 << 20
+)
+
+// DatasetReader reads the rows of an upload one at a time, so imports never
+// hold the whole dataset in memory. Next returns io.EOF after the last row;
+// a malformed row is reported as a validator error on the "body" field.
+type DatasetReader interface {
+	Next() (*DatasetRow, error)
+}
+
+// NewDatasetReader reads rows encoded as format from r.
+func NewDatasetReader(format DatasetFormat, r io.Reader) (DatasetReader, error) {
+	switch format {
+	case DatasetFormatCSV:
+		reader := csv.NewReader(r)
+		reader.ReuseRecord = true
+		return &csvDatasetReader{reader: reader}, nil
+	case DatasetFormatJSONL:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxDatasetLineSize)
+		return &jsonlDatasetReader{scanner: scanner}, nil
+	default:
+		return nil, validator.NewFieldError("format", "oneof", fmt.Sprintf("unknown dataset format %q", format))
+	}
+}
+
+type csvDatasetReader struct {
+	reader   *csv.Reader
+	columns  []string
+	position int
+}
+
+func (r *csvDatasetReader) Next() (*DatasetRow, error) {
+	if r.columns == nil {
+		if err := r.readHeader(); err != nil {
+			return nil, err
+		}
+	}
+
+	record, err := r.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, malformedDataset(err)
+	}
+
+	row := &DatasetRow{Position: r.position, Inputs: map[string]any{}}
+	for i, value := range record {
+		// Empty cells leave the input unset, so the variable's default
+		// applies.
+		if value == "" {
+			continue
+		}
+		switch r.columns[i] {
+		case datasetNameColumn:
+			row.Name = value
+		case datasetExpectedColumn:
+			row.Expected = value
+		default:
+			row.Inputs[r.columns[i]] = value
+		}
+	}
+	r.position++
+	return row, nil
+}
+
+func (r *csvDatasetReader) readHeader() error {
+	header, err := r.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return io.EOF
+	}
+	if err != nil {
+		return malformedDataset(err)
+	}
+
+	c := &fieldChecker{}
+	seen := map[string]bool{}
+	columns := make([]string, 0, len(header))
+	for i, column := range header {
+		if i == 0 {
+			column = strings.TrimPrefix(column, "\ufeff")
+		}
+		field := fmt.Sprintf("columns[%d]", i)
+		if seen[column] {
+			c.fail(field, "unique", "column %q is declared twice", column)
+		}
+		seen[column] = true
+		if column != datasetNameColumn && column != datasetExpectedColumn {
+			c.checkIdentifier(field, column)
+		}
+		columns = append(columns, column)
+	}
+	if err := c.err(); err != nil {
+		return err
+	}
+	r.columns = columns
+	return nil
+}
+
+type jsonlDatasetReader struct {
+	scanner  *bufio.Scanner
+	line     int
+	position int
+}
+
+type jsonlDatasetRow struct {
+	Name     string         `json:"name"`
+	Inputs   map[string]any `json:"inputs"`
+	Expected any            `json:"expected"`
+}
+
+func (r *jsonlDatasetReader) Next() (*DatasetRow, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var decoded jsonlDatasetRow
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&decoded); err != nil {
+			return nil, malformedDataset(fmt.Errorf("line %d: %w", r.line, err))
+		}
+
+		c := &fieldChecker{}
+		for name := range decoded.Inputs {
+			c.checkIdentifier(fmt.Sprintf("rows[%d].inputs.%s", r.position, name), name)
+		}
+		if err := c.err(); err != nil {
+			return nil, err
+		}
+
+		row := &DatasetRow{
+			Position: r.position,
+			Name:     decoded.Name,
+			Inputs:   decoded.Inputs,
+			Expected: decoded.Expected,
+		}
+		if row.Inputs == nil {
+			row.Inputs = map[string]any{}
+		}
+		r.position++
+		return row, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, malformedDataset(fmt.Errorf("line %d: %w", r.line+1, err))
+		}
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+func malformedDataset(err error) error {
+	return validator.NewFieldError("body", "dataset", err.Error())
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"errors"
+	"flow-run/internal/lib/validator"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDataset(t *testing.T) {
+	t.Parallel()
+
+	d, err := NewDataset(
+		WithDatasetID(uuid.New()),
+		WithDatasetAccountID(uuid.New()),
+		WithDatasetName("capitals.v1"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 0, d.Version)
+
+	_, err = NewDataset(WithDatasetID(uuid.New()), WithDatasetAccountID(uuid.New()), WithDatasetName("my cities"))
+	fields, ok := validator.FieldErrors(err)
+	require.True(t, ok, err)
+	assert.Equal(t, "dataset_name", fields[0].Tag)
+}
+
+func TestDatasetReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		format DatasetFormat
+		input  string
+		want   []*DatasetRow
+	}{
+		{
+			name:   "csv",
+			format: DatasetFormatCSV,
+			input:  "\ufeffname,city,country,expected\nfrance,Paris,France,\"Paris, France\"\n,Rome,,\n",
+			want: []*DatasetRow{
+				{
+					Position: 0,
+					Name:     "france",
+					Inputs:   map[string]any{"city": "Paris", "country": "France"},
+					Expected: "Paris, France",
+				},
+				{Position: 1, Inputs: map[string]any{"city": "Rome"}},
+			},
+		},
+		{
+			name:   "jsonl",
+			format: DatasetFormatJSONL,
+			input: `{"name": "france", "inputs": {"city": "Paris", "size": 2}, "expected": {"country": "France"}}` +
+				"\n\n" + `{"inputs": {"city": "Rome"}}` + "\n",
+			want: []*DatasetRow{
+				{
+					Position: 0,
+					Name:     "france",
+					Inputs:   map[string]any{"city": "Paris", "size": float64(2)},
+					Expected: map[string]any{"country": "France"},
+				},
+				{Position: 1, Inputs: map[string]any{"city": "Rome"}},
+			},
+		},
+		{name: "empty csv", format: DatasetFormatCSV, input: ""},
+		{name: "empty jsonl", format: DatasetFormatJSONL, input: "\n"},
+	}
+
+	for _, tt := range tests {
+		reader, err := NewDatasetReader(tt.format, strings.NewReader(tt.input))
+		require.NoError(t, err, tt.name)
+
+		var rows []*DatasetRow
+		for {
+			row, err := reader.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err, tt.name)
+			rows = append(rows, row)
+		}
+		assert.Equal(t, tt.want, rows, tt.name)
+	}
+}
+
+func TestDatasetReaderIfMalformed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		format DatasetFormat
+		input  string
+		field  string
+		tag    string
+	}{
+		{name: "unknown format", format: "xlsx", field: "format", tag: "oneof"},
+		{name: "bad column", format: DatasetFormatCSV, input: "city,first name\n", field: "columns[1]", tag: "identifier"},
+		{name: "duplicate column", format: DatasetFormatCSV, input: "city,city\n", field: "columns[1]", tag: "unique"},
+		{name: "ragged csv", format: DatasetFormatCSV, input: "city,country\nParis\n", field: "body", tag: "dataset"},
+		{name: "bad json", format: DatasetFormatJSONL, input: `{"inputs": {}}` + "\n{", field: "body", tag: "dataset"},
+		{name: "unknown member", format: DatasetFormatJSONL, input: `{"input": {}}`, field: "body", tag: "dataset"},
+		{
+			name:   "bad input name",
+			format: DatasetFormatJSONL,
+			input:  `{"inputs": {"first name": "Ada"}}`,
+			field:  "rows[0].inputs.first name",
+			tag:    "identifier",
+		},
+	}
+
+	for _, tt := range tests {
+		reader, err := NewDatasetReader(tt.format, strings.NewReader(tt.input))
+		for err == nil {
+			_, err = reader.Next()
+		}
+		fields, ok := validator.FieldErrors(err)
+		require.True(t, ok, "%s: %v", tt.name, err)
+		assert.Equal(t, tt.field, fields[0].Field, tt.name)
+		assert.Equal(t, tt.tag, fields[0].Tag, tt.name)
+	}
+}
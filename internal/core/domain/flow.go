@@ -1,7 +1,304 @@
 package domain
 
-import "github.com/google/uuid"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flow-run/internal/lib/validator"
+	"fmt"
 
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+type FlowSourceFormat string
+
+const (
+	FlowSourceFormatYAML = FlowSourceFormat("yaml")
+	FlowSourceFormatJSON = FlowSourceFormat("json")
+)
+
+// FlowMode decides how steps are ordered. In sequential mode every step
+// depends on the one before it; in dag mode steps list their dependencies in
+// depends_on and steps without any are roots.
+type FlowMode string
+
+const (
+	FlowModeSequential = FlowMode("sequential")
+	FlowModeDAG        = FlowMode("dag")
+)
+
+type StepType string
+
+const (
+	StepTypePrompt    = StepType("prompt")
+	StepTypeTransform = StepType("transform")
+	StepTypeBranch    = StepType("branch")
+	StepTypeFlow      = StepType("flow")
+)
+
+// ValueType is the declared type of a flow input or output or of a prompt
+// variable, using JSON vocabulary.
+type ValueType string
+
+const (
+	ValueTypeString  = ValueType("string")
+	ValueTypeNumber  = ValueType("number")
+	ValueTypeInteger = ValueType("integer")
+	ValueTypeBoolean = ValueType("boolean")
+	ValueTypeObject  = ValueType("object")
+	ValueTypeArray   = ValueType("array")
+)
+
+var ErrInvalidFlowSource = errors.New("invalid flow source")
+
+// Flow is a stored flow definition. Source keeps the document exactly as it
+// was authored; Definition is parsed from it and never stored separately.
 type Flow struct {
-	ID uuid.UUID `json:"id"`
+	ID         uuid.UUID        `json:"id" validate:"required"`
+	AccountID  uuid.UUID        `json:"account_id" validate:"required"`
+	Name       string           `json:"name" validate:"required"`
+	Format     FlowSourceFormat `json:"format" validate:"oneof=yaml json"`
+	Source     string           `json:"source" validate:"required"`
+	Definition *FlowDefinition  `json:"-" validate:"required"`
+}
+
+// FlowDefinition is the authored document.
+type FlowDefinition struct {
+	Name        string         `json:"name" validate:"required,max=100"`
+	Description string         `json:"description,omitempty"`
+	Mode        FlowMode       `json:"mode,omitempty" validate:"omitempty,oneof=sequential dag"`
+	Inputs      []FlowVariable `json:"inputs,omitempty" validate:"dive"`
+	Outputs     []FlowOutput   `json:"outputs,omitempty" validate:"dive"`
+	Steps       []Step         `json:"steps" validate:"required,min=1,dive"`
+}
+
+type FlowVariable struct {
+	Name        string    `json:"name" validate:"required"`
+	Type        ValueType `json:"type" validate:"oneof=string number integer boolean object array"`
+	Required    bool      `json:"required,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Default     any       `json:"default,omitempty"`
+}
+
+// FlowOutput exposes a value produced by the flow. From is a reference, see
+// ParseReference.
+type FlowOutput struct {
+	Name string    `json:"name" validate:"required"`
+	Type ValueType `json:"type" validate:"oneof=string number integer boolean object array"`
+	From string    `json:"from" validate:"required"`
+}
+
+// Step is one unit of work. Inputs binds the names a step's templates see to
+// references; exactly the section matching Type must be set.
+type Step struct {
+	ID        string            `json:"id" validate:"required"`
+	Type      StepType          `json:"type" validate:"oneof=prompt transform branch flow"`
+	DependsOn []string          `json:"depends_on,omitempty"`
+	Inputs    map[string]string `json:"inputs,omitempty"`
+	Prompt    *PromptStep       `json:"prompt,omitempty" validate:"required_if=Type prompt,excluded_unless=Type prompt"`          //nolint:lll
+	Transform *TransformStep    `json:"transform,omitempty" validate:"required_if=Type transform,excluded_unless=Type transform"` //nolint:lll
+	Branch    *BranchStep       `json:"branch,omitempty" validate:"required_if=Type branch,excluded_unless=Type branch"`          //nolint:lll
+	Flow      *SubFlowStep      `json:"flow,omitempty" validate:"required_if=Type flow,excluded_unless=Type flow"`                //nolint:lll
+}
+
+// PromptStep calls a model. Model is the name of a domain.Model in the flow's
+// account. The prompt is either inline (System, User and optionally typed
+// Variables) or Prompt, the name of a stored prompt in the same account; the
+// step inputs bind the template variables either way. A stored prompt runs
+// its latest revision unless the step pins a Revision or follows a Label.
+type PromptStep struct {
+	Model      string               `json:"model" validate:"required"`
+	Prompt     string               `json:"prompt,omitempty" validate:"excluded_with=User"`
+	Revision   int                  `json:"revision,omitempty" validate:"omitempty,min=1,excluded_without=Prompt,excluded_with=Label"` //nolint:lll
+	Label      string               `json:"label,omitempty" validate:"excluded_without=Prompt"`
+	System     string               `json:"system,omitempty" validate:"excluded_with=Prompt"`
+	User       string               `json:"user,omitempty" validate:"required_without=Prompt"`
+	Variables  []FlowVariable       `json:"variables,omitempty" validate:"excluded_with=Prompt,dive"`
+	Parameters CompletionParameters `json:"parameters"`
+}
+
+// Ref returns the stored prompt revision the step runs.
+func (s *PromptStep) Ref() PromptRef {
+	return PromptRef{Name: s.Prompt, Revision: s.Revision, Label: s.Label}
+}
+
+// Template returns the inline prompt.
+func (s *PromptStep) Template() *PromptTemplate {
+	return &PromptTemplate{System: s.System, User: s.User, Variables: s.Variables}
+}
+
+// TransformStep renders Template over the step inputs. With ParseJSON the
+// rendered text is decoded and the step outputs the resulting value.
+type TransformStep struct {
+	Template  string `json:"template" validate:"required"`
+	ParseJSON bool   `json:"parse_json,omitempty"`
+}
+
+// BranchStep selects which of its direct dependents run. Cases are evaluated
+// in order; the first whose When template renders "true" wins, otherwise
+// Default applies. Targets that are not selected are skipped.
+type BranchStep struct {
+	Cases   []BranchCase `json:"cases" validate:"required,min=1,dive"`
+	Default []string     `json:"default,omitempty"`
+}
+
+type BranchCase struct {
+	Name string   `json:"name" validate:"required"`
+	When string   `json:"when" validate:"required"`
+	Then []string `json:"then" validate:"required,min=1"`
+}
+
+// SubFlowStep runs another flow of the same account by name. The step inputs
+// become the sub-flow inputs and its outputs become the step output.
+type SubFlowStep struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type FlowOpt func(*Flow)
+
+func WithFlowID(id uuid.UUID) FlowOpt {
+	return func(f *Flow) {
+		f.ID = id
+	}
+}
+
+func WithFlowAccountID(accountID uuid.UUID) FlowOpt {
+	return func(f *Flow) {
+		f.AccountID = accountID
+	}
+}
+
+func WithFlowSource(source string, format FlowSourceFormat) FlowOpt {
+	return func(f *Flow) {
+		f.Source = source
+		f.Format = format
+	}
+}
+
+// NewFlow parses and checks the source. The flow takes its name from the
+// definition.
+func NewFlow(opts ...FlowOpt) (*Flow, error) {
+	f := &Flow{}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	if f.Source != "" {
+		definition, err := ParseFlowDefinition(f.Source, f.Format)
+		if err != nil {
+			return nil, err
+		}
+		f.Definition = definition
+		f.Name = definition.Name
+	}
+
+	return validator.Struct(f)
+}
+
+// ParseFlowDefinition decodes source and validates it, including the checks
+// that span several steps. YAML is converted to JSON first so both formats
+// share one set of field names and unknown fields are rejected in either.
+func ParseFlowDefinition(source string, format FlowSourceFormat) (*FlowDefinition, error) {
+	data := []byte(source)
+
+	switch format {
+	case FlowSourceFormatJSON:
+	case FlowSourceFormatYAML:
+		var document any
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return nil, validator.NewFieldError("source", "yaml", fmt.Sprintf("%s: %s", ErrInvalidFlowSource, err))
+		}
+		converted, err := json.Marshal(document)
+		if err != nil {
+			return nil, validator.NewFieldError("source", "yaml", fmt.Sprintf("%s: %s", ErrInvalidFlowSource, err))
+		}
+		data = converted
+	default:
+		return nil, validator.NewFieldError("format", "oneof", "format must be yaml or json")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	d := &FlowDefinition{}
+	if err := decoder.Decode(d); err != nil {
+		return nil, validator.NewFieldError("source", string(format), fmt.Sprintf("%s: %s", ErrInvalidFlowSource, err))
+	}
+
+	d, err := validator.Struct(d)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.check(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// StepByID returns the step with the given id, or nil.
+func (d *FlowDefinition) StepByID(id string) *Step {
+	for i := range d.Steps {
+		if d.Steps[i].ID == id {
+			return &d.Steps[i]
+		}
+	}
+	return nil
+}
+
+// Dependencies returns the ids of the steps that must finish before step i.
+func (d *FlowDefinition) Dependencies(i int) []string {
+	if d.Mode == FlowModeDAG {
+		return d.Steps[i].DependsOn
+	}
+	if i == 0 {
+		return nil
+	}
+	return []string{d.Steps[i-1].ID}
+}
+
+// ModelNames lists the models referenced by prompt steps, without duplicates.
+func (d *FlowDefinition) ModelNames() []string {
+	return d.collect(func(s *Step) string {
+		if s.Prompt == nil {
+			return ""
+		}
+		return s.Prompt.Model
+	})
+}
+
+// PromptNames lists the stored prompts referenced by prompt steps, without
+// duplicates.
+func (d *FlowDefinition) PromptNames() []string {
+	return d.collect(func(s *Step) string {
+		if s.Prompt == nil {
+			return ""
+		}
+		return s.Prompt.Prompt
+	})
+}
+
+// SubFlowNames lists the flows referenced by sub-flow steps, without
+// duplicates.
+func (d *FlowDefinition) SubFlowNames() []string {
+	return d.collect(func(s *Step) string {
+		if s.Flow == nil {
+			return ""
+		}
+		return s.Flow.Name
+	})
+}
+
+func (d *FlowDefinition) collect(value func(*Step) string) []string {
+	seen := map[string]bool{}
+	var names []string
+	for i := range d.Steps {
+		name := value(&d.Steps[i])
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
 }
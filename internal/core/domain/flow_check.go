@@ -0,0 +1,330 @@
+package domain
+
+import (
+	"errors"
+	"flow-run/internal/lib/validator"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	ReferenceSourceInputs = "inputs"
+	ReferenceSourceSteps  = "steps"
+
+	// referenceStepOutput is the only field of a step a reference can read.
+	referenceStepOutput = "output"
+)
+
+var (
+	ErrInvalidReference = errors.New("invalid reference")
+
+	// identifierPattern keeps names usable as template fields, e.g. {{ .text }}.
+	identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	flowNamePattern   = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+)
+
+// Reference points at a flow input ("inputs.<name>") or a step result
+// ("steps.<id>.output"), optionally followed by a path into an object value
+// ("steps.extract.output.title").
+type Reference struct {
+	Source string
+	Name   string
+	Path   []string
+}
+
+func ParseReference(ref string) (Reference, error) {
+	parts := strings.Split(ref, ".")
+	if len(parts) < 2 || slices.Contains(parts, "") {
+		return Reference{}, fmt.Errorf("%w: %q", ErrInvalidReference, ref)
+	}
+
+	switch parts[0] {
+	case ReferenceSourceInputs:
+		return Reference{Source: ReferenceSourceInputs, Name: parts[1], Path: parts[2:]}, nil
+	case ReferenceSourceSteps:
+		if len(parts) < 3 || parts[2] != referenceStepOutput {
+			return Reference{}, fmt.Errorf("%w: %q, expected steps.<id>.output", ErrInvalidReference, ref)
+		}
+		return Reference{Source: ReferenceSourceSteps, Name: parts[1], Path: parts[3:]}, nil
+	default:
+		return Reference{}, fmt.Errorf("%w: %q, expected inputs.<name> or steps.<id>.output", ErrInvalidReference, ref)
+	}
+}
+
+// fieldChecker collects every problem of a document so authors can fix them
+// in one round trip.
+type fieldChecker struct {
+	fields []validator.FieldError
+}
+
+func (c *fieldChecker) fail(field, tag, format string, args ...any) {
+	c.fields = append(c.fields, validator.FieldError{
+		Field:   field,
+		Tag:     tag,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *fieldChecker) checkIdentifier(field, name string) {
+	if !identifierPattern.MatchString(name) {
+		c.fail(field, "identifier", "%q must start with a letter or '_' and contain only letters, digits and '_'", name)
+	}
+}
+
+// checkTemplate parses text and requires every name it reads from its data to
+// be declared.
+func (c *fieldChecker) checkTemplate(field, text string, declared map[string]bool) {
+	refs, err := TemplateReferences(text)
+	if err != nil {
+		c.fail(field, "template", "%s", err)
+		return
+	}
+	for _, name := range refs {
+		if !declared[name] {
+			c.fail(field, "declared", "template reads %q, which is not declared", name)
+		}
+	}
+}
+
+func (c *fieldChecker) err() error {
+	if len(c.fields) > 0 {
+		return &validator.Error{Fields: c.fields}
+	}
+	return nil
+}
+
+// flowChecker checks what spans several fields of a definition.
+type flowChecker struct {
+	fieldChecker
+
+	d *FlowDefinition
+
+	steps     map[string]int
+	ancestors map[string]map[string]bool
+}
+
+func (d *FlowDefinition) check() error {
+	c := &flowChecker{d: d, steps: map[string]int{}}
+
+	c.checkNames()
+	if c.checkDependencies() {
+		c.checkStepReferences()
+		c.checkBranches()
+	}
+	c.checkOutputs()
+	c.checkTemplates()
+
+	return c.err()
+}
+
+func (c *flowChecker) checkNames() {
+	if !flowNamePattern.MatchString(c.d.Name) {
+		c.fail("name", "flow_name", "name %q may contain only letters, digits, '_', '.' and '-'", c.d.Name)
+	}
+
+	inputs := map[string]bool{}
+	for i, input := range c.d.Inputs {
+		field := fmt.Sprintf("inputs[%d].name", i)
+		c.checkIdentifier(field, input.Name)
+		if inputs[input.Name] {
+			c.fail(field, "unique", "input %q is declared twice", input.Name)
+		}
+		inputs[input.Name] = true
+	}
+
+	outputs := map[string]bool{}
+	for i, output := range c.d.Outputs {
+		field := fmt.Sprintf("outputs[%d].name", i)
+		c.checkIdentifier(field, output.Name)
+		if outputs[output.Name] {
+			c.fail(field, "unique", "output %q is declared twice", output.Name)
+		}
+		outputs[output.Name] = true
+	}
+
+	for i, step := range c.d.Steps {
+		field := fmt.Sprintf("steps[%d].id", i)
+		c.checkIdentifier(field, step.ID)
+		if _, ok := c.steps[step.ID]; ok {
+			c.fail(field, "unique", "step %q is declared twice", step.ID)
+			continue
+		}
+		c.steps[step.ID] = i
+
+		for name := range step.Inputs {
+			c.checkIdentifier(fmt.Sprintf("steps[%d].inputs.%s", i, name), name)
+		}
+	}
+}
+
+// checkDependencies validates depends_on and computes the ancestors of every
+// step. It returns false when the graph is unusable for further checks.
+func (c *flowChecker) checkDependencies() bool {
+	valid := true
+	for i, step := range c.d.Steps {
+		if c.d.Mode != FlowModeDAG && len(step.DependsOn) > 0 {
+			c.fail(fmt.Sprintf("steps[%d].depends_on", i), "excluded_unless",
+				"depends_on is only allowed in dag mode; sequential steps depend on the previous one")
+			valid = false
+			continue
+		}
+		for j, dep := range step.DependsOn {
+			field := fmt.Sprintf("steps[%d].depends_on[%d]", i, j)
+			if _, ok := c.steps[dep]; !ok {
+				c.fail(field, "exists", "step %q does not exist", dep)
+				valid = false
+			} else if dep == step.ID {
+				c.fail(field, "acyclic", "step %q depends on itself", dep)
+				valid = false
+			}
+		}
+	}
+	if !valid {
+		return false
+	}
+
+	c.ancestors = map[string]map[string]bool{}
+	visiting := map[string]bool{}
+	var visit func(i int) bool
+	visit = func(i int) bool {
+		id := c.d.Steps[i].ID
+		if c.ancestors[id] != nil {
+			return true
+		}
+		if visiting[id] {
+			c.fail(fmt.Sprintf("steps[%d].depends_on", i), "acyclic", "step %q is part of a dependency cycle", id)
+			return false
+		}
+		visiting[id] = true
+
+		ancestors := map[string]bool{}
+		for _, dep := range c.d.Dependencies(i) {
+			if !visit(c.steps[dep]) {
+				return false
+			}
+			ancestors[dep] = true
+			for a := range c.ancestors[dep] {
+				ancestors[a] = true
+			}
+		}
+		c.ancestors[id] = ancestors
+		return true
+	}
+	for i := range c.d.Steps {
+		if !visit(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkStepReferences makes sure a step only reads declared inputs and the
+// results of steps guaranteed to have finished before it.
+func (c *flowChecker) checkStepReferences() {
+	for i, step := range c.d.Steps {
+		for name, ref := range step.Inputs {
+			field := fmt.Sprintf("steps[%d].inputs.%s", i, name)
+			parsed, ok := c.checkReference(field, ref)
+			if !ok || parsed.Source != ReferenceSourceSteps {
+				continue
+			}
+			if !c.ancestors[step.ID][parsed.Name] {
+				c.fail(field, "reference", "step %q does not depend on step %q", step.ID, parsed.Name)
+			}
+		}
+		if step.Flow != nil && step.Flow.Name == c.d.Name {
+			c.fail(fmt.Sprintf("steps[%d].flow.name", i), "acyclic", "flow %q cannot call itself", c.d.Name)
+		}
+	}
+}
+
+func (c *flowChecker) checkOutputs() {
+	for i, output := range c.d.Outputs {
+		c.checkReference(fmt.Sprintf("outputs[%d].from", i), output.From)
+	}
+}
+
+func (c *flowChecker) checkReference(field, ref string) (Reference, bool) {
+	parsed, err := ParseReference(ref)
+	if err != nil {
+		c.fail(field, "reference", "%s", err)
+		return Reference{}, false
+	}
+
+	switch parsed.Source {
+	case ReferenceSourceInputs:
+		if !slices.ContainsFunc(c.d.Inputs, func(v FlowVariable) bool { return v.Name == parsed.Name }) {
+			c.fail(field, "reference", "input %q is not declared", parsed.Name)
+			return parsed, false
+		}
+	case ReferenceSourceSteps:
+		if _, ok := c.steps[parsed.Name]; !ok {
+			c.fail(field, "reference", "step %q does not exist", parsed.Name)
+			return parsed, false
+		}
+	}
+	return parsed, true
+}
+
+// checkBranches requires every branch target to depend directly on the
+// branch, otherwise skipping it would not be decided by the branch alone.
+func (c *flowChecker) checkBranches() {
+	for i, step := range c.d.Steps {
+		if step.Branch == nil {
+			continue
+		}
+		for j, branchCase := range step.Branch.Cases {
+			c.checkBranchTargets(fmt.Sprintf("steps[%d].branch.cases[%d].then", i, j), step.ID, branchCase.Then)
+		}
+		c.checkBranchTargets(fmt.Sprintf("steps[%d].branch.default", i), step.ID, step.Branch.Default)
+	}
+}
+
+func (c *flowChecker) checkBranchTargets(field, branchID string, targets []string) {
+	for k, target := range targets {
+		targetField := fmt.Sprintf("%s[%d]", field, k)
+		index, ok := c.steps[target]
+		if !ok {
+			c.fail(targetField, "exists", "step %q does not exist", target)
+			continue
+		}
+		if !slices.Contains(c.d.Dependencies(index), branchID) {
+			c.fail(targetField, "reference", "step %q must depend directly on branch %q", target, branchID)
+		}
+	}
+}
+
+// checkTemplates requires step templates to read only the names their step
+// binds. Inline prompts that declare variables are checked against those, and
+// the step inputs must bind them.
+func (c *flowChecker) checkTemplates() {
+	for i, step := range c.d.Steps {
+		field := fmt.Sprintf("steps[%d]", i)
+		bound := map[string]bool{}
+		for name := range step.Inputs {
+			bound[name] = true
+		}
+
+		switch {
+		case step.Prompt != nil && step.Prompt.Prompt != "":
+			// Stored prompts are checked by the repository, which can load them.
+		case step.Prompt != nil && len(step.Prompt.Variables) > 0:
+			prompt := step.Prompt.Template()
+			prompt.check(&c.fieldChecker, field+".prompt.")
+			prompt.checkBindings(&c.fieldChecker, field+".inputs", step.Inputs)
+		case step.Prompt != nil:
+			if step.Prompt.System != "" {
+				c.checkTemplate(field+".prompt.system", step.Prompt.System, bound)
+			}
+			c.checkTemplate(field+".prompt.user", step.Prompt.User, bound)
+		case step.Transform != nil:
+			c.checkTemplate(field+".transform.template", step.Transform.Template, bound)
+		case step.Branch != nil:
+			for j, branchCase := range step.Branch.Cases {
+				c.checkTemplate(fmt.Sprintf("%s.branch.cases[%d].when", field, j), branchCase.When, bound)
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"flow-run/internal/lib/validator"
+
+	"github.com/google/uuid"
+)
+
+// DefaultCompletionTokens is the completion length an estimate assumes for
+// prompt steps that set no max_tokens.
+const DefaultCompletionTokens = 256
+
+// Chat APIs wrap every message in a few tokens of their own and prime the
+// reply with a few more, per OpenAI's accounting of chat prompts.
+const (
+	tokensPerMessage = 3
+	tokensPerReply   = 3
+)
+
+// CompletionLengths is what an estimate assumes prompt steps complete with,
+// in tokens: Steps by step ID, sub-flow steps by their path such as
+// "research.search", else the step's max_tokens, else Default.
+type CompletionLengths struct {
+	Default int            `json:"completion_tokens" validate:"min=0"`
+	Steps   map[string]int `json:"step_completion_tokens" validate:"dive,min=0"`
+}
+
+// NewCompletionLengths checks lengths. A zero default is
+// DefaultCompletionTokens.
+func NewCompletionLengths(defaultTokens int, steps map[string]int) (*CompletionLengths, error) {
+	if defaultTokens == 0 {
+		defaultTokens = DefaultCompletionTokens
+	}
+	return validator.Struct(&CompletionLengths{Default: defaultTokens, Steps: steps})
+}
+
+// For returns the completion length of the prompt step at path.
+func (l *CompletionLengths) For(path string, params CompletionParameters) int {
+	if n, ok := l.Steps[path]; ok {
+		return n
+	}
+	if params.MaxTokens != nil {
+		return *params.MaxTokens
+	}
+	return l.Default
+}
+
+// TokenCounter counts the tokens of text as the named model would.
+// Encoding names the tokenizer used, or is empty when the count is an
+// approximation.
+type TokenCounter func(model, text string) (count int, encoding string)
+
+// CountPromptTokens counts the prompt tokens of messages, including what the
+// chat format adds around them.
+func CountPromptTokens(count TokenCounter, model string, messages []Message) (int, string) {
+	tokens, encoding := tokensPerReply, ""
+	for _, m := range messages {
+		n, e := count(model, m.Content)
+		tokens += tokensPerMessage + n
+		encoding = e
+	}
+	return tokens, encoding
+}
+
+// ApproximateTokens counts one token per four bytes of text, the rule of
+// thumb for English text, for models whose tokenizer is not available.
+func ApproximateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// StepEstimate is the estimated usage of one prompt step. StepID is the
+// path of the step through sub-flow steps, e.g. "research.search". Encoding
+// is empty when tokens were approximated. Rendered is false when the
+// prompt could not be rendered from what is known before running, and the
+// template source was counted instead.
+type StepEstimate struct {
+	StepID   string
+	Model    string
+	Encoding string
+	Rendered bool
+	Usage    TokenUsage
+	Cost     Money
+}
+
+// FlowEstimate is the estimated usage and cost of running a flow once,
+// totalled over its prompt steps.
+type FlowEstimate struct {
+	FlowID uuid.UUID
+	Steps  []*StepEstimate
+	Usage  TokenUsage
+	Cost   Money
+}
+
+// NewFlowEstimate totals steps.
+func NewFlowEstimate(flowID uuid.UUID, steps []*StepEstimate) *FlowEstimate {
+	e := &FlowEstimate{FlowID: flowID, Steps: steps}
+	for _, step := range steps {
+		e.Usage = e.Usage.Add(step.Usage)
+		e.Cost += step.Cost
+	}
+	return e
+}
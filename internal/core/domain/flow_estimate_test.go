@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"flow-run/internal/lib/validator"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletionLengths(t *testing.T) {
+	t.Parallel()
+
+	lengths, err := NewCompletionLengths(0, map[string]int{"research.search": 10})
+	require.NoError(t, err)
+
+	maxTokens := 100
+	assert.Equal(t, 10, lengths.For("research.search", CompletionParameters{MaxTokens: &maxTokens}))
+	assert.Equal(t, 100, lengths.For("search", CompletionParameters{MaxTokens: &maxTokens}))
+	assert.Equal(t, DefaultCompletionTokens, lengths.For("search", CompletionParameters{}))
+
+	_, err = NewCompletionLengths(-1, map[string]int{"search": -1})
+	fields, ok := validator.FieldErrors(err)
+	require.True(t, ok, err)
+	require.Len(t, fields, 2)
+	assert.Equal(t, "min", fields[0].Tag)
+}
+
+func TestCountPromptTokens(t *testing.T) {
+	t.Parallel()
+
+	messages := []Message{{Role: MessageRoleSystem, Content: "1234"}, {Role: MessageRoleUser, Content: "12345"}}
+
+	tokens, encoding := CountPromptTokens(func(_, text string) (int, string) {
+		return ApproximateTokens(text), "test"
+	}, "gpt", messages)
+	assert.Equal(t, 3+3+1+3+2, tokens)
+	assert.Equal(t, "test", encoding)
+}
+
+func TestNewFlowEstimate(t *testing.T) {
+	t.Parallel()
+
+	flowID := uuid.New()
+	steps := []*StepEstimate{
+		{StepID: "a", Usage: TokenUsage{PromptTokens: 1, CompletionTokens: 2, TotalTokens: 3}, Cost: 10},
+		{StepID: "b", Usage: TokenUsage{PromptTokens: 4, CompletionTokens: 5, TotalTokens: 9}, Cost: 20},
+	}
+
+	estimate := NewFlowEstimate(flowID, steps)
+	assert.Equal(t, flowID, estimate.FlowID)
+	assert.Equal(t, TokenUsage{PromptTokens: 5, CompletionTokens: 7, TotalTokens: 12}, estimate.Usage)
+	assert.Equal(t, Money(30), estimate.Cost)
+}
@@ -0,0 +1,302 @@
+package domain
+
+import (
+	"flow-run/internal/lib/validator"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFlowYAML = `
+name: triage
+description: Classify a support ticket and draft a reply
+mode: dag
+inputs:
+  - name: ticket
+    type: string
+    required: true
+outputs:
+  - name: reply
+    type: string
+    from: steps.reply.output
+steps:
+  - id: classify
+    type: prompt
+    inputs:
+      ticket: inputs.ticket
+    prompt:
+      model: gpt-4o-mini
+      system: Answer with one word, billing or other.
+      user: "{{ .ticket }}"
+      parameters:
+        max_tokens: 5
+  - id: route
+    type: branch
+    depends_on: [classify]
+    inputs:
+      label: steps.classify.output
+    branch:
+      cases:
+        - name: billing
+          when: '{{ eq .label "billing" }}'
+          then: [billing]
+      default: [reply]
+  - id: billing
+    type: flow
+    depends_on: [route]
+    inputs:
+      ticket: inputs.ticket
+    flow:
+      name: billing_reply
+  - id: reply
+    type: transform
+    depends_on: [route, classify]
+    inputs:
+      label: steps.classify.output
+    transform:
+      template: "Routed as {{ .label }}"
+`
+
+func TestNewFlowIfValidYAML(t *testing.T) {
+	t.Parallel()
+
+	flow, err := NewFlow(
+		WithFlowID(uuid.New()),
+		WithFlowAccountID(uuid.New()),
+		WithFlowSource(testFlowYAML, FlowSourceFormatYAML),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "triage", flow.Name)
+	assert.Equal(t, testFlowYAML, flow.Source)
+
+	d := flow.Definition
+	assert.Equal(t, FlowModeDAG, d.Mode)
+	require.Len(t, d.Steps, 4)
+	assert.Equal(t, StepTypePrompt, d.Steps[0].Type)
+	require.NotNil(t, d.Steps[0].Prompt.Parameters.MaxTokens)
+	assert.Equal(t, 5, *d.Steps[0].Prompt.Parameters.MaxTokens)
+	assert.Equal(t, []string{"gpt-4o-mini"}, d.ModelNames())
+	assert.Equal(t, []string{"billing_reply"}, d.SubFlowNames())
+	assert.Equal(t, []string{"route", "classify"}, d.Dependencies(3))
+}
+
+func TestNewFlowIfValidJSON(t *testing.T) {
+	t.Parallel()
+
+	source := `{
+		"name": "echo",
+		"inputs": [{"name": "text", "type": "string"}],
+		"outputs": [{"name": "result", "type": "string", "from": "steps.second.output"}],
+		"steps": [
+			{"id": "first", "type": "transform", "inputs": {"text": "inputs.text"}, "transform": {"template": "{{ .text }}"}},
+			{"id": "second", "type": "transform", "inputs": {"text": "steps.first.output"},
+			 "transform": {"template": "{{ .text }}!"}}
+		]
+	}`
+
+	flow, err := NewFlow(
+		WithFlowID(uuid.New()),
+		WithFlowAccountID(uuid.New()),
+		WithFlowSource(source, FlowSourceFormatJSON),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "echo", flow.Name)
+	assert.Nil(t, flow.Definition.Dependencies(0))
+	assert.Equal(t, []string{"first"}, flow.Definition.Dependencies(1))
+}
+
+func TestNewFlowIfInvalidSource(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		source string
+		fields []string
+	}{
+		{
+			name:   "not_yaml",
+			source: "name: [unterminated",
+			fields: []string{"source"},
+		},
+		{
+			name:   "unknown_field",
+			source: "name: x\nsteps:\n  - id: a\n    type: transform\n    transfrom: {template: x}\n",
+			fields: []string{"source"},
+		},
+		{
+			name:   "missing_steps",
+			source: "name: x\n",
+			fields: []string{"steps"},
+		},
+		{
+			name:   "section_does_not_match_type",
+			source: "name: x\nsteps:\n  - id: a\n    type: prompt\n    transform: {template: x}\n",
+			fields: []string{"steps[0].prompt", "steps[0].transform"},
+		},
+		{
+			name: "duplicate_step_and_bad_identifier",
+			source: "name: x\nsteps:\n" +
+				"  - {id: a, type: transform, transform: {template: x}}\n" +
+				"  - {id: a, type: transform, transform: {template: x}}\n" +
+				"  - {id: 1b, type: transform, transform: {template: x}}\n",
+			fields: []string{"steps[1].id", "steps[2].id"},
+		},
+		{
+			name: "depends_on_in_sequential_mode",
+			source: "name: x\nsteps:\n" +
+				"  - {id: a, type: transform, transform: {template: x}}\n" +
+				"  - {id: b, type: transform, depends_on: [a], transform: {template: x}}\n",
+			fields: []string{"steps[1].depends_on"},
+		},
+		{
+			name: "unknown_dependency",
+			source: "name: x\nmode: dag\nsteps:\n" +
+				"  - {id: a, type: transform, depends_on: [missing], transform: {template: x}}\n",
+			fields: []string{"steps[0].depends_on[0]"},
+		},
+		{
+			name: "cycle",
+			source: "name: x\nmode: dag\nsteps:\n" +
+				"  - {id: a, type: transform, depends_on: [b], transform: {template: x}}\n" +
+				"  - {id: b, type: transform, depends_on: [a], transform: {template: x}}\n",
+			fields: []string{"steps[0].depends_on"},
+		},
+		{
+			name: "reference_to_non_ancestor",
+			source: "name: x\nmode: dag\nsteps:\n" +
+				"  - {id: a, type: transform, transform: {template: x}}\n" +
+				"  - {id: b, type: transform, inputs: {v: steps.a.output}, transform: {template: x}}\n",
+			fields: []string{"steps[1].inputs.v"},
+		},
+		{
+			name: "undeclared_input_and_bad_output",
+			source: "name: x\noutputs: [{name: o, type: string, from: steps.a}]\nsteps:\n" +
+				"  - {id: a, type: transform, inputs: {v: inputs.missing}, transform: {template: x}}\n",
+			fields: []string{"steps[0].inputs.v", "outputs[0].from"},
+		},
+		{
+			name: "branch_target_not_dependent",
+			source: "name: x\nmode: dag\nsteps:\n" +
+				"  - {id: r, type: branch, branch: {cases: [{name: c, when: 'true', then: [a]}]}}\n" +
+				"  - {id: a, type: transform, transform: {template: x}}\n",
+			fields: []string{"steps[0].branch.cases[0].then[0]"},
+		},
+		{
+			name: "sub_flow_calls_itself",
+			source: "name: x\nsteps:\n" +
+				"  - {id: a, type: flow, flow: {name: x}}\n",
+			fields: []string{"steps[0].flow.name"},
+		},
+		{
+			name: "template_reads_unbound_name",
+			source: "name: x\nsteps:\n" +
+				"  - {id: a, type: transform, transform: {template: '{{ .missing }}'}}\n" +
+				"  - {id: b, type: prompt, prompt: {model: m, system: '{{ .s }}', user: '{{ $.u }}'}}\n",
+			fields: []string{"steps[0].transform.template", "steps[1].prompt.system", "steps[1].prompt.user"},
+		},
+		{
+			name: "template_does_not_parse",
+			source: "name: x\nsteps:\n" +
+				"  - {id: a, type: transform, transform: {template: '{{ if }}'}}\n",
+			fields: []string{"steps[0].transform.template"},
+		},
+		{
+			name: "inline_prompt_variables_not_bound",
+			source: "name: x\ninputs: [{name: t, type: string}]\nsteps:\n" +
+				"  - id: a\n    type: prompt\n    inputs: {t: inputs.t, extra: inputs.t}\n" +
+				"    prompt:\n      model: m\n      user: '{{ .t }} {{ .n }} {{ .undeclared }}'\n" +
+				"      variables: [{name: t, type: string}, {name: n, type: integer, required: true}]\n",
+			fields: []string{"steps[0].prompt.user", "steps[0].inputs", "steps[0].inputs.extra"},
+		},
+		{
+			name: "stored_prompt_with_inline_template",
+			source: "name: x\nsteps:\n" +
+				"  - {id: a, type: prompt, prompt: {model: m, prompt: p, user: hi}}\n",
+			fields: []string{"steps[0].prompt.prompt"},
+		},
+		{
+			name: "stored_prompt_pinned_and_labelled",
+			source: "name: x\nsteps:\n" +
+				"  - {id: a, type: prompt, prompt: {model: m, prompt: p, revision: 2, label: production}}\n" +
+				"  - {id: b, type: prompt, prompt: {model: m, user: hi, revision: 1, label: production}}\n",
+			fields: []string{"steps[0].prompt.revision", "steps[1].prompt.revision", "steps[1].prompt.label"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			flow, err := NewFlow(
+				WithFlowID(uuid.New()),
+				WithFlowAccountID(uuid.New()),
+				WithFlowSource(tt.source, FlowSourceFormatYAML),
+			)
+			require.Error(t, err)
+			assert.Nil(t, flow)
+
+			fieldErrors, ok := validator.FieldErrors(err)
+			require.True(t, ok, err.Error())
+			fields := make([]string, 0, len(fieldErrors))
+			for _, f := range fieldErrors {
+				fields = append(fields, f.Field)
+			}
+			assert.ElementsMatch(t, tt.fields, fields)
+		})
+	}
+}
+
+func TestNewFlowIfUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	flow, err := NewFlow(
+		WithFlowID(uuid.New()),
+		WithFlowAccountID(uuid.New()),
+		WithFlowSource(testFlowYAML, FlowSourceFormat("toml")),
+	)
+
+	assert.Error(t, err)
+	assert.Nil(t, flow)
+}
+
+func TestParseReference(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		ref     string
+		want    Reference
+		wantErr bool
+	}{
+		{ref: "inputs.text", want: Reference{Source: ReferenceSourceInputs, Name: "text", Path: []string{}}},
+		{ref: "steps.a.output", want: Reference{Source: ReferenceSourceSteps, Name: "a", Path: []string{}}},
+		{
+			ref:  "steps.a.output.title.text",
+			want: Reference{Source: ReferenceSourceSteps, Name: "a", Path: []string{"title", "text"}},
+		},
+		{ref: "inputs", wantErr: true},
+		{ref: "steps.a", wantErr: true},
+		{ref: "steps.a.usage", wantErr: true},
+		{ref: "outputs.x", wantErr: true},
+		{ref: "inputs..x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.ReplaceAll(tt.ref, ".", "_"), func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseReference(tt.ref)
+
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidReference)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
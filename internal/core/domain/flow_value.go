@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"flow-run/internal/lib/validator"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidPath = errors.New("invalid path")
+
+// Accepts reports whether v, as decoded from JSON or passed by a Go caller,
+// is a value of type t. nil is never accepted.
+func (t ValueType) Accepts(v any) bool {
+	switch t {
+	case ValueTypeString:
+		_, ok := v.(string)
+		return ok
+	case ValueTypeBoolean:
+		_, ok := v.(bool)
+		return ok
+	case ValueTypeNumber:
+		_, ok := toFloat(v)
+		return ok
+	case ValueTypeInteger:
+		f, ok := toFloat(v)
+		return ok && f == math.Trunc(f)
+	case ValueTypeObject:
+		_, ok := v.(map[string]any)
+		return ok
+	case ValueTypeArray:
+		_, ok := v.([]any)
+		return ok
+	default:
+		return false
+	}
+}
+
+// ResolveFlowInputs checks inputs against the declared flow inputs and fills
+// in defaults. Unknown inputs are rejected so a typo does not silently drop a
+// value.
+func ResolveFlowInputs(d *FlowDefinition, inputs map[string]any) (map[string]any, error) {
+	return resolveVariables(d.Inputs, inputs, "inputs.", "input")
+}
+
+// resolveVariables checks values against declared variables and fills in
+// defaults. prefix and kind name the values in field errors.
+func resolveVariables(declared []FlowVariable, values map[string]any, prefix, kind string) (map[string]any, error) {
+	var fields []validator.FieldError
+	resolved := make(map[string]any, len(declared))
+
+	names := make(map[string]bool, len(declared))
+	for _, variable := range declared {
+		names[variable.Name] = true
+		field := prefix + variable.Name
+
+		value, ok := values[variable.Name]
+		if !ok || value == nil {
+			switch {
+			case variable.Default != nil:
+				resolved[variable.Name] = variable.Default
+			case variable.Required:
+				fields = append(fields, validator.FieldError{
+					Field: field, Tag: "required", Message: fmt.Sprintf("%s %q is required", kind, variable.Name),
+				})
+			}
+			continue
+		}
+
+		if !variable.Type.Accepts(value) {
+			fields = append(fields, validator.FieldError{
+				Field: field, Tag: "type", Param: string(variable.Type),
+				Message: fmt.Sprintf("%s %q must be of type %s", kind, variable.Name, variable.Type),
+			})
+			continue
+		}
+		resolved[variable.Name] = value
+	}
+
+	unknown := make([]string, 0)
+	for name := range values {
+		if !names[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		fields = append(fields, validator.FieldError{
+			Field: prefix + name, Tag: "declared", Message: fmt.Sprintf("%s %q is not declared", kind, name),
+		})
+	}
+
+	if len(fields) > 0 {
+		return nil, &validator.Error{Fields: fields}
+	}
+	return resolved, nil
+}
+
+// LookupPath walks path through nested objects. An empty path returns value.
+func LookupPath(value any, path []string) (any, error) {
+	for i, key := range path {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: cannot read %q of a non-object value", ErrInvalidPath, strings.Join(path[:i+1], "."))
+		}
+		value = object[key]
+	}
+	return value, nil
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"encoding/json"
+	"flow-run/internal/lib/validator"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValueTypeAccepts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		typ   ValueType
+		value any
+		want  bool
+	}{
+		{typ: ValueTypeString, value: "x", want: true},
+		{typ: ValueTypeString, value: 1.0, want: false},
+		{typ: ValueTypeNumber, value: 1.5, want: true},
+		{typ: ValueTypeNumber, value: json.Number("2.5"), want: true},
+		{typ: ValueTypeInteger, value: 2.0, want: true},
+		{typ: ValueTypeInteger, value: 7, want: true},
+		{typ: ValueTypeInteger, value: 2.5, want: false},
+		{typ: ValueTypeBoolean, value: true, want: true},
+		{typ: ValueTypeObject, value: map[string]any{}, want: true},
+		{typ: ValueTypeArray, value: []any{1.0}, want: true},
+		{typ: ValueTypeArray, value: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.typ.Accepts(tt.value), "%s accepts %#v", tt.typ, tt.value)
+	}
+}
+
+func TestResolveFlowInputs(t *testing.T) {
+	t.Parallel()
+
+	definition := &FlowDefinition{Inputs: []FlowVariable{
+		{Name: "text", Type: ValueTypeString, Required: true},
+		{Name: "limit", Type: ValueTypeInteger, Default: 10.0},
+		{Name: "tags", Type: ValueTypeArray},
+	}}
+
+	resolved, err := ResolveFlowInputs(definition, map[string]any{"text": "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"text": "hi", "limit": 10.0}, resolved)
+
+	_, err = ResolveFlowInputs(definition, map[string]any{"limit": "ten", "extra": 1.0})
+	fields, ok := validator.FieldErrors(err)
+	require.True(t, ok)
+
+	tags := map[string]string{}
+	for _, f := range fields {
+		tags[f.Field] = f.Tag
+	}
+	assert.Equal(t, map[string]string{
+		"inputs.text":  "required",
+		"inputs.limit": "type",
+		"inputs.extra": "declared",
+	}, tags)
+}
+
+func TestLookupPath(t *testing.T) {
+	t.Parallel()
+
+	value := map[string]any{"title": map[string]any{"text": "hello"}}
+
+	got, err := LookupPath(value, []string{"title", "text"})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", got)
+
+	got, err = LookupPath(value, []string{"missing"})
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	_, err = LookupPath(value, []string{"title", "text", "more"})
+	assert.ErrorIs(t, err, ErrInvalidPath)
+}
@@ -0,0 +1,191 @@
+package domain
+
+import (
+	"encoding/json"
+	"flow-run/internal/lib/validator"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobKind names the work a job does; workers claim the kinds they handle.
+type JobKind string
+
+const (
+	// JobKindFlowRun executes a submitted flow run, see FlowRunJob.
+	JobKindFlowRun = JobKind("flow_run")
+	// JobKindTestSuiteRun executes a submitted test suite run, see
+	// TestSuiteRunJob.
+	JobKindTestSuiteRun = JobKind("test_suite_run")
+)
+
+type JobStatus string
+
+const (
+	JobStatusQueued    = JobStatus("queued")
+	JobStatusRunning   = JobStatus("running")
+	JobStatusSucceeded = JobStatus("succeeded")
+	// JobStatusDead is the dead letter: the job failed or lost its lease on
+	// its last attempt and is not retried.
+	JobStatusDead = JobStatus("dead")
+)
+
+// defaultJobMaxAttempts is how often a job is tried unless it says otherwise.
+const defaultJobMaxAttempts = 3
+
+// Job is background work in the durable queue. Queued jobs are claimed by
+// priority, highest first, once RunAt has passed. A claim leases the job to
+// one worker until LockedUntil, which the worker extends while it works; a
+// job whose lease expires is claimed again, so work must tolerate running
+// more than once. Attempts counts the claims. SubjectID is what the job
+// works on, such as the flow run it executes.
+type Job struct {
+	ID          uuid.UUID       `json:"id" validate:"required"`
+	Kind        JobKind         `json:"kind" validate:"required"`
+	SubjectID   *uuid.UUID      `json:"subject_id,omitempty"`
+	Payload     json.RawMessage `json:"payload"`
+	Priority    int             `json:"priority"`
+	Status      JobStatus       `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts" validate:"min=1,max=100"`
+	RunAt       time.Time       `json:"run_at"`
+	LockedBy    string          `json:"locked_by,omitempty"`
+	LockedUntil *time.Time      `json:"locked_until,omitempty"`
+	LastError   string          `json:"last_error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+}
+
+type JobOpt func(*Job)
+
+func WithJobSubjectID(id uuid.UUID) JobOpt {
+	return func(j *Job) {
+		j.SubjectID = &id
+	}
+}
+
+func WithJobPriority(priority int) JobOpt {
+	return func(j *Job) {
+		j.Priority = priority
+	}
+}
+
+func WithJobMaxAttempts(attempts int) JobOpt {
+	return func(j *Job) {
+		j.MaxAttempts = attempts
+	}
+}
+
+// WithJobRunAt delays the job until t.
+func WithJobRunAt(t time.Time) JobOpt {
+	return func(j *Job) {
+		j.RunAt = t
+	}
+}
+
+// NewJob queues work of kind described by payload, which is stored as JSON.
+func NewJob(kind JobKind, payload any, now time.Time, opts ...JobOpt) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	j := &Job{
+		ID:          uuid.New(),
+		Kind:        kind,
+		Payload:     data,
+		Status:      JobStatusQueued,
+		MaxAttempts: defaultJobMaxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return validator.Struct(j)
+}
+
+// Decode unmarshals the payload into v.
+func (j *Job) Decode(v any) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// Lease claims the job for worker until until, as another attempt.
+func (j *Job) Lease(worker string, until time.Time) {
+	j.Status = JobStatusRunning
+	j.Attempts++
+	j.LockedBy = worker
+	j.LockedUntil = &until
+}
+
+// Expired reports whether the job was claimed by a worker that stopped
+// extending its lease.
+func (j *Job) Expired(now time.Time) bool {
+	return j.Status == JobStatusRunning && j.LockedUntil != nil && !j.LockedUntil.After(now)
+}
+
+// Extend keeps the job leased until until.
+func (j *Job) Extend(until time.Time) {
+	j.LockedUntil = &until
+}
+
+func (j *Job) Succeed(now time.Time) {
+	j.Status = JobStatusSucceeded
+	j.LockedUntil = nil
+	j.FinishedAt = &now
+}
+
+// Fail records why the attempt failed and queues the job again at retryAt,
+// or moves it to the dead letter when it has no attempts left. LockedBy is
+// kept, naming the worker that made the last attempt.
+func (j *Job) Fail(message string, now, retryAt time.Time) {
+	j.LastError = message
+	j.LockedUntil = nil
+	if j.Attempts >= j.MaxAttempts {
+		j.Status = JobStatusDead
+		j.FinishedAt = &now
+		return
+	}
+	j.Status = JobStatusQueued
+	j.RunAt = retryAt
+}
+
+// FlowRunJob is the payload of JobKindFlowRun jobs. It carries the flow as
+// it was when the run was submitted, so that editing the flow meanwhile does
+// not change what the run executes.
+type FlowRunJob struct {
+	RunID      uuid.UUID        `json:"run_id"`
+	FlowID     uuid.UUID        `json:"flow_id"`
+	AccountID  uuid.UUID        `json:"account_id"`
+	FlowFormat FlowSourceFormat `json:"flow_format"`
+	FlowSource string           `json:"flow_source"`
+}
+
+func NewFlowRunJob(run *FlowRun, flow *Flow) *FlowRunJob {
+	return &FlowRunJob{
+		RunID:      run.ID,
+		FlowID:     flow.ID,
+		AccountID:  flow.AccountID,
+		FlowFormat: flow.Format,
+		FlowSource: flow.Source,
+	}
+}
+
+// Flow parses the flow the run was submitted with.
+func (p *FlowRunJob) Flow() (*Flow, error) {
+	return NewFlow(
+		WithFlowID(p.FlowID),
+		WithFlowAccountID(p.AccountID),
+		WithFlowSource(p.FlowSource, p.FlowFormat),
+	)
+}
+
+// TestSuiteRunJob is the payload of JobKindTestSuiteRun jobs. It carries the
+// suite as it was when the run was submitted, as FlowRunJob carries the flow.
+type TestSuiteRunJob struct {
+	RunID uuid.UUID  `json:"run_id"`
+	Suite *TestSuite `json:"suite"`
+}
+
+func NewTestSuiteRunJob(run *TestSuiteRun, suite *TestSuite) *TestSuiteRunJob {
+	return &TestSuiteRunJob{RunID: run.ID, Suite: suite}
+}
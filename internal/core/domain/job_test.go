@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobFail(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	retryAt := now.Add(time.Minute)
+
+	tests := []struct {
+		name       string
+		attempts   int
+		wantStatus JobStatus
+		wantRunAt  time.Time
+		finished   bool
+	}{
+		{name: "retried", attempts: 1, wantStatus: JobStatusQueued, wantRunAt: retryAt},
+		{name: "last_attempt", attempts: 2, wantStatus: JobStatusDead, wantRunAt: now, finished: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			job, err := NewJob(JobKindFlowRun, nil, now, WithJobMaxAttempts(2))
+			require.NoError(t, err)
+			for range tt.attempts {
+				job.Lease("w", now.Add(time.Minute))
+			}
+
+			job.Fail("unavailable", now, retryAt)
+
+			assert.Equal(t, tt.wantStatus, job.Status)
+			assert.Equal(t, tt.wantRunAt, job.RunAt)
+			assert.Equal(t, "unavailable", job.LastError)
+			assert.Equal(t, "w", job.LockedBy)
+			assert.Nil(t, job.LockedUntil)
+			assert.Equal(t, tt.finished, job.FinishedAt != nil)
+		})
+	}
+}
+
+func TestJobExpired(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	job, err := NewJob(JobKindFlowRun, nil, now)
+	require.NoError(t, err)
+	assert.False(t, job.Expired(now))
+
+	job.Lease("w", now.Add(time.Minute))
+	assert.False(t, job.Expired(now))
+	assert.True(t, job.Expired(now.Add(time.Minute)))
+
+	job.Succeed(now)
+	assert.False(t, job.Expired(now.Add(time.Hour)))
+}
+
+func TestNewJobIfInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewJob(JobKindFlowRun, nil, time.Now(), WithJobMaxAttempts(0))
+	require.Error(t, err)
+	_, err = NewJob("", nil, time.Now())
+	require.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidJSONPath = errors.New("invalid JSON path")
+
+// JSONPath is a parsed path such as "$.items[0].name": a chain of object keys
+// and array indexes below the root. Segments are strings for keys and ints
+// for indexes. Wildcards, filters and slices are not supported.
+type JSONPath []any
+
+// ParseJSONPath parses a path made of "$" followed by ".key", "['key']" and
+// "[index]" segments.
+func ParseJSONPath(path string) (JSONPath, error) {
+	rest, ok := strings.CutPrefix(path, "$")
+	if !ok {
+		return nil, fmt.Errorf("%w %q: must start with $", ErrInvalidJSONPath, path)
+	}
+
+	parsed := JSONPath{}
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("%w %q: empty key", ErrInvalidJSONPath, path)
+			}
+			parsed = append(parsed, rest[:end])
+			rest = rest[end:]
+		case strings.HasPrefix(rest, "['"):
+			end := strings.Index(rest, "']")
+			if end < 0 {
+				return nil, fmt.Errorf("%w %q: unterminated key", ErrInvalidJSONPath, path)
+			}
+			parsed = append(parsed, rest[2:end])
+			rest = rest[end+2:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("%w %q: unterminated index", ErrInvalidJSONPath, path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("%w %q: bad index %q", ErrInvalidJSONPath, path, rest[1:end])
+			}
+			parsed = append(parsed, index)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("%w %q: unexpected %q", ErrInvalidJSONPath, path, rest)
+		}
+	}
+	return parsed, nil
+}
+
+// Lookup returns the value at the path in a decoded JSON document. found is
+// false when a key or index does not exist.
+func (p JSONPath) Lookup(document any) (value any, found bool) {
+	value = document
+	for _, segment := range p {
+		switch s := segment.(type) {
+		case string:
+			object, ok := value.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if value, ok = object[s]; !ok {
+				return nil, false
+			}
+		case int:
+			array, ok := value.([]any)
+			if !ok || s >= len(array) {
+				return nil, false
+			}
+			value = array[s]
+		}
+	}
+	return value, true
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONPathLookup(t *testing.T) {
+	t.Parallel()
+
+	var document any
+	require.NoError(t, json.Unmarshal([]byte(`{"items": [{"name": "a"}, {"name": "b"}], "a.b": 1}`), &document))
+
+	tests := []struct {
+		path  string
+		want  any
+		found bool
+	}{
+		{path: "$", want: document, found: true},
+		{path: "$.items[1].name", want: "b", found: true},
+		{path: "$['a.b']", want: 1.0, found: true},
+		{path: "$.items[2]", found: false},
+		{path: "$.missing", found: false},
+		{path: "$.items.name", found: false},
+	}
+
+	for _, tt := range tests {
+		path, err := ParseJSONPath(tt.path)
+		require.NoError(t, err, tt.path)
+		got, found := path.Lookup(document)
+		assert.Equal(t, tt.found, found, tt.path)
+		assert.Equal(t, tt.want, got, tt.path)
+	}
+}
+
+func TestParseJSONPathIfInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, path := range []string{"", "items", "$.", "$..a", "$[x]", "$[-1]", "$[0", "$['a'", "$a"} {
+		_, err := ParseJSONPath(path)
+		require.ErrorIs(t, err, ErrInvalidJSONPath, path)
+	}
+}
@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"flow-run/internal/lib/validator"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JudgeToolName is the tool judge models call to record their verdict.
+const JudgeToolName = "record_verdict"
+
+// defaultJudgeThreshold is the lowest passing score of judge assertions
+// that set no threshold.
+const defaultJudgeThreshold = 0.5
+
+var ErrInvalidVerdict = errors.New("invalid judge verdict")
+
+const judgeSystemPrompt = `You are an impartial evaluator grading the output of an AI system against a rubric.
+Read the rubric, the inputs the system was given and the output it produced, then record your verdict by calling
+the ` + JudgeToolName + ` tool. Give a score between 0 and 1, where 1 fully meets the rubric, or pass true or false
+when the rubric asks for a pass/fail decision, and explain your reasoning in one or two sentences. If you cannot
+call tools, answer with the same JSON object and nothing else.`
+
+var judgeVerdictSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"score": {"type": "number", "minimum": 0, "maximum": 1},
+		"pass": {"type": "boolean"},
+		"reasoning": {"type": "string"}
+	},
+	"required": ["reasoning"]
+}`)
+
+// JudgeVerdict is the structured answer of a judge model: a score between 0
+// and 1, a pass/fail decision, or both, with the reasoning behind it.
+type JudgeVerdict struct {
+	Score     *float64 `json:"score" validate:"required_without=Pass,omitempty,min=0,max=1"`
+	Pass      *bool    `json:"pass"`
+	Reasoning string   `json:"reasoning"`
+}
+
+// Judgement is the outcome of asking a judge model to grade a case: its
+// verdict, or the error that kept it from giving one. Usage and Cost are the
+// judge's own and are accounted apart from the case's.
+type Judgement struct {
+	Verdict *JudgeVerdict
+	Err     error
+	Usage   TokenUsage
+	Cost    Money
+}
+
+// JudgeTool is the tool offered to judge models to record their verdict.
+func JudgeTool() Tool {
+	return Tool{
+		Name:        JudgeToolName,
+		Description: "Record the verdict on the graded output.",
+		InputSchema: judgeVerdictSchema,
+	}
+}
+
+// JudgeMessages builds the conversation asking a judge to grade output,
+// produced from the inputs of testCase, against the assertion's rubric. The
+// case's expected output, when set, is shown as a reference.
+func JudgeMessages(a *Assertion, testCase *TestCase, output any) []Message {
+	var user strings.Builder
+	user.WriteString("Rubric:\n")
+	user.WriteString(strings.TrimSpace(a.Rubric))
+	if len(testCase.Inputs) > 0 {
+		// Inputs are decoded JSON values, which always marshal.
+		encoded, _ := json.MarshalIndent(testCase.Inputs, "", "  ")
+		user.WriteString("\n\nInputs:\n")
+		user.Write(encoded)
+	}
+	if testCase.Expected != nil {
+		user.WriteString("\n\nExpected output:\n")
+		user.WriteString(outputText(testCase.Expected))
+	}
+	user.WriteString("\n\nOutput:\n")
+	user.WriteString(outputText(output))
+
+	return []Message{
+		{Role: MessageRoleSystem, Content: judgeSystemPrompt},
+		{Role: MessageRoleUser, Content: user.String()},
+	}
+}
+
+// ParseJudgeVerdict reads the verdict from a judge's response: the arguments
+// of its record_verdict call or, for models that answered in text, the JSON
+// object of its message.
+func ParseJudgeVerdict(resp *CompletionResponse) (*JudgeVerdict, error) {
+	text := resp.Message.Content
+	for _, call := range resp.Message.ToolCalls {
+		if call.Name == JudgeToolName {
+			text = string(call.Arguments)
+			break
+		}
+	}
+
+	var verdict JudgeVerdict
+	if err := json.Unmarshal([]byte(stripCodeFence(strings.TrimSpace(text))), &verdict); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidVerdict, err)
+	}
+	if _, err := validator.Struct(&verdict); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidVerdict, err)
+	}
+	return &verdict, nil
+}
+
+// Grade checks a judge assertion against the judge's judgement. A judge
+// that failed to answer fails the assertion.
+func (a *Assertion) Grade(j *Judgement) AssertionResult {
+	result := AssertionResult{Type: a.Type}
+	switch {
+	case j == nil:
+		result.Message = "the case was not graded"
+		return result
+	case j.Err != nil:
+		result.Message = "judge failed: " + j.Err.Error()
+		return result
+	}
+
+	score := 0.0
+	switch {
+	case j.Verdict.Score != nil:
+		score = *j.Verdict.Score
+	case *j.Verdict.Pass:
+		score = 1
+	}
+	threshold := a.Threshold
+	if threshold == 0 {
+		threshold = defaultJudgeThreshold
+	}
+
+	result.Score = &score
+	result.Reasoning = j.Verdict.Reasoning
+	result.Passed = score >= threshold
+	if !result.Passed {
+		result.Message = fmt.Sprintf("score %s is below the threshold of %s", formatScore(score), formatScore(threshold))
+	}
+	return result
+}
+
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJudgeVerdict(t *testing.T) {
+	t.Parallel()
+
+	score := 0.75
+	pass := false
+	tests := []struct {
+		name string
+		resp *CompletionResponse
+		want *JudgeVerdict
+	}{
+		{
+			name: "tool call",
+			resp: &CompletionResponse{Message: Message{ToolCalls: []ToolCall{
+				{ID: "1", Name: JudgeToolName, Arguments: json.RawMessage(`{"score": 0.75, "reasoning": "mostly"}`)},
+			}}},
+			want: &JudgeVerdict{Score: &score, Reasoning: "mostly"},
+		},
+		{
+			name: "fenced text",
+			resp: &CompletionResponse{Message: Message{Content: "```json\n{\"pass\": false, \"reasoning\": \"rude\"}\n```"}},
+			want: &JudgeVerdict{Pass: &pass, Reasoning: "rude"},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseJudgeVerdict(tt.resp)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.want, got, tt.name)
+	}
+
+	for _, content := range []string{"looks good", `{"reasoning": "no score"}`, `{"score": 7, "reasoning": "x"}`} {
+		_, err := ParseJudgeVerdict(&CompletionResponse{Message: Message{Content: content}})
+		require.ErrorIs(t, err, ErrInvalidVerdict, content)
+	}
+}
+
+func TestAssertionGrade(t *testing.T) {
+	t.Parallel()
+
+	low, high := 0.4, 0.6
+	pass, fail := true, false
+	tests := []struct {
+		threshold float64
+		judgement *Judgement
+		passed    bool
+	}{
+		{judgement: &Judgement{Verdict: &JudgeVerdict{Score: &high}}, passed: true},
+		{judgement: &Judgement{Verdict: &JudgeVerdict{Score: &low}}, passed: false},
+		{threshold: 0.7, judgement: &Judgement{Verdict: &JudgeVerdict{Score: &high}}, passed: false},
+		{threshold: 1, judgement: &Judgement{Verdict: &JudgeVerdict{Pass: &pass}}, passed: true},
+		{judgement: &Judgement{Verdict: &JudgeVerdict{Pass: &fail}}, passed: false},
+		{judgement: &Judgement{Err: errors.New("judge unavailable")}, passed: false},
+		{judgement: nil, passed: false},
+	}
+
+	for i, tt := range tests {
+		a := &Assertion{Type: AssertionTypeJudge, Model: "judge", Rubric: "r", Threshold: tt.threshold}
+		result := a.Grade(tt.judgement)
+		assert.Equal(t, tt.passed, result.Passed, "case %d: %s", i, result.Message)
+		if !tt.passed {
+			assert.NotEmpty(t, result.Message, "case %d", i)
+		}
+	}
+}
+
+func TestJudgeMessages(t *testing.T) {
+	t.Parallel()
+
+	a := &Assertion{Type: AssertionTypeJudge, Model: "judge", Rubric: "  Is it polite?  "}
+	messages := JudgeMessages(a, &TestCase{Inputs: map[string]any{"name": "Ada"}}, map[string]any{"greeting": "Hi"})
+
+	require.Len(t, messages, 2)
+	assert.Equal(t, MessageRoleSystem, messages[0].Role)
+	assert.Contains(t, messages[0].Content, JudgeToolName)
+	assert.Equal(t,
+		"Rubric:\nIs it polite?\n\nInputs:\n{\n  \"name\": \"Ada\"\n}\n\nOutput:\n{\"greeting\":\"Hi\"}",
+		messages[1].Content)
+
+	messages = JudgeMessages(a, &TestCase{Expected: "Hello, Ada"}, "Hi")
+	assert.Equal(t, "Rubric:\nIs it polite?\n\nExpected output:\nHello, Ada\n\nOutput:\nHi", messages[1].Content)
+}
@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flow-run/internal/lib/validator"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type MockErrorKind string
+
+const (
+	MockErrorKindAuthentication = MockErrorKind("authentication")
+	MockErrorKindRateLimited    = MockErrorKind("rate_limited")
+	MockErrorKindQuotaExceeded  = MockErrorKind("quota_exceeded")
+	MockErrorKindInvalidRequest = MockErrorKind("invalid_request")
+	MockErrorKindUnavailable    = MockErrorKind("unavailable")
+)
+
+var ErrInvalidMockScript = errors.New("invalid mock script")
+
+// MockScript is the credential of a mock provider. Rules are tried in order
+// and the first one matching the request answers it; a request no rule
+// matches is an error, never a default reply.
+type MockScript struct {
+	Usage MockUsage  `json:"usage"`
+	Rules []MockRule `json:"rules" validate:"required,min=1,dive"`
+}
+
+// MockRule matches either the SHA-256 of the rendered prompt (see MockPrompt)
+// or a regular expression over it. Model optionally narrows the rule to one
+// model name. When Error is set the call fails after Latency instead of
+// returning Response.
+type MockRule struct {
+	Model        string       `json:"model,omitempty"`
+	PromptSHA256 string       `json:"prompt_sha256,omitempty" validate:"required_without=Regex,excluded_with=Regex,omitempty,len=64,hexadecimal"` //nolint:lll
+	Regex        string       `json:"regex,omitempty" validate:"required_without=PromptSHA256"`
+	Response     string       `json:"response"`
+	ToolCalls    []ToolCall   `json:"tool_calls,omitempty" validate:"dive"`
+	FinishReason FinishReason `json:"finish_reason,omitempty" validate:"omitempty,oneof=stop length tool_calls content_filter"` //nolint:lll
+	Usage        *MockUsage   `json:"usage,omitempty"`
+	Latency      MockLatency  `json:"latency,omitempty" validate:"min=0"`
+	Error        *MockError   `json:"error,omitempty"`
+}
+
+// MockUsage is the fixed token count reported for a matched call. Rules
+// without their own usage fall back to the script-level one. CacheReadTokens
+// are part of PromptTokens.
+type MockUsage struct {
+	PromptTokens     int `json:"prompt_tokens" validate:"min=0"`
+	CompletionTokens int `json:"completion_tokens" validate:"min=0"`
+	CacheReadTokens  int `json:"cache_read_tokens" validate:"min=0,ltefield=PromptTokens"`
+}
+
+type MockError struct {
+	Kind    MockErrorKind `json:"kind" validate:"oneof=authentication rate_limited quota_exceeded invalid_request unavailable"` //nolint:lll
+	Message string        `json:"message"`
+}
+
+// MockLatency is a time.Duration written as a Go duration string ("250ms").
+type MockLatency time.Duration
+
+func (l *MockLatency) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("latency must be a duration string: %w", err)
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("latency: %w", err)
+	}
+	*l = MockLatency(d)
+	return nil
+}
+
+func (l MockLatency) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(l).String())
+}
+
+// ParseMockScript decodes and validates a script. Unknown fields are
+// rejected so a typo cannot silently turn into a rule that never matches.
+func ParseMockScript(script string) (*MockScript, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(script)))
+	decoder.DisallowUnknownFields()
+
+	s := &MockScript{}
+	if err := decoder.Decode(s); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidMockScript, err)
+	}
+	if _, err := validator.Struct(s); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidMockScript, err)
+	}
+	for i, rule := range s.Rules {
+		if rule.Regex == "" {
+			continue
+		}
+		if _, err := regexp.Compile(rule.Regex); err != nil {
+			return nil, fmt.Errorf("%w: rules[%d].regex: %w", ErrInvalidMockScript, i, err)
+		}
+	}
+	return s, nil
+}
+
+// MockPrompt renders messages into the text mock rules match against: one
+// "<role>: <content>" entry per message, joined by newlines.
+func MockPrompt(messages []Message) string {
+	parts := make([]string, 0, len(messages))
+	for _, m := range messages {
+		parts = append(parts, string(m.Role)+": "+m.Content)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// MockPromptHash is the hex SHA-256 of MockPrompt, the key used by
+// prompt_sha256 rules.
+func MockPromptHash(messages []Message) string {
+	sum := sha256.Sum256([]byte(MockPrompt(messages)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMockScriptIfValidInput(t *testing.T) {
+	t.Parallel()
+
+	script, err := ParseMockScript(`{
+		"usage": {"prompt_tokens": 12, "completion_tokens": 3},
+		"rules": [
+			{"regex": "^user: hi$", "response": "hello", "latency": "150ms"},
+			{
+				"prompt_sha256": "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae",
+				"error": {"kind": "rate_limited", "message": "slow down"}
+			}
+		]
+	}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, MockUsage{PromptTokens: 12, CompletionTokens: 3}, script.Usage)
+	require.Len(t, script.Rules, 2)
+	assert.Equal(t, MockLatency(150*time.Millisecond), script.Rules[0].Latency)
+	require.NotNil(t, script.Rules[1].Error)
+	assert.Equal(t, MockErrorKindRateLimited, script.Rules[1].Error.Kind)
+}
+
+func TestParseMockScriptIfInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		script string
+	}{
+		{name: "not_json", script: "sk-123"},
+		{name: "no_rules", script: `{"rules": []}`},
+		{name: "unknown_field", script: `{"rules": [{"regex": ".", "reply": "typo"}]}`},
+		{name: "no_matcher", script: `{"rules": [{"response": "hi"}]}`},
+		{name: "both_matchers", script: `{"rules": [{"regex": ".", "prompt_sha256": "` + MockPromptHash(nil) + `"}]}`},
+		{name: "short_hash", script: `{"rules": [{"prompt_sha256": "abc"}]}`},
+		{name: "invalid_regex", script: `{"rules": [{"regex": "("}]}`},
+		{name: "invalid_latency", script: `{"rules": [{"regex": ".", "latency": "soon"}]}`},
+		{name: "negative_latency", script: `{"rules": [{"regex": ".", "latency": "-1s"}]}`},
+		{name: "unknown_error_kind", script: `{"rules": [{"regex": ".", "error": {"kind": "boom"}}]}`},
+		{name: "negative_usage", script: `{"usage": {"prompt_tokens": -1}, "rules": [{"regex": "."}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			script, err := ParseMockScript(tt.script)
+
+			require.ErrorIs(t, err, ErrInvalidMockScript)
+			assert.Nil(t, script)
+		})
+	}
+}
+
+func TestMockPrompt(t *testing.T) {
+	t.Parallel()
+
+	messages := []Message{
+		{Role: MessageRoleSystem, Content: "Be brief"},
+		{Role: MessageRoleUser, Content: "hi"},
+	}
+
+	assert.Equal(t, "system: Be brief\nuser: hi", MockPrompt(messages))
+	assert.Len(t, MockPromptHash(messages), 64)
+	assert.NotEqual(t, MockPromptHash(messages), MockPromptHash(messages[1:]))
+}
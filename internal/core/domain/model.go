@@ -6,11 +6,21 @@ import (
 	"github.com/google/uuid"
 )
 
+// Model is a provider model callable by name. InputPrice and OutputPrice are
+// dollars per million prompt and completion tokens; zero means unpriced.
+// CachedPrice is dollars per million prompt tokens read from the provider's
+// cache; zero bills them at InputPrice. The prices are those currently in
+// effect in the model's pricing catalog, see ModelPrice. ContextWindow is the
+// number of tokens the model accepts, zero when unknown.
 type Model struct {
-	ID         uuid.UUID `json:"id" validate:"required"`
-	Name       string    `json:"name" validate:"required"`
-	AccountID  uuid.UUID `json:"account_id" validate:"required"`
-	ProviderID uuid.UUID `json:"provider_id" validate:"required"`
+	ID            uuid.UUID `json:"id" validate:"required"`
+	Name          string    `json:"name" validate:"required"`
+	AccountID     uuid.UUID `json:"account_id" validate:"required"`
+	ProviderID    uuid.UUID `json:"provider_id" validate:"required"`
+	InputPrice    Money     `json:"input_price" validate:"min=0"`
+	OutputPrice   Money     `json:"output_price" validate:"min=0"`
+	CachedPrice   Money     `json:"cached_price" validate:"min=0"`
+	ContextWindow int       `json:"context_window" validate:"min=0"`
 }
 
 type ModelOpt func(*Model)
@@ -39,6 +49,25 @@ func WithModelProviderID(providerID uuid.UUID) ModelOpt {
 	}
 }
 
+func WithModelPrices(input, output Money) ModelOpt {
+	return func(m *Model) {
+		m.InputPrice = input
+		m.OutputPrice = output
+	}
+}
+
+func WithModelCachedPrice(price Money) ModelOpt {
+	return func(m *Model) {
+		m.CachedPrice = price
+	}
+}
+
+func WithModelContextWindow(tokens int) ModelOpt {
+	return func(m *Model) {
+		m.ContextWindow = tokens
+	}
+}
+
 func NewModel(opts ...ModelOpt) (*Model, error) {
 	m := &Model{}
 	for _, opt := range opts {
@@ -46,3 +75,14 @@ func NewModel(opts ...ModelOpt) (*Model, error) {
 	}
 	return validator.Struct(m)
 }
+
+// Cost prices usage at the model's prices. Cache reads are part of the
+// prompt tokens and billed at CachedPrice when the model has one.
+func (m *Model) Cost(usage TokenUsage) Money {
+	output := m.OutputPrice.PerMillion(usage.CompletionTokens)
+	if m.CachedPrice == 0 || usage.CacheReadTokens == 0 {
+		return m.InputPrice.PerMillion(usage.PromptTokens) + output
+	}
+	cached := min(usage.CacheReadTokens, usage.PromptTokens)
+	return m.InputPrice.PerMillion(usage.PromptTokens-cached) + m.CachedPrice.PerMillion(cached) + output
+}
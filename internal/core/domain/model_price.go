@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"flow-run/internal/lib/validator"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PriceSource tells who set a catalog price.
+type PriceSource string
+
+const (
+	// PriceSourceManual prices were set by a user. They override synced
+	// prices for as long as they are in effect.
+	PriceSourceManual = PriceSource("manual")
+	// PriceSourceOpenRouter prices were synced from OpenRouter's models list.
+	PriceSourceOpenRouter = PriceSource("open_router")
+)
+
+// ModelPrice is an entry of a model's pricing catalog: the prices in effect
+// from EffectiveAt until a later entry of the same source takes over.
+// Entries are never changed, so the price of any past moment can be looked
+// up; see EffectivePrice. Prices are dollars per million tokens, as on
+// Model.
+type ModelPrice struct {
+	ID          uuid.UUID   `json:"id" validate:"required"`
+	ModelID     uuid.UUID   `json:"model_id" validate:"required"`
+	AccountID   uuid.UUID   `json:"account_id" validate:"required"`
+	Source      PriceSource `json:"source" validate:"oneof=manual open_router"`
+	InputPrice  Money       `json:"input_price" validate:"min=0"`
+	OutputPrice Money       `json:"output_price" validate:"min=0"`
+	CachedPrice Money       `json:"cached_price" validate:"min=0"`
+	EffectiveAt time.Time   `json:"effective_at" validate:"required"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+type ModelPriceOpt func(*ModelPrice)
+
+func WithModelPriceEffectiveAt(at time.Time) ModelPriceOpt {
+	return func(p *ModelPrice) {
+		p.EffectiveAt = at
+	}
+}
+
+// NewModelPrice creates a catalog entry for model from source with the
+// model's prices, effective now unless an option says otherwise.
+func NewModelPrice(model *Model, source PriceSource, now time.Time, opts ...ModelPriceOpt) (*ModelPrice, error) {
+	p := &ModelPrice{
+		ID:          uuid.New(),
+		ModelID:     model.ID,
+		AccountID:   model.AccountID,
+		Source:      source,
+		InputPrice:  model.InputPrice,
+		OutputPrice: model.OutputPrice,
+		CachedPrice: model.CachedPrice,
+		EffectiveAt: now,
+		CreatedAt:   now,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return validator.Struct(p)
+}
+
+// SamePrices reports whether p charges what other charges.
+func (p *ModelPrice) SamePrices(other *ModelPrice) bool {
+	return p.InputPrice == other.InputPrice && p.OutputPrice == other.OutputPrice &&
+		p.CachedPrice == other.CachedPrice
+}
+
+// EffectivePrice returns the entry of prices in effect at t: the latest
+// manual entry effective by then, else the latest synced one. It returns nil
+// when no entry is effective yet.
+func EffectivePrice(prices []*ModelPrice, t time.Time) *ModelPrice {
+	var manual, synced *ModelPrice
+	for _, p := range prices {
+		if p.EffectiveAt.After(t) {
+			continue
+		}
+		latest := &synced
+		if p.Source == PriceSourceManual {
+			latest = &manual
+		}
+		if *latest == nil || p.EffectiveAt.After((*latest).EffectiveAt) {
+			*latest = p
+		}
+	}
+	if manual != nil {
+		return manual
+	}
+	return synced
+}
+
+// ApplyPrice sets the model's prices to those of p.
+func (m *Model) ApplyPrice(p *ModelPrice) {
+	m.InputPrice = p.InputPrice
+	m.OutputPrice = p.OutputPrice
+	m.CachedPrice = p.CachedPrice
+}
+
+// CatalogModel is a model as listed by a provider's catalog, with its
+// prices in dollars per million tokens.
+type CatalogModel struct {
+	Name          string
+	ContextWindow int
+	InputPrice    Money
+	OutputPrice   Money
+	CachedPrice   Money
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEffectivePrice(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) time.Time {
+		return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	synced := &ModelPrice{Source: PriceSourceOpenRouter, InputPrice: 1, EffectiveAt: day(1)}
+	resynced := &ModelPrice{Source: PriceSourceOpenRouter, InputPrice: 2, EffectiveAt: day(10)}
+	manual := &ModelPrice{Source: PriceSourceManual, InputPrice: 3, EffectiveAt: day(5)}
+	scheduled := &ModelPrice{Source: PriceSourceManual, InputPrice: 4, EffectiveAt: day(20)}
+	prices := []*ModelPrice{scheduled, resynced, manual, synced}
+
+	tests := []struct {
+		name   string
+		prices []*ModelPrice
+		at     time.Time
+		want   *ModelPrice
+	}{
+		{name: "no_prices", at: day(1)},
+		{name: "before_first_price", prices: prices, at: day(1).Add(-time.Second)},
+		{name: "synced", prices: prices, at: day(3), want: synced},
+		{name: "manual_overrides_synced", prices: prices, at: day(5), want: manual},
+		{name: "manual_overrides_later_sync", prices: prices, at: day(15), want: manual},
+		{name: "scheduled_manual", prices: prices, at: day(20), want: scheduled},
+		{name: "latest_sync", prices: []*ModelPrice{synced, resynced}, at: day(15), want: resynced},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Same(t, tt.want, EffectivePrice(tt.prices, tt.at))
+		})
+	}
+}
@@ -159,7 +159,7 @@ func TestModelOptFunctions(t *testing.T) {
 
 		id := uuid.New()
 		model := &Model{}
-		
+
 		opt := WithModelID(id)
 		opt(model)
 
@@ -171,7 +171,7 @@ func TestModelOptFunctions(t *testing.T) {
 
 		name := faker.Name()
 		model := &Model{}
-		
+
 		opt := WithModelName(name)
 		opt(model)
 
@@ -183,7 +183,7 @@ func TestModelOptFunctions(t *testing.T) {
 
 		accountID := uuid.New()
 		model := &Model{}
-		
+
 		opt := WithModelAccountID(accountID)
 		opt(model)
 
@@ -195,7 +195,7 @@ func TestModelOptFunctions(t *testing.T) {
 
 		providerID := uuid.New()
 		model := &Model{}
-		
+
 		opt := WithModelProviderID(providerID)
 		opt(model)
 
@@ -331,4 +331,35 @@ func TestModelCreationWithRandomData(t *testing.T) {
 			assert.NotEqual(t, uuid.Nil, model.ProviderID)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestModelCost(t *testing.T) {
+	t.Parallel()
+
+	usage := TokenUsage{PromptTokens: 1_000_000, CompletionTokens: 500_000, CacheReadTokens: 400_000}
+	tests := []struct {
+		name  string
+		model Model
+		want  string
+	}{
+		{name: "unpriced", model: Model{}, want: "0"},
+		{
+			name:  "cache_reads_at_input_price",
+			model: Model{InputPrice: 2 * moneyScale, OutputPrice: 8 * moneyScale},
+			want:  "6",
+		},
+		{
+			name:  "cache_reads_at_cached_price",
+			model: Model{InputPrice: 2 * moneyScale, OutputPrice: 8 * moneyScale, CachedPrice: moneyScale / 2},
+			want:  "5.4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.model.Cost(usage).String())
+		})
+	}
+}
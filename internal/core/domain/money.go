@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// moneyDigits is the number of decimal places Money keeps. Nano-dollars are
+// fine enough to price a single cached token of the cheapest models.
+const moneyDigits = 9
+
+// moneyScale is the number of Money units in one dollar.
+const moneyScale = 1_000_000_000
+
+var ErrInvalidMoney = errors.New("invalid amount")
+
+// Money is an amount of US dollars in fixed point, counted in nano-dollars,
+// so sums of many small costs stay exact. It is written to JSON as a decimal
+// string such as "0.000125".
+type Money int64
+
+// ParseMoney parses a decimal dollar amount such as "12", "0.15" or "-1.5".
+// Amounts with more than nine decimal places are rejected rather than
+// rounded.
+func ParseMoney(s string) (Money, error) {
+	text := strings.TrimSpace(s)
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(text, "-")
+
+	whole, fraction, _ := strings.Cut(text, ".")
+	if whole == "" && fraction == "" || len(fraction) > moneyDigits {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	units, err := strconv.ParseUint(whole, 10, 63)
+	if err != nil || units > (1<<63-1)/moneyScale {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+	var nanos uint64
+	if fraction != "" {
+		nanos, err = strconv.ParseUint(fraction+strings.Repeat("0", moneyDigits-len(fraction)), 10, 63)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+		}
+	}
+
+	m := Money(units*moneyScale + nanos)
+	if negative {
+		m = -m
+	}
+	return m, nil
+}
+
+// String formats m as a decimal without trailing zeros, e.g. "0.000125".
+func (m Money) String() string {
+	sign := ""
+	abs := int64(m)
+	if abs < 0 {
+		sign, abs = "-", -abs
+	}
+
+	whole, fraction := abs/moneyScale, abs%moneyScale
+	if fraction == 0 {
+		return sign + strconv.FormatInt(whole, 10)
+	}
+	digits := strings.TrimRight(fmt.Sprintf("%0*d", moneyDigits, fraction), "0")
+	return sign + strconv.FormatInt(whole, 10) + "." + digits
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(m.String())), nil
+}
+
+// UnmarshalJSON accepts a decimal string or a JSON number.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+	parsed, err := ParseMoney(text)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// PerMillion returns the cost of tokens at m per million tokens, rounded half
+// up to the nearest nano-dollar.
+func (m Money) PerMillion(tokens int) Money {
+	const million = 1_000_000
+	// Splitting the price keeps the intermediate product far from overflowing.
+	whole, rest := int64(m)/million, int64(m)%million
+	return Money(whole*int64(tokens) + (rest*int64(tokens)+million/2)/million)
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		text string
+		want Money
+		str  string
+	}{
+		{text: "12", want: 12 * moneyScale, str: "12"},
+		{text: "0.15", want: 150_000_000, str: "0.15"},
+		{text: ".5", want: 500_000_000, str: "0.5"},
+		{text: "-1.5", want: -1_500_000_000, str: "-1.5"},
+		{text: "0.000000001", want: 1, str: "0.000000001"},
+		{text: " 3.10 ", want: 3_100_000_000, str: "3.1"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.text)
+		require.NoError(t, err, tt.text)
+		assert.Equal(t, tt.want, got, tt.text)
+		assert.Equal(t, tt.str, got.String(), tt.text)
+	}
+
+	for _, text := range []string{"", ".", "abc", "1.2.3", "0.0000000001", "1e3", "99999999999999"} {
+		_, err := ParseMoney(text)
+		require.ErrorIs(t, err, ErrInvalidMoney, text)
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	t.Parallel()
+
+	encoded, err := json.Marshal(Money(125_000))
+	require.NoError(t, err)
+	assert.JSONEq(t, `"0.000125"`, string(encoded))
+
+	var fromString, fromNumber Money
+	require.NoError(t, json.Unmarshal([]byte(`"2.5"`), &fromString))
+	require.NoError(t, json.Unmarshal([]byte(`2.5`), &fromNumber))
+	assert.Equal(t, Money(2_500_000_000), fromString)
+	assert.Equal(t, fromString, fromNumber)
+}
+
+func TestMoneyPerMillion(t *testing.T) {
+	t.Parallel()
+
+	price, err := ParseMoney("0.15")
+	require.NoError(t, err)
+
+	assert.Equal(t, Money(0), price.PerMillion(0))
+	assert.Equal(t, Money(150), price.PerMillion(1))
+	assert.Equal(t, price, price.PerMillion(1_000_000))
+	// Fractions of a nano-dollar round half up.
+	assert.Equal(t, Money(0), Money(1).PerMillion(3))
+	assert.Equal(t, Money(1), Money(1000).PerMillion(500))
+}
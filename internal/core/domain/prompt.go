@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"flow-run/internal/lib/validator"
+
+	"github.com/google/uuid"
+)
+
+// Prompt is a stored prompt template that prompt steps reference by name.
+// Template is the latest revision; Revision is its number and Author the
+// author of that revision. Older revisions are kept as PromptRevision.
+type Prompt struct {
+	ID          uuid.UUID      `json:"id" validate:"required"`
+	AccountID   uuid.UUID      `json:"account_id" validate:"required"`
+	Name        string         `json:"name" validate:"required,max=100"`
+	Description string         `json:"description,omitempty"`
+	Template    PromptTemplate `json:"template"`
+	Revision    int            `json:"revision"`
+	Author      string         `json:"author" validate:"required,max=100"`
+}
+
+type PromptOpt func(*Prompt)
+
+func WithPromptID(id uuid.UUID) PromptOpt {
+	return func(p *Prompt) {
+		p.ID = id
+	}
+}
+
+func WithPromptAccountID(accountID uuid.UUID) PromptOpt {
+	return func(p *Prompt) {
+		p.AccountID = accountID
+	}
+}
+
+func WithPromptName(name string) PromptOpt {
+	return func(p *Prompt) {
+		p.Name = name
+	}
+}
+
+func WithPromptDescription(description string) PromptOpt {
+	return func(p *Prompt) {
+		p.Description = description
+	}
+}
+
+// WithPromptAuthor sets who made the edit; it becomes the author of the
+// revision the edit creates.
+func WithPromptAuthor(author string) PromptOpt {
+	return func(p *Prompt) {
+		p.Author = author
+	}
+}
+
+func WithPromptTemplate(template PromptTemplate) PromptOpt {
+	return func(p *Prompt) {
+		p.Template = template
+	}
+}
+
+// NewPrompt validates the prompt, parses its templates and rejects references
+// to undeclared variables.
+func NewPrompt(opts ...PromptOpt) (*Prompt, error) {
+	p := &Prompt{}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p, err := validator.Struct(p)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &fieldChecker{}
+	if !flowNamePattern.MatchString(p.Name) {
+		c.fail("name", "prompt_name", "name %q may contain only letters, digits, '_', '.' and '-'", p.Name)
+	}
+	p.Template.check(c, "template.")
+	if err := c.err(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flow-run/internal/lib/diff"
+	"flow-run/internal/lib/validator"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PromptRevision is an immutable snapshot of a prompt template. Every edit
+// that changes the template adds one; revisions are numbered from 1 per
+// prompt.
+type PromptRevision struct {
+	ID          uuid.UUID      `json:"id" validate:"required"`
+	PromptID    uuid.UUID      `json:"prompt_id" validate:"required"`
+	AccountID   uuid.UUID      `json:"account_id" validate:"required"`
+	Number      int            `json:"revision" validate:"min=1"`
+	Author      string         `json:"author" validate:"required,max=100"`
+	ContentHash string         `json:"content_hash" validate:"required,len=64"`
+	Template    PromptTemplate `json:"template"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// PromptLabel points a name such as "production" at a revision, so flows can
+// follow the label while the revision behind it moves.
+type PromptLabel struct {
+	PromptID  uuid.UUID `json:"prompt_id" validate:"required"`
+	Name      string    `json:"name" validate:"required,max=50"`
+	AccountID uuid.UUID `json:"account_id" validate:"required"`
+	Revision  int       `json:"revision" validate:"min=1"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PromptRef selects a revision of a stored prompt: a pinned Revision, the
+// revision Label points at, or the latest revision when both are empty.
+type PromptRef struct {
+	Name     string
+	Revision int
+	Label    string
+}
+
+func (r PromptRef) String() string {
+	switch {
+	case r.Revision > 0:
+		return fmt.Sprintf("%s@%d", r.Name, r.Revision)
+	case r.Label != "":
+		return r.Name + "@" + r.Label
+	default:
+		return r.Name
+	}
+}
+
+// Hash returns the hex SHA-256 of the template content. Two revisions with
+// the same hash render identically.
+func (t *PromptTemplate) Hash() string {
+	// Marshalling a struct of strings, bools and JSON values cannot fail.
+	content, _ := json.Marshal(t)
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// NewPromptRevision snapshots the current template of p as revision number.
+func NewPromptRevision(p *Prompt, number int, now time.Time) (*PromptRevision, error) {
+	return validator.Struct(&PromptRevision{
+		ID:          uuid.New(),
+		PromptID:    p.ID,
+		AccountID:   p.AccountID,
+		Number:      number,
+		Author:      p.Author,
+		ContentHash: p.Template.Hash(),
+		Template:    p.Template,
+		CreatedAt:   now,
+	})
+}
+
+// NewPromptLabel points label name of p at revision. Label names follow the
+// same rules as prompt names.
+func NewPromptLabel(p *Prompt, name string, revision int, now time.Time) (*PromptLabel, error) {
+	if !flowNamePattern.MatchString(name) {
+		return nil, validator.NewFieldError("label", "label",
+			fmt.Sprintf("label %q may contain only letters, digits, '_', '.' and '-'", name))
+	}
+	return validator.Struct(&PromptLabel{
+		PromptID:  p.ID,
+		Name:      name,
+		AccountID: p.AccountID,
+		Revision:  revision,
+		UpdatedAt: now,
+	})
+}
+
+// PromptDiff holds line-level changes between two revisions, per message.
+// Variables are compared as indented JSON, one declaration field per line.
+type PromptDiff struct {
+	From      int         `json:"from"`
+	To        int         `json:"to"`
+	System    []diff.Line `json:"system"`
+	User      []diff.Line `json:"user"`
+	Variables []diff.Line `json:"variables"`
+}
+
+func DiffPromptRevisions(from, to *PromptRevision) *PromptDiff {
+	return &PromptDiff{
+		From:      from.Number,
+		To:        to.Number,
+		System:    diff.Lines(from.Template.System, to.Template.System),
+		User:      diff.Lines(from.Template.User, to.Template.User),
+		Variables: diff.Lines(variablesText(from.Template.Variables), variablesText(to.Template.Variables)),
+	}
+}
+
+func variablesText(variables []FlowVariable) string {
+	if len(variables) == 0 {
+		return ""
+	}
+	text, _ := json.MarshalIndent(variables, "", "  ")
+	return string(text)
+}
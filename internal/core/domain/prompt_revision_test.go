@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"flow-run/internal/lib/diff"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptTemplateHash(t *testing.T) {
+	t.Parallel()
+
+	template := PromptTemplate{User: "{{ .text }}", Variables: []FlowVariable{{Name: "text", Type: ValueTypeString}}}
+	same := template
+	changed := template
+	changed.User = "{{ .text }}!"
+
+	assert.Len(t, template.Hash(), 64)
+	assert.Equal(t, template.Hash(), same.Hash())
+	assert.NotEqual(t, template.Hash(), changed.Hash())
+}
+
+func TestDiffPromptRevisions(t *testing.T) {
+	t.Parallel()
+
+	prompt, err := NewPrompt(
+		WithPromptID(uuid.New()),
+		WithPromptAccountID(uuid.New()),
+		WithPromptName("summary"),
+		WithPromptAuthor("alice"),
+		WithPromptTemplate(PromptTemplate{
+			System:    "Be brief.",
+			User:      "Summarize:\n{{ .text }}",
+			Variables: []FlowVariable{{Name: "text", Type: ValueTypeString}},
+		}),
+	)
+	require.NoError(t, err)
+	from, err := NewPromptRevision(prompt, 1, time.Now())
+	require.NoError(t, err)
+
+	prompt.Template.User = "Summarize in one line:\n{{ .text }}"
+	to, err := NewPromptRevision(prompt, 2, time.Now())
+	require.NoError(t, err)
+
+	d := DiffPromptRevisions(from, to)
+	assert.Equal(t, 1, d.From)
+	assert.Equal(t, 2, d.To)
+	assert.False(t, diff.Changed(d.System))
+	assert.False(t, diff.Changed(d.Variables))
+	assert.Equal(t, []diff.Line{
+		{Op: diff.OpDelete, Text: "Summarize:", OldLine: 1},
+		{Op: diff.OpInsert, Text: "Summarize in one line:", NewLine: 1},
+		{Op: diff.OpEqual, Text: "{{ .text }}", OldLine: 2, NewLine: 2},
+	}, d.User)
+}
+
+func TestNewPromptLabelIfInvalidName(t *testing.T) {
+	t.Parallel()
+
+	prompt := &Prompt{ID: uuid.New(), AccountID: uuid.New()}
+	_, err := NewPromptLabel(prompt, "prod uction", 1, time.Now())
+	require.Error(t, err)
+
+	label, err := NewPromptLabel(prompt, "production", 1, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "production", label.Name)
+}
@@ -0,0 +1,195 @@
+package domain
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"slices"
+	"text/template"
+	"text/template/parse"
+)
+
+// PromptTemplate is a chat prompt whose messages are text/template sources.
+// Templates may use conditionals and loops; every name they read from the
+// data ({{ .name }} or {{ $.name }}) must be declared in Variables.
+//
+// A template without declared variables renders its data as is. Inline flow
+// prompts use that form and declare their variables through step inputs.
+type PromptTemplate struct {
+	System    string         `json:"system,omitempty"`
+	User      string         `json:"user" validate:"required"`
+	Variables []FlowVariable `json:"variables,omitempty" validate:"dive"`
+}
+
+// Render resolves vars against the declared variables and renders the
+// messages. name prefixes template names, so execution errors point at the
+// exact message, line and column, e.g. "template: summarize.user:1:9: ...".
+func (t *PromptTemplate) Render(name string, vars map[string]any) ([]Message, error) {
+	data := vars
+	if len(t.Variables) > 0 {
+		resolved, err := resolveVariables(t.Variables, vars, "variables.", "variable")
+		if err != nil {
+			return nil, err
+		}
+		// Unset optional variables are nil rather than missing, so templates
+		// can test them with {{ if .name }}.
+		for _, variable := range t.Variables {
+			if _, ok := resolved[variable.Name]; !ok {
+				resolved[variable.Name] = nil
+			}
+		}
+		data = resolved
+	}
+
+	var messages []Message
+	if t.System != "" {
+		system, err := RenderTemplate(name+".system", t.System, data)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, Message{Role: MessageRoleSystem, Content: system})
+	}
+	user, err := RenderTemplate(name+".user", t.User, data)
+	if err != nil {
+		return nil, err
+	}
+	return append(messages, Message{Role: MessageRoleUser, Content: user}), nil
+}
+
+// check validates the variable declarations and that the templates only read
+// declared variables. field prefixes the reported field names.
+func (t *PromptTemplate) check(c *fieldChecker, field string) {
+	declared := map[string]bool{}
+	for i, variable := range t.Variables {
+		variableField := fmt.Sprintf("%svariables[%d]", field, i)
+		c.checkIdentifier(variableField+".name", variable.Name)
+		if declared[variable.Name] {
+			c.fail(variableField+".name", "unique", "variable %q is declared twice", variable.Name)
+		}
+		declared[variable.Name] = true
+
+		if variable.Default != nil && !variable.Type.Accepts(variable.Default) {
+			c.fail(variableField+".default", "type", "default of %q must be of type %s", variable.Name, variable.Type)
+		}
+	}
+
+	if t.System != "" {
+		c.checkTemplate(field+"system", t.System, declared)
+	}
+	c.checkTemplate(field+"user", t.User, declared)
+}
+
+// checkBindings validates the step inputs that bind a template's variables:
+// every input must be a declared variable and every required variable
+// without a default must be bound.
+func (t *PromptTemplate) checkBindings(c *fieldChecker, field string, inputs map[string]string) {
+	declared := map[string]bool{}
+	for _, variable := range t.Variables {
+		declared[variable.Name] = true
+		if _, ok := inputs[variable.Name]; !ok && variable.Required && variable.Default == nil {
+			c.fail(field, "required", "variable %q is required by the prompt but not bound", variable.Name)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(inputs)) {
+		if !declared[name] {
+			c.fail(field+"."+name, "declared", "variable %q is not declared by the prompt", name)
+		}
+	}
+}
+
+// CheckPromptBindings is checkBindings for callers outside the package, such
+// as repositories checking steps that reference stored prompts.
+func CheckPromptBindings(t *PromptTemplate, field string, inputs map[string]string) error {
+	c := &fieldChecker{}
+	t.checkBindings(c, field, inputs)
+	return c.err()
+}
+
+// RenderTemplate executes text as a template over data. Reading a name that
+// is not in data is an error rather than an empty string.
+func RenderTemplate(name, text string, data map[string]any) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// TemplateReferences parses text and returns the top-level names it reads
+// from its data, sorted: "user" for {{ .user.name }} or {{ $.user }}. Fields
+// read inside range and with blocks belong to the element, not the data.
+func TemplateReferences(text string) ([]string, error) {
+	tree, err := template.New("").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := map[string]bool{}
+	walkTemplateNode(tree.Root, true, refs)
+	return slices.Sorted(maps.Keys(refs)), nil
+}
+
+// walkTemplateNode collects references below node. root reports whether dot
+// is still the template data.
+func walkTemplateNode(node parse.Node, root bool, refs map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkTemplateNode(child, root, refs)
+		}
+	case *parse.ActionNode:
+		walkTemplatePipe(n.Pipe, root, refs)
+	case *parse.TemplateNode:
+		walkTemplatePipe(n.Pipe, root, refs)
+	case *parse.IfNode:
+		walkTemplatePipe(n.Pipe, root, refs)
+		walkTemplateNode(n.List, root, refs)
+		walkTemplateNode(n.ElseList, root, refs)
+	case *parse.RangeNode:
+		walkTemplatePipe(n.Pipe, root, refs)
+		walkTemplateNode(n.List, false, refs)
+		walkTemplateNode(n.ElseList, root, refs)
+	case *parse.WithNode:
+		walkTemplatePipe(n.Pipe, root, refs)
+		walkTemplateNode(n.List, false, refs)
+		walkTemplateNode(n.ElseList, root, refs)
+	}
+}
+
+func walkTemplatePipe(pipe *parse.PipeNode, root bool, refs map[string]bool) {
+	if pipe == nil {
+		return
+	}
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			walkTemplateArg(arg, root, refs)
+		}
+	}
+}
+
+func walkTemplateArg(arg parse.Node, root bool, refs map[string]bool) {
+	switch n := arg.(type) {
+	case *parse.FieldNode:
+		if root {
+			refs[n.Ident[0]] = true
+		}
+	case *parse.VariableNode:
+		// $ always holds the template data, whatever dot currently is.
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			refs[n.Ident[1]] = true
+		}
+	case *parse.ChainNode:
+		walkTemplateArg(n.Node, root, refs)
+	case *parse.PipeNode:
+		walkTemplatePipe(n, root, refs)
+	}
+}
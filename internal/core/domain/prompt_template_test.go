@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"flow-run/internal/lib/validator"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateReferences(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "plain text", want: nil},
+		{text: "{{ .user.name }} and {{ .topic }}", want: []string{"topic", "user"}},
+		{text: `{{ if eq .mode "short" }}brief{{ else if .verbose }}long{{ end }}`, want: []string{"mode", "verbose"}},
+		{
+			text: "{{ range .items }}{{ .title }} {{ $.sep }}{{ else }}{{ .empty }}{{ end }}",
+			want: []string{"empty", "items", "sep"},
+		},
+		{text: "{{ with .doc }}{{ .body }}{{ end }}", want: []string{"doc"}},
+		{text: "{{ $n := len .items }}{{ $n }} {{ (.a).b }}", want: []string{"a", "items"}},
+		{text: "{{ .x | printf \"%q\" }}", want: []string{"x"}},
+	}
+
+	for _, tt := range tests {
+		refs, err := TemplateReferences(tt.text)
+		require.NoError(t, err, tt.text)
+		assert.Equal(t, tt.want, refs, tt.text)
+	}
+
+	_, err := TemplateReferences("{{ .unclosed ")
+	assert.Error(t, err)
+}
+
+func TestPromptTemplateRender(t *testing.T) {
+	t.Parallel()
+
+	template := &PromptTemplate{
+		System: "{{ if .formal }}Be formal.{{ else }}Be brief.{{ end }}",
+		User:   "Summarize:{{ range .points }}\n- {{ . }}{{ end }}",
+		Variables: []FlowVariable{
+			{Name: "points", Type: ValueTypeArray, Required: true},
+			{Name: "formal", Type: ValueTypeBoolean, Default: false},
+		},
+	}
+
+	messages, err := template.Render("summary", map[string]any{"points": []any{"a", "b"}})
+	require.NoError(t, err)
+	assert.Equal(t, []Message{
+		{Role: MessageRoleSystem, Content: "Be brief."},
+		{Role: MessageRoleUser, Content: "Summarize:\n- a\n- b"},
+	}, messages)
+
+	_, err = template.Render("summary", map[string]any{"points": "a"})
+	fields, ok := validator.FieldErrors(err)
+	require.True(t, ok)
+	assert.Equal(t, "variables.points", fields[0].Field)
+	assert.Equal(t, "type", fields[0].Tag)
+
+	optional := &PromptTemplate{
+		User:      "{{ if .name }}Hi {{ .name }}{{ else }}Hi{{ end }}",
+		Variables: []FlowVariable{{Name: "name", Type: ValueTypeString}},
+	}
+	messages, err = optional.Render("greeting", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Hi", messages[0].Content)
+
+	broken := &PromptTemplate{User: "{{ index .points 3 }}", Variables: template.Variables}
+	_, err = broken.Render("summary", map[string]any{"points": []any{"a"}})
+	assert.ErrorContains(t, err, `template: summary.user:1:3: executing "summary.user" at <index .points 3>`)
+}
+
+func TestNewPromptIfInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		template PromptTemplate
+		fields   []string
+	}{
+		{
+			name:     "missing_user",
+			template: PromptTemplate{System: "hi"},
+			fields:   []string{"template.user"},
+		},
+		{
+			name:     "undeclared_variable",
+			template: PromptTemplate{System: "{{ .tone }}", User: "{{ .text }}"},
+			fields:   []string{"template.system", "template.user"},
+		},
+		{
+			name: "bad_variables",
+			template: PromptTemplate{
+				User: "{{ .text }}",
+				Variables: []FlowVariable{
+					{Name: "text", Type: ValueTypeString, Default: 1.0},
+					{Name: "text", Type: ValueTypeString},
+					{Name: "bad-name", Type: ValueTypeString},
+				},
+			},
+			fields: []string{"template.variables[0].default", "template.variables[1].name", "template.variables[2].name"},
+		},
+		{
+			name:     "unknown_type",
+			template: PromptTemplate{User: "x", Variables: []FlowVariable{{Name: "x", Type: "date"}}},
+			fields:   []string{"template.variables[0].type"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			prompt, err := NewPrompt(
+				WithPromptID(uuid.New()),
+				WithPromptAccountID(uuid.New()),
+				WithPromptName("summary"),
+				WithPromptAuthor("alice"),
+				WithPromptTemplate(tt.template),
+			)
+			require.Error(t, err)
+			assert.Nil(t, prompt)
+
+			fieldErrors, ok := validator.FieldErrors(err)
+			require.True(t, ok, err.Error())
+			fields := make([]string, 0, len(fieldErrors))
+			for _, f := range fieldErrors {
+				fields = append(fields, f.Field)
+			}
+			assert.ElementsMatch(t, tt.fields, fields)
+		})
+	}
+}
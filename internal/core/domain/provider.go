@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"flow-run/internal/lib/envelope"
 	"flow-run/internal/lib/validator"
 
 	"github.com/google/uuid"
@@ -9,15 +10,41 @@ import (
 type ProviderType string
 
 const (
-	ProviderTypeOpenRouter = ProviderType("open_router")
+	ProviderTypeOpenRouter       = ProviderType("open_router")
+	ProviderTypeOpenAI           = ProviderType("openai")
+	ProviderTypeOpenAICompatible = ProviderType("openai_compatible")
+	ProviderTypeOllama           = ProviderType("ollama")
+	ProviderTypeAnthropic        = ProviderType("anthropic")
+	ProviderTypeMock             = ProviderType("mock")
 )
 
+// Provider holds the credentials for one LLM backend. ApiKey is optional for
+// self-hosted backends (openai_compatible, ollama); BaseURL is required for
+// openai_compatible and overrides the public endpoint for every other type.
+// For the mock type ApiKey holds a MockScript instead of a credential.
+//
+// ApiKey is plaintext and only travels from the API to the repository, which
+// seals it into EncryptedApiKey; only the LLM adapter factory opens it again.
 type Provider struct {
 	ID        uuid.UUID    `json:"id" validate:"required"`
 	Name      string       `json:"name" validate:"required,alphanum,min=1,max=50"`
 	AccountID uuid.UUID    `json:"account_id" validate:"required"`
-	Type      ProviderType `json:"type" validate:"oneof=open_router"`
-	ApiKey    string       `json:"api_key" validate:"required"`
+	Type      ProviderType `json:"type" validate:"oneof=open_router openai openai_compatible ollama anthropic mock"`
+	ApiKey    string       `json:"api_key"`
+	BaseURL   string       `json:"base_url" validate:"required_if=Type openai_compatible,omitempty,url"`
+
+	EncryptedApiKey *envelope.Sealed `json:"-"`
+}
+
+// RequiresApiKey reports whether providers of this type cannot work without
+// a key. Self-hosted backends usually run unauthenticated.
+func (t ProviderType) RequiresApiKey() bool {
+	switch t {
+	case ProviderTypeOpenRouter, ProviderTypeOpenAI, ProviderTypeAnthropic, ProviderTypeMock:
+		return true
+	default:
+		return false
+	}
 }
 
 type ProviderOpt func(*Provider)
@@ -52,6 +79,20 @@ func WithProviderApiKey(apiKey string) ProviderOpt {
 	}
 }
 
+func WithProviderBaseURL(baseURL string) ProviderOpt {
+	return func(p *Provider) {
+		p.BaseURL = baseURL
+	}
+}
+
+// WithProviderEncryptedApiKey keeps an already stored key, e.g. when an update
+// does not send a new one.
+func WithProviderEncryptedApiKey(sealed *envelope.Sealed) ProviderOpt {
+	return func(p *Provider) {
+		p.EncryptedApiKey = sealed
+	}
+}
+
 func NewProvider(opts ...ProviderOpt) (*Provider, error) {
 
 	p := &Provider{}
@@ -59,5 +100,20 @@ func NewProvider(opts ...ProviderOpt) (*Provider, error) {
 		opt(p)
 	}
 
-	return validator.Struct(p)
+	p, err := validator.Struct(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Type.RequiresApiKey() && p.ApiKey == "" && p.EncryptedApiKey.IsZero() {
+		return nil, validator.NewFieldError("api_key", "required", "api_key is required for "+string(p.Type))
+	}
+
+	if p.Type == ProviderTypeMock && p.ApiKey != "" {
+		if _, err := ParseMockScript(p.ApiKey); err != nil {
+			return nil, validator.NewFieldError("api_key", "mock_script", err.Error())
+		}
+	}
+
+	return p, nil
 }
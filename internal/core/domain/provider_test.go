@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"flow-run/internal/lib/envelope"
 	"strings"
 	"testing"
 
@@ -204,7 +205,7 @@ func TestProviderOptFunctions(t *testing.T) {
 
 		id := uuid.New()
 		provider := &Provider{}
-		
+
 		opt := WithProviderID(id)
 		opt(provider)
 
@@ -216,7 +217,7 @@ func TestProviderOptFunctions(t *testing.T) {
 
 		name := faker.Username()
 		provider := &Provider{}
-		
+
 		opt := WithProviderName(name)
 		opt(provider)
 
@@ -228,7 +229,7 @@ func TestProviderOptFunctions(t *testing.T) {
 
 		accountID := uuid.New()
 		provider := &Provider{}
-		
+
 		opt := WithProviderAccountID(accountID)
 		opt(provider)
 
@@ -239,7 +240,7 @@ func TestProviderOptFunctions(t *testing.T) {
 		t.Parallel()
 
 		provider := &Provider{}
-		
+
 		opt := WithProviderType(ProviderTypeOpenRouter)
 		opt(provider)
 
@@ -251,7 +252,7 @@ func TestProviderOptFunctions(t *testing.T) {
 
 		apiKey := faker.Password()
 		provider := &Provider{}
-		
+
 		opt := WithProviderApiKey(apiKey)
 		opt(provider)
 
@@ -312,4 +313,170 @@ func TestProviderTypeConstant(t *testing.T) {
 	t.Parallel()
 
 	assert.Equal(t, "open_router", string(ProviderTypeOpenRouter))
-}
\ No newline at end of file
+	assert.Equal(t, "openai", string(ProviderTypeOpenAI))
+	assert.Equal(t, "openai_compatible", string(ProviderTypeOpenAICompatible))
+	assert.Equal(t, "ollama", string(ProviderTypeOllama))
+	assert.Equal(t, "anthropic", string(ProviderTypeAnthropic))
+	assert.Equal(t, "mock", string(ProviderTypeMock))
+}
+
+func TestNewProviderIfSelfHostedType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []ProviderOpt
+		wantErr bool
+	}{
+		{
+			name:    "openai_requires_api_key",
+			opts:    []ProviderOpt{WithProviderType(ProviderTypeOpenAI)},
+			wantErr: true,
+		},
+		{
+			name: "openai_with_api_key",
+			opts: []ProviderOpt{WithProviderType(ProviderTypeOpenAI), WithProviderApiKey(faker.Password())},
+		},
+		{
+			name:    "openai_compatible_requires_base_url",
+			opts:    []ProviderOpt{WithProviderType(ProviderTypeOpenAICompatible)},
+			wantErr: true,
+		},
+		{
+			name: "openai_compatible_without_api_key",
+			opts: []ProviderOpt{
+				WithProviderType(ProviderTypeOpenAICompatible),
+				WithProviderBaseURL("http://localhost:8000/v1"),
+			},
+		},
+		{
+			name: "openai_compatible_with_invalid_base_url",
+			opts: []ProviderOpt{
+				WithProviderType(ProviderTypeOpenAICompatible),
+				WithProviderBaseURL("not a url"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "ollama_without_api_key_and_base_url",
+			opts: []ProviderOpt{WithProviderType(ProviderTypeOllama)},
+		},
+		{
+			name: "ollama_with_base_url",
+			opts: []ProviderOpt{
+				WithProviderType(ProviderTypeOllama),
+				WithProviderBaseURL("http://ollama:11434"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := append([]ProviderOpt{
+				WithProviderID(uuid.New()),
+				WithProviderName("local"),
+				WithProviderAccountID(uuid.New()),
+			}, tt.opts...)
+
+			provider, err := NewProvider(opts...)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, provider)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, provider)
+		})
+	}
+}
+
+func TestNewProviderIfAnthropicType(t *testing.T) {
+	t.Parallel()
+
+	provider, err := NewProvider(
+		WithProviderID(uuid.New()),
+		WithProviderName("anthropic"),
+		WithProviderAccountID(uuid.New()),
+		WithProviderType(ProviderTypeAnthropic),
+	)
+
+	assert.Error(t, err)
+	assert.Nil(t, provider)
+
+	provider, err = NewProvider(
+		WithProviderID(uuid.New()),
+		WithProviderName("anthropic"),
+		WithProviderAccountID(uuid.New()),
+		WithProviderType(ProviderTypeAnthropic),
+		WithProviderApiKey(faker.Password()),
+	)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, provider)
+}
+
+func TestNewProviderIfMockType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		script  string
+		wantErr bool
+	}{
+		{name: "missing_script", wantErr: true},
+		{name: "not_json", script: "sk-123", wantErr: true},
+		{name: "no_rules", script: `{"rules": []}`, wantErr: true},
+		{name: "valid_script", script: `{"rules": [{"regex": "hello", "response": "hi"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			provider, err := NewProvider(
+				WithProviderID(uuid.New()),
+				WithProviderName("mock"),
+				WithProviderAccountID(uuid.New()),
+				WithProviderType(ProviderTypeMock),
+				WithProviderApiKey(tt.script),
+			)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, provider)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, provider)
+		})
+	}
+}
+
+func TestNewProviderIfApiKeyAlreadyEncrypted(t *testing.T) {
+	t.Parallel()
+
+	provider, err := NewProvider(
+		WithProviderID(uuid.New()),
+		WithProviderName("openrouter"),
+		WithProviderAccountID(uuid.New()),
+		WithProviderType(ProviderTypeOpenRouter),
+		WithProviderEncryptedApiKey(&envelope.Sealed{KeyVersion: 1, Ciphertext: []byte("sealed")}),
+	)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, provider)
+
+	provider, err = NewProvider(
+		WithProviderID(uuid.New()),
+		WithProviderName("openrouter"),
+		WithProviderAccountID(uuid.New()),
+		WithProviderType(ProviderTypeOpenRouter),
+		WithProviderEncryptedApiKey(&envelope.Sealed{}),
+	)
+
+	assert.Error(t, err)
+	assert.Nil(t, provider)
+}
@@ -0,0 +1,276 @@
+package domain
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// CaseChange tells how a case fared in the candidate run of a regression
+// report compared with the baseline.
+type CaseChange string
+
+const (
+	CaseChangeFixed     = CaseChange("fixed")
+	CaseChangeRegressed = CaseChange("regressed")
+	CaseChangePassing   = CaseChange("passing")
+	CaseChangeFailing   = CaseChange("failing")
+	// CaseChangeAdded and CaseChangeRemoved mark cases only one of the runs
+	// has, e.g. after the suite's cases were edited between the runs.
+	CaseChangeAdded   = CaseChange("added")
+	CaseChangeRemoved = CaseChange("removed")
+)
+
+// RunSummary aggregates one side of a regression report. PassRate and Score
+// are fractions between 0 and 1; Score averages the case scores, see
+// CaseScore.
+type RunSummary struct {
+	RunID          uuid.UUID  `json:"run_id"`
+	Model          string     `json:"model"`
+	PromptRevision int        `json:"prompt_revision,omitempty"`
+	DatasetVersion int        `json:"dataset_version,omitempty"`
+	Cases          int        `json:"cases"`
+	Passed         int        `json:"passed"`
+	Failed         int        `json:"failed"`
+	PassRate       float64    `json:"pass_rate"`
+	Score          float64    `json:"score"`
+	Usage          TokenUsage `json:"usage"`
+	Cost           Money      `json:"cost"`
+	LatencyMS      int64      `json:"latency_ms"`
+	MeanLatencyMS  int64      `json:"mean_latency_ms"`
+}
+
+// RegressionDelta is the candidate's summary minus the baseline's: a
+// negative PassRate is worse, a negative Cost cheaper.
+type RegressionDelta struct {
+	Passed        int     `json:"passed"`
+	PassRate      float64 `json:"pass_rate"`
+	Score         float64 `json:"score"`
+	Tokens        int     `json:"tokens"`
+	Cost          Money   `json:"cost"`
+	MeanLatencyMS int64   `json:"mean_latency_ms"`
+}
+
+// CaseOutcome is how one case fared in one run.
+type CaseOutcome struct {
+	Passed    bool    `json:"passed"`
+	Score     float64 `json:"score"`
+	Error     string  `json:"error,omitempty"`
+	Cost      Money   `json:"cost"`
+	LatencyMS int64   `json:"latency_ms"`
+	// Failures are the messages of the assertions that failed.
+	Failures []string `json:"failures,omitempty"`
+}
+
+// CaseComparison pairs the outcomes of a case in both runs. Baseline or
+// Candidate is nil for cases only the other run has.
+type CaseComparison struct {
+	Position  int          `json:"position"`
+	Name      string       `json:"name"`
+	Change    CaseChange   `json:"change"`
+	Baseline  *CaseOutcome `json:"baseline,omitempty"`
+	Candidate *CaseOutcome `json:"candidate,omitempty"`
+}
+
+// Flipped reports whether the case passes in one run and fails in the
+// other.
+func (c *CaseComparison) Flipped() bool {
+	return c.Change == CaseChangeFixed || c.Change == CaseChangeRegressed
+}
+
+// RegressionReport compares a candidate run of a test suite with a baseline
+// run of the same suite, over the same cases or dataset version, e.g. after
+// a prompt edit or a model swap. Cases are matched by position.
+type RegressionReport struct {
+	SuiteID   uuid.UUID        `json:"suite_id"`
+	Baseline  RunSummary       `json:"baseline"`
+	Candidate RunSummary       `json:"candidate"`
+	Delta     RegressionDelta  `json:"delta"`
+	Cases     []CaseComparison `json:"cases"`
+}
+
+// Flipped returns the cases that pass in one run and fail in the other.
+func (r *RegressionReport) Flipped() []CaseComparison {
+	var flipped []CaseComparison
+	for _, c := range r.Cases {
+		if c.Flipped() {
+			flipped = append(flipped, c)
+		}
+	}
+	return flipped
+}
+
+// Regressions counts the cases that pass in the baseline and fail in the
+// candidate.
+func (r *RegressionReport) Regressions() int {
+	count := 0
+	for _, c := range r.Cases {
+		if c.Change == CaseChangeRegressed {
+			count++
+		}
+	}
+	return count
+}
+
+// NewRegressionReport compares candidate with baseline. Both runs must have
+// succeeded, belong to the same suite and, for dataset suites, have run the
+// same dataset version; otherwise a validator error names the offending run
+// as "baseline" or "candidate".
+func NewRegressionReport(
+	baseline *TestSuiteRun, baselineCases []*TestCaseResult,
+	candidate *TestSuiteRun, candidateCases []*TestCaseResult,
+) (*RegressionReport, error) {
+	c := &fieldChecker{}
+	for _, side := range []struct {
+		field string
+		run   *TestSuiteRun
+	}{{"baseline", baseline}, {"candidate", candidate}} {
+		if side.run.Status != RunStatusSucceeded {
+			c.fail(side.field, "succeeded", "run %s is %s; only succeeded runs can be compared",
+				side.run.ID, side.run.Status)
+		}
+	}
+	if candidate.SuiteID != baseline.SuiteID {
+		c.fail("candidate", "same_suite", "run %s belongs to another suite than the baseline", candidate.ID)
+	}
+	if candidate.DatasetVersion != baseline.DatasetVersion {
+		c.fail("candidate", "dataset_version", "run %s ran dataset version %d, the baseline version %d",
+			candidate.ID, candidate.DatasetVersion, baseline.DatasetVersion)
+	}
+	if err := c.err(); err != nil {
+		return nil, err
+	}
+
+	report := &RegressionReport{
+		SuiteID:   baseline.SuiteID,
+		Baseline:  summarizeRun(baseline, baselineCases),
+		Candidate: summarizeRun(candidate, candidateCases),
+		Cases:     compareCases(baselineCases, candidateCases),
+	}
+	report.Delta = RegressionDelta{
+		Passed:        report.Candidate.Passed - report.Baseline.Passed,
+		PassRate:      report.Candidate.PassRate - report.Baseline.PassRate,
+		Score:         report.Candidate.Score - report.Baseline.Score,
+		Tokens:        report.Candidate.Usage.TotalTokens - report.Baseline.Usage.TotalTokens,
+		Cost:          report.Candidate.Cost - report.Baseline.Cost,
+		MeanLatencyMS: report.Candidate.MeanLatencyMS - report.Baseline.MeanLatencyMS,
+	}
+	return report, nil
+}
+
+// CaseScore grades a case between 0 and 1: the share of its assertions that
+// passed, where judge assertions count with their judge's score. Cases that
+// produced no output score 0; cases without assertions score 1 when they
+// passed.
+func CaseScore(r *TestCaseResult) float64 {
+	if r.Error != "" {
+		return 0
+	}
+	if len(r.Assertions) == 0 {
+		if r.Passed {
+			return 1
+		}
+		return 0
+	}
+
+	total := 0.0
+	for _, a := range r.Assertions {
+		switch {
+		case a.Score != nil:
+			total += *a.Score
+		case a.Passed:
+			total++
+		}
+	}
+	return total / float64(len(r.Assertions))
+}
+
+func summarizeRun(run *TestSuiteRun, cases []*TestCaseResult) RunSummary {
+	summary := RunSummary{
+		RunID:          run.ID,
+		Model:          run.Model,
+		PromptRevision: run.PromptRevision,
+		DatasetVersion: run.DatasetVersion,
+		Cases:          len(cases),
+		Passed:         run.Passed,
+		Failed:         run.Failed,
+		Usage:          run.Usage,
+		Cost:           run.Cost,
+	}
+	if len(cases) == 0 {
+		return summary
+	}
+
+	score := 0.0
+	for _, r := range cases {
+		score += CaseScore(r)
+		summary.LatencyMS += r.LatencyMS
+	}
+	summary.PassRate = float64(run.Passed) / float64(len(cases))
+	summary.Score = score / float64(len(cases))
+	summary.MeanLatencyMS = summary.LatencyMS / int64(len(cases))
+	return summary
+}
+
+func compareCases(baseline, candidate []*TestCaseResult) []CaseComparison {
+	byPosition := map[int]*CaseComparison{}
+	var positions []int
+	comparison := func(r *TestCaseResult) *CaseComparison {
+		c, ok := byPosition[r.Position]
+		if !ok {
+			c = &CaseComparison{Position: r.Position}
+			byPosition[r.Position] = c
+			positions = append(positions, r.Position)
+		}
+		c.Name = r.Name
+		return c
+	}
+	for _, r := range baseline {
+		comparison(r).Baseline = caseOutcome(r)
+	}
+	for _, r := range candidate {
+		comparison(r).Candidate = caseOutcome(r)
+	}
+
+	slices.Sort(positions)
+	cases := make([]CaseComparison, 0, len(positions))
+	for _, position := range positions {
+		c := byPosition[position]
+		c.Change = caseChange(c.Baseline, c.Candidate)
+		cases = append(cases, *c)
+	}
+	return cases
+}
+
+func caseOutcome(r *TestCaseResult) *CaseOutcome {
+	outcome := &CaseOutcome{
+		Passed:    r.Passed,
+		Score:     CaseScore(r),
+		Error:     r.Error,
+		Cost:      r.Cost,
+		LatencyMS: r.LatencyMS,
+	}
+	for _, a := range r.Assertions {
+		if !a.Passed {
+			outcome.Failures = append(outcome.Failures, a.Message)
+		}
+	}
+	return outcome
+}
+
+func caseChange(baseline, candidate *CaseOutcome) CaseChange {
+	switch {
+	case baseline == nil:
+		return CaseChangeAdded
+	case candidate == nil:
+		return CaseChangeRemoved
+	case baseline.Passed && candidate.Passed:
+		return CaseChangePassing
+	case baseline.Passed:
+		return CaseChangeRegressed
+	case candidate.Passed:
+		return CaseChangeFixed
+	default:
+		return CaseChangeFailing
+	}
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"flow-run/internal/lib/validator"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRegressionReport(t *testing.T) {
+	t.Parallel()
+
+	suite := &TestSuite{ID: uuid.New()}
+	half := 0.5
+	result := func(position int, passed bool, cost Money, latency int64, assertions ...AssertionResult) *TestCaseResult {
+		return &TestCaseResult{
+			Position: position, Name: "case", Passed: passed, Cost: cost, LatencyMS: latency, Assertions: assertions,
+		}
+	}
+	finished := func(model string, results ...*TestCaseResult) *TestSuiteRun {
+		run := NewTestSuiteRun(suite, model, time.Now())
+		for _, r := range results {
+			run.Record(r)
+		}
+		run.Finish(RunStatusSucceeded, "", time.Now())
+		return run
+	}
+
+	baselineCases := []*TestCaseResult{
+		result(0, true, 10, 100, AssertionResult{Passed: true}),
+		result(1, true, 10, 100, AssertionResult{Passed: true}),
+		result(2, false, 10, 100, AssertionResult{Message: "too long"}),
+		result(3, true, 10, 100),
+	}
+	candidateCases := []*TestCaseResult{
+		result(0, true, 5, 300, AssertionResult{Passed: true}),
+		result(1, false, 5, 300, AssertionResult{Passed: true}, AssertionResult{Score: &half, Message: "vague"}),
+		result(2, true, 5, 300, AssertionResult{Passed: true}),
+	}
+	baseline := finished("gpt", baselineCases...)
+	candidate := finished("mini", candidateCases...)
+
+	report, err := NewRegressionReport(baseline, baselineCases, candidate, candidateCases)
+	require.NoError(t, err)
+
+	assert.Equal(t, 0.75, report.Baseline.PassRate)
+	assert.Equal(t, 0.75, report.Baseline.Score)
+	assert.InDelta(t, 2.0/3, report.Candidate.PassRate, 1e-9)
+	assert.InDelta(t, (1+0.75+1)/3.0, report.Candidate.Score, 1e-9)
+	assert.Equal(t, -1, report.Delta.Passed)
+	assert.Equal(t, Money(-25), report.Delta.Cost)
+	assert.Equal(t, int64(200), report.Delta.MeanLatencyMS)
+
+	changes := make([]CaseChange, 0, len(report.Cases))
+	for _, c := range report.Cases {
+		changes = append(changes, c.Change)
+	}
+	assert.Equal(t, []CaseChange{CaseChangePassing, CaseChangeRegressed, CaseChangeFixed, CaseChangeRemoved}, changes)
+	assert.Equal(t, []string{"vague"}, report.Cases[1].Candidate.Failures)
+	assert.Nil(t, report.Cases[3].Candidate)
+	assert.Len(t, report.Flipped(), 2)
+	assert.Equal(t, 1, report.Regressions())
+}
+
+func TestNewRegressionReportIfIncomparable(t *testing.T) {
+	t.Parallel()
+
+	baseline := &TestSuiteRun{ID: uuid.New(), SuiteID: uuid.New(), Status: RunStatusSucceeded, DatasetVersion: 1}
+	tests := []struct {
+		name      string
+		candidate *TestSuiteRun
+		tag       string
+	}{
+		{
+			name:      "unfinished",
+			candidate: &TestSuiteRun{SuiteID: baseline.SuiteID, Status: RunStatusRunning, DatasetVersion: 1},
+			tag:       "succeeded",
+		},
+		{
+			name:      "other suite",
+			candidate: &TestSuiteRun{SuiteID: uuid.New(), Status: RunStatusSucceeded, DatasetVersion: 1},
+			tag:       "same_suite",
+		},
+		{
+			name:      "other dataset version",
+			candidate: &TestSuiteRun{SuiteID: baseline.SuiteID, Status: RunStatusSucceeded, DatasetVersion: 2},
+			tag:       "dataset_version",
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := NewRegressionReport(baseline, nil, tt.candidate, nil)
+		fields, ok := validator.FieldErrors(err)
+		require.True(t, ok, "%s: %v", tt.name, err)
+		assert.Equal(t, "candidate", fields[0].Field, tt.name)
+		assert.Equal(t, tt.tag, fields[0].Tag, tt.name)
+	}
+}
@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RunStatus string
+
+const (
+	RunStatusPending   = RunStatus("pending")
+	RunStatusRunning   = RunStatus("running")
+	RunStatusSucceeded = RunStatus("succeeded")
+	RunStatusFailed    = RunStatus("failed")
+	RunStatusCanceled  = RunStatus("canceled")
+	// RunStatusSkipped is only used for steps: a branch did not select them
+	// or an earlier step failed.
+	RunStatusSkipped = RunStatus("skipped")
+)
+
+// Finished reports whether s is a terminal status.
+func (s RunStatus) Finished() bool {
+	switch s {
+	case RunStatusSucceeded, RunStatusFailed, RunStatusCanceled, RunStatusSkipped:
+		return true
+	default:
+		return false
+	}
+}
+
+// FlowRun is one execution of a flow. It is persisted as it progresses so it
+// can be inspected even if the process running it dies. Sub-flow runs point
+// at the step that started them through ParentStepRunID. Model is set when
+// the run replaced the models of its prompt steps with a single one. Usage
+// and Cost add up the run's steps, including those of its sub-flows.
+// CancelRequestedAt is set once the run is asked to cancel; a running run
+// keeps its status until its engine notices. LeaseExpiresAt is how long the
+// process executing a root run vouches for it; it keeps extending the lease
+// while the run executes, so a running run whose lease expired was left
+// behind by a process that stopped.
+type FlowRun struct {
+	ID              uuid.UUID      `json:"id"`
+	AccountID       uuid.UUID      `json:"account_id"`
+	FlowID          uuid.UUID      `json:"flow_id"`
+	ParentStepRunID *uuid.UUID     `json:"parent_step_run_id,omitempty"`
+	Model           string         `json:"model,omitempty"`
+	Status          RunStatus      `json:"status"`
+	Inputs          map[string]any `json:"inputs"`
+	Outputs         map[string]any `json:"outputs,omitempty"`
+	Error           string         `json:"error,omitempty"`
+	Usage           TokenUsage     `json:"usage"`
+	Cost            Money          `json:"cost"`
+	CreatedAt       time.Time      `json:"created_at"`
+	StartedAt       *time.Time     `json:"started_at,omitempty"`
+	FinishedAt      *time.Time     `json:"finished_at,omitempty"`
+
+	CancelRequestedAt *time.Time `json:"cancel_requested_at,omitempty"`
+	LeaseExpiresAt    *time.Time `json:"lease_expires_at,omitempty"`
+}
+
+// StepRun records one step of a FlowRun. Position is the index of the step
+// in the definition; ChildRunID is set for sub-flow steps and
+// PromptRevisionID for steps that ran a stored prompt.
+type StepRun struct {
+	ID         uuid.UUID      `json:"id"`
+	RunID      uuid.UUID      `json:"run_id"`
+	StepID     string         `json:"step_id"`
+	Position   int            `json:"position"`
+	Type       StepType       `json:"type"`
+	Status     RunStatus      `json:"status"`
+	Inputs     map[string]any `json:"inputs,omitempty"`
+	Output     any            `json:"output,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	Usage      TokenUsage     `json:"usage"`
+	Cost       Money          `json:"cost"`
+	ChildRunID *uuid.UUID     `json:"child_run_id,omitempty"`
+	StartedAt  *time.Time     `json:"started_at,omitempty"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+
+	PromptRevisionID *uuid.UUID `json:"prompt_revision_id,omitempty"`
+}
+
+type FlowRunOpt func(*FlowRun)
+
+func WithFlowRunParentStepRunID(id uuid.UUID) FlowRunOpt {
+	return func(r *FlowRun) {
+		r.ParentStepRunID = &id
+	}
+}
+
+func WithFlowRunModel(model string) FlowRunOpt {
+	return func(r *FlowRun) {
+		r.Model = model
+	}
+}
+
+// NewFlowRun creates a pending run of flow with already resolved inputs,
+// together with a pending StepRun for every step.
+func NewFlowRun(flow *Flow, inputs map[string]any, now time.Time, opts ...FlowRunOpt) (*FlowRun, []*StepRun) {
+	run := &FlowRun{
+		ID:        uuid.New(),
+		AccountID: flow.AccountID,
+		FlowID:    flow.ID,
+		Status:    RunStatusPending,
+		Inputs:    inputs,
+		CreatedAt: now,
+	}
+	for _, opt := range opts {
+		opt(run)
+	}
+
+	steps := make([]*StepRun, 0, len(flow.Definition.Steps))
+	for i, step := range flow.Definition.Steps {
+		steps = append(steps, &StepRun{
+			ID:       uuid.New(),
+			RunID:    run.ID,
+			StepID:   step.ID,
+			Position: i,
+			Type:     step.Type,
+			Status:   RunStatusPending,
+		})
+	}
+	return run, steps
+}
+
+func (r *FlowRun) Start(now time.Time) {
+	r.Status = RunStatusRunning
+	r.StartedAt = &now
+}
+
+func (r *FlowRun) Finish(status RunStatus, errMessage string, now time.Time) {
+	r.Status = status
+	r.Error = errMessage
+	r.FinishedAt = &now
+}
+
+func (s *StepRun) Start(inputs map[string]any, now time.Time) {
+	s.Status = RunStatusRunning
+	s.Inputs = inputs
+	s.StartedAt = &now
+}
+
+func (s *StepRun) Finish(status RunStatus, errMessage string, now time.Time) {
+	s.Status = status
+	s.Error = errMessage
+	s.FinishedAt = &now
+}
@@ -0,0 +1,194 @@
+package domain
+
+import (
+	"flow-run/internal/lib/validator"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// TestSuite is a set of test cases for a stored prompt or a flow, both
+// referenced by name. Case inputs bind the prompt's variables or the flow's
+// inputs.
+//
+// A suite takes its cases either from Cases or from the rows of Dataset, at
+// DatasetVersion or the latest version when zero. Every row of a dataset
+// suite is checked with Assertions; a dataset suite without assertions is a
+// batch run, whose rows pass when they produce an output.
+type TestSuite struct {
+	ID             uuid.UUID   `json:"id" validate:"required"`
+	AccountID      uuid.UUID   `json:"account_id" validate:"required"`
+	Name           string      `json:"name" validate:"required,max=100"`
+	Description    string      `json:"description,omitempty"`
+	Prompt         string      `json:"prompt,omitempty" validate:"required_without=Flow,excluded_with=Flow"`
+	Flow           string      `json:"flow,omitempty"`
+	Cases          []TestCase  `json:"cases" validate:"dive"`
+	Dataset        string      `json:"dataset,omitempty" validate:"max=100"`
+	DatasetVersion int         `json:"dataset_version,omitempty" validate:"min=0"`
+	Assertions     []Assertion `json:"assertions,omitempty" validate:"dive"`
+}
+
+// TestCase passes when every one of its assertions holds. Expected is the
+// output the case should produce; equals, contains, not_contains and
+// json_path assertions without an operand compare with it, and judges are
+// shown it.
+type TestCase struct {
+	Name       string         `json:"name" validate:"required,max=100"`
+	Inputs     map[string]any `json:"inputs,omitempty"`
+	Expected   any            `json:"expected,omitempty"`
+	Assertions []Assertion    `json:"assertions" validate:"required,min=1,dive"`
+}
+
+type TestSuiteOpt func(*TestSuite)
+
+func WithTestSuiteID(id uuid.UUID) TestSuiteOpt {
+	return func(s *TestSuite) {
+		s.ID = id
+	}
+}
+
+func WithTestSuiteAccountID(accountID uuid.UUID) TestSuiteOpt {
+	return func(s *TestSuite) {
+		s.AccountID = accountID
+	}
+}
+
+func WithTestSuiteName(name string) TestSuiteOpt {
+	return func(s *TestSuite) {
+		s.Name = name
+	}
+}
+
+func WithTestSuiteDescription(description string) TestSuiteOpt {
+	return func(s *TestSuite) {
+		s.Description = description
+	}
+}
+
+// WithTestSuitePrompt attaches the suite to the stored prompt named name.
+func WithTestSuitePrompt(name string) TestSuiteOpt {
+	return func(s *TestSuite) {
+		s.Prompt = name
+	}
+}
+
+// WithTestSuiteFlow attaches the suite to the flow named name.
+func WithTestSuiteFlow(name string) TestSuiteOpt {
+	return func(s *TestSuite) {
+		s.Flow = name
+	}
+}
+
+func WithTestSuiteCases(cases ...TestCase) TestSuiteOpt {
+	return func(s *TestSuite) {
+		s.Cases = cases
+	}
+}
+
+// WithTestSuiteDataset takes the suite's cases from the rows of the dataset
+// named name, at version or the latest version when zero.
+func WithTestSuiteDataset(name string, version int) TestSuiteOpt {
+	return func(s *TestSuite) {
+		s.Dataset = name
+		s.DatasetVersion = version
+	}
+}
+
+// WithTestSuiteAssertions sets the assertions every row of a dataset suite
+// is checked with.
+func WithTestSuiteAssertions(assertions ...Assertion) TestSuiteOpt {
+	return func(s *TestSuite) {
+		s.Assertions = assertions
+	}
+}
+
+// NewTestSuite validates the suite, including the operands of every
+// assertion, so a suite that is stored can always be run.
+func NewTestSuite(opts ...TestSuiteOpt) (*TestSuite, error) {
+	s := &TestSuite{}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s, err := validator.Struct(s)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &fieldChecker{}
+	if !flowNamePattern.MatchString(s.Name) {
+		c.fail("name", "suite_name", "name %q may contain only letters, digits, '_', '.' and '-'", s.Name)
+	}
+	s.checkCases(c)
+	if err := c.err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *TestSuite) checkCases(c *fieldChecker) {
+	if s.Dataset != "" {
+		if !flowNamePattern.MatchString(s.Dataset) {
+			c.fail("dataset", "dataset_name", "name %q may contain only letters, digits, '_', '.' and '-'", s.Dataset)
+		}
+		if len(s.Cases) > 0 {
+			c.fail("cases", "excluded_with", "a dataset suite takes its cases from the dataset")
+		}
+		// Rows may carry the expected output assertions compare with.
+		for i := range s.Assertions {
+			s.Assertions[i].check(c, fmt.Sprintf("assertions[%d]", i), true)
+		}
+		return
+	}
+
+	if len(s.Cases) == 0 {
+		c.fail("cases", "required", "a suite needs cases or a dataset")
+	}
+	if len(s.Assertions) > 0 {
+		c.fail("assertions", "excluded_without", "suite assertions check dataset rows; give cases their own")
+	}
+	if s.DatasetVersion > 0 {
+		c.fail("dataset_version", "excluded_without", "dataset_version needs a dataset")
+	}
+	names := map[string]bool{}
+	for i, testCase := range s.Cases {
+		if names[testCase.Name] {
+			c.fail(fmt.Sprintf("cases[%d].name", i), "unique", "case %q is declared twice", testCase.Name)
+		}
+		names[testCase.Name] = true
+
+		for j := range testCase.Assertions {
+			field := fmt.Sprintf("cases[%d].assertions[%d]", i, j)
+			testCase.Assertions[j].check(c, field, testCase.Expected != nil)
+		}
+	}
+}
+
+// DatasetCase is the case of a dataset suite for row.
+func (s *TestSuite) DatasetCase(row *DatasetRow) TestCase {
+	name := row.Name
+	if name == "" {
+		name = fmt.Sprintf("row %d", row.Position+1)
+	}
+	return TestCase{Name: name, Inputs: row.Inputs, Expected: row.Expected, Assertions: s.Assertions}
+}
+
+// assertion returns the case's i-th assertion with the operand it leaves
+// out filled in from the case's expected output.
+func (tc *TestCase) assertion(i int) *Assertion {
+	a := tc.Assertions[i]
+	if tc.Expected == nil {
+		return &a
+	}
+	switch a.Type {
+	case AssertionTypeEquals, AssertionTypeContains, AssertionTypeNotContains:
+		if a.Value == "" {
+			a.Value = outputText(tc.Expected)
+		}
+	case AssertionTypeJSONPath:
+		if a.Expected == nil {
+			a.Expected = tc.Expected
+		}
+	}
+	return &a
+}
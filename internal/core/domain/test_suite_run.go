@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TestSuiteRun is one execution of a test suite against Model. Prompt suites
+// record the prompt revision they ran, dataset suites the dataset version.
+// The run succeeds once every case has been executed, whatever the cases'
+// outcome, which Passed and Failed count. Usage and Cost add up the cases;
+// JudgeUsage and JudgeCost add up what judge models spent grading them.
+// LeaseExpiresAt is how long the process executing the run vouches for it,
+// as for flow runs.
+type TestSuiteRun struct {
+	ID               uuid.UUID  `json:"id"`
+	AccountID        uuid.UUID  `json:"account_id"`
+	SuiteID          uuid.UUID  `json:"suite_id"`
+	Model            string     `json:"model"`
+	PromptRevisionID *uuid.UUID `json:"prompt_revision_id,omitempty"`
+	PromptRevision   int        `json:"prompt_revision,omitempty"`
+	DatasetVersionID *uuid.UUID `json:"dataset_version_id,omitempty"`
+	DatasetVersion   int        `json:"dataset_version,omitempty"`
+	Status           RunStatus  `json:"status"`
+	Error            string     `json:"error,omitempty"`
+	Passed           int        `json:"passed"`
+	Failed           int        `json:"failed"`
+	Usage            TokenUsage `json:"usage"`
+	Cost             Money      `json:"cost"`
+	JudgeUsage       TokenUsage `json:"judge_usage"`
+	JudgeCost        Money      `json:"judge_cost"`
+	CreatedAt        time.Time  `json:"created_at"`
+	StartedAt        *time.Time `json:"started_at,omitempty"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty"`
+	LeaseExpiresAt   *time.Time `json:"lease_expires_at,omitempty"`
+}
+
+// TestCaseResult records one case of a TestSuiteRun. Error is set when the
+// case could not produce an output, e.g. the provider failed; such a case
+// fails without evaluating its assertions. FlowRunID is set for flow suites.
+// Usage and Cost are the case's own; the judge models grading it are
+// accounted in JudgeUsage and JudgeCost.
+type TestCaseResult struct {
+	ID         uuid.UUID         `json:"id"`
+	RunID      uuid.UUID         `json:"run_id"`
+	Position   int               `json:"position"`
+	Name       string            `json:"name"`
+	Passed     bool              `json:"passed"`
+	Output     any               `json:"output,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Assertions []AssertionResult `json:"assertions"`
+	FlowRunID  *uuid.UUID        `json:"flow_run_id,omitempty"`
+	Usage      TokenUsage        `json:"usage"`
+	Cost       Money             `json:"cost"`
+	JudgeUsage TokenUsage        `json:"judge_usage"`
+	JudgeCost  Money             `json:"judge_cost"`
+	LatencyMS  int64             `json:"latency_ms"`
+}
+
+// NewTestSuiteRun creates a pending run of suite against model.
+func NewTestSuiteRun(suite *TestSuite, model string, now time.Time) *TestSuiteRun {
+	return &TestSuiteRun{
+		ID:        uuid.New(),
+		AccountID: suite.AccountID,
+		SuiteID:   suite.ID,
+		Model:     model,
+		Status:    RunStatusPending,
+		CreatedAt: now,
+	}
+}
+
+func (r *TestSuiteRun) Start(now time.Time) {
+	r.Status = RunStatusRunning
+	r.StartedAt = &now
+}
+
+// Record adds a finished case to the run's totals.
+func (r *TestSuiteRun) Record(result *TestCaseResult) {
+	if result.Passed {
+		r.Passed++
+	} else {
+		r.Failed++
+	}
+	r.Usage = r.Usage.Add(result.Usage)
+	r.Cost += result.Cost
+	r.JudgeUsage = r.JudgeUsage.Add(result.JudgeUsage)
+	r.JudgeCost += result.JudgeCost
+}
+
+func (r *TestSuiteRun) Finish(status RunStatus, errMessage string, now time.Time) {
+	r.Status = status
+	r.Error = errMessage
+	r.FinishedAt = &now
+}
+
+// NewTestCaseResult evaluates testCase's assertions against out, grading
+// judge assertions with out.Judgements. A case without assertions, as in
+// batch runs, passes when it produced an output. A non-nil caseErr fails the
+// case without evaluating them.
+func NewTestCaseResult(
+	runID uuid.UUID, position int, testCase *TestCase, out *CaseOutput, caseErr error,
+) *TestCaseResult {
+	result := &TestCaseResult{
+		ID:         uuid.New(),
+		RunID:      runID,
+		Position:   position,
+		Name:       testCase.Name,
+		Output:     out.Output,
+		Assertions: []AssertionResult{},
+		Usage:      out.Usage,
+		Cost:       out.Cost,
+		LatencyMS:  out.Latency.Milliseconds(),
+	}
+	if caseErr != nil {
+		result.Error = caseErr.Error()
+		return result
+	}
+
+	result.Passed = true
+	for i := range testCase.Assertions {
+		var assertion AssertionResult
+		if a := testCase.assertion(i); a.Type == AssertionTypeJudge {
+			assertion = a.Grade(out.Judgements[i])
+		} else {
+			assertion = a.Evaluate(out)
+		}
+		result.Passed = result.Passed && assertion.Passed
+		result.Assertions = append(result.Assertions, assertion)
+	}
+	for _, j := range out.Judgements {
+		result.JudgeUsage = result.JudgeUsage.Add(j.Usage)
+		result.JudgeCost += j.Cost
+	}
+	return result
+}
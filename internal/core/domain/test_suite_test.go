@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"flow-run/internal/lib/validator"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTestSuiteIfInvalid(t *testing.T) {
+	t.Parallel()
+
+	valid := TestCase{Name: "ok", Assertions: []Assertion{{Type: AssertionTypeContains, Value: "x"}}}
+	tests := []struct {
+		name  string
+		opts  []TestSuiteOpt
+		field string
+		tag   string
+	}{
+		{
+			name:  "no subject",
+			opts:  []TestSuiteOpt{WithTestSuiteCases(valid)},
+			field: "prompt",
+			tag:   "required_without",
+		},
+		{
+			name:  "prompt and flow",
+			opts:  []TestSuiteOpt{WithTestSuitePrompt("p"), WithTestSuiteFlow("f"), WithTestSuiteCases(valid)},
+			field: "prompt",
+			tag:   "excluded_with",
+		},
+		{
+			name:  "no cases",
+			opts:  []TestSuiteOpt{WithTestSuitePrompt("p")},
+			field: "cases",
+			tag:   "required",
+		},
+		{
+			name:  "cases and dataset",
+			opts:  []TestSuiteOpt{WithTestSuitePrompt("p"), WithTestSuiteCases(valid), WithTestSuiteDataset("d", 0)},
+			field: "cases",
+			tag:   "excluded_with",
+		},
+		{
+			name: "suite assertions without dataset",
+			opts: []TestSuiteOpt{
+				WithTestSuitePrompt("p"),
+				WithTestSuiteCases(valid),
+				WithTestSuiteAssertions(Assertion{Type: AssertionTypeContains, Value: "x"}),
+			},
+			field: "assertions",
+			tag:   "excluded_without",
+		},
+		{
+			name: "bad suite assertion",
+			opts: []TestSuiteOpt{
+				WithTestSuitePrompt("p"),
+				WithTestSuiteDataset("d", 0),
+				WithTestSuiteAssertions(Assertion{Type: AssertionTypeRegex, Value: "("}),
+			},
+			field: "assertions[0].value",
+			tag:   "regex",
+		},
+		{
+			name: "text assertion without value or expected",
+			opts: []TestSuiteOpt{WithTestSuitePrompt("p"), WithTestSuiteCases(TestCase{
+				Name: "x", Assertions: []Assertion{{Type: AssertionTypeEquals}},
+			})},
+			field: "cases[0].assertions[0].value",
+			tag:   "required",
+		},
+		{
+			name:  "duplicate case",
+			opts:  []TestSuiteOpt{WithTestSuitePrompt("p"), WithTestSuiteCases(valid, valid)},
+			field: "cases[1].name",
+			tag:   "unique",
+		},
+		{
+			name: "unknown assertion",
+			opts: []TestSuiteOpt{WithTestSuitePrompt("p"), WithTestSuiteCases(TestCase{
+				Name: "x", Assertions: []Assertion{{Type: "similar"}},
+			})},
+			field: "cases[0].assertions[0].type",
+			tag:   "oneof",
+		},
+		{
+			name: "bad regex",
+			opts: []TestSuiteOpt{WithTestSuitePrompt("p"), WithTestSuiteCases(TestCase{
+				Name: "x", Assertions: []Assertion{{Type: AssertionTypeRegex, Value: "("}},
+			})},
+			field: "cases[0].assertions[0].value",
+			tag:   "regex",
+		},
+		{
+			name: "bad schema",
+			opts: []TestSuiteOpt{WithTestSuitePrompt("p"), WithTestSuiteCases(TestCase{
+				Name: "x", Assertions: []Assertion{{Type: AssertionTypeJSONSchema, Schema: json.RawMessage(`{"type": 1}`)}},
+			})},
+			field: "cases[0].assertions[0].schema",
+			tag:   "json_schema",
+		},
+		{
+			name: "bad path",
+			opts: []TestSuiteOpt{WithTestSuitePrompt("p"), WithTestSuiteCases(TestCase{
+				Name: "x", Assertions: []Assertion{{Type: AssertionTypeJSONPath, Path: "name", Expected: "a"}},
+			})},
+			field: "cases[0].assertions[0].path",
+			tag:   "json_path",
+		},
+		{
+			name: "judge without rubric",
+			opts: []TestSuiteOpt{WithTestSuitePrompt("p"), WithTestSuiteCases(TestCase{
+				Name: "x", Assertions: []Assertion{{Type: AssertionTypeJudge, Model: "judge"}},
+			})},
+			field: "cases[0].assertions[0].rubric",
+			tag:   "required",
+		},
+		{
+			name: "bad cost",
+			opts: []TestSuiteOpt{WithTestSuitePrompt("p"), WithTestSuiteCases(TestCase{
+				Name: "x", Assertions: []Assertion{{Type: AssertionTypeMaxCost, Value: "a dollar"}},
+			})},
+			field: "cases[0].assertions[0].value",
+			tag:   "max_cost",
+		},
+	}
+
+	for _, tt := range tests {
+		opts := append([]TestSuiteOpt{
+			WithTestSuiteID(uuid.New()),
+			WithTestSuiteAccountID(uuid.New()),
+			WithTestSuiteName("suite"),
+		}, tt.opts...)
+		_, err := NewTestSuite(opts...)
+		fields, ok := validator.FieldErrors(err)
+		require.True(t, ok, "%s: %v", tt.name, err)
+
+		tags := map[string]string{}
+		for _, f := range fields {
+			tags[f.Field] = f.Tag
+		}
+		assert.Equal(t, tt.tag, tags[tt.field], "%s: %v", tt.name, fields)
+	}
+}
+
+func TestNewTestCaseResult(t *testing.T) {
+	t.Parallel()
+
+	testCase := &TestCase{Name: "capital", Assertions: []Assertion{
+		{Type: AssertionTypeContains, Value: "Paris"},
+		{Type: AssertionTypeMaxTokens, Value: "10"},
+	}}
+	out := &CaseOutput{Output: "Paris", Usage: TokenUsage{TotalTokens: 12}, Latency: 2 * time.Second, Cost: 5}
+	runID := uuid.New()
+
+	result := NewTestCaseResult(runID, 3, testCase, out, nil)
+	assert.Equal(t, runID, result.RunID)
+	assert.Equal(t, 3, result.Position)
+	assert.False(t, result.Passed)
+	assert.Equal(t, []bool{true, false}, []bool{result.Assertions[0].Passed, result.Assertions[1].Passed})
+	assert.Equal(t, int64(2000), result.LatencyMS)
+
+	failed := NewTestCaseResult(runID, 0, testCase, &CaseOutput{}, errors.New("provider down"))
+	assert.False(t, failed.Passed)
+	assert.Equal(t, "provider down", failed.Error)
+	assert.Empty(t, failed.Assertions)
+
+	expected := &TestCase{Name: "expected", Expected: map[string]any{"city": "Paris"}, Assertions: []Assertion{
+		{Type: AssertionTypeEquals},
+		{Type: AssertionTypeJSONPath, Path: "$"},
+		{Type: AssertionTypeContains, Value: "Rome"},
+	}}
+	compared := NewTestCaseResult(runID, 0, expected, &CaseOutput{Output: `{"city":"Paris"}`}, nil)
+	assert.Equal(t, []bool{true, true, false}, []bool{
+		compared.Assertions[0].Passed, compared.Assertions[1].Passed, compared.Assertions[2].Passed,
+	})
+
+	run := NewTestSuiteRun(&TestSuite{ID: uuid.New()}, "gpt", time.Now())
+	run.Record(result)
+	run.Record(NewTestCaseResult(runID, 1, &TestCase{}, out, nil))
+	assert.Equal(t, 1, run.Passed)
+	assert.Equal(t, 1, run.Failed)
+	assert.Equal(t, Money(10), run.Cost)
+	assert.Equal(t, 24, run.Usage.TotalTokens)
+}
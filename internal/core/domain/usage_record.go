@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UsageRecord is the ledger entry of one LLM call: the tokens it used, the
+// model it resolved to and what it cost. The prices the cost was computed
+// with are recorded alongside, so a record stays correct after the model is
+// repriced. Records are append-only; they are never updated or deleted.
+//
+// Calls made by flow runs point at the flow, the run and the step run, and at
+// the prompt revision when the step uses a stored prompt. Calls made for test
+// suites, by the flows they run, their prompt cases or their judges, point at
+// the suite run and the case too. Purpose sets them apart from the calls of
+// flows.
+type UsageRecord struct {
+	ID               uuid.UUID    `json:"id"`
+	AccountID        uuid.UUID    `json:"account_id"`
+	ProviderID       uuid.UUID    `json:"provider_id"`
+	ModelID          uuid.UUID    `json:"model_id"`
+	Model            string       `json:"model"`
+	FlowID           *uuid.UUID   `json:"flow_id,omitempty"`
+	RunID            *uuid.UUID   `json:"run_id,omitempty"`
+	StepRunID        *uuid.UUID   `json:"step_run_id,omitempty"`
+	StepID           string       `json:"step_id,omitempty"`
+	PromptRevisionID *uuid.UUID   `json:"prompt_revision_id,omitempty"`
+	Purpose          UsagePurpose `json:"purpose"`
+	SuiteRunID       *uuid.UUID   `json:"suite_run_id,omitempty"`
+	CasePosition     *int         `json:"case_position,omitempty"`
+	Usage            TokenUsage   `json:"usage"`
+	InputPrice       Money        `json:"input_price"`
+	OutputPrice      Money        `json:"output_price"`
+	CachedPrice      Money        `json:"cached_price"`
+	Cost             Money        `json:"cost"`
+	CreatedAt        time.Time    `json:"created_at"`
+}
+
+// UsagePurpose tells what a call was made for.
+type UsagePurpose string
+
+const (
+	UsagePurposeFlow     = UsagePurpose("flow")
+	UsagePurposeTestCase = UsagePurpose("test_case")
+	UsagePurposeJudge    = UsagePurpose("judge")
+)
+
+type UsageRecordOpt func(*UsageRecord)
+
+// WithUsageRecordStep links the record to the step run of a flow run that
+// made the call.
+func WithUsageRecordStep(run *FlowRun, step *StepRun) UsageRecordOpt {
+	return func(r *UsageRecord) {
+		r.FlowID = &run.FlowID
+		r.RunID = &run.ID
+		r.StepRunID = &step.ID
+		r.StepID = step.StepID
+		r.PromptRevisionID = step.PromptRevisionID
+	}
+}
+
+// WithUsageRecordSuiteCase links the record to the case at position of a test
+// suite run, which made the call for purpose.
+func WithUsageRecordSuiteCase(runID uuid.UUID, position int, purpose UsagePurpose) UsageRecordOpt {
+	return func(r *UsageRecord) {
+		r.SuiteRunID = &runID
+		r.CasePosition = &position
+		r.Purpose = purpose
+	}
+}
+
+// WithUsageRecordPromptRevision links the record to the stored prompt
+// revision the call was made with, outside flow steps.
+func WithUsageRecordPromptRevision(id uuid.UUID) UsageRecordOpt {
+	return func(r *UsageRecord) {
+		r.PromptRevisionID = &id
+	}
+}
+
+// NewUsageRecord records a call to model that used usage, priced at the
+// model's current prices, for a flow unless an option says otherwise.
+func NewUsageRecord(model *Model, usage TokenUsage, now time.Time, opts ...UsageRecordOpt) *UsageRecord {
+	r := &UsageRecord{
+		ID:          uuid.New(),
+		AccountID:   model.AccountID,
+		ProviderID:  model.ProviderID,
+		ModelID:     model.ID,
+		Model:       model.Name,
+		Purpose:     UsagePurposeFlow,
+		Usage:       usage,
+		InputPrice:  model.InputPrice,
+		OutputPrice: model.OutputPrice,
+		CachedPrice: model.CachedPrice,
+		Cost:        model.Cost(usage),
+		CreatedAt:   now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// UsageFilter narrows a listing of usage records to one flow or run.
+type UsageFilter struct {
+	FlowID *uuid.UUID
+	RunID  *uuid.UUID
+}
@@ -0,0 +1,590 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"flow-run/internal/lib/logger"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxSubFlowDepth bounds sub-flow nesting. Flows reference each other by name,
+// so A -> B -> A cannot be rejected when either is saved.
+const maxSubFlowDepth = 8
+
+// interruptedMessage is recorded on runs whose lease expired, i.e. left
+// behind by a process that stopped, and on queued runs whose worker stopped.
+const interruptedMessage = "interrupted: the process running this flow stopped before it finished"
+
+// runLease is how long a root run stays leased to the engine executing it.
+// The engine extends the lease every third of it while the run executes.
+const runLease = time.Minute
+
+// cancelPollInterval is how often an executing run checks whether Cancel
+// was called for it in another process.
+const cancelPollInterval = time.Second
+
+var (
+	ErrEngineStopped = errors.New("engine stopped")
+	ErrSubFlowDepth  = errors.New("sub-flow nesting too deep")
+	ErrRunCanceled   = errors.New("run canceled")
+)
+
+// Engine executes flow definitions and persists every FlowRun and StepRun as
+// it goes. Steps run one at a time in dependency order; the order is stable
+// for a given definition, which keeps runs reproducible. Run executes a flow
+// in the caller's goroutine; Submit queues it for a worker, which hands the
+// job to Execute.
+type Engine struct {
+	flows     port.FlowRepository
+	models    port.ModelRepository
+	prompts   port.PromptRepository
+	providers port.ProviderRepository
+	llm       port.LLMProviderFactory
+	runs      port.FlowRunRepository
+	jobs      port.JobQueue
+	usage     port.UsageLedger
+	budgets   port.BudgetGuard
+	now       func() time.Time
+
+	mu      sync.Mutex
+	stopped bool
+	ctx     context.Context //nolint:containedctx // cancels in-flight runs on Stop
+	cancel  context.CancelFunc
+	active  sync.WaitGroup
+
+	// executing cancels the root runs executing in this process, by ID.
+	executing  map[uuid.UUID]context.CancelCauseFunc
+	cancelPoll time.Duration
+	lease      time.Duration
+}
+
+func NewEngine(
+	flows port.FlowRepository,
+	models port.ModelRepository,
+	prompts port.PromptRepository,
+	providers port.ProviderRepository,
+	llm port.LLMProviderFactory,
+	runs port.FlowRunRepository,
+	jobs port.JobQueue,
+	usage port.UsageLedger,
+	budgets port.BudgetGuard,
+) *Engine {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Engine{
+		flows:     flows,
+		models:    models,
+		prompts:   prompts,
+		providers: providers,
+		llm:       llm,
+		runs:      runs,
+		jobs:      jobs,
+		usage:     usage,
+		budgets:   budgets,
+		now:       time.Now,
+		ctx:       ctx,
+		cancel:    cancel,
+
+		executing:  map[uuid.UUID]context.CancelCauseFunc{},
+		cancelPoll: cancelPollInterval,
+		lease:      runLease,
+	}
+}
+
+// Start fails the runs whose lease expired, then keeps doing so every lease
+// period until Stop. Engines extend the leases of the runs they execute, so
+// the runs of the engines still running, in any process sharing the
+// database, are left alone.
+func (e *Engine) Start(ctx context.Context) error {
+	if err := e.failExpiredRuns(ctx); err != nil {
+		return err
+	}
+
+	e.active.Add(1)
+	go func() {
+		defer e.active.Done()
+		ticker := time.NewTicker(e.lease)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := e.failExpiredRuns(e.ctx); err != nil && e.ctx.Err() == nil {
+				logger.Log.WithError(err).Warn("Failed to fail flow runs whose lease expired")
+			}
+		}
+	}()
+	return nil
+}
+
+func (e *Engine) failExpiredRuns(ctx context.Context) error {
+	failed, err := e.runs.FailExpiredRuns(ctx, e.now(), interruptedMessage)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		logger.Log.WithField("runs", failed).Warn("Marked interrupted flow runs as failed")
+	}
+	return nil
+}
+
+// Stop cancels in-flight runs and waits until they have recorded their final
+// state, or until ctx expires.
+func (e *Engine) Stop(ctx context.Context) error {
+	e.mu.Lock()
+	e.stopped = true
+	e.mu.Unlock()
+	e.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		e.active.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunOpt adjusts a single run.
+type RunOpt func(*runOptions)
+
+type runOptions struct {
+	model     string
+	usageOpts []domain.UsageRecordOpt
+}
+
+// WithModel runs every prompt step, including those of sub-flows, on the
+// named model instead of the model the step declares.
+func WithModel(name string) RunOpt {
+	return func(o *runOptions) {
+		o.model = name
+	}
+}
+
+// WithSuiteCase records the calls of the run and its sub-flows as made for
+// the case at position of a test suite run. Runs queued by Submit drop it.
+func WithSuiteCase(suiteRunID uuid.UUID, position int) RunOpt {
+	return func(o *runOptions) {
+		o.usageOpts = append(o.usageOpts,
+			domain.WithUsageRecordSuiteCase(suiteRunID, position, domain.UsagePurposeTestCase))
+	}
+}
+
+// Run executes flow with inputs and returns the finished run. Flow failures
+// are reported through the run's status and error; the returned error is
+// reserved for invalid inputs, persistence failures, a stopped engine and
+// budgets at their hard limit, see domain.ErrBudgetExceeded. Rejected runs
+// are not recorded.
+func (e *Engine) Run(
+	ctx context.Context, flow *domain.Flow, inputs map[string]any, opts ...RunOpt,
+) (*domain.FlowRun, error) {
+	var options runOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return e.run(ctx, flow, inputs, nil, 0, options)
+}
+
+func (e *Engine) run(
+	ctx context.Context, flow *domain.Flow, inputs map[string]any, parent *uuid.UUID, depth int, options runOptions,
+) (*domain.FlowRun, error) {
+	if depth > maxSubFlowDepth {
+		return nil, ErrSubFlowDepth
+	}
+
+	resolved, err := domain.ResolveFlowInputs(flow.Definition, inputs)
+	if err != nil {
+		return nil, err
+	}
+	target := domain.BudgetTarget{AccountID: flow.AccountID, FlowID: &flow.ID}
+	if err := e.budgets.CheckRun(ctx, target); err != nil {
+		return nil, err
+	}
+
+	ctx, done, ok := e.track(ctx)
+	if !ok {
+		return nil, ErrEngineStopped
+	}
+	defer done()
+
+	var opts []domain.FlowRunOpt
+	if parent != nil {
+		opts = append(opts, domain.WithFlowRunParentStepRunID(*parent))
+	}
+	if options.model != "" {
+		opts = append(opts, domain.WithFlowRunModel(options.model))
+	}
+	run, steps := domain.NewFlowRun(flow, resolved, e.now(), opts...)
+	run.Start(e.now())
+	if parent == nil {
+		lease := e.now().Add(e.lease)
+		run.LeaseExpiresAt = &lease
+	}
+
+	// State is recorded even when ctx is canceled, so a canceled run still
+	// ends up with its final status.
+	if err := e.runs.CreateRun(context.WithoutCancel(ctx), run, steps); err != nil {
+		return nil, err
+	}
+	return e.execute(ctx, flow, run, steps, depth, options)
+}
+
+// execute runs the steps of a started run and records how it ended. Root
+// runs can be canceled while they execute; sub-flow runs are canceled with
+// them.
+func (e *Engine) execute(
+	ctx context.Context, flow *domain.Flow, run *domain.FlowRun, steps []*domain.StepRun, depth int, options runOptions,
+) (*domain.FlowRun, error) {
+	if depth == 0 {
+		var stop func()
+		ctx, stop = e.cancelable(ctx, run)
+		defer stop()
+	}
+
+	x := newExecution(e, flow, run, steps, depth, options)
+	status, message, err := x.execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	run.Finish(status, message, e.now())
+	if err := e.runs.UpdateRun(context.WithoutCancel(ctx), run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// Submit records a pending run of flow with inputs and queues a job to
+// execute it, returning the run right away. Inputs and budgets are checked
+// as Run checks them; budgets are checked again when the run starts.
+func (e *Engine) Submit(
+	ctx context.Context, flow *domain.Flow, inputs map[string]any, opts ...RunOpt,
+) (*domain.FlowRun, error) {
+	var options runOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	resolved, err := domain.ResolveFlowInputs(flow.Definition, inputs)
+	if err != nil {
+		return nil, err
+	}
+	target := domain.BudgetTarget{AccountID: flow.AccountID, FlowID: &flow.ID}
+	if err := e.budgets.CheckRun(ctx, target); err != nil {
+		return nil, err
+	}
+
+	var runOpts []domain.FlowRunOpt
+	if options.model != "" {
+		runOpts = append(runOpts, domain.WithFlowRunModel(options.model))
+	}
+	run, steps := domain.NewFlowRun(flow, resolved, e.now(), runOpts...)
+	job, err := domain.NewJob(domain.JobKindFlowRun, domain.NewFlowRunJob(run, flow), e.now(),
+		domain.WithJobSubjectID(run.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.runs.CreateRun(ctx, run, steps); err != nil {
+		return nil, err
+	}
+	if err := e.jobs.Enqueue(ctx, job); err != nil {
+		// Without its job the run would never start.
+		run.Finish(domain.RunStatusFailed, "not queued: "+err.Error(), e.now())
+		_ = e.runs.UpdateRun(context.WithoutCancel(ctx), run)
+		return nil, err
+	}
+	return run, nil
+}
+
+// Execute is the handler of JobKindFlowRun jobs. It executes the submitted
+// run unless it has finished already, e.g. when it was canceled before it
+// started. A run that an earlier attempt started is failed instead of
+// executed again, as its steps may have called models already. An error is
+// only returned when the job should be retried.
+func (e *Engine) Execute(ctx context.Context, job *domain.Job) error {
+	var payload domain.FlowRunJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	flow, err := payload.Flow()
+	if err != nil {
+		return err
+	}
+	run, err := e.runs.GetRun(ctx, payload.AccountID, payload.RunID)
+	if err != nil {
+		return err
+	}
+	if run.Status.Finished() {
+		return nil
+	}
+	steps, err := e.runs.ListStepRuns(ctx, run.ID)
+	if err != nil {
+		return err
+	}
+	if run.Status == domain.RunStatusRunning {
+		return e.abandon(ctx, run, steps, interruptedMessage)
+	}
+
+	ctx, done, ok := e.track(ctx)
+	if !ok {
+		return ErrEngineStopped
+	}
+	defer done()
+
+	target := domain.BudgetTarget{AccountID: flow.AccountID, FlowID: &flow.ID}
+	if err := e.budgets.CheckRun(ctx, target); errors.Is(err, domain.ErrBudgetExceeded) {
+		return e.abandon(ctx, run, steps, err.Error())
+	} else if err != nil {
+		return err
+	}
+
+	if err := e.runs.ExtendRunLease(ctx, run.ID, e.now().Add(e.lease)); err != nil {
+		return err
+	}
+	run.Start(e.now())
+	if err := e.runs.UpdateRun(context.WithoutCancel(ctx), run); err != nil {
+		return err
+	}
+	_, err = e.execute(ctx, flow, run, steps, 0, runOptions{model: run.Model})
+	return err
+}
+
+// Cancel cancels run id of account. A pending run is canceled right away.
+// A running run is canceled through the context it executes with, which
+// reaches the provider calls in flight, wherever it executes; Cancel returns
+// without waiting for it to finish. Canceling a canceled run changes nothing.
+// Runs that finished otherwise cannot be canceled, nor can sub-flow runs on
+// their own: they are canceled with their root run.
+func (e *Engine) Cancel(ctx context.Context, accountID, id uuid.UUID) (*domain.FlowRun, error) {
+	run, err := e.runs.GetRun(ctx, accountID, id)
+	if err != nil {
+		return nil, err
+	}
+	if run.ParentStepRunID != nil {
+		return nil, &port.ConflictError{Entity: "run", Key: id.String(), Reason: "is a sub-flow run, cancel its root run"}
+	}
+
+	run, err = e.runs.CancelRun(ctx, accountID, id, e.now())
+	if err != nil {
+		return nil, err
+	}
+	switch run.Status {
+	case domain.RunStatusCanceled:
+		return run, nil
+	case domain.RunStatusRunning:
+		e.mu.Lock()
+		cancel, ok := e.executing[id]
+		e.mu.Unlock()
+		if ok {
+			cancel(ErrRunCanceled)
+		}
+		return run, nil
+	default:
+		return nil, &port.ConflictError{Entity: "run", Key: id.String(), Reason: "has already finished"}
+	}
+}
+
+// cancelable derives the context run executes with, which Cancel cancels:
+// directly when run executes in this process, and otherwise once run's
+// CancelRequestedAt shows up in its polls. Meanwhile it extends run's lease.
+// The returned function stops watching.
+func (e *Engine) cancelable(ctx context.Context, run *domain.FlowRun) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	e.mu.Lock()
+	e.executing[run.ID] = cancel
+	e.mu.Unlock()
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(e.cancelPoll)
+		defer ticker.Stop()
+		heartbeat := time.NewTicker(e.lease / 3)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			case <-heartbeat.C:
+				if err := e.runs.ExtendRunLease(ctx, run.ID, e.now().Add(e.lease)); err != nil {
+					logger.Log.WithError(err).WithField("run", run.ID).Warn("Failed to extend the lease of a run")
+				}
+				continue
+			case <-ticker.C:
+			}
+
+			current, err := e.runs.GetRun(ctx, run.AccountID, run.ID)
+			if err != nil {
+				logger.Log.WithError(err).WithField("run", run.ID).Warn("Failed to check whether a run was canceled")
+				continue
+			}
+			if current.CancelRequestedAt != nil {
+				cancel(ErrRunCanceled)
+				return
+			}
+		}
+	}()
+
+	return ctx, func() {
+		close(stop)
+		<-stopped
+		e.mu.Lock()
+		delete(e.executing, run.ID)
+		e.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// abandon fails run with message. Its running steps fail too and its pending
+// ones are skipped.
+func (e *Engine) abandon(ctx context.Context, run *domain.FlowRun, steps []*domain.StepRun, message string) error {
+	now := e.now()
+	for _, step := range steps {
+		switch step.Status {
+		case domain.RunStatusRunning:
+			step.Finish(domain.RunStatusFailed, message, now)
+		case domain.RunStatusPending:
+			step.Finish(domain.RunStatusSkipped, "", now)
+		default:
+			continue
+		}
+		if err := e.runs.UpdateStepRun(ctx, step); err != nil {
+			return err
+		}
+	}
+	run.Finish(domain.RunStatusFailed, message, now)
+	return e.runs.UpdateRun(ctx, run)
+}
+
+// Complete sends a single chat completion to the named model of the account,
+// resolved the way prompt steps resolve theirs. It lets callers outside flows,
+// such as test suites, share the engine's providers, usage ledger and
+// lifecycle; usageOpts link the call's usage record to what it was made for.
+func (e *Engine) Complete(
+	ctx context.Context,
+	accountID uuid.UUID,
+	model string,
+	messages []domain.Message,
+	params domain.CompletionParameters,
+	usageOpts []domain.UsageRecordOpt,
+	opts ...domain.CompletionRequestOpt,
+) (*domain.CompletionResponse, error) {
+	ctx, done, ok := e.track(ctx)
+	if !ok {
+		return nil, ErrEngineStopped
+	}
+	defer done()
+
+	resp, _, err := e.complete(ctx, accountID, model, messages, params, callLink{usageOpts: usageOpts}, opts...)
+	return resp, err
+}
+
+// callLink ties a call to what it is made for: the flow whose budgets it
+// counts against, and what its usage record points at.
+type callLink struct {
+	flowID    *uuid.UUID
+	usageOpts []domain.UsageRecordOpt
+}
+
+// complete sends a chat completion and appends its usage to the ledger. The
+// call's estimated cost is reserved against its budgets first, and the call
+// is not made when that would cross a hard limit. A call whose usage cannot
+// be recorded fails, so the ledger never misses a call that succeeded.
+func (e *Engine) complete(
+	ctx context.Context,
+	accountID uuid.UUID,
+	modelName string,
+	messages []domain.Message,
+	params domain.CompletionParameters,
+	link callLink,
+	opts ...domain.CompletionRequestOpt,
+) (*domain.CompletionResponse, *domain.UsageRecord, error) {
+	model, err := e.models.GetByName(ctx, accountID, modelName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("model %q: %w", modelName, err)
+	}
+	provider, err := e.providers.Get(ctx, accountID, model.ProviderID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("provider of model %q: %w", modelName, err)
+	}
+	client, err := e.llm.NewProvider(provider)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := domain.NewCompletionRequest(model.Name, messages, params, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	target := domain.BudgetTarget{AccountID: accountID, FlowID: link.flowID, ProviderID: &model.ProviderID}
+	hold, err := e.budgets.Reserve(ctx, target, domain.EstimateCost(model, messages, params))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err := e.budgets.Settle(context.WithoutCancel(ctx), hold); err != nil {
+			logger.Log.WithError(err).Warn("Failed to settle budget reservation")
+		}
+	}()
+	if hold != nil {
+		// Expired reservations no longer count: the call must not outlive them.
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, hold.ExpiresAt)
+		defer cancel()
+	}
+
+	resp, err := client.ChatCompletion(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	record := domain.NewUsageRecord(model, resp.Usage, e.now(), link.usageOpts...)
+	if err := e.usage.Append(context.WithoutCancel(ctx), record); err != nil {
+		return nil, nil, fmt.Errorf("record usage: %w", err)
+	}
+	return resp, record, nil
+}
+
+// track registers work with the engine so Stop waits for it, and derives a
+// context that Stop cancels. ok is false once the engine is stopped.
+func (e *Engine) track(ctx context.Context) (context.Context, func(), bool) {
+	if !e.enter() {
+		return nil, nil, false
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	stopWatching := context.AfterFunc(e.ctx, func() { cancel(ErrEngineStopped) })
+	return ctx, func() {
+		stopWatching()
+		cancel(nil)
+		e.active.Done()
+	}, true
+}
+
+func (e *Engine) enter() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.stopped {
+		return false
+	}
+	e.active.Add(1)
+	return true
+}
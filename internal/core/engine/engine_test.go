@@ -0,0 +1,1194 @@
+package engine
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"flow-run/internal/flowrun/infra/memory"
+	"flow-run/internal/lib/validator"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunThreadsOutputsBetweenSteps(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, func(req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+		return &domain.CompletionResponse{
+			Message: domain.Message{Role: domain.MessageRoleAssistant, Content: "short: " + req.Messages[1].Content},
+			Usage:   domain.TokenUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		}, nil
+	})
+	flow := env.flow(t, `
+name: summarize
+inputs:
+  - {name: text, type: string, required: true}
+  - {name: tone, type: string, default: plain}
+outputs:
+  - {name: summary, type: string, from: steps.wrap.output.text}
+steps:
+  - id: summarize
+    type: prompt
+    inputs: {text: inputs.text, tone: inputs.tone}
+    prompt:
+      model: gpt
+      system: "Tone: {{ .tone }}"
+      user: "{{ .text }}"
+  - id: wrap
+    type: transform
+    inputs: {summary: steps.summarize.output}
+    transform:
+      template: '{"text": "<{{ .summary }}>"}'
+      parse_json: true
+`)
+
+	run, err := env.engine.Run(context.Background(), flow, map[string]any{"text": "long text"})
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.RunStatusSucceeded, run.Status)
+	assert.Empty(t, run.Error)
+	assert.Equal(t, map[string]any{"summary": "<short: long text>"}, run.Outputs)
+	assert.Equal(t, map[string]any{"text": "long text", "tone": "plain"}, run.Inputs)
+	assert.Equal(t, domain.TokenUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}, run.Usage)
+	assert.NotNil(t, run.StartedAt)
+	assert.NotNil(t, run.FinishedAt)
+
+	steps := env.steps(t, run.ID)
+	require.Len(t, steps, 2)
+	assert.Equal(t, domain.RunStatusSucceeded, steps[0].Status)
+	assert.Equal(t, "short: long text", steps[0].Output)
+	assert.Equal(t, 15, steps[0].Usage.TotalTokens)
+	assert.Equal(t, domain.RunStatusSucceeded, steps[1].Status)
+	assert.Equal(t, map[string]any{"summary": "short: long text"}, steps[1].Inputs)
+
+	require.Len(t, env.llm.requests, 1)
+	assert.Equal(t, "gpt", env.llm.requests[0].Model)
+	assert.Equal(t, "Tone: plain", env.llm.requests[0].Messages[0].Content)
+}
+
+func TestRunRendersStoredPrompt(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, nil)
+	prompt, err := domain.NewPrompt(
+		domain.WithPromptID(uuid.New()),
+		domain.WithPromptAccountID(env.accountID),
+		domain.WithPromptName("list"),
+		domain.WithPromptAuthor("alice"),
+		domain.WithPromptTemplate(domain.PromptTemplate{
+			System: "{{ if .formal }}Be formal.{{ else }}Be casual.{{ end }}",
+			User:   "{{ range $i, $item := .items }}{{ if $i }}, {{ end }}{{ $item }}{{ end }}",
+			Variables: []domain.FlowVariable{
+				{Name: "items", Type: domain.ValueTypeArray, Required: true},
+				{Name: "formal", Type: domain.ValueTypeBoolean, Default: false},
+			},
+		}),
+	)
+	require.NoError(t, err)
+	first := env.prompts.add(t, prompt)
+	prompt.Template.User = "{{ len .items }} items"
+	latest := env.prompts.add(t, prompt)
+
+	flow := env.flow(t, `
+name: x
+inputs:
+  - {name: items, type: array, required: true}
+steps:
+  - {id: latest, type: prompt, inputs: {items: inputs.items}, prompt: {model: gpt, prompt: list}}
+  - {id: pinned, type: prompt, inputs: {items: inputs.items}, prompt: {model: gpt, prompt: list, revision: 1}}
+`)
+
+	run, err := env.engine.Run(context.Background(), flow, map[string]any{"items": []any{"a", "b"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.RunStatusSucceeded, run.Status, run.Error)
+	require.Len(t, env.llm.requests, 2)
+	assert.Equal(t, []domain.Message{
+		{Role: domain.MessageRoleSystem, Content: "Be casual."},
+		{Role: domain.MessageRoleUser, Content: "2 items"},
+	}, env.llm.requests[0].Messages)
+	assert.Equal(t, "a, b", env.llm.requests[1].Messages[1].Content)
+
+	steps := env.steps(t, run.ID)
+	require.Len(t, steps, 2)
+	assert.Equal(t, &latest.ID, steps[0].PromptRevisionID)
+	assert.Equal(t, &first.ID, steps[1].PromptRevisionID)
+}
+
+func TestRunSkipsStepsNotSelectedByBranch(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, nil)
+	flow := env.flow(t, `
+name: route
+mode: dag
+inputs:
+  - {name: label, type: string, required: true}
+outputs:
+  - {name: billing, type: string, from: steps.billing.output}
+  - {name: other, type: string, from: steps.other.output}
+steps:
+  - id: route
+    type: branch
+    inputs: {label: inputs.label}
+    branch:
+      cases:
+        - {name: billing, when: '{{ eq .label "billing" }}', then: [billing]}
+      default: [other]
+  - {id: billing, type: transform, depends_on: [route], transform: {template: billing}}
+  - {id: other, type: transform, depends_on: [route], transform: {template: other}}
+  - {id: after_other, type: transform, depends_on: [other], transform: {template: after}}
+`)
+
+	run, err := env.engine.Run(context.Background(), flow, map[string]any{"label": "billing"})
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.RunStatusSucceeded, run.Status)
+	assert.Equal(t, map[string]any{"billing": "billing", "other": nil}, run.Outputs)
+
+	statuses := map[string]domain.RunStatus{}
+	for _, step := range env.steps(t, run.ID) {
+		statuses[step.StepID] = step.Status
+	}
+	assert.Equal(t, map[string]domain.RunStatus{
+		"route":       domain.RunStatusSucceeded,
+		"billing":     domain.RunStatusSucceeded,
+		"other":       domain.RunStatusSkipped,
+		"after_other": domain.RunStatusSkipped,
+	}, statuses)
+	assert.Equal(t, "billing", env.steps(t, run.ID)[0].Output)
+}
+
+func TestRunIfStepFails(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		source  string
+		respond func(*domain.CompletionRequest) (*domain.CompletionResponse, error)
+		wantErr string
+	}{
+		{
+			name: "provider error",
+			source: `
+name: x
+steps:
+  - {id: ask, type: prompt, prompt: {model: gpt, user: hi}}
+  - {id: after, type: transform, transform: {template: x}}
+`,
+			respond: func(*domain.CompletionRequest) (*domain.CompletionResponse, error) {
+				return nil, &port.LLMError{Provider: domain.ProviderTypeMock, StatusCode: 429, Kind: port.ErrLLMRateLimited}
+			},
+			wantErr: `step "ask": mock: llm provider rate limit exceeded`,
+		},
+		{
+			name: "template execution error",
+			source: `
+name: x
+inputs:
+  - {name: items, type: array, default: []}
+steps:
+  - id: ask
+    type: transform
+    inputs: {items: inputs.items}
+    transform: {template: "first: {{ index .items 0 }}"}
+  - {id: after, type: transform, transform: {template: x}}
+`,
+			wantErr: `step "ask": template: ask.template:1:10: executing "ask.template" at <index .items 0>: ` +
+				`error calling index: reflect: slice index out of range`,
+		},
+		{
+			name: "variable of wrong type",
+			source: `
+name: x
+inputs:
+  - {name: count, type: string, default: "three"}
+steps:
+  - id: ask
+    type: prompt
+    inputs: {count: inputs.count}
+    prompt:
+      model: gpt
+      user: "{{ range .count }}x{{ end }}"
+      variables: [{name: count, type: array, required: true}]
+  - {id: after, type: transform, transform: {template: x}}
+`,
+			wantErr: `step "ask": variables.count failed on the 'type' check: variable "count" must be of type array`,
+		},
+		{
+			name: "unknown model",
+			source: `
+name: x
+steps:
+  - {id: ask, type: prompt, prompt: {model: deleted, user: hi}}
+  - {id: after, type: transform, transform: {template: x}}
+`,
+			wantErr: `step "ask": model "deleted": record not found: model deleted`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env := newTestEnv(t, tt.respond)
+			run, err := env.engine.Run(context.Background(), env.flow(t, tt.source), nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, domain.RunStatusFailed, run.Status)
+			assert.True(t, strings.HasPrefix(run.Error, tt.wantErr), run.Error)
+
+			steps := env.steps(t, run.ID)
+			assert.Equal(t, domain.RunStatusFailed, steps[0].Status)
+			assert.NotEmpty(t, steps[0].Error)
+			assert.Equal(t, domain.RunStatusSkipped, steps[1].Status)
+		})
+	}
+}
+
+func TestRunSubFlow(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, nil)
+	env.flow(t, `
+name: shout
+inputs:
+  - {name: text, type: string, required: true}
+outputs:
+  - {name: text, type: string, from: steps.shout.output}
+steps:
+  - {id: shout, type: transform, inputs: {text: inputs.text}, transform: {template: "{{ .text }}!"}}
+`)
+	parent := env.flow(t, `
+name: parent
+outputs:
+  - {name: result, type: string, from: steps.call.output.text}
+steps:
+  - {id: hello, type: transform, transform: {template: hello}}
+  - {id: call, type: flow, inputs: {text: steps.hello.output}, flow: {name: shout}}
+`)
+
+	run, err := env.engine.Run(context.Background(), parent, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.RunStatusSucceeded, run.Status)
+	assert.Equal(t, map[string]any{"result": "hello!"}, run.Outputs)
+
+	call := env.steps(t, run.ID)[1]
+	require.NotNil(t, call.ChildRunID)
+	child := env.run(t, *call.ChildRunID)
+	assert.Equal(t, domain.RunStatusSucceeded, child.Status)
+	assert.Equal(t, &call.ID, child.ParentStepRunID)
+}
+
+func TestRunWithModel(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, nil)
+	env.flow(t, `
+name: child
+outputs:
+  - {name: text, type: string, from: steps.ask.output}
+steps:
+  - {id: ask, type: prompt, prompt: {model: unknown, user: child}}
+`)
+	parent := env.flow(t, `
+name: parent
+steps:
+  - {id: ask, type: prompt, prompt: {model: unknown, user: parent}}
+  - {id: call, type: flow, flow: {name: child}}
+`)
+
+	run, err := env.engine.Run(context.Background(), parent, nil, WithModel("gpt"))
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.RunStatusSucceeded, run.Status, run.Error)
+	assert.Equal(t, "gpt", run.Model)
+	child := env.run(t, *env.steps(t, run.ID)[1].ChildRunID)
+	assert.Equal(t, "gpt", child.Model)
+	require.Len(t, env.llm.requests, 2)
+	for _, req := range env.llm.requests {
+		assert.Equal(t, "gpt", req.Model)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, nil)
+	messages := []domain.Message{{Role: domain.MessageRoleUser, Content: "hi"}}
+	params := domain.CompletionParameters{}
+
+	resp, err := env.engine.Complete(context.Background(), env.accountID, "gpt", messages, params, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hi", resp.Message.Content)
+
+	_, err = env.engine.Complete(context.Background(), env.accountID, "unknown", messages, params, nil)
+	require.ErrorIs(t, err, port.ErrNotFound)
+}
+
+func TestRunSubFlowIfRecursive(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, nil)
+	env.flow(t, `
+name: b
+steps:
+  - {id: call, type: flow, flow: {name: a}}
+`)
+	a := env.flow(t, `
+name: a
+steps:
+  - {id: call, type: flow, flow: {name: b}}
+`)
+
+	run, err := env.engine.Run(context.Background(), a, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.RunStatusFailed, run.Status)
+	assert.Contains(t, run.Error, ErrSubFlowDepth.Error())
+}
+
+func TestRunIfInvalidInputs(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, nil)
+	flow := env.flow(t, `
+name: x
+inputs:
+  - {name: count, type: integer, required: true}
+steps:
+  - {id: a, type: transform, transform: {template: x}}
+`)
+
+	_, err := env.engine.Run(context.Background(), flow, map[string]any{"count": 1.5, "extra": true})
+
+	fields, ok := validator.FieldErrors(err)
+	require.True(t, ok)
+	require.Len(t, fields, 2)
+	assert.Equal(t, "inputs.count", fields[0].Field)
+	assert.Equal(t, "type", fields[0].Tag)
+	assert.Equal(t, "inputs.extra", fields[1].Field)
+	assert.Equal(t, "declared", fields[1].Tag)
+	assert.Empty(t, env.runs.all())
+}
+
+func TestRunIfCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	env := newTestEnv(t, func(*domain.CompletionRequest) (*domain.CompletionResponse, error) {
+		cancel()
+		return nil, context.Canceled
+	})
+	flow := env.flow(t, `
+name: x
+steps:
+  - {id: ask, type: prompt, prompt: {model: gpt, user: hi}}
+`)
+
+	run, err := env.engine.Run(ctx, flow, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.RunStatusCanceled, run.Status)
+	assert.Equal(t, domain.RunStatusCanceled, env.steps(t, run.ID)[0].Status)
+}
+
+func TestRunIfStopped(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, nil)
+	flow := env.flow(t, `
+name: x
+steps:
+  - {id: a, type: transform, transform: {template: x}}
+`)
+	require.NoError(t, env.engine.Stop(context.Background()))
+
+	_, err := env.engine.Run(context.Background(), flow, nil)
+	assert.ErrorIs(t, err, ErrEngineStopped)
+}
+
+func TestStartFailsExpiredRuns(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, nil)
+	flow := env.flow(t, `
+name: x
+steps:
+  - {id: a, type: transform, transform: {template: x}}
+`)
+	now := env.engine.now()
+	runs := map[time.Duration]*domain.FlowRun{}
+	for _, lease := range []time.Duration{-time.Second, time.Minute} {
+		run, steps := domain.NewFlowRun(flow, map[string]any{}, now)
+		run.Start(now)
+		expiresAt := now.Add(lease)
+		run.LeaseExpiresAt = &expiresAt
+		require.NoError(t, env.runs.CreateRun(context.Background(), run, steps))
+		runs[lease] = run
+	}
+
+	require.NoError(t, env.engine.Start(context.Background()))
+	t.Cleanup(func() { _ = env.engine.Stop(context.Background()) })
+
+	expired := env.run(t, runs[-time.Second].ID)
+	assert.Equal(t, domain.RunStatusFailed, expired.Status)
+	assert.Equal(t, interruptedMessage, expired.Error)
+	assert.Equal(t, domain.RunStatusRunning, env.run(t, runs[time.Minute].ID).Status)
+}
+
+func TestRunExtendsLease(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, func(req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+		time.Sleep(100 * time.Millisecond)
+		return &domain.CompletionResponse{Message: req.Messages[0]}, nil
+	})
+	env.engine.lease = 30 * time.Millisecond
+	flow := env.flow(t, `
+name: x
+steps:
+  - {id: ask, type: prompt, prompt: {model: gpt, user: hi}}
+`)
+
+	run, err := env.engine.Run(context.Background(), flow, nil)
+	require.NoError(t, err)
+	require.Equal(t, domain.RunStatusSucceeded, run.Status)
+
+	stored := env.run(t, run.ID)
+	require.NotNil(t, stored.LeaseExpiresAt)
+	assert.True(t, stored.LeaseExpiresAt.After(run.StartedAt.Add(env.engine.lease)))
+}
+
+func TestSubmit(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	env := newTestEnv(t, nil)
+	flow := env.flow(t, `
+name: x
+inputs:
+  - {name: text, type: string, required: true}
+outputs:
+  - {name: answer, type: string, from: steps.ask.output}
+steps:
+  - {id: ask, type: prompt, inputs: {text: inputs.text}, prompt: {model: gpt, user: "{{ .text }}"}}
+`)
+
+	run, err := env.engine.Submit(ctx, flow, map[string]any{"text": "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStatusPending, env.run(t, run.ID).Status)
+	assert.Empty(t, env.llm.requests)
+
+	// The run executes the flow as submitted, whatever became of it since.
+	require.NoError(t, env.flows.Delete(ctx, env.accountID, flow.ID))
+
+	job := env.claim(t)
+	assert.Equal(t, &run.ID, job.SubjectID)
+	require.NoError(t, env.engine.Execute(ctx, job))
+
+	stored := env.run(t, run.ID)
+	assert.Equal(t, domain.RunStatusSucceeded, stored.Status)
+	assert.Equal(t, map[string]any{"answer": "hi"}, stored.Outputs)
+	assert.Equal(t, domain.RunStatusSucceeded, env.steps(t, run.ID)[0].Status)
+}
+
+func TestSubmitIfInvalid(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, nil)
+	flow := env.flow(t, `
+name: x
+inputs:
+  - {name: text, type: string, required: true}
+steps:
+  - {id: a, type: transform, inputs: {text: inputs.text}, transform: {template: "{{ .text }}"}}
+`)
+
+	_, err := env.engine.Submit(context.Background(), flow, nil)
+	var validationErr *validator.Error
+	require.ErrorAs(t, err, &validationErr)
+
+	env.budgets.checkErr = &domain.BudgetExceededError{Budget: &domain.Budget{Name: "monthly"}}
+	_, err = env.engine.Submit(context.Background(), flow, map[string]any{"text": "x"})
+	require.ErrorIs(t, err, domain.ErrBudgetExceeded)
+
+	assert.Empty(t, env.runs.all())
+}
+
+func TestExecuteIfNotPending(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		status     domain.RunStatus
+		wantStatus domain.RunStatus
+		wantError  string
+	}{
+		// A worker died while it executed the run.
+		{name: "interrupted", status: domain.RunStatusRunning, wantStatus: domain.RunStatusFailed,
+			wantError: interruptedMessage},
+		{name: "canceled", status: domain.RunStatusCanceled, wantStatus: domain.RunStatusCanceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			env := newTestEnv(t, nil)
+			flow := env.flow(t, `
+name: x
+steps:
+  - {id: ask, type: prompt, prompt: {model: gpt, user: hi}}
+`)
+			run, err := env.engine.Submit(ctx, flow, nil)
+			require.NoError(t, err)
+			run.Status = tt.status
+			require.NoError(t, env.runs.UpdateRun(ctx, run))
+
+			require.NoError(t, env.engine.Execute(ctx, env.claim(t)))
+
+			stored := env.run(t, run.ID)
+			assert.Equal(t, tt.wantStatus, stored.Status)
+			assert.Equal(t, tt.wantError, stored.Error)
+			assert.Empty(t, env.llm.requests)
+		})
+	}
+}
+
+func TestExecuteIfBudgetExceeded(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	env := newTestEnv(t, nil)
+	flow := env.flow(t, `
+name: x
+steps:
+  - {id: ask, type: prompt, prompt: {model: gpt, user: hi}}
+`)
+	run, err := env.engine.Submit(ctx, flow, nil)
+	require.NoError(t, err)
+
+	env.budgets.checkErr = &domain.BudgetExceededError{Budget: &domain.Budget{Name: "monthly"}}
+	require.NoError(t, env.engine.Execute(ctx, env.claim(t)))
+
+	stored := env.run(t, run.ID)
+	assert.Equal(t, domain.RunStatusFailed, stored.Status)
+	assert.Equal(t, env.budgets.checkErr.Error(), stored.Error)
+	assert.Equal(t, domain.RunStatusSkipped, env.steps(t, run.ID)[0].Status)
+	assert.Empty(t, env.llm.requests)
+}
+
+func TestCancel(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	env := newTestEnv(t, nil)
+	flow := env.flow(t, `
+name: x
+steps:
+  - {id: ask, type: prompt, prompt: {model: gpt, user: hi}}
+`)
+
+	run, err := env.engine.Submit(ctx, flow, nil)
+	require.NoError(t, err)
+	canceled, err := env.engine.Cancel(ctx, env.accountID, run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStatusCanceled, canceled.Status)
+	assert.NotNil(t, canceled.CancelRequestedAt)
+	assert.Equal(t, domain.RunStatusSkipped, env.steps(t, run.ID)[0].Status)
+
+	// Canceling again changes nothing, and the job finds nothing to do.
+	_, err = env.engine.Cancel(ctx, env.accountID, run.ID)
+	require.NoError(t, err)
+	require.NoError(t, env.engine.Execute(ctx, env.claim(t)))
+	assert.Equal(t, domain.RunStatusCanceled, env.run(t, run.ID).Status)
+	assert.Empty(t, env.llm.requests)
+
+	finished, err := env.engine.Run(ctx, flow, nil)
+	require.NoError(t, err)
+	_, err = env.engine.Cancel(ctx, env.accountID, finished.ID)
+	require.ErrorIs(t, err, port.ErrConflict)
+	assert.Equal(t, domain.RunStatusSucceeded, env.run(t, finished.ID).Status)
+
+	_, err = env.engine.Cancel(ctx, uuid.New(), run.ID)
+	assert.ErrorIs(t, err, port.ErrNotFound)
+}
+
+func TestCancelWhileRunning(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		cancel func(env *testEnv, id uuid.UUID) error
+	}{
+		{name: "in process", cancel: func(env *testEnv, id uuid.UUID) error {
+			_, err := env.engine.Cancel(context.Background(), env.accountID, id)
+			return err
+		}},
+		// Another engine, sharing the repository, only records the request.
+		{name: "in another process", cancel: func(env *testEnv, id uuid.UUID) error {
+			_, err := env.runs.CancelRun(context.Background(), env.accountID, id, time.Now())
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			env := newTestEnv(t, nil)
+			env.llm.blocked = make(chan struct{})
+			env.engine.cancelPoll = 10 * time.Millisecond
+			flow := env.flow(t, `
+name: x
+steps:
+  - {id: ask, type: prompt, prompt: {model: gpt, user: hi}}
+  - {id: b, type: transform, transform: {template: x}}
+`)
+
+			result := make(chan *domain.FlowRun)
+			go func() {
+				run, err := env.engine.Run(context.Background(), flow, nil)
+				assert.NoError(t, err)
+				result <- run
+			}()
+			<-env.llm.blocked
+
+			ids := env.runs.all()
+			require.Len(t, ids, 1)
+			require.NoError(t, tt.cancel(env, ids[0]))
+
+			run := <-result
+			assert.Equal(t, domain.RunStatusCanceled, run.Status)
+			assert.Contains(t, run.Error, ErrRunCanceled.Error())
+
+			steps := env.steps(t, run.ID)
+			assert.Equal(t, domain.RunStatusCanceled, steps[0].Status)
+			assert.Equal(t, domain.RunStatusSkipped, steps[1].Status)
+			assert.Equal(t, domain.RunStatusCanceled, env.run(t, run.ID).Status)
+		})
+	}
+}
+
+func TestRunRecordsUsage(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, func(*domain.CompletionRequest) (*domain.CompletionResponse, error) {
+		return &domain.CompletionResponse{
+			Usage: domain.TokenUsage{PromptTokens: 1000, CompletionTokens: 100, TotalTokens: 1100, CacheReadTokens: 400},
+		}, nil
+	})
+	env.model.InputPrice = 2_000_000_000
+	env.model.OutputPrice = 8_000_000_000
+	env.model.CachedPrice = 500_000_000
+	env.saveModel(t)
+	env.flow(t, `
+name: child
+steps:
+  - {id: ask, type: prompt, prompt: {model: gpt, user: hi}}
+`)
+	parent := env.flow(t, `
+name: parent
+steps:
+  - {id: ask, type: prompt, prompt: {model: gpt, user: hi}}
+  - {id: call, type: flow, flow: {name: child}}
+`)
+
+	run, err := env.engine.Run(context.Background(), parent, nil)
+	require.NoError(t, err)
+
+	// 600 uncached and 400 cached prompt tokens, 100 completion tokens.
+	const callCost = domain.Money(2_200_000)
+	assert.Equal(t, 2*callCost, run.Cost)
+	steps := env.steps(t, run.ID)
+	assert.Equal(t, callCost, steps[0].Cost)
+	assert.Equal(t, callCost, steps[1].Cost)
+
+	records := env.usage.all()
+	require.Len(t, records, 2)
+	assert.Equal(t, env.model.ID, records[0].ModelID)
+	assert.Equal(t, "gpt", records[0].Model)
+	assert.Equal(t, &parent.ID, records[0].FlowID)
+	assert.Equal(t, &run.ID, records[0].RunID)
+	assert.Equal(t, &steps[0].ID, records[0].StepRunID)
+	assert.Equal(t, "ask", records[0].StepID)
+	assert.Equal(t, 400, records[0].Usage.CacheReadTokens)
+	assert.Equal(t, env.model.CachedPrice, records[0].CachedPrice)
+	assert.Equal(t, callCost, records[0].Cost)
+	assert.Equal(t, domain.UsagePurposeFlow, records[0].Purpose)
+	assert.Equal(t, steps[1].ChildRunID, records[1].RunID)
+
+	// Calls outside flows are recorded against what the caller links them to.
+	suiteRunID := uuid.New()
+	messages := []domain.Message{{Role: domain.MessageRoleUser, Content: "hi"}}
+	_, err = env.engine.Complete(context.Background(), env.accountID, "gpt", messages, domain.CompletionParameters{},
+		[]domain.UsageRecordOpt{domain.WithUsageRecordSuiteCase(suiteRunID, 3, domain.UsagePurposeJudge)})
+	require.NoError(t, err)
+	records = env.usage.all()
+	require.Len(t, records, 3)
+	assert.Nil(t, records[2].RunID)
+	assert.Equal(t, env.accountID, records[2].AccountID)
+	assert.Equal(t, domain.UsagePurposeJudge, records[2].Purpose)
+	assert.Equal(t, &suiteRunID, records[2].SuiteRunID)
+	assert.Equal(t, 3, *records[2].CasePosition)
+
+	// So are the calls of runs made for a test suite, sub-flows included.
+	_, err = env.engine.Run(context.Background(), parent, nil, WithSuiteCase(suiteRunID, 4))
+	require.NoError(t, err)
+	records = env.usage.all()
+	require.Len(t, records, 5)
+	for _, record := range records[3:] {
+		assert.Equal(t, domain.UsagePurposeTestCase, record.Purpose)
+		assert.Equal(t, &suiteRunID, record.SuiteRunID)
+		assert.Equal(t, 4, *record.CasePosition)
+	}
+}
+
+func TestRunIfUsageCannotBeRecorded(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, nil)
+	env.usage.err = errors.New("disk full")
+	flow := env.flow(t, `
+name: x
+steps:
+  - {id: ask, type: prompt, prompt: {model: gpt, user: hi}}
+`)
+
+	run, err := env.engine.Run(context.Background(), flow, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.RunStatusFailed, run.Status)
+	assert.Equal(t, `step "ask": record usage: disk full`, run.Error)
+}
+
+func TestRunReservesBudget(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, nil)
+	env.model.InputPrice = 1_000_000_000
+	env.model.OutputPrice = 2_000_000_000
+	env.saveModel(t)
+	flow := env.flow(t, `
+name: x
+steps:
+  - {id: ask, type: prompt, prompt: {model: gpt, user: hello, parameters: {max_tokens: 100}}}
+`)
+
+	run, err := env.engine.Run(context.Background(), flow, nil)
+	require.NoError(t, err)
+	require.Equal(t, domain.RunStatusSucceeded, run.Status, run.Error)
+
+	target := domain.BudgetTarget{AccountID: env.accountID, FlowID: &flow.ID}
+	assert.Equal(t, []domain.BudgetTarget{target}, env.budgets.checked)
+	target.ProviderID = &env.model.ProviderID
+	assert.Equal(t, []domain.BudgetTarget{target}, env.budgets.reserved)
+	// "hello" is taken as 2 prompt tokens at $1 and max_tokens as 100
+	// completion tokens at $2 per million.
+	assert.Equal(t, []domain.Money{202_000}, env.budgets.amounts)
+	assert.Equal(t, 1, env.budgets.settled)
+}
+
+func TestRunIfBudgetExceeded(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, nil)
+	env.budgets.checkErr = &domain.BudgetExceededError{Budget: &domain.Budget{Name: "monthly"}}
+	flow := env.flow(t, `
+name: x
+steps:
+  - {id: ask, type: prompt, prompt: {model: gpt, user: hi}}
+`)
+
+	_, err := env.engine.Run(context.Background(), flow, nil)
+
+	require.ErrorIs(t, err, domain.ErrBudgetExceeded)
+	assert.Empty(t, env.runs.all())
+	assert.Empty(t, env.llm.requests)
+}
+
+func TestRunIfCallExceedsBudget(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, nil)
+	env.budgets.reserveErr = &domain.BudgetExceededError{Budget: &domain.Budget{Name: "daily"}}
+	flow := env.flow(t, `
+name: x
+steps:
+  - {id: ask, type: prompt, prompt: {model: gpt, user: hi}}
+`)
+
+	run, err := env.engine.Run(context.Background(), flow, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.RunStatusFailed, run.Status)
+	assert.Contains(t, run.Error, `budget exceeded: budget "daily"`)
+	assert.Empty(t, env.llm.requests)
+	assert.Empty(t, env.usage.all())
+	assert.Zero(t, env.budgets.settled)
+}
+
+func TestRunIfReservationExpires(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, nil)
+	env.budgets.ttl = time.Millisecond
+	env.llm.blocked = make(chan struct{}, 1)
+	flow := env.flow(t, `
+name: x
+steps:
+  - {id: ask, type: prompt, prompt: {model: gpt, user: hi}}
+`)
+
+	run, err := env.engine.Run(context.Background(), flow, nil)
+	require.NoError(t, err)
+
+	// The call is cut off once its reservations no longer count.
+	assert.Equal(t, domain.RunStatusFailed, run.Status)
+	assert.Contains(t, run.Error, context.DeadlineExceeded.Error())
+	assert.Equal(t, 1, env.budgets.settled)
+}
+
+type testEnv struct {
+	accountID uuid.UUID
+	engine    *Engine
+	model     *domain.Model
+	flows     *memory.FlowRepository
+	models    *memory.ModelRepository
+	llm       *fakeLLM
+	prompts   fakePrompts
+	runs      *recordedRuns
+	jobs      *memory.JobQueue
+	usage     *fakeLedger
+	budgets   *fakeBudgets
+}
+
+// newTestEnv builds an engine with one model, "gpt", whose provider answers
+// with respond. A nil respond echoes the last message.
+func TestEstimate(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, nil)
+	env.model.InputPrice = 1_000_000_000
+	env.model.OutputPrice = 2_000_000_000
+	env.saveModel(t)
+	env.flow(t, `
+name: child
+inputs:
+  - {name: question, type: object}
+steps:
+  - {id: ask, type: prompt, inputs: {question: inputs.question}, prompt: {model: gpt, user: "{{ .question.text }}"}}
+`)
+	flow := env.flow(t, `
+name: x
+inputs:
+  - {name: text, type: string, required: true}
+steps:
+  - id: summarize
+    type: prompt
+    inputs: {text: inputs.text}
+    prompt: {model: gpt, system: Be brief., user: "{{ .text }}", parameters: {max_tokens: 100}}
+  - {id: wrap, type: transform, inputs: {summary: steps.summarize.output}, transform: {template: "<{{ .summary }}>"}}
+  - id: review
+    type: prompt
+    inputs: {summary: steps.summarize.output, wrapped: steps.wrap.output}
+    prompt: {model: gpt, user: "Review {{ .wrapped }}"}
+  - {id: call, type: flow, flow: {name: child}}
+`)
+	lengths, err := domain.NewCompletionLengths(0, map[string]int{"review": 50})
+	require.NoError(t, err)
+
+	estimate, err := env.engine.Estimate(context.Background(), flow, map[string]any{"text": "long text"}, lengths)
+	require.NoError(t, err)
+
+	count := func(text string) int {
+		n, _ := countTokens("gpt", text)
+		return n
+	}
+	_, encoding := countTokens("gpt", "")
+	usage := func(prompt, completion int) domain.TokenUsage {
+		return domain.TokenUsage{PromptTokens: prompt, CompletionTokens: completion, TotalTokens: prompt + completion}
+	}
+	want := []*domain.StepEstimate{
+		{StepID: "summarize", Rendered: true, Usage: usage(9+count("Be brief.")+count("long text"), 100)},
+		// The completion of summarize is read again, and wrap renders
+		// around its empty output.
+		{StepID: "review", Rendered: true, Usage: usage(6+count("Review <>")+100, 50)},
+		// The child's template reads into an input it is not given, so its
+		// source is counted.
+		{StepID: "call.ask", Usage: usage(6+count("{{ .question.text }}"), domain.DefaultCompletionTokens)},
+	}
+	for _, step := range want {
+		step.Model, step.Encoding, step.Cost = "gpt", encoding, env.model.Cost(step.Usage)
+	}
+	assert.Equal(t, domain.NewFlowEstimate(flow.ID, want), estimate)
+	assert.Empty(t, env.llm.requests)
+	assert.Empty(t, env.runs.all())
+	assert.Empty(t, env.budgets.reserved)
+}
+
+func TestEstimateIfInvalid(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t, nil)
+	flow := env.flow(t, `
+name: x
+inputs:
+  - {name: text, type: string, required: true}
+steps:
+  - {id: ask, type: prompt, inputs: {text: inputs.text}, prompt: {model: gpt, user: "{{ .text }}"}}
+`)
+	lengths, err := domain.NewCompletionLengths(0, nil)
+	require.NoError(t, err)
+
+	_, err = env.engine.Estimate(context.Background(), flow, nil, lengths)
+	var validationErr *validator.Error
+	require.ErrorAs(t, err, &validationErr)
+
+	_, err = env.engine.Estimate(context.Background(), flow, map[string]any{"text": "x"}, lengths, WithModel("unknown"))
+	require.ErrorIs(t, err, port.ErrNotFound)
+}
+
+func newTestEnv(t *testing.T, respond func(*domain.CompletionRequest) (*domain.CompletionResponse, error)) *testEnv {
+	t.Helper()
+
+	if respond == nil {
+		respond = func(req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+			return &domain.CompletionResponse{Message: req.Messages[len(req.Messages)-1]}, nil
+		}
+	}
+
+	accountID := uuid.New()
+	provider := &domain.Provider{ID: uuid.New(), AccountID: accountID, Type: domain.ProviderTypeMock}
+	model := &domain.Model{ID: uuid.New(), Name: "gpt", AccountID: accountID, ProviderID: provider.ID}
+
+	providers := memory.NewProviderRepository()
+	require.NoError(t, providers.Create(context.Background(), provider))
+
+	env := &testEnv{
+		accountID: accountID,
+		model:     model,
+		flows:     memory.NewFlowRepository(),
+		models:    memory.NewModelRepository(),
+		llm:       &fakeLLM{respond: respond},
+		prompts:   fakePrompts{},
+		runs:      &recordedRuns{RunRepository: memory.NewRunRepository()},
+		jobs:      memory.NewJobQueue(),
+		usage:     &fakeLedger{},
+		budgets:   &fakeBudgets{},
+	}
+	require.NoError(t, env.models.Create(context.Background(), model))
+	env.engine = NewEngine(
+		env.flows,
+		env.models,
+		env.prompts,
+		providers,
+		env.llm,
+		env.runs,
+		env.jobs,
+		env.usage,
+		env.budgets,
+	)
+	return env
+}
+
+func (e *testEnv) flow(t *testing.T, source string) *domain.Flow {
+	t.Helper()
+
+	flow, err := domain.NewFlow(
+		domain.WithFlowID(uuid.New()),
+		domain.WithFlowAccountID(e.accountID),
+		domain.WithFlowSource(source, domain.FlowSourceFormatYAML),
+	)
+	require.NoError(t, err)
+	require.NoError(t, e.flows.Create(context.Background(), flow))
+	return flow
+}
+
+// claim claims the next job of the queue.
+func (e *testEnv) claim(t *testing.T) *domain.Job {
+	t.Helper()
+
+	now := time.Now()
+	kinds := []domain.JobKind{domain.JobKindFlowRun}
+	job, err := e.jobs.Claim(context.Background(), "test", kinds, now, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	return job
+}
+
+// saveModel stores changes made to e.model.
+func (e *testEnv) saveModel(t *testing.T) {
+	t.Helper()
+
+	require.NoError(t, e.models.Update(context.Background(), e.model))
+}
+
+func (e *testEnv) run(t *testing.T, id uuid.UUID) *domain.FlowRun {
+	t.Helper()
+
+	run, err := e.runs.GetRun(context.Background(), e.accountID, id)
+	require.NoError(t, err)
+	return run
+}
+
+func (e *testEnv) steps(t *testing.T, runID uuid.UUID) []*domain.StepRun {
+	t.Helper()
+
+	steps, err := e.runs.ListStepRuns(context.Background(), runID)
+	require.NoError(t, err)
+	return steps
+}
+
+// fakePrompts holds the revisions of each prompt by name, oldest first.
+type fakePrompts map[string][]*domain.PromptRevision
+
+func (f fakePrompts) add(t *testing.T, prompt *domain.Prompt) *domain.PromptRevision {
+	t.Helper()
+
+	revision, err := domain.NewPromptRevision(prompt, len(f[prompt.Name])+1, time.Now())
+	require.NoError(t, err)
+	f[prompt.Name] = append(f[prompt.Name], revision)
+	return revision
+}
+
+func (f fakePrompts) ResolvePrompt(
+	_ context.Context, accountID uuid.UUID, ref domain.PromptRef,
+) (*domain.PromptRevision, error) {
+	revisions := f[ref.Name]
+	if len(revisions) == 0 || revisions[0].AccountID != accountID || ref.Revision > len(revisions) {
+		return nil, &port.NotFoundError{Entity: "prompt", Key: ref.String()}
+	}
+	if ref.Revision > 0 {
+		return revisions[ref.Revision-1], nil
+	}
+	return revisions[len(revisions)-1], nil
+}
+
+type fakeLLM struct {
+	respond  func(*domain.CompletionRequest) (*domain.CompletionResponse, error)
+	requests []*domain.CompletionRequest
+	// blocked, when set, receives every call, which then waits until its
+	// context is canceled instead of responding.
+	blocked chan struct{}
+}
+
+func (f *fakeLLM) NewProvider(*domain.Provider) (port.LLMProvider, error) {
+	return f, nil
+}
+
+func (f *fakeLLM) ChatCompletion(
+	ctx context.Context, req *domain.CompletionRequest,
+) (*domain.CompletionResponse, error) {
+	f.requests = append(f.requests, req)
+	if f.blocked != nil {
+		f.blocked <- struct{}{}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return f.respond(req)
+}
+
+// recordedRuns remembers which runs were created.
+type recordedRuns struct {
+	*memory.RunRepository
+
+	mu      sync.Mutex
+	created []uuid.UUID
+}
+
+func (r *recordedRuns) CreateRun(ctx context.Context, run *domain.FlowRun, steps []*domain.StepRun) error {
+	r.mu.Lock()
+	r.created = append(r.created, run.ID)
+	r.mu.Unlock()
+
+	return r.RunRepository.CreateRun(ctx, run, steps)
+}
+
+func (r *recordedRuns) all() []uuid.UUID {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.created)
+}
+
+type fakeLedger struct {
+	mu      sync.Mutex
+	records []*domain.UsageRecord
+	err     error
+}
+
+func (f *fakeLedger) Append(_ context.Context, record *domain.UsageRecord) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return f.err
+	}
+	f.records = append(f.records, record)
+	return nil
+}
+
+func (f *fakeLedger) all() []*domain.UsageRecord {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Clone(f.records)
+}
+
+type fakeBudgets struct {
+	mu         sync.Mutex
+	checkErr   error
+	reserveErr error
+	checked    []domain.BudgetTarget
+	reserved   []domain.BudgetTarget
+	amounts    []domain.Money
+	settled    int
+	// ttl is how long holds last, an hour when zero.
+	ttl time.Duration
+}
+
+func (f *fakeBudgets) CheckRun(_ context.Context, target domain.BudgetTarget) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.checked = append(f.checked, target)
+	return f.checkErr
+}
+
+func (f *fakeBudgets) Reserve(
+	_ context.Context, target domain.BudgetTarget, amount domain.Money,
+) (*domain.BudgetHold, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.reserveErr != nil {
+		return nil, f.reserveErr
+	}
+	f.reserved = append(f.reserved, target)
+	f.amounts = append(f.amounts, amount)
+	ttl := cmp.Or(f.ttl, time.Hour)
+	return &domain.BudgetHold{ExpiresAt: time.Now().Add(ttl)}, nil
+}
+
+func (f *fakeBudgets) Settle(_ context.Context, hold *domain.BudgetHold) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if hold != nil {
+		f.settled++
+	}
+	return nil
+}
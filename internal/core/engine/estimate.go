@@ -0,0 +1,177 @@
+package engine
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/lib/tokenizer"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Estimate prices a run of flow with inputs without running it or calling
+// any provider. Prompts are rendered and their tokens counted locally, and
+// each prompt step is assumed to complete with the tokens lengths gives it.
+//
+// Only flow inputs are known before running. Prompt steps are taken to
+// output empty text, and the tokens they complete with are added to the
+// prompt of each step that reads them directly; transform steps are
+// rendered from these values. Branches are not evaluated: the steps of
+// every case count, which makes the estimate an upper bound.
+func (e *Engine) Estimate(
+	ctx context.Context, flow *domain.Flow, inputs map[string]any, lengths *domain.CompletionLengths, opts ...RunOpt,
+) (*domain.FlowEstimate, error) {
+	var options runOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	resolved, err := domain.ResolveFlowInputs(flow.Definition, inputs)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := e.estimate(ctx, flow, resolved, "", 0, lengths, options)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewFlowEstimate(flow.ID, steps), nil
+}
+
+// estimate estimates the prompt steps of flow, and of its sub-flows, in the
+// order they would run. prefix is the path of flow's steps.
+func (e *Engine) estimate(
+	ctx context.Context,
+	flow *domain.Flow,
+	inputs map[string]any,
+	prefix string,
+	depth int,
+	lengths *domain.CompletionLengths,
+	options runOptions,
+) ([]*domain.StepEstimate, error) {
+	if depth > maxSubFlowDepth {
+		return nil, ErrSubFlowDepth
+	}
+
+	definition := flow.Definition
+	outputs := map[string]any{}
+	completions := map[string]int{}
+	var estimates []*domain.StepEstimate
+	for _, i := range order(definition) {
+		step := &definition.Steps[i]
+		path := prefix + step.ID
+		stepInputs := make(map[string]any, len(step.Inputs))
+		read := 0
+		for name, ref := range step.Inputs {
+			parsed, err := domain.ParseReference(ref)
+			if err != nil {
+				return nil, err
+			}
+			source := inputs
+			if parsed.Source == domain.ReferenceSourceSteps {
+				source = outputs
+				read += completions[parsed.Name]
+			}
+			stepInputs[name], _ = domain.LookupPath(source[parsed.Name], parsed.Path)
+		}
+
+		switch step.Type {
+		case domain.StepTypePrompt:
+			estimate, err := e.estimatePrompt(ctx, flow.AccountID, step, path, stepInputs, read, lengths, options)
+			if err != nil {
+				return nil, err
+			}
+			completions[step.ID] = estimate.Usage.CompletionTokens
+			outputs[step.ID] = ""
+			estimates = append(estimates, estimate)
+		case domain.StepTypeTransform:
+			if result, err := runTransform(step, stepInputs); err == nil {
+				outputs[step.ID] = result.output
+			}
+		case domain.StepTypeFlow:
+			child, err := e.flows.GetByName(ctx, flow.AccountID, step.Flow.Name)
+			if err != nil {
+				return nil, fmt.Errorf("flow %q: %w", step.Flow.Name, err)
+			}
+			childInputs, err := domain.ResolveFlowInputs(child.Definition, stepInputs)
+			if err != nil {
+				childInputs = stepInputs
+			}
+			childEstimates, err := e.estimate(ctx, child, childInputs, path+".", depth+1, lengths, options)
+			if err != nil {
+				return nil, fmt.Errorf("flow %q: %w", step.Flow.Name, err)
+			}
+			estimates = append(estimates, childEstimates...)
+		case domain.StepTypeBranch:
+		}
+	}
+	return estimates, nil
+}
+
+// estimatePrompt estimates a prompt step whose inputs also carry extra tokens
+// of completions they were rendered without.
+func (e *Engine) estimatePrompt(
+	ctx context.Context,
+	accountID uuid.UUID,
+	step *domain.Step,
+	path string,
+	inputs map[string]any,
+	extra int,
+	lengths *domain.CompletionLengths,
+	options runOptions,
+) (*domain.StepEstimate, error) {
+	prompt := step.Prompt
+	template, name := prompt.Template(), step.ID
+	if prompt.Prompt != "" {
+		ref := prompt.Ref()
+		revision, err := e.prompts.ResolvePrompt(ctx, accountID, ref)
+		if err != nil {
+			return nil, fmt.Errorf("prompt %q: %w", ref, err)
+		}
+		template, name = &revision.Template, fmt.Sprintf("%s@%d", ref.Name, revision.Number)
+	}
+
+	modelName := prompt.Model
+	if options.model != "" {
+		modelName = options.model
+	}
+	model, err := e.models.GetByName(ctx, accountID, modelName)
+	if err != nil {
+		return nil, fmt.Errorf("model %q: %w", modelName, err)
+	}
+
+	estimate := &domain.StepEstimate{StepID: path, Model: model.Name, Rendered: true}
+	messages, err := template.Render(name, inputs)
+	if err != nil {
+		// Inputs that only exist once earlier steps ran can fail to render;
+		// the template source is the next best measure of the prompt.
+		estimate.Rendered = false
+		messages = templateMessages(template)
+	}
+
+	promptTokens, encoding := domain.CountPromptTokens(countTokens, model.Name, messages)
+	estimate.Encoding = encoding
+	estimate.Usage.PromptTokens = promptTokens + extra
+	estimate.Usage.CompletionTokens = lengths.For(path, prompt.Parameters)
+	estimate.Usage.TotalTokens = estimate.Usage.PromptTokens + estimate.Usage.CompletionTokens
+	estimate.Cost = model.Cost(estimate.Usage)
+	return estimate, nil
+}
+
+// templateMessages returns the unrendered messages of template.
+func templateMessages(template *domain.PromptTemplate) []domain.Message {
+	var messages []domain.Message
+	if template.System != "" {
+		messages = append(messages, domain.Message{Role: domain.MessageRoleSystem, Content: template.System})
+	}
+	return append(messages, domain.Message{Role: domain.MessageRoleUser, Content: template.User})
+}
+
+// countTokens counts with the encoding of model when its table is vendored,
+// and approximates otherwise.
+func countTokens(model, text string) (int, string) {
+	encoding, err := tokenizer.Get(tokenizer.ForModel(model))
+	if err != nil {
+		return domain.ApproximateTokens(text), ""
+	}
+	return encoding.Count(text), encoding.Name()
+}
@@ -0,0 +1,290 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+	"fmt"
+	"slices"
+)
+
+// execution holds the state of one run while its steps execute.
+type execution struct {
+	engine  *Engine
+	flow    *domain.Flow
+	run     *domain.FlowRun
+	steps   []*domain.StepRun
+	depth   int
+	options runOptions
+
+	// selected records, per finished branch step, the targets it chose.
+	selected map[string][]string
+}
+
+// stepResult is what a step type produces besides its status.
+type stepResult struct {
+	output   any
+	usage    domain.TokenUsage
+	cost     domain.Money
+	childRun *domain.FlowRun
+	// targets is set by branch steps.
+	targets []string
+}
+
+func newExecution(
+	engine *Engine, flow *domain.Flow, run *domain.FlowRun, steps []*domain.StepRun, depth int, options runOptions,
+) *execution {
+	return &execution{
+		engine:   engine,
+		flow:     flow,
+		run:      run,
+		steps:    steps,
+		depth:    depth,
+		options:  options,
+		selected: map[string][]string{},
+	}
+}
+
+// execute runs every step and resolves the flow outputs. It returns the final
+// run status and error message; the error is only set when state could not
+// be persisted.
+func (x *execution) execute(ctx context.Context) (domain.RunStatus, string, error) {
+	for _, i := range order(x.flow.Definition) {
+		step := &x.flow.Definition.Steps[i]
+		stepRun := x.steps[i]
+
+		// A run canceled between steps starts no more of them, even those
+		// that call no model.
+		if ctx.Err() != nil {
+			if err := x.skipRemaining(ctx); err != nil {
+				return "", "", err
+			}
+			return domain.RunStatusCanceled, context.Cause(ctx).Error(), nil
+		}
+		if x.skipped(i) {
+			stepRun.Finish(domain.RunStatusSkipped, "", x.engine.now())
+			if err := x.engine.runs.UpdateStepRun(context.WithoutCancel(ctx), stepRun); err != nil {
+				return "", "", err
+			}
+			continue
+		}
+
+		status, stepErr, err := x.executeStep(ctx, step, stepRun)
+		if err != nil {
+			return "", "", err
+		}
+		if status != domain.RunStatusSucceeded {
+			if err := x.skipRemaining(ctx); err != nil {
+				return "", "", err
+			}
+			return status, fmt.Sprintf("step %q: %s", step.ID, stepErr), nil
+		}
+	}
+
+	outputs, err := x.outputs()
+	if err != nil {
+		return domain.RunStatusFailed, err.Error(), nil
+	}
+	x.run.Outputs = outputs
+	return domain.RunStatusSucceeded, "", nil
+}
+
+// executeStep runs one step and persists its state before and after.
+func (x *execution) executeStep(
+	ctx context.Context, step *domain.Step, stepRun *domain.StepRun,
+) (domain.RunStatus, string, error) {
+	persistCtx := context.WithoutCancel(ctx)
+
+	inputs, inputErr := x.stepInputs(step)
+	stepRun.Start(inputs, x.engine.now())
+	if err := x.engine.runs.UpdateStepRun(persistCtx, stepRun); err != nil {
+		return "", "", err
+	}
+
+	var result stepResult
+	stepErr := inputErr
+	if stepErr == nil {
+		result, stepErr = x.runStep(ctx, step, stepRun, inputs)
+	}
+
+	status := domain.RunStatusSucceeded
+	message := ""
+	switch {
+	case stepErr == nil:
+	case ctx.Err() != nil:
+		status = domain.RunStatusCanceled
+		message = context.Cause(ctx).Error()
+	default:
+		status = domain.RunStatusFailed
+		message = stepErr.Error()
+	}
+
+	stepRun.Output = result.output
+	stepRun.Usage = result.usage
+	stepRun.Cost = result.cost
+	if result.childRun != nil {
+		stepRun.ChildRunID = &result.childRun.ID
+	}
+	if result.targets != nil {
+		x.selected[step.ID] = result.targets
+	}
+	x.run.Usage = x.run.Usage.Add(result.usage)
+	x.run.Cost += result.cost
+
+	stepRun.Finish(status, message, x.engine.now())
+	if err := x.engine.runs.UpdateStepRun(persistCtx, stepRun); err != nil {
+		return "", "", err
+	}
+	return status, message, nil
+}
+
+func (x *execution) runStep(
+	ctx context.Context, step *domain.Step, stepRun *domain.StepRun, inputs map[string]any,
+) (stepResult, error) {
+	switch step.Type {
+	case domain.StepTypePrompt:
+		return x.runPrompt(ctx, step, stepRun, inputs)
+	case domain.StepTypeTransform:
+		return runTransform(step, inputs)
+	case domain.StepTypeBranch:
+		return runBranch(step, inputs)
+	case domain.StepTypeFlow:
+		return x.runSubFlow(ctx, step, stepRun, inputs)
+	default:
+		return stepResult{}, fmt.Errorf("unsupported step type %q", step.Type)
+	}
+}
+
+// order returns step indexes of definition in dependency order, preferring
+// declaration order among steps that are ready at the same time.
+func order(definition *domain.FlowDefinition) []int {
+	done := make(map[string]bool, len(definition.Steps))
+	order := make([]int, 0, len(definition.Steps))
+
+	for len(order) < len(definition.Steps) {
+		for i, step := range definition.Steps {
+			if done[step.ID] {
+				continue
+			}
+			ready := true
+			for _, dep := range definition.Dependencies(i) {
+				ready = ready && done[dep]
+			}
+			if ready {
+				done[step.ID] = true
+				order = append(order, i)
+				break
+			}
+		}
+	}
+	return order
+}
+
+// skipped decides whether step i must not run: a branch it depends on chose
+// other targets, or every one of its dependencies was skipped.
+func (x *execution) skipped(i int) bool {
+	dependencies := x.flow.Definition.Dependencies(i)
+	if len(dependencies) == 0 {
+		return false
+	}
+
+	stepID := x.flow.Definition.Steps[i].ID
+	allSkipped := true
+	for _, dep := range dependencies {
+		depRun := x.stepRun(dep)
+		if depRun.Status != domain.RunStatusSkipped {
+			allSkipped = false
+		}
+
+		branch := x.flow.Definition.StepByID(dep).Branch
+		if branch != nil && depRun.Status == domain.RunStatusSucceeded &&
+			isBranchTarget(branch, stepID) && !slices.Contains(x.selected[dep], stepID) {
+			return true
+		}
+	}
+	return allSkipped
+}
+
+func (x *execution) skipRemaining(ctx context.Context) error {
+	for _, stepRun := range x.steps {
+		if stepRun.Status != domain.RunStatusPending {
+			continue
+		}
+		stepRun.Finish(domain.RunStatusSkipped, "", x.engine.now())
+		if err := x.engine.runs.UpdateStepRun(context.WithoutCancel(ctx), stepRun); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *execution) stepInputs(step *domain.Step) (map[string]any, error) {
+	inputs := make(map[string]any, len(step.Inputs))
+	var errs []error
+	for name, ref := range step.Inputs {
+		value, err := x.resolve(ref)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("input %q: %w", name, err))
+			continue
+		}
+		inputs[name] = value
+	}
+	return inputs, errors.Join(errs...)
+}
+
+func (x *execution) outputs() (map[string]any, error) {
+	outputs := make(map[string]any, len(x.flow.Definition.Outputs))
+	for _, output := range x.flow.Definition.Outputs {
+		value, err := x.resolve(output.From)
+		if err != nil {
+			return nil, fmt.Errorf("output %q: %w", output.Name, err)
+		}
+		// A value produced by a skipped branch stays null.
+		if value != nil && !output.Type.Accepts(value) {
+			return nil, fmt.Errorf("output %q: expected %s, got %T", output.Name, output.Type, value)
+		}
+		outputs[output.Name] = value
+	}
+	return outputs, nil
+}
+
+// resolve reads a reference. Steps that were skipped resolve to nil.
+func (x *execution) resolve(ref string) (any, error) {
+	parsed, err := domain.ParseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	switch parsed.Source {
+	case domain.ReferenceSourceInputs:
+		value = x.run.Inputs[parsed.Name]
+	case domain.ReferenceSourceSteps:
+		value = x.stepRun(parsed.Name).Output
+	}
+	if value == nil {
+		return nil, nil
+	}
+	return domain.LookupPath(value, parsed.Path)
+}
+
+func (x *execution) stepRun(stepID string) *domain.StepRun {
+	for _, stepRun := range x.steps {
+		if stepRun.StepID == stepID {
+			return stepRun
+		}
+	}
+	return nil
+}
+
+func isBranchTarget(branch *domain.BranchStep, stepID string) bool {
+	if slices.Contains(branch.Default, stepID) {
+		return true
+	}
+	for _, branchCase := range branch.Cases {
+		if slices.Contains(branchCase.Then, stepID) {
+			return true
+		}
+	}
+	return false
+}
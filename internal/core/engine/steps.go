@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"flow-run/internal/core/domain"
+	"fmt"
+	"strings"
+)
+
+func (x *execution) runPrompt(
+	ctx context.Context, step *domain.Step, stepRun *domain.StepRun, inputs map[string]any,
+) (stepResult, error) {
+	prompt := step.Prompt
+
+	// Template names carry the revision, so render errors of stored prompts
+	// point at the exact revision, e.g. "summary@3.user:2:7".
+	template, name := prompt.Template(), step.ID
+	if prompt.Prompt != "" {
+		ref := prompt.Ref()
+		revision, err := x.engine.prompts.ResolvePrompt(ctx, x.run.AccountID, ref)
+		if err != nil {
+			return stepResult{}, fmt.Errorf("prompt %q: %w", ref, err)
+		}
+		stepRun.PromptRevisionID = &revision.ID
+		template, name = &revision.Template, fmt.Sprintf("%s@%d", ref.Name, revision.Number)
+	}
+	messages, err := template.Render(name, inputs)
+	if err != nil {
+		return stepResult{}, err
+	}
+
+	model := prompt.Model
+	if x.options.model != "" {
+		model = x.options.model
+	}
+	link := callLink{
+		flowID:    &x.run.FlowID,
+		usageOpts: append([]domain.UsageRecordOpt{domain.WithUsageRecordStep(x.run, stepRun)}, x.options.usageOpts...),
+	}
+	resp, record, err := x.engine.complete(ctx, x.run.AccountID, model, messages, prompt.Parameters, link)
+	if err != nil {
+		return stepResult{}, err
+	}
+	return stepResult{output: resp.Message.Content, usage: resp.Usage, cost: record.Cost}, nil
+}
+
+func runTransform(step *domain.Step, inputs map[string]any) (stepResult, error) {
+	text, err := domain.RenderTemplate(step.ID+".template", step.Transform.Template, inputs)
+	if err != nil {
+		return stepResult{}, err
+	}
+	if !step.Transform.ParseJSON {
+		return stepResult{output: text}, nil
+	}
+
+	var output any
+	if err := json.Unmarshal([]byte(text), &output); err != nil {
+		return stepResult{}, fmt.Errorf("parse rendered template as JSON: %w", err)
+	}
+	return stepResult{output: output}, nil
+}
+
+// runBranch outputs the name of the selected case, or "default".
+func runBranch(step *domain.Step, inputs map[string]any) (stepResult, error) {
+	for i, branchCase := range step.Branch.Cases {
+		when, err := domain.RenderTemplate(fmt.Sprintf("%s.cases[%d].when", step.ID, i), branchCase.When, inputs)
+		if err != nil {
+			return stepResult{}, err
+		}
+		if strings.TrimSpace(when) == "true" {
+			return stepResult{output: branchCase.Name, targets: branchCase.Then}, nil
+		}
+	}
+	return stepResult{output: "default", targets: append([]string{}, step.Branch.Default...)}, nil
+}
+
+func (x *execution) runSubFlow(
+	ctx context.Context, step *domain.Step, stepRun *domain.StepRun, inputs map[string]any,
+) (stepResult, error) {
+	flow, err := x.engine.flows.GetByName(ctx, x.run.AccountID, step.Flow.Name)
+	if err != nil {
+		return stepResult{}, fmt.Errorf("flow %q: %w", step.Flow.Name, err)
+	}
+
+	child, err := x.engine.run(ctx, flow, inputs, &stepRun.ID, x.depth+1, x.options)
+	if err != nil {
+		return stepResult{}, fmt.Errorf("flow %q: %w", step.Flow.Name, err)
+	}
+
+	result := stepResult{childRun: child, usage: child.Usage, cost: child.Cost}
+	if child.Status != domain.RunStatusSucceeded {
+		return result, fmt.Errorf("flow %q %s: %s", step.Flow.Name, child.Status, child.Error)
+	}
+	result.output = child.Outputs
+	return result, nil
+}
@@ -0,0 +1,44 @@
+package evaluation
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+)
+
+// Compare queues a run of suite against baseline and one against candidate,
+// and returns both while they are pending; once both succeeded,
+// domain.NewRegressionReport compares them. Both targets are resolved before
+// either run is queued. A target that cannot be resolved is reported as
+// *TargetError with its field prefixed by "baseline." or "candidate.".
+func (r *Runner) Compare(
+	ctx context.Context, suite *domain.TestSuite, baseline, candidate Target,
+) (*domain.TestSuiteRun, *domain.TestSuiteRun, error) {
+	sides := []struct {
+		name   string
+		target Target
+	}{{"baseline", baseline}, {"candidate", candidate}}
+
+	runs := make([]*domain.TestSuiteRun, 0, len(sides))
+	for _, side := range sides {
+		model, subject, err := r.resolveTarget(ctx, suite, side.target)
+		if err != nil {
+			return nil, nil, sideError(side.name, err)
+		}
+		runs = append(runs, newRun(suite, model, subject, r.now()))
+	}
+	for _, run := range runs {
+		if err := r.enqueue(ctx, suite, run); err != nil {
+			return nil, nil, err
+		}
+	}
+	return runs[0], runs[1], nil
+}
+
+func sideError(side string, err error) error {
+	var targetErr *TargetError
+	if errors.As(err, &targetErr) {
+		return &TargetError{Field: side + "." + targetErr.Field, Value: targetErr.Value, Err: targetErr.Err}
+	}
+	return err
+}
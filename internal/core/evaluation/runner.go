@@ -0,0 +1,592 @@
+// Package evaluation runs test suites against models and records how every
+// case fared.
+package evaluation
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/engine"
+	"flow-run/internal/core/port"
+	"flow-run/internal/lib/logger"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// datasetPageSize is how many dataset rows the runner loads at a time.
+const datasetPageSize = 100
+
+// interruptedMessage is recorded on suite runs whose lease expired, i.e.
+// left behind by a process that stopped, and on queued runs whose worker
+// stopped.
+const interruptedMessage = "interrupted: the process running this suite stopped before it finished"
+
+// runLease is how long a suite run stays leased to the runner executing it.
+// The runner extends the lease every third of it while the run executes.
+const runLease = time.Minute
+
+// Executor is the part of the engine the runner drives: flow suites run
+// their flow, prompt suites send single completions.
+type Executor interface {
+	Run(ctx context.Context, flow *domain.Flow, inputs map[string]any, opts ...engine.RunOpt) (*domain.FlowRun, error)
+	Complete(
+		ctx context.Context,
+		accountID uuid.UUID,
+		model string,
+		messages []domain.Message,
+		params domain.CompletionParameters,
+		usageOpts []domain.UsageRecordOpt,
+		opts ...domain.CompletionRequestOpt,
+	) (*domain.CompletionResponse, error)
+}
+
+// Target selects what a suite runs against. Model replaces the model of
+// every call. Prompt suites run Revision, the revision Label points at, or
+// the latest revision when both are empty. Dataset suites run
+// DatasetVersion, else the version the suite pins, else the latest.
+type Target struct {
+	Model          string
+	Revision       int
+	Label          string
+	DatasetVersion int
+}
+
+// TargetError reports a part of a suite's target that cannot be resolved.
+// Field names it as in the run request: model, revision, label, prompt,
+// flow, dataset or dataset_version.
+type TargetError struct {
+	Field string
+	Value string
+	Err   error
+}
+
+func (e *TargetError) Error() string {
+	return fmt.Sprintf("%s %q: %s", e.Field, e.Value, e.Err)
+}
+
+func (e *TargetError) Unwrap() error {
+	return e.Err
+}
+
+// Runner executes test suites one case at a time and persists the run and
+// every case result as it goes.
+type Runner struct {
+	executor Executor
+	flows    port.FlowRepository
+	models   port.ModelRepository
+	prompts  port.PromptRepository
+	datasets port.DatasetRepository
+	runs     port.TestSuiteRunRepository
+	jobs     port.JobQueue
+	now      func() time.Time
+	lease    time.Duration
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewRunner(
+	executor Executor,
+	flows port.FlowRepository,
+	models port.ModelRepository,
+	prompts port.PromptRepository,
+	datasets port.DatasetRepository,
+	runs port.TestSuiteRunRepository,
+	jobs port.JobQueue,
+) *Runner {
+	return &Runner{
+		executor: executor,
+		flows:    flows,
+		models:   models,
+		prompts:  prompts,
+		datasets: datasets,
+		runs:     runs,
+		jobs:     jobs,
+		now:      time.Now,
+		lease:    runLease,
+	}
+}
+
+// Start fails the suite runs whose lease expired, then keeps doing so every
+// lease period until Stop. Runners extend the leases of the runs they
+// execute, so the runs of the runners still running, in any process sharing
+// the database, are left alone.
+func (r *Runner) Start(ctx context.Context) error {
+	if err := r.failExpiredRuns(ctx); err != nil {
+		return err
+	}
+
+	sweepCtx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.lease)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-sweepCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := r.failExpiredRuns(sweepCtx); err != nil && sweepCtx.Err() == nil {
+				logger.Log.WithError(err).Warn("Failed to fail test suite runs whose lease expired")
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops failing expired runs. Runs in progress are left to the jobs
+// executing them.
+func (r *Runner) Stop(ctx context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+	r.cancel()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Runner) failExpiredRuns(ctx context.Context) error {
+	failed, err := r.runs.FailExpiredSuiteRuns(ctx, r.now(), interruptedMessage)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		logger.Log.WithField("runs", failed).Warn("Marked interrupted test suite runs as failed")
+	}
+	return nil
+}
+
+// suiteSubject is what a suite's cases run: a prompt revision or a flow,
+// and the dataset version the cases come from, if any.
+type suiteSubject struct {
+	revision *domain.PromptRevision
+	flow     *domain.Flow
+	dataset  *domain.DatasetVersion
+}
+
+// Run executes every case of suite against target and returns the finished
+// run with its case results. Failing cases do not fail the run, except one
+// rejected by a budget, which fails it without running the rest; the
+// returned error is reserved for targets that cannot be resolved, reported
+// as *TargetError, and persistence failures.
+func (r *Runner) Run(
+	ctx context.Context, suite *domain.TestSuite, target Target,
+) (*domain.TestSuiteRun, []*domain.TestCaseResult, error) {
+	model, subject, err := r.resolveTarget(ctx, suite, target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	run := newRun(suite, model, subject, r.now())
+	run.Start(r.now())
+	lease := r.now().Add(r.lease)
+	run.LeaseExpiresAt = &lease
+	if err := r.runs.CreateSuiteRun(context.WithoutCancel(ctx), run); err != nil {
+		return nil, nil, err
+	}
+	results, err := r.execute(ctx, suite, model, subject, run)
+	if err != nil {
+		return nil, nil, err
+	}
+	return run, results, nil
+}
+
+// Submit records a pending run of suite against target and queues a job to
+// execute it, returning the run right away. Target is resolved as Run
+// resolves it; the run pins the prompt revision and dataset version it
+// resolved to.
+func (r *Runner) Submit(ctx context.Context, suite *domain.TestSuite, target Target) (*domain.TestSuiteRun, error) {
+	model, subject, err := r.resolveTarget(ctx, suite, target)
+	if err != nil {
+		return nil, err
+	}
+
+	run := newRun(suite, model, subject, r.now())
+	if err := r.enqueue(ctx, suite, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// enqueue records the pending run of suite and queues the job executing it.
+func (r *Runner) enqueue(ctx context.Context, suite *domain.TestSuite, run *domain.TestSuiteRun) error {
+	job, err := domain.NewJob(domain.JobKindTestSuiteRun, domain.NewTestSuiteRunJob(run, suite), r.now(),
+		domain.WithJobSubjectID(run.ID))
+	if err != nil {
+		return err
+	}
+
+	if err := r.runs.CreateSuiteRun(ctx, run); err != nil {
+		return err
+	}
+	if err := r.jobs.Enqueue(ctx, job); err != nil {
+		// Without its job the run would never start.
+		run.Finish(domain.RunStatusFailed, "not queued: "+err.Error(), r.now())
+		_ = r.runs.UpdateSuiteRun(context.WithoutCancel(ctx), run)
+		return err
+	}
+	return nil
+}
+
+// Execute is the handler of JobKindTestSuiteRun jobs. It executes the
+// submitted run against the model, prompt revision and dataset version it
+// pinned, unless it has finished already. A run that an earlier attempt
+// started is failed instead of executed again, as its cases may have called
+// models already; so is a run whose target is gone, e.g. a deleted model. An
+// error is only returned when the job should be retried.
+func (r *Runner) Execute(ctx context.Context, job *domain.Job) error {
+	var payload domain.TestSuiteRunJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	suite := payload.Suite
+	run, err := r.runs.GetSuiteRun(ctx, suite.AccountID, suite.ID, payload.RunID)
+	if err != nil {
+		return err
+	}
+	if run.Status.Finished() {
+		return nil
+	}
+	if run.Status == domain.RunStatusRunning {
+		run.Finish(domain.RunStatusFailed, interruptedMessage, r.now())
+		return r.runs.UpdateSuiteRun(ctx, run)
+	}
+
+	target := Target{Model: run.Model, Revision: run.PromptRevision, DatasetVersion: run.DatasetVersion}
+	model, subject, err := r.resolveTarget(ctx, suite, target)
+	if errors.Is(err, port.ErrNotFound) {
+		run.Finish(domain.RunStatusFailed, err.Error(), r.now())
+		return r.runs.UpdateSuiteRun(ctx, run)
+	} else if err != nil {
+		return err
+	}
+
+	if err := r.runs.ExtendSuiteRunLease(ctx, run.ID, r.now().Add(r.lease)); err != nil {
+		return err
+	}
+	run.Start(r.now())
+	if err := r.runs.UpdateSuiteRun(context.WithoutCancel(ctx), run); err != nil {
+		return err
+	}
+	_, err = r.execute(ctx, suite, model, subject, run)
+	return err
+}
+
+// newRun creates a pending run of suite against model and subject.
+func newRun(suite *domain.TestSuite, model *domain.Model, subject suiteSubject, now time.Time) *domain.TestSuiteRun {
+	run := domain.NewTestSuiteRun(suite, model.Name, now)
+	if subject.revision != nil {
+		run.PromptRevisionID = &subject.revision.ID
+		run.PromptRevision = subject.revision.Number
+	}
+	if subject.dataset != nil {
+		run.DatasetVersionID = &subject.dataset.ID
+		run.DatasetVersion = subject.dataset.Number
+	}
+	return run
+}
+
+// execute runs every case of the started run, extending its lease
+// meanwhile, and records how it ended.
+func (r *Runner) execute(
+	ctx context.Context, suite *domain.TestSuite, model *domain.Model, subject suiteSubject, run *domain.TestSuiteRun,
+) ([]*domain.TestCaseResult, error) {
+	stopHeartbeat := r.heartbeat(ctx, run)
+	defer stopHeartbeat()
+
+	// State is recorded even when ctx is canceled, so a canceled run still
+	// ends up with its final status.
+	persistCtx := context.WithoutCancel(ctx)
+
+	// A case rejected by a budget stops the run: the cases after it would be
+	// rejected too.
+	cases, stop := context.WithCancelCause(ctx)
+	defer stop(nil)
+
+	var results []*domain.TestCaseResult
+	err := r.eachCase(cases, suite, subject, func(position int, testCase *domain.TestCase) error {
+		out, flowRunID, caseErr := r.runCase(cases, suite, model, subject, run, position, testCase)
+		if errors.Is(caseErr, domain.ErrBudgetExceeded) {
+			stop(caseErr)
+		}
+		if caseErr == nil {
+			links := usageLinks(run, position, domain.UsagePurposeJudge)
+			out.Judgements = r.judge(ctx, suite.AccountID, links, testCase, out.Output)
+		}
+		result := domain.NewTestCaseResult(run.ID, position, testCase, out, caseErr)
+		result.FlowRunID = flowRunID
+		if err := r.runs.CreateCaseResult(persistCtx, result); err != nil {
+			return err
+		}
+		run.Record(result)
+		results = append(results, result)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		run.Finish(domain.RunStatusCanceled, context.Cause(ctx).Error(), r.now())
+	case cases.Err() != nil:
+		run.Finish(domain.RunStatusFailed, context.Cause(cases).Error(), r.now())
+	default:
+		run.Finish(domain.RunStatusSucceeded, "", r.now())
+	}
+	if err := r.runs.UpdateSuiteRun(persistCtx, run); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// heartbeat extends the lease of run every third of it until the returned
+// function is called.
+func (r *Runner) heartbeat(ctx context.Context, run *domain.TestSuiteRun) func() {
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(r.lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := r.runs.ExtendSuiteRunLease(ctx, run.ID, r.now().Add(r.lease)); err != nil {
+				logger.Log.WithError(err).WithField("run", run.ID).Warn("Failed to extend the lease of a test suite run")
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-stopped
+	}
+}
+
+// eachCase calls fn with every case of suite until ctx is done. Dataset
+// rows are loaded a page at a time, so large datasets are never held in
+// memory whole.
+func (r *Runner) eachCase(
+	ctx context.Context, suite *domain.TestSuite, subject suiteSubject, fn func(int, *domain.TestCase) error,
+) error {
+	if subject.dataset == nil {
+		for i := range suite.Cases {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err := fn(i, &suite.Cases[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for offset := 0; ; offset += datasetPageSize {
+		rows, err := r.datasets.ListRows(ctx, subject.dataset.ID, offset, datasetPageSize)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if ctx.Err() != nil {
+				return nil
+			}
+			testCase := suite.DatasetCase(row)
+			if err := fn(row.Position, &testCase); err != nil {
+				return err
+			}
+		}
+		if len(rows) < datasetPageSize {
+			return nil
+		}
+	}
+}
+
+// resolveTarget resolves the model of target and what suite runs.
+func (r *Runner) resolveTarget(
+	ctx context.Context, suite *domain.TestSuite, target Target,
+) (*domain.Model, suiteSubject, error) {
+	model, err := r.models.GetByName(ctx, suite.AccountID, target.Model)
+	if err != nil {
+		return nil, suiteSubject{}, &TargetError{Field: "model", Value: target.Model, Err: err}
+	}
+	subject, err := r.resolve(ctx, suite, target)
+	if err != nil {
+		return nil, suiteSubject{}, err
+	}
+	return model, subject, nil
+}
+
+func (r *Runner) resolve(ctx context.Context, suite *domain.TestSuite, target Target) (suiteSubject, error) {
+	var subject suiteSubject
+	if suite.Dataset != "" {
+		field, version := "dataset", suite.DatasetVersion
+		if target.DatasetVersion > 0 {
+			field, version = "dataset_version", target.DatasetVersion
+		}
+		dataset, err := r.datasets.ResolveDataset(ctx, suite.AccountID, suite.Dataset, version)
+		if err != nil {
+			value := suite.Dataset
+			if version > 0 {
+				value = fmt.Sprintf("%s@%d", suite.Dataset, version)
+			}
+			return subject, &TargetError{Field: field, Value: value, Err: err}
+		}
+		subject.dataset = dataset
+	}
+
+	if suite.Flow != "" {
+		flow, err := r.flows.GetByName(ctx, suite.AccountID, suite.Flow)
+		if err != nil {
+			return subject, &TargetError{Field: "flow", Value: suite.Flow, Err: err}
+		}
+		subject.flow = flow
+		return subject, nil
+	}
+
+	ref := domain.PromptRef{Name: suite.Prompt, Revision: target.Revision, Label: target.Label}
+	revision, err := r.prompts.ResolvePrompt(ctx, suite.AccountID, ref)
+	if err != nil {
+		field := "prompt"
+		switch {
+		case target.Revision > 0:
+			field = "revision"
+		case target.Label != "":
+			field = "label"
+		}
+		return subject, &TargetError{Field: field, Value: ref.String(), Err: err}
+	}
+	subject.revision = revision
+	return subject, nil
+}
+
+// runCase produces the output of the case at position of run. Usage and cost
+// are reported even for a failed flow run, since its steps may have called
+// the model.
+func (r *Runner) runCase(
+	ctx context.Context,
+	suite *domain.TestSuite,
+	model *domain.Model,
+	subject suiteSubject,
+	run *domain.TestSuiteRun,
+	position int,
+	testCase *domain.TestCase,
+) (*domain.CaseOutput, *uuid.UUID, error) {
+	out := &domain.CaseOutput{}
+	started := r.now()
+	defer func() {
+		out.Latency = r.now().Sub(started)
+		out.Cost = model.Cost(out.Usage)
+	}()
+
+	if subject.flow != nil {
+		flowRun, err := r.executor.Run(ctx, subject.flow, testCase.Inputs,
+			engine.WithModel(model.Name), engine.WithSuiteCase(run.ID, position))
+		if err != nil {
+			return out, nil, err
+		}
+		out.Usage = flowRun.Usage
+		if flowRun.Status != domain.RunStatusSucceeded {
+			return out, &flowRun.ID, fmt.Errorf("flow run %s: %s", flowRun.Status, flowRun.Error)
+		}
+		out.Output = flowOutput(subject.flow, flowRun.Outputs)
+		return out, &flowRun.ID, nil
+	}
+
+	name := fmt.Sprintf("%s@%d", suite.Prompt, subject.revision.Number)
+	messages, err := subject.revision.Template.Render(name, testCase.Inputs)
+	if err != nil {
+		return out, nil, err
+	}
+	links := usageLinks(run, position, domain.UsagePurposeTestCase)
+	resp, err := r.executor.Complete(ctx, suite.AccountID, model.Name, messages, domain.CompletionParameters{}, links)
+	if err != nil {
+		return out, nil, err
+	}
+	out.Output = resp.Message.Content
+	out.Usage = resp.Usage
+	return out, nil, nil
+}
+
+// judge asks the judge models of testCase's judge assertions to grade
+// output. Judges are billed at their own model's prices, and their usage
+// recorded with links.
+func (r *Runner) judge(
+	ctx context.Context, accountID uuid.UUID, links []domain.UsageRecordOpt, testCase *domain.TestCase, output any,
+) map[int]*domain.Judgement {
+	judgements := map[int]*domain.Judgement{}
+	for i := range testCase.Assertions {
+		if a := &testCase.Assertions[i]; a.Type == domain.AssertionTypeJudge {
+			judgements[i] = r.grade(ctx, accountID, a, testCase, output, links)
+		}
+	}
+	return judgements
+}
+
+func (r *Runner) grade(
+	ctx context.Context,
+	accountID uuid.UUID,
+	a *domain.Assertion,
+	testCase *domain.TestCase,
+	output any,
+	links []domain.UsageRecordOpt,
+) *domain.Judgement {
+	model, err := r.models.GetByName(ctx, accountID, a.Model)
+	if err != nil {
+		return &domain.Judgement{Err: fmt.Errorf("model %q: %w", a.Model, err)}
+	}
+	resp, err := r.executor.Complete(
+		ctx,
+		accountID,
+		model.Name,
+		domain.JudgeMessages(a, testCase, output),
+		domain.CompletionParameters{},
+		links,
+		domain.WithCompletionTools(domain.JudgeTool()),
+	)
+	if err != nil {
+		return &domain.Judgement{Err: err}
+	}
+
+	judgement := &domain.Judgement{Usage: resp.Usage, Cost: model.Cost(resp.Usage)}
+	judgement.Verdict, judgement.Err = domain.ParseJudgeVerdict(resp)
+	return judgement
+}
+
+// usageLinks links the usage of a call made for the case at position of run,
+// for purpose. The calls of prompt suites, judges included, also point at the
+// revision under test.
+func usageLinks(run *domain.TestSuiteRun, position int, purpose domain.UsagePurpose) []domain.UsageRecordOpt {
+	links := []domain.UsageRecordOpt{domain.WithUsageRecordSuiteCase(run.ID, position, purpose)}
+	if run.PromptRevisionID != nil {
+		links = append(links, domain.WithUsageRecordPromptRevision(*run.PromptRevisionID))
+	}
+	return links
+}
+
+// flowOutput is what assertions see of a flow run: the value of its only
+// output, or the object of all outputs.
+func flowOutput(flow *domain.Flow, outputs map[string]any) any {
+	if declared := flow.Definition.Outputs; len(declared) == 1 {
+		return outputs[declared[0].Name]
+	}
+	return outputs
+}
@@ -0,0 +1,656 @@
+package evaluation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/engine"
+	"flow-run/internal/core/port"
+	"flow-run/internal/flowrun/infra/memory"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPromptSuite(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	env.executor.complete = func(messages []domain.Message) (*domain.CompletionResponse, error) {
+		if messages[0].Content == "Hello Bob" {
+			return nil, errors.New("provider unavailable")
+		}
+		return &domain.CompletionResponse{
+			Message: domain.Message{Role: domain.MessageRoleAssistant, Content: messages[0].Content},
+			Usage:   domain.TokenUsage{PromptTokens: 1000, CompletionTokens: 1000, TotalTokens: 2000},
+		}, nil
+	}
+	suite := env.suite(t, domain.WithTestSuitePrompt("greet"), domain.WithTestSuiteCases(
+		domain.TestCase{Name: "ada", Inputs: map[string]any{"name": "Ada"}, Assertions: []domain.Assertion{
+			{Type: domain.AssertionTypeEquals, Value: "Hello Ada"},
+			{Type: domain.AssertionTypeMaxCost, Value: "0.01"},
+		}},
+		domain.TestCase{Name: "too expensive", Inputs: map[string]any{"name": "Eve"}, Assertions: []domain.Assertion{
+			{Type: domain.AssertionTypeMaxCost, Value: "0.001"},
+		}},
+		domain.TestCase{Name: "bob", Inputs: map[string]any{"name": "Bob"}, Assertions: []domain.Assertion{
+			{Type: domain.AssertionTypeContains, Value: "Bob"},
+		}},
+	))
+
+	run, results, err := env.runner.Run(context.Background(), suite, Target{Model: "gpt"})
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.RunStatusSucceeded, run.Status)
+	assert.Equal(t, "gpt", run.Model)
+	assert.Equal(t, 2, run.PromptRevision)
+	assert.Equal(t, 1, run.Passed)
+	assert.Equal(t, 2, run.Failed)
+	// Each successful case costs $1 per million for 1000 tokens in and $2 per
+	// million for 1000 tokens out.
+	assert.Equal(t, domain.Money(6_000_000), run.Cost)
+	assert.Equal(t, 4000, run.Usage.TotalTokens)
+
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Passed)
+	assert.False(t, results[1].Passed)
+	assert.Equal(t, domain.Money(3_000_000), results[1].Cost)
+	assert.False(t, results[2].Passed)
+	assert.Equal(t, "provider unavailable", results[2].Error)
+	assert.Equal(t, results, env.runs.results)
+	assert.Same(t, run, env.runs.updated)
+}
+
+func TestRunPromptSuiteAtLabel(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	suite := env.suite(t, domain.WithTestSuitePrompt("greet"), domain.WithTestSuiteCases(
+		domain.TestCase{Name: "ada", Inputs: map[string]any{"name": "Ada"}, Assertions: []domain.Assertion{
+			{Type: domain.AssertionTypeEquals, Value: "Hi Ada"},
+		}},
+	))
+
+	run, results, err := env.runner.Run(context.Background(), suite, Target{Model: "gpt", Label: "production"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, run.PromptRevision)
+	assert.True(t, results[0].Passed, results[0].Assertions)
+}
+
+func TestRunFlowSuite(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	env.executor.run = func(flow *domain.Flow, inputs map[string]any, opts []engine.RunOpt) *domain.FlowRun {
+		assert.Len(t, opts, 2)
+		run, _ := domain.NewFlowRun(flow, inputs, time.Now())
+		run.Status = domain.RunStatusSucceeded
+		run.Outputs = map[string]any{"answer": `{"city": "Paris"}`}
+		run.Usage = domain.TokenUsage{PromptTokens: 1000, TotalTokens: 1000}
+		return run
+	}
+	suite := env.suite(t, domain.WithTestSuiteFlow("capital"), domain.WithTestSuiteCases(
+		domain.TestCase{Name: "france", Inputs: map[string]any{"country": "France"}, Assertions: []domain.Assertion{
+			{Type: domain.AssertionTypeJSONPath, Path: "$.city", Expected: "Paris"},
+		}},
+	))
+
+	run, results, err := env.runner.Run(context.Background(), suite, Target{Model: "gpt"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, run.Passed)
+	assert.Nil(t, run.PromptRevisionID)
+	assert.Equal(t, domain.Money(1_000_000), run.Cost)
+	require.NotNil(t, results[0].FlowRunID)
+	assert.Equal(t, `{"city": "Paris"}`, results[0].Output)
+}
+
+func TestRunWithJudge(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	env.executor.judge = func(model string, messages []domain.Message) (*domain.CompletionResponse, error) {
+		assert.Equal(t, "gpt", model)
+		score := `{"score": 0.4, "reasoning": "too curt"}`
+		if strings.Contains(messages[1].Content, "Hello Ada") {
+			score = `{"score": 0.9, "reasoning": "friendly"}`
+		}
+		return &domain.CompletionResponse{
+			Message: domain.Message{Role: domain.MessageRoleAssistant, ToolCalls: []domain.ToolCall{
+				{ID: "1", Name: domain.JudgeToolName, Arguments: json.RawMessage(score)},
+			}},
+			Usage: domain.TokenUsage{PromptTokens: 1000, TotalTokens: 1000},
+		}, nil
+	}
+	judge := domain.Assertion{Type: domain.AssertionTypeJudge, Model: "gpt", Rubric: "Is it friendly?", Threshold: 0.7}
+	suite := env.suite(t, domain.WithTestSuitePrompt("greet"), domain.WithTestSuiteCases(
+		domain.TestCase{Name: "ada", Inputs: map[string]any{"name": "Ada"}, Assertions: []domain.Assertion{judge}},
+		domain.TestCase{Name: "eve", Inputs: map[string]any{"name": "Eve"}, Assertions: []domain.Assertion{judge}},
+		domain.TestCase{Name: "bob", Inputs: map[string]any{"name": "Bob"}, Assertions: []domain.Assertion{
+			{Type: domain.AssertionTypeJudge, Model: "unknown", Rubric: "Is it friendly?"},
+		}},
+	))
+
+	run, results, err := env.runner.Run(context.Background(), suite, Target{Model: "gpt"})
+	require.NoError(t, err)
+
+	assert.True(t, results[0].Passed)
+	assert.Equal(t, 0.9, *results[0].Assertions[0].Score)
+	assert.Equal(t, "friendly", results[0].Assertions[0].Reasoning)
+	assert.False(t, results[1].Passed)
+	assert.Contains(t, results[1].Assertions[0].Message, "below the threshold")
+	assert.False(t, results[2].Passed)
+	assert.Contains(t, results[2].Assertions[0].Message, "unknown")
+
+	// The cases themselves used no tokens; only the judges did.
+	assert.Equal(t, domain.Money(0), run.Cost)
+	assert.Equal(t, 2000, run.JudgeUsage.TotalTokens)
+	assert.Equal(t, domain.Money(2_000_000), run.JudgeCost)
+	assert.Equal(t, domain.Money(1_000_000), results[0].JudgeCost)
+
+	// The case of Ada, its judge, the case of Eve, its judge, then Bob's.
+	purposes := []domain.UsagePurpose{
+		domain.UsagePurposeTestCase, domain.UsagePurposeJudge,
+		domain.UsagePurposeTestCase, domain.UsagePurposeJudge,
+		domain.UsagePurposeTestCase,
+	}
+	positions := []int{0, 0, 1, 1, 2}
+	require.NotNil(t, run.PromptRevisionID)
+	require.Len(t, env.executor.records, len(purposes))
+	for i, record := range env.executor.records {
+		assert.Equal(t, purposes[i], record.Purpose, i)
+		assert.Equal(t, &run.ID, record.SuiteRunID, i)
+		assert.Equal(t, positions[i], *record.CasePosition, i)
+		assert.Equal(t, run.PromptRevisionID, record.PromptRevisionID, i)
+	}
+}
+
+func TestRunDatasetSuite(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	suite := env.suite(t,
+		domain.WithTestSuitePrompt("greet"),
+		domain.WithTestSuiteDataset("names", 1),
+		domain.WithTestSuiteAssertions(domain.Assertion{Type: domain.AssertionTypeEquals}),
+	)
+
+	run, results, err := env.runner.Run(context.Background(), suite, Target{Model: "gpt"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, run.DatasetVersion)
+	assert.Equal(t, 1, run.Passed)
+	assert.Equal(t, 1, run.Failed)
+	require.Len(t, results, 2)
+	assert.Equal(t, "ada", results[0].Name)
+	assert.True(t, results[0].Passed)
+	assert.Equal(t, `expected "Hi Bob", got "Hello Bob"`, results[1].Assertions[0].Message)
+
+	// Without assertions the suite is a batch run over the latest version,
+	// which spans several pages of rows.
+	batch := env.suite(t, domain.WithTestSuitePrompt("greet"), domain.WithTestSuiteDataset("names", 0))
+	run, results, err = env.runner.Run(context.Background(), batch, Target{Model: "gpt"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, run.DatasetVersion)
+	assert.Equal(t, 150, run.Passed)
+	require.Len(t, results, 150)
+	assert.Equal(t, "row 150", results[149].Name)
+	assert.Equal(t, 149, results[149].Position)
+	assert.Equal(t, "Hello 149", results[149].Output)
+
+	_, _, err = env.runner.Run(context.Background(), batch, Target{Model: "gpt", DatasetVersion: 9})
+	var targetErr *TargetError
+	require.ErrorAs(t, err, &targetErr)
+	assert.Equal(t, "dataset_version", targetErr.Field)
+	assert.Equal(t, "names@9", targetErr.Value)
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	env := newTestEnv(t)
+	suite := env.suite(t,
+		domain.WithTestSuitePrompt("greet"),
+		domain.WithTestSuiteDataset("names", 1),
+		domain.WithTestSuiteAssertions(domain.Assertion{Type: domain.AssertionTypeContains}),
+	)
+
+	baseline, candidate, err := env.runner.Compare(ctx, suite,
+		Target{Model: "gpt", Revision: 1}, Target{Model: "gpt", Revision: 2})
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStatusPending, baseline.Status)
+	assert.Equal(t, 1, baseline.PromptRevision)
+	assert.Equal(t, 2, candidate.PromptRevision)
+
+	require.NoError(t, env.runner.Execute(ctx, env.claim(t)))
+	require.NoError(t, env.runner.Execute(ctx, env.claim(t)))
+	cases := map[uuid.UUID][]*domain.TestCaseResult{}
+	for _, result := range env.runs.results {
+		cases[result.RunID] = append(cases[result.RunID], result)
+	}
+
+	// Revision 1 says "Hi", so only Bob, expected "Hi Bob", passes; revision
+	// 2 says "Hello" and passes Ada instead.
+	report, err := domain.NewRegressionReport(
+		env.runs.stored(t, baseline.ID), cases[baseline.ID], env.runs.stored(t, candidate.ID), cases[candidate.ID])
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Delta.Passed)
+	assert.Equal(t, []domain.CaseChange{domain.CaseChangeFixed, domain.CaseChangeRegressed},
+		[]domain.CaseChange{report.Cases[0].Change, report.Cases[1].Change})
+	assert.Len(t, report.Flipped(), 2)
+
+	// Neither run is queued when a target is missing.
+	_, _, err = env.runner.Compare(ctx, suite, Target{Model: "gpt"}, Target{Model: "gpt", Label: "staging"})
+	var targetErr *TargetError
+	require.ErrorAs(t, err, &targetErr)
+	assert.Equal(t, "candidate.label", targetErr.Field)
+	assert.Len(t, env.runs.runs, 2)
+}
+
+func TestSubmit(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	env := newTestEnv(t)
+	suite := env.suite(t, domain.WithTestSuitePrompt("greet"), domain.WithTestSuiteCases(
+		domain.TestCase{Name: "ada", Inputs: map[string]any{"name": "Ada"}, Assertions: []domain.Assertion{
+			{Type: domain.AssertionTypeEquals, Value: "Hi Ada"},
+		}},
+	))
+
+	run, err := env.runner.Submit(ctx, suite, Target{Model: "gpt", Label: "production"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStatusPending, run.Status)
+	assert.Equal(t, 1, run.PromptRevision)
+	assert.Empty(t, env.runs.results)
+
+	// The job runs the revision the run pinned, wherever the label points.
+	job := env.claim(t)
+	require.NoError(t, env.runner.Execute(ctx, job))
+	stored := env.runs.stored(t, run.ID)
+	assert.Equal(t, domain.RunStatusSucceeded, stored.Status)
+	assert.Equal(t, 1, stored.Passed)
+
+	// A finished run is not executed again.
+	require.NoError(t, env.runner.Execute(ctx, job))
+	assert.Len(t, env.runs.results, 1)
+
+	_, err = env.runner.Submit(ctx, suite, Target{Model: "unknown"})
+	var targetErr *TargetError
+	require.ErrorAs(t, err, &targetErr)
+	assert.Equal(t, "model", targetErr.Field)
+}
+
+func TestExecuteIfStartedBefore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	env := newTestEnv(t)
+	suite := env.suite(t, domain.WithTestSuitePrompt("greet"), domain.WithTestSuiteCases(
+		domain.TestCase{Name: "ada", Assertions: []domain.Assertion{{Type: domain.AssertionTypeContains, Value: "x"}}},
+	))
+	run, err := env.runner.Submit(ctx, suite, Target{Model: "gpt"})
+	require.NoError(t, err)
+	env.runs.runs[run.ID].Start(time.Now())
+
+	require.NoError(t, env.runner.Execute(ctx, env.claim(t)))
+
+	stored := env.runs.stored(t, run.ID)
+	assert.Equal(t, domain.RunStatusFailed, stored.Status)
+	assert.Equal(t, interruptedMessage, stored.Error)
+	assert.Empty(t, env.runs.results)
+}
+
+func TestRunIfTargetMissing(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	suite := env.suite(t, domain.WithTestSuitePrompt("greet"), domain.WithTestSuiteCases(
+		domain.TestCase{Name: "ada", Assertions: []domain.Assertion{{Type: domain.AssertionTypeContains, Value: "x"}}},
+	))
+
+	tests := []struct {
+		target Target
+		field  string
+	}{
+		{target: Target{Model: "unknown"}, field: "model"},
+		{target: Target{Model: "gpt", Revision: 9}, field: "revision"},
+		{target: Target{Model: "gpt", Label: "staging"}, field: "label"},
+	}
+
+	for _, tt := range tests {
+		_, _, err := env.runner.Run(context.Background(), suite, tt.target)
+		var targetErr *TargetError
+		require.ErrorAs(t, err, &targetErr)
+		assert.Equal(t, tt.field, targetErr.Field)
+		assert.ErrorIs(t, err, port.ErrNotFound)
+	}
+	assert.Empty(t, env.runs.results)
+}
+
+func TestRunIfCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	env := newTestEnv(t)
+	env.executor.complete = func(messages []domain.Message) (*domain.CompletionResponse, error) {
+		cancel()
+		return nil, context.Canceled
+	}
+	cases := []domain.TestCase{
+		{Name: "a", Assertions: []domain.Assertion{{Type: domain.AssertionTypeContains, Value: "x"}}},
+		{Name: "b", Assertions: []domain.Assertion{{Type: domain.AssertionTypeContains, Value: "x"}}},
+	}
+	suite := env.suite(t, domain.WithTestSuitePrompt("greet"), domain.WithTestSuiteCases(cases...))
+
+	run, results, err := env.runner.Run(ctx, suite, Target{Model: "gpt"})
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.RunStatusCanceled, run.Status)
+	assert.Len(t, results, 1)
+	assert.Equal(t, domain.RunStatusCanceled, env.runs.updated.Status)
+}
+
+func TestRunIfBudgetExceeded(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	calls := 0
+	env.executor.complete = func(messages []domain.Message) (*domain.CompletionResponse, error) {
+		calls++
+		return nil, &domain.BudgetExceededError{Budget: &domain.Budget{Name: "daily", Period: domain.BudgetPeriodDaily}}
+	}
+	cases := []domain.TestCase{
+		{Name: "a", Assertions: []domain.Assertion{{Type: domain.AssertionTypeContains, Value: "x"}}},
+		{Name: "b", Assertions: []domain.Assertion{{Type: domain.AssertionTypeContains, Value: "x"}}},
+	}
+	suite := env.suite(t, domain.WithTestSuitePrompt("greet"), domain.WithTestSuiteCases(cases...))
+
+	run, results, err := env.runner.Run(context.Background(), suite, Target{Model: "gpt"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, domain.RunStatusFailed, run.Status)
+	assert.Contains(t, run.Error, `budget "daily"`)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Passed)
+}
+
+func TestStartFailsExpiredSuiteRuns(t *testing.T) {
+	t.Parallel()
+
+	env := newTestEnv(t)
+	require.NoError(t, env.runner.Start(context.Background()))
+	require.NoError(t, env.runner.Stop(context.Background()))
+	assert.Equal(t, interruptedMessage, env.runs.failMessage)
+}
+
+type testEnv struct {
+	accountID uuid.UUID
+	executor  *fakeExecutor
+	runs      *fakeRuns
+	jobs      *memory.JobQueue
+	runner    *Runner
+}
+
+// newTestEnv builds a runner with one model, "gpt", priced at $1 and $2 per
+// million input and output tokens, a flow named "capital" and a prompt named
+// "greet" whose revision 1 says "Hi" and is labeled production, and whose
+// revision 2 says "Hello". Dataset "names" has version 1 with rows for Ada,
+// expected to be greeted "Hello Ada", and Bob, expected "Hi Bob", and a
+// latest version 2 of 150 rows without expected outputs. The executor
+// echoes the first message.
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	accountID := uuid.New()
+	model := &domain.Model{
+		ID:          uuid.New(),
+		Name:        "gpt",
+		AccountID:   accountID,
+		InputPrice:  domain.Money(1_000_000_000),
+		OutputPrice: domain.Money(2_000_000_000),
+	}
+	flow, err := domain.NewFlow(
+		domain.WithFlowID(uuid.New()),
+		domain.WithFlowAccountID(accountID),
+		domain.WithFlowSource(`
+name: capital
+inputs:
+  - {name: country, type: string, required: true}
+outputs:
+  - {name: answer, type: string, from: steps.ask.output}
+steps:
+  - {id: ask, type: prompt, inputs: {country: inputs.country}, prompt: {model: gpt, user: "{{ .country }}"}}
+`, domain.FlowSourceFormatYAML),
+	)
+	require.NoError(t, err)
+	flows, models := memory.NewFlowRepository(), memory.NewModelRepository()
+	require.NoError(t, flows.Create(context.Background(), flow))
+	require.NoError(t, models.Create(context.Background(), model))
+
+	prompt, err := domain.NewPrompt(
+		domain.WithPromptID(uuid.New()),
+		domain.WithPromptAccountID(accountID),
+		domain.WithPromptName("greet"),
+		domain.WithPromptAuthor("alice"),
+		domain.WithPromptTemplate(domain.PromptTemplate{
+			User:      "Hi {{ .name }}",
+			Variables: []domain.FlowVariable{{Name: "name", Type: domain.ValueTypeString}},
+		}),
+	)
+	require.NoError(t, err)
+	first, err := domain.NewPromptRevision(prompt, 1, time.Now())
+	require.NoError(t, err)
+	prompt.Template.User = "Hello {{ .name }}"
+	second, err := domain.NewPromptRevision(prompt, 2, time.Now())
+	require.NoError(t, err)
+
+	env := &testEnv{
+		accountID: accountID,
+		executor: &fakeExecutor{complete: func(messages []domain.Message) (*domain.CompletionResponse, error) {
+			return &domain.CompletionResponse{Message: messages[0]}, nil
+		}},
+		runs: &fakeRuns{runs: map[uuid.UUID]*domain.TestSuiteRun{}},
+		jobs: memory.NewJobQueue(),
+	}
+	env.runner = NewRunner(
+		env.executor,
+		flows,
+		models,
+		fakePrompts{first, second},
+		newFakeDatasets(accountID),
+		env.runs,
+		env.jobs,
+	)
+	return env
+}
+
+// claim claims the next job of the queue.
+func (e *testEnv) claim(t *testing.T) *domain.Job {
+	t.Helper()
+
+	now := time.Now()
+	kinds := []domain.JobKind{domain.JobKindTestSuiteRun}
+	job, err := e.jobs.Claim(context.Background(), "test", kinds, now, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	return job
+}
+
+func (e *testEnv) suite(t *testing.T, opts ...domain.TestSuiteOpt) *domain.TestSuite {
+	t.Helper()
+
+	suite, err := domain.NewTestSuite(append([]domain.TestSuiteOpt{
+		domain.WithTestSuiteID(uuid.New()),
+		domain.WithTestSuiteAccountID(e.accountID),
+		domain.WithTestSuiteName("suite"),
+	}, opts...)...)
+	require.NoError(t, err)
+	return suite
+}
+
+type fakeExecutor struct {
+	run      func(flow *domain.Flow, inputs map[string]any, opts []engine.RunOpt) *domain.FlowRun
+	complete func(messages []domain.Message) (*domain.CompletionResponse, error)
+	// judge answers the calls offering the judge tool, when set.
+	judge func(model string, messages []domain.Message) (*domain.CompletionResponse, error)
+	// records are the usage records of the completions, as linked.
+	records []*domain.UsageRecord
+}
+
+func (f *fakeExecutor) Run(
+	_ context.Context, flow *domain.Flow, inputs map[string]any, opts ...engine.RunOpt,
+) (*domain.FlowRun, error) {
+	return f.run(flow, inputs, opts), nil
+}
+
+func (f *fakeExecutor) Complete(
+	_ context.Context,
+	_ uuid.UUID,
+	model string,
+	messages []domain.Message,
+	params domain.CompletionParameters,
+	usageOpts []domain.UsageRecordOpt,
+	opts ...domain.CompletionRequestOpt,
+) (*domain.CompletionResponse, error) {
+	req, err := domain.NewCompletionRequest(model, messages, params, opts...)
+	if err != nil {
+		return nil, err
+	}
+	f.records = append(f.records, domain.NewUsageRecord(&domain.Model{Name: model}, domain.TokenUsage{}, time.Now(),
+		usageOpts...))
+	if len(req.Tools) > 0 && f.judge != nil {
+		return f.judge(model, messages)
+	}
+	return f.complete(messages)
+}
+
+// fakePrompts holds the revisions of a single prompt, oldest first. Only the
+// production label exists; it points at revision 1.
+type fakePrompts []*domain.PromptRevision
+
+func (f fakePrompts) ResolvePrompt(
+	_ context.Context, _ uuid.UUID, ref domain.PromptRef,
+) (*domain.PromptRevision, error) {
+	switch {
+	case ref.Revision > 0 && ref.Revision <= len(f):
+		return f[ref.Revision-1], nil
+	case ref.Revision > 0:
+		return nil, &port.NotFoundError{Entity: "prompt revision", Key: strconv.Itoa(ref.Revision)}
+	case ref.Label == "production":
+		return f[0], nil
+	case ref.Label != "":
+		return nil, &port.NotFoundError{Entity: "prompt label", Key: ref.Label}
+	}
+	return f[len(f)-1], nil
+}
+
+// fakeDatasets holds the versions of a single dataset, "names", oldest
+// first.
+type fakeDatasets struct {
+	versions []*domain.DatasetVersion
+	rows     map[uuid.UUID][]*domain.DatasetRow
+}
+
+func newFakeDatasets(accountID uuid.UUID) *fakeDatasets {
+	dataset := &domain.Dataset{ID: uuid.New(), AccountID: accountID, Name: "names"}
+	f := &fakeDatasets{rows: map[uuid.UUID][]*domain.DatasetRow{}}
+
+	first, _ := domain.NewDatasetVersion(dataset, 1, domain.DatasetFormatJSONL, time.Now())
+	f.versions = append(f.versions, first)
+	f.rows[first.ID] = []*domain.DatasetRow{
+		{Position: 0, Name: "ada", Inputs: map[string]any{"name": "Ada"}, Expected: "Hello Ada"},
+		{Position: 1, Name: "bob", Inputs: map[string]any{"name": "Bob"}, Expected: "Hi Bob"},
+	}
+
+	second, _ := domain.NewDatasetVersion(dataset, 2, domain.DatasetFormatCSV, time.Now())
+	f.versions = append(f.versions, second)
+	for i := range 150 {
+		f.rows[second.ID] = append(f.rows[second.ID], &domain.DatasetRow{
+			Position: i, Inputs: map[string]any{"name": strconv.Itoa(i)},
+		})
+	}
+	return f
+}
+
+func (f *fakeDatasets) ResolveDataset(
+	_ context.Context, _ uuid.UUID, name string, version int,
+) (*domain.DatasetVersion, error) {
+	if version == 0 {
+		version = len(f.versions)
+	}
+	if name != "names" || version > len(f.versions) {
+		return nil, &port.NotFoundError{Entity: "dataset", Key: name}
+	}
+	return f.versions[version-1], nil
+}
+
+func (f *fakeDatasets) ListRows(
+	_ context.Context, versionID uuid.UUID, offset, limit int,
+) ([]*domain.DatasetRow, error) {
+	rows := f.rows[versionID]
+	if offset >= len(rows) {
+		return nil, nil
+	}
+	return rows[offset:min(offset+limit, len(rows))], nil
+}
+
+// fakeRuns keeps the suite runs it is given, and the last one updated.
+type fakeRuns struct {
+	runs        map[uuid.UUID]*domain.TestSuiteRun
+	updated     *domain.TestSuiteRun
+	results     []*domain.TestCaseResult
+	failMessage string
+}
+
+func (f *fakeRuns) CreateSuiteRun(_ context.Context, run *domain.TestSuiteRun) error {
+	f.runs[run.ID] = run
+	return nil
+}
+
+func (f *fakeRuns) UpdateSuiteRun(_ context.Context, run *domain.TestSuiteRun) error {
+	f.runs[run.ID] = run
+	f.updated = run
+	return nil
+}
+
+func (f *fakeRuns) GetSuiteRun(_ context.Context, _, _, id uuid.UUID) (*domain.TestSuiteRun, error) {
+	run, ok := f.runs[id]
+	if !ok {
+		return nil, &port.NotFoundError{Entity: "test suite run", Key: id.String()}
+	}
+	stored := *run
+	return &stored, nil
+}
+
+func (f *fakeRuns) CreateCaseResult(_ context.Context, result *domain.TestCaseResult) error {
+	f.results = append(f.results, result)
+	return nil
+}
+
+func (f *fakeRuns) ExtendSuiteRunLease(context.Context, uuid.UUID, time.Time) error {
+	return nil
+}
+
+func (f *fakeRuns) FailExpiredSuiteRuns(_ context.Context, _ time.Time, message string) (int, error) {
+	f.failMessage = message
+	return 0, nil
+}
+
+func (f *fakeRuns) stored(t *testing.T, id uuid.UUID) *domain.TestSuiteRun {
+	t.Helper()
+
+	run, err := f.GetSuiteRun(context.Background(), uuid.Nil, uuid.Nil, id)
+	require.NoError(t, err)
+	return run
+}
@@ -0,0 +1,63 @@
+package port
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Repositories fail with these, whatever stores the records. They are
+// scoped to an account, and a record of another account is not found.
+// Reads of a missing record, and updates or deletes of one, fail with a
+// *NotFoundError; writes that would duplicate a name within the account or
+// orphan a dependent record fail with a *ConflictError, and writes that
+// reference a missing record fail with a *ReferenceError.
+var (
+	ErrNotFound = errors.New("record not found")
+	ErrConflict = errors.New("record conflict")
+)
+
+// NotFoundError reports that no Entity is stored under Key, or that it
+// belongs to another account. It matches ErrNotFound.
+type NotFoundError struct {
+	Entity string
+	Key    string
+}
+
+func (e *NotFoundError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("%s: %s", ErrNotFound, e.Entity)
+	}
+	return fmt.Sprintf("%s: %s %s", ErrNotFound, e.Entity, e.Key)
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ConflictError reports that storing Entity Key would break a rule of the
+// store, such as a unique name or a record that still references it. It
+// matches ErrConflict.
+type ConflictError struct {
+	Entity string
+	Key    string
+	Reason string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s: %s %q %s", ErrConflict, e.Entity, e.Key, e.Reason)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// ReferenceError reports that a field points at a record that does not exist
+// or belongs to another account.
+type ReferenceError struct {
+	Field string
+	Value string
+}
+
+func (e *ReferenceError) Error() string {
+	return fmt.Sprintf("%s %s does not exist", e.Field, e.Value)
+}
@@ -0,0 +1,66 @@
+package port
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrLLMAuthentication  = errors.New("llm provider rejected credentials")
+	ErrLLMRateLimited     = errors.New("llm provider rate limit exceeded")
+	ErrLLMQuotaExceeded   = errors.New("llm provider quota or credits exhausted")
+	ErrLLMInvalidRequest  = errors.New("llm provider rejected request")
+	ErrLLMUnavailable     = errors.New("llm provider unavailable")
+	ErrLLMInvalidResponse = errors.New("llm provider returned invalid response")
+)
+
+// LLMProvider is implemented by every provider adapter. Implementations must
+// honour ctx cancellation for in-flight HTTP calls.
+type LLMProvider interface {
+	ChatCompletion(ctx context.Context, req *domain.CompletionRequest) (*domain.CompletionResponse, error)
+}
+
+// ModelCatalog lists the models a provider offers, with their prices.
+type ModelCatalog interface {
+	ListModels(ctx context.Context) ([]domain.CatalogModel, error)
+}
+
+// LLMError is returned by adapters for failed calls. Kind is one of the ErrLLM*
+// sentinels so callers can use errors.Is without knowing the provider.
+type LLMError struct {
+	Provider   domain.ProviderType
+	StatusCode int
+	Message    string
+	Kind       error
+}
+
+func (e *LLMError) Error() string {
+	return fmt.Sprintf("%s: %s (status %d): %s", e.Provider, e.Kind, e.StatusCode, e.Message)
+}
+
+func (e *LLMError) Unwrap() error {
+	return e.Kind
+}
+
+// Retryable reports whether the same request may succeed later.
+func (e *LLMError) Retryable() bool {
+	return errors.Is(e.Kind, ErrLLMRateLimited) || errors.Is(e.Kind, ErrLLMUnavailable)
+}
+
+// ErrorKindForStatus maps an HTTP status to an ErrLLM* sentinel. Adapters use it
+// as the fallback when the provider body carries no more specific code.
+func ErrorKindForStatus(status int) error {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ErrLLMAuthentication
+	case status == http.StatusTooManyRequests:
+		return ErrLLMRateLimited
+	case status >= http.StatusInternalServerError || status == http.StatusRequestTimeout:
+		return ErrLLMUnavailable
+	default:
+		return ErrLLMInvalidRequest
+	}
+}
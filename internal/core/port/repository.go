@@ -0,0 +1,183 @@
+package port
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProviderRepository stores the LLM backends of accounts.
+type ProviderRepository interface {
+	Create(ctx context.Context, p *domain.Provider) error
+	Get(ctx context.Context, accountID, id uuid.UUID) (*domain.Provider, error)
+	List(ctx context.Context, accountID uuid.UUID) ([]*domain.Provider, error)
+	Update(ctx context.Context, p *domain.Provider) error
+	Delete(ctx context.Context, accountID, id uuid.UUID) error
+}
+
+// ModelRepository stores models and resolves the model names used by prompt
+// steps.
+type ModelRepository interface {
+	Create(ctx context.Context, m *domain.Model) error
+	Get(ctx context.Context, accountID, id uuid.UUID) (*domain.Model, error)
+	GetByName(ctx context.Context, accountID uuid.UUID, name string) (*domain.Model, error)
+	List(ctx context.Context, accountID uuid.UUID) ([]*domain.Model, error)
+	Update(ctx context.Context, m *domain.Model) error
+	Delete(ctx context.Context, accountID, id uuid.UUID) error
+}
+
+// FlowRepository stores flows and resolves them for the engine, including
+// sub-flows that are referenced by name.
+type FlowRepository interface {
+	Create(ctx context.Context, f *domain.Flow) error
+	Get(ctx context.Context, accountID, id uuid.UUID) (*domain.Flow, error)
+	GetByName(ctx context.Context, accountID uuid.UUID, name string) (*domain.Flow, error)
+	List(ctx context.Context, accountID uuid.UUID) ([]*domain.Flow, error)
+	Update(ctx context.Context, f *domain.Flow) error
+	Delete(ctx context.Context, accountID, id uuid.UUID) error
+}
+
+// PromptRepository resolves the stored prompt revisions used by prompt steps.
+type PromptRepository interface {
+	ResolvePrompt(ctx context.Context, accountID uuid.UUID, ref domain.PromptRef) (*domain.PromptRevision, error)
+}
+
+// FlowRunRepository persists run state as a run progresses.
+type FlowRunRepository interface {
+	// CreateRun stores a new run together with all of its step runs.
+	CreateRun(ctx context.Context, run *domain.FlowRun, steps []*domain.StepRun) error
+	// UpdateRun saves run, except for CancelRequestedAt and LeaseExpiresAt:
+	// only CancelRun and ExtendRunLease set them, so a running run cannot
+	// write over a request to cancel it or over a newer lease.
+	UpdateRun(ctx context.Context, run *domain.FlowRun) error
+	UpdateStepRun(ctx context.Context, step *domain.StepRun) error
+	GetRun(ctx context.Context, accountID, id uuid.UUID) (*domain.FlowRun, error)
+	ListStepRuns(ctx context.Context, runID uuid.UUID) ([]*domain.StepRun, error)
+	// CancelRun sets CancelRequestedAt of an unfinished run to now. A pending
+	// run is canceled right away and its steps are skipped; a running run is
+	// left to the engine executing it. It returns the run as it is then, and
+	// finished runs unchanged.
+	CancelRun(ctx context.Context, accountID, id uuid.UUID, now time.Time) (*domain.FlowRun, error)
+	// ExtendRunLease sets LeaseExpiresAt of run id to until.
+	ExtendRunLease(ctx context.Context, id uuid.UUID, until time.Time) error
+	// FailExpiredRuns marks the running root runs whose lease expired by now
+	// as failed with message, together with their unfinished sub-flow runs
+	// at any depth, and returns how many runs were changed. Their
+	// running steps fail with message and their pending steps are skipped.
+	FailExpiredRuns(ctx context.Context, now time.Time, message string) (int, error)
+}
+
+// JobQueue is the durable queue that background work goes through. Workers,
+// in any number of processes, claim jobs one at a time; no two hold the same
+// job at once.
+type JobQueue interface {
+	Enqueue(ctx context.Context, job *domain.Job) error
+	// Claim leases the most urgent job of kinds that is due at now to
+	// worker until until. Jobs whose lease expired are claimed again, unless
+	// that was their last attempt: those are moved to the dead letter. It
+	// returns nil when no job is due.
+	Claim(ctx context.Context, worker string, kinds []domain.JobKind, now, until time.Time) (*domain.Job, error)
+	// Update saves a claimed job: an extended lease, a success or a failure.
+	// It fails with a *ConflictError once the worker lost the job, i.e. its
+	// lease expired and the job was claimed again.
+	Update(ctx context.Context, job *domain.Job) error
+	Get(ctx context.Context, id uuid.UUID) (*domain.Job, error)
+}
+
+// UsageLedger records every LLM call. It is append-only: records are never
+// updated or deleted once appended.
+type UsageLedger interface {
+	Append(ctx context.Context, record *domain.UsageRecord) error
+}
+
+// LLMProviderFactory builds the adapter for a stored provider.
+type LLMProviderFactory interface {
+	NewProvider(provider *domain.Provider) (LLMProvider, error)
+}
+
+// ModelCatalogFactory builds the models catalog of a stored provider.
+type ModelCatalogFactory interface {
+	NewCatalog(provider *domain.Provider) (ModelCatalog, error)
+}
+
+// ProviderLister lists providers across accounts, e.g. to sync every
+// provider of a type.
+type ProviderLister interface {
+	ListByType(ctx context.Context, t domain.ProviderType) ([]*domain.Provider, error)
+}
+
+// ModelPriceRepository is the pricing catalog synced from providers.
+type ModelPriceRepository interface {
+	ListByProvider(ctx context.Context, providerID uuid.UUID) ([]*domain.Model, error)
+	// SyncPrice adds price to its model's catalog unless the latest entry of
+	// the same source already charges the same, and stores the model's
+	// context window when it is known. It reports whether the price was
+	// added.
+	SyncPrice(ctx context.Context, price *domain.ModelPrice, contextWindow int) (bool, error)
+}
+
+// TestSuiteRunRepository persists test suite runs and their case results as
+// a run progresses.
+type TestSuiteRunRepository interface {
+	CreateSuiteRun(ctx context.Context, run *domain.TestSuiteRun) error
+	// UpdateSuiteRun saves run, except for LeaseExpiresAt, which only
+	// ExtendSuiteRunLease sets.
+	UpdateSuiteRun(ctx context.Context, run *domain.TestSuiteRun) error
+	GetSuiteRun(ctx context.Context, accountID, suiteID, id uuid.UUID) (*domain.TestSuiteRun, error)
+	CreateCaseResult(ctx context.Context, result *domain.TestCaseResult) error
+	// ExtendSuiteRunLease sets LeaseExpiresAt of run id to until.
+	ExtendSuiteRunLease(ctx context.Context, id uuid.UUID, until time.Time) error
+	// FailExpiredSuiteRuns marks the running suite runs whose lease expired
+	// by now as failed with message and returns how many runs were changed.
+	FailExpiredSuiteRuns(ctx context.Context, now time.Time, message string) (int, error)
+}
+
+// DatasetRepository resolves the dataset versions test suites run over and
+// pages through their rows.
+type DatasetRepository interface {
+	// ResolveDataset returns version of the dataset named name, or its
+	// latest version when version is zero.
+	ResolveDataset(ctx context.Context, accountID uuid.UUID, name string, version int) (*domain.DatasetVersion, error)
+	// ListRows returns at most limit rows of a version, in position order,
+	// starting at offset.
+	ListRows(ctx context.Context, versionID uuid.UUID, offset, limit int) ([]*domain.DatasetRow, error)
+}
+
+// BudgetRepository keeps budgets, their reservations and their events.
+type BudgetRepository interface {
+	// ListApplicable returns the budgets that calls made for target count
+	// against.
+	ListApplicable(ctx context.Context, target domain.BudgetTarget) ([]*domain.Budget, error)
+	// Spent returns what b has spent in the period of now: the ledger's
+	// costs plus the reservations of calls in flight, that did not expire.
+	Spent(ctx context.Context, b *domain.Budget, now time.Time) (domain.Money, error)
+	// Reserve stores the reservations of hold unless one of its budgets
+	// does not allow them, which it reports as a *domain.BudgetExceededError.
+	// Concurrent reservations against the same budget are serialized.
+	Reserve(ctx context.Context, hold *domain.BudgetHold, now time.Time) error
+	// Settle deletes the reservations of hold once its call is in the ledger
+	// or failed.
+	Settle(ctx context.Context, hold *domain.BudgetHold) error
+	// SettleExpired deletes the reservations that expired by now, left
+	// behind by calls that were in flight when their process died.
+	SettleExpired(ctx context.Context, now time.Time) (int, error)
+	// AddEvent stores e unless its budget already has an event of the same
+	// type for the period. It reports whether e was stored.
+	AddEvent(ctx context.Context, e *domain.BudgetEvent) (bool, error)
+}
+
+// BudgetGuard enforces budgets on runs and calls.
+type BudgetGuard interface {
+	// CheckRun rejects a new run for target with a
+	// *domain.BudgetExceededError when a budget is already at its hard limit.
+	CheckRun(ctx context.Context, target domain.BudgetTarget) error
+	// Reserve reserves amount against the budgets of target before a call,
+	// or rejects the call like CheckRun when amount does not fit. The call
+	// must be over by the hold's ExpiresAt.
+	Reserve(ctx context.Context, target domain.BudgetTarget, amount domain.Money) (*domain.BudgetHold, error)
+	// Settle releases hold after its call and emits the events of limits
+	// the call crossed.
+	Settle(ctx context.Context, hold *domain.BudgetHold) error
+}
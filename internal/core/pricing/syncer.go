@@ -0,0 +1,172 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"flow-run/internal/lib/logger"
+	"fmt"
+	"time"
+)
+
+// SyncResult counts what syncing one provider did.
+type SyncResult struct {
+	// Models is the number of the provider's models found in its catalog.
+	Models int
+	// Repriced is the number of those whose catalog gained a new price.
+	Repriced int
+}
+
+// Syncer keeps the pricing catalog of models in line with the catalogs their
+// providers publish. Models are matched to catalog entries by name. Synced
+// prices only take effect where no manual price is in effect.
+type Syncer struct {
+	providers port.ProviderLister
+	models    port.ModelPriceRepository
+	catalogs  port.ModelCatalogFactory
+	interval  time.Duration
+	now       func() time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewSyncer creates a syncer that, once started, syncs every interval. A zero
+// interval disables periodic syncs; Sync can still be called directly.
+func NewSyncer(
+	providers port.ProviderLister,
+	models port.ModelPriceRepository,
+	catalogs port.ModelCatalogFactory,
+	interval time.Duration,
+) *Syncer {
+	return &Syncer{
+		providers: providers,
+		models:    models,
+		catalogs:  catalogs,
+		interval:  interval,
+		now:       time.Now,
+	}
+}
+
+// Start syncs in the background every interval, beginning right away.
+func (s *Syncer) Start(_ context.Context) error {
+	if s.interval <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.Sync(ctx); err != nil && ctx.Err() == nil {
+				logger.Log.WithError(err).Warn("Failed to sync model prices")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop cancels a sync in progress and waits for it, or until ctx expires.
+func (s *Syncer) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Sync syncs every OpenRouter provider. A provider that fails does not stop
+// the others; all failures are returned together.
+func (s *Syncer) Sync(ctx context.Context) (SyncResult, error) {
+	providers, err := s.providers.ListByType(ctx, domain.ProviderTypeOpenRouter)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	var total SyncResult
+	var errs []error
+	for _, provider := range providers {
+		result, err := s.SyncProvider(ctx, provider)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sync provider %s: %w", provider.ID, err))
+			continue
+		}
+		total.Models += result.Models
+		total.Repriced += result.Repriced
+	}
+	if total.Repriced > 0 {
+		logger.Log.
+			WithField("models", total.Models).
+			WithField("repriced", total.Repriced).
+			Info("Synced model prices")
+	}
+	return total, errors.Join(errs...)
+}
+
+// SyncProvider prices the models of provider from its catalog, effective now.
+// Models missing from the catalog are left alone.
+func (s *Syncer) SyncProvider(ctx context.Context, provider *domain.Provider) (SyncResult, error) {
+	models, err := s.models.ListByProvider(ctx, provider.ID)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	if len(models) == 0 {
+		return SyncResult{}, nil
+	}
+
+	catalog, err := s.catalogs.NewCatalog(provider)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	listed, err := catalog.ListModels(ctx)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	byName := make(map[string]domain.CatalogModel, len(listed))
+	for _, m := range listed {
+		byName[m.Name] = m
+	}
+
+	var result SyncResult
+	now := s.now()
+	for _, model := range models {
+		entry, ok := byName[model.Name]
+		if !ok {
+			continue
+		}
+		result.Models++
+
+		priced := *model
+		priced.InputPrice = entry.InputPrice
+		priced.OutputPrice = entry.OutputPrice
+		priced.CachedPrice = entry.CachedPrice
+		price, err := domain.NewModelPrice(&priced, domain.PriceSourceOpenRouter, now)
+		if err != nil {
+			return result, err
+		}
+		added, err := s.models.SyncPrice(ctx, price, entry.ContextWindow)
+		if err != nil {
+			return result, fmt.Errorf("sync price of model %s: %w", model.Name, err)
+		}
+		if added {
+			result.Repriced++
+		}
+	}
+	return result, nil
+}
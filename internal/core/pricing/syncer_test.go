@@ -0,0 +1,175 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSync(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	accountID := uuid.New()
+	synced := newProvider(t, accountID)
+	failing := newProvider(t, accountID)
+	listed := newModel(t, accountID, synced.ID, "openai/gpt-4o")
+	unlisted := newModel(t, accountID, synced.ID, "my-fine-tune")
+
+	models := &fakeModels{
+		byProvider: map[uuid.UUID][]*domain.Model{
+			synced.ID:  {listed, unlisted},
+			failing.ID: {newModel(t, accountID, failing.ID, "openai/gpt-4o")},
+		},
+	}
+	catalogs := fakeCatalogs{
+		synced.ID: fakeCatalog{models: []domain.CatalogModel{{
+			Name:          "openai/gpt-4o",
+			ContextWindow: 128000,
+			InputPrice:    domain.Money(2_500_000_000),
+			OutputPrice:   domain.Money(10_000_000_000),
+			CachedPrice:   domain.Money(1_250_000_000),
+		}}},
+		failing.ID: fakeCatalog{err: errors.New("catalog unavailable")},
+	}
+	syncer := NewSyncer(fakeProviders{synced, failing}, models, catalogs, 0)
+	syncer.now = func() time.Time { return now }
+
+	result, err := syncer.Sync(context.Background())
+	require.ErrorContains(t, err, "catalog unavailable")
+	assert.Equal(t, SyncResult{Models: 1, Repriced: 1}, result)
+
+	require.Len(t, models.synced, 1)
+	price := models.synced[0]
+	assert.Equal(t, listed.ID, price.ModelID)
+	assert.Equal(t, domain.PriceSourceOpenRouter, price.Source)
+	assert.Equal(t, domain.Money(2_500_000_000), price.InputPrice)
+	assert.Equal(t, domain.Money(10_000_000_000), price.OutputPrice)
+	assert.Equal(t, domain.Money(1_250_000_000), price.CachedPrice)
+	assert.Equal(t, now, price.EffectiveAt)
+	assert.Equal(t, []int{128000}, models.contextWindows)
+
+	// Unchanged prices are not added again.
+	result, err = syncer.SyncProvider(context.Background(), synced)
+	require.NoError(t, err)
+	assert.Equal(t, SyncResult{Models: 1}, result)
+}
+
+func TestStartAndStop(t *testing.T) {
+	t.Parallel()
+
+	accountID := uuid.New()
+	provider := newProvider(t, accountID)
+	models := &fakeModels{byProvider: map[uuid.UUID][]*domain.Model{
+		provider.ID: {newModel(t, accountID, provider.ID, "openai/gpt-4o")},
+	}}
+	catalogs := fakeCatalogs{provider.ID: fakeCatalog{models: []domain.CatalogModel{{Name: "openai/gpt-4o"}}}}
+
+	disabled := NewSyncer(fakeProviders{provider}, models, catalogs, 0)
+	require.NoError(t, disabled.Start(context.Background()))
+	require.NoError(t, disabled.Stop(context.Background()))
+
+	syncer := NewSyncer(fakeProviders{provider}, models, catalogs, time.Hour)
+	require.NoError(t, syncer.Start(context.Background()))
+	require.Eventually(t, func() bool {
+		return models.calls() > 0
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, syncer.Stop(context.Background()))
+}
+
+func newProvider(t *testing.T, accountID uuid.UUID) *domain.Provider {
+	t.Helper()
+
+	p, err := domain.NewProvider(
+		domain.WithProviderID(uuid.New()),
+		domain.WithProviderName("openrouter"),
+		domain.WithProviderAccountID(accountID),
+		domain.WithProviderType(domain.ProviderTypeOpenRouter),
+		domain.WithProviderApiKey("sk-test"),
+	)
+	require.NoError(t, err)
+	return p
+}
+
+func newModel(t *testing.T, accountID, providerID uuid.UUID, name string) *domain.Model {
+	t.Helper()
+
+	m, err := domain.NewModel(
+		domain.WithModelID(uuid.New()),
+		domain.WithModelName(name),
+		domain.WithModelAccountID(accountID),
+		domain.WithModelProviderID(providerID),
+	)
+	require.NoError(t, err)
+	return m
+}
+
+type fakeProviders []*domain.Provider
+
+func (f fakeProviders) ListByType(_ context.Context, t domain.ProviderType) ([]*domain.Provider, error) {
+	var providers []*domain.Provider
+	for _, p := range f {
+		if p.Type == t {
+			providers = append(providers, p)
+		}
+	}
+	return providers, nil
+}
+
+type fakeModels struct {
+	byProvider     map[uuid.UUID][]*domain.Model
+	synced         []*domain.ModelPrice
+	contextWindows []int
+
+	mu       sync.Mutex
+	listings int
+}
+
+func (f *fakeModels) ListByProvider(_ context.Context, providerID uuid.UUID) ([]*domain.Model, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.listings++
+	return f.byProvider[providerID], nil
+}
+
+func (f *fakeModels) SyncPrice(_ context.Context, price *domain.ModelPrice, contextWindow int) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, p := range f.synced {
+		if p.ModelID == price.ModelID && p.SamePrices(price) {
+			return false, nil
+		}
+	}
+	f.synced = append(f.synced, price)
+	f.contextWindows = append(f.contextWindows, contextWindow)
+	return true, nil
+}
+
+func (f *fakeModels) calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.listings
+}
+
+type fakeCatalogs map[uuid.UUID]fakeCatalog
+
+func (f fakeCatalogs) NewCatalog(provider *domain.Provider) (port.ModelCatalog, error) {
+	return f[provider.ID], nil
+}
+
+type fakeCatalog struct {
+	models []domain.CatalogModel
+	err    error
+}
+
+func (f fakeCatalog) ListModels(_ context.Context) ([]domain.CatalogModel, error) {
+	return f.models, f.err
+}
@@ -0,0 +1,247 @@
+// Package worker executes the jobs of the durable queue in the background.
+package worker
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"flow-run/internal/lib/logger"
+	"flow-run/internal/lib/validator"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// retryBackoff is how long a failed job waits before its second attempt;
+	// the wait doubles with every attempt, up to maxRetryBackoff.
+	retryBackoff    = 10 * time.Second
+	maxRetryBackoff = 10 * time.Minute
+)
+
+// errLeaseLost cancels a job whose lease could not be extended: it may run
+// on another worker by now.
+var errLeaseLost = errors.New("job lease lost")
+
+// Handler does the work of a job. Returning an error fails the attempt, and
+// the job is retried later while it has attempts left. ctx is canceled when
+// the pool loses the job's lease.
+type Handler func(ctx context.Context, job *domain.Job) error
+
+type PoolConfig struct {
+	// Concurrency is how many jobs the pool works on at once; zero disables
+	// the pool, for processes that only serve the API.
+	Concurrency int `validate:"min=0,max=1000"`
+	// VisibilityTimeout is how long a claimed job stays hidden from other
+	// workers without a heartbeat. Heartbeats extend it every third of it.
+	VisibilityTimeout time.Duration `validate:"required,min=3s,max=1h"`
+	// PollInterval is how long an idle worker waits before it looks for
+	// jobs again.
+	PollInterval time.Duration `validate:"required,min=10ms,max=1m"`
+}
+
+// Pool claims jobs from the queue and runs their handlers. Several pools,
+// in as many processes, can share a queue: each job is leased to one worker
+// at a time, and is claimed again when its worker stops heartbeating.
+type Pool struct {
+	queue    port.JobQueue
+	config   *PoolConfig
+	handlers map[domain.JobKind]Handler
+	name     string
+	now      func() time.Time
+
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+}
+
+func NewPool(queue port.JobQueue, config *PoolConfig) (*Pool, error) {
+	validatedConfig, err := validator.Struct(config)
+	if err != nil {
+		return nil, err
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return &Pool{
+		queue:    queue,
+		config:   validatedConfig,
+		handlers: map[domain.JobKind]Handler{},
+		name:     fmt.Sprintf("%s/%d/%s", host, os.Getpid(), uuid.NewString()[:8]),
+		now:      time.Now,
+	}, nil
+}
+
+// Handle registers the handler of kind. Handlers are registered before the
+// pool starts; only jobs of registered kinds are claimed.
+func (p *Pool) Handle(kind domain.JobKind, handler Handler) {
+	p.handlers[kind] = handler
+}
+
+// Start runs Concurrency workers in the background.
+func (p *Pool) Start(_ context.Context) error {
+	if p.config.Concurrency == 0 || len(p.handlers) == 0 {
+		return nil
+	}
+
+	kinds := make([]domain.JobKind, 0, len(p.handlers))
+	for kind := range p.handlers {
+		kinds = append(kinds, kind)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	for i := range p.config.Concurrency {
+		worker := fmt.Sprintf("%s/%d", p.name, i)
+		p.done.Add(1)
+		go func() {
+			defer p.done.Done()
+			p.work(ctx, worker, kinds)
+		}()
+	}
+	return nil
+}
+
+// Stop stops claiming jobs and waits for the jobs in progress, or until ctx
+// expires. Jobs still in progress then are left to their leases.
+func (p *Pool) Stop(ctx context.Context) error {
+	if p.cancel == nil {
+		return nil
+	}
+	p.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		p.done.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// work claims and runs jobs until ctx is canceled, resting PollInterval
+// whenever the queue has nothing due.
+func (p *Pool) work(ctx context.Context, worker string, kinds []domain.JobKind) {
+	for {
+		now := p.now()
+		job, err := p.queue.Claim(ctx, worker, kinds, now, now.Add(p.config.VisibilityTimeout))
+		if err != nil && ctx.Err() == nil {
+			logger.Log.WithError(err).Warn("Failed to claim a job")
+		}
+		if job != nil {
+			// A claimed job is finished even while the pool stops, as its
+			// handler already got going.
+			p.run(context.WithoutCancel(ctx), job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.config.PollInterval):
+		}
+	}
+}
+
+// run runs the handler of job, heartbeating its lease meanwhile, and records
+// the outcome.
+func (p *Pool) run(ctx context.Context, job *domain.Job) {
+	log := logger.Log.WithField("job", job.ID).WithField("kind", job.Kind).WithField("attempt", job.Attempts)
+
+	handlerCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	stopHeartbeat := p.heartbeat(handlerCtx, job, cancel)
+	err := p.handle(handlerCtx, job)
+	stopHeartbeat()
+	if cause := context.Cause(handlerCtx); errors.Is(cause, errLeaseLost) {
+		log.Warn("Lost the lease of a job while it ran")
+		return
+	}
+
+	now := p.now()
+	if err == nil {
+		job.Succeed(now)
+	} else {
+		job.Fail(err.Error(), now, now.Add(backoff(job.Attempts)))
+	}
+	if err := p.queue.Update(ctx, job); err != nil {
+		log.WithError(err).Warn("Failed to record the outcome of a job")
+		return
+	}
+
+	switch job.Status {
+	case domain.JobStatusDead:
+		log.WithError(err).Error("Job failed on its last attempt, moved to the dead letter")
+	case domain.JobStatusQueued:
+		log.WithError(err).WithField("retry_at", job.RunAt).Warn("Job failed, retrying later")
+	}
+}
+
+// handle runs the handler of job, reporting a panic as an error.
+func (p *Pool) handle(ctx context.Context, job *domain.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return p.handlers[job.Kind](ctx, job)
+}
+
+// heartbeat extends the lease of job every third of the visibility timeout
+// until the returned function is called. It cancels the handler when the
+// lease is lost.
+func (p *Pool) heartbeat(ctx context.Context, job *domain.Job, cancel context.CancelCauseFunc) func() {
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(p.config.VisibilityTimeout / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			job.Extend(p.now().Add(p.config.VisibilityTimeout))
+			err := p.queue.Update(ctx, job)
+			if errors.Is(err, port.ErrConflict) {
+				cancel(errLeaseLost)
+				return
+			}
+			if err != nil {
+				logger.Log.WithError(err).WithField("job", job.ID).Warn("Failed to extend the lease of a job")
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-stopped
+	}
+}
+
+// backoff is how long a job waits after its attempt-th attempt failed.
+func backoff(attempt int) time.Duration {
+	delay := retryBackoff
+	for range attempt - 1 {
+		delay *= 2
+		if delay >= maxRetryBackoff {
+			return maxRetryBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,140 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/memory"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKind = domain.JobKind("test")
+
+func TestPool(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		maxAttempts int
+		handler     Handler
+		wantStatus  domain.JobStatus
+		wantError   string
+	}{
+		{
+			name:        "succeeds",
+			maxAttempts: 3,
+			handler:     func(context.Context, *domain.Job) error { return nil },
+			wantStatus:  domain.JobStatusSucceeded,
+		},
+		{
+			name:        "retries",
+			maxAttempts: 3,
+			handler:     func(context.Context, *domain.Job) error { return errors.New("unavailable") },
+			wantStatus:  domain.JobStatusQueued,
+			wantError:   "unavailable",
+		},
+		{
+			name:        "dead_letter",
+			maxAttempts: 1,
+			handler:     func(context.Context, *domain.Job) error { return errors.New("unavailable") },
+			wantStatus:  domain.JobStatusDead,
+			wantError:   "unavailable",
+		},
+		{
+			name:        "panics",
+			maxAttempts: 1,
+			handler:     func(context.Context, *domain.Job) error { panic("boom") },
+			wantStatus:  domain.JobStatusDead,
+			wantError:   "handler panicked: boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			queue := memory.NewJobQueue()
+			job, err := domain.NewJob(testKind, map[string]string{"a": "b"}, time.Now(),
+				domain.WithJobMaxAttempts(tt.maxAttempts))
+			require.NoError(t, err)
+			require.NoError(t, queue.Enqueue(ctx, job))
+
+			pool := newTestPool(t, queue)
+			handled := make(chan *domain.Job, 1)
+			pool.Handle(testKind, func(ctx context.Context, job *domain.Job) error {
+				handled <- job
+				return tt.handler(ctx, job)
+			})
+			require.NoError(t, pool.Start(ctx))
+			got := <-handled
+			require.NoError(t, pool.Stop(ctx))
+
+			assert.Equal(t, job.ID, got.ID)
+			assert.JSONEq(t, `{"a": "b"}`, string(got.Payload))
+			stored, err := queue.Get(ctx, job.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, stored.Status)
+			assert.Equal(t, tt.wantError, stored.LastError)
+			assert.Equal(t, 1, stored.Attempts)
+			if tt.wantStatus == domain.JobStatusQueued {
+				assert.WithinDuration(t, time.Now().Add(retryBackoff), stored.RunAt, time.Second)
+			}
+		})
+	}
+}
+
+func TestPoolOnlyClaimsHandledKinds(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	queue := memory.NewJobQueue()
+	job, err := domain.NewJob(domain.JobKind("other"), nil, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, queue.Enqueue(ctx, job))
+
+	pool := newTestPool(t, queue)
+	pool.Handle(testKind, func(context.Context, *domain.Job) error { return nil })
+	require.NoError(t, pool.Start(ctx))
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, pool.Stop(ctx))
+
+	stored, err := queue.Get(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobStatusQueued, stored.Status)
+}
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 10 * time.Second},
+		{attempt: 2, want: 20 * time.Second},
+		{attempt: 4, want: 80 * time.Second},
+		{attempt: 7, want: maxRetryBackoff},
+		{attempt: 100, want: maxRetryBackoff},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, backoff(tt.attempt), "attempt %d", tt.attempt)
+	}
+}
+
+func newTestPool(t *testing.T, queue *memory.JobQueue) *Pool {
+	t.Helper()
+
+	pool, err := NewPool(queue, &PoolConfig{
+		Concurrency:       2,
+		VisibilityTimeout: time.Minute,
+		PollInterval:      10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	return pool
+}
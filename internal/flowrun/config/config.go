@@ -1,7 +1,9 @@
 package config
 
 import (
+	"flow-run/internal/core/worker"
 	"flow-run/internal/flowrun/infra/database"
+	"flow-run/internal/lib/envelope"
 	"flow-run/internal/lib/logger"
 	"flow-run/internal/lib/validator"
 	"os"
@@ -13,27 +15,56 @@ import (
 
 type Config struct {
 	*database.DatabaseConfig
+	*envelope.KeyringConfig
+	*worker.PoolConfig
 	ServerPort string `validate:"required,numeric,min=1,max=65535"`
 	ServerHost string `validate:"required,ip"`
+	// PriceSyncInterval is how often model prices are synced from OpenRouter;
+	// zero disables the sync.
+	PriceSyncInterval time.Duration `validate:"min=0"`
 }
 
 func FromEnv() (*Config, error) {
 	_ = godotenv.Load()
 
 	config := &Config{
-		DatabaseConfig: &database.DatabaseConfig{
-			URL:             os.Getenv("DATABASE_URL"),
-			MaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
-			MaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 10),
-			ConnMaxLifetime: getEnvAsDuration("DB_CONN_MAX_LIFETIME", 5*time.Minute),
+		DatabaseConfig: DatabaseFromEnv(),
+		KeyringConfig:  KeyringFromEnv(),
+		PoolConfig: &worker.PoolConfig{
+			Concurrency:       getEnvAsInt("WORKER_CONCURRENCY", 4),
+			VisibilityTimeout: getEnvAsDuration("JOB_VISIBILITY_TIMEOUT", time.Minute),
+			PollInterval:      getEnvAsDuration("JOB_POLL_INTERVAL", time.Second),
 		},
-		ServerPort: getEnvWithDefault("SERVER_PORT", "8080"),
-		ServerHost: getEnvWithDefault("SERVER_HOST", "0.0.0.0"),
+		ServerPort:        getEnvWithDefault("SERVER_PORT", "8080"),
+		ServerHost:        getEnvWithDefault("SERVER_HOST", "0.0.0.0"),
+		PriceSyncInterval: getEnvAsDuration("PRICE_SYNC_INTERVAL", 24*time.Hour),
 	}
 
 	return validator.Struct(config)
 }
 
+// DatabaseFromEnv reads only the database settings, for commands that need
+// nothing else, such as migrations.
+func DatabaseFromEnv() *database.DatabaseConfig {
+	_ = godotenv.Load()
+
+	return &database.DatabaseConfig{
+		URL:             os.Getenv("DATABASE_URL"),
+		MaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
+		MaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 10),
+		ConnMaxLifetime: getEnvAsDuration("DB_CONN_MAX_LIFETIME", 5*time.Minute),
+	}
+}
+
+// KeyringFromEnv reads only the master keys.
+func KeyringFromEnv() *envelope.KeyringConfig {
+	_ = godotenv.Load()
+
+	return &envelope.KeyringConfig{
+		MasterKeys: os.Getenv("ENCRYPTION_MASTER_KEYS"),
+	}
+}
+
 func getEnvWithDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
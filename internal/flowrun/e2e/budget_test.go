@@ -0,0 +1,186 @@
+package e2e
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/database"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCappedFlow = `
+name: capped
+steps:
+  - {id: ask, type: prompt, prompt: {model: capped, user: hi, parameters: {max_tokens: 100}}}
+`
+
+func TestBudget(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	provider, err := testClient.CreateProvider(ctx, model.CreateProviderRequest{
+		Name:      "mock",
+		AccountID: accountID,
+		Type:      "mock",
+		ApiKey: `{"usage": {"prompt_tokens": 1000, "completion_tokens": 100},` +
+			` "rules": [{"regex": ".", "response": "ok"}]}`,
+	})
+	require.NoError(t, err)
+	_, err = testClient.CreateModel(ctx, model.CreateModelRequest{
+		Name:        "capped",
+		AccountID:   accountID,
+		ProviderID:  provider.ID,
+		InputPrice:  "2",
+		OutputPrice: "8",
+	})
+	require.NoError(t, err)
+	created, err := testClient.CreateFlow(ctx, model.CreateFlowRequest{
+		AccountID: accountID,
+		Format:    "yaml",
+		Source:    testCappedFlow,
+	})
+	require.NoError(t, err)
+
+	// Each call costs $0.0028; a call is let through while the $0.0008 its
+	// 100 completion tokens may cost still fits.
+	b, err := testClient.CreateBudget(ctx, model.CreateBudgetRequest{
+		Name:      "capped",
+		AccountID: accountID,
+		Scope:     "flow",
+		TargetID:  &created.ID,
+		Period:    "daily",
+		SoftLimit: "0.002",
+		HardLimit: "0.005",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "0", b.Spent)
+	assert.Equal(t, time.Now().UTC().Truncate(24*time.Hour), b.PeriodStart.UTC())
+
+	flow, err := database.NewFlowRepository(testFlowRun.DB).Get(ctx, accountID, created.ID)
+	require.NoError(t, err)
+	for range 2 {
+		run, err := testFlowRun.Engine.Run(ctx, flow, nil)
+		require.NoError(t, err)
+		require.Equal(t, domain.RunStatusSucceeded, run.Status, run.Error)
+	}
+
+	_, err = testFlowRun.Engine.Run(ctx, flow, nil)
+	require.ErrorIs(t, err, domain.ErrBudgetExceeded)
+
+	b, err = testClient.GetBudget(ctx, accountID, b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "0.0056", b.Spent)
+
+	events, err := testClient.ListBudgetEvents(ctx, accountID, b.ID)
+	require.NoError(t, err)
+	require.Len(t, events.Events, 2)
+	assert.Equal(t, "soft_limit_crossed", events.Events[0].Type)
+	assert.Equal(t, "0.0028", events.Events[0].Spent)
+	assert.Equal(t, "0.002", events.Events[0].Limit)
+	assert.Equal(t, "hard_limit_reached", events.Events[1].Type)
+	assert.Equal(t, "0.0056", events.Events[1].Spent)
+
+	// Raising the hard limit lets runs through again.
+	b, err = testClient.UpdateBudget(ctx, accountID, b.ID, model.UpdateBudgetRequest{
+		Name:      "capped",
+		Scope:     "flow",
+		TargetID:  &created.ID,
+		Period:    "monthly",
+		HardLimit: "1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "0", b.SoftLimit)
+	run, err := testFlowRun.Engine.Run(ctx, flow, nil)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStatusSucceeded, run.Status, run.Error)
+
+	list, err := testClient.ListBudgets(ctx, accountID)
+	require.NoError(t, err)
+	require.Len(t, list.Budgets, 1)
+	assert.Equal(t, "0.0084", list.Budgets[0].Spent)
+
+	require.NoError(t, testClient.DeleteBudget(ctx, accountID, b.ID))
+	_, err = testClient.GetBudget(ctx, accountID, b.ID)
+	var errResp *model.ErrorResponse
+	require.True(t, errors.As(err, &errResp), err)
+	assert.Equal(t, http.StatusNotFound, errResp.StatusCode)
+}
+
+func TestCreateBudgetIfInvalid(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	unknown := uuid.New()
+	_, err := testClient.CreateBudget(ctx, model.CreateBudgetRequest{
+		Name: "account", AccountID: accountID, Scope: "account", Period: "monthly", HardLimit: "10",
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		req    model.CreateBudgetRequest
+		status int
+		field  string
+	}{
+		{
+			name:   "no_limit",
+			req:    model.CreateBudgetRequest{Name: "x", AccountID: accountID, Scope: "account", Period: "daily"},
+			status: http.StatusBadRequest,
+			field:  "hard_limit",
+		},
+		{
+			name: "invalid_limit",
+			req: model.CreateBudgetRequest{
+				Name: "x", AccountID: accountID, Scope: "account", Period: "daily", HardLimit: "ten",
+			},
+			status: http.StatusBadRequest,
+			field:  "hard_limit",
+		},
+		{
+			name: "unknown_flow",
+			req: model.CreateBudgetRequest{
+				Name: "x", AccountID: accountID, Scope: "flow", TargetID: &unknown, Period: "daily", HardLimit: "1",
+			},
+			status: http.StatusBadRequest,
+			field:  "target_id",
+		},
+		{
+			name: "duplicate_name",
+			req: model.CreateBudgetRequest{
+				Name: "account", AccountID: accountID, Scope: "account", Period: "daily", HardLimit: "1",
+			},
+			status: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			_, err := testClient.CreateBudget(ctx, tt.req)
+			var errResp *model.ErrorResponse
+			require.True(t, errors.As(err, &errResp), err)
+			assert.Equal(t, tt.status, errResp.StatusCode)
+			if tt.field != "" {
+				require.NotEmpty(t, errResp.Fields)
+				assert.Equal(t, tt.field, errResp.Fields[0].Field)
+			}
+		})
+	}
+}
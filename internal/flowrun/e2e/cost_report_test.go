@@ -0,0 +1,152 @@
+package e2e
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/database"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBulletsFlow = `
+name: bullets
+inputs:
+  - {name: points, type: array, required: true}
+outputs:
+  - {name: summary, type: string, from: steps.summarize.output}
+steps:
+  - id: summarize
+    type: prompt
+    inputs: {points: inputs.points}
+    prompt: {model: summarizer, prompt: summary}
+`
+
+func TestCostReport(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	provider, err := testClient.CreateProvider(ctx, model.CreateProviderRequest{
+		Name:      "mock",
+		AccountID: accountID,
+		Type:      "mock",
+		ApiKey: `{"usage": {"prompt_tokens": 1000, "completion_tokens": 100},` +
+			` "rules": [{"regex": ".", "response": "ok"}]}`,
+	})
+	require.NoError(t, err)
+	summarizer, err := testClient.CreateModel(ctx, model.CreateModelRequest{
+		Name:        "summarizer",
+		AccountID:   accountID,
+		ProviderID:  provider.ID,
+		InputPrice:  "2",
+		OutputPrice: "8",
+	})
+	require.NoError(t, err)
+	prompt, err := testClient.CreatePrompt(ctx, model.CreatePromptRequest{
+		AccountID: accountID,
+		Name:      "summary",
+		Author:    "alice",
+		Template:  testPromptTemplate,
+	})
+	require.NoError(t, err)
+
+	flows := database.NewFlowRepository(testFlowRun.DB)
+	run := func(source string, inputs map[string]any) uuid.UUID {
+		created, err := testClient.CreateFlow(ctx, model.CreateFlowRequest{
+			AccountID: accountID,
+			Format:    "yaml",
+			Source:    source,
+		})
+		require.NoError(t, err)
+		flow, err := flows.Get(ctx, accountID, created.ID)
+		require.NoError(t, err)
+		for range 2 {
+			run, err := testFlowRun.Engine.Run(ctx, flow, inputs)
+			require.NoError(t, err)
+			require.Equal(t, domain.RunStatusSucceeded, run.Status, run.Error)
+		}
+		return created.ID
+	}
+	bulletsID := run(testBulletsFlow, map[string]any{"points": []any{"a"}})
+	summarizeID := run(testSummarizeFlow, map[string]any{"text": "a long text"})
+
+	from, to := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	report, err := testClient.GetCostReport(ctx, model.CostReportRequest{
+		AccountID: &accountID,
+		From:      from,
+		To:        to,
+		Bucket:    "hour",
+		GroupBy:   []string{"flow", "model", "prompt_revision"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hour", report.Bucket)
+	assert.Equal(t, 4, report.Calls)
+	assert.Equal(t, 4400, report.Usage.TotalTokens)
+	// Each call costs 1000 tokens at $2 and 100 tokens at $8 per million.
+	assert.Equal(t, "0.0112", report.Cost)
+
+	calls := map[uuid.UUID]int{}
+	for _, row := range report.Rows {
+		assert.Equal(t, row.Bucket.Truncate(time.Hour), row.Bucket)
+		assert.WithinRange(t, row.Bucket, from.Truncate(time.Hour), to)
+		assert.Equal(t, &summarizer.ID, row.ModelID)
+		assert.Equal(t, "summarizer", row.Model)
+		assert.Nil(t, row.AccountID)
+		require.NotNil(t, row.FlowID)
+		calls[*row.FlowID] += row.Calls
+		if *row.FlowID == bulletsID {
+			assert.Equal(t, &prompt.ID, row.PromptID)
+			assert.Equal(t, 1, row.PromptRevision)
+		} else {
+			assert.Nil(t, row.PromptID)
+		}
+	}
+	assert.Equal(t, map[uuid.UUID]int{bulletsID: 2, summarizeID: 2}, calls)
+
+	// Across accounts, grouped by account.
+	all, err := testClient.GetCostReport(ctx, model.CostReportRequest{
+		From:    from,
+		To:      to,
+		Bucket:  "month",
+		GroupBy: []string{"account", "provider"},
+	})
+	require.NoError(t, err)
+	var mine []model.CostReportRow
+	for _, row := range all.Rows {
+		if *row.AccountID == accountID {
+			mine = append(mine, row)
+		}
+	}
+	require.NotEmpty(t, mine)
+	assert.Equal(t, &provider.ID, mine[0].ProviderID)
+
+	empty, err := testClient.GetCostReport(ctx, model.CostReportRequest{
+		AccountID: &accountID,
+		From:      from.Add(-48 * time.Hour),
+		To:        from.Add(-24 * time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "day", empty.Bucket)
+	assert.Equal(t, "0", empty.Cost)
+	assert.Empty(t, empty.Rows)
+
+	_, err = testClient.GetCostReport(ctx, model.CostReportRequest{
+		AccountID: &accountID,
+		From:      from,
+		To:        to,
+		GroupBy:   []string{"step"},
+	})
+	var errResp *model.ErrorResponse
+	require.True(t, errors.As(err, &errResp), err)
+	assert.Equal(t, http.StatusBadRequest, errResp.StatusCode)
+	assert.Equal(t, "group_by[0]", errResp.Fields[0].Field)
+}
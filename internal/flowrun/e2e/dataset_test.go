@@ -0,0 +1,181 @@
+package e2e
+
+import (
+	"context"
+	"errors"
+	"flow-run/pkg/flowrunclient/model"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatasetCRUD(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	req := model.CreateDatasetRequest{AccountID: accountID, Name: "cities"}
+	created, err := testClient.CreateDataset(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 0, created.Version)
+
+	_, err = testClient.CreateDataset(ctx, req)
+	assertStatus(t, err, http.StatusConflict)
+
+	updated, err := testClient.UpdateDataset(ctx, accountID, created.ID, model.UpdateDatasetRequest{
+		Name:        "capitals",
+		Description: "European capitals",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "European capitals", updated.Description)
+
+	datasets, err := testClient.ListDatasets(ctx, accountID)
+	require.NoError(t, err)
+	require.Len(t, datasets.Datasets, 1)
+	assert.Equal(t, *updated, datasets.Datasets[0])
+
+	require.NoError(t, testClient.DeleteDataset(ctx, accountID, created.ID))
+	_, err = testClient.GetDataset(ctx, accountID, created.ID)
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func TestImportDataset(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	dataset, err := testClient.CreateDataset(ctx, model.CreateDatasetRequest{AccountID: accountID, Name: "cities"})
+	require.NoError(t, err)
+
+	csv := "name,city,expected\nparis,Paris,France\nrome,Rome,\n"
+	first, err := testClient.ImportDataset(ctx, accountID, dataset.ID, model.DatasetFormatCSV, strings.NewReader(csv))
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.Version)
+	assert.Equal(t, 2, first.RowCount)
+
+	jsonl := `{"inputs": {"city": "Oslo"}, "expected": {"country": "Norway"}}`
+	second, err := testClient.ImportDataset(
+		ctx, accountID, dataset.ID, model.DatasetFormatJSONL, strings.NewReader(jsonl),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 2, second.Version)
+
+	rows, err := testClient.ListDatasetRows(ctx, accountID, dataset.ID, 1, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []model.DatasetRow{{Position: 1, Name: "rome", Inputs: map[string]any{"city": "Rome"}}}, rows.Rows)
+	rows, err = testClient.ListDatasetRows(ctx, accountID, dataset.ID, 2, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"country": "Norway"}, rows.Rows[0].Expected)
+
+	// Malformed and empty uploads leave the dataset as it was.
+	_, err = testClient.ImportDataset(ctx, accountID, dataset.ID, model.DatasetFormatJSONL, strings.NewReader("{"))
+	assertStatus(t, err, http.StatusBadRequest)
+	_, err = testClient.ImportDataset(ctx, accountID, dataset.ID, model.DatasetFormatCSV, strings.NewReader("city\n"))
+	assertStatus(t, err, http.StatusBadRequest)
+	_, err = testClient.ImportDataset(ctx, accountID, dataset.ID, "xlsx", strings.NewReader("city\n"))
+	assertStatus(t, err, http.StatusBadRequest)
+
+	versions, err := testClient.ListDatasetVersions(ctx, accountID, dataset.ID)
+	require.NoError(t, err)
+	require.Len(t, versions.Versions, 2)
+	assert.Equal(t, []string{"csv", "jsonl"}, []string{versions.Versions[0].Format, versions.Versions[1].Format})
+
+	fetched, err := testClient.GetDataset(ctx, accountID, dataset.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fetched.Version)
+	assert.Equal(t, 1, fetched.RowCount)
+
+	_, err = testClient.ListDatasetRows(ctx, accountID, dataset.ID, 9, 0, 0)
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func TestImportLargeDataset(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	dataset, err := testClient.CreateDataset(ctx, model.CreateDatasetRequest{AccountID: accountID, Name: "numbers"})
+	require.NoError(t, err)
+
+	// The upload is produced while it is sent, spanning several insert
+	// batches.
+	body, writer := io.Pipe()
+	go func() {
+		_, err := io.WriteString(writer, "n\n")
+		for i := 0; i < 1234 && err == nil; i++ {
+			_, err = fmt.Fprintf(writer, "%d\n", i)
+		}
+		writer.CloseWithError(err)
+	}()
+	version, err := testClient.ImportDataset(ctx, accountID, dataset.ID, model.DatasetFormatCSV, body)
+	require.NoError(t, err)
+	assert.Equal(t, 1234, version.RowCount)
+
+	rows, err := testClient.ListDatasetRows(ctx, accountID, dataset.ID, 1, 1200, 1000)
+	require.NoError(t, err)
+	require.Len(t, rows.Rows, 34)
+	assert.Equal(t, map[string]any{"n": "1233"}, rows.Rows[33].Inputs)
+}
+
+func TestRunDatasetTestSuite(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	createTestSuitePrompt(ctx, t, accountID)
+	dataset, err := testClient.CreateDataset(ctx, model.CreateDatasetRequest{AccountID: accountID, Name: "cities"})
+	require.NoError(t, err)
+	csv := "city,expected\nParis,France\nBerlin,Germany\n"
+	_, err = testClient.ImportDataset(ctx, accountID, dataset.ID, model.DatasetFormatCSV, strings.NewReader(csv))
+	require.NoError(t, err)
+
+	req := model.CreateTestSuiteRequest{
+		AccountID:  accountID,
+		Name:       "countries",
+		Prompt:     "describe",
+		Dataset:    "cities",
+		Assertions: []model.Assertion{{Type: "contains"}},
+	}
+	suite, err := testClient.CreateTestSuite(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "cities", suite.Dataset)
+
+	run := runTestSuite(ctx, t, accountID, suite.ID, model.RunTestSuiteRequest{Model: "priced"})
+	assert.Equal(t, 1, run.DatasetVersion)
+	assert.Equal(t, 1, run.Passed)
+	assert.Equal(t, 1, run.Failed)
+	require.Len(t, run.Cases, 2)
+	assert.Equal(t, "row 2", run.Cases[1].Name)
+	assert.Equal(t, `output does not contain "Germany"`, run.Cases[1].Assertions[0].Message)
+
+	_, err = testClient.RunTestSuite(ctx, accountID, suite.ID, model.RunTestSuiteRequest{
+		Model: "priced", DatasetVersion: 2,
+	})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	// Suites reference datasets by name, so a dataset in use stays.
+	err = testClient.DeleteDataset(ctx, accountID, dataset.ID)
+	assertStatus(t, err, http.StatusConflict)
+
+	req.Name, req.Dataset = "missing", "towns"
+	_, err = testClient.CreateTestSuite(ctx, req)
+	var errResp *model.ErrorResponse
+	require.True(t, errors.As(err, &errResp), err)
+	assert.Equal(t, http.StatusBadRequest, errResp.StatusCode)
+	assert.Equal(t, "dataset", errResp.Fields[0].Field)
+}
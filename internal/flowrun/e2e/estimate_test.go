@@ -0,0 +1,115 @@
+package e2e
+
+import (
+	"context"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateFlow(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	provider := createTestProvider(ctx, t, accountID)
+	_, err := testClient.CreateModel(ctx, model.CreateModelRequest{
+		Name:        "summarizer",
+		AccountID:   accountID,
+		ProviderID:  provider.ID,
+		InputPrice:  "2",
+		OutputPrice: "8",
+	})
+	require.NoError(t, err)
+	flow, err := testClient.CreateFlow(ctx, model.CreateFlowRequest{
+		AccountID: accountID,
+		Format:    "yaml",
+		Source:    testSummarizeFlow,
+	})
+	require.NoError(t, err)
+
+	estimate, err := testClient.EstimateFlow(ctx, accountID, flow.ID, model.EstimateFlowRequest{
+		Inputs:           map[string]any{"text": "hello"},
+		CompletionTokens: 100,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, flow.ID, estimate.FlowID)
+	require.Len(t, estimate.Steps, 1)
+	step := estimate.Steps[0]
+	assert.Equal(t, "summarize", step.StepID)
+	assert.Equal(t, "summarizer", step.Model)
+	assert.True(t, step.Rendered)
+	assert.Equal(t, 100, step.Usage.CompletionTokens)
+	assert.Equal(t, step.Usage, estimate.Usage)
+	assert.Equal(t, step.Cost, estimate.Cost)
+	// "Summarize: hello" is 5 tokens of cl100k_base, wrapped in 6 of the
+	// chat format: 11 tokens at $2 and 100 at $8 per million.
+	assert.Equal(t, "cl100k_base", step.Encoding)
+	assert.Equal(t, 11, step.Usage.PromptTokens)
+	assert.Equal(t, "0.000822", step.Cost)
+
+	// Estimates never call the provider, so nothing is recorded.
+	usage, err := testClient.ListUsage(ctx, accountID, model.ListUsageRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, usage.Records)
+}
+
+func TestEstimateFlowIfInvalid(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	createTestModel(ctx, t, accountID, "summarizer")
+	flow, err := testClient.CreateFlow(ctx, model.CreateFlowRequest{
+		AccountID: accountID,
+		Format:    "yaml",
+		Source:    testSummarizeFlow,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		req   model.EstimateFlowRequest
+		field string
+	}{
+		{name: "missing_input", req: model.EstimateFlowRequest{}, field: "inputs.text"},
+		{
+			name:  "unknown_model",
+			req:   model.EstimateFlowRequest{Inputs: map[string]any{"text": "x"}, Model: "missing"},
+			field: "model",
+		},
+		{
+			name:  "negative_completion_tokens",
+			req:   model.EstimateFlowRequest{Inputs: map[string]any{"text": "x"}, CompletionTokens: -1},
+			field: "completion_tokens",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			_, err := testClient.EstimateFlow(ctx, accountID, flow.ID, tt.req)
+			var errResp *model.ErrorResponse
+			require.ErrorAs(t, err, &errResp)
+			assert.Equal(t, http.StatusBadRequest, errResp.StatusCode)
+			require.Len(t, errResp.Fields, 1)
+			assert.Equal(t, tt.field, errResp.Fields[0].Field)
+		})
+	}
+
+	_, err = testClient.EstimateFlow(ctx, accountID, uuid.New(), model.EstimateFlowRequest{})
+	assertStatus(t, err, http.StatusNotFound)
+}
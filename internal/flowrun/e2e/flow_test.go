@@ -0,0 +1,163 @@
+package e2e
+
+import (
+	"context"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSummarizeFlow = `
+name: summarize
+inputs:
+  - name: text
+    type: string
+    required: true
+outputs:
+  - name: summary
+    type: string
+    from: steps.summarize.output
+steps:
+  - id: summarize
+    type: prompt
+    inputs:
+      text: inputs.text
+    prompt:
+      model: summarizer
+      user: "Summarize: {{ .text }}"
+`
+
+func TestFlowCRUD(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	createTestModel(ctx, t, accountID, "summarizer")
+
+	created, err := testClient.CreateFlow(ctx, model.CreateFlowRequest{
+		AccountID: accountID,
+		Format:    "yaml",
+		Source:    testSummarizeFlow,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "summarize", created.Name)
+	assert.Equal(t, testSummarizeFlow, created.Source)
+
+	fetched, err := testClient.GetFlow(ctx, accountID, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created, fetched)
+
+	jsonSource := `{"name": "summarize_v2", "steps": [` +
+		`{"id": "echo", "type": "transform", "transform": {"template": "static"}}]}`
+	updated, err := testClient.UpdateFlow(ctx, accountID, created.ID, model.UpdateFlowRequest{
+		Format: "json",
+		Source: jsonSource,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "summarize_v2", updated.Name)
+	assert.Equal(t, "json", updated.Format)
+
+	list, err := testClient.ListFlows(ctx, accountID)
+	require.NoError(t, err)
+	require.Len(t, list.Flows, 1)
+	assert.Equal(t, *updated, list.Flows[0])
+
+	require.NoError(t, testClient.DeleteFlow(ctx, accountID, created.ID))
+
+	_, err = testClient.GetFlow(ctx, accountID, created.ID)
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func TestCreateFlowIfReferencesMissing(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+
+	_, err := testClient.CreateFlow(ctx, model.CreateFlowRequest{
+		AccountID: accountID,
+		Format:    "yaml",
+		Source:    testSummarizeFlow,
+	})
+
+	var errResp *model.ErrorResponse
+	require.ErrorAs(t, err, &errResp)
+	assert.Equal(t, http.StatusBadRequest, errResp.StatusCode)
+	require.Len(t, errResp.Fields, 1)
+	assert.Equal(t, "steps[0].prompt.model", errResp.Fields[0].Field)
+
+	_, err = testClient.CreateFlow(ctx, model.CreateFlowRequest{
+		AccountID: accountID,
+		Format:    "yaml",
+		Source:    "name: parent\nsteps:\n  - {id: child, type: flow, flow: {name: missing}}\n",
+	})
+	assertStatus(t, err, http.StatusBadRequest)
+}
+
+func TestCreateFlowIfInvalidDefinition(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := testClient.CreateFlow(ctx, model.CreateFlowRequest{
+		AccountID: uuid.New(),
+		Format:    "yaml",
+		Source: "name: broken\nmode: dag\nsteps:\n" +
+			"  - {id: a, type: transform, depends_on: [b], transform: {template: x}}\n" +
+			"  - {id: b, type: transform, inputs: {v: inputs.missing}, transform: {template: x}}\n",
+	})
+
+	var errResp *model.ErrorResponse
+	require.ErrorAs(t, err, &errResp)
+	assert.Equal(t, http.StatusBadRequest, errResp.StatusCode)
+
+	fields := make([]string, 0, len(errResp.Fields))
+	for _, f := range errResp.Fields {
+		fields = append(fields, f.Field)
+	}
+	assert.ElementsMatch(t, []string{"steps[1].inputs.v"}, fields)
+}
+
+func TestCreateFlowIfDuplicateName(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req := model.CreateFlowRequest{
+		AccountID: uuid.New(),
+		Format:    "yaml",
+		Source:    "name: once\nsteps:\n  - {id: a, type: transform, transform: {template: x}}\n",
+	}
+
+	_, err := testClient.CreateFlow(ctx, req)
+	require.NoError(t, err)
+
+	_, err = testClient.CreateFlow(ctx, req)
+	assertStatus(t, err, http.StatusConflict)
+}
+
+func createTestModel(ctx context.Context, t *testing.T, accountID uuid.UUID, name string) *model.ModelResponse {
+	t.Helper()
+
+	provider := createTestProvider(ctx, t, accountID)
+
+	m, err := testClient.CreateModel(ctx, model.CreateModelRequest{
+		Name:       name,
+		AccountID:  accountID,
+		ProviderID: provider.ID,
+	})
+	require.NoError(t, err)
+
+	return m
+}
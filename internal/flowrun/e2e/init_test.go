@@ -3,6 +3,7 @@ package e2e
 import (
 	"context"
 	"flow-run/internal/flowrun"
+	"flow-run/internal/flowrun/infra/database"
 	"flow-run/internal/lib/logger"
 	"flow-run/pkg/flowrunclient"
 	"fmt"
@@ -40,6 +41,14 @@ func TestMain(m *testing.M) {
 	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
 	defer cancel()
 
+	migrator, err := database.NewMigrator(testFlowRun.DB, nil)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to create migrator")
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		logger.WithError(err).Fatal("Failed to migrate database")
+	}
+
 	if err := testFlowRun.Start(ctx); err != nil {
 		logger.WithError(err).Fatal("Failed to start FlowRun instance")
 	}
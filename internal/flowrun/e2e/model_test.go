@@ -0,0 +1,126 @@
+package e2e
+
+import (
+	"context"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelCRUD(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	provider := createTestProvider(ctx, t, accountID)
+
+	created, err := testClient.CreateModel(ctx, model.CreateModelRequest{
+		Name:       "openai/gpt-4o-mini",
+		AccountID:  accountID,
+		ProviderID: provider.ID,
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, created.ID)
+	assert.Equal(t, provider.ID, created.ProviderID)
+
+	fetched, err := testClient.GetModel(ctx, accountID, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created, fetched)
+
+	assert.Equal(t, "0", created.InputPrice)
+
+	_, err = testClient.UpdateModel(ctx, accountID, created.ID, model.UpdateModelRequest{
+		Name:       "openai/gpt-4o",
+		ProviderID: provider.ID,
+		InputPrice: "cheap",
+	})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	updated, err := testClient.UpdateModel(ctx, accountID, created.ID, model.UpdateModelRequest{
+		Name:        "openai/gpt-4o",
+		ProviderID:  provider.ID,
+		InputPrice:  "2.50",
+		OutputPrice: "10",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "openai/gpt-4o", updated.Name)
+	assert.Equal(t, "2.5", updated.InputPrice)
+	assert.Equal(t, "10", updated.OutputPrice)
+
+	list, err := testClient.ListModels(ctx, accountID)
+	require.NoError(t, err)
+	require.Len(t, list.Models, 1)
+	assert.Equal(t, *updated, list.Models[0])
+
+	err = testClient.DeleteProvider(ctx, accountID, provider.ID)
+	assertStatus(t, err, http.StatusConflict)
+
+	require.NoError(t, testClient.DeleteModel(ctx, accountID, created.ID))
+
+	_, err = testClient.GetModel(ctx, accountID, created.ID)
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func TestCreateModelIfProviderOwnedByOtherAccount(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	provider := createTestProvider(ctx, t, uuid.New())
+
+	_, err := testClient.CreateModel(ctx, model.CreateModelRequest{
+		Name:       "foreign",
+		AccountID:  uuid.New(),
+		ProviderID: provider.ID,
+	})
+
+	var errResp *model.ErrorResponse
+	require.ErrorAs(t, err, &errResp)
+	assert.Equal(t, http.StatusBadRequest, errResp.StatusCode)
+	require.Len(t, errResp.Fields, 1)
+	assert.Equal(t, "provider_id", errResp.Fields[0].Field)
+}
+
+func TestCreateModelIfDuplicateName(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	provider := createTestProvider(ctx, t, accountID)
+	req := model.CreateModelRequest{
+		Name:       "duplicate",
+		AccountID:  accountID,
+		ProviderID: provider.ID,
+	}
+
+	_, err := testClient.CreateModel(ctx, req)
+	require.NoError(t, err)
+
+	_, err = testClient.CreateModel(ctx, req)
+	assertStatus(t, err, http.StatusConflict)
+}
+
+func createTestProvider(ctx context.Context, t *testing.T, accountID uuid.UUID) *model.ProviderResponse {
+	t.Helper()
+
+	provider, err := testClient.CreateProvider(ctx, model.CreateProviderRequest{
+		Name:      "provider",
+		AccountID: accountID,
+		Type:      "open_router",
+		ApiKey:    faker.Password(),
+	})
+	require.NoError(t, err)
+
+	return provider
+}
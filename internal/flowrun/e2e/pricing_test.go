@@ -0,0 +1,176 @@
+package e2e
+
+import (
+	"context"
+	"flow-run/internal/flowrun/infra/database"
+	"flow-run/internal/lib/envelope"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOpenRouterModels = `{"data": [
+	{"id": "openai/gpt-4o", "context_length": 128000,
+	 "pricing": {"prompt": "0.0000025", "completion": "0.00001", "input_cache_read": "0.00000125"}},
+	{"id": "openrouter/auto", "context_length": 2000000, "pricing": {"prompt": "-1", "completion": "-1"}}
+]}`
+
+func TestPricingCatalog(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	openRouter := newTestOpenRouter(t)
+	accountID := uuid.New()
+	provider, created := createOpenRouterModel(ctx, t, accountID, openRouter.URL)
+
+	keyring, err := envelope.NewKeyring(testFlowRun.Config.KeyringConfig)
+	require.NoError(t, err)
+	stored, err := database.NewProviderRepository(testFlowRun.DB, keyring).Get(ctx, accountID, provider.ID)
+	require.NoError(t, err)
+	result, err := testFlowRun.Prices.SyncProvider(ctx, stored)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Repriced)
+
+	synced, err := testClient.GetModel(ctx, accountID, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "2.5", synced.InputPrice)
+	assert.Equal(t, "10", synced.OutputPrice)
+	assert.Equal(t, "1.25", synced.CachedPrice)
+	assert.Equal(t, 128000, synced.ContextWindow)
+
+	// A manual price overrides the synced one once in effect; one scheduled
+	// for later does not yet.
+	_, err = testClient.AddModelPrice(ctx, accountID, created.ID, model.AddModelPriceRequest{
+		InputPrice:  "2",
+		OutputPrice: "8",
+	})
+	require.NoError(t, err)
+	later := time.Now().Add(time.Hour).UTC()
+	_, err = testClient.AddModelPrice(ctx, accountID, created.ID, model.AddModelPriceRequest{
+		InputPrice:  "1",
+		OutputPrice: "4",
+		EffectiveAt: &later,
+	})
+	require.NoError(t, err)
+
+	overridden, err := testClient.GetModel(ctx, accountID, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "2", overridden.InputPrice)
+	assert.Equal(t, "0", overridden.CachedPrice)
+
+	// Resyncing unchanged prices adds nothing and keeps the override.
+	result, err = testFlowRun.Prices.SyncProvider(ctx, stored)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Repriced)
+
+	prices, err := testClient.ListModelPrices(ctx, accountID, created.ID)
+	require.NoError(t, err)
+	require.Len(t, prices.Prices, 3)
+	assert.Equal(t, "open_router", prices.Prices[0].Source)
+	assert.Equal(t, "manual", prices.Prices[1].Source)
+	assert.Equal(t, "1", prices.Prices[2].InputPrice)
+	assert.True(t, later.Equal(prices.Prices[2].EffectiveAt))
+
+	_, err = testClient.ListModelPrices(ctx, uuid.New(), created.ID)
+	require.Error(t, err)
+}
+
+func TestUpdateModelKeepsSyncedPrices(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	openRouter := newTestOpenRouter(t)
+	accountID := uuid.New()
+	provider, created := createOpenRouterModel(ctx, t, accountID, openRouter.URL)
+	keyring, err := envelope.NewKeyring(testFlowRun.Config.KeyringConfig)
+	require.NoError(t, err)
+	stored, err := database.NewProviderRepository(testFlowRun.DB, keyring).Get(ctx, accountID, provider.ID)
+	require.NoError(t, err)
+	_, err = testFlowRun.Prices.SyncProvider(ctx, stored)
+	require.NoError(t, err)
+
+	// Renaming the model leaves out its prices, which keeps those synced.
+	renamed, err := testClient.UpdateModel(ctx, accountID, created.ID, model.UpdateModelRequest{
+		Name:       "gpt-4o",
+		ProviderID: provider.ID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "2.5", renamed.InputPrice)
+	fetched, err := testClient.GetModel(ctx, accountID, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-4o", fetched.Name)
+	assert.Equal(t, "2.5", fetched.InputPrice)
+	assert.Equal(t, "10", fetched.OutputPrice)
+	assert.Equal(t, "1.25", fetched.CachedPrice)
+	prices, err := testClient.ListModelPrices(ctx, accountID, created.ID)
+	require.NoError(t, err)
+	require.Len(t, prices.Prices, 1)
+
+	// A price that is sent overrides only itself.
+	_, err = testClient.UpdateModel(ctx, accountID, created.ID, model.UpdateModelRequest{
+		Name:        "gpt-4o",
+		ProviderID:  provider.ID,
+		OutputPrice: "9",
+	})
+	require.NoError(t, err)
+	fetched, err = testClient.GetModel(ctx, accountID, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "2.5", fetched.InputPrice)
+	assert.Equal(t, "9", fetched.OutputPrice)
+	assert.Equal(t, "1.25", fetched.CachedPrice)
+	prices, err = testClient.ListModelPrices(ctx, accountID, created.ID)
+	require.NoError(t, err)
+	require.Len(t, prices.Prices, 2)
+	assert.Equal(t, "manual", prices.Prices[1].Source)
+}
+
+// newTestOpenRouter serves testOpenRouterModels as OpenRouter's models list
+// until the test ends.
+func newTestOpenRouter(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	openRouter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(testOpenRouterModels))
+	}))
+	t.Cleanup(openRouter.Close)
+	return openRouter
+}
+
+// createOpenRouterModel creates an OpenRouter provider at baseURL with an
+// unpriced openai/gpt-4o model.
+func createOpenRouterModel(
+	ctx context.Context, t *testing.T, accountID uuid.UUID, baseURL string,
+) (*model.ProviderResponse, *model.ModelResponse) {
+	t.Helper()
+
+	provider, err := testClient.CreateProvider(ctx, model.CreateProviderRequest{
+		Name:      "openrouter",
+		AccountID: accountID,
+		Type:      "open_router",
+		ApiKey:    "sk-or-test",
+		BaseURL:   baseURL,
+	})
+	require.NoError(t, err)
+	created, err := testClient.CreateModel(ctx, model.CreateModelRequest{
+		Name:       "openai/gpt-4o",
+		AccountID:  accountID,
+		ProviderID: provider.ID,
+	})
+	require.NoError(t, err)
+	return provider, created
+}
@@ -0,0 +1,181 @@
+package e2e
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/database"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptRevisions(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	created, err := testClient.CreatePrompt(ctx, model.CreatePromptRequest{
+		AccountID: accountID,
+		Name:      "summary",
+		Author:    "alice",
+		Template:  testPromptTemplate,
+	})
+	require.NoError(t, err)
+
+	// Metadata-only edits keep the revision and its author.
+	updated, err := testClient.UpdatePrompt(ctx, accountID, created.ID, model.UpdatePromptRequest{
+		Name:        "summary",
+		Description: "Bullet summary",
+		Author:      "bob",
+		Template:    testPromptTemplate,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated.Revision)
+	assert.Equal(t, "alice", updated.Author)
+
+	changed := testPromptTemplate
+	changed.User = "Summarize in one line:{{ range .points }}\n- {{ . }}{{ end }}"
+	updated, err = testClient.UpdatePrompt(ctx, accountID, created.ID, model.UpdatePromptRequest{
+		Name:     "summary",
+		Author:   "bob",
+		Template: changed,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.Revision)
+	assert.Equal(t, "bob", updated.Author)
+
+	revisions, err := testClient.ListPromptRevisions(ctx, accountID, created.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions.Revisions, 2)
+	first, second := revisions.Revisions[0], revisions.Revisions[1]
+	assert.Equal(t, 1, first.Revision)
+	assert.Equal(t, "alice", first.Author)
+	assert.Equal(t, testPromptTemplate, first.Template)
+	assert.Len(t, first.ContentHash, 64)
+	assert.Equal(t, changed, second.Template)
+	assert.NotEqual(t, first.ContentHash, second.ContentHash)
+
+	fetched, err := testClient.GetPromptRevision(ctx, accountID, created.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, first, *fetched)
+
+	_, err = testClient.GetPromptRevision(ctx, accountID, created.ID, 3)
+	assertStatus(t, err, http.StatusNotFound)
+
+	d, err := testClient.DiffPromptRevisions(ctx, accountID, created.ID, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []model.DiffLine{
+		{Op: "delete", Text: "Summarize:{{ range .points }}", OldLine: 1},
+		{Op: "insert", Text: "Summarize in one line:{{ range .points }}", NewLine: 1},
+		{Op: "equal", Text: "- {{ . }}{{ end }}", OldLine: 2, NewLine: 2},
+	}, d.User)
+	for _, line := range append(d.System, d.Variables...) {
+		assert.Equal(t, "equal", line.Op)
+	}
+
+	_, err = testClient.DiffPromptRevisions(ctx, accountID, created.ID, 0, 2)
+	assertStatus(t, err, http.StatusBadRequest)
+}
+
+func TestRunFlowFollowsPromptLabel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	provider, err := testClient.CreateProvider(ctx, model.CreateProviderRequest{
+		Name:      "mock",
+		AccountID: accountID,
+		Type:      "mock",
+		ApiKey:    `{"rules": [{"regex": ".", "response": "done"}]}`,
+	})
+	require.NoError(t, err)
+	_, err = testClient.CreateModel(ctx, model.CreateModelRequest{
+		Name:       "summarizer",
+		AccountID:  accountID,
+		ProviderID: provider.ID,
+	})
+	require.NoError(t, err)
+	prompt, err := testClient.CreatePrompt(ctx, model.CreatePromptRequest{
+		AccountID: accountID,
+		Name:      "summary",
+		Author:    "alice",
+		Template:  testPromptTemplate,
+	})
+	require.NoError(t, err)
+
+	source := `
+name: bullets
+inputs:
+  - {name: points, type: array, required: true}
+steps:
+  - id: labelled
+    type: prompt
+    inputs: {points: inputs.points}
+    prompt: {model: summarizer, prompt: summary, label: production}
+  - id: pinned
+    type: prompt
+    inputs: {points: inputs.points}
+    prompt: {model: summarizer, prompt: summary, revision: 1}
+`
+	req := model.CreateFlowRequest{AccountID: accountID, Format: "yaml", Source: source}
+	_, err = testClient.CreateFlow(ctx, req)
+	var errResp *model.ErrorResponse
+	require.ErrorAs(t, err, &errResp)
+	require.Len(t, errResp.Fields, 1)
+	assert.Equal(t, "steps[0].prompt.label", errResp.Fields[0].Field)
+
+	setLabel := func(revision int) (*model.PromptLabelResponse, error) {
+		return testClient.SetPromptLabel(ctx, accountID, prompt.ID, "production", model.SetPromptLabelRequest{
+			Revision: revision,
+		})
+	}
+	_, err = setLabel(2)
+	assertStatus(t, err, http.StatusBadRequest)
+	label, err := setLabel(1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, label.Revision)
+
+	created, err := testClient.CreateFlow(ctx, req)
+	require.NoError(t, err)
+
+	changed := testPromptTemplate
+	changed.System = "Be terse."
+	_, err = testClient.UpdatePrompt(ctx, accountID, prompt.ID, model.UpdatePromptRequest{
+		Name:     "summary",
+		Author:   "bob",
+		Template: changed,
+	})
+	require.NoError(t, err)
+	_, err = setLabel(2)
+	require.NoError(t, err)
+
+	labels, err := testClient.ListPromptLabels(ctx, accountID, prompt.ID)
+	require.NoError(t, err)
+	require.Len(t, labels.Labels, 1)
+	assert.Equal(t, 2, labels.Labels[0].Revision)
+
+	revisions, err := testClient.ListPromptRevisions(ctx, accountID, prompt.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions.Revisions, 2)
+
+	flow, err := database.NewFlowRepository(testFlowRun.DB).Get(ctx, accountID, created.ID)
+	require.NoError(t, err)
+	run, err := testFlowRun.Engine.Run(ctx, flow, map[string]any{"points": []any{"a"}})
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStatusSucceeded, run.Status, run.Error)
+
+	steps, err := database.NewRunRepository(testFlowRun.DB).ListStepRuns(ctx, run.ID)
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	assert.Equal(t, &revisions.Revisions[1].ID, steps[0].PromptRevisionID)
+	assert.Equal(t, &revisions.Revisions[0].ID, steps[1].PromptRevisionID)
+}
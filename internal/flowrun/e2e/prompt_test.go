@@ -0,0 +1,185 @@
+package e2e
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/database"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPromptTemplate = model.PromptTemplate{
+	System: "{{ if .formal }}Be formal.{{ else }}Be brief.{{ end }}",
+	User:   "Summarize:{{ range .points }}\n- {{ . }}{{ end }}",
+	Variables: []model.PromptVariable{
+		{Name: "points", Type: "array", Required: true},
+		{Name: "formal", Type: "boolean", Default: false},
+	},
+}
+
+func TestPromptCRUD(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	created, err := testClient.CreatePrompt(ctx, model.CreatePromptRequest{
+		AccountID: accountID,
+		Name:      "summary",
+		Author:    "alice",
+		Template:  testPromptTemplate,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "summary", created.Name)
+	assert.Equal(t, 1, created.Revision)
+	assert.Equal(t, "alice", created.Author)
+	assert.Equal(t, testPromptTemplate, created.Template)
+
+	fetched, err := testClient.GetPrompt(ctx, accountID, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created, fetched)
+
+	updated, err := testClient.UpdatePrompt(ctx, accountID, created.ID, model.UpdatePromptRequest{
+		Name:        "summary_v2",
+		Description: "Bullet summary",
+		Author:      "bob",
+		Template: model.PromptTemplate{
+			User:      "Summarize {{ .text }}",
+			Variables: []model.PromptVariable{{Name: "text", Type: "string"}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "summary_v2", updated.Name)
+	assert.Equal(t, "Bullet summary", updated.Description)
+	assert.Equal(t, 2, updated.Revision)
+	assert.Equal(t, "bob", updated.Author)
+
+	list, err := testClient.ListPrompts(ctx, accountID)
+	require.NoError(t, err)
+	require.Len(t, list.Prompts, 1)
+	assert.Equal(t, *updated, list.Prompts[0])
+
+	require.NoError(t, testClient.DeletePrompt(ctx, accountID, created.ID))
+
+	_, err = testClient.GetPrompt(ctx, accountID, created.ID)
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func TestCreatePromptIfUndeclaredVariable(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := testClient.CreatePrompt(ctx, model.CreatePromptRequest{
+		AccountID: uuid.New(),
+		Name:      "summary",
+		Author:    "alice",
+		Template: model.PromptTemplate{
+			User:      "{{ .text }} in {{ .language }}",
+			Variables: []model.PromptVariable{{Name: "text", Type: "string"}},
+		},
+	})
+
+	var errResp *model.ErrorResponse
+	require.ErrorAs(t, err, &errResp)
+	assert.Equal(t, http.StatusBadRequest, errResp.StatusCode)
+	require.Len(t, errResp.Fields, 1)
+	assert.Equal(t, "template.user", errResp.Fields[0].Field)
+	assert.Equal(t, "declared", errResp.Fields[0].Tag)
+}
+
+func TestCreateFlowIfStoredPromptNotBound(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	createTestModel(ctx, t, accountID, "summarizer")
+
+	source := "name: x\nsteps:\n  - {id: a, type: prompt, prompt: {model: summarizer, prompt: summary}}\n"
+	req := model.CreateFlowRequest{AccountID: accountID, Format: "yaml", Source: source}
+
+	_, err := testClient.CreateFlow(ctx, req)
+	var errResp *model.ErrorResponse
+	require.ErrorAs(t, err, &errResp)
+	require.Len(t, errResp.Fields, 1)
+	assert.Equal(t, "steps[0].prompt.prompt", errResp.Fields[0].Field)
+
+	_, err = testClient.CreatePrompt(ctx, model.CreatePromptRequest{
+		AccountID: accountID,
+		Name:      "summary",
+		Author:    "alice",
+		Template:  testPromptTemplate,
+	})
+	require.NoError(t, err)
+
+	_, err = testClient.CreateFlow(ctx, req)
+	require.ErrorAs(t, err, &errResp)
+	require.Len(t, errResp.Fields, 1)
+	assert.Equal(t, "steps[0].inputs", errResp.Fields[0].Field)
+	assert.Equal(t, "required", errResp.Fields[0].Tag)
+}
+
+func TestRunFlowWithStoredPrompt(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	provider, err := testClient.CreateProvider(ctx, model.CreateProviderRequest{
+		Name:      "mock",
+		AccountID: accountID,
+		Type:      "mock",
+		ApiKey:    `{"rules": [{"regex": "(?s)Be brief\\..*- a\\n- b", "response": "done"}]}`,
+	})
+	require.NoError(t, err)
+	_, err = testClient.CreateModel(ctx, model.CreateModelRequest{
+		Name:       "summarizer",
+		AccountID:  accountID,
+		ProviderID: provider.ID,
+	})
+	require.NoError(t, err)
+	_, err = testClient.CreatePrompt(ctx, model.CreatePromptRequest{
+		AccountID: accountID,
+		Name:      "summary",
+		Author:    "alice",
+		Template:  testPromptTemplate,
+	})
+	require.NoError(t, err)
+
+	created, err := testClient.CreateFlow(ctx, model.CreateFlowRequest{
+		AccountID: accountID,
+		Format:    "yaml",
+		Source: `
+name: bullets
+inputs:
+  - {name: points, type: array, required: true}
+outputs:
+  - {name: summary, type: string, from: steps.summarize.output}
+steps:
+  - id: summarize
+    type: prompt
+    inputs: {points: inputs.points}
+    prompt: {model: summarizer, prompt: summary}
+`,
+	})
+	require.NoError(t, err)
+
+	flow, err := database.NewFlowRepository(testFlowRun.DB).Get(ctx, accountID, created.ID)
+	require.NoError(t, err)
+
+	run, err := testFlowRun.Engine.Run(ctx, flow, map[string]any{"points": []any{"a", "b"}})
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStatusSucceeded, run.Status, run.Error)
+	assert.Equal(t, map[string]any{"summary": "done"}, run.Outputs)
+}
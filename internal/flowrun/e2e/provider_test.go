@@ -0,0 +1,211 @@
+package e2e
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/flowrun/infra/database"
+	"flow-run/internal/lib/envelope"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderCRUD(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+
+	created, err := testClient.CreateProvider(ctx, model.CreateProviderRequest{
+		Name:      "openrouter",
+		AccountID: accountID,
+		Type:      "open_router",
+		ApiKey:    faker.Password(),
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, created.ID)
+	assert.Equal(t, "openrouter", created.Name)
+	assert.Equal(t, accountID, created.AccountID)
+
+	fetched, err := testClient.GetProvider(ctx, accountID, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created, fetched)
+
+	updated, err := testClient.UpdateProvider(ctx, accountID, created.ID, model.UpdateProviderRequest{
+		Name: "renamed",
+		Type: "open_router",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", updated.Name)
+
+	list, err := testClient.ListProviders(ctx, accountID)
+	require.NoError(t, err)
+	require.Len(t, list.Providers, 1)
+	assert.Equal(t, *updated, list.Providers[0])
+
+	require.NoError(t, testClient.DeleteProvider(ctx, accountID, created.ID))
+
+	_, err = testClient.GetProvider(ctx, accountID, created.ID)
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func TestProviderScopedByAccount(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	created, err := testClient.CreateProvider(ctx, model.CreateProviderRequest{
+		Name:      "scoped",
+		AccountID: uuid.New(),
+		Type:      "open_router",
+		ApiKey:    faker.Password(),
+	})
+	require.NoError(t, err)
+
+	otherAccount := uuid.New()
+
+	_, err = testClient.GetProvider(ctx, otherAccount, created.ID)
+	assertStatus(t, err, http.StatusNotFound)
+
+	err = testClient.DeleteProvider(ctx, otherAccount, created.ID)
+	assertStatus(t, err, http.StatusNotFound)
+
+	list, err := testClient.ListProviders(ctx, otherAccount)
+	require.NoError(t, err)
+	assert.Empty(t, list.Providers)
+}
+
+func TestCreateProviderIfInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := testClient.CreateProvider(ctx, model.CreateProviderRequest{
+		Name:      "not valid!",
+		AccountID: uuid.New(),
+		Type:      "unknown",
+	})
+
+	var errResp *model.ErrorResponse
+	require.ErrorAs(t, err, &errResp)
+	assert.Equal(t, http.StatusBadRequest, errResp.StatusCode)
+
+	fields := make([]string, 0, len(errResp.Fields))
+	for _, f := range errResp.Fields {
+		fields = append(fields, f.Field)
+	}
+	assert.ElementsMatch(t, []string{"name", "type"}, fields)
+}
+
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+
+	var errResp *model.ErrorResponse
+	if !errors.As(err, &errResp) {
+		t.Fatalf("Expected error response with status %d, got %v", status, err)
+	}
+	assert.Equal(t, status, errResp.StatusCode)
+}
+
+func TestCreateSelfHostedProvider(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	created, err := testClient.CreateProvider(ctx, model.CreateProviderRequest{
+		Name:      "vllm",
+		AccountID: uuid.New(),
+		Type:      "openai_compatible",
+		BaseURL:   "http://vllm:8000/v1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "http://vllm:8000/v1", created.BaseURL)
+
+	_, err = testClient.CreateProvider(ctx, model.CreateProviderRequest{
+		Name:      "vllm",
+		AccountID: uuid.New(),
+		Type:      "openai_compatible",
+	})
+	assertStatus(t, err, http.StatusBadRequest)
+}
+
+func TestCreateMockProvider(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	created, err := testClient.CreateProvider(ctx, model.CreateProviderRequest{
+		Name:      "mock",
+		AccountID: uuid.New(),
+		Type:      "mock",
+		ApiKey:    `{"rules": [{"regex": "hello", "response": "hi"}]}`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "mock", created.Type)
+
+	_, err = testClient.CreateProvider(ctx, model.CreateProviderRequest{
+		Name:      "mock",
+		AccountID: uuid.New(),
+		Type:      "mock",
+		ApiKey:    `{"rules": [{"regex": "(", "response": "hi"}]}`,
+	})
+
+	var errResp *model.ErrorResponse
+	require.ErrorAs(t, err, &errResp)
+	assert.Equal(t, http.StatusBadRequest, errResp.StatusCode)
+	require.Len(t, errResp.Fields, 1)
+	assert.Equal(t, "api_key", errResp.Fields[0].Field)
+	assert.Equal(t, "mock_script", errResp.Fields[0].Tag)
+}
+
+func TestProviderApiKeyEncryptedAtRest(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	apiKey := "sk-" + faker.Password()
+	accountID := uuid.New()
+
+	created, err := testClient.CreateProvider(ctx, model.CreateProviderRequest{
+		Name:      "openai",
+		AccountID: accountID,
+		Type:      "openai",
+		ApiKey:    apiKey,
+	})
+	require.NoError(t, err)
+
+	keyring, err := envelope.NewKeyring(testFlowRun.Config.KeyringConfig)
+	require.NoError(t, err)
+	stored, err := database.NewProviderRepository(testFlowRun.DB, keyring).Get(ctx, accountID, created.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stored.ApiKey)
+	require.NotNil(t, stored.EncryptedApiKey)
+	assert.Positive(t, stored.EncryptedApiKey.KeyVersion)
+	assert.NotEmpty(t, stored.EncryptedApiKey.Ciphertext)
+	assert.NotContains(t, string(stored.EncryptedApiKey.Ciphertext), apiKey)
+
+	_, err = testClient.UpdateProvider(ctx, accountID, created.ID, model.UpdateProviderRequest{
+		Name: "renamed",
+		Type: "openai",
+	})
+	require.NoError(t, err)
+
+	_, err = testClient.UpdateProvider(ctx, accountID, created.ID, model.UpdateProviderRequest{
+		Name: "renamed",
+		Type: "anthropic",
+	})
+	assertStatus(t, err, http.StatusBadRequest)
+}
@@ -0,0 +1,108 @@
+package e2e
+
+import (
+	"context"
+	"errors"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegressionReport(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	createTestSuitePrompt(ctx, t, accountID)
+	prompts, err := testClient.ListPrompts(ctx, accountID)
+	require.NoError(t, err)
+	prompt := prompts.Prompts[0]
+
+	// Revision 2 mentions Paris, so the mock answers every case with JSON
+	// about France.
+	template := prompt.Template
+	template.User = "Describe {{ .city }} as JSON, like Paris."
+	_, err = testClient.UpdatePrompt(ctx, accountID, prompt.ID, model.UpdatePromptRequest{
+		Name:     prompt.Name,
+		Author:   "bob",
+		Template: template,
+	})
+	require.NoError(t, err)
+
+	suite, err := testClient.CreateTestSuite(ctx, model.CreateTestSuiteRequest{
+		AccountID: accountID,
+		Name:      "capitals",
+		Prompt:    "describe",
+		Cases: []model.TestCase{
+			{
+				Name:       "paris",
+				Inputs:     map[string]any{"city": "Paris"},
+				Assertions: []model.Assertion{{Type: "contains", Value: "France"}},
+			},
+			{
+				Name:       "lyon",
+				Inputs:     map[string]any{"city": "Lyon"},
+				Assertions: []model.Assertion{{Type: "contains", Value: "France"}},
+			},
+			{
+				Name:       "oslo",
+				Inputs:     map[string]any{"city": "Oslo"},
+				Assertions: []model.Assertion{{Type: "contains", Value: "know"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	runs, err := testClient.RunRegression(ctx, accountID, suite.ID, model.RunRegressionRequest{
+		Baseline:  model.RunTestSuiteRequest{Model: "priced", Revision: 1},
+		Candidate: model.RunTestSuiteRequest{Model: "unpriced", Revision: 2},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "pending", runs.Baseline.Status)
+	assert.Equal(t, 2, runs.Candidate.PromptRevision)
+
+	// Runs that have not finished yet cannot be compared.
+	_, err = testClient.GetRegressionReport(ctx, accountID, suite.ID, runs.Baseline.ID, runs.Candidate.ID)
+	assertStatus(t, err, http.StatusBadRequest)
+
+	for _, run := range []model.TestSuiteRunResponse{runs.Baseline, runs.Candidate} {
+		finished, err := testClient.WaitTestSuiteRun(ctx, accountID, suite.ID, run.ID)
+		require.NoError(t, err)
+		require.Equal(t, "succeeded", finished.Status)
+	}
+	report, err := testClient.GetRegressionReport(ctx, accountID, suite.ID, runs.Baseline.ID, runs.Candidate.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Baseline.PromptRevision)
+	assert.Equal(t, 2, report.Candidate.PromptRevision)
+	assert.Equal(t, 2, report.Baseline.Passed)
+	assert.Equal(t, 2, report.Candidate.Passed)
+	assert.Equal(t, 0, report.Delta.Passed)
+	assert.Equal(t, "-0.00135", report.Delta.Cost)
+	require.Len(t, report.Cases, 3)
+	changes := []string{report.Cases[0].Change, report.Cases[1].Change, report.Cases[2].Change}
+	assert.Equal(t, []string{"passing", "fixed", "regressed"}, changes)
+	require.Len(t, report.Flipped, 2)
+	assert.Equal(t, "oslo", report.Flipped[1].Name)
+	assert.Equal(t, []string{`output does not contain "know"`}, report.Flipped[1].Candidate.Failures)
+
+	_, err = testClient.GetRegressionReport(ctx, accountID, suite.ID, report.Baseline.RunID, uuid.New())
+	var errResp *model.ErrorResponse
+	require.True(t, errors.As(err, &errResp), err)
+	assert.Equal(t, http.StatusBadRequest, errResp.StatusCode)
+	assert.Equal(t, "candidate", errResp.Fields[0].Field)
+
+	_, err = testClient.RunRegression(ctx, accountID, suite.ID, model.RunRegressionRequest{
+		Baseline:  model.RunTestSuiteRequest{Model: "priced"},
+		Candidate: model.RunTestSuiteRequest{Model: "priced", Revision: 3},
+	})
+	require.True(t, errors.As(err, &errResp), err)
+	assert.Equal(t, http.StatusBadRequest, errResp.StatusCode)
+	assert.Equal(t, "candidate.revision", errResp.Fields[0].Field)
+}
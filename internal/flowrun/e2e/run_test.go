@@ -0,0 +1,218 @@
+package e2e
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/database"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunFlowWithMockProvider(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	provider, err := testClient.CreateProvider(ctx, model.CreateProviderRequest{
+		Name:      "mock",
+		AccountID: accountID,
+		Type:      "mock",
+		ApiKey: `{"rules": [{"regex": "Summarize", "response": "short",` +
+			` "usage": {"prompt_tokens": 12, "completion_tokens": 3}}]}`,
+	})
+	require.NoError(t, err)
+	_, err = testClient.CreateModel(ctx, model.CreateModelRequest{
+		Name:       "summarizer",
+		AccountID:  accountID,
+		ProviderID: provider.ID,
+	})
+	require.NoError(t, err)
+	created, err := testClient.CreateFlow(ctx, model.CreateFlowRequest{
+		AccountID: accountID,
+		Format:    "yaml",
+		Source:    testSummarizeFlow,
+	})
+	require.NoError(t, err)
+
+	flow, err := database.NewFlowRepository(testFlowRun.DB).Get(ctx, accountID, created.ID)
+	require.NoError(t, err)
+
+	run, err := testFlowRun.Engine.Run(ctx, flow, map[string]any{"text": "a long text"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStatusSucceeded, run.Status)
+
+	runRepo := database.NewRunRepository(testFlowRun.DB)
+	stored, err := runRepo.GetRun(ctx, accountID, run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStatusSucceeded, stored.Status)
+	assert.Equal(t, map[string]any{"text": "a long text"}, stored.Inputs)
+	assert.Equal(t, map[string]any{"summary": "short"}, stored.Outputs)
+	assert.Equal(t, 15, stored.Usage.TotalTokens)
+	assert.NotNil(t, stored.FinishedAt)
+
+	steps, err := runRepo.ListStepRuns(ctx, run.ID)
+	require.NoError(t, err)
+	require.Len(t, steps, 1)
+	assert.Equal(t, "summarize", steps[0].StepID)
+	assert.Equal(t, domain.RunStatusSucceeded, steps[0].Status)
+	assert.Equal(t, map[string]any{"text": "a long text"}, steps[0].Inputs)
+	assert.Equal(t, "short", steps[0].Output)
+	assert.Equal(t, 12, steps[0].Usage.PromptTokens)
+}
+
+func TestSubmitRunExecutesOnWorker(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	provider, err := testClient.CreateProvider(ctx, model.CreateProviderRequest{
+		Name:      "mock",
+		AccountID: accountID,
+		Type:      "mock",
+		ApiKey:    `{"rules": [{"regex": "Summarize", "response": "short"}]}`,
+	})
+	require.NoError(t, err)
+	_, err = testClient.CreateModel(ctx, model.CreateModelRequest{
+		Name:       "summarizer",
+		AccountID:  accountID,
+		ProviderID: provider.ID,
+	})
+	require.NoError(t, err)
+	created, err := testClient.CreateFlow(ctx, model.CreateFlowRequest{
+		AccountID: accountID,
+		Format:    "yaml",
+		Source:    testSummarizeFlow,
+	})
+	require.NoError(t, err)
+
+	flow, err := database.NewFlowRepository(testFlowRun.DB).Get(ctx, accountID, created.ID)
+	require.NoError(t, err)
+	run, err := testFlowRun.Engine.Submit(ctx, flow, map[string]any{"text": "a long text"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStatusPending, run.Status)
+
+	runRepo := database.NewRunRepository(testFlowRun.DB)
+	require.Eventually(t, func() bool {
+		stored, err := runRepo.GetRun(ctx, accountID, run.ID)
+		return err == nil && stored.Status.Finished()
+	}, 10*time.Second, 50*time.Millisecond)
+
+	stored, err := runRepo.GetRun(ctx, accountID, run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStatusSucceeded, stored.Status)
+	assert.Equal(t, map[string]any{"summary": "short"}, stored.Outputs)
+}
+
+func TestStartRunOverAPI(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	flowID := createMockFlow(ctx, t, accountID, `{"rules": [{"regex": "Summarize", "response": "short",`+
+		` "usage": {"prompt_tokens": 12, "completion_tokens": 3}}]}`)
+
+	started, err := testClient.StartRun(ctx, accountID, flowID, model.RunFlowRequest{
+		Inputs: map[string]any{"text": "a long text"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "pending", started.Status)
+	assert.Equal(t, flowID, started.FlowID)
+
+	run, err := testClient.WaitRun(ctx, accountID, started.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "succeeded", run.Status)
+	assert.Equal(t, map[string]any{"summary": "short"}, run.Outputs)
+	assert.Equal(t, 15, run.Usage.TotalTokens)
+	assert.NotEmpty(t, run.Cost)
+	require.Len(t, run.Steps, 1)
+	assert.Equal(t, "summarize", run.Steps[0].StepID)
+	assert.Equal(t, "succeeded", run.Steps[0].Status)
+	assert.Equal(t, "short", run.Steps[0].Output)
+
+	_, err = testClient.CancelRun(ctx, accountID, run.ID)
+	assertStatus(t, err, http.StatusConflict)
+
+	_, err = testClient.StartRun(ctx, accountID, flowID, model.RunFlowRequest{})
+	assertStatus(t, err, http.StatusBadRequest)
+	_, err = testClient.StartRun(ctx, accountID, flowID, model.RunFlowRequest{
+		Inputs: map[string]any{"text": "x"},
+		Model:  "missing",
+	})
+	assertStatus(t, err, http.StatusBadRequest)
+	_, err = testClient.GetRun(ctx, uuid.New(), run.ID)
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func TestCancelRunOverAPI(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	// The call would take a minute unless canceling the run cuts it short.
+	flowID := createMockFlow(ctx, t, accountID, `{"rules": [{"regex": "Summarize", "response": "short",`+
+		` "latency": "1m"}]}`)
+
+	started, err := testClient.StartRun(ctx, accountID, flowID, model.RunFlowRequest{
+		Inputs: map[string]any{"text": "a long text"},
+	})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		run, err := testClient.GetRun(ctx, accountID, started.ID)
+		return err == nil && run.Steps[0].Status == "running"
+	}, 10*time.Second, 50*time.Millisecond)
+
+	requested, err := testClient.CancelRun(ctx, accountID, started.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, requested.CancelRequestedAt)
+
+	run, err := testClient.WaitRun(ctx, accountID, started.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "canceled", run.Status)
+	require.Len(t, run.Steps, 1)
+	assert.Equal(t, "canceled", run.Steps[0].Status)
+
+	again, err := testClient.CancelRun(ctx, accountID, started.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "canceled", again.Status)
+}
+
+// createMockFlow stores testSummarizeFlow with its model on a mock provider
+// scripted by script.
+func createMockFlow(ctx context.Context, t *testing.T, accountID uuid.UUID, script string) uuid.UUID {
+	t.Helper()
+
+	provider, err := testClient.CreateProvider(ctx, model.CreateProviderRequest{
+		Name:      "mock",
+		AccountID: accountID,
+		Type:      "mock",
+		ApiKey:    script,
+	})
+	require.NoError(t, err)
+	_, err = testClient.CreateModel(ctx, model.CreateModelRequest{
+		Name:       "summarizer",
+		AccountID:  accountID,
+		ProviderID: provider.ID,
+	})
+	require.NoError(t, err)
+	flow, err := testClient.CreateFlow(ctx, model.CreateFlowRequest{
+		AccountID: accountID,
+		Format:    "yaml",
+		Source:    testSummarizeFlow,
+	})
+	require.NoError(t, err)
+	return flow.ID
+}
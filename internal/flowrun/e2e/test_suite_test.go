@@ -0,0 +1,356 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSuiteMockScript answers JSON for prompts mentioning Paris and refuses
+// anything about Rome. Every call uses 1000 prompt and 500 completion tokens.
+const testSuiteMockScript = `{
+	"usage": {"prompt_tokens": 1000, "completion_tokens": 500},
+	"rules": [
+		{"regex": "Paris", "response": "{\"city\": \"Paris\", \"country\": \"France\"}"},
+		{"regex": "Rome", "error": {"kind": "unavailable", "message": "down"}},
+		{"regex": ".", "response": "I do not know."}
+	]
+}`
+
+func TestTestSuiteCRUD(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	createTestSuitePrompt(ctx, t, accountID)
+
+	req := model.CreateTestSuiteRequest{
+		AccountID: accountID,
+		Name:      "capitals",
+		Prompt:    "describe",
+		Cases: []model.TestCase{{
+			Name:       "paris",
+			Inputs:     map[string]any{"city": "Paris"},
+			Assertions: []model.Assertion{{Type: "contains", Value: "France"}},
+		}},
+	}
+	created, err := testClient.CreateTestSuite(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, req.Cases, created.Cases)
+
+	_, err = testClient.CreateTestSuite(ctx, req)
+	assertStatus(t, err, http.StatusConflict)
+
+	fetched, err := testClient.GetTestSuite(ctx, accountID, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created, fetched)
+
+	updated, err := testClient.UpdateTestSuite(ctx, accountID, created.ID, model.UpdateTestSuiteRequest{
+		Name:        "capitals",
+		Description: "European capitals",
+		Prompt:      "describe",
+		Cases:       req.Cases,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "European capitals", updated.Description)
+
+	list, err := testClient.ListTestSuites(ctx, accountID)
+	require.NoError(t, err)
+	require.Len(t, list.Suites, 1)
+	assert.Equal(t, *updated, list.Suites[0])
+
+	require.NoError(t, testClient.DeleteTestSuite(ctx, accountID, created.ID))
+	_, err = testClient.GetTestSuite(ctx, accountID, created.ID)
+	assertStatus(t, err, http.StatusNotFound)
+}
+
+func TestCreateTestSuiteIfInvalid(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	_, err := testClient.CreateTestSuite(ctx, model.CreateTestSuiteRequest{
+		AccountID: accountID,
+		Name:      "broken",
+		Prompt:    "missing",
+		Cases: []model.TestCase{{
+			Name:       "a",
+			Assertions: []model.Assertion{{Type: "contains", Value: "x"}},
+		}},
+	})
+	var errResp *model.ErrorResponse
+	require.ErrorAs(t, err, &errResp)
+	require.Len(t, errResp.Fields, 1)
+	assert.Equal(t, "prompt", errResp.Fields[0].Field)
+
+	_, err = testClient.CreateTestSuite(ctx, model.CreateTestSuiteRequest{
+		AccountID: accountID,
+		Name:      "broken",
+		Prompt:    "missing",
+		Cases: []model.TestCase{{
+			Name:       "a",
+			Assertions: []model.Assertion{{Type: "json_schema", Schema: json.RawMessage(`{"type": 1}`)}},
+		}},
+	})
+	require.ErrorAs(t, err, &errResp)
+	require.Len(t, errResp.Fields, 1)
+	assert.Equal(t, "cases[0].assertions[0].schema", errResp.Fields[0].Field)
+}
+
+func TestRunPromptTestSuite(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	createTestSuitePrompt(ctx, t, accountID)
+	suite, err := testClient.CreateTestSuite(ctx, model.CreateTestSuiteRequest{
+		AccountID: accountID,
+		Name:      "capitals",
+		Prompt:    "describe",
+		Cases: []model.TestCase{
+			{
+				Name:   "paris",
+				Inputs: map[string]any{"city": "Paris"},
+				Assertions: []model.Assertion{
+					{Type: "json_schema", Schema: json.RawMessage(`{"type": "object", "required": ["country"]}`)},
+					{Type: "json_path", Path: "$.country", Expected: "France"},
+					{Type: "not_contains", Value: "Italy"},
+					{Type: "max_tokens", Value: "1500"},
+					{Type: "max_latency", Value: "5s"},
+					{Type: "max_cost", Value: "0.0005"},
+				},
+			},
+			{
+				Name:       "berlin",
+				Inputs:     map[string]any{"city": "Berlin"},
+				Assertions: []model.Assertion{{Type: "regex", Value: "(?i)germany"}},
+			},
+			{
+				Name:       "rome",
+				Inputs:     map[string]any{"city": "Rome"},
+				Assertions: []model.Assertion{{Type: "contains", Value: "Italy"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = testClient.RunTestSuite(ctx, accountID, suite.ID, model.RunTestSuiteRequest{Model: "missing"})
+	var errResp *model.ErrorResponse
+	require.ErrorAs(t, err, &errResp)
+	require.Len(t, errResp.Fields, 1)
+	assert.Equal(t, "model", errResp.Fields[0].Field)
+
+	run := runTestSuite(ctx, t, accountID, suite.ID, model.RunTestSuiteRequest{Model: "priced"})
+	assert.Equal(t, "succeeded", run.Status)
+	assert.Equal(t, 1, run.PromptRevision)
+	assert.Equal(t, 1, run.Passed)
+	assert.Equal(t, 2, run.Failed)
+	// Two calls of 1000 tokens at $0.15 and 500 tokens at $0.6 per million;
+	// the failed call reports no usage.
+	assert.Equal(t, "0.0009", run.Cost)
+	assert.Equal(t, 3000, run.Usage.TotalTokens)
+
+	require.Len(t, run.Cases, 3)
+	paris, berlin, rome := run.Cases[0], run.Cases[1], run.Cases[2]
+	assert.True(t, paris.Passed, paris.Assertions)
+	assert.Len(t, paris.Assertions, 6)
+	assert.Equal(t, "0.00045", paris.Cost)
+	assert.False(t, berlin.Passed)
+	assert.Equal(t, "I do not know.", berlin.Output)
+	assert.NotEmpty(t, berlin.Assertions[0].Message)
+	assert.False(t, rome.Passed)
+	assert.NotEmpty(t, rome.Error)
+	assert.Empty(t, rome.Assertions)
+
+	fetched, err := testClient.GetTestSuiteRun(ctx, accountID, suite.ID, run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, run.Cases, fetched.Cases)
+
+	runs, err := testClient.ListTestSuiteRuns(ctx, accountID, suite.ID)
+	require.NoError(t, err)
+	require.Len(t, runs.Runs, 1)
+	assert.Equal(t, run.ID, runs.Runs[0].ID)
+	assert.Empty(t, runs.Runs[0].Cases)
+}
+
+func TestRunFlowTestSuite(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	createTestSuitePrompt(ctx, t, accountID)
+	_, err := testClient.CreateFlow(ctx, model.CreateFlowRequest{
+		AccountID: accountID,
+		Format:    "yaml",
+		Source: `
+name: capital
+inputs:
+  - {name: city, type: string, required: true}
+outputs:
+  - {name: answer, type: string, from: steps.ask.output}
+steps:
+  - id: ask
+    type: prompt
+    inputs: {city: inputs.city}
+    prompt: {model: unpriced, user: "Describe {{ .city }}"}
+`,
+	})
+	require.NoError(t, err)
+	suite, err := testClient.CreateTestSuite(ctx, model.CreateTestSuiteRequest{
+		AccountID: accountID,
+		Name:      "capital-flow",
+		Flow:      "capital",
+		Cases: []model.TestCase{{
+			Name:       "paris",
+			Inputs:     map[string]any{"city": "Paris"},
+			Assertions: []model.Assertion{{Type: "json_path", Path: "$.city", Expected: "Paris"}},
+		}},
+	})
+	require.NoError(t, err)
+
+	_, err = testClient.RunTestSuite(ctx, accountID, suite.ID, model.RunTestSuiteRequest{Model: "priced", Revision: 1})
+	assertStatus(t, err, http.StatusBadRequest)
+
+	// The run's model replaces the flow's, so the case is priced.
+	run := runTestSuite(ctx, t, accountID, suite.ID, model.RunTestSuiteRequest{Model: "priced"})
+	assert.Equal(t, 1, run.Passed)
+	assert.Equal(t, "0.00045", run.Cost)
+	require.NotNil(t, run.Cases[0].FlowRunID)
+}
+
+func TestRunTestSuiteWithJudge(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	createTestSuitePrompt(ctx, t, accountID)
+	judge, err := testClient.CreateProvider(ctx, model.CreateProviderRequest{
+		Name:      "judge",
+		AccountID: accountID,
+		Type:      "mock",
+		ApiKey: `{"usage": {"prompt_tokens": 2000}, "rules": [
+			{"regex": "France", "tool_calls": [
+				{"id": "1", "name": "record_verdict", "arguments": {"score": 0.9, "reasoning": "names the country"}}
+			]},
+			{"regex": ".", "response": "{\"pass\": false, \"reasoning\": \"no country\"}"}
+		]}`,
+	})
+	require.NoError(t, err)
+	_, err = testClient.CreateModel(ctx, model.CreateModelRequest{
+		Name:       "grader",
+		AccountID:  accountID,
+		ProviderID: judge.ID,
+		InputPrice: "1",
+	})
+	require.NoError(t, err)
+
+	rubric := model.Assertion{Type: "judge", Model: "grader", Rubric: "Does the answer name the country?", Threshold: 0.8}
+	req := model.CreateTestSuiteRequest{
+		AccountID: accountID,
+		Name:      "judged",
+		Prompt:    "describe",
+		Cases: []model.TestCase{
+			{Name: "paris", Inputs: map[string]any{"city": "Paris"}, Assertions: []model.Assertion{rubric}},
+			{Name: "oslo", Inputs: map[string]any{"city": "Oslo"}, Assertions: []model.Assertion{rubric}},
+		},
+	}
+	missing := req
+	missing.Cases = []model.TestCase{{
+		Name:       "a",
+		Assertions: []model.Assertion{{Type: "judge", Model: "nobody", Rubric: "?"}},
+	}}
+	_, err = testClient.CreateTestSuite(ctx, missing)
+	var errResp *model.ErrorResponse
+	require.ErrorAs(t, err, &errResp)
+	require.Len(t, errResp.Fields, 1)
+	assert.Equal(t, "cases[0].assertions[0].model", errResp.Fields[0].Field)
+
+	suite, err := testClient.CreateTestSuite(ctx, req)
+	require.NoError(t, err)
+	run := runTestSuite(ctx, t, accountID, suite.ID, model.RunTestSuiteRequest{Model: "unpriced"})
+
+	assert.Equal(t, 1, run.Passed)
+	assert.Equal(t, 1, run.Failed)
+	paris, oslo := run.Cases[0].Assertions[0], run.Cases[1].Assertions[0]
+	assert.True(t, paris.Passed)
+	require.NotNil(t, paris.Score)
+	assert.InDelta(t, 0.9, *paris.Score, 1e-9)
+	assert.Equal(t, "names the country", paris.Reasoning)
+	assert.False(t, oslo.Passed)
+	assert.Equal(t, "no country", oslo.Reasoning)
+
+	// The system under test is unpriced; only the judge costs money.
+	assert.Equal(t, "0", run.Cost)
+	assert.Equal(t, 4000, run.JudgeUsage.PromptTokens)
+	assert.Equal(t, "0.004", run.JudgeCost)
+}
+
+// createTestSuitePrompt creates a mock provider answering with
+// testSuiteMockScript, the models "priced" and "unpriced" on it, and the
+// prompt "describe" taking a city.
+func createTestSuitePrompt(ctx context.Context, t *testing.T, accountID uuid.UUID) {
+	t.Helper()
+
+	provider, err := testClient.CreateProvider(ctx, model.CreateProviderRequest{
+		Name:      "mock",
+		AccountID: accountID,
+		Type:      "mock",
+		ApiKey:    testSuiteMockScript,
+	})
+	require.NoError(t, err)
+	_, err = testClient.CreateModel(ctx, model.CreateModelRequest{
+		Name:        "priced",
+		AccountID:   accountID,
+		ProviderID:  provider.ID,
+		InputPrice:  "0.15",
+		OutputPrice: "0.6",
+	})
+	require.NoError(t, err)
+	_, err = testClient.CreateModel(ctx, model.CreateModelRequest{
+		Name:       "unpriced",
+		AccountID:  accountID,
+		ProviderID: provider.ID,
+	})
+	require.NoError(t, err)
+	_, err = testClient.CreatePrompt(ctx, model.CreatePromptRequest{
+		AccountID: accountID,
+		Name:      "describe",
+		Author:    "alice",
+		Template: model.PromptTemplate{
+			User:      "Describe {{ .city }} as JSON.",
+			Variables: []model.PromptVariable{{Name: "city", Type: "string", Required: true}},
+		},
+	})
+	require.NoError(t, err)
+}
+
+// runTestSuite queues a run of the suite and waits until it has finished.
+func runTestSuite(
+	ctx context.Context, t *testing.T, accountID, suiteID uuid.UUID, req model.RunTestSuiteRequest,
+) *model.TestSuiteRunResponse {
+	t.Helper()
+
+	started, err := testClient.RunTestSuite(ctx, accountID, suiteID, req)
+	require.NoError(t, err)
+	assert.Equal(t, "pending", started.Status)
+	run, err := testClient.WaitTestSuiteRun(ctx, accountID, suiteID, started.ID)
+	require.NoError(t, err)
+	return run
+}
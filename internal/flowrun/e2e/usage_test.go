@@ -0,0 +1,85 @@
+package e2e
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/database"
+	"flow-run/pkg/flowrunclient/model"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageLedger(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	accountID := uuid.New()
+	provider, err := testClient.CreateProvider(ctx, model.CreateProviderRequest{
+		Name:      "mock",
+		AccountID: accountID,
+		Type:      "mock",
+		ApiKey: `{"usage": {"prompt_tokens": 1000, "completion_tokens": 100, "cache_read_tokens": 400},` +
+			` "rules": [{"regex": ".", "response": "short"}]}`,
+	})
+	require.NoError(t, err)
+	summarizer, err := testClient.CreateModel(ctx, model.CreateModelRequest{
+		Name:        "summarizer",
+		AccountID:   accountID,
+		ProviderID:  provider.ID,
+		InputPrice:  "2",
+		OutputPrice: "8",
+		CachedPrice: "0.5",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "0.5", summarizer.CachedPrice)
+	created, err := testClient.CreateFlow(ctx, model.CreateFlowRequest{
+		AccountID: accountID,
+		Format:    "yaml",
+		Source:    testSummarizeFlow,
+	})
+	require.NoError(t, err)
+
+	flow, err := database.NewFlowRepository(testFlowRun.DB).Get(ctx, accountID, created.ID)
+	require.NoError(t, err)
+	run, err := testFlowRun.Engine.Run(ctx, flow, map[string]any{"text": "a long text"})
+	require.NoError(t, err)
+	require.Equal(t, domain.RunStatusSucceeded, run.Status, run.Error)
+
+	stored, err := database.NewRunRepository(testFlowRun.DB).GetRun(ctx, accountID, run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "0.0022", stored.Cost.String())
+
+	// Repricing the model leaves recorded costs as they were.
+	_, err = testClient.UpdateModel(ctx, accountID, summarizer.ID, model.UpdateModelRequest{
+		Name:        "summarizer",
+		ProviderID:  provider.ID,
+		InputPrice:  "20",
+		OutputPrice: "80",
+	})
+	require.NoError(t, err)
+
+	usage, err := testClient.ListUsage(ctx, accountID, model.ListUsageRequest{RunID: &run.ID})
+	require.NoError(t, err)
+	require.Len(t, usage.Records, 1)
+	record := usage.Records[0]
+	assert.Equal(t, summarizer.ID, record.ModelID)
+	assert.Equal(t, "summarizer", record.Model)
+	assert.Equal(t, &created.ID, record.FlowID)
+	assert.Equal(t, "summarize", record.StepID)
+	assert.Equal(t, 400, record.Usage.CacheReadTokens)
+	assert.Equal(t, "2", record.InputPrice)
+	assert.Equal(t, "0.0022", record.Cost)
+
+	all, err := testClient.ListUsage(ctx, accountID, model.ListUsageRequest{})
+	require.NoError(t, err)
+	assert.Len(t, all.Records, 1)
+	other, err := testClient.ListUsage(ctx, uuid.New(), model.ListUsageRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, other.Records)
+}
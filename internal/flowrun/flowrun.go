@@ -2,17 +2,37 @@ package flowrun
 
 import (
 	"context"
+	"flow-run/internal/core/budget"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/engine"
+	"flow-run/internal/core/evaluation"
+	"flow-run/internal/core/pricing"
+	"flow-run/internal/core/worker"
 	"flow-run/internal/flowrun/config"
 	"flow-run/internal/flowrun/infra/api"
+	budgethandler "flow-run/internal/flowrun/infra/api/handler/budget"
+	"flow-run/internal/flowrun/infra/api/handler/cost"
+	"flow-run/internal/flowrun/infra/api/handler/dataset"
+	"flow-run/internal/flowrun/infra/api/handler/flow"
 	"flow-run/internal/flowrun/infra/api/handler/health"
+	"flow-run/internal/flowrun/infra/api/handler/model"
+	"flow-run/internal/flowrun/infra/api/handler/prompt"
+	"flow-run/internal/flowrun/infra/api/handler/provider"
+	"flow-run/internal/flowrun/infra/api/handler/run"
+	"flow-run/internal/flowrun/infra/api/handler/suite"
+	"flow-run/internal/flowrun/infra/api/handler/usage"
 	"flow-run/internal/flowrun/infra/api/middleware"
 	"flow-run/internal/flowrun/infra/database"
+	"flow-run/internal/flowrun/infra/llm"
+	"flow-run/internal/lib/envelope"
 	"flow-run/internal/lib/logger"
 )
 
 type FlowRun struct {
 	Config *config.Config
 	DB     *database.Database
+	Engine *engine.Engine
+	Prices *pricing.Syncer
 
 	components []component
 }
@@ -29,10 +49,45 @@ func NewFlowRun() (*FlowRun, error) {
 		return nil, err
 	}
 
+	keyring, err := envelope.NewKeyring(cfg.KeyringConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	db, err := database.NewDatabase(cfg.DatabaseConfig)
 	if err != nil {
 		return nil, err
 	}
+	migrator, err := database.NewMigrator(db, keyring)
+	if err != nil {
+		return nil, err
+	}
+
+	providerRepo := database.NewProviderRepository(db, keyring)
+	modelRepo := database.NewModelRepository(db)
+	promptRepo := database.NewPromptRepository(db)
+	flowRepo := database.NewFlowRepository(db)
+	runRepo := database.NewRunRepository(db)
+	jobRepo := database.NewJobRepository(db)
+	suiteRepo := database.NewTestSuiteRepository(db)
+	suiteRunRepo := database.NewTestSuiteRunRepository(db)
+	datasetRepo := database.NewDatasetRepository(db)
+	usageRepo := database.NewUsageRecordRepository(db)
+	budgetRepo := database.NewBudgetRepository(db)
+
+	llmFactory := llm.NewFactory(keyring)
+	budgetGuard := budget.NewGuard(budgetRepo)
+	flowEngine := engine.NewEngine(
+		flowRepo, modelRepo, promptRepo, providerRepo, llmFactory, runRepo, jobRepo, usageRepo, budgetGuard,
+	)
+	workers, err := worker.NewPool(jobRepo, cfg.PoolConfig)
+	if err != nil {
+		return nil, err
+	}
+	prices := pricing.NewSyncer(providerRepo, modelRepo, llmFactory, cfg.PriceSyncInterval)
+	runner := evaluation.NewRunner(flowEngine, flowRepo, modelRepo, promptRepo, datasetRepo, suiteRunRepo, jobRepo)
+	workers.Handle(domain.JobKindFlowRun, flowEngine.Execute)
+	workers.Handle(domain.JobKindTestSuiteRun, runner.Execute)
 
 	server := api.NewServer(
 		[]api.Middleware{
@@ -40,6 +95,63 @@ func NewFlowRun() (*FlowRun, error) {
 		},
 		[]api.Handler{
 			health.NewHealthHandler(db),
+			provider.NewCreateProviderHandler(providerRepo),
+			provider.NewGetProviderHandler(providerRepo),
+			provider.NewListProvidersHandler(providerRepo),
+			provider.NewUpdateProviderHandler(providerRepo),
+			provider.NewDeleteProviderHandler(providerRepo),
+			model.NewCreateModelHandler(modelRepo),
+			model.NewGetModelHandler(modelRepo),
+			model.NewListModelsHandler(modelRepo),
+			model.NewUpdateModelHandler(modelRepo),
+			model.NewDeleteModelHandler(modelRepo),
+			model.NewListModelPricesHandler(modelRepo),
+			model.NewAddModelPriceHandler(modelRepo),
+			prompt.NewCreatePromptHandler(promptRepo),
+			prompt.NewGetPromptHandler(promptRepo),
+			prompt.NewListPromptsHandler(promptRepo),
+			prompt.NewUpdatePromptHandler(promptRepo),
+			prompt.NewDeletePromptHandler(promptRepo),
+			prompt.NewListPromptRevisionsHandler(promptRepo),
+			prompt.NewGetPromptRevisionHandler(promptRepo),
+			prompt.NewDiffPromptRevisionsHandler(promptRepo),
+			prompt.NewListPromptLabelsHandler(promptRepo),
+			prompt.NewSetPromptLabelHandler(promptRepo),
+			flow.NewCreateFlowHandler(flowRepo),
+			flow.NewGetFlowHandler(flowRepo),
+			flow.NewListFlowsHandler(flowRepo),
+			flow.NewUpdateFlowHandler(flowRepo),
+			flow.NewDeleteFlowHandler(flowRepo),
+			flow.NewEstimateFlowHandler(flowRepo, modelRepo, flowEngine),
+			flow.NewRunFlowHandler(flowRepo, modelRepo, flowEngine),
+			run.NewGetRunHandler(runRepo),
+			run.NewCancelRunHandler(runRepo, flowEngine),
+			suite.NewCreateTestSuiteHandler(suiteRepo),
+			suite.NewGetTestSuiteHandler(suiteRepo),
+			suite.NewListTestSuitesHandler(suiteRepo),
+			suite.NewUpdateTestSuiteHandler(suiteRepo),
+			suite.NewDeleteTestSuiteHandler(suiteRepo),
+			suite.NewRunTestSuiteHandler(suiteRepo, runner),
+			suite.NewListTestSuiteRunsHandler(suiteRepo, suiteRunRepo),
+			suite.NewGetTestSuiteRunHandler(suiteRunRepo),
+			suite.NewRunRegressionHandler(suiteRepo, runner),
+			suite.NewGetRegressionReportHandler(suiteRunRepo),
+			dataset.NewCreateDatasetHandler(datasetRepo),
+			dataset.NewGetDatasetHandler(datasetRepo),
+			dataset.NewListDatasetsHandler(datasetRepo),
+			dataset.NewUpdateDatasetHandler(datasetRepo),
+			dataset.NewDeleteDatasetHandler(datasetRepo),
+			dataset.NewImportDatasetHandler(datasetRepo),
+			dataset.NewListDatasetVersionsHandler(datasetRepo),
+			dataset.NewListDatasetRowsHandler(datasetRepo),
+			usage.NewListUsageHandler(usageRepo),
+			cost.NewGetCostReportHandler(usageRepo),
+			budgethandler.NewCreateBudgetHandler(budgetRepo),
+			budgethandler.NewGetBudgetHandler(budgetRepo),
+			budgethandler.NewListBudgetsHandler(budgetRepo),
+			budgethandler.NewUpdateBudgetHandler(budgetRepo),
+			budgethandler.NewDeleteBudgetHandler(budgetRepo),
+			budgethandler.NewListBudgetEventsHandler(budgetRepo),
 		},
 		cfg,
 	)
@@ -47,8 +159,15 @@ func NewFlowRun() (*FlowRun, error) {
 	return &FlowRun{
 		Config: cfg,
 		DB:     db,
+		Engine: flowEngine,
+		Prices: prices,
 		components: []component{
-			{name: "database", stop: db.Stop},
+			{name: "database", start: migrator.Check, stop: db.Stop},
+			{name: "budget", start: budgetGuard.Start},
+			{name: "engine", start: flowEngine.Start, stop: flowEngine.Stop},
+			{name: "evaluation", start: runner.Start, stop: runner.Stop},
+			{name: "pricing", start: prices.Start, stop: prices.Stop},
+			{name: "worker", start: workers.Start, stop: workers.Stop},
 			{name: "server", start: server.Start, stop: server.Stop},
 		},
 	}, nil
@@ -72,7 +191,10 @@ func (fr *FlowRun) Start(ctx context.Context) error {
 func (fr *FlowRun) Stop(ctx context.Context) error {
 	logger.Log.Info("Stopping FlowRun server")
 
-	for _, component := range fr.components {
+	// Components stop in reverse order so the engine can still record the
+	// state of interrupted runs before the database closes.
+	for i := len(fr.components) - 1; i >= 0; i-- {
+		component := fr.components[i]
 		if component.stop != nil {
 			if err := component.stop(ctx); err != nil {
 				logger.Log.WithError(err).Warnf("Failed to stop component %s", component.name)
@@ -0,0 +1,91 @@
+package budget
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/lib/validator"
+	dto "flow-run/pkg/flowrunclient/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const groupBudgetV1 = "v1/budget"
+
+type budgetRepository interface {
+	Create(ctx context.Context, b *domain.Budget) error
+	Get(ctx context.Context, accountID, id uuid.UUID) (*domain.Budget, error)
+	List(ctx context.Context, accountID uuid.UUID) ([]*domain.Budget, error)
+	Update(ctx context.Context, b *domain.Budget) error
+	Delete(ctx context.Context, accountID, id uuid.UUID) error
+	Spent(ctx context.Context, b *domain.Budget, now time.Time) (domain.Money, error)
+	ListEvents(ctx context.Context, accountID, id uuid.UUID) ([]*domain.BudgetEvent, error)
+}
+
+// budgetResponse builds the response of b with what it has spent in the
+// period of now.
+func budgetResponse(ctx context.Context, repo budgetRepository, b *domain.Budget) (dto.BudgetResponse, error) {
+	now := time.Now()
+	spent, err := repo.Spent(ctx, b, now)
+	if err != nil {
+		return dto.BudgetResponse{}, err
+	}
+	return toBudgetResponse(b, spent, b.PeriodStart(now)), nil
+}
+
+func toBudgetResponse(b *domain.Budget, spent domain.Money, periodStart time.Time) dto.BudgetResponse {
+	return dto.BudgetResponse{
+		ID:          b.ID,
+		Name:        b.Name,
+		AccountID:   b.AccountID,
+		Scope:       string(b.Scope),
+		TargetID:    b.TargetID,
+		Period:      string(b.Period),
+		SoftLimit:   b.SoftLimit.String(),
+		HardLimit:   b.HardLimit.String(),
+		Spent:       spent.String(),
+		PeriodStart: periodStart,
+	}
+}
+
+func toBudgetEventResponse(e *domain.BudgetEvent) dto.BudgetEventResponse {
+	return dto.BudgetEventResponse{
+		ID:          e.ID,
+		BudgetID:    e.BudgetID,
+		Type:        string(e.Type),
+		PeriodStart: e.PeriodStart,
+		Spent:       e.Spent.String(),
+		Limit:       e.Limit.String(),
+		CreatedAt:   e.CreatedAt,
+	}
+}
+
+// budgetLimits are the limits of a budget request.
+type budgetLimits struct {
+	soft, hard domain.Money
+}
+
+// parseLimits parses the decimal dollar limits of a request. An empty limit
+// is zero, i.e. no limit.
+func parseLimits(soft, hard string) (budgetLimits, error) {
+	var fields []validator.FieldError
+	parse := func(field, value string) domain.Money {
+		if value == "" {
+			return 0
+		}
+		limit, err := domain.ParseMoney(value)
+		if err != nil {
+			fields = append(fields, validator.FieldError{Field: field, Tag: "money", Message: err.Error()})
+		}
+		return limit
+	}
+
+	limits := budgetLimits{
+		soft: parse("soft_limit", soft),
+		hard: parse("hard_limit", hard),
+	}
+	if len(fields) > 0 {
+		return budgetLimits{}, &validator.Error{Fields: fields}
+	}
+	return limits, nil
+}
@@ -0,0 +1,71 @@
+package budget
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	dto "flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CreateBudgetHandler struct {
+	repo budgetRepository
+}
+
+func NewCreateBudgetHandler(repo budgetRepository) *CreateBudgetHandler {
+	return &CreateBudgetHandler{repo: repo}
+}
+
+func (h *CreateBudgetHandler) Group() string {
+	return groupBudgetV1
+}
+
+func (h *CreateBudgetHandler) Method() string {
+	return http.MethodPost
+}
+
+func (h *CreateBudgetHandler) Path() string {
+	return ""
+}
+
+func (h *CreateBudgetHandler) Handle(c *gin.Context) {
+	var req dto.CreateBudgetRequest
+	if !request.JSON(c, &req) {
+		return
+	}
+
+	limits, err := parseLimits(req.SoftLimit, req.HardLimit)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	b, err := domain.NewBudget(
+		domain.WithBudgetID(uuid.New()),
+		domain.WithBudgetAccountID(req.AccountID),
+		domain.WithBudgetName(req.Name),
+		domain.WithBudgetScope(domain.BudgetScope(req.Scope), req.TargetID),
+		domain.WithBudgetPeriod(domain.BudgetPeriod(req.Period)),
+		domain.WithBudgetLimits(limits.soft, limits.hard),
+	)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.repo.Create(c.Request.Context(), b); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp, err := budgetResponse(c.Request.Context(), h.repo, b)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
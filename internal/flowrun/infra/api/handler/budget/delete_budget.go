@@ -0,0 +1,43 @@
+package budget
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeleteBudgetHandler struct {
+	repo budgetRepository
+}
+
+func NewDeleteBudgetHandler(repo budgetRepository) *DeleteBudgetHandler {
+	return &DeleteBudgetHandler{repo: repo}
+}
+
+func (h *DeleteBudgetHandler) Group() string {
+	return groupBudgetV1
+}
+
+func (h *DeleteBudgetHandler) Method() string {
+	return http.MethodDelete
+}
+
+func (h *DeleteBudgetHandler) Path() string {
+	return "/:id"
+}
+
+func (h *DeleteBudgetHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), accountID, id); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
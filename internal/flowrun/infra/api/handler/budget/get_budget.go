@@ -0,0 +1,50 @@
+package budget
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetBudgetHandler struct {
+	repo budgetRepository
+}
+
+func NewGetBudgetHandler(repo budgetRepository) *GetBudgetHandler {
+	return &GetBudgetHandler{repo: repo}
+}
+
+func (h *GetBudgetHandler) Group() string {
+	return groupBudgetV1
+}
+
+func (h *GetBudgetHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *GetBudgetHandler) Path() string {
+	return "/:id"
+}
+
+func (h *GetBudgetHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	b, err := h.repo.Get(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp, err := budgetResponse(c.Request.Context(), h.repo, b)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
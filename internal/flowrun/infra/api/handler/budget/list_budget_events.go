@@ -0,0 +1,56 @@
+package budget
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	dto "flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListBudgetEventsHandler struct {
+	repo budgetRepository
+}
+
+func NewListBudgetEventsHandler(repo budgetRepository) *ListBudgetEventsHandler {
+	return &ListBudgetEventsHandler{repo: repo}
+}
+
+func (h *ListBudgetEventsHandler) Group() string {
+	return groupBudgetV1
+}
+
+func (h *ListBudgetEventsHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *ListBudgetEventsHandler) Path() string {
+	return "/:id/events"
+}
+
+// Handle lists the events of a budget, oldest first.
+func (h *ListBudgetEventsHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.repo.Get(c.Request.Context(), accountID, id); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	events, err := h.repo.ListEvents(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp := dto.BudgetEventsResponse{Events: make([]dto.BudgetEventResponse, 0, len(events))}
+	for _, e := range events {
+		resp.Events = append(resp.Events, toBudgetEventResponse(e))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
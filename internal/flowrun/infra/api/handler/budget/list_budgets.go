@@ -0,0 +1,55 @@
+package budget
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	dto "flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListBudgetsHandler struct {
+	repo budgetRepository
+}
+
+func NewListBudgetsHandler(repo budgetRepository) *ListBudgetsHandler {
+	return &ListBudgetsHandler{repo: repo}
+}
+
+func (h *ListBudgetsHandler) Group() string {
+	return groupBudgetV1
+}
+
+func (h *ListBudgetsHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *ListBudgetsHandler) Path() string {
+	return ""
+}
+
+func (h *ListBudgetsHandler) Handle(c *gin.Context) {
+	accountID, ok := request.AccountID(c)
+	if !ok {
+		return
+	}
+
+	budgets, err := h.repo.List(c.Request.Context(), accountID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp := dto.BudgetsResponse{Budgets: make([]dto.BudgetResponse, 0, len(budgets))}
+	for _, b := range budgets {
+		budgetResp, err := budgetResponse(c.Request.Context(), h.repo, b)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+		resp.Budgets = append(resp.Budgets, budgetResp)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,75 @@
+package budget
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	dto "flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateBudgetHandler struct {
+	repo budgetRepository
+}
+
+func NewUpdateBudgetHandler(repo budgetRepository) *UpdateBudgetHandler {
+	return &UpdateBudgetHandler{repo: repo}
+}
+
+func (h *UpdateBudgetHandler) Group() string {
+	return groupBudgetV1
+}
+
+func (h *UpdateBudgetHandler) Method() string {
+	return http.MethodPut
+}
+
+func (h *UpdateBudgetHandler) Path() string {
+	return "/:id"
+}
+
+func (h *UpdateBudgetHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateBudgetRequest
+	if !request.JSON(c, &req) {
+		return
+	}
+
+	limits, err := parseLimits(req.SoftLimit, req.HardLimit)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	b, err := domain.NewBudget(
+		domain.WithBudgetID(id),
+		domain.WithBudgetAccountID(accountID),
+		domain.WithBudgetName(req.Name),
+		domain.WithBudgetScope(domain.BudgetScope(req.Scope), req.TargetID),
+		domain.WithBudgetPeriod(domain.BudgetPeriod(req.Period)),
+		domain.WithBudgetLimits(limits.soft, limits.hard),
+	)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.repo.Update(c.Request.Context(), b); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp, err := budgetResponse(c.Request.Context(), h.repo, b)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,58 @@
+package cost
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	dto "flow-run/pkg/flowrunclient/model"
+)
+
+const groupCostV1 = "v1/cost"
+
+type costRepository interface {
+	Report(ctx context.Context, q *domain.CostReportQuery) ([]*domain.CostReportRow, error)
+}
+
+func toCostReportResponse(r *domain.CostReport) dto.CostReportResponse {
+	groupBy := make([]string, 0, len(r.Query.GroupBy))
+	for _, d := range r.Query.GroupBy {
+		groupBy = append(groupBy, string(d))
+	}
+	rows := make([]dto.CostReportRow, 0, len(r.Rows))
+	for _, row := range r.Rows {
+		rows = append(rows, dto.CostReportRow{
+			Bucket:         row.Bucket,
+			AccountID:      row.AccountID,
+			FlowID:         row.FlowID,
+			ModelID:        row.ModelID,
+			Model:          row.Model,
+			ProviderID:     row.ProviderID,
+			PromptID:       row.PromptID,
+			PromptRevision: row.PromptRevision,
+			Purpose:        string(row.Purpose),
+			Calls:          row.Calls,
+			Usage:          toTokenUsage(row.Usage),
+			Cost:           row.Cost.String(),
+		})
+	}
+	return dto.CostReportResponse{
+		AccountID: r.Query.AccountID,
+		From:      r.Query.From,
+		To:        r.Query.To,
+		Bucket:    string(r.Query.Bucket),
+		GroupBy:   groupBy,
+		Calls:     r.Calls,
+		Usage:     toTokenUsage(r.Usage),
+		Cost:      r.Cost.String(),
+		Rows:      rows,
+	}
+}
+
+func toTokenUsage(u domain.TokenUsage) dto.TokenUsage {
+	return dto.TokenUsage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+		CacheReadTokens:  u.CacheReadTokens,
+		CacheWriteTokens: u.CacheWriteTokens,
+	}
+}
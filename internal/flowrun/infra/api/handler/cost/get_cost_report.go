@@ -0,0 +1,71 @@
+package cost
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCostReportHandler aggregates the usage ledger over the [from, to) range
+// into hour, day or month buckets, optionally grouped by a comma-separated
+// group_by list. Without account_id the report covers every account.
+type GetCostReportHandler struct {
+	repo costRepository
+}
+
+func NewGetCostReportHandler(repo costRepository) *GetCostReportHandler {
+	return &GetCostReportHandler{repo: repo}
+}
+
+func (h *GetCostReportHandler) Group() string {
+	return groupCostV1
+}
+
+func (h *GetCostReportHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *GetCostReportHandler) Path() string {
+	return "/report"
+}
+
+func (h *GetCostReportHandler) Handle(c *gin.Context) {
+	accountID, ok := request.OptionalQueryID(c, "account_id")
+	if !ok {
+		return
+	}
+	from, ok := request.QueryTime(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := request.QueryTime(c, "to")
+	if !ok {
+		return
+	}
+	var groupBy []domain.CostDimension
+	if value := c.Query("group_by"); value != "" {
+		for _, d := range strings.Split(value, ",") {
+			groupBy = append(groupBy, domain.CostDimension(strings.TrimSpace(d)))
+		}
+	}
+
+	q, err := domain.NewCostReportQuery(
+		accountID, from, to, domain.CostBucket(c.DefaultQuery("bucket", string(domain.CostBucketDay))), groupBy,
+	)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	rows, err := h.repo.Report(c.Request.Context(), q)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toCostReportResponse(domain.NewCostReport(q, rows)))
+}
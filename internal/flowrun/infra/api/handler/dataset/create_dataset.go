@@ -0,0 +1,57 @@
+package dataset
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CreateDatasetHandler struct {
+	repo datasetRepository
+}
+
+func NewCreateDatasetHandler(repo datasetRepository) *CreateDatasetHandler {
+	return &CreateDatasetHandler{repo: repo}
+}
+
+func (h *CreateDatasetHandler) Group() string {
+	return groupDatasetV1
+}
+
+func (h *CreateDatasetHandler) Method() string {
+	return http.MethodPost
+}
+
+func (h *CreateDatasetHandler) Path() string {
+	return ""
+}
+
+func (h *CreateDatasetHandler) Handle(c *gin.Context) {
+	var req model.CreateDatasetRequest
+	if !request.JSON(c, &req) {
+		return
+	}
+
+	d, err := domain.NewDataset(
+		domain.WithDatasetID(uuid.New()),
+		domain.WithDatasetAccountID(req.AccountID),
+		domain.WithDatasetName(req.Name),
+		domain.WithDatasetDescription(req.Description),
+	)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.repo.Create(c.Request.Context(), d); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toDatasetResponse(d))
+}
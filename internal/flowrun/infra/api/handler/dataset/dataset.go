@@ -0,0 +1,47 @@
+package dataset
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/pkg/flowrunclient/model"
+
+	"github.com/google/uuid"
+)
+
+const groupDatasetV1 = "v1/dataset"
+
+type datasetRepository interface {
+	Create(ctx context.Context, d *domain.Dataset) error
+	Get(ctx context.Context, accountID, id uuid.UUID) (*domain.Dataset, error)
+	List(ctx context.Context, accountID uuid.UUID) ([]*domain.Dataset, error)
+	Update(ctx context.Context, d *domain.Dataset) error
+	Delete(ctx context.Context, accountID, id uuid.UUID) error
+	Import(
+		ctx context.Context, accountID, id uuid.UUID, format domain.DatasetFormat, rows domain.DatasetReader,
+	) (*domain.DatasetVersion, error)
+	ListVersions(ctx context.Context, accountID, datasetID uuid.UUID) ([]*domain.DatasetVersion, error)
+	GetVersion(ctx context.Context, accountID, datasetID uuid.UUID, number int) (*domain.DatasetVersion, error)
+	ListRows(ctx context.Context, versionID uuid.UUID, offset, limit int) ([]*domain.DatasetRow, error)
+}
+
+func toDatasetResponse(d *domain.Dataset) model.DatasetResponse {
+	return model.DatasetResponse{
+		ID:          d.ID,
+		AccountID:   d.AccountID,
+		Name:        d.Name,
+		Description: d.Description,
+		Version:     d.Version,
+		RowCount:    d.RowCount,
+	}
+}
+
+func toDatasetVersionResponse(v *domain.DatasetVersion) model.DatasetVersionResponse {
+	return model.DatasetVersionResponse{
+		ID:        v.ID,
+		DatasetID: v.DatasetID,
+		Version:   v.Number,
+		Format:    string(v.Format),
+		RowCount:  v.RowCount,
+		CreatedAt: v.CreatedAt,
+	}
+}
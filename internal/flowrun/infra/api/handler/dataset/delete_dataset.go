@@ -0,0 +1,43 @@
+package dataset
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeleteDatasetHandler struct {
+	repo datasetRepository
+}
+
+func NewDeleteDatasetHandler(repo datasetRepository) *DeleteDatasetHandler {
+	return &DeleteDatasetHandler{repo: repo}
+}
+
+func (h *DeleteDatasetHandler) Group() string {
+	return groupDatasetV1
+}
+
+func (h *DeleteDatasetHandler) Method() string {
+	return http.MethodDelete
+}
+
+func (h *DeleteDatasetHandler) Path() string {
+	return "/:id"
+}
+
+func (h *DeleteDatasetHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), accountID, id); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
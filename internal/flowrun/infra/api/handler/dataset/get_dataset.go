@@ -0,0 +1,44 @@
+package dataset
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetDatasetHandler struct {
+	repo datasetRepository
+}
+
+func NewGetDatasetHandler(repo datasetRepository) *GetDatasetHandler {
+	return &GetDatasetHandler{repo: repo}
+}
+
+func (h *GetDatasetHandler) Group() string {
+	return groupDatasetV1
+}
+
+func (h *GetDatasetHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *GetDatasetHandler) Path() string {
+	return "/:id"
+}
+
+func (h *GetDatasetHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	d, err := h.repo.Get(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toDatasetResponse(d))
+}
@@ -0,0 +1,76 @@
+package dataset
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contentTypeFormats maps the media types of uploads that name no format to
+// the format they carry.
+var contentTypeFormats = map[string]domain.DatasetFormat{
+	"text/csv":             domain.DatasetFormatCSV,
+	"application/x-ndjson": domain.DatasetFormatJSONL,
+	"application/jsonl":    domain.DatasetFormatJSONL,
+	"application/ndjson":   domain.DatasetFormatJSONL,
+}
+
+// ImportDatasetHandler adds a version to a dataset from the request body,
+// which is streamed into the database rather than buffered. The format is
+// taken from the format query parameter, or else from the Content-Type.
+type ImportDatasetHandler struct {
+	repo datasetRepository
+}
+
+func NewImportDatasetHandler(repo datasetRepository) *ImportDatasetHandler {
+	return &ImportDatasetHandler{repo: repo}
+}
+
+func (h *ImportDatasetHandler) Group() string {
+	return groupDatasetV1
+}
+
+func (h *ImportDatasetHandler) Method() string {
+	return http.MethodPost
+}
+
+func (h *ImportDatasetHandler) Path() string {
+	return "/:id/versions"
+}
+
+func (h *ImportDatasetHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	format := domain.DatasetFormat(c.Query("format"))
+	if format == "" {
+		format = contentTypeFormats[c.ContentType()]
+	}
+	if format == "" {
+		response.BadRequest(c, "invalid format", model.FieldError{
+			Field:   "format",
+			Tag:     "required",
+			Message: "format must be csv or jsonl, given as a query parameter or by the Content-Type",
+		})
+		return
+	}
+
+	rows, err := domain.NewDatasetReader(format, c.Request.Body)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	version, err := h.repo.Import(c.Request.Context(), accountID, id, format, rows)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toDatasetVersionResponse(version))
+}
@@ -0,0 +1,79 @@
+package dataset
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultRowLimit = 100
+	maxRowLimit     = 1000
+)
+
+// ListDatasetRowsHandler pages through the rows of a dataset version with
+// the offset and limit query parameters.
+type ListDatasetRowsHandler struct {
+	repo datasetRepository
+}
+
+func NewListDatasetRowsHandler(repo datasetRepository) *ListDatasetRowsHandler {
+	return &ListDatasetRowsHandler{repo: repo}
+}
+
+func (h *ListDatasetRowsHandler) Group() string {
+	return groupDatasetV1
+}
+
+func (h *ListDatasetRowsHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *ListDatasetRowsHandler) Path() string {
+	return "/:id/versions/:version/rows"
+}
+
+func (h *ListDatasetRowsHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+	number, ok := request.PositiveInt(c, "version", c.Param("version"))
+	if !ok {
+		return
+	}
+	offset, limit, ok := request.Page(c, defaultRowLimit, maxRowLimit)
+	if !ok {
+		return
+	}
+
+	version, err := h.repo.GetVersion(c.Request.Context(), accountID, id, number)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	rows, err := h.repo.ListRows(c.Request.Context(), version.ID, offset, limit)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp := model.DatasetRowsResponse{
+		Version: version.Number,
+		Offset:  offset,
+		Rows:    make([]model.DatasetRow, 0, len(rows)),
+	}
+	for _, r := range rows {
+		resp.Rows = append(resp.Rows, model.DatasetRow{
+			Position: r.Position,
+			Name:     r.Name,
+			Inputs:   r.Inputs,
+			Expected: r.Expected,
+		})
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,54 @@
+package dataset
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListDatasetVersionsHandler struct {
+	repo datasetRepository
+}
+
+func NewListDatasetVersionsHandler(repo datasetRepository) *ListDatasetVersionsHandler {
+	return &ListDatasetVersionsHandler{repo: repo}
+}
+
+func (h *ListDatasetVersionsHandler) Group() string {
+	return groupDatasetV1
+}
+
+func (h *ListDatasetVersionsHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *ListDatasetVersionsHandler) Path() string {
+	return "/:id/versions"
+}
+
+func (h *ListDatasetVersionsHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.repo.Get(c.Request.Context(), accountID, id); err != nil {
+		response.Error(c, err)
+		return
+	}
+	versions, err := h.repo.ListVersions(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp := model.DatasetVersionsResponse{Versions: make([]model.DatasetVersionResponse, 0, len(versions))}
+	for _, v := range versions {
+		resp.Versions = append(resp.Versions, toDatasetVersionResponse(v))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,50 @@
+package dataset
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListDatasetsHandler struct {
+	repo datasetRepository
+}
+
+func NewListDatasetsHandler(repo datasetRepository) *ListDatasetsHandler {
+	return &ListDatasetsHandler{repo: repo}
+}
+
+func (h *ListDatasetsHandler) Group() string {
+	return groupDatasetV1
+}
+
+func (h *ListDatasetsHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *ListDatasetsHandler) Path() string {
+	return ""
+}
+
+func (h *ListDatasetsHandler) Handle(c *gin.Context) {
+	accountID, ok := request.AccountID(c)
+	if !ok {
+		return
+	}
+
+	datasets, err := h.repo.List(c.Request.Context(), accountID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp := model.DatasetsResponse{Datasets: make([]model.DatasetResponse, 0, len(datasets))}
+	for _, d := range datasets {
+		resp.Datasets = append(resp.Datasets, toDatasetResponse(d))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
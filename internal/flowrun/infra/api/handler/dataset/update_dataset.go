@@ -0,0 +1,67 @@
+package dataset
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateDatasetHandler struct {
+	repo datasetRepository
+}
+
+func NewUpdateDatasetHandler(repo datasetRepository) *UpdateDatasetHandler {
+	return &UpdateDatasetHandler{repo: repo}
+}
+
+func (h *UpdateDatasetHandler) Group() string {
+	return groupDatasetV1
+}
+
+func (h *UpdateDatasetHandler) Method() string {
+	return http.MethodPut
+}
+
+func (h *UpdateDatasetHandler) Path() string {
+	return "/:id"
+}
+
+func (h *UpdateDatasetHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateDatasetRequest
+	if !request.JSON(c, &req) {
+		return
+	}
+
+	existing, err := h.repo.Get(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	d, err := domain.NewDataset(
+		domain.WithDatasetID(existing.ID),
+		domain.WithDatasetAccountID(existing.AccountID),
+		domain.WithDatasetName(req.Name),
+		domain.WithDatasetDescription(req.Description),
+	)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.repo.Update(c.Request.Context(), d); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toDatasetResponse(d))
+}
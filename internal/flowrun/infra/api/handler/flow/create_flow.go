@@ -0,0 +1,56 @@
+package flow
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CreateFlowHandler struct {
+	repo flowRepository
+}
+
+func NewCreateFlowHandler(repo flowRepository) *CreateFlowHandler {
+	return &CreateFlowHandler{repo: repo}
+}
+
+func (h *CreateFlowHandler) Group() string {
+	return groupFlowV1
+}
+
+func (h *CreateFlowHandler) Method() string {
+	return http.MethodPost
+}
+
+func (h *CreateFlowHandler) Path() string {
+	return ""
+}
+
+func (h *CreateFlowHandler) Handle(c *gin.Context) {
+	var req model.CreateFlowRequest
+	if !request.JSON(c, &req) {
+		return
+	}
+
+	f, err := domain.NewFlow(
+		domain.WithFlowID(uuid.New()),
+		domain.WithFlowAccountID(req.AccountID),
+		domain.WithFlowSource(req.Source, domain.FlowSourceFormat(req.Format)),
+	)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.repo.Create(c.Request.Context(), f); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toFlowResponse(f))
+}
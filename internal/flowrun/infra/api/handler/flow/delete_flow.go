@@ -0,0 +1,43 @@
+package flow
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeleteFlowHandler struct {
+	repo flowRepository
+}
+
+func NewDeleteFlowHandler(repo flowRepository) *DeleteFlowHandler {
+	return &DeleteFlowHandler{repo: repo}
+}
+
+func (h *DeleteFlowHandler) Group() string {
+	return groupFlowV1
+}
+
+func (h *DeleteFlowHandler) Method() string {
+	return http.MethodDelete
+}
+
+func (h *DeleteFlowHandler) Path() string {
+	return "/:id"
+}
+
+func (h *DeleteFlowHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), accountID, id); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,82 @@
+package flow
+
+import (
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/engine"
+	"flow-run/internal/core/port"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EstimateFlowHandler struct {
+	repo      flowRepository
+	models    modelRepository
+	estimator flowEstimator
+}
+
+func NewEstimateFlowHandler(repo flowRepository, models modelRepository, estimator flowEstimator) *EstimateFlowHandler {
+	return &EstimateFlowHandler{repo: repo, models: models, estimator: estimator}
+}
+
+func (h *EstimateFlowHandler) Group() string {
+	return groupFlowV1
+}
+
+func (h *EstimateFlowHandler) Method() string {
+	return http.MethodPost
+}
+
+func (h *EstimateFlowHandler) Path() string {
+	return "/:id/estimate"
+}
+
+// Handle prices a run of the flow from its rendered prompts without calling
+// any provider, so it neither spends nor counts against budgets.
+func (h *EstimateFlowHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	var req model.EstimateFlowRequest
+	if !request.JSON(c, &req) {
+		return
+	}
+
+	lengths, err := domain.NewCompletionLengths(req.CompletionTokens, req.StepCompletionTokens)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	f, err := h.repo.Get(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	var opts []engine.RunOpt
+	if req.Model != "" {
+		if _, err := h.models.GetByName(c.Request.Context(), accountID, req.Model); err != nil {
+			if errors.Is(err, port.ErrNotFound) {
+				err = &port.ReferenceError{Field: "model", Value: req.Model}
+			}
+			response.Error(c, err)
+			return
+		}
+		opts = append(opts, engine.WithModel(req.Model))
+	}
+
+	estimate, err := h.estimator.Estimate(c.Request.Context(), f, req.Inputs, lengths, opts...)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toFlowEstimateResponse(estimate))
+}
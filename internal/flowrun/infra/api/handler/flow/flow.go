@@ -0,0 +1,98 @@
+package flow
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/engine"
+	"flow-run/pkg/flowrunclient/model"
+
+	"github.com/google/uuid"
+)
+
+const groupFlowV1 = "v1/flow"
+
+type flowRepository interface {
+	Create(ctx context.Context, f *domain.Flow) error
+	Get(ctx context.Context, accountID, id uuid.UUID) (*domain.Flow, error)
+	List(ctx context.Context, accountID uuid.UUID) ([]*domain.Flow, error)
+	Update(ctx context.Context, f *domain.Flow) error
+	Delete(ctx context.Context, accountID, id uuid.UUID) error
+}
+
+type flowEstimator interface {
+	Estimate(
+		ctx context.Context,
+		flow *domain.Flow,
+		inputs map[string]any,
+		lengths *domain.CompletionLengths,
+		opts ...engine.RunOpt,
+	) (*domain.FlowEstimate, error)
+}
+
+type flowSubmitter interface {
+	Submit(ctx context.Context, flow *domain.Flow, inputs map[string]any, opts ...engine.RunOpt) (*domain.FlowRun, error)
+}
+
+type modelRepository interface {
+	GetByName(ctx context.Context, accountID uuid.UUID, name string) (*domain.Model, error)
+}
+
+func toFlowResponse(f *domain.Flow) model.FlowResponse {
+	return model.FlowResponse{
+		ID:        f.ID,
+		Name:      f.Name,
+		AccountID: f.AccountID,
+		Format:    string(f.Format),
+		Source:    f.Source,
+	}
+}
+
+func toFlowEstimateResponse(e *domain.FlowEstimate) model.FlowEstimateResponse {
+	steps := make([]model.StepEstimate, 0, len(e.Steps))
+	for _, step := range e.Steps {
+		steps = append(steps, model.StepEstimate{
+			StepID:   step.StepID,
+			Model:    step.Model,
+			Encoding: step.Encoding,
+			Rendered: step.Rendered,
+			Usage:    toTokenUsage(step.Usage),
+			Cost:     step.Cost.String(),
+		})
+	}
+	return model.FlowEstimateResponse{
+		FlowID: e.FlowID,
+		Usage:  toTokenUsage(e.Usage),
+		Cost:   e.Cost.String(),
+		Steps:  steps,
+	}
+}
+
+// toFlowRunResponse leaves out the steps: a run is only submitted here.
+func toFlowRunResponse(r *domain.FlowRun) model.FlowRunResponse {
+	return model.FlowRunResponse{
+		ID:                r.ID,
+		FlowID:            r.FlowID,
+		AccountID:         r.AccountID,
+		Model:             r.Model,
+		Status:            string(r.Status),
+		Inputs:            r.Inputs,
+		Outputs:           r.Outputs,
+		Error:             r.Error,
+		Usage:             toTokenUsage(r.Usage),
+		Cost:              r.Cost.String(),
+		CreatedAt:         r.CreatedAt,
+		StartedAt:         r.StartedAt,
+		FinishedAt:        r.FinishedAt,
+		CancelRequestedAt: r.CancelRequestedAt,
+	}
+}
+
+func toTokenUsage(u domain.TokenUsage) model.TokenUsage {
+	return model.TokenUsage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+		CacheReadTokens:  u.CacheReadTokens,
+		CacheWriteTokens: u.CacheWriteTokens,
+	}
+}
@@ -0,0 +1,44 @@
+package flow
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetFlowHandler struct {
+	repo flowRepository
+}
+
+func NewGetFlowHandler(repo flowRepository) *GetFlowHandler {
+	return &GetFlowHandler{repo: repo}
+}
+
+func (h *GetFlowHandler) Group() string {
+	return groupFlowV1
+}
+
+func (h *GetFlowHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *GetFlowHandler) Path() string {
+	return "/:id"
+}
+
+func (h *GetFlowHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	f, err := h.repo.Get(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toFlowResponse(f))
+}
@@ -0,0 +1,50 @@
+package flow
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListFlowsHandler struct {
+	repo flowRepository
+}
+
+func NewListFlowsHandler(repo flowRepository) *ListFlowsHandler {
+	return &ListFlowsHandler{repo: repo}
+}
+
+func (h *ListFlowsHandler) Group() string {
+	return groupFlowV1
+}
+
+func (h *ListFlowsHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *ListFlowsHandler) Path() string {
+	return ""
+}
+
+func (h *ListFlowsHandler) Handle(c *gin.Context) {
+	accountID, ok := request.AccountID(c)
+	if !ok {
+		return
+	}
+
+	flows, err := h.repo.List(c.Request.Context(), accountID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp := model.FlowsResponse{Flows: make([]model.FlowResponse, 0, len(flows))}
+	for _, f := range flows {
+		resp.Flows = append(resp.Flows, toFlowResponse(f))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,76 @@
+package flow
+
+import (
+	"errors"
+	"flow-run/internal/core/engine"
+	"flow-run/internal/core/port"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RunFlowHandler struct {
+	repo      flowRepository
+	models    modelRepository
+	submitter flowSubmitter
+}
+
+func NewRunFlowHandler(repo flowRepository, models modelRepository, submitter flowSubmitter) *RunFlowHandler {
+	return &RunFlowHandler{repo: repo, models: models, submitter: submitter}
+}
+
+func (h *RunFlowHandler) Group() string {
+	return groupFlowV1
+}
+
+func (h *RunFlowHandler) Method() string {
+	return http.MethodPost
+}
+
+func (h *RunFlowHandler) Path() string {
+	return "/:id/run"
+}
+
+// Handle queues a run of the flow and responds with the pending run right
+// away; a worker executes it later. Invalid inputs and budgets at their hard
+// limit are rejected before anything is queued.
+func (h *RunFlowHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	var req model.RunFlowRequest
+	if !request.JSON(c, &req) {
+		return
+	}
+
+	f, err := h.repo.Get(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	var opts []engine.RunOpt
+	if req.Model != "" {
+		if _, err := h.models.GetByName(c.Request.Context(), accountID, req.Model); err != nil {
+			if errors.Is(err, port.ErrNotFound) {
+				err = &port.ReferenceError{Field: "model", Value: req.Model}
+			}
+			response.Error(c, err)
+			return
+		}
+		opts = append(opts, engine.WithModel(req.Model))
+	}
+
+	run, err := h.submitter.Submit(c.Request.Context(), f, req.Inputs, opts...)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, toFlowRunResponse(run))
+}
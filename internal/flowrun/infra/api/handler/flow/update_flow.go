@@ -0,0 +1,66 @@
+package flow
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateFlowHandler struct {
+	repo flowRepository
+}
+
+func NewUpdateFlowHandler(repo flowRepository) *UpdateFlowHandler {
+	return &UpdateFlowHandler{repo: repo}
+}
+
+func (h *UpdateFlowHandler) Group() string {
+	return groupFlowV1
+}
+
+func (h *UpdateFlowHandler) Method() string {
+	return http.MethodPut
+}
+
+func (h *UpdateFlowHandler) Path() string {
+	return "/:id"
+}
+
+func (h *UpdateFlowHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateFlowRequest
+	if !request.JSON(c, &req) {
+		return
+	}
+
+	existing, err := h.repo.Get(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	f, err := domain.NewFlow(
+		domain.WithFlowID(existing.ID),
+		domain.WithFlowAccountID(existing.AccountID),
+		domain.WithFlowSource(req.Source, domain.FlowSourceFormat(req.Format)),
+	)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.repo.Update(c.Request.Context(), f); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toFlowResponse(f))
+}
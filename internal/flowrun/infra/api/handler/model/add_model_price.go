@@ -0,0 +1,76 @@
+package model
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	dto "flow-run/pkg/flowrunclient/model"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AddModelPriceHandler struct {
+	repo modelRepository
+}
+
+func NewAddModelPriceHandler(repo modelRepository) *AddModelPriceHandler {
+	return &AddModelPriceHandler{repo: repo}
+}
+
+func (h *AddModelPriceHandler) Group() string {
+	return groupModelV1
+}
+
+func (h *AddModelPriceHandler) Method() string {
+	return http.MethodPost
+}
+
+func (h *AddModelPriceHandler) Path() string {
+	return "/:id/prices"
+}
+
+// Handle adds a manual price to the model's catalog. Calls made from its
+// effective date on are billed at it, whatever the provider's catalog says.
+func (h *AddModelPriceHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	var req dto.AddModelPriceRequest
+	if !request.JSON(c, &req) {
+		return
+	}
+
+	m, err := h.repo.Get(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	prices, err := parsePrices(req.InputPrice, req.OutputPrice, req.CachedPrice, modelPrices{})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	m.InputPrice, m.OutputPrice, m.CachedPrice = prices.input, prices.output, prices.cached
+
+	var opts []domain.ModelPriceOpt
+	if req.EffectiveAt != nil {
+		opts = append(opts, domain.WithModelPriceEffectiveAt(*req.EffectiveAt))
+	}
+	price, err := domain.NewModelPrice(m, domain.PriceSourceManual, time.Now(), opts...)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.repo.AddPrice(c.Request.Context(), price); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toModelPriceResponse(price))
+}
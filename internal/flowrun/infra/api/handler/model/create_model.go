@@ -0,0 +1,65 @@
+package model
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	dto "flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CreateModelHandler struct {
+	repo modelRepository
+}
+
+func NewCreateModelHandler(repo modelRepository) *CreateModelHandler {
+	return &CreateModelHandler{repo: repo}
+}
+
+func (h *CreateModelHandler) Group() string {
+	return groupModelV1
+}
+
+func (h *CreateModelHandler) Method() string {
+	return http.MethodPost
+}
+
+func (h *CreateModelHandler) Path() string {
+	return ""
+}
+
+func (h *CreateModelHandler) Handle(c *gin.Context) {
+	var req dto.CreateModelRequest
+	if !request.JSON(c, &req) {
+		return
+	}
+
+	prices, err := parsePrices(req.InputPrice, req.OutputPrice, req.CachedPrice, modelPrices{})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	m, err := domain.NewModel(
+		domain.WithModelID(uuid.New()),
+		domain.WithModelName(req.Name),
+		domain.WithModelAccountID(req.AccountID),
+		domain.WithModelProviderID(req.ProviderID),
+		domain.WithModelPrices(prices.input, prices.output),
+		domain.WithModelCachedPrice(prices.cached),
+	)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.repo.Create(c.Request.Context(), m); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toModelResponse(m))
+}
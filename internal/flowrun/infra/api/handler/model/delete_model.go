@@ -0,0 +1,43 @@
+package model
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeleteModelHandler struct {
+	repo modelRepository
+}
+
+func NewDeleteModelHandler(repo modelRepository) *DeleteModelHandler {
+	return &DeleteModelHandler{repo: repo}
+}
+
+func (h *DeleteModelHandler) Group() string {
+	return groupModelV1
+}
+
+func (h *DeleteModelHandler) Method() string {
+	return http.MethodDelete
+}
+
+func (h *DeleteModelHandler) Path() string {
+	return "/:id"
+}
+
+func (h *DeleteModelHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), accountID, id); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
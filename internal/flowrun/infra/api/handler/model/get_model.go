@@ -0,0 +1,44 @@
+package model
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetModelHandler struct {
+	repo modelRepository
+}
+
+func NewGetModelHandler(repo modelRepository) *GetModelHandler {
+	return &GetModelHandler{repo: repo}
+}
+
+func (h *GetModelHandler) Group() string {
+	return groupModelV1
+}
+
+func (h *GetModelHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *GetModelHandler) Path() string {
+	return "/:id"
+}
+
+func (h *GetModelHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	m, err := h.repo.Get(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toModelResponse(m))
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	dto "flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListModelPricesHandler struct {
+	repo modelRepository
+}
+
+func NewListModelPricesHandler(repo modelRepository) *ListModelPricesHandler {
+	return &ListModelPricesHandler{repo: repo}
+}
+
+func (h *ListModelPricesHandler) Group() string {
+	return groupModelV1
+}
+
+func (h *ListModelPricesHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *ListModelPricesHandler) Path() string {
+	return "/:id/prices"
+}
+
+func (h *ListModelPricesHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	prices, err := h.repo.ListPrices(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp := dto.ModelPricesResponse{Prices: make([]dto.ModelPriceResponse, 0, len(prices))}
+	for _, p := range prices {
+		resp.Prices = append(resp.Prices, toModelPriceResponse(p))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
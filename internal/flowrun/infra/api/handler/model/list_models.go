@@ -0,0 +1,50 @@
+package model
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	dto "flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListModelsHandler struct {
+	repo modelRepository
+}
+
+func NewListModelsHandler(repo modelRepository) *ListModelsHandler {
+	return &ListModelsHandler{repo: repo}
+}
+
+func (h *ListModelsHandler) Group() string {
+	return groupModelV1
+}
+
+func (h *ListModelsHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *ListModelsHandler) Path() string {
+	return ""
+}
+
+func (h *ListModelsHandler) Handle(c *gin.Context) {
+	accountID, ok := request.AccountID(c)
+	if !ok {
+		return
+	}
+
+	models, err := h.repo.List(c.Request.Context(), accountID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp := dto.ModelsResponse{Models: make([]dto.ModelResponse, 0, len(models))}
+	for _, m := range models {
+		resp.Models = append(resp.Models, toModelResponse(m))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/lib/validator"
+	dto "flow-run/pkg/flowrunclient/model"
+
+	"github.com/google/uuid"
+)
+
+const groupModelV1 = "v1/model"
+
+type modelRepository interface {
+	Create(ctx context.Context, m *domain.Model) error
+	Get(ctx context.Context, accountID, id uuid.UUID) (*domain.Model, error)
+	List(ctx context.Context, accountID uuid.UUID) ([]*domain.Model, error)
+	Update(ctx context.Context, m *domain.Model) error
+	Delete(ctx context.Context, accountID, id uuid.UUID) error
+	ListPrices(ctx context.Context, accountID, id uuid.UUID) ([]*domain.ModelPrice, error)
+	AddPrice(ctx context.Context, price *domain.ModelPrice) error
+}
+
+func toModelResponse(m *domain.Model) dto.ModelResponse {
+	return dto.ModelResponse{
+		ID:            m.ID,
+		Name:          m.Name,
+		AccountID:     m.AccountID,
+		ProviderID:    m.ProviderID,
+		InputPrice:    m.InputPrice.String(),
+		OutputPrice:   m.OutputPrice.String(),
+		CachedPrice:   m.CachedPrice.String(),
+		ContextWindow: m.ContextWindow,
+	}
+}
+
+func toModelPriceResponse(p *domain.ModelPrice) dto.ModelPriceResponse {
+	return dto.ModelPriceResponse{
+		ID:          p.ID,
+		ModelID:     p.ModelID,
+		Source:      string(p.Source),
+		InputPrice:  p.InputPrice.String(),
+		OutputPrice: p.OutputPrice.String(),
+		CachedPrice: p.CachedPrice.String(),
+		EffectiveAt: p.EffectiveAt,
+		CreatedAt:   p.CreatedAt,
+	}
+}
+
+// modelPrices are the prices of a model request.
+type modelPrices struct {
+	input, output, cached domain.Money
+}
+
+// parsePrices parses the decimal dollar prices of a request. An empty price
+// is that of current, which is zero, i.e. unpriced, for new prices.
+func parsePrices(input, output, cached string, current modelPrices) (modelPrices, error) {
+	var fields []validator.FieldError
+	parse := func(field, value string, price domain.Money) domain.Money {
+		if value == "" {
+			return price
+		}
+		price, err := domain.ParseMoney(value)
+		if err != nil {
+			fields = append(fields, validator.FieldError{Field: field, Tag: "money", Message: err.Error()})
+		}
+		return price
+	}
+
+	prices := modelPrices{
+		input:  parse("input_price", input, current.input),
+		output: parse("output_price", output, current.output),
+		cached: parse("cached_price", cached, current.cached),
+	}
+	if len(fields) > 0 {
+		return modelPrices{}, &validator.Error{Fields: fields}
+	}
+	return prices, nil
+}
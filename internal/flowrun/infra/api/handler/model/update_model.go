@@ -0,0 +1,78 @@
+package model
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	dto "flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateModelHandler struct {
+	repo modelRepository
+}
+
+func NewUpdateModelHandler(repo modelRepository) *UpdateModelHandler {
+	return &UpdateModelHandler{repo: repo}
+}
+
+func (h *UpdateModelHandler) Group() string {
+	return groupModelV1
+}
+
+func (h *UpdateModelHandler) Method() string {
+	return http.MethodPut
+}
+
+func (h *UpdateModelHandler) Path() string {
+	return "/:id"
+}
+
+func (h *UpdateModelHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateModelRequest
+	if !request.JSON(c, &req) {
+		return
+	}
+
+	existing, err := h.repo.Get(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	// Prices left out keep those in effect, so that renaming a model or
+	// moving it to another provider does not override its synced prices.
+	current := modelPrices{input: existing.InputPrice, output: existing.OutputPrice, cached: existing.CachedPrice}
+	prices, err := parsePrices(req.InputPrice, req.OutputPrice, req.CachedPrice, current)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	m, err := domain.NewModel(
+		domain.WithModelID(existing.ID),
+		domain.WithModelName(req.Name),
+		domain.WithModelAccountID(existing.AccountID),
+		domain.WithModelProviderID(req.ProviderID),
+		domain.WithModelPrices(prices.input, prices.output),
+		domain.WithModelCachedPrice(prices.cached),
+	)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.repo.Update(c.Request.Context(), m); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toModelResponse(m))
+}
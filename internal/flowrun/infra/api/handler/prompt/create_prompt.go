@@ -0,0 +1,59 @@
+package prompt
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CreatePromptHandler struct {
+	repo promptRepository
+}
+
+func NewCreatePromptHandler(repo promptRepository) *CreatePromptHandler {
+	return &CreatePromptHandler{repo: repo}
+}
+
+func (h *CreatePromptHandler) Group() string {
+	return groupPromptV1
+}
+
+func (h *CreatePromptHandler) Method() string {
+	return http.MethodPost
+}
+
+func (h *CreatePromptHandler) Path() string {
+	return ""
+}
+
+func (h *CreatePromptHandler) Handle(c *gin.Context) {
+	var req model.CreatePromptRequest
+	if !request.JSON(c, &req) {
+		return
+	}
+
+	p, err := domain.NewPrompt(
+		domain.WithPromptID(uuid.New()),
+		domain.WithPromptAccountID(req.AccountID),
+		domain.WithPromptName(req.Name),
+		domain.WithPromptDescription(req.Description),
+		domain.WithPromptAuthor(req.Author),
+		domain.WithPromptTemplate(toPromptTemplate(req.Template)),
+	)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.repo.Create(c.Request.Context(), p); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toPromptResponse(p))
+}
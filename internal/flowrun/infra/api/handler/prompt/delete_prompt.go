@@ -0,0 +1,43 @@
+package prompt
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeletePromptHandler struct {
+	repo promptRepository
+}
+
+func NewDeletePromptHandler(repo promptRepository) *DeletePromptHandler {
+	return &DeletePromptHandler{repo: repo}
+}
+
+func (h *DeletePromptHandler) Group() string {
+	return groupPromptV1
+}
+
+func (h *DeletePromptHandler) Method() string {
+	return http.MethodDelete
+}
+
+func (h *DeletePromptHandler) Path() string {
+	return "/:id"
+}
+
+func (h *DeletePromptHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), accountID, id); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
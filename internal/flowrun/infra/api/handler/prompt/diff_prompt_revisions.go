@@ -0,0 +1,76 @@
+package prompt
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/internal/lib/diff"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DiffPromptRevisionsHandler struct {
+	repo promptRepository
+}
+
+func NewDiffPromptRevisionsHandler(repo promptRepository) *DiffPromptRevisionsHandler {
+	return &DiffPromptRevisionsHandler{repo: repo}
+}
+
+func (h *DiffPromptRevisionsHandler) Group() string {
+	return groupPromptV1
+}
+
+func (h *DiffPromptRevisionsHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *DiffPromptRevisionsHandler) Path() string {
+	return "/:id/diff"
+}
+
+// Handle diffs revision ?from= against revision ?to= of the prompt.
+func (h *DiffPromptRevisionsHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+	fromNumber, ok := request.PositiveInt(c, "from", c.Query("from"))
+	if !ok {
+		return
+	}
+	toNumber, ok := request.PositiveInt(c, "to", c.Query("to"))
+	if !ok {
+		return
+	}
+
+	from, err := h.repo.GetRevision(c.Request.Context(), accountID, id, fromNumber)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	to, err := h.repo.GetRevision(c.Request.Context(), accountID, id, toNumber)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	d := domain.DiffPromptRevisions(from, to)
+	c.JSON(http.StatusOK, model.PromptDiffResponse{
+		From:      d.From,
+		To:        d.To,
+		System:    toDiffLines(d.System),
+		User:      toDiffLines(d.User),
+		Variables: toDiffLines(d.Variables),
+	})
+}
+
+func toDiffLines(lines []diff.Line) []model.DiffLine {
+	result := make([]model.DiffLine, 0, len(lines))
+	for _, l := range lines {
+		result = append(result, model.DiffLine{Op: string(l.Op), Text: l.Text, OldLine: l.OldLine, NewLine: l.NewLine})
+	}
+	return result
+}
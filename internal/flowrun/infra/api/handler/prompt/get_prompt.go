@@ -0,0 +1,44 @@
+package prompt
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetPromptHandler struct {
+	repo promptRepository
+}
+
+func NewGetPromptHandler(repo promptRepository) *GetPromptHandler {
+	return &GetPromptHandler{repo: repo}
+}
+
+func (h *GetPromptHandler) Group() string {
+	return groupPromptV1
+}
+
+func (h *GetPromptHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *GetPromptHandler) Path() string {
+	return "/:id"
+}
+
+func (h *GetPromptHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	p, err := h.repo.Get(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toPromptResponse(p))
+}
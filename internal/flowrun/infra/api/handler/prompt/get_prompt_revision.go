@@ -0,0 +1,48 @@
+package prompt
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetPromptRevisionHandler struct {
+	repo promptRepository
+}
+
+func NewGetPromptRevisionHandler(repo promptRepository) *GetPromptRevisionHandler {
+	return &GetPromptRevisionHandler{repo: repo}
+}
+
+func (h *GetPromptRevisionHandler) Group() string {
+	return groupPromptV1
+}
+
+func (h *GetPromptRevisionHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *GetPromptRevisionHandler) Path() string {
+	return "/:id/revisions/:revision"
+}
+
+func (h *GetPromptRevisionHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+	number, ok := request.PositiveInt(c, "revision", c.Param("revision"))
+	if !ok {
+		return
+	}
+
+	revision, err := h.repo.GetRevision(c.Request.Context(), accountID, id, number)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toPromptRevisionResponse(revision))
+}
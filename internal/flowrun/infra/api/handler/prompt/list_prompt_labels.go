@@ -0,0 +1,54 @@
+package prompt
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListPromptLabelsHandler struct {
+	repo promptRepository
+}
+
+func NewListPromptLabelsHandler(repo promptRepository) *ListPromptLabelsHandler {
+	return &ListPromptLabelsHandler{repo: repo}
+}
+
+func (h *ListPromptLabelsHandler) Group() string {
+	return groupPromptV1
+}
+
+func (h *ListPromptLabelsHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *ListPromptLabelsHandler) Path() string {
+	return "/:id/labels"
+}
+
+func (h *ListPromptLabelsHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.repo.Get(c.Request.Context(), accountID, id); err != nil {
+		response.Error(c, err)
+		return
+	}
+	labels, err := h.repo.ListLabels(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp := model.PromptLabelsResponse{Labels: make([]model.PromptLabelResponse, 0, len(labels))}
+	for _, l := range labels {
+		resp.Labels = append(resp.Labels, toPromptLabelResponse(l))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
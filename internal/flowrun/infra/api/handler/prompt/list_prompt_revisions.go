@@ -0,0 +1,54 @@
+package prompt
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListPromptRevisionsHandler struct {
+	repo promptRepository
+}
+
+func NewListPromptRevisionsHandler(repo promptRepository) *ListPromptRevisionsHandler {
+	return &ListPromptRevisionsHandler{repo: repo}
+}
+
+func (h *ListPromptRevisionsHandler) Group() string {
+	return groupPromptV1
+}
+
+func (h *ListPromptRevisionsHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *ListPromptRevisionsHandler) Path() string {
+	return "/:id/revisions"
+}
+
+func (h *ListPromptRevisionsHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.repo.Get(c.Request.Context(), accountID, id); err != nil {
+		response.Error(c, err)
+		return
+	}
+	revisions, err := h.repo.ListRevisions(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp := model.PromptRevisionsResponse{Revisions: make([]model.PromptRevisionResponse, 0, len(revisions))}
+	for _, r := range revisions {
+		resp.Revisions = append(resp.Revisions, toPromptRevisionResponse(r))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,50 @@
+package prompt
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListPromptsHandler struct {
+	repo promptRepository
+}
+
+func NewListPromptsHandler(repo promptRepository) *ListPromptsHandler {
+	return &ListPromptsHandler{repo: repo}
+}
+
+func (h *ListPromptsHandler) Group() string {
+	return groupPromptV1
+}
+
+func (h *ListPromptsHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *ListPromptsHandler) Path() string {
+	return ""
+}
+
+func (h *ListPromptsHandler) Handle(c *gin.Context) {
+	accountID, ok := request.AccountID(c)
+	if !ok {
+		return
+	}
+
+	prompts, err := h.repo.List(c.Request.Context(), accountID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp := model.PromptsResponse{Prompts: make([]model.PromptResponse, 0, len(prompts))}
+	for _, p := range prompts {
+		resp.Prompts = append(resp.Prompts, toPromptResponse(p))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,79 @@
+package prompt
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/pkg/flowrunclient/model"
+
+	"github.com/google/uuid"
+)
+
+const groupPromptV1 = "v1/prompt"
+
+type promptRepository interface {
+	Create(ctx context.Context, p *domain.Prompt) error
+	Get(ctx context.Context, accountID, id uuid.UUID) (*domain.Prompt, error)
+	List(ctx context.Context, accountID uuid.UUID) ([]*domain.Prompt, error)
+	Update(ctx context.Context, p *domain.Prompt) error
+	Delete(ctx context.Context, accountID, id uuid.UUID) error
+	ListRevisions(ctx context.Context, accountID, promptID uuid.UUID) ([]*domain.PromptRevision, error)
+	GetRevision(ctx context.Context, accountID, promptID uuid.UUID, number int) (*domain.PromptRevision, error)
+	SetLabel(ctx context.Context, label *domain.PromptLabel) error
+	ListLabels(ctx context.Context, accountID, promptID uuid.UUID) ([]*domain.PromptLabel, error)
+}
+
+func toPromptTemplate(t model.PromptTemplate) domain.PromptTemplate {
+	variables := make([]domain.FlowVariable, 0, len(t.Variables))
+	for _, v := range t.Variables {
+		variables = append(variables, domain.FlowVariable{
+			Name:        v.Name,
+			Type:        domain.ValueType(v.Type),
+			Required:    v.Required,
+			Description: v.Description,
+			Default:     v.Default,
+		})
+	}
+	return domain.PromptTemplate{System: t.System, User: t.User, Variables: variables}
+}
+
+func toPromptResponse(p *domain.Prompt) model.PromptResponse {
+	return model.PromptResponse{
+		ID:          p.ID,
+		AccountID:   p.AccountID,
+		Name:        p.Name,
+		Description: p.Description,
+		Revision:    p.Revision,
+		Author:      p.Author,
+		Template:    toPromptTemplateResponse(&p.Template),
+	}
+}
+
+func toPromptRevisionResponse(r *domain.PromptRevision) model.PromptRevisionResponse {
+	return model.PromptRevisionResponse{
+		ID:          r.ID,
+		PromptID:    r.PromptID,
+		Revision:    r.Number,
+		Author:      r.Author,
+		ContentHash: r.ContentHash,
+		Template:    toPromptTemplateResponse(&r.Template),
+		CreatedAt:   r.CreatedAt,
+	}
+}
+
+func toPromptLabelResponse(l *domain.PromptLabel) model.PromptLabelResponse {
+	return model.PromptLabelResponse{Name: l.Name, Revision: l.Revision, UpdatedAt: l.UpdatedAt}
+}
+
+func toPromptTemplateResponse(t *domain.PromptTemplate) model.PromptTemplate {
+	variables := make([]model.PromptVariable, 0, len(t.Variables))
+	for _, v := range t.Variables {
+		variables = append(variables, model.PromptVariable{
+			Name:        v.Name,
+			Type:        string(v.Type),
+			Required:    v.Required,
+			Description: v.Description,
+			Default:     v.Default,
+		})
+	}
+	return model.PromptTemplate{System: t.System, User: t.User, Variables: variables}
+}
@@ -0,0 +1,65 @@
+package prompt
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SetPromptLabelHandler struct {
+	repo promptRepository
+}
+
+func NewSetPromptLabelHandler(repo promptRepository) *SetPromptLabelHandler {
+	return &SetPromptLabelHandler{repo: repo}
+}
+
+func (h *SetPromptLabelHandler) Group() string {
+	return groupPromptV1
+}
+
+func (h *SetPromptLabelHandler) Method() string {
+	return http.MethodPut
+}
+
+func (h *SetPromptLabelHandler) Path() string {
+	return "/:id/labels/:label"
+}
+
+// Handle creates the label or moves it to another revision. Flows following
+// the label run the new revision from their next run on.
+func (h *SetPromptLabelHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	var req model.SetPromptLabelRequest
+	if !request.JSON(c, &req) {
+		return
+	}
+
+	p, err := h.repo.Get(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	label, err := domain.NewPromptLabel(p, c.Param("label"), req.Revision, time.Now())
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.repo.SetLabel(c.Request.Context(), label); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toPromptLabelResponse(label))
+}
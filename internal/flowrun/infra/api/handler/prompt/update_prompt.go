@@ -0,0 +1,69 @@
+package prompt
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdatePromptHandler struct {
+	repo promptRepository
+}
+
+func NewUpdatePromptHandler(repo promptRepository) *UpdatePromptHandler {
+	return &UpdatePromptHandler{repo: repo}
+}
+
+func (h *UpdatePromptHandler) Group() string {
+	return groupPromptV1
+}
+
+func (h *UpdatePromptHandler) Method() string {
+	return http.MethodPut
+}
+
+func (h *UpdatePromptHandler) Path() string {
+	return "/:id"
+}
+
+func (h *UpdatePromptHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdatePromptRequest
+	if !request.JSON(c, &req) {
+		return
+	}
+
+	existing, err := h.repo.Get(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	p, err := domain.NewPrompt(
+		domain.WithPromptID(existing.ID),
+		domain.WithPromptAccountID(existing.AccountID),
+		domain.WithPromptName(req.Name),
+		domain.WithPromptDescription(req.Description),
+		domain.WithPromptAuthor(req.Author),
+		domain.WithPromptTemplate(toPromptTemplate(req.Template)),
+	)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.repo.Update(c.Request.Context(), p); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toPromptResponse(p))
+}
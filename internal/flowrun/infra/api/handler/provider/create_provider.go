@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CreateProviderHandler struct {
+	repo providerRepository
+}
+
+func NewCreateProviderHandler(repo providerRepository) *CreateProviderHandler {
+	return &CreateProviderHandler{repo: repo}
+}
+
+func (h *CreateProviderHandler) Group() string {
+	return groupProviderV1
+}
+
+func (h *CreateProviderHandler) Method() string {
+	return http.MethodPost
+}
+
+func (h *CreateProviderHandler) Path() string {
+	return ""
+}
+
+func (h *CreateProviderHandler) Handle(c *gin.Context) {
+	var req model.CreateProviderRequest
+	if !request.JSON(c, &req) {
+		return
+	}
+
+	p, err := domain.NewProvider(
+		domain.WithProviderID(uuid.New()),
+		domain.WithProviderName(req.Name),
+		domain.WithProviderAccountID(req.AccountID),
+		domain.WithProviderType(domain.ProviderType(req.Type)),
+		domain.WithProviderApiKey(req.ApiKey),
+		domain.WithProviderBaseURL(req.BaseURL),
+	)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.repo.Create(c.Request.Context(), p); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toProviderResponse(p))
+}
@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeleteProviderHandler struct {
+	repo providerRepository
+}
+
+func NewDeleteProviderHandler(repo providerRepository) *DeleteProviderHandler {
+	return &DeleteProviderHandler{repo: repo}
+}
+
+func (h *DeleteProviderHandler) Group() string {
+	return groupProviderV1
+}
+
+func (h *DeleteProviderHandler) Method() string {
+	return http.MethodDelete
+}
+
+func (h *DeleteProviderHandler) Path() string {
+	return "/:id"
+}
+
+func (h *DeleteProviderHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), accountID, id); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -1,8 +1,44 @@
 package provider
 
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
 type GetProviderHandler struct {
+	repo providerRepository
+}
+
+func NewGetProviderHandler(repo providerRepository) *GetProviderHandler {
+	return &GetProviderHandler{repo: repo}
 }
 
-func (g *GetProviderHandler) Group() string {
+func (h *GetProviderHandler) Group() string {
 	return groupProviderV1
 }
+
+func (h *GetProviderHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *GetProviderHandler) Path() string {
+	return "/:id"
+}
+
+func (h *GetProviderHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	p, err := h.repo.Get(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toProviderResponse(p))
+}
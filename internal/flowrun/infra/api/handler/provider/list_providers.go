@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListProvidersHandler struct {
+	repo providerRepository
+}
+
+func NewListProvidersHandler(repo providerRepository) *ListProvidersHandler {
+	return &ListProvidersHandler{repo: repo}
+}
+
+func (h *ListProvidersHandler) Group() string {
+	return groupProviderV1
+}
+
+func (h *ListProvidersHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *ListProvidersHandler) Path() string {
+	return ""
+}
+
+func (h *ListProvidersHandler) Handle(c *gin.Context) {
+	accountID, ok := request.AccountID(c)
+	if !ok {
+		return
+	}
+
+	providers, err := h.repo.List(c.Request.Context(), accountID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp := model.ProvidersResponse{Providers: make([]model.ProviderResponse, 0, len(providers))}
+	for _, p := range providers {
+		resp.Providers = append(resp.Providers, toProviderResponse(p))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
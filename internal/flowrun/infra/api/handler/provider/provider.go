@@ -1,3 +1,29 @@
 package provider
 
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/pkg/flowrunclient/model"
+
+	"github.com/google/uuid"
+)
+
 const groupProviderV1 = "v1/provider"
+
+type providerRepository interface {
+	Create(ctx context.Context, p *domain.Provider) error
+	Get(ctx context.Context, accountID, id uuid.UUID) (*domain.Provider, error)
+	List(ctx context.Context, accountID uuid.UUID) ([]*domain.Provider, error)
+	Update(ctx context.Context, p *domain.Provider) error
+	Delete(ctx context.Context, accountID, id uuid.UUID) error
+}
+
+func toProviderResponse(p *domain.Provider) model.ProviderResponse {
+	return model.ProviderResponse{
+		ID:        p.ID,
+		Name:      p.Name,
+		AccountID: p.AccountID,
+		Type:      string(p.Type),
+		BaseURL:   p.BaseURL,
+	}
+}
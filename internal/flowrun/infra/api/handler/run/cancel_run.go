@@ -0,0 +1,54 @@
+package run
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CancelRunHandler struct {
+	runs     runRepository
+	canceler runCanceler
+}
+
+func NewCancelRunHandler(runs runRepository, canceler runCanceler) *CancelRunHandler {
+	return &CancelRunHandler{runs: runs, canceler: canceler}
+}
+
+func (h *CancelRunHandler) Group() string {
+	return groupRunV1
+}
+
+func (h *CancelRunHandler) Method() string {
+	return http.MethodPost
+}
+
+func (h *CancelRunHandler) Path() string {
+	return "/:id/cancel"
+}
+
+// Handle cancels a flow run. A pending run is canceled by the time it
+// responds; a running run only has its cancellation requested, and stays
+// running until its engine stops it. Runs that finished otherwise are a
+// conflict.
+func (h *CancelRunHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	run, err := h.canceler.Cancel(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	steps, err := h.runs.ListStepRuns(c.Request.Context(), run.ID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toFlowRunResponse(run, steps))
+}
@@ -0,0 +1,51 @@
+package run
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetRunHandler struct {
+	runs runRepository
+}
+
+func NewGetRunHandler(runs runRepository) *GetRunHandler {
+	return &GetRunHandler{runs: runs}
+}
+
+func (h *GetRunHandler) Group() string {
+	return groupRunV1
+}
+
+func (h *GetRunHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *GetRunHandler) Path() string {
+	return "/:id"
+}
+
+// Handle returns a flow run as it stands, together with its steps. Clients
+// poll it until the run has finished.
+func (h *GetRunHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	run, err := h.runs.GetRun(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	steps, err := h.runs.ListStepRuns(c.Request.Context(), run.ID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toFlowRunResponse(run, steps))
+}
@@ -0,0 +1,65 @@
+package run
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/pkg/flowrunclient/model"
+
+	"github.com/google/uuid"
+)
+
+const groupRunV1 = "v1/run"
+
+type runRepository interface {
+	GetRun(ctx context.Context, accountID, id uuid.UUID) (*domain.FlowRun, error)
+	ListStepRuns(ctx context.Context, runID uuid.UUID) ([]*domain.StepRun, error)
+}
+
+type runCanceler interface {
+	Cancel(ctx context.Context, accountID, id uuid.UUID) (*domain.FlowRun, error)
+}
+
+func toFlowRunResponse(r *domain.FlowRun, steps []*domain.StepRun) model.FlowRunResponse {
+	resp := model.FlowRunResponse{
+		ID:                r.ID,
+		FlowID:            r.FlowID,
+		AccountID:         r.AccountID,
+		Model:             r.Model,
+		Status:            string(r.Status),
+		Inputs:            r.Inputs,
+		Outputs:           r.Outputs,
+		Error:             r.Error,
+		Usage:             toTokenUsage(r.Usage),
+		Cost:              r.Cost.String(),
+		CreatedAt:         r.CreatedAt,
+		StartedAt:         r.StartedAt,
+		FinishedAt:        r.FinishedAt,
+		CancelRequestedAt: r.CancelRequestedAt,
+	}
+	for _, step := range steps {
+		resp.Steps = append(resp.Steps, model.StepRunResponse{
+			StepID:     step.StepID,
+			Type:       string(step.Type),
+			Status:     string(step.Status),
+			Inputs:     step.Inputs,
+			Output:     step.Output,
+			Error:      step.Error,
+			Usage:      toTokenUsage(step.Usage),
+			Cost:       step.Cost.String(),
+			ChildRunID: step.ChildRunID,
+			StartedAt:  step.StartedAt,
+			FinishedAt: step.FinishedAt,
+		})
+	}
+	return resp
+}
+
+func toTokenUsage(u domain.TokenUsage) model.TokenUsage {
+	return model.TokenUsage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+		CacheReadTokens:  u.CacheReadTokens,
+		CacheWriteTokens: u.CacheWriteTokens,
+	}
+}
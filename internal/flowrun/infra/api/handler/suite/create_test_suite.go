@@ -0,0 +1,62 @@
+package suite
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CreateTestSuiteHandler struct {
+	repo testSuiteRepository
+}
+
+func NewCreateTestSuiteHandler(repo testSuiteRepository) *CreateTestSuiteHandler {
+	return &CreateTestSuiteHandler{repo: repo}
+}
+
+func (h *CreateTestSuiteHandler) Group() string {
+	return groupTestSuiteV1
+}
+
+func (h *CreateTestSuiteHandler) Method() string {
+	return http.MethodPost
+}
+
+func (h *CreateTestSuiteHandler) Path() string {
+	return ""
+}
+
+func (h *CreateTestSuiteHandler) Handle(c *gin.Context) {
+	var req model.CreateTestSuiteRequest
+	if !request.JSON(c, &req) {
+		return
+	}
+
+	s, err := domain.NewTestSuite(
+		domain.WithTestSuiteID(uuid.New()),
+		domain.WithTestSuiteAccountID(req.AccountID),
+		domain.WithTestSuiteName(req.Name),
+		domain.WithTestSuiteDescription(req.Description),
+		domain.WithTestSuitePrompt(req.Prompt),
+		domain.WithTestSuiteFlow(req.Flow),
+		domain.WithTestSuiteCases(toTestCases(req.Cases)...),
+		domain.WithTestSuiteDataset(req.Dataset, req.DatasetVersion),
+		domain.WithTestSuiteAssertions(toAssertions(req.Assertions)...),
+	)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.repo.Create(c.Request.Context(), s); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toTestSuiteResponse(s))
+}
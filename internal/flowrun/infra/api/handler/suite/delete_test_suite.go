@@ -0,0 +1,43 @@
+package suite
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeleteTestSuiteHandler struct {
+	repo testSuiteRepository
+}
+
+func NewDeleteTestSuiteHandler(repo testSuiteRepository) *DeleteTestSuiteHandler {
+	return &DeleteTestSuiteHandler{repo: repo}
+}
+
+func (h *DeleteTestSuiteHandler) Group() string {
+	return groupTestSuiteV1
+}
+
+func (h *DeleteTestSuiteHandler) Method() string {
+	return http.MethodDelete
+}
+
+func (h *DeleteTestSuiteHandler) Path() string {
+	return "/:id"
+}
+
+func (h *DeleteTestSuiteHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), accountID, id); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
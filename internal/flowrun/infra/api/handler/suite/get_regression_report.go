@@ -0,0 +1,89 @@
+package suite
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetRegressionReportHandler compares two finished runs of a suite, given by
+// the baseline and candidate query parameters.
+type GetRegressionReportHandler struct {
+	runs testSuiteRunRepository
+}
+
+func NewGetRegressionReportHandler(runs testSuiteRunRepository) *GetRegressionReportHandler {
+	return &GetRegressionReportHandler{runs: runs}
+}
+
+func (h *GetRegressionReportHandler) Group() string {
+	return groupTestSuiteV1
+}
+
+func (h *GetRegressionReportHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *GetRegressionReportHandler) Path() string {
+	return "/:id/regression"
+}
+
+func (h *GetRegressionReportHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+	baselineID, ok := request.QueryID(c, "baseline")
+	if !ok {
+		return
+	}
+	candidateID, ok := request.QueryID(c, "candidate")
+	if !ok {
+		return
+	}
+
+	baseline, baselineCases, err := h.loadRun(c.Request.Context(), accountID, id, "baseline", baselineID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	candidate, candidateCases, err := h.loadRun(c.Request.Context(), accountID, id, "candidate", candidateID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	report, err := domain.NewRegressionReport(baseline, baselineCases, candidate, candidateCases)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toRegressionReportResponse(report))
+}
+
+// loadRun reads a run of the suite with its case results. A run that does
+// not exist is reported on field.
+func (h *GetRegressionReportHandler) loadRun(
+	ctx context.Context, accountID, suiteID uuid.UUID, field string, id uuid.UUID,
+) (*domain.TestSuiteRun, []*domain.TestCaseResult, error) {
+	run, err := h.runs.GetSuiteRun(ctx, accountID, suiteID, id)
+	if errors.Is(err, port.ErrNotFound) {
+		return nil, nil, &port.ReferenceError{Field: field, Value: id.String()}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	results, err := h.runs.ListCaseResults(ctx, run.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return run, results, nil
+}
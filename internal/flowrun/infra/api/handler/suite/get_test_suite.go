@@ -0,0 +1,44 @@
+package suite
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetTestSuiteHandler struct {
+	repo testSuiteRepository
+}
+
+func NewGetTestSuiteHandler(repo testSuiteRepository) *GetTestSuiteHandler {
+	return &GetTestSuiteHandler{repo: repo}
+}
+
+func (h *GetTestSuiteHandler) Group() string {
+	return groupTestSuiteV1
+}
+
+func (h *GetTestSuiteHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *GetTestSuiteHandler) Path() string {
+	return "/:id"
+}
+
+func (h *GetTestSuiteHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	s, err := h.repo.Get(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toTestSuiteResponse(s))
+}
@@ -0,0 +1,54 @@
+package suite
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetTestSuiteRunHandler struct {
+	runs testSuiteRunRepository
+}
+
+func NewGetTestSuiteRunHandler(runs testSuiteRunRepository) *GetTestSuiteRunHandler {
+	return &GetTestSuiteRunHandler{runs: runs}
+}
+
+func (h *GetTestSuiteRunHandler) Group() string {
+	return groupTestSuiteV1
+}
+
+func (h *GetTestSuiteRunHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *GetTestSuiteRunHandler) Path() string {
+	return "/:id/runs/:run_id"
+}
+
+// Handle returns a run of the suite together with its case results.
+func (h *GetTestSuiteRunHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+	runID, ok := request.PathID(c, "run_id")
+	if !ok {
+		return
+	}
+
+	run, err := h.runs.GetSuiteRun(c.Request.Context(), accountID, id, runID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	results, err := h.runs.ListCaseResults(c.Request.Context(), run.ID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toTestSuiteRunResponse(run, results))
+}
@@ -0,0 +1,56 @@
+package suite
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListTestSuiteRunsHandler struct {
+	repo testSuiteRepository
+	runs testSuiteRunRepository
+}
+
+func NewListTestSuiteRunsHandler(repo testSuiteRepository, runs testSuiteRunRepository) *ListTestSuiteRunsHandler {
+	return &ListTestSuiteRunsHandler{repo: repo, runs: runs}
+}
+
+func (h *ListTestSuiteRunsHandler) Group() string {
+	return groupTestSuiteV1
+}
+
+func (h *ListTestSuiteRunsHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *ListTestSuiteRunsHandler) Path() string {
+	return "/:id/runs"
+}
+
+// Handle lists the runs of the suite, newest first, without their cases.
+func (h *ListTestSuiteRunsHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.repo.Get(c.Request.Context(), accountID, id); err != nil {
+		response.Error(c, err)
+		return
+	}
+	runs, err := h.runs.ListSuiteRuns(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp := model.TestSuiteRunsResponse{Runs: make([]model.TestSuiteRunResponse, 0, len(runs))}
+	for _, r := range runs {
+		resp.Runs = append(resp.Runs, toTestSuiteRunResponse(r, nil))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,50 @@
+package suite
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ListTestSuitesHandler struct {
+	repo testSuiteRepository
+}
+
+func NewListTestSuitesHandler(repo testSuiteRepository) *ListTestSuitesHandler {
+	return &ListTestSuitesHandler{repo: repo}
+}
+
+func (h *ListTestSuitesHandler) Group() string {
+	return groupTestSuiteV1
+}
+
+func (h *ListTestSuitesHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *ListTestSuitesHandler) Path() string {
+	return ""
+}
+
+func (h *ListTestSuitesHandler) Handle(c *gin.Context) {
+	accountID, ok := request.AccountID(c)
+	if !ok {
+		return
+	}
+
+	suites, err := h.repo.List(c.Request.Context(), accountID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp := model.TestSuitesResponse{Suites: make([]model.TestSuiteResponse, 0, len(suites))}
+	for _, s := range suites {
+		resp.Suites = append(resp.Suites, toTestSuiteResponse(s))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,71 @@
+package suite
+
+import (
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RunRegressionHandler queues runs of a suite against a baseline and a
+// candidate target and responds with both while they are pending. Once both
+// have succeeded, GetRegressionReportHandler compares them.
+type RunRegressionHandler struct {
+	repo   testSuiteRepository
+	runner suiteRunner
+}
+
+func NewRunRegressionHandler(repo testSuiteRepository, runner suiteRunner) *RunRegressionHandler {
+	return &RunRegressionHandler{repo: repo, runner: runner}
+}
+
+func (h *RunRegressionHandler) Group() string {
+	return groupTestSuiteV1
+}
+
+func (h *RunRegressionHandler) Method() string {
+	return http.MethodPost
+}
+
+func (h *RunRegressionHandler) Path() string {
+	return "/:id/regression"
+}
+
+func (h *RunRegressionHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	var req model.RunRegressionRequest
+	if !request.JSON(c, &req) {
+		return
+	}
+
+	s, err := h.repo.Get(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	err = checkTargets(s, targetRequest{"baseline.", &req.Baseline}, targetRequest{"candidate.", &req.Candidate})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	baseline, candidate, err := h.runner.Compare(
+		c.Request.Context(), s, toTarget(&req.Baseline), toTarget(&req.Candidate),
+	)
+	if err != nil {
+		response.Error(c, runError(err))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, model.RegressionRunResponse{
+		SuiteID:   s.ID,
+		Baseline:  toTestSuiteRunResponse(baseline, nil),
+		Candidate: toTestSuiteRunResponse(candidate, nil),
+	})
+}
@@ -0,0 +1,120 @@
+package suite
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/internal/lib/validator"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RunTestSuiteHandler struct {
+	repo   testSuiteRepository
+	runner suiteRunner
+}
+
+func NewRunTestSuiteHandler(repo testSuiteRepository, runner suiteRunner) *RunTestSuiteHandler {
+	return &RunTestSuiteHandler{repo: repo, runner: runner}
+}
+
+func (h *RunTestSuiteHandler) Group() string {
+	return groupTestSuiteV1
+}
+
+func (h *RunTestSuiteHandler) Method() string {
+	return http.MethodPost
+}
+
+func (h *RunTestSuiteHandler) Path() string {
+	return "/:id/run"
+}
+
+// Handle queues a run of the suite and responds with it while it is pending;
+// the run and its case results are fetched once it has finished.
+func (h *RunTestSuiteHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	var req model.RunTestSuiteRequest
+	if !request.JSON(c, &req) {
+		return
+	}
+
+	s, err := h.repo.Get(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	if err := checkTargets(s, targetRequest{"", &req}); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	run, err := h.runner.Submit(c.Request.Context(), s, toTarget(&req))
+	if err != nil {
+		response.Error(c, runError(err))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, toTestSuiteRunResponse(run, nil))
+}
+
+// targetRequest is a run request whose fields are reported prefixed with
+// prefix, e.g. "baseline.".
+type targetRequest struct {
+	prefix string
+	req    *model.RunTestSuiteRequest
+}
+
+// checkTargets validates run requests: a model is required, only prompt
+// suites pick a revision, by number or label, and only dataset suites pick a
+// dataset version.
+func checkTargets(s *domain.TestSuite, targets ...targetRequest) error {
+	var fields []validator.FieldError
+	for _, target := range targets {
+		for _, field := range checkTarget(s, target.req) {
+			field.Field = target.prefix + field.Field
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) > 0 {
+		return &validator.Error{Fields: fields}
+	}
+	return nil
+}
+
+func checkTarget(s *domain.TestSuite, req *model.RunTestSuiteRequest) []validator.FieldError {
+	var fields []validator.FieldError
+	if req.Model == "" {
+		fields = append(fields, validator.FieldError{Field: "model", Tag: "required", Message: "model is required"})
+	}
+	if req.Revision < 0 {
+		fields = append(fields, validator.FieldError{Field: "revision", Tag: "min", Message: "revision must be positive"})
+	}
+	if req.Revision > 0 && req.Label != "" {
+		fields = append(fields, validator.FieldError{
+			Field: "label", Tag: "excluded_with", Message: "label cannot be combined with revision",
+		})
+	}
+	if s.Flow != "" && (req.Revision != 0 || req.Label != "") {
+		fields = append(fields, validator.FieldError{
+			Field: "revision", Tag: "excluded", Message: "revision and label only apply to prompt suites",
+		})
+	}
+	if req.DatasetVersion < 0 {
+		fields = append(fields, validator.FieldError{
+			Field: "dataset_version", Tag: "min", Message: "dataset_version must be positive",
+		})
+	}
+	if s.Dataset == "" && req.DatasetVersion != 0 {
+		fields = append(fields, validator.FieldError{
+			Field: "dataset_version", Tag: "excluded", Message: "dataset_version only applies to dataset suites",
+		})
+	}
+	return fields
+}
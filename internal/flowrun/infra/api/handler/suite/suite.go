@@ -0,0 +1,250 @@
+package suite
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/evaluation"
+	"flow-run/internal/core/port"
+	"flow-run/pkg/flowrunclient/model"
+
+	"github.com/google/uuid"
+)
+
+const groupTestSuiteV1 = "v1/test-suite"
+
+type testSuiteRepository interface {
+	Create(ctx context.Context, s *domain.TestSuite) error
+	Get(ctx context.Context, accountID, id uuid.UUID) (*domain.TestSuite, error)
+	List(ctx context.Context, accountID uuid.UUID) ([]*domain.TestSuite, error)
+	Update(ctx context.Context, s *domain.TestSuite) error
+	Delete(ctx context.Context, accountID, id uuid.UUID) error
+}
+
+type testSuiteRunRepository interface {
+	GetSuiteRun(ctx context.Context, accountID, suiteID, id uuid.UUID) (*domain.TestSuiteRun, error)
+	ListSuiteRuns(ctx context.Context, accountID, suiteID uuid.UUID) ([]*domain.TestSuiteRun, error)
+	ListCaseResults(ctx context.Context, runID uuid.UUID) ([]*domain.TestCaseResult, error)
+}
+
+type suiteRunner interface {
+	Submit(ctx context.Context, suite *domain.TestSuite, target evaluation.Target) (*domain.TestSuiteRun, error)
+	Compare(
+		ctx context.Context, suite *domain.TestSuite, baseline, candidate evaluation.Target,
+	) (*domain.TestSuiteRun, *domain.TestSuiteRun, error)
+}
+
+// runError reports targets that do not exist like other missing references,
+// as a 400 on the field of the run request.
+func runError(err error) error {
+	var targetErr *evaluation.TargetError
+	if errors.As(err, &targetErr) && errors.Is(err, port.ErrNotFound) {
+		return &port.ReferenceError{Field: targetErr.Field, Value: targetErr.Value}
+	}
+	return err
+}
+
+func toTarget(req *model.RunTestSuiteRequest) evaluation.Target {
+	return evaluation.Target{
+		Model:          req.Model,
+		Revision:       req.Revision,
+		Label:          req.Label,
+		DatasetVersion: req.DatasetVersion,
+	}
+}
+
+func toTestCases(cases []model.TestCase) []domain.TestCase {
+	result := make([]domain.TestCase, 0, len(cases))
+	for _, tc := range cases {
+		result = append(result, domain.TestCase{
+			Name:       tc.Name,
+			Inputs:     tc.Inputs,
+			Expected:   tc.Expected,
+			Assertions: toAssertions(tc.Assertions),
+		})
+	}
+	return result
+}
+
+func toAssertions(assertions []model.Assertion) []domain.Assertion {
+	result := make([]domain.Assertion, 0, len(assertions))
+	for _, a := range assertions {
+		result = append(result, domain.Assertion{
+			Type:      domain.AssertionType(a.Type),
+			Value:     a.Value,
+			Path:      a.Path,
+			Expected:  a.Expected,
+			Schema:    a.Schema,
+			Model:     a.Model,
+			Rubric:    a.Rubric,
+			Threshold: a.Threshold,
+		})
+	}
+	return result
+}
+
+func toTestSuiteResponse(s *domain.TestSuite) model.TestSuiteResponse {
+	cases := make([]model.TestCase, 0, len(s.Cases))
+	for _, tc := range s.Cases {
+		cases = append(cases, model.TestCase{
+			Name:       tc.Name,
+			Inputs:     tc.Inputs,
+			Expected:   tc.Expected,
+			Assertions: toAssertionModels(tc.Assertions),
+		})
+	}
+	resp := model.TestSuiteResponse{
+		ID:             s.ID,
+		AccountID:      s.AccountID,
+		Name:           s.Name,
+		Description:    s.Description,
+		Prompt:         s.Prompt,
+		Flow:           s.Flow,
+		Cases:          cases,
+		Dataset:        s.Dataset,
+		DatasetVersion: s.DatasetVersion,
+	}
+	if len(s.Assertions) > 0 {
+		resp.Assertions = toAssertionModels(s.Assertions)
+	}
+	return resp
+}
+
+func toAssertionModels(assertions []domain.Assertion) []model.Assertion {
+	result := make([]model.Assertion, 0, len(assertions))
+	for _, a := range assertions {
+		result = append(result, model.Assertion{
+			Type:      string(a.Type),
+			Value:     a.Value,
+			Path:      a.Path,
+			Expected:  a.Expected,
+			Schema:    a.Schema,
+			Model:     a.Model,
+			Rubric:    a.Rubric,
+			Threshold: a.Threshold,
+		})
+	}
+	return result
+}
+
+func toTestSuiteRunResponse(r *domain.TestSuiteRun, results []*domain.TestCaseResult) model.TestSuiteRunResponse {
+	resp := model.TestSuiteRunResponse{
+		ID:             r.ID,
+		SuiteID:        r.SuiteID,
+		Model:          r.Model,
+		PromptRevision: r.PromptRevision,
+		DatasetVersion: r.DatasetVersion,
+		Status:         string(r.Status),
+		Error:          r.Error,
+		Passed:         r.Passed,
+		Failed:         r.Failed,
+		Usage:          toTokenUsage(r.Usage),
+		Cost:           r.Cost.String(),
+		JudgeUsage:     toTokenUsage(r.JudgeUsage),
+		JudgeCost:      r.JudgeCost.String(),
+		CreatedAt:      r.CreatedAt,
+		StartedAt:      r.StartedAt,
+		FinishedAt:     r.FinishedAt,
+	}
+	for _, result := range results {
+		assertions := make([]model.AssertionResult, 0, len(result.Assertions))
+		for _, a := range result.Assertions {
+			assertions = append(assertions, model.AssertionResult{
+				Type:      string(a.Type),
+				Passed:    a.Passed,
+				Message:   a.Message,
+				Score:     a.Score,
+				Reasoning: a.Reasoning,
+			})
+		}
+		resp.Cases = append(resp.Cases, model.TestCaseResultResponse{
+			Position:   result.Position,
+			Name:       result.Name,
+			Passed:     result.Passed,
+			Output:     result.Output,
+			Error:      result.Error,
+			Assertions: assertions,
+			FlowRunID:  result.FlowRunID,
+			Usage:      toTokenUsage(result.Usage),
+			Cost:       result.Cost.String(),
+			JudgeUsage: toTokenUsage(result.JudgeUsage),
+			JudgeCost:  result.JudgeCost.String(),
+			LatencyMS:  result.LatencyMS,
+		})
+	}
+	return resp
+}
+
+func toTokenUsage(u domain.TokenUsage) model.TokenUsage {
+	return model.TokenUsage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+		CacheReadTokens:  u.CacheReadTokens,
+		CacheWriteTokens: u.CacheWriteTokens,
+	}
+}
+
+func toRegressionReportResponse(r *domain.RegressionReport) model.RegressionReportResponse {
+	resp := model.RegressionReportResponse{
+		SuiteID:   r.SuiteID,
+		Baseline:  toRunSummary(r.Baseline),
+		Candidate: toRunSummary(r.Candidate),
+		Delta: model.RegressionDelta{
+			Passed:        r.Delta.Passed,
+			PassRate:      r.Delta.PassRate,
+			Score:         r.Delta.Score,
+			Tokens:        r.Delta.Tokens,
+			Cost:          r.Delta.Cost.String(),
+			MeanLatencyMS: r.Delta.MeanLatencyMS,
+		},
+		Flipped: []model.CaseComparison{},
+		Cases:   make([]model.CaseComparison, 0, len(r.Cases)),
+	}
+	for _, c := range r.Cases {
+		comparison := model.CaseComparison{
+			Position:  c.Position,
+			Name:      c.Name,
+			Change:    string(c.Change),
+			Baseline:  toCaseOutcome(c.Baseline),
+			Candidate: toCaseOutcome(c.Candidate),
+		}
+		resp.Cases = append(resp.Cases, comparison)
+		if c.Flipped() {
+			resp.Flipped = append(resp.Flipped, comparison)
+		}
+	}
+	return resp
+}
+
+func toRunSummary(s domain.RunSummary) model.RunSummary {
+	return model.RunSummary{
+		RunID:          s.RunID,
+		Model:          s.Model,
+		PromptRevision: s.PromptRevision,
+		DatasetVersion: s.DatasetVersion,
+		Cases:          s.Cases,
+		Passed:         s.Passed,
+		Failed:         s.Failed,
+		PassRate:       s.PassRate,
+		Score:          s.Score,
+		Usage:          toTokenUsage(s.Usage),
+		Cost:           s.Cost.String(),
+		LatencyMS:      s.LatencyMS,
+		MeanLatencyMS:  s.MeanLatencyMS,
+	}
+}
+
+func toCaseOutcome(o *domain.CaseOutcome) *model.CaseOutcome {
+	if o == nil {
+		return nil
+	}
+	return &model.CaseOutcome{
+		Passed:    o.Passed,
+		Score:     o.Score,
+		Error:     o.Error,
+		Cost:      o.Cost.String(),
+		LatencyMS: o.LatencyMS,
+		Failures:  o.Failures,
+	}
+}
@@ -0,0 +1,72 @@
+package suite
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateTestSuiteHandler struct {
+	repo testSuiteRepository
+}
+
+func NewUpdateTestSuiteHandler(repo testSuiteRepository) *UpdateTestSuiteHandler {
+	return &UpdateTestSuiteHandler{repo: repo}
+}
+
+func (h *UpdateTestSuiteHandler) Group() string {
+	return groupTestSuiteV1
+}
+
+func (h *UpdateTestSuiteHandler) Method() string {
+	return http.MethodPut
+}
+
+func (h *UpdateTestSuiteHandler) Path() string {
+	return "/:id"
+}
+
+func (h *UpdateTestSuiteHandler) Handle(c *gin.Context) {
+	accountID, id, ok := request.AccountResource(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateTestSuiteRequest
+	if !request.JSON(c, &req) {
+		return
+	}
+
+	existing, err := h.repo.Get(c.Request.Context(), accountID, id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	s, err := domain.NewTestSuite(
+		domain.WithTestSuiteID(existing.ID),
+		domain.WithTestSuiteAccountID(existing.AccountID),
+		domain.WithTestSuiteName(req.Name),
+		domain.WithTestSuiteDescription(req.Description),
+		domain.WithTestSuitePrompt(req.Prompt),
+		domain.WithTestSuiteFlow(req.Flow),
+		domain.WithTestSuiteCases(toTestCases(req.Cases)...),
+		domain.WithTestSuiteDataset(req.Dataset, req.DatasetVersion),
+		domain.WithTestSuiteAssertions(toAssertions(req.Assertions)...),
+	)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.repo.Update(c.Request.Context(), s); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toTestSuiteResponse(s))
+}
@@ -0,0 +1,69 @@
+package usage
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/api/request"
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultRecordLimit = 100
+	maxRecordLimit     = 1000
+)
+
+// ListUsageHandler pages through the usage ledger of an account, optionally
+// narrowed to a flow or a run with the flow_id and run_id query parameters.
+type ListUsageHandler struct {
+	repo usageRepository
+}
+
+func NewListUsageHandler(repo usageRepository) *ListUsageHandler {
+	return &ListUsageHandler{repo: repo}
+}
+
+func (h *ListUsageHandler) Group() string {
+	return groupUsageV1
+}
+
+func (h *ListUsageHandler) Method() string {
+	return http.MethodGet
+}
+
+func (h *ListUsageHandler) Path() string {
+	return ""
+}
+
+func (h *ListUsageHandler) Handle(c *gin.Context) {
+	accountID, ok := request.AccountID(c)
+	if !ok {
+		return
+	}
+	var filter domain.UsageFilter
+	if filter.FlowID, ok = request.OptionalQueryID(c, "flow_id"); !ok {
+		return
+	}
+	if filter.RunID, ok = request.OptionalQueryID(c, "run_id"); !ok {
+		return
+	}
+	offset, limit, ok := request.Page(c, defaultRecordLimit, maxRecordLimit)
+	if !ok {
+		return
+	}
+
+	records, err := h.repo.List(c.Request.Context(), accountID, filter, offset, limit)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp := model.UsageRecordsResponse{Offset: offset, Records: make([]model.UsageRecord, 0, len(records))}
+	for _, r := range records {
+		resp.Records = append(resp.Records, toUsageRecord(r))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
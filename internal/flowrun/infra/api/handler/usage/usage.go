@@ -0,0 +1,47 @@
+package usage
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	dto "flow-run/pkg/flowrunclient/model"
+
+	"github.com/google/uuid"
+)
+
+const groupUsageV1 = "v1/usage"
+
+type usageRepository interface {
+	List(
+		ctx context.Context, accountID uuid.UUID, filter domain.UsageFilter, offset, limit int,
+	) ([]*domain.UsageRecord, error)
+}
+
+func toUsageRecord(r *domain.UsageRecord) dto.UsageRecord {
+	return dto.UsageRecord{
+		ID:               r.ID,
+		AccountID:        r.AccountID,
+		ProviderID:       r.ProviderID,
+		ModelID:          r.ModelID,
+		Model:            r.Model,
+		FlowID:           r.FlowID,
+		RunID:            r.RunID,
+		StepRunID:        r.StepRunID,
+		StepID:           r.StepID,
+		PromptRevisionID: r.PromptRevisionID,
+		Purpose:          string(r.Purpose),
+		SuiteRunID:       r.SuiteRunID,
+		CasePosition:     r.CasePosition,
+		Usage: dto.TokenUsage{
+			PromptTokens:     r.Usage.PromptTokens,
+			CompletionTokens: r.Usage.CompletionTokens,
+			TotalTokens:      r.Usage.TotalTokens,
+			CacheReadTokens:  r.Usage.CacheReadTokens,
+			CacheWriteTokens: r.Usage.CacheWriteTokens,
+		},
+		InputPrice:  r.InputPrice.String(),
+		OutputPrice: r.OutputPrice.String(),
+		CachedPrice: r.CachedPrice.String(),
+		Cost:        r.Cost.String(),
+		CreatedAt:   r.CreatedAt,
+	}
+}
@@ -0,0 +1,127 @@
+package request
+
+import (
+	"flow-run/internal/flowrun/infra/api/response"
+	"flow-run/pkg/flowrunclient/model"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PathID parses the named path parameter as a UUID. It writes a 400 and
+// returns false when the parameter is malformed.
+func PathID(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		response.BadRequest(c, "invalid "+name, model.FieldError{Field: name, Tag: "uuid", Message: err.Error()})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// QueryID parses the named query parameter as a UUID. It writes a 400 and
+// returns false when the parameter is missing or malformed.
+func QueryID(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Query(name))
+	if err != nil {
+		response.BadRequest(c, "invalid "+name, model.FieldError{Field: name, Tag: "uuid", Message: err.Error()})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// OptionalQueryID is QueryID for optional parameters: a missing parameter
+// yields nil.
+func OptionalQueryID(c *gin.Context, name string) (*uuid.UUID, bool) {
+	if c.Query(name) == "" {
+		return nil, true
+	}
+	id, ok := QueryID(c, name)
+	if !ok {
+		return nil, false
+	}
+	return &id, true
+}
+
+// PositiveInt parses value, the named path or query parameter, as an integer
+// of at least 1, such as a revision number. It writes a 400 and returns false
+// otherwise.
+func PositiveInt(c *gin.Context, name, value string) (int, bool) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		response.BadRequest(c, "invalid "+name,
+			model.FieldError{Field: name, Tag: "min", Param: "1", Message: name + " must be a positive integer"})
+		return 0, false
+	}
+	return n, true
+}
+
+// Page parses the offset and limit query parameters of paged lists. Offset
+// defaults to 0 and limit to defaultLimit; a limit above maxLimit is capped.
+// It writes a 400 and returns false when either is malformed.
+func Page(c *gin.Context, defaultLimit, maxLimit int) (offset, limit int, ok bool) {
+	if value := c.Query("offset"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			response.BadRequest(c, "invalid offset",
+				model.FieldError{Field: "offset", Tag: "min", Param: "0", Message: "offset must not be negative"})
+			return 0, 0, false
+		}
+		offset = n
+	}
+
+	limit = defaultLimit
+	if value := c.Query("limit"); value != "" {
+		if limit, ok = PositiveInt(c, "limit", value); !ok {
+			return 0, 0, false
+		}
+	}
+	return offset, min(limit, maxLimit), true
+}
+
+// QueryTime parses the named query parameter as an RFC 3339 timestamp. It
+// writes a 400 and returns false when it is missing or malformed.
+func QueryTime(c *gin.Context, name string) (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339, c.Query(name))
+	if err != nil {
+		response.BadRequest(c, "invalid "+name,
+			model.FieldError{Field: name, Tag: "datetime", Param: time.RFC3339, Message: err.Error()})
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// AccountID parses the account_id query parameter that scopes every
+// account-owned resource. It writes a 400 and returns false when it is missing
+// or malformed.
+func AccountID(c *gin.Context) (uuid.UUID, bool) {
+	accountID, err := uuid.Parse(c.Query("account_id"))
+	if err != nil {
+		response.BadRequest(c, "invalid account_id", model.FieldError{Field: "account_id", Tag: "uuid", Message: err.Error()})
+		return uuid.Nil, false
+	}
+	return accountID, true
+}
+
+// AccountResource combines AccountID and PathID for the "id" parameter.
+func AccountResource(c *gin.Context) (accountID, id uuid.UUID, ok bool) {
+	if id, ok = PathID(c, "id"); !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	if accountID, ok = AccountID(c); !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	return accountID, id, true
+}
+
+// JSON binds the request body. It writes a 400 and returns false when the body
+// cannot be decoded.
+func JSON(c *gin.Context, v any) bool {
+	if err := c.ShouldBindJSON(v); err != nil {
+		response.BadRequest(c, "invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package response
+
+import (
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"flow-run/internal/lib/logger"
+	"flow-run/internal/lib/validator"
+	"flow-run/pkg/flowrunclient/model"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Error writes err as an ErrorResponse. Validation and reference errors become
+// 400 with field-level details, missing records become 404, conflicts become
+// 409, budget rejections become 402 with code budget_exceeded and everything
+// else is logged and reported as 500 without leaking the cause.
+func Error(c *gin.Context, err error) {
+	if fields, ok := validator.FieldErrors(err); ok {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse("validation failed", toFieldErrors(fields)...))
+		return
+	}
+
+	var refErr *port.ReferenceError
+	if errors.As(err, &refErr) {
+		c.JSON(http.StatusBadRequest, model.NewErrorResponse("validation failed", model.FieldError{
+			Field:   refErr.Field,
+			Tag:     "exists",
+			Message: refErr.Error(),
+		}))
+		return
+	}
+
+	if errors.Is(err, port.ErrConflict) {
+		c.JSON(http.StatusConflict, model.NewErrorResponse(err.Error()))
+		return
+	}
+
+	if errors.Is(err, domain.ErrBudgetExceeded) {
+		resp := model.NewErrorResponse(err.Error())
+		resp.Code = model.ErrorCodeBudgetExceeded
+		c.JSON(http.StatusPaymentRequired, resp)
+		return
+	}
+
+	if errors.Is(err, port.ErrNotFound) {
+		c.JSON(http.StatusNotFound, model.NewErrorResponse("not found"))
+		return
+	}
+
+	logger.WithError(err).Errorf("Request %s %s failed", c.Request.Method, c.Request.URL.Path)
+	c.JSON(http.StatusInternalServerError, model.NewErrorResponse("internal server error"))
+}
+
+// BadRequest reports a malformed request that never reached domain validation,
+// for example an unparsable body or path parameter.
+func BadRequest(c *gin.Context, message string, fields ...model.FieldError) {
+	c.JSON(http.StatusBadRequest, model.NewErrorResponse(message, fields...))
+}
+
+func toFieldErrors(fields []validator.FieldError) []model.FieldError {
+	result := make([]model.FieldError, 0, len(fields))
+	for _, f := range fields {
+		result = append(result, model.FieldError{
+			Field:   f.Field,
+			Tag:     f.Tag,
+			Param:   f.Param,
+			Message: fieldMessage(f),
+		})
+	}
+	return result
+}
+
+func fieldMessage(f validator.FieldError) string {
+	if f.Message != "" {
+		return f.Message
+	}
+	if f.Param == "" {
+		return fmt.Sprintf("%s failed on the '%s' rule", f.Field, f.Tag)
+	}
+	return fmt.Sprintf("%s failed on the '%s=%s' rule", f.Field, f.Tag, f.Param)
+}
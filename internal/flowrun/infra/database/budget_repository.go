@@ -0,0 +1,352 @@
+package database
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BudgetRepository stores budgets with their reservations and events. Spend
+// is read from the usage ledger, so a budget created mid-period counts the
+// calls made before it.
+type BudgetRepository struct {
+	db *Database
+}
+
+func NewBudgetRepository(db *Database) *BudgetRepository {
+	return &BudgetRepository{db: db}
+}
+
+// budgetRecord is the row of a budget.
+type budgetRecord struct {
+	ID        uuid.UUID
+	AccountID uuid.UUID
+	Name      string
+	Scope     domain.BudgetScope
+	TargetID  *uuid.UUID
+	Period    domain.BudgetPeriod
+	SoftLimit domain.Money
+	HardLimit domain.Money
+}
+
+func (budgetRecord) TableName() string {
+	return "budgets"
+}
+
+func newBudgetRecord(b *domain.Budget) *budgetRecord {
+	return &budgetRecord{
+		ID:        b.ID,
+		AccountID: b.AccountID,
+		Name:      b.Name,
+		Scope:     b.Scope,
+		TargetID:  b.TargetID,
+		Period:    b.Period,
+		SoftLimit: b.SoftLimit,
+		HardLimit: b.HardLimit,
+	}
+}
+
+func (r *budgetRecord) toDomain() *domain.Budget {
+	return &domain.Budget{
+		ID:        r.ID,
+		AccountID: r.AccountID,
+		Name:      r.Name,
+		Scope:     r.Scope,
+		TargetID:  r.TargetID,
+		Period:    r.Period,
+		SoftLimit: r.SoftLimit,
+		HardLimit: r.HardLimit,
+	}
+}
+
+// budgetReservationRecord is the row of a budget reservation.
+type budgetReservationRecord struct {
+	ID        uuid.UUID
+	BudgetID  uuid.UUID
+	Amount    domain.Money
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+func (budgetReservationRecord) TableName() string {
+	return "budget_reservations"
+}
+
+func newBudgetReservationRecord(reservation *domain.BudgetReservation) *budgetReservationRecord {
+	return &budgetReservationRecord{
+		ID:        reservation.ID,
+		BudgetID:  reservation.BudgetID,
+		Amount:    reservation.Amount,
+		CreatedAt: reservation.CreatedAt,
+		ExpiresAt: reservation.ExpiresAt,
+	}
+}
+
+// budgetEventRecord is the row of a budget event.
+type budgetEventRecord struct {
+	ID          uuid.UUID
+	BudgetID    uuid.UUID
+	AccountID   uuid.UUID
+	Type        domain.BudgetEventType
+	PeriodStart time.Time
+	Spent       domain.Money
+	Limit       domain.Money
+	CreatedAt   time.Time
+}
+
+func (budgetEventRecord) TableName() string {
+	return "budget_events"
+}
+
+func newBudgetEventRecord(e *domain.BudgetEvent) *budgetEventRecord {
+	return &budgetEventRecord{
+		ID:          e.ID,
+		BudgetID:    e.BudgetID,
+		AccountID:   e.AccountID,
+		Type:        e.Type,
+		PeriodStart: e.PeriodStart,
+		Spent:       e.Spent,
+		Limit:       e.Limit,
+		CreatedAt:   e.CreatedAt,
+	}
+}
+
+func (r *budgetEventRecord) toDomain() *domain.BudgetEvent {
+	return &domain.BudgetEvent{
+		ID:          r.ID,
+		BudgetID:    r.BudgetID,
+		AccountID:   r.AccountID,
+		Type:        r.Type,
+		PeriodStart: r.PeriodStart,
+		Spent:       r.Spent,
+		Limit:       r.Limit,
+		CreatedAt:   r.CreatedAt,
+	}
+}
+
+// Create stores a budget. Its name must be unique within the account, and
+// its flow or provider must belong to the account.
+func (r *BudgetRepository) Create(ctx context.Context, b *domain.Budget) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkBudget(tx, b); err != nil {
+			return err
+		}
+		return nameTaken(tx.Create(newBudgetRecord(b)).Error, "budget", b.Name)
+	})
+}
+
+func (r *BudgetRepository) Get(ctx context.Context, accountID, id uuid.UUID) (*domain.Budget, error) {
+	var record budgetRecord
+	err := r.db.session(ctx).
+		Where("id = ? AND account_id = ?", id, accountID).
+		Take(&record).Error
+	if err != nil {
+		return nil, translateError(err, "budget", id)
+	}
+	return record.toDomain(), nil
+}
+
+func (r *BudgetRepository) List(ctx context.Context, accountID uuid.UUID) ([]*domain.Budget, error) {
+	return findBudgets(r.db.session(ctx).Where("account_id = ?", accountID).Order("name"))
+}
+
+func (r *BudgetRepository) Update(ctx context.Context, b *domain.Budget) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkBudget(tx, b); err != nil {
+			return err
+		}
+
+		result := tx.Model(&budgetRecord{}).
+			Where("id = ? AND account_id = ?", b.ID, b.AccountID).
+			Updates(map[string]any{
+				"name":       b.Name,
+				"scope":      b.Scope,
+				"target_id":  b.TargetID,
+				"period":     b.Period,
+				"soft_limit": b.SoftLimit,
+				"hard_limit": b.HardLimit,
+			})
+		if result.Error != nil {
+			return nameTaken(result.Error, "budget", b.Name)
+		}
+		if result.RowsAffected == 0 {
+			return notFound("budget", b.ID)
+		}
+		return nil
+	})
+}
+
+// Delete removes the budget with its reservations and events.
+func (r *BudgetRepository) Delete(ctx context.Context, accountID, id uuid.UUID) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND account_id = ?", id, accountID).Delete(&budgetRecord{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return notFound("budget", id)
+		}
+		if err := tx.Where("budget_id = ?", id).Delete(&budgetReservationRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("budget_id = ?", id).Delete(&budgetEventRecord{}).Error
+	})
+}
+
+// ListEvents returns the events of a budget, oldest first.
+func (r *BudgetRepository) ListEvents(ctx context.Context, accountID, id uuid.UUID) ([]*domain.BudgetEvent, error) {
+	var records []*budgetEventRecord
+	err := r.db.session(ctx).
+		Where("budget_id = ? AND account_id = ?", id, accountID).
+		Order("created_at, id").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	events := make([]*domain.BudgetEvent, 0, len(records))
+	for _, record := range records {
+		events = append(events, record.toDomain())
+	}
+	return events, nil
+}
+
+// ListApplicable returns the budgets of the target's account that apply to
+// it, ordered by ID, the order Reserve locks them in.
+func (r *BudgetRepository) ListApplicable(
+	ctx context.Context, target domain.BudgetTarget,
+) ([]*domain.Budget, error) {
+	budgets, err := findBudgets(r.db.session(ctx).Where("account_id = ?", target.AccountID).Order("id"))
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(budgets, func(b *domain.Budget) bool { return !b.Applies(target) }), nil
+}
+
+func (r *BudgetRepository) Spent(ctx context.Context, b *domain.Budget, now time.Time) (domain.Money, error) {
+	return budgetSpent(r.db.session(ctx), b, now)
+}
+
+// Reserve locks the budgets of hold in ID order, so concurrent reservations
+// against a budget queue up behind each other and each sees the ones before
+// it.
+func (r *BudgetRepository) Reserve(ctx context.Context, hold *domain.BudgetHold, now time.Time) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, b := range hold.Budgets {
+			var record budgetRecord
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", b.ID).Take(&record).Error
+			if err != nil {
+				return translateError(err, "budget", b.ID)
+			}
+			locked := record.toDomain()
+			spent, err := budgetSpent(tx, locked, now)
+			if err != nil {
+				return err
+			}
+			amount := hold.Reservations[i].Amount
+			if !locked.Allows(spent, amount) {
+				return &domain.BudgetExceededError{Budget: locked, Spent: spent, Amount: amount}
+			}
+		}
+		if len(hold.Reservations) == 0 {
+			return nil
+		}
+		records := make([]*budgetReservationRecord, 0, len(hold.Reservations))
+		for _, reservation := range hold.Reservations {
+			records = append(records, newBudgetReservationRecord(reservation))
+		}
+		return tx.Create(records).Error
+	})
+}
+
+func (r *BudgetRepository) Settle(ctx context.Context, hold *domain.BudgetHold) error {
+	ids := make([]uuid.UUID, 0, len(hold.Reservations))
+	for _, reservation := range hold.Reservations {
+		ids = append(ids, reservation.ID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.session(ctx).Where("id IN ?", ids).Delete(&budgetReservationRecord{}).Error
+}
+
+func (r *BudgetRepository) SettleExpired(ctx context.Context, now time.Time) (int, error) {
+	result := r.db.session(ctx).Where("expires_at <= ?", now).Delete(&budgetReservationRecord{})
+	return int(result.RowsAffected), result.Error
+}
+
+func (r *BudgetRepository) AddEvent(ctx context.Context, e *domain.BudgetEvent) (bool, error) {
+	result := r.db.session(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(newBudgetEventRecord(e))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// budgetSpent sums the ledger's costs in the current period of b and the
+// reservations held against b that did not expire.
+func budgetSpent(tx *gorm.DB, b *domain.Budget, now time.Time) (domain.Money, error) {
+	ledger := tx.Session(&gorm.Session{NewDB: true}).
+		Model(&usageRecord{}).
+		Select("CAST(COALESCE(SUM(cost), 0) AS BIGINT)").
+		Where("account_id = ? AND created_at >= ?", b.AccountID, b.PeriodStart(now))
+	switch b.Scope {
+	case domain.BudgetScopeFlow:
+		ledger = ledger.Where("flow_id = ?", b.TargetID)
+	case domain.BudgetScopeProvider:
+		ledger = ledger.Where("provider_id = ?", b.TargetID)
+	}
+	var recorded int64
+	if err := ledger.Scan(&recorded).Error; err != nil {
+		return 0, err
+	}
+
+	var reserved int64
+	err := tx.Session(&gorm.Session{NewDB: true}).
+		Model(&budgetReservationRecord{}).
+		Select("CAST(COALESCE(SUM(amount), 0) AS BIGINT)").
+		Where("budget_id = ? AND expires_at > ?", b.ID, now).
+		Scan(&reserved).Error
+	if err != nil {
+		return 0, err
+	}
+	return domain.Money(recorded + reserved), nil
+}
+
+func findBudgets(tx *gorm.DB) ([]*domain.Budget, error) {
+	var records []*budgetRecord
+	if err := tx.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	budgets := make([]*domain.Budget, 0, len(records))
+	for _, record := range records {
+		budgets = append(budgets, record.toDomain())
+	}
+	return budgets, nil
+}
+
+func checkBudget(tx *gorm.DB, b *domain.Budget) error {
+	var target any
+	switch b.Scope {
+	case domain.BudgetScopeFlow:
+		target = &flowRecord{}
+	case domain.BudgetScopeProvider:
+		target = &providerRecord{}
+	default:
+		return nil
+	}
+	var targets int64
+	err := tx.Model(target).Where("id = ? AND account_id = ?", b.TargetID, b.AccountID).Count(&targets).Error
+	if err != nil {
+		return err
+	}
+	if targets == 0 {
+		return &port.ReferenceError{Field: "target_id", Value: b.TargetID.String()}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudgetRepositorySettleExpired(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := NewBudgetRepository(newTestDatabase(t))
+	b, err := domain.NewBudget(
+		domain.WithBudgetID(uuid.New()),
+		domain.WithBudgetAccountID(uuid.New()),
+		domain.WithBudgetName("monthly"),
+		domain.WithBudgetScope(domain.BudgetScopeAccount, nil),
+		domain.WithBudgetPeriod(domain.BudgetPeriodMonthly),
+		domain.WithBudgetLimits(0, 100),
+	)
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, b))
+
+	now := time.Now()
+	budgets := []*domain.Budget{b}
+	require.NoError(t, repo.Reserve(ctx, domain.NewBudgetHold(budgets, 30, now, now.Add(time.Minute)), now))
+	require.NoError(t, repo.Reserve(ctx, domain.NewBudgetHold(budgets, 20, now, now.Add(time.Hour)), now))
+
+	// Expired reservations stop counting before they are settled.
+	later := now.Add(2 * time.Minute)
+	spent, err := repo.Spent(ctx, b, later)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Money(20), spent)
+
+	settled, err := repo.SettleExpired(ctx, later)
+	require.NoError(t, err)
+	assert.Equal(t, 1, settled)
+	spent, err = repo.Spent(ctx, b, now)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Money(20), spent)
+}
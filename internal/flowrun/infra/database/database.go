@@ -2,25 +2,48 @@ package database
 
 import (
 	"context"
-	"flow-run/internal/core/domain"
 	"flow-run/internal/lib/validator"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+// sqliteScheme selects the embedded SQLite backend, for running FlowRun as a
+// single binary. The path of the database file follows it, e.g.
+// sqlite:///var/lib/flowrun.db, or sqlite://flowrun.db relative to the
+// working directory. Every other URL connects to Postgres.
+const sqliteScheme = "sqlite://"
+
+const sqliteBusyTimeout = 10 * time.Second
+
+// dialect is the SQL flavor of a Database, for the few statements that are
+// written differently.
+type dialect string
+
+const (
+	dialectPostgres = dialect("postgres")
+	dialectSQLite   = dialect("sqlite")
+)
+
 type DatabaseConfig struct {
-	URL             string        `validate:"required,url"`
+	URL             string        `validate:"required,uri"`
 	MaxOpenConns    int           `validate:"required,min=1,max=1000"`
 	MaxIdleConns    int           `validate:"required,min=1,max=100"`
 	ConnMaxLifetime time.Duration `validate:"required,min=1m,max=1h"`
 }
 
+// Database is the connection pool the repositories of this package share.
+// GORM stays behind them: nothing outside the package sees its types.
 type Database struct {
-	*gorm.DB
+	gorm    *gorm.DB
+	dialect dialect
 }
 
+// NewDatabase connects to the database. Its schema is managed by Migrator.
 func NewDatabase(config *DatabaseConfig) (*Database, error) {
 	// Validate config before using it
 	validatedConfig, err := validator.Struct(config)
@@ -28,10 +51,14 @@ func NewDatabase(config *DatabaseConfig) (*Database, error) {
 		return nil, err
 	}
 
-	db, err := gorm.Open(postgres.Open(validatedConfig.URL), &gorm.Config{})
+	dialector, dialect := openDialector(validatedConfig.URL)
+	db, err := gorm.Open(dialector, &gorm.Config{TranslateError: true})
 	if err != nil {
 		return nil, err
 	}
+	if dialect == dialectSQLite {
+		inUTC(db)
+	}
 
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -42,13 +69,32 @@ func NewDatabase(config *DatabaseConfig) (*Database, error) {
 	sqlDB.SetMaxIdleConns(validatedConfig.MaxIdleConns)
 	sqlDB.SetConnMaxLifetime(validatedConfig.ConnMaxLifetime)
 
-	db.AutoMigrate(&domain.Provider{})
+	return &Database{gorm: db, dialect: dialect}, nil
+}
+
+// openDialector picks the backend of url. SQLite runs in WAL mode so that
+// reads go on while a write is in progress, and takes the write lock when a
+// transaction begins: SQLite has no row locks, so this is what serializes
+// transactions that read before they write, such as budget reservations.
+// Writers wait their turn for up to sqliteBusyTimeout rather than failing.
+// SQLite only enforces foreign keys when asked to, on every connection.
+func openDialector(url string) (gorm.Dialector, dialect) {
+	path, ok := strings.CutPrefix(url, sqliteScheme)
+	if !ok {
+		return postgres.Open(url), dialectPostgres
+	}
 
-	return &Database{DB: db}, nil
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	options := fmt.Sprintf("_pragma=journal_mode(WAL)&_pragma=busy_timeout(%d)&_pragma=foreign_keys(1)&_txlock=immediate",
+		sqliteBusyTimeout.Milliseconds())
+	return sqlite.Open(path + separator + options), dialectSQLite
 }
 
 func (d *Database) Stop(ctx context.Context) error {
-	sqlDB, err := d.DB.DB()
+	sqlDB, err := d.gorm.DB()
 	if err != nil {
 		return err
 	}
@@ -57,5 +103,10 @@ func (d *Database) Stop(ctx context.Context) error {
 }
 
 func (d *Database) Ping(ctx context.Context) error {
-	return d.DB.Exec("SELECT 1").Error
+	return d.session(ctx).Exec("SELECT 1").Error
+}
+
+// session starts a query bound to ctx.
+func (d *Database) session(ctx context.Context) *gorm.DB {
+	return d.gorm.WithContext(ctx)
 }
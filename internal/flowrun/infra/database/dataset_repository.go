@@ -0,0 +1,409 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/lib/validator"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// datasetImportBatchSize is how many rows an import inserts per statement.
+// Rows are written as they are read, so an upload is never held in memory
+// whole.
+const datasetImportBatchSize = 500
+
+// DatasetRepository stores datasets with their versions. The dataset row
+// describes the latest version; every import appends a version with its
+// rows, which are never modified afterwards.
+type DatasetRepository struct {
+	db *Database
+}
+
+func NewDatasetRepository(db *Database) *DatasetRepository {
+	return &DatasetRepository{db: db}
+}
+
+// datasetRecord is the row of a dataset, describing its latest version.
+type datasetRecord struct {
+	ID          uuid.UUID
+	AccountID   uuid.UUID
+	Name        string
+	Description string
+	Version     int
+	RowCount    int
+}
+
+func (datasetRecord) TableName() string {
+	return "datasets"
+}
+
+func newDatasetRecord(d *domain.Dataset) *datasetRecord {
+	return &datasetRecord{
+		ID:          d.ID,
+		AccountID:   d.AccountID,
+		Name:        d.Name,
+		Description: d.Description,
+		Version:     d.Version,
+		RowCount:    d.RowCount,
+	}
+}
+
+func (r *datasetRecord) toDomain() *domain.Dataset {
+	return &domain.Dataset{
+		ID:          r.ID,
+		AccountID:   r.AccountID,
+		Name:        r.Name,
+		Description: r.Description,
+		Version:     r.Version,
+		RowCount:    r.RowCount,
+	}
+}
+
+// datasetVersionRecord is the row of a dataset version.
+type datasetVersionRecord struct {
+	ID        uuid.UUID
+	DatasetID uuid.UUID
+	AccountID uuid.UUID
+	Number    int
+	Format    domain.DatasetFormat
+	RowCount  int
+	CreatedAt time.Time
+}
+
+func (datasetVersionRecord) TableName() string {
+	return "dataset_versions"
+}
+
+func newDatasetVersionRecord(version *domain.DatasetVersion) *datasetVersionRecord {
+	return &datasetVersionRecord{
+		ID:        version.ID,
+		DatasetID: version.DatasetID,
+		AccountID: version.AccountID,
+		Number:    version.Number,
+		Format:    version.Format,
+		RowCount:  version.RowCount,
+		CreatedAt: version.CreatedAt,
+	}
+}
+
+func (r *datasetVersionRecord) toDomain() *domain.DatasetVersion {
+	return &domain.DatasetVersion{
+		ID:        r.ID,
+		DatasetID: r.DatasetID,
+		AccountID: r.AccountID,
+		Number:    r.Number,
+		Format:    r.Format,
+		RowCount:  r.RowCount,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+// datasetRowRecord is the row of a dataset row. Its inputs and expected
+// output are stored as JSON.
+type datasetRowRecord struct {
+	VersionID uuid.UUID `gorm:"primaryKey"`
+	Position  int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	Inputs    map[string]any `gorm:"serializer:json"`
+	Expected  any            `gorm:"serializer:json"`
+}
+
+func (datasetRowRecord) TableName() string {
+	return "dataset_rows"
+}
+
+func newDatasetRowRecord(row *domain.DatasetRow) *datasetRowRecord {
+	return &datasetRowRecord{
+		VersionID: row.VersionID,
+		Position:  row.Position,
+		Name:      row.Name,
+		Inputs:    row.Inputs,
+		Expected:  row.Expected,
+	}
+}
+
+func (r *datasetRowRecord) toDomain() *domain.DatasetRow {
+	return &domain.DatasetRow{
+		VersionID: r.VersionID,
+		Position:  r.Position,
+		Name:      r.Name,
+		Inputs:    r.Inputs,
+		Expected:  r.Expected,
+	}
+}
+
+// Create stores a dataset without versions. The name must be unique within
+// the account.
+func (r *DatasetRepository) Create(ctx context.Context, d *domain.Dataset) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		return nameTaken(tx.Create(newDatasetRecord(d)).Error, "dataset", d.Name)
+	})
+}
+
+func (r *DatasetRepository) Get(ctx context.Context, accountID, id uuid.UUID) (*domain.Dataset, error) {
+	var record datasetRecord
+	err := r.db.session(ctx).
+		Where("id = ? AND account_id = ?", id, accountID).
+		Take(&record).Error
+	if err != nil {
+		return nil, translateError(err, "dataset", id)
+	}
+	return record.toDomain(), nil
+}
+
+func (r *DatasetRepository) List(ctx context.Context, accountID uuid.UUID) ([]*domain.Dataset, error) {
+	var records []*datasetRecord
+	err := r.db.session(ctx).
+		Where("account_id = ?", accountID).
+		Order("name").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	datasets := make([]*domain.Dataset, 0, len(records))
+	for _, record := range records {
+		datasets = append(datasets, record.toDomain())
+	}
+	return datasets, nil
+}
+
+// Update changes the dataset's name and description; its rows only change
+// through imports.
+func (r *DatasetRepository) Update(ctx context.Context, d *domain.Dataset) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		var current datasetRecord
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND account_id = ?", d.ID, d.AccountID).
+			Take(&current).Error
+		if err != nil {
+			return translateError(err, "dataset", d.ID)
+		}
+		if current.Name != d.Name {
+			if err := checkDatasetUnused(tx, current.toDomain()); err != nil {
+				return err
+			}
+		}
+
+		d.Version, d.RowCount = current.Version, current.RowCount
+		err = tx.Model(&datasetRecord{}).
+			Where("id = ? AND account_id = ?", d.ID, d.AccountID).
+			Select("name", "description").
+			Updates(newDatasetRecord(d)).Error
+		return nameTaken(err, "dataset", d.Name)
+	})
+}
+
+// Delete removes the dataset with its versions and rows. Datasets that test
+// suites run over cannot be deleted.
+func (r *DatasetRepository) Delete(ctx context.Context, accountID, id uuid.UUID) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		var record datasetRecord
+		if err := tx.Where("id = ? AND account_id = ?", id, accountID).Take(&record).Error; err != nil {
+			return translateError(err, "dataset", id)
+		}
+		if err := checkDatasetUnused(tx, record.toDomain()); err != nil {
+			return err
+		}
+
+		versions := tx.Model(&datasetVersionRecord{}).Select("id").Where("dataset_id = ?", id)
+		if err := tx.Where("version_id IN (?)", versions).Delete(&datasetRowRecord{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("dataset_id = ?", id).Delete(&datasetVersionRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&record).Error
+	})
+}
+
+// Import reads every row of rows into a new version of the dataset and makes
+// it the latest. Rows are inserted in batches as they are read; the version
+// only becomes visible once the whole upload has been stored, and a
+// malformed or empty upload leaves the dataset unchanged.
+func (r *DatasetRepository) Import(
+	ctx context.Context, accountID, id uuid.UUID, format domain.DatasetFormat, rows domain.DatasetReader,
+) (*domain.DatasetVersion, error) {
+	var version *domain.DatasetVersion
+	err := r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		// Locking the dataset serializes imports, so version numbers are
+		// assigned in order.
+		var record datasetRecord
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND account_id = ?", id, accountID).
+			Take(&record).Error
+		if err != nil {
+			return translateError(err, "dataset", id)
+		}
+
+		version, err = domain.NewDatasetVersion(record.toDomain(), record.Version+1, format, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(newDatasetVersionRecord(version)).Error; err != nil {
+			return err
+		}
+
+		version.RowCount, err = insertRows(tx, version.ID, rows)
+		if err != nil {
+			return err
+		}
+		if version.RowCount == 0 {
+			return validator.NewFieldError("body", "required", "the dataset has no rows")
+		}
+		err = tx.Model(&datasetVersionRecord{}).Where("id = ?", version.ID).Update("row_count", version.RowCount).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&record).Updates(map[string]any{"version": version.Number, "row_count": version.RowCount}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+func insertRows(tx *gorm.DB, versionID uuid.UUID, rows domain.DatasetReader) (int, error) {
+	count := 0
+	batch := make([]*datasetRowRecord, 0, datasetImportBatchSize)
+	for {
+		row, err := rows.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		row.VersionID = versionID
+		batch = append(batch, newDatasetRowRecord(row))
+		count++
+		if len(batch) == datasetImportBatchSize {
+			if err := createRows(tx, batch); err != nil {
+				return 0, err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := createRows(tx, batch); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func createRows(tx *gorm.DB, batch []*datasetRowRecord) error {
+	// gorm's JSON serializer cannot save a nil interface, so rows without an
+	// expected output are inserted apart, leaving the column NULL.
+	var withExpected, withoutExpected []*datasetRowRecord
+	for _, row := range batch {
+		if row.Expected == nil {
+			withoutExpected = append(withoutExpected, row)
+		} else {
+			withExpected = append(withExpected, row)
+		}
+	}
+	if len(withExpected) > 0 {
+		if err := tx.Create(&withExpected).Error; err != nil {
+			return err
+		}
+	}
+	if len(withoutExpected) > 0 {
+		return tx.Omit("expected").Create(&withoutExpected).Error
+	}
+	return nil
+}
+
+// ListVersions returns the versions of a dataset, oldest first.
+func (r *DatasetRepository) ListVersions(
+	ctx context.Context, accountID, datasetID uuid.UUID,
+) ([]*domain.DatasetVersion, error) {
+	var records []*datasetVersionRecord
+	err := r.db.session(ctx).
+		Where("dataset_id = ? AND account_id = ?", datasetID, accountID).
+		Order("number").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]*domain.DatasetVersion, 0, len(records))
+	for _, record := range records {
+		versions = append(versions, record.toDomain())
+	}
+	return versions, nil
+}
+
+func (r *DatasetRepository) GetVersion(
+	ctx context.Context, accountID, datasetID uuid.UUID, number int,
+) (*domain.DatasetVersion, error) {
+	return takeDatasetVersion(r.db.session(ctx), accountID, datasetID, number)
+}
+
+// ResolveDataset returns version of the dataset named name, or its latest
+// version when version is zero.
+func (r *DatasetRepository) ResolveDataset(
+	ctx context.Context, accountID uuid.UUID, name string, version int,
+) (*domain.DatasetVersion, error) {
+	tx := r.db.session(ctx)
+
+	var d datasetRecord
+	if err := tx.Where("account_id = ? AND name = ?", accountID, name).Take(&d).Error; err != nil {
+		return nil, translateError(err, "dataset", name)
+	}
+	if version == 0 {
+		version = d.Version
+	}
+	return takeDatasetVersion(tx, accountID, d.ID, version)
+}
+
+// ListRows returns at most limit rows of a version, in position order,
+// starting at offset.
+func (r *DatasetRepository) ListRows(
+	ctx context.Context, versionID uuid.UUID, offset, limit int,
+) ([]*domain.DatasetRow, error) {
+	var records []*datasetRowRecord
+	err := r.db.session(ctx).
+		Where("version_id = ? AND position >= ?", versionID, offset).
+		Order("position").
+		Limit(limit).
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]*domain.DatasetRow, 0, len(records))
+	for _, record := range records {
+		rows = append(rows, record.toDomain())
+	}
+	return rows, nil
+}
+
+func takeDatasetVersion(tx *gorm.DB, accountID, datasetID uuid.UUID, number int) (*domain.DatasetVersion, error) {
+	var record datasetVersionRecord
+	err := tx.Where("dataset_id = ? AND account_id = ? AND number = ?", datasetID, accountID, number).
+		Take(&record).Error
+	if err != nil {
+		return nil, translateError(err, "dataset version", number)
+	}
+	return record.toDomain(), nil
+}
+
+// checkDatasetUnused refuses changes that would break the test suites that
+// reference d by name.
+func checkDatasetUnused(tx *gorm.DB, d *domain.Dataset) error {
+	var suites int64
+	err := tx.Model(&testSuiteRecord{}).
+		Where("account_id = ? AND dataset = ?", d.AccountID, d.Name).
+		Count(&suites).Error
+	if err != nil {
+		return err
+	}
+	if suites > 0 {
+		return conflict("dataset", d.Name, fmt.Sprintf("is used by %d test suite(s)", suites))
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"errors"
+	"flow-run/internal/core/port"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// translateError reports gorm's missing record as a *port.NotFoundError for
+// the entity stored under key.
+func translateError(err error, entity string, key any) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFound(entity, key)
+	}
+	return err
+}
+
+// nameTaken reports a write that gives entity a name already used in its
+// account, or an ID already used, as a *port.ConflictError. Only the unique index can tell: two
+// concurrent writes would both pass a check made ahead of them.
+func nameTaken(err error, entity, name string) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return conflict(entity, name, "already exists")
+	}
+	return err
+}
+
+func notFound(entity string, key any) error {
+	return &port.NotFoundError{Entity: entity, Key: fmt.Sprint(key)}
+}
+
+func conflict(entity string, key any, reason string) error {
+	return &port.ConflictError{Entity: entity, Key: fmt.Sprint(key), Reason: reason}
+}
@@ -0,0 +1,232 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FlowRepository stores flows with their original source. Definitions are
+// parsed again on every read, so the source stays the single source of truth.
+type FlowRepository struct {
+	db *Database
+}
+
+func NewFlowRepository(db *Database) *FlowRepository {
+	return &FlowRepository{db: db}
+}
+
+// flowRecord is the row of a flow, which stores its source only.
+type flowRecord struct {
+	ID        uuid.UUID
+	AccountID uuid.UUID
+	Name      string
+	Format    domain.FlowSourceFormat
+	Source    string
+}
+
+func (flowRecord) TableName() string {
+	return "flows"
+}
+
+func newFlowRecord(f *domain.Flow) *flowRecord {
+	return &flowRecord{ID: f.ID, AccountID: f.AccountID, Name: f.Name, Format: f.Format, Source: f.Source}
+}
+
+// toDomain parses the stored source into the flow's definition.
+func (r *flowRecord) toDomain() (*domain.Flow, error) {
+	definition, err := domain.ParseFlowDefinition(r.Source, r.Format)
+	if err != nil {
+		return nil, fmt.Errorf("stored flow %s no longer parses: %w", r.ID, err)
+	}
+	return &domain.Flow{
+		ID:         r.ID,
+		AccountID:  r.AccountID,
+		Name:       r.Name,
+		Format:     r.Format,
+		Source:     r.Source,
+		Definition: definition,
+	}, nil
+}
+
+// Create stores a flow. Models, prompts and sub-flows are referenced by name
+// and must exist in the flow's account; the flow name must be unique within
+// it. Steps using a stored prompt must bind its variables.
+func (r *FlowRepository) Create(ctx context.Context, f *domain.Flow) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkFlow(tx, f); err != nil {
+			return err
+		}
+		return nameTaken(tx.Create(newFlowRecord(f)).Error, "flow", f.Name)
+	})
+}
+
+func (r *FlowRepository) Get(ctx context.Context, accountID, id uuid.UUID) (*domain.Flow, error) {
+	return r.take(r.db.session(ctx).Where("id = ? AND account_id = ?", id, accountID), id)
+}
+
+// GetByName looks a flow up the way sub-flow steps reference it.
+func (r *FlowRepository) GetByName(ctx context.Context, accountID uuid.UUID, name string) (*domain.Flow, error) {
+	return r.take(r.db.session(ctx).Where("account_id = ? AND name = ?", accountID, name), name)
+}
+
+func (r *FlowRepository) List(ctx context.Context, accountID uuid.UUID) ([]*domain.Flow, error) {
+	var records []*flowRecord
+	err := r.db.session(ctx).
+		Where("account_id = ?", accountID).
+		Order("name").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	flows := make([]*domain.Flow, 0, len(records))
+	for _, record := range records {
+		f, err := record.toDomain()
+		if err != nil {
+			return nil, err
+		}
+		flows = append(flows, f)
+	}
+	return flows, nil
+}
+
+func (r *FlowRepository) Update(ctx context.Context, f *domain.Flow) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkFlow(tx, f); err != nil {
+			return err
+		}
+
+		result := tx.Model(&flowRecord{}).
+			Where("id = ? AND account_id = ?", f.ID, f.AccountID).
+			Updates(map[string]any{
+				"name":   f.Name,
+				"format": f.Format,
+				"source": f.Source,
+			})
+		if result.Error != nil {
+			return nameTaken(result.Error, "flow", f.Name)
+		}
+		if result.RowsAffected == 0 {
+			return notFound("flow", f.ID)
+		}
+		return nil
+	})
+}
+
+func (r *FlowRepository) Delete(ctx context.Context, accountID, id uuid.UUID) error {
+	result := r.db.session(ctx).
+		Where("id = ? AND account_id = ?", id, accountID).
+		Delete(&flowRecord{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return notFound("flow", id)
+	}
+	return nil
+}
+
+// take takes the flow that query finds, reporting key when there is none.
+func (r *FlowRepository) take(query *gorm.DB, key any) (*domain.Flow, error) {
+	var record flowRecord
+	if err := query.Take(&record).Error; err != nil {
+		return nil, translateError(err, "flow", key)
+	}
+	return record.toDomain()
+}
+
+func checkFlow(tx *gorm.DB, f *domain.Flow) error {
+	models, err := existingNames(tx, &modelRecord{}, f.AccountID, f.Definition.ModelNames())
+	if err != nil {
+		return err
+	}
+	flows, err := existingNames(tx, &flowRecord{}, f.AccountID, f.Definition.SubFlowNames())
+	if err != nil {
+		return err
+	}
+	prompts, err := promptsByName(tx, f.AccountID, f.Definition.PromptNames())
+	if err != nil {
+		return err
+	}
+
+	for i, step := range f.Definition.Steps {
+		if step.Prompt != nil && !models[step.Prompt.Model] {
+			return &port.ReferenceError{Field: fmt.Sprintf("steps[%d].prompt.model", i), Value: step.Prompt.Model}
+		}
+		if step.Flow != nil && !flows[step.Flow.Name] {
+			return &port.ReferenceError{Field: fmt.Sprintf("steps[%d].flow.name", i), Value: step.Flow.Name}
+		}
+		if step.Prompt == nil || step.Prompt.Prompt == "" {
+			continue
+		}
+		prompt, ok := prompts[step.Prompt.Prompt]
+		if !ok {
+			return &port.ReferenceError{Field: fmt.Sprintf("steps[%d].prompt.prompt", i), Value: step.Prompt.Prompt}
+		}
+		revision, err := resolveRevision(tx, prompt, step.Prompt.Ref())
+		if errors.Is(err, port.ErrNotFound) {
+			return promptRefError(i, step.Prompt)
+		}
+		if err != nil {
+			return err
+		}
+		err = domain.CheckPromptBindings(&revision.Template, fmt.Sprintf("steps[%d].inputs", i), step.Inputs)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// promptRefError reports the revision or label of a prompt step that does not
+// exist. Steps following the latest revision always resolve.
+func promptRefError(i int, step *domain.PromptStep) error {
+	if step.Revision > 0 {
+		return &port.ReferenceError{Field: fmt.Sprintf("steps[%d].prompt.revision", i), Value: strconv.Itoa(step.Revision)}
+	}
+	return &port.ReferenceError{Field: fmt.Sprintf("steps[%d].prompt.label", i), Value: step.Label}
+}
+
+func promptsByName(tx *gorm.DB, accountID uuid.UUID, names []string) (map[string]*domain.Prompt, error) {
+	prompts := map[string]*domain.Prompt{}
+	if len(names) == 0 {
+		return prompts, nil
+	}
+
+	var found []*promptRecord
+	if err := tx.Where("account_id = ? AND name IN ?", accountID, names).Find(&found).Error; err != nil {
+		return nil, err
+	}
+	for _, record := range found {
+		prompts[record.Name] = record.toDomain()
+	}
+	return prompts, nil
+}
+
+// existingNames returns which of names exist for the account in model's table.
+func existingNames(tx *gorm.DB, model any, accountID uuid.UUID, names []string) (map[string]bool, error) {
+	existing := map[string]bool{}
+	if len(names) == 0 {
+		return existing, nil
+	}
+
+	var found []string
+	err := tx.Model(model).
+		Where("account_id = ? AND name IN ?", accountID, names).
+		Pluck("name", &found).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range found {
+		existing[name] = true
+	}
+	return existing, nil
+}
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"flow-run/internal/flowrun/infra/repotest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlowRepository(t *testing.T) {
+	t.Parallel()
+
+	repotest.FlowRepository(t, func(t *testing.T) port.FlowRepository {
+		return NewFlowRepository(newTestDatabase(t))
+	})
+}
+
+func TestFlowRepositoryIfReferenceMissing(t *testing.T) {
+	t.Parallel()
+
+	flow, err := domain.NewFlow(
+		domain.WithFlowID(uuid.New()),
+		domain.WithFlowAccountID(uuid.New()),
+		domain.WithFlowSource("name: x\nsteps:\n  - {id: a, type: prompt, prompt: {model: gpt, user: x}}\n",
+			domain.FlowSourceFormatYAML),
+	)
+	require.NoError(t, err)
+
+	err = NewFlowRepository(newTestDatabase(t)).Create(context.Background(), flow)
+	assert.Equal(t, &port.ReferenceError{Field: "steps[0].prompt.model", Value: "gpt"}, err)
+}
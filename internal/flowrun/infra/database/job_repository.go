@@ -0,0 +1,162 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// leaseExpiredMessage is recorded on jobs whose worker stopped extending its
+// lease, e.g. because its process died.
+const leaseExpiredMessage = "lease expired: the worker stopped before it finished"
+
+// JobRepository is the job queue. On Postgres, workers skip the rows other
+// workers are claiming instead of waiting for them; on SQLite, claims are
+// serialized by the database lock.
+type JobRepository struct {
+	db *Database
+}
+
+func NewJobRepository(db *Database) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+// jobRecord is the row of a job. The payload is stored as text, as it is
+// read back verbatim.
+type jobRecord struct {
+	ID          uuid.UUID
+	Kind        domain.JobKind
+	SubjectID   *uuid.UUID
+	Payload     string
+	Priority    int
+	Status      domain.JobStatus
+	Attempts    int
+	MaxAttempts int
+	RunAt       time.Time
+	LockedBy    string
+	LockedUntil *time.Time
+	LastError   string
+	CreatedAt   time.Time
+	FinishedAt  *time.Time
+}
+
+func (jobRecord) TableName() string {
+	return "jobs"
+}
+
+func newJobRecord(j *domain.Job) *jobRecord {
+	return &jobRecord{
+		ID:          j.ID,
+		Kind:        j.Kind,
+		SubjectID:   j.SubjectID,
+		Payload:     string(j.Payload),
+		Priority:    j.Priority,
+		Status:      j.Status,
+		Attempts:    j.Attempts,
+		MaxAttempts: j.MaxAttempts,
+		RunAt:       j.RunAt,
+		LockedBy:    j.LockedBy,
+		LockedUntil: j.LockedUntil,
+		LastError:   j.LastError,
+		CreatedAt:   j.CreatedAt,
+		FinishedAt:  j.FinishedAt,
+	}
+}
+
+func (r *jobRecord) toDomain() *domain.Job {
+	return &domain.Job{
+		ID:          r.ID,
+		Kind:        r.Kind,
+		SubjectID:   r.SubjectID,
+		Payload:     []byte(r.Payload),
+		Priority:    r.Priority,
+		Status:      r.Status,
+		Attempts:    r.Attempts,
+		MaxAttempts: r.MaxAttempts,
+		RunAt:       r.RunAt,
+		LockedBy:    r.LockedBy,
+		LockedUntil: r.LockedUntil,
+		LastError:   r.LastError,
+		CreatedAt:   r.CreatedAt,
+		FinishedAt:  r.FinishedAt,
+	}
+}
+
+func (r *JobRepository) Enqueue(ctx context.Context, job *domain.Job) error {
+	return nameTaken(r.db.session(ctx).Create(newJobRecord(job)).Error, "job", job.ID.String())
+}
+
+func (r *JobRepository) Claim(
+	ctx context.Context, worker string, kinds []domain.JobKind, now, until time.Time,
+) (*domain.Job, error) {
+	var claimed *domain.Job
+	err := r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		for {
+			var record jobRecord
+			err := tx.Clauses(clause.Locking{
+				Strength: clause.LockingStrengthUpdate,
+				Options:  clause.LockingOptionsSkipLocked,
+			}).
+				Where("status IN ? AND kind IN ?", []domain.JobStatus{domain.JobStatusQueued, domain.JobStatusRunning}, kinds).
+				Where("((status = ? AND run_at <= ?) OR (status = ? AND locked_until <= ?))",
+					domain.JobStatusQueued, now, domain.JobStatusRunning, now).
+				Order("priority DESC, run_at, id").
+				Take(&record).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			job := record.toDomain()
+			if job.Expired(now) {
+				// The expired attempt failed; this may have been its last.
+				job.Fail(leaseExpiredMessage, now, now)
+			}
+			if job.Status == domain.JobStatusQueued {
+				job.Lease(worker, until)
+				claimed = job
+			}
+			if err := tx.Select("*").Updates(newJobRecord(job)).Error; err != nil {
+				return err
+			}
+			if claimed != nil {
+				return nil
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// Update only saves the job while the stored row is still the claim the job
+// was given: running, by the same worker, in the same attempt.
+func (r *JobRepository) Update(ctx context.Context, job *domain.Job) error {
+	result := r.db.session(ctx).
+		Select("*").
+		Where("status = ? AND locked_by = ? AND attempts = ?", domain.JobStatusRunning, job.LockedBy, job.Attempts).
+		Updates(newJobRecord(job))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return conflict("job", job.ID, "is no longer claimed by "+job.LockedBy)
+	}
+	return nil
+}
+
+func (r *JobRepository) Get(ctx context.Context, id uuid.UUID) (*domain.Job, error) {
+	var record jobRecord
+	if err := r.db.session(ctx).Where("id = ?", id).Take(&record).Error; err != nil {
+		return nil, translateError(err, "job", id)
+	}
+	return record.toDomain(), nil
+}
@@ -0,0 +1,13 @@
+package database
+
+import (
+	"flow-run/internal/core/port"
+	"flow-run/internal/flowrun/infra/repotest"
+	"testing"
+)
+
+func TestJobRepository(t *testing.T) {
+	t.Parallel()
+
+	repotest.JobQueue(t, func(t *testing.T) port.JobQueue { return NewJobRepository(newTestDatabase(t)) })
+}
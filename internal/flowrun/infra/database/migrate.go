@@ -0,0 +1,538 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"flow-run/internal/lib/envelope"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var (
+	ErrSchemaBehind      = errors.New("database schema is behind")
+	ErrMigrationChanged  = errors.New("applied migration has changed")
+	ErrInvalidMigrations = errors.New("invalid migrations")
+	ErrUnknownMigration  = errors.New("migration is not part of this build")
+	ErrKeyringRequired   = errors.New("master keys are required to seal plaintext API keys")
+)
+
+// migrationLockID keys the advisory lock migrations run under, so that
+// replicas starting at once apply each migration exactly once.
+const migrationLockID int64 = 0x666c6f7772756e // "flowrun"
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// sqliteTypes maps the Postgres column types migrations are written with to
+// those SQLite reads back as the same Go values. SQLite takes any other type
+// name as it is.
+var sqliteTypes = map[string]string{"timestamptz": "datetime", "bytea": "blob"}
+
+var postgresOnlyType = regexp.MustCompile(`\b(timestamptz|bytea)\b`)
+
+// initialTables are the tables of the first migration, parents first. They
+// are those AutoMigrate created before migrations were versioned.
+var initialTables = []string{
+	"providers", "models", "model_prices",
+	"prompts", "prompt_revisions", "prompt_labels",
+	"flows", "flow_runs", "step_runs",
+	"test_suites", "test_suite_runs", "test_case_results",
+	"datasets", "dataset_versions", "dataset_rows",
+	"usage_records",
+	"budgets", "budget_reservations", "budget_events",
+}
+
+// Migration is a numbered schema change, read from the files
+// "<version>_<name>.up.sql" and "<version>_<name>.down.sql". Checksum is the
+// SHA-256 of Up, and tells whether an applied migration was edited since.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// MigrationStatus is the state of a migration in the database. AppliedAt is
+// nil while it is pending. Changed is true when its file no longer matches
+// what was applied, and Unknown when the database has it but this build
+// does not, e.g. after rolling back to an older build.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+	Changed   bool
+	Unknown   bool
+}
+
+// Migrator applies the migrations embedded in the build, recording them in
+// the schema_migrations table. Postgres and SQLite share the migrations,
+// which are written for Postgres; their column types are mapped for SQLite.
+// The keyring seals the plaintext API keys of databases AutoMigrate created;
+// it may be nil for those that hold none.
+type Migrator struct {
+	db         *sql.DB
+	dialect    dialect
+	keyring    *envelope.Keyring
+	migrations []Migration
+	now        func() time.Time
+}
+
+func NewMigrator(db *Database, keyring *envelope.Keyring) (*Migrator, error) {
+	sqlDB, err := db.gorm.DB()
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: sqlDB, dialect: db.dialect, keyring: keyring, migrations: migrations, now: time.Now}, nil
+}
+
+// loadMigrations reads the migrations of dir, which must be numbered from 1
+// without gaps and each have an up and a down file.
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("%w: %s is not named <version>_<name>.up|down.sql", ErrInvalidMigrations, entry.Name())
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidMigrations, entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, dir+"/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("%w: version %d is named both %q and %q", ErrInvalidMigrations, version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			sum := sha256.Sum256(content)
+			m.Up, m.Checksum = string(content), hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for version := 1; version <= len(byVersion); version++ {
+		m, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("%w: version %d is missing", ErrInvalidMigrations, version)
+		}
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("%w: version %d needs both an up and a down file", ErrInvalidMigrations, version)
+		}
+		migrations = append(migrations, *m)
+	}
+	return migrations, nil
+}
+
+// Up applies pending migrations in order, each in its own transaction, and
+// returns those it applied. It refuses to run when an applied migration has
+// changed.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		statuses, err := m.status(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := checkChanged(statuses); err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if statuses[migration.Version-1].AppliedAt != nil {
+				continue
+			}
+			err := m.transact(ctx, conn, func(tx *sql.Tx) error { return m.upgrade(ctx, tx, migration) },
+				"INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES ($1, $2, $3, $4)",
+				migration.Version, migration.Name, migration.Checksum, m.now().UTC())
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the last steps applied migrations, newest first, and
+// returns those it rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var rolledBack []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		statuses, err := m.status(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(statuses) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+			status := statuses[i]
+			if status.AppliedAt == nil {
+				continue
+			}
+			if status.Unknown {
+				return fmt.Errorf("%w: %d_%s", ErrUnknownMigration, status.Version, status.Name)
+			}
+			migration := m.migrations[status.Version-1]
+			err := m.transact(ctx, conn, func(tx *sql.Tx) error { return m.exec(ctx, tx, migration.Down) },
+				"DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			rolledBack = append(rolledBack, migration)
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Status lists the migrations of this build, then those only the database
+// knows, by version.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return m.status(ctx, conn)
+}
+
+// Check fails with ErrSchemaBehind while migrations are pending, and with
+// ErrMigrationChanged when an applied one was edited. A schema ahead of this
+// build passes, so that an older build keeps running during a rollout.
+func (m *Migrator) Check(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if err := checkChanged(statuses); err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%w: %d migration(s) pending, run \"flowrun migrate up\"", ErrSchemaBehind, pending)
+	}
+	return nil
+}
+
+func (m *Migrator) status(ctx context.Context, conn *sql.Conn) ([]MigrationStatus, error) {
+	// Looking the table up spares read-only callers from creating it.
+	query := "SELECT to_regclass('schema_migrations') IS NOT NULL"
+	if m.dialect == dialectSQLite {
+		query = "SELECT count(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'"
+	}
+	var exists bool
+	if err := conn.QueryRowContext(ctx, query).Scan(&exists); err != nil {
+		return nil, err
+	}
+
+	type appliedMigration struct {
+		name, checksum string
+		appliedAt      time.Time
+	}
+	applied := map[int]appliedMigration{}
+	if exists {
+		rows, err := conn.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM schema_migrations")
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var version int
+			var a appliedMigration
+			if err := rows.Scan(&version, &a.name, &a.checksum, &a.appliedAt); err != nil {
+				return nil, err
+			}
+			applied[version] = a
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if a, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &a.appliedAt
+			status.Changed = a.checksum != migration.Checksum
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for version, a := range applied {
+		statuses = append(statuses, MigrationStatus{Version: version, Name: a.name, AppliedAt: &a.appliedAt, Unknown: true})
+	}
+	slices.SortFunc(statuses, func(a, b MigrationStatus) int { return a.Version - b.Version })
+	return statuses, nil
+}
+
+// locked runs f on a connection holding the migration lock, after creating
+// the schema_migrations table. The lock is held by the session, so f must
+// use conn rather than the pool. SQLite has no such lock: its database is a
+// local file that one process migrates, and a second one applying the same
+// migration fails on the version it records.
+func (m *Migrator) locked(ctx context.Context, f func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.dialect == dialectPostgres {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		defer func() {
+			_, _ = conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", migrationLockID)
+		}()
+	}
+
+	_, err = conn.ExecContext(ctx, m.script(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY,
+		name text NOT NULL,
+		checksum text NOT NULL,
+		applied_at timestamptz NOT NULL
+	)`))
+	if err != nil {
+		return err
+	}
+	return f(conn)
+}
+
+// transact runs migrate, then the bookkeeping statement record, in one
+// transaction.
+func (m *Migrator) transact(
+	ctx context.Context, conn *sql.Conn, migrate func(tx *sql.Tx) error, record string, args ...any,
+) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := migrate(tx); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// upgrade applies migration. The first one takes over the tables of a
+// database AutoMigrate created, if it finds any.
+func (m *Migrator) upgrade(ctx context.Context, tx *sql.Tx, migration Migration) error {
+	if migration.Version != 1 {
+		return m.exec(ctx, tx, migration.Up)
+	}
+
+	legacy := map[string][]string{}
+	for _, table := range initialTables {
+		columns, err := m.columns(ctx, tx, table)
+		if err != nil {
+			return err
+		}
+		if len(columns) > 0 {
+			legacy[table] = columns
+		}
+	}
+	if len(legacy) == 0 {
+		return m.exec(ctx, tx, migration.Up)
+	}
+	return m.adoptLegacySchema(ctx, tx, migration.Up, legacy)
+}
+
+// adoptLegacySchema applies script over the tables AutoMigrate created,
+// which lack its constraints and may lack columns added since. It sets their
+// rows aside, creates the tables anew and copies back the columns both have,
+// sealing the plaintext API keys of releases that predate encryption on the
+// way. Rows that break a constraint, such as duplicate names, fail the
+// migration and are left to fix by hand. legacy lists the columns of each
+// table found.
+func (m *Migrator) adoptLegacySchema(ctx context.Context, tx *sql.Tx, script string, legacy map[string][]string) error {
+	// The copies carry no constraints or indexes, whose names the new tables
+	// take over.
+	for _, table := range slices.Backward(initialTables) {
+		if _, ok := legacy[table]; !ok {
+			continue
+		}
+		_, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE TEMPORARY TABLE legacy_%[1]s AS SELECT * FROM %[1]s", table))
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DROP TABLE "+table); err != nil {
+			return err
+		}
+	}
+	if err := m.exec(ctx, tx, script); err != nil {
+		return err
+	}
+
+	for _, table := range initialTables {
+		columns, ok := legacy[table]
+		if !ok {
+			continue
+		}
+		current, err := m.columns(ctx, tx, table)
+		if err != nil {
+			return err
+		}
+		var shared []string
+		for _, column := range current {
+			if slices.Contains(columns, column) {
+				shared = append(shared, `"`+column+`"`)
+			}
+		}
+		list := strings.Join(shared, ", ")
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %[1]s (%[2]s) SELECT %[2]s FROM legacy_%[1]s", table, list))
+		if err != nil {
+			return fmt.Errorf("copy %s: %w", table, err)
+		}
+	}
+
+	if slices.Contains(legacy["providers"], "api_key") {
+		if err := m.sealLegacyApiKeys(ctx, tx); err != nil {
+			return err
+		}
+	}
+	for _, table := range slices.Backward(initialTables) {
+		if _, ok := legacy[table]; !ok {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, "DROP TABLE legacy_"+table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sealLegacyApiKeys seals the keys of the plaintext api_key column of
+// legacy_providers into the providers they were copied to.
+func (m *Migrator) sealLegacyApiKeys(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx,
+		"SELECT id, api_key FROM legacy_providers WHERE api_key IS NOT NULL AND api_key <> ''")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	keys := map[string]string{}
+	for rows.Next() {
+		var id, key string
+		if err := rows.Scan(&id, &key); err != nil {
+			return err
+		}
+		keys[id] = key
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(keys) > 0 && m.keyring == nil {
+		return fmt.Errorf("%w: %d provider(s) hold one", ErrKeyringRequired, len(keys))
+	}
+
+	for id, key := range keys {
+		providerID, err := uuid.Parse(id)
+		if err != nil {
+			return fmt.Errorf("provider %s: %w", id, err)
+		}
+		sealed, err := m.keyring.Seal([]byte(key), providerID[:])
+		if err != nil {
+			return fmt.Errorf("provider %s: %w", id, err)
+		}
+		_, err = tx.ExecContext(ctx,
+			"UPDATE providers SET api_key_key_version = $1, api_key_data_key = $2, api_key_ciphertext = $3 WHERE id = $4",
+			sealed.KeyVersion, sealed.DataKey, sealed.Ciphertext, id)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columns lists the columns of table in order, or none when it does not
+// exist.
+func (m *Migrator) columns(ctx context.Context, tx *sql.Tx, table string) ([]string, error) {
+	query := "SELECT column_name FROM information_schema.columns " +
+		"WHERE table_schema = current_schema() AND table_name = $1 ORDER BY ordinal_position"
+	if m.dialect == dialectSQLite {
+		query = "SELECT name FROM pragma_table_info($1) ORDER BY cid"
+	}
+	rows, err := tx.QueryContext(ctx, query, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+func (m *Migrator) exec(ctx context.Context, tx *sql.Tx, script string) error {
+	_, err := tx.ExecContext(ctx, m.script(script))
+	return err
+}
+
+// script adapts a script written for Postgres to the dialect of m.
+func (m *Migrator) script(script string) string {
+	if m.dialect == dialectSQLite {
+		return postgresOnlyType.ReplaceAllStringFunc(script, func(t string) string { return sqliteTypes[t] })
+	}
+	return script
+}
+
+func checkChanged(statuses []MigrationStatus) error {
+	for _, status := range statuses {
+		if status.Changed {
+			return fmt.Errorf("%w: %d_%s no longer matches what was applied", ErrMigrationChanged, status.Version, status.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"flow-run/internal/lib/envelope"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	t.Parallel()
+
+	migrations, err := loadMigrations(fstest.MapFS{
+		"m/0002_add_index.up.sql":   {Data: []byte("CREATE INDEX i ON t (c);")},
+		"m/0002_add_index.down.sql": {Data: []byte("DROP INDEX i;")},
+		"m/0001_initial.up.sql":     {Data: []byte("CREATE TABLE t (c text);")},
+		"m/0001_initial.down.sql":   {Data: []byte("DROP TABLE t;")},
+	}, "m")
+	require.NoError(t, err)
+
+	require.Len(t, migrations, 2)
+	assert.Equal(t, 1, migrations[0].Version)
+	assert.Equal(t, "initial", migrations[0].Name)
+	assert.Equal(t, "CREATE TABLE t (c text);", migrations[0].Up)
+	assert.Equal(t, "DROP TABLE t;", migrations[0].Down)
+	assert.Len(t, migrations[0].Checksum, 64)
+	assert.Equal(t, "add_index", migrations[1].Name)
+	assert.NotEqual(t, migrations[0].Checksum, migrations[1].Checksum)
+}
+
+func TestLoadMigrationsIfInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		files []string
+	}{
+		{name: "bad_name", files: []string{"initial.up.sql"}},
+		{name: "missing_down", files: []string{"0001_initial.up.sql"}},
+		{name: "gap", files: []string{"0001_a.up.sql", "0001_a.down.sql", "0003_c.up.sql", "0003_c.down.sql"}},
+		{name: "renamed", files: []string{"0001_a.up.sql", "0001_b.down.sql"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fsys := fstest.MapFS{}
+			for _, name := range tt.files {
+				fsys["m/"+name] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+			}
+
+			_, err := loadMigrations(fsys, "m")
+			require.ErrorIs(t, err, ErrInvalidMigrations)
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	t.Parallel()
+
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	require.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+}
+
+func TestMigrator(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	migrator, err := NewMigrator(openTestDatabase(t, newTestURL(t)), nil)
+	require.NoError(t, err)
+	require.ErrorIs(t, migrator.Check(ctx), ErrSchemaBehind)
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(migrator.migrations))
+	require.NoError(t, migrator.Check(ctx))
+
+	// Applying again is a no-op.
+	applied, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	for _, status := range statuses {
+		assert.NotNil(t, status.AppliedAt, status.Name)
+		assert.False(t, status.Changed, status.Name)
+	}
+
+	reverted, err := migrator.Down(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+	assert.Equal(t, migrator.migrations[len(migrator.migrations)-1].Version, reverted[0].Version)
+	require.ErrorIs(t, migrator.Check(ctx), ErrSchemaBehind)
+}
+
+func TestMigratorIfChanged(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	migrator, err := NewMigrator(openTestDatabase(t, newTestURL(t)), nil)
+	require.NoError(t, err)
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	migrator.migrations[0].Checksum = "edited"
+	require.ErrorIs(t, migrator.Check(ctx), ErrMigrationChanged)
+}
+
+// legacySchema is what the first release's AutoMigrate of domain.Provider
+// created, with an API key in plaintext.
+const legacySchema = `
+CREATE TABLE providers (id text, name text, account_id text, type text, api_key text, PRIMARY KEY (id));
+CREATE UNIQUE INDEX idx_providers_name ON providers (name);`
+
+func TestMigratorAdoptsLegacySchema(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := openTestDatabase(t, newTestURL(t))
+	accountID, providerID := uuid.New(), uuid.New()
+	require.NoError(t, db.session(ctx).Exec(legacySchema).Error)
+	require.NoError(t, db.session(ctx).Exec("INSERT INTO providers VALUES (?, 'main', ?, 'open_router', 'sk-legacy')",
+		providerID, accountID).Error)
+
+	keyring, err := envelope.NewKeyring(&envelope.KeyringConfig{
+		MasterKeys: "1:" + base64.StdEncoding.EncodeToString(make([]byte, 32)),
+	})
+	require.NoError(t, err)
+	migrator, err := NewMigrator(db, keyring)
+	require.NoError(t, err)
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	provider, err := NewProviderRepository(db, keyring).Get(ctx, accountID, providerID)
+	require.NoError(t, err)
+	assert.Equal(t, "main", provider.Name)
+	assert.Empty(t, provider.BaseURL)
+	apiKey, err := keyring.Open(provider.EncryptedApiKey, providerID[:])
+	require.NoError(t, err)
+	assert.Equal(t, "sk-legacy", string(apiKey))
+
+	assert.False(t, db.session(ctx).Migrator().HasColumn("providers", "api_key"))
+	assert.False(t, db.session(ctx).Migrator().HasTable("legacy_providers"))
+	err = db.session(ctx).Exec("INSERT INTO providers (id, name, account_id, type) VALUES (?, 'main', ?, 'mock')",
+		uuid.New(), accountID).Error
+	require.Error(t, err, "names are unique per account")
+}
+
+func TestMigratorAdoptsLegacySchemaIfNoKeyring(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := openTestDatabase(t, newTestURL(t))
+	require.NoError(t, db.session(ctx).Exec(legacySchema).Error)
+	require.NoError(t, db.session(ctx).Exec("INSERT INTO providers VALUES (?, 'main', ?, 'open_router', 'sk-legacy')",
+		uuid.New(), uuid.New()).Error)
+
+	migrator, err := NewMigrator(db, nil)
+	require.NoError(t, err)
+	_, err = migrator.Up(ctx)
+	require.ErrorIs(t, err, ErrKeyringRequired)
+
+	// The legacy table is left as it was.
+	assert.True(t, db.session(ctx).Migrator().HasColumn("providers", "api_key"))
+	require.ErrorIs(t, migrator.Check(ctx), ErrSchemaBehind)
+}
@@ -0,0 +1,19 @@
+DROP TABLE budget_events;
+DROP TABLE budget_reservations;
+DROP TABLE budgets;
+DROP TABLE usage_records;
+DROP TABLE dataset_rows;
+DROP TABLE dataset_versions;
+DROP TABLE datasets;
+DROP TABLE test_case_results;
+DROP TABLE test_suite_runs;
+DROP TABLE test_suites;
+DROP TABLE step_runs;
+DROP TABLE flow_runs;
+DROP TABLE flows;
+DROP TABLE prompt_labels;
+DROP TABLE prompt_revisions;
+DROP TABLE prompts;
+DROP TABLE model_prices;
+DROP TABLE models;
+DROP TABLE providers;
@@ -0,0 +1,315 @@
+-- The schema AutoMigrate created before migrations were versioned, with the
+-- constraints it lacked. Databases AutoMigrate created are not adopted as
+-- they are: the migrator recreates their tables with this script and copies
+-- their rows over, see Migrator.adoptLegacySchema.
+
+CREATE TABLE providers (
+    id text NOT NULL,
+    name text NOT NULL,
+    account_id text NOT NULL,
+    type text NOT NULL,
+    base_url text NOT NULL DEFAULT '',
+    api_key_key_version bigint,
+    api_key_data_key bytea,
+    api_key_ciphertext bytea,
+    PRIMARY KEY (id),
+    UNIQUE (account_id, name)
+);
+
+CREATE TABLE models (
+    id text NOT NULL,
+    name text NOT NULL,
+    account_id text NOT NULL,
+    provider_id text NOT NULL,
+    input_price bigint NOT NULL,
+    output_price bigint NOT NULL,
+    cached_price bigint NOT NULL,
+    context_window bigint NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE (account_id, name),
+    FOREIGN KEY (provider_id) REFERENCES providers (id)
+);
+
+CREATE TABLE model_prices (
+    id text NOT NULL,
+    model_id text NOT NULL,
+    account_id text NOT NULL,
+    source text NOT NULL,
+    input_price bigint NOT NULL,
+    output_price bigint NOT NULL,
+    cached_price bigint NOT NULL,
+    effective_at timestamptz NOT NULL,
+    created_at timestamptz NOT NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (model_id) REFERENCES models (id) ON DELETE CASCADE
+);
+CREATE INDEX idx_model_prices_model_effective ON model_prices (model_id,effective_at);
+
+CREATE TABLE prompts (
+    id text NOT NULL,
+    account_id text NOT NULL,
+    name text NOT NULL,
+    description text NOT NULL,
+    template_system text NOT NULL,
+    template_user text NOT NULL,
+    template_variables text,
+    revision bigint NOT NULL,
+    author text NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE (account_id, name)
+);
+
+CREATE TABLE prompt_revisions (
+    id text NOT NULL,
+    prompt_id text NOT NULL,
+    account_id text NOT NULL,
+    number bigint NOT NULL,
+    author text NOT NULL,
+    content_hash text NOT NULL,
+    template_system text NOT NULL,
+    template_user text NOT NULL,
+    template_variables text,
+    created_at timestamptz NOT NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (prompt_id) REFERENCES prompts (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX idx_prompt_revisions_number ON prompt_revisions (prompt_id,number);
+
+CREATE TABLE prompt_labels (
+    prompt_id text NOT NULL,
+    name text NOT NULL,
+    account_id text NOT NULL,
+    revision bigint NOT NULL,
+    updated_at timestamptz NOT NULL,
+    PRIMARY KEY (prompt_id,name),
+    FOREIGN KEY (prompt_id) REFERENCES prompts (id) ON DELETE CASCADE
+);
+
+CREATE TABLE flows (
+    id text NOT NULL,
+    account_id text NOT NULL,
+    name text NOT NULL,
+    format text NOT NULL,
+    source text NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE (account_id, name)
+);
+
+CREATE TABLE flow_runs (
+    id text NOT NULL,
+    account_id text NOT NULL,
+    flow_id text NOT NULL,
+    parent_step_run_id text,
+    model text NOT NULL,
+    status text NOT NULL,
+    inputs text,
+    outputs text,
+    error text NOT NULL,
+    usage_prompt_tokens bigint NOT NULL,
+    usage_completion_tokens bigint NOT NULL,
+    usage_total_tokens bigint NOT NULL,
+    usage_cache_read_tokens bigint NOT NULL,
+    usage_cache_write_tokens bigint NOT NULL,
+    cost bigint NOT NULL,
+    created_at timestamptz NOT NULL,
+    started_at timestamptz,
+    finished_at timestamptz,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE step_runs (
+    id text NOT NULL,
+    run_id text NOT NULL,
+    step_id text NOT NULL,
+    position bigint NOT NULL,
+    type text NOT NULL,
+    status text NOT NULL,
+    inputs text,
+    output text,
+    error text NOT NULL,
+    usage_prompt_tokens bigint NOT NULL,
+    usage_completion_tokens bigint NOT NULL,
+    usage_total_tokens bigint NOT NULL,
+    usage_cache_read_tokens bigint NOT NULL,
+    usage_cache_write_tokens bigint NOT NULL,
+    cost bigint NOT NULL,
+    child_run_id text,
+    started_at timestamptz,
+    finished_at timestamptz,
+    prompt_revision_id text,
+    PRIMARY KEY (id),
+    FOREIGN KEY (run_id) REFERENCES flow_runs (id) ON DELETE CASCADE
+);
+
+CREATE TABLE test_suites (
+    id text NOT NULL,
+    account_id text NOT NULL,
+    name text NOT NULL,
+    description text NOT NULL,
+    prompt text NOT NULL,
+    flow text NOT NULL,
+    cases text,
+    dataset text NOT NULL,
+    dataset_version bigint NOT NULL,
+    assertions text,
+    PRIMARY KEY (id),
+    UNIQUE (account_id, name)
+);
+
+CREATE TABLE test_suite_runs (
+    id text NOT NULL,
+    account_id text NOT NULL,
+    suite_id text NOT NULL,
+    model text NOT NULL,
+    prompt_revision_id text,
+    prompt_revision bigint NOT NULL,
+    dataset_version_id text,
+    dataset_version bigint NOT NULL,
+    status text NOT NULL,
+    error text NOT NULL,
+    passed bigint NOT NULL,
+    failed bigint NOT NULL,
+    usage_prompt_tokens bigint NOT NULL,
+    usage_completion_tokens bigint NOT NULL,
+    usage_total_tokens bigint NOT NULL,
+    usage_cache_read_tokens bigint NOT NULL,
+    usage_cache_write_tokens bigint NOT NULL,
+    cost bigint NOT NULL,
+    judge_usage_prompt_tokens bigint NOT NULL,
+    judge_usage_completion_tokens bigint NOT NULL,
+    judge_usage_total_tokens bigint NOT NULL,
+    judge_usage_cache_read_tokens bigint NOT NULL,
+    judge_usage_cache_write_tokens bigint NOT NULL,
+    judge_cost bigint NOT NULL,
+    created_at timestamptz NOT NULL,
+    started_at timestamptz,
+    finished_at timestamptz,
+    PRIMARY KEY (id),
+    FOREIGN KEY (suite_id) REFERENCES test_suites (id) ON DELETE CASCADE
+);
+
+CREATE TABLE test_case_results (
+    id text NOT NULL,
+    run_id text NOT NULL,
+    position bigint NOT NULL,
+    name text NOT NULL,
+    passed boolean NOT NULL,
+    output text,
+    error text NOT NULL,
+    assertions text,
+    flow_run_id text,
+    usage_prompt_tokens bigint NOT NULL,
+    usage_completion_tokens bigint NOT NULL,
+    usage_total_tokens bigint NOT NULL,
+    usage_cache_read_tokens bigint NOT NULL,
+    usage_cache_write_tokens bigint NOT NULL,
+    cost bigint NOT NULL,
+    judge_usage_prompt_tokens bigint NOT NULL,
+    judge_usage_completion_tokens bigint NOT NULL,
+    judge_usage_total_tokens bigint NOT NULL,
+    judge_usage_cache_read_tokens bigint NOT NULL,
+    judge_usage_cache_write_tokens bigint NOT NULL,
+    judge_cost bigint NOT NULL,
+    latency_ms bigint NOT NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (run_id) REFERENCES test_suite_runs (id) ON DELETE CASCADE
+);
+
+CREATE TABLE datasets (
+    id text NOT NULL,
+    account_id text NOT NULL,
+    name text NOT NULL,
+    description text NOT NULL,
+    version bigint NOT NULL,
+    row_count bigint NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE (account_id, name)
+);
+
+CREATE TABLE dataset_versions (
+    id text NOT NULL,
+    dataset_id text NOT NULL,
+    account_id text NOT NULL,
+    number bigint NOT NULL,
+    format text NOT NULL,
+    row_count bigint NOT NULL,
+    created_at timestamptz NOT NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (dataset_id) REFERENCES datasets (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX idx_dataset_versions_number ON dataset_versions (dataset_id,number);
+
+CREATE TABLE dataset_rows (
+    version_id text NOT NULL,
+    position bigint NOT NULL,
+    name text NOT NULL,
+    inputs text,
+    expected text,
+    PRIMARY KEY (version_id,position),
+    FOREIGN KEY (version_id) REFERENCES dataset_versions (id) ON DELETE CASCADE
+);
+
+CREATE TABLE usage_records (
+    id text NOT NULL,
+    account_id text NOT NULL,
+    provider_id text NOT NULL,
+    model_id text NOT NULL,
+    model text NOT NULL,
+    flow_id text,
+    run_id text,
+    step_run_id text,
+    step_id text NOT NULL,
+    prompt_revision_id text,
+    usage_prompt_tokens bigint NOT NULL,
+    usage_completion_tokens bigint NOT NULL,
+    usage_total_tokens bigint NOT NULL,
+    usage_cache_read_tokens bigint NOT NULL,
+    usage_cache_write_tokens bigint NOT NULL,
+    input_price bigint NOT NULL,
+    output_price bigint NOT NULL,
+    cached_price bigint NOT NULL,
+    cost bigint NOT NULL,
+    created_at timestamptz NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE INDEX idx_usage_records_created ON usage_records (created_at);
+CREATE INDEX idx_usage_records_run_id ON usage_records (run_id);
+CREATE INDEX idx_usage_records_account_created ON usage_records (account_id,created_at);
+
+CREATE TABLE budgets (
+    id text NOT NULL,
+    account_id text NOT NULL,
+    name text NOT NULL,
+    scope text NOT NULL,
+    target_id text,
+    period text NOT NULL,
+    soft_limit bigint NOT NULL,
+    hard_limit bigint NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE (account_id, name)
+);
+CREATE INDEX idx_budgets_account_id ON budgets (account_id);
+
+CREATE TABLE budget_reservations (
+    id text NOT NULL,
+    budget_id text NOT NULL,
+    amount bigint NOT NULL,
+    created_at timestamptz NOT NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (budget_id) REFERENCES budgets (id) ON DELETE CASCADE
+);
+CREATE INDEX idx_budget_reservations_budget_id ON budget_reservations (budget_id);
+
+CREATE TABLE budget_events (
+    id text NOT NULL,
+    budget_id text NOT NULL,
+    account_id text NOT NULL,
+    type text NOT NULL,
+    period_start timestamptz NOT NULL,
+    spent bigint NOT NULL,
+    "limit" bigint NOT NULL,
+    created_at timestamptz NOT NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (budget_id) REFERENCES budgets (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX idx_budget_events_period ON budget_events (budget_id,type,period_start);
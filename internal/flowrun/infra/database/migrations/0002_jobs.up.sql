@@ -0,0 +1,22 @@
+-- The durable job queue. Workers claim due jobs in index order, so finished
+-- jobs are left out of the index.
+
+CREATE TABLE jobs (
+    id text,
+    kind text NOT NULL,
+    subject_id text,
+    payload text NOT NULL,
+    priority bigint NOT NULL,
+    status text NOT NULL,
+    attempts bigint NOT NULL,
+    max_attempts bigint NOT NULL,
+    run_at timestamptz NOT NULL,
+    locked_by text,
+    locked_until timestamptz,
+    last_error text,
+    created_at timestamptz NOT NULL,
+    finished_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX idx_jobs_due ON jobs (priority DESC, run_at) WHERE status IN ('queued', 'running');
+CREATE INDEX idx_jobs_subject_id ON jobs (subject_id);
@@ -0,0 +1 @@
+ALTER TABLE flow_runs DROP COLUMN cancel_requested_at;
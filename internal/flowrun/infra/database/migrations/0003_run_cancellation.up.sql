@@ -0,0 +1,4 @@
+-- Cancellation requests of flow runs. The engine executing a run polls for
+-- them, as it may run in another process than the request.
+
+ALTER TABLE flow_runs ADD COLUMN cancel_requested_at timestamptz;
@@ -0,0 +1,3 @@
+ALTER TABLE usage_records DROP COLUMN case_position;
+ALTER TABLE usage_records DROP COLUMN suite_run_id;
+ALTER TABLE usage_records DROP COLUMN purpose;
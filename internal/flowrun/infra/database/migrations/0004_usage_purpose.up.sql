@@ -0,0 +1,9 @@
+-- Usage records tell what each call was made for, and those made for test
+-- suites point at the suite run and case. Calls recorded before were made by
+-- flow steps when they have a run, and for test suites otherwise; those do
+-- not tell prompt cases from judges and count as test cases.
+
+ALTER TABLE usage_records ADD COLUMN purpose text NOT NULL DEFAULT 'flow';
+ALTER TABLE usage_records ADD COLUMN suite_run_id text;
+ALTER TABLE usage_records ADD COLUMN case_position bigint;
+UPDATE usage_records SET purpose = 'test_case' WHERE run_id IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE budget_reservations DROP COLUMN expires_at;
@@ -0,0 +1,6 @@
+-- Budget reservations expire, so that calls cut short by a process that
+-- stopped do not hold their budgets forever, and each process settles only
+-- the expired ones: the calls of the others may be in flight. Reservations
+-- made before expire right away.
+
+ALTER TABLE budget_reservations ADD COLUMN expires_at timestamptz NOT NULL DEFAULT '1970-01-01 00:00:00+00';
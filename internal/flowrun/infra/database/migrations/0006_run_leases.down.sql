@@ -0,0 +1,4 @@
+DROP INDEX idx_test_suite_runs_lease_expires_at;
+DROP INDEX idx_flow_runs_lease_expires_at;
+ALTER TABLE test_suite_runs DROP COLUMN lease_expires_at;
+ALTER TABLE flow_runs DROP COLUMN lease_expires_at;
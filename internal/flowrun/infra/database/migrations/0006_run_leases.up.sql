@@ -0,0 +1,13 @@
+-- Leases of running flow runs and test suite runs. The process executing a
+-- run keeps extending its lease; runs whose lease expired were left behind by
+-- a process that stopped and are failed by any other. Runs running before
+-- leases existed expire right away.
+
+ALTER TABLE flow_runs ADD COLUMN lease_expires_at timestamptz;
+ALTER TABLE test_suite_runs ADD COLUMN lease_expires_at timestamptz;
+
+UPDATE flow_runs SET lease_expires_at = started_at WHERE status = 'running' AND parent_step_run_id IS NULL;
+UPDATE test_suite_runs SET lease_expires_at = started_at WHERE status = 'running';
+
+CREATE INDEX idx_flow_runs_lease_expires_at ON flow_runs (lease_expires_at) WHERE status = 'running';
+CREATE INDEX idx_test_suite_runs_lease_expires_at ON test_suite_runs (lease_expires_at) WHERE status = 'running';
@@ -0,0 +1,381 @@
+package database
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ModelRepository stores models with their pricing catalogs. Models are
+// read with the prices in effect at the time of reading; models that have no
+// catalog entries keep the prices stored on their row.
+type ModelRepository struct {
+	db *Database
+}
+
+func NewModelRepository(db *Database) *ModelRepository {
+	return &ModelRepository{db: db}
+}
+
+// modelRecord is the row of a model. Its prices are those the model was
+// created or last updated with.
+type modelRecord struct {
+	ID            uuid.UUID
+	AccountID     uuid.UUID
+	ProviderID    uuid.UUID
+	Name          string
+	InputPrice    domain.Money
+	OutputPrice   domain.Money
+	CachedPrice   domain.Money
+	ContextWindow int
+}
+
+func (modelRecord) TableName() string {
+	return "models"
+}
+
+func newModelRecord(m *domain.Model) *modelRecord {
+	return &modelRecord{
+		ID:            m.ID,
+		AccountID:     m.AccountID,
+		ProviderID:    m.ProviderID,
+		Name:          m.Name,
+		InputPrice:    m.InputPrice,
+		OutputPrice:   m.OutputPrice,
+		CachedPrice:   m.CachedPrice,
+		ContextWindow: m.ContextWindow,
+	}
+}
+
+func (r *modelRecord) toDomain() *domain.Model {
+	return &domain.Model{
+		ID:            r.ID,
+		AccountID:     r.AccountID,
+		ProviderID:    r.ProviderID,
+		Name:          r.Name,
+		InputPrice:    r.InputPrice,
+		OutputPrice:   r.OutputPrice,
+		CachedPrice:   r.CachedPrice,
+		ContextWindow: r.ContextWindow,
+	}
+}
+
+// modelPriceRecord is the row of a pricing catalog entry.
+type modelPriceRecord struct {
+	ID          uuid.UUID
+	ModelID     uuid.UUID
+	AccountID   uuid.UUID
+	Source      domain.PriceSource
+	InputPrice  domain.Money
+	OutputPrice domain.Money
+	CachedPrice domain.Money
+	EffectiveAt time.Time
+	CreatedAt   time.Time
+}
+
+func (modelPriceRecord) TableName() string {
+	return "model_prices"
+}
+
+func newModelPriceRecord(p *domain.ModelPrice) *modelPriceRecord {
+	return &modelPriceRecord{
+		ID:          p.ID,
+		ModelID:     p.ModelID,
+		AccountID:   p.AccountID,
+		Source:      p.Source,
+		InputPrice:  p.InputPrice,
+		OutputPrice: p.OutputPrice,
+		CachedPrice: p.CachedPrice,
+		EffectiveAt: p.EffectiveAt,
+		CreatedAt:   p.CreatedAt,
+	}
+}
+
+func (r *modelPriceRecord) toDomain() *domain.ModelPrice {
+	return &domain.ModelPrice{
+		ID:          r.ID,
+		ModelID:     r.ModelID,
+		AccountID:   r.AccountID,
+		Source:      r.Source,
+		InputPrice:  r.InputPrice,
+		OutputPrice: r.OutputPrice,
+		CachedPrice: r.CachedPrice,
+		EffectiveAt: r.EffectiveAt,
+		CreatedAt:   r.CreatedAt,
+	}
+}
+
+// Create stores a model. The provider must belong to the model's account and
+// the model name must be unique within the account. A priced model starts its
+// catalog with a manual entry.
+func (r *ModelRepository) Create(ctx context.Context, m *domain.Model) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkModel(tx, m); err != nil {
+			return err
+		}
+		if err := tx.Create(newModelRecord(m)).Error; err != nil {
+			return nameTaken(err, "model", m.Name)
+		}
+		if m.InputPrice == 0 && m.OutputPrice == 0 && m.CachedPrice == 0 {
+			return nil
+		}
+		return addManualPrice(tx, m)
+	})
+}
+
+func (r *ModelRepository) Get(ctx context.Context, accountID, id uuid.UUID) (*domain.Model, error) {
+	return takeModel(r.db.session(ctx), id, "id = ? AND account_id = ?", id, accountID)
+}
+
+func (r *ModelRepository) GetByName(ctx context.Context, accountID uuid.UUID, name string) (*domain.Model, error) {
+	return takeModel(r.db.session(ctx), name, "account_id = ? AND name = ?", accountID, name)
+}
+
+func (r *ModelRepository) List(ctx context.Context, accountID uuid.UUID) ([]*domain.Model, error) {
+	return findModels(r.db.session(ctx).Where("account_id = ?", accountID))
+}
+
+// ListByProvider returns the models of a provider, e.g. to sync their prices
+// from the provider's catalog.
+func (r *ModelRepository) ListByProvider(ctx context.Context, providerID uuid.UUID) ([]*domain.Model, error) {
+	return findModels(r.db.session(ctx).Where("provider_id = ?", providerID))
+}
+
+// Update changes the model. Prices that differ from those in effect are
+// added to the catalog as a manual override, effective immediately.
+func (r *ModelRepository) Update(ctx context.Context, m *domain.Model) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkModel(tx, m); err != nil {
+			return err
+		}
+		current, err := takeModel(tx, m.ID, "id = ? AND account_id = ?", m.ID, m.AccountID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&modelRecord{}).
+			Where("id = ? AND account_id = ?", m.ID, m.AccountID).
+			Updates(map[string]any{
+				"name":         m.Name,
+				"provider_id":  m.ProviderID,
+				"input_price":  m.InputPrice,
+				"output_price": m.OutputPrice,
+				"cached_price": m.CachedPrice,
+			}).Error
+		if err != nil {
+			return nameTaken(err, "model", m.Name)
+		}
+
+		m.ContextWindow = current.ContextWindow
+		if m.InputPrice == current.InputPrice && m.OutputPrice == current.OutputPrice &&
+			m.CachedPrice == current.CachedPrice {
+			return nil
+		}
+		return addManualPrice(tx, m)
+	})
+}
+
+// Delete removes the model with its pricing catalog. Usage already recorded
+// for the model keeps the prices it was charged at. It fails with a
+// *port.ConflictError while flows or test suites still reference the model.
+func (r *ModelRepository) Delete(ctx context.Context, accountID, id uuid.UUID) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		m, err := takeModel(tx, id, "id = ? AND account_id = ?", id, accountID)
+		if err != nil {
+			return err
+		}
+		if err := checkModelUnused(tx, m); err != nil {
+			return err
+		}
+
+		if err := tx.Where("id = ?", id).Delete(&modelRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("model_id = ?", id).Delete(&modelPriceRecord{}).Error
+	})
+}
+
+// ListPrices returns the pricing catalog of a model, oldest entry first.
+func (r *ModelRepository) ListPrices(ctx context.Context, accountID, id uuid.UUID) ([]*domain.ModelPrice, error) {
+	tx := r.db.session(ctx)
+	if _, err := takeModel(tx, id, "id = ? AND account_id = ?", id, accountID); err != nil {
+		return nil, err
+	}
+
+	return findPrices(tx.Where("model_id = ?", id).Order("effective_at, created_at"))
+}
+
+// AddPrice adds an entry to the catalog of its model, which must belong to
+// the entry's account.
+func (r *ModelRepository) AddPrice(ctx context.Context, price *domain.ModelPrice) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := takeModel(tx, price.ModelID, "id = ? AND account_id = ?", price.ModelID, price.AccountID); err != nil {
+			return err
+		}
+		return tx.Create(newModelPriceRecord(price)).Error
+	})
+}
+
+// SyncPrice adds price to its model's catalog unless the latest entry of
+// the same source already charges the same, and stores the model's context
+// window when it is known. It reports whether the price was added.
+func (r *ModelRepository) SyncPrice(ctx context.Context, price *domain.ModelPrice, contextWindow int) (bool, error) {
+	added := false
+	err := r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		if contextWindow > 0 {
+			err := tx.Model(&modelRecord{}).Where("id = ?", price.ModelID).Update("context_window", contextWindow).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		latest, err := findPrices(tx.Where("model_id = ? AND source = ?", price.ModelID, price.Source).
+			Order("effective_at DESC").
+			Limit(1))
+		if err != nil {
+			return err
+		}
+		if len(latest) > 0 && latest[0].SamePrices(price) {
+			return nil
+		}
+		added = true
+		return tx.Create(newModelPriceRecord(price)).Error
+	})
+	return added, err
+}
+
+// takeModel takes the model that query finds, reporting key when there is
+// none.
+func takeModel(tx *gorm.DB, key any, query string, args ...any) (*domain.Model, error) {
+	var record modelRecord
+	if err := tx.Where(query, args...).Take(&record).Error; err != nil {
+		return nil, translateError(err, "model", key)
+	}
+	m := record.toDomain()
+	if err := applyPrices(tx, []*domain.Model{m}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func findModels(tx *gorm.DB) ([]*domain.Model, error) {
+	var records []*modelRecord
+	if err := tx.Order("name").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	models := make([]*domain.Model, 0, len(records))
+	for _, record := range records {
+		models = append(models, record.toDomain())
+	}
+	if err := applyPrices(tx, models); err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+// applyPrices sets the prices of models to those their catalogs have in
+// effect now.
+func applyPrices(tx *gorm.DB, models []*domain.Model) error {
+	if len(models) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(models))
+	for _, m := range models {
+		ids = append(ids, m.ID)
+	}
+
+	now := time.Now()
+	// A fresh session drops the conditions of the query that found models.
+	prices, err := findPrices(tx.Session(&gorm.Session{NewDB: true}).
+		Where("model_id IN ? AND effective_at <= ?", ids, now))
+	if err != nil {
+		return err
+	}
+
+	byModel := map[uuid.UUID][]*domain.ModelPrice{}
+	for _, p := range prices {
+		byModel[p.ModelID] = append(byModel[p.ModelID], p)
+	}
+	for _, m := range models {
+		if p := domain.EffectivePrice(byModel[m.ID], now); p != nil {
+			m.ApplyPrice(p)
+		}
+	}
+	return nil
+}
+
+func findPrices(tx *gorm.DB) ([]*domain.ModelPrice, error) {
+	var records []*modelPriceRecord
+	if err := tx.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	prices := make([]*domain.ModelPrice, 0, len(records))
+	for _, record := range records {
+		prices = append(prices, record.toDomain())
+	}
+	return prices, nil
+}
+
+func addManualPrice(tx *gorm.DB, m *domain.Model) error {
+	price, err := domain.NewModelPrice(m, domain.PriceSourceManual, time.Now())
+	if err != nil {
+		return err
+	}
+	return tx.Create(newModelPriceRecord(price)).Error
+}
+
+func checkModel(tx *gorm.DB, m *domain.Model) error {
+	var providers int64
+	err := tx.Model(&providerRecord{}).
+		Where("id = ? AND account_id = ?", m.ProviderID, m.AccountID).
+		Count(&providers).Error
+	if err != nil {
+		return err
+	}
+	if providers == 0 {
+		return &port.ReferenceError{Field: "provider_id", Value: m.ProviderID.String()}
+	}
+	return nil
+}
+
+// checkModelUnused refuses to delete a model that the prompt steps of flows
+// or the judge assertions of test suites reference by name.
+func checkModelUnused(tx *gorm.DB, m *domain.Model) error {
+	var flows []*flowRecord
+	if err := tx.Where("account_id = ?", m.AccountID).Find(&flows).Error; err != nil {
+		return err
+	}
+	users := 0
+	for _, record := range flows {
+		f, err := record.toDomain()
+		if err != nil {
+			return err
+		}
+		if slices.Contains(f.Definition.ModelNames(), m.Name) {
+			users++
+		}
+	}
+	if users > 0 {
+		return conflict("model", m.Name, fmt.Sprintf("is used by %d flow(s)", users))
+	}
+
+	var suites []*testSuiteRecord
+	if err := tx.Where("account_id = ?", m.AccountID).Find(&suites).Error; err != nil {
+		return err
+	}
+	for _, record := range suites {
+		if slices.Contains(judgeModels(record.toDomain()), m.Name) {
+			users++
+		}
+	}
+	if users > 0 {
+		return conflict("model", m.Name, fmt.Sprintf("is used by %d test suite(s)", users))
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelRepositoryDeleteIfReferenced(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := newTestDatabase(t)
+	repo := NewModelRepository(db)
+	accountID := uuid.New()
+	provider, err := domain.NewProvider(
+		domain.WithProviderID(uuid.New()),
+		domain.WithProviderName("ollama"),
+		domain.WithProviderAccountID(accountID),
+		domain.WithProviderType(domain.ProviderTypeOllama),
+	)
+	require.NoError(t, err)
+	require.NoError(t, NewProviderRepository(db, nil).Create(ctx, provider))
+
+	newModel := func(name string) *domain.Model {
+		m, err := domain.NewModel(
+			domain.WithModelID(uuid.New()),
+			domain.WithModelName(name),
+			domain.WithModelAccountID(accountID),
+			domain.WithModelProviderID(provider.ID),
+		)
+		require.NoError(t, err)
+		require.NoError(t, repo.Create(ctx, m))
+		return m
+	}
+	used, judge, unused := newModel("gpt"), newModel("judge"), newModel("spare")
+
+	flow, err := domain.NewFlow(
+		domain.WithFlowID(uuid.New()),
+		domain.WithFlowAccountID(accountID),
+		domain.WithFlowSource("name: x\nsteps:\n  - {id: a, type: prompt, prompt: {model: gpt, user: x}}\n",
+			domain.FlowSourceFormatYAML),
+	)
+	require.NoError(t, err)
+	require.NoError(t, NewFlowRepository(db).Create(ctx, flow))
+	suite := &domain.TestSuite{
+		ID:        uuid.New(),
+		AccountID: accountID,
+		Name:      "suite",
+		Flow:      "x",
+		Cases:     []domain.TestCase{{Name: "case"}},
+		Assertions: []domain.Assertion{
+			{Type: domain.AssertionTypeJudge, Model: "judge", Value: "is polite"},
+		},
+	}
+	require.NoError(t, NewTestSuiteRepository(db).Create(ctx, suite))
+
+	require.ErrorIs(t, repo.Delete(ctx, accountID, used.ID), port.ErrConflict)
+	require.ErrorIs(t, repo.Delete(ctx, accountID, judge.ID), port.ErrConflict)
+	require.NoError(t, repo.Delete(ctx, accountID, unused.ID))
+	require.ErrorIs(t, repo.Delete(ctx, accountID, unused.ID), port.ErrNotFound)
+}
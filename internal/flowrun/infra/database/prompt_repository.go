@@ -0,0 +1,353 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PromptRepository stores prompts with their revision history. The prompt row
+// holds the latest revision; every template change appends a revision, which
+// is never modified afterwards.
+type PromptRepository struct {
+	db *Database
+}
+
+func NewPromptRepository(db *Database) *PromptRepository {
+	return &PromptRepository{db: db}
+}
+
+// promptRecord is the row of a prompt, with its latest template.
+type promptRecord struct {
+	ID                uuid.UUID
+	AccountID         uuid.UUID
+	Name              string
+	Description       string
+	TemplateSystem    string
+	TemplateUser      string
+	TemplateVariables []domain.FlowVariable `gorm:"serializer:json"`
+	Revision          int
+	Author            string
+}
+
+func (promptRecord) TableName() string {
+	return "prompts"
+}
+
+func newPromptRecord(p *domain.Prompt) *promptRecord {
+	return &promptRecord{
+		ID:                p.ID,
+		AccountID:         p.AccountID,
+		Name:              p.Name,
+		Description:       p.Description,
+		TemplateSystem:    p.Template.System,
+		TemplateUser:      p.Template.User,
+		TemplateVariables: p.Template.Variables,
+		Revision:          p.Revision,
+		Author:            p.Author,
+	}
+}
+
+func (r *promptRecord) toDomain() *domain.Prompt {
+	return &domain.Prompt{
+		ID:          r.ID,
+		AccountID:   r.AccountID,
+		Name:        r.Name,
+		Description: r.Description,
+		Template: domain.PromptTemplate{
+			System:    r.TemplateSystem,
+			User:      r.TemplateUser,
+			Variables: r.TemplateVariables,
+		},
+		Revision: r.Revision,
+		Author:   r.Author,
+	}
+}
+
+// promptRevisionRecord is the row of a prompt revision.
+type promptRevisionRecord struct {
+	ID                uuid.UUID
+	PromptID          uuid.UUID
+	AccountID         uuid.UUID
+	Number            int
+	Author            string
+	ContentHash       string
+	TemplateSystem    string
+	TemplateUser      string
+	TemplateVariables []domain.FlowVariable `gorm:"serializer:json"`
+	CreatedAt         time.Time
+}
+
+func (promptRevisionRecord) TableName() string {
+	return "prompt_revisions"
+}
+
+func newPromptRevisionRecord(revision *domain.PromptRevision) *promptRevisionRecord {
+	return &promptRevisionRecord{
+		ID:                revision.ID,
+		PromptID:          revision.PromptID,
+		AccountID:         revision.AccountID,
+		Number:            revision.Number,
+		Author:            revision.Author,
+		ContentHash:       revision.ContentHash,
+		TemplateSystem:    revision.Template.System,
+		TemplateUser:      revision.Template.User,
+		TemplateVariables: revision.Template.Variables,
+		CreatedAt:         revision.CreatedAt,
+	}
+}
+
+func (r *promptRevisionRecord) toDomain() *domain.PromptRevision {
+	return &domain.PromptRevision{
+		ID:          r.ID,
+		PromptID:    r.PromptID,
+		AccountID:   r.AccountID,
+		Number:      r.Number,
+		Author:      r.Author,
+		ContentHash: r.ContentHash,
+		Template: domain.PromptTemplate{
+			System:    r.TemplateSystem,
+			User:      r.TemplateUser,
+			Variables: r.TemplateVariables,
+		},
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+// promptLabelRecord is the row of a prompt label.
+type promptLabelRecord struct {
+	PromptID  uuid.UUID `gorm:"primaryKey"`
+	Name      string    `gorm:"primaryKey"`
+	AccountID uuid.UUID
+	Revision  int
+	UpdatedAt time.Time
+}
+
+func (promptLabelRecord) TableName() string {
+	return "prompt_labels"
+}
+
+func newPromptLabelRecord(label *domain.PromptLabel) *promptLabelRecord {
+	return &promptLabelRecord{
+		PromptID:  label.PromptID,
+		Name:      label.Name,
+		AccountID: label.AccountID,
+		Revision:  label.Revision,
+		UpdatedAt: label.UpdatedAt,
+	}
+}
+
+func (r *promptLabelRecord) toDomain() *domain.PromptLabel {
+	return &domain.PromptLabel{
+		PromptID:  r.PromptID,
+		Name:      r.Name,
+		AccountID: r.AccountID,
+		Revision:  r.Revision,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+// Create stores a prompt as its revision 1. The name must be unique within
+// the account.
+func (r *PromptRepository) Create(ctx context.Context, p *domain.Prompt) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		p.Revision = 1
+		revision, err := domain.NewPromptRevision(p, p.Revision, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(newPromptRecord(p)).Error; err != nil {
+			return nameTaken(err, "prompt", p.Name)
+		}
+		return tx.Create(newPromptRevisionRecord(revision)).Error
+	})
+}
+
+func (r *PromptRepository) Get(ctx context.Context, accountID, id uuid.UUID) (*domain.Prompt, error) {
+	var record promptRecord
+	err := r.db.session(ctx).
+		Where("id = ? AND account_id = ?", id, accountID).
+		Take(&record).Error
+	if err != nil {
+		return nil, translateError(err, "prompt", id)
+	}
+	return record.toDomain(), nil
+}
+
+func (r *PromptRepository) List(ctx context.Context, accountID uuid.UUID) ([]*domain.Prompt, error) {
+	var records []*promptRecord
+	err := r.db.session(ctx).
+		Where("account_id = ?", accountID).
+		Order("name").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	prompts := make([]*domain.Prompt, 0, len(records))
+	for _, record := range records {
+		prompts = append(prompts, record.toDomain())
+	}
+	return prompts, nil
+}
+
+// Update stores p as the latest state of the prompt. A changed template
+// becomes a new revision authored by p.Author; an unchanged one keeps the
+// current revision and its author, so metadata edits do not add revisions.
+func (r *PromptRepository) Update(ctx context.Context, p *domain.Prompt) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		var current promptRecord
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND account_id = ?", p.ID, p.AccountID).
+			Take(&current).Error
+		if err != nil {
+			return translateError(err, "prompt", p.ID)
+		}
+
+		if current.toDomain().Template.Hash() == p.Template.Hash() {
+			p.Revision, p.Author = current.Revision, current.Author
+		} else {
+			p.Revision = current.Revision + 1
+			revision, err := domain.NewPromptRevision(p, p.Revision, time.Now())
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(newPromptRevisionRecord(revision)).Error; err != nil {
+				return err
+			}
+		}
+
+		// Updating from the struct keeps the variables column serialized.
+		err = tx.Model(&promptRecord{}).
+			Where("id = ? AND account_id = ?", p.ID, p.AccountID).
+			Select("name", "description", "template_system", "template_user", "template_variables", "revision", "author").
+			Updates(newPromptRecord(p)).Error
+		return nameTaken(err, "prompt", p.Name)
+	})
+}
+
+// Delete removes the prompt with its revisions and labels.
+func (r *PromptRepository) Delete(ctx context.Context, accountID, id uuid.UUID) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND account_id = ?", id, accountID).Delete(&promptRecord{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return notFound("prompt", id)
+		}
+
+		if err := tx.Where("prompt_id = ?", id).Delete(&promptLabelRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("prompt_id = ?", id).Delete(&promptRevisionRecord{}).Error
+	})
+}
+
+// ListRevisions returns the revisions of a prompt, oldest first.
+func (r *PromptRepository) ListRevisions(
+	ctx context.Context, accountID, promptID uuid.UUID,
+) ([]*domain.PromptRevision, error) {
+	var records []*promptRevisionRecord
+	err := r.db.session(ctx).
+		Where("prompt_id = ? AND account_id = ?", promptID, accountID).
+		Order("number").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	revisions := make([]*domain.PromptRevision, 0, len(records))
+	for _, record := range records {
+		revisions = append(revisions, record.toDomain())
+	}
+	return revisions, nil
+}
+
+func (r *PromptRepository) GetRevision(
+	ctx context.Context, accountID, promptID uuid.UUID, number int,
+) (*domain.PromptRevision, error) {
+	return takeRevision(r.db.session(ctx), accountID, promptID, number)
+}
+
+// SetLabel points a label at a revision, creating the label or moving it.
+// The revision must exist.
+func (r *PromptRepository) SetLabel(ctx context.Context, label *domain.PromptLabel) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		_, err := takeRevision(tx, label.AccountID, label.PromptID, label.Revision)
+		if errors.Is(err, port.ErrNotFound) {
+			return &port.ReferenceError{Field: "revision", Value: strconv.Itoa(label.Revision)}
+		}
+		if err != nil {
+			return err
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "prompt_id"}, {Name: "name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"revision", "updated_at"}),
+		}).Create(newPromptLabelRecord(label)).Error
+	})
+}
+
+func (r *PromptRepository) ListLabels(
+	ctx context.Context, accountID, promptID uuid.UUID,
+) ([]*domain.PromptLabel, error) {
+	var records []*promptLabelRecord
+	err := r.db.session(ctx).
+		Where("prompt_id = ? AND account_id = ?", promptID, accountID).
+		Order("name").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	labels := make([]*domain.PromptLabel, 0, len(records))
+	for _, record := range records {
+		labels = append(labels, record.toDomain())
+	}
+	return labels, nil
+}
+
+// ResolvePrompt returns the revision a prompt step references.
+func (r *PromptRepository) ResolvePrompt(
+	ctx context.Context, accountID uuid.UUID, ref domain.PromptRef,
+) (*domain.PromptRevision, error) {
+	tx := r.db.session(ctx)
+
+	var record promptRecord
+	if err := tx.Where("account_id = ? AND name = ?", accountID, ref.Name).Take(&record).Error; err != nil {
+		return nil, translateError(err, "prompt", ref.Name)
+	}
+	return resolveRevision(tx, record.toDomain(), ref)
+}
+
+// resolveRevision returns the revision of p that ref selects: the pinned
+// revision, the one its label points at, or the latest.
+func resolveRevision(tx *gorm.DB, p *domain.Prompt, ref domain.PromptRef) (*domain.PromptRevision, error) {
+	number := p.Revision
+	switch {
+	case ref.Revision > 0:
+		number = ref.Revision
+	case ref.Label != "":
+		var label promptLabelRecord
+		if err := tx.Where("prompt_id = ? AND name = ?", p.ID, ref.Label).Take(&label).Error; err != nil {
+			return nil, translateError(err, "prompt label", ref.Label)
+		}
+		number = label.Revision
+	}
+	return takeRevision(tx, p.AccountID, p.ID, number)
+}
+
+func takeRevision(tx *gorm.DB, accountID, promptID uuid.UUID, number int) (*domain.PromptRevision, error) {
+	var record promptRevisionRecord
+	err := tx.Where("prompt_id = ? AND account_id = ? AND number = ?", promptID, accountID, number).
+		Take(&record).Error
+	if err != nil {
+		return nil, translateError(err, "prompt revision", number)
+	}
+	return record.toDomain(), nil
+}
@@ -0,0 +1,221 @@
+package database
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/lib/envelope"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProviderRepository never stores a plaintext API key: Create and Update seal
+// Provider.ApiKey with the keyring, using the provider ID as associated data.
+type ProviderRepository struct {
+	db      *Database
+	keyring *envelope.Keyring
+}
+
+func NewProviderRepository(db *Database, keyring *envelope.Keyring) *ProviderRepository {
+	return &ProviderRepository{db: db, keyring: keyring}
+}
+
+// providerRecord is the row of a provider. It only holds the sealed API key.
+type providerRecord struct {
+	ID              uuid.UUID
+	AccountID       uuid.UUID
+	Name            string
+	Type            domain.ProviderType
+	BaseURL         string
+	EncryptedApiKey *envelope.Sealed `gorm:"embedded;embeddedPrefix:api_key_"`
+}
+
+func (providerRecord) TableName() string {
+	return "providers"
+}
+
+func newProviderRecord(p *domain.Provider) *providerRecord {
+	return &providerRecord{
+		ID:              p.ID,
+		AccountID:       p.AccountID,
+		Name:            p.Name,
+		Type:            p.Type,
+		BaseURL:         p.BaseURL,
+		EncryptedApiKey: p.EncryptedApiKey,
+	}
+}
+
+func (r *providerRecord) toDomain() *domain.Provider {
+	return &domain.Provider{
+		ID:              r.ID,
+		AccountID:       r.AccountID,
+		Name:            r.Name,
+		Type:            r.Type,
+		BaseURL:         r.BaseURL,
+		EncryptedApiKey: r.EncryptedApiKey,
+	}
+}
+
+func toProviders(records []*providerRecord) []*domain.Provider {
+	providers := make([]*domain.Provider, 0, len(records))
+	for _, record := range records {
+		providers = append(providers, record.toDomain())
+	}
+	return providers
+}
+
+func (r *ProviderRepository) Create(ctx context.Context, p *domain.Provider) error {
+	if err := r.sealApiKey(p); err != nil {
+		return err
+	}
+	return nameTaken(r.db.session(ctx).Create(newProviderRecord(p)).Error, "provider", p.Name)
+}
+
+func (r *ProviderRepository) Get(ctx context.Context, accountID, id uuid.UUID) (*domain.Provider, error) {
+	var p providerRecord
+	err := r.db.session(ctx).
+		Where("id = ? AND account_id = ?", id, accountID).
+		Take(&p).Error
+	if err != nil {
+		return nil, translateError(err, "provider", id)
+	}
+	return p.toDomain(), nil
+}
+
+func (r *ProviderRepository) List(ctx context.Context, accountID uuid.UUID) ([]*domain.Provider, error) {
+	var providers []*providerRecord
+	err := r.db.session(ctx).
+		Where("account_id = ?", accountID).
+		Order("name").
+		Find(&providers).Error
+	if err != nil {
+		return nil, err
+	}
+	return toProviders(providers), nil
+}
+
+// ListByType returns the providers of every account that have type t.
+func (r *ProviderRepository) ListByType(ctx context.Context, t domain.ProviderType) ([]*domain.Provider, error) {
+	var providers []*providerRecord
+	err := r.db.session(ctx).
+		Where("type = ?", t).
+		Order("id").
+		Find(&providers).Error
+	if err != nil {
+		return nil, err
+	}
+	return toProviders(providers), nil
+}
+
+func (r *ProviderRepository) Update(ctx context.Context, p *domain.Provider) error {
+	if err := r.sealApiKey(p); err != nil {
+		return err
+	}
+
+	columns := sealedApiKeyColumns(p.EncryptedApiKey)
+	columns["name"] = p.Name
+	columns["type"] = p.Type
+	columns["base_url"] = p.BaseURL
+
+	result := r.db.session(ctx).
+		Model(&providerRecord{}).
+		Where("id = ? AND account_id = ?", p.ID, p.AccountID).
+		Updates(columns)
+	if result.Error != nil {
+		return nameTaken(result.Error, "provider", p.Name)
+	}
+	if result.RowsAffected == 0 {
+		return notFound("provider", p.ID)
+	}
+	return nil
+}
+
+// Delete removes a provider. It fails with a *port.ConflictError while models
+// still reference the provider.
+func (r *ProviderRepository) Delete(ctx context.Context, accountID, id uuid.UUID) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		var models int64
+		if err := tx.Model(&modelRecord{}).Where("provider_id = ?", id).Count(&models).Error; err != nil {
+			return err
+		}
+		if models > 0 {
+			return conflict("provider", id, fmt.Sprintf("is used by %d model(s)", models))
+		}
+
+		result := tx.Where("id = ? AND account_id = ?", id, accountID).Delete(&providerRecord{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return notFound("provider", id)
+		}
+		return nil
+	})
+}
+
+// RotateKeys rewraps every stored API key whose data key is still wrapped by
+// an older master key. It returns the number of rows changed. The plaintext
+// keys of releases that predate encryption are sealed by the migrations.
+func (r *ProviderRepository) RotateKeys(ctx context.Context) (int, error) {
+	var stale []*providerRecord
+	err := r.db.session(ctx).
+		Where("api_key_key_version <> ?", r.keyring.ActiveVersion()).
+		Find(&stale).Error
+	if err != nil {
+		return 0, err
+	}
+
+	rotated := 0
+	for _, p := range stale {
+		if p.EncryptedApiKey.IsZero() {
+			continue
+		}
+		rewrapped, err := r.keyring.Rewrap(p.EncryptedApiKey, p.ID[:])
+		if err != nil {
+			return rotated, fmt.Errorf("provider %s: %w", p.ID, err)
+		}
+		// The version guard skips rows a concurrent update has already
+		// re-sealed with the active key.
+		err = r.db.session(ctx).
+			Model(&providerRecord{}).
+			Where("id = ? AND api_key_key_version = ?", p.ID, p.EncryptedApiKey.KeyVersion).
+			Updates(sealedApiKeyColumns(rewrapped)).Error
+		if err != nil {
+			return rotated, err
+		}
+		rotated++
+	}
+
+	return rotated, nil
+}
+
+// sealApiKey replaces a plaintext ApiKey with its sealed form.
+func (r *ProviderRepository) sealApiKey(p *domain.Provider) error {
+	if p.ApiKey == "" {
+		return nil
+	}
+
+	sealed, err := r.keyring.Seal([]byte(p.ApiKey), p.ID[:])
+	if err != nil {
+		return err
+	}
+	p.EncryptedApiKey = sealed
+	p.ApiKey = ""
+	return nil
+}
+
+func sealedApiKeyColumns(sealed *envelope.Sealed) map[string]any {
+	if sealed.IsZero() {
+		return map[string]any{
+			"api_key_key_version": nil,
+			"api_key_data_key":    nil,
+			"api_key_ciphertext":  nil,
+		}
+	}
+	return map[string]any{
+		"api_key_key_version": sealed.KeyVersion,
+		"api_key_data_key":    sealed.DataKey,
+		"api_key_ciphertext":  sealed.Ciphertext,
+	}
+}
@@ -0,0 +1,335 @@
+package database
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RunRepository persists flow runs and their step runs. Runs are written as
+// they progress, so whatever state a run reached survives the process.
+type RunRepository struct {
+	db *Database
+}
+
+func NewRunRepository(db *Database) *RunRepository {
+	return &RunRepository{db: db}
+}
+
+// flowRunRecord is the row of a flow run.
+type flowRunRecord struct {
+	ID              uuid.UUID
+	AccountID       uuid.UUID
+	FlowID          uuid.UUID
+	ParentStepRunID *uuid.UUID
+	Model           string
+	Status          domain.RunStatus
+	Inputs          map[string]any `gorm:"serializer:json"`
+	Outputs         map[string]any `gorm:"serializer:json"`
+	Error           string
+	Usage           domain.TokenUsage `gorm:"embedded;embeddedPrefix:usage_"`
+	Cost            domain.Money
+	CreatedAt       time.Time
+	StartedAt       *time.Time
+	FinishedAt      *time.Time
+
+	CancelRequestedAt *time.Time
+	LeaseExpiresAt    *time.Time
+}
+
+func (flowRunRecord) TableName() string {
+	return "flow_runs"
+}
+
+func newFlowRunRecord(run *domain.FlowRun) *flowRunRecord {
+	return &flowRunRecord{
+		ID:              run.ID,
+		AccountID:       run.AccountID,
+		FlowID:          run.FlowID,
+		ParentStepRunID: run.ParentStepRunID,
+		Model:           run.Model,
+		Status:          run.Status,
+		Inputs:          run.Inputs,
+		Outputs:         run.Outputs,
+		Error:           run.Error,
+		Usage:           run.Usage,
+		Cost:            run.Cost,
+		CreatedAt:       run.CreatedAt,
+		StartedAt:       run.StartedAt,
+		FinishedAt:      run.FinishedAt,
+
+		CancelRequestedAt: run.CancelRequestedAt,
+		LeaseExpiresAt:    run.LeaseExpiresAt,
+	}
+}
+
+func (r *flowRunRecord) toDomain() *domain.FlowRun {
+	return &domain.FlowRun{
+		ID:              r.ID,
+		AccountID:       r.AccountID,
+		FlowID:          r.FlowID,
+		ParentStepRunID: r.ParentStepRunID,
+		Model:           r.Model,
+		Status:          r.Status,
+		Inputs:          r.Inputs,
+		Outputs:         r.Outputs,
+		Error:           r.Error,
+		Usage:           r.Usage,
+		Cost:            r.Cost,
+		CreatedAt:       r.CreatedAt,
+		StartedAt:       r.StartedAt,
+		FinishedAt:      r.FinishedAt,
+
+		CancelRequestedAt: r.CancelRequestedAt,
+		LeaseExpiresAt:    r.LeaseExpiresAt,
+	}
+}
+
+// stepRunRecord is the row of a step run.
+type stepRunRecord struct {
+	ID               uuid.UUID
+	RunID            uuid.UUID
+	StepID           string
+	Position         int
+	Type             domain.StepType
+	Status           domain.RunStatus
+	Inputs           map[string]any `gorm:"serializer:json"`
+	Output           any            `gorm:"serializer:json"`
+	Error            string
+	Usage            domain.TokenUsage `gorm:"embedded;embeddedPrefix:usage_"`
+	Cost             domain.Money
+	ChildRunID       *uuid.UUID
+	PromptRevisionID *uuid.UUID
+	StartedAt        *time.Time
+	FinishedAt       *time.Time
+}
+
+func (stepRunRecord) TableName() string {
+	return "step_runs"
+}
+
+func newStepRunRecord(step *domain.StepRun) *stepRunRecord {
+	return &stepRunRecord{
+		ID:               step.ID,
+		RunID:            step.RunID,
+		StepID:           step.StepID,
+		Position:         step.Position,
+		Type:             step.Type,
+		Status:           step.Status,
+		Inputs:           step.Inputs,
+		Output:           step.Output,
+		Error:            step.Error,
+		Usage:            step.Usage,
+		Cost:             step.Cost,
+		ChildRunID:       step.ChildRunID,
+		PromptRevisionID: step.PromptRevisionID,
+		StartedAt:        step.StartedAt,
+		FinishedAt:       step.FinishedAt,
+	}
+}
+
+func (r *stepRunRecord) toDomain() *domain.StepRun {
+	return &domain.StepRun{
+		ID:               r.ID,
+		RunID:            r.RunID,
+		StepID:           r.StepID,
+		Position:         r.Position,
+		Type:             r.Type,
+		Status:           r.Status,
+		Inputs:           r.Inputs,
+		Output:           r.Output,
+		Error:            r.Error,
+		Usage:            r.Usage,
+		Cost:             r.Cost,
+		ChildRunID:       r.ChildRunID,
+		PromptRevisionID: r.PromptRevisionID,
+		StartedAt:        r.StartedAt,
+		FinishedAt:       r.FinishedAt,
+	}
+}
+
+func (r *RunRepository) CreateRun(ctx context.Context, run *domain.FlowRun, steps []*domain.StepRun) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		record := newFlowRunRecord(run)
+		if err := tx.Create(record).Error; err != nil {
+			return nameTaken(err, "run", run.ID.String())
+		}
+		// gorm fills in a zero creation time.
+		run.CreatedAt = record.CreatedAt
+		if len(steps) == 0 {
+			return nil
+		}
+		records := make([]*stepRunRecord, 0, len(steps))
+		for _, step := range steps {
+			records = append(records, newStepRunRecord(step))
+		}
+		return tx.Create(records).Error
+	})
+}
+
+// UpdateRun and UpdateStepRun save whole rows: the JSON columns are only
+// serialized when gorm writes a struct. A missing row is reported rather
+// than inserted. UpdateRun leaves cancel_requested_at to CancelRun and
+// lease_expires_at to ExtendRunLease.
+func (r *RunRepository) UpdateRun(ctx context.Context, run *domain.FlowRun) error {
+	result := r.db.session(ctx).
+		Select("*").
+		Omit("cancel_requested_at", "lease_expires_at").
+		Updates(newFlowRunRecord(run))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return notFound("run", run.ID)
+	}
+	return nil
+}
+
+func (r *RunRepository) UpdateStepRun(ctx context.Context, step *domain.StepRun) error {
+	tx := r.db.session(ctx).Select("*")
+	// gorm's JSON serializer cannot save a nil interface. A step has no output
+	// until it succeeds, so the column is left NULL instead.
+	if step.Output == nil {
+		tx = tx.Omit("output")
+	}
+	result := tx.Updates(newStepRunRecord(step))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return notFound("step run", step.ID)
+	}
+	return nil
+}
+
+func (r *RunRepository) GetRun(ctx context.Context, accountID, id uuid.UUID) (*domain.FlowRun, error) {
+	var run flowRunRecord
+	err := r.db.session(ctx).
+		Where("id = ? AND account_id = ?", id, accountID).
+		Take(&run).Error
+	if err != nil {
+		return nil, translateError(err, "run", id)
+	}
+	return run.toDomain(), nil
+}
+
+func (r *RunRepository) ListStepRuns(ctx context.Context, runID uuid.UUID) ([]*domain.StepRun, error) {
+	var records []*stepRunRecord
+	err := r.db.session(ctx).
+		Where("run_id = ?", runID).
+		Order("position").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	steps := make([]*domain.StepRun, 0, len(records))
+	for _, record := range records {
+		steps = append(steps, record.toDomain())
+	}
+	return steps, nil
+}
+
+func (r *RunRepository) CancelRun(
+	ctx context.Context, accountID, id uuid.UUID, now time.Time,
+) (*domain.FlowRun, error) {
+	var run flowRunRecord
+	err := r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&flowRunRecord{}).
+			Where("id = ? AND account_id = ? AND status IN ? AND cancel_requested_at IS NULL",
+				id, accountID, []domain.RunStatus{domain.RunStatusPending, domain.RunStatusRunning}).
+			Update("cancel_requested_at", now).Error
+		if err != nil {
+			return err
+		}
+
+		// Only a pending run that no worker started meanwhile is canceled here.
+		result := tx.Model(&flowRunRecord{}).
+			Where("id = ? AND account_id = ? AND status = ?", id, accountID, domain.RunStatusPending).
+			Updates(map[string]any{"status": domain.RunStatusCanceled, "finished_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			err = tx.Model(&stepRunRecord{}).
+				Where("run_id = ? AND status = ?", id, domain.RunStatusPending).
+				Updates(map[string]any{"status": domain.RunStatusSkipped, "finished_at": now}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Where("id = ? AND account_id = ?", id, accountID).Take(&run).Error
+	})
+	if err != nil {
+		return nil, translateError(err, "run", id)
+	}
+	return run.toDomain(), nil
+}
+
+func (r *RunRepository) ExtendRunLease(ctx context.Context, id uuid.UUID, until time.Time) error {
+	result := r.db.session(ctx).
+		Model(&flowRunRecord{}).
+		Where("id = ?", id).
+		Update("lease_expires_at", until)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return notFound("run", id)
+	}
+	return nil
+}
+
+// expiredRunsQuery selects the running root runs whose lease expired, and
+// their unfinished sub-flow runs at any depth.
+const expiredRunsQuery = `WITH RECURSIVE expired(id) AS (
+	SELECT id FROM flow_runs
+	WHERE status = ? AND parent_step_run_id IS NULL AND lease_expires_at <= ?
+	UNION
+	SELECT flow_runs.id FROM flow_runs
+	JOIN step_runs ON step_runs.id = flow_runs.parent_step_run_id
+	JOIN expired ON expired.id = step_runs.run_id
+	WHERE flow_runs.status IN ?
+)
+SELECT id FROM expired`
+
+func (r *RunRepository) FailExpiredRuns(ctx context.Context, now time.Time, message string) (int, error) {
+	unfinished := []domain.RunStatus{domain.RunStatusPending, domain.RunStatusRunning}
+
+	var failed int
+	err := r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []uuid.UUID
+		if err := tx.Raw(expiredRunsQuery, domain.RunStatusRunning, now, unfinished).Scan(&ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		err := tx.Model(&stepRunRecord{}).
+			Where("run_id IN ? AND status = ?", ids, domain.RunStatusRunning).
+			Updates(map[string]any{"status": domain.RunStatusFailed, "error": message, "finished_at": now}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&stepRunRecord{}).
+			Where("run_id IN ? AND status = ?", ids, domain.RunStatusPending).
+			Updates(map[string]any{"status": domain.RunStatusSkipped, "finished_at": now}).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Model(&flowRunRecord{}).
+			Where("id IN ? AND status IN ?", ids, unfinished).
+			Updates(map[string]any{"status": domain.RunStatusFailed, "error": message, "finished_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		failed = int(result.RowsAffected)
+		return nil
+	})
+	return failed, err
+}
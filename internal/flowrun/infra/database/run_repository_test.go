@@ -0,0 +1,15 @@
+package database
+
+import (
+	"flow-run/internal/core/port"
+	"flow-run/internal/flowrun/infra/repotest"
+	"testing"
+)
+
+func TestRunRepository(t *testing.T) {
+	t.Parallel()
+
+	repotest.RunRepository(t, func(t *testing.T) port.FlowRunRepository {
+		return NewRunRepository(newTestDatabase(t))
+	})
+}
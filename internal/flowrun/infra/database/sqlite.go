@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// utcConnPool hands SQLite every time in UTC. SQLite stores times as text
+// with their offset and compares them as text, which orders only the times
+// written with the same offset: a time.Now() in the local zone would not
+// compare with a period start in UTC.
+type utcConnPool struct {
+	gorm.ConnPool
+}
+
+// inUTC wraps the connection pool of db, once it is open.
+func inUTC(db *gorm.DB) {
+	db.ConnPool = &utcConnPool{db.ConnPool}
+	db.Statement.ConnPool = db.ConnPool
+}
+
+func (p *utcConnPool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return p.ConnPool.ExecContext(ctx, query, utcArgs(args)...)
+}
+
+func (p *utcConnPool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return p.ConnPool.QueryContext(ctx, query, utcArgs(args)...)
+}
+
+func (p *utcConnPool) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return p.ConnPool.QueryRowContext(ctx, query, utcArgs(args)...)
+}
+
+// BeginTx keeps the transaction in UTC too.
+func (p *utcConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	db, ok := p.ConnPool.(*sql.DB)
+	if !ok {
+		return nil, gorm.ErrInvalidTransaction
+	}
+
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &utcTx{utcConnPool{tx}, tx}, nil
+}
+
+// GetDBConn lets gorm.DB.DB reach the pool under the wrapper.
+func (p *utcConnPool) GetDBConn() (*sql.DB, error) {
+	db, ok := p.ConnPool.(*sql.DB)
+	if !ok {
+		return nil, gorm.ErrInvalidDB
+	}
+	return db, nil
+}
+
+type utcTx struct {
+	utcConnPool
+	tx *sql.Tx
+}
+
+func (t *utcTx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *utcTx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+func utcArgs(args []any) []any {
+	for i, arg := range args {
+		switch value := arg.(type) {
+		case time.Time:
+			args[i] = value.UTC()
+		case *time.Time:
+			if value != nil {
+				args[i] = value.UTC()
+			}
+		}
+	}
+	return args
+}
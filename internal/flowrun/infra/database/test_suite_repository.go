@@ -0,0 +1,233 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TestSuiteRepository stores test suites. The prompt or flow a suite is
+// attached to, and the dataset it runs over, must exist in the suite's
+// account when the suite is saved.
+type TestSuiteRepository struct {
+	db *Database
+}
+
+func NewTestSuiteRepository(db *Database) *TestSuiteRepository {
+	return &TestSuiteRepository{db: db}
+}
+
+// testSuiteRecord is the row of a test suite. Cases and suite-wide
+// assertions are stored as JSON.
+type testSuiteRecord struct {
+	ID             uuid.UUID
+	AccountID      uuid.UUID
+	Name           string
+	Description    string
+	Prompt         string
+	Flow           string
+	Cases          []domain.TestCase `gorm:"serializer:json"`
+	Dataset        string
+	DatasetVersion int
+	Assertions     []domain.Assertion `gorm:"serializer:json"`
+}
+
+func (testSuiteRecord) TableName() string {
+	return "test_suites"
+}
+
+func newTestSuiteRecord(s *domain.TestSuite) *testSuiteRecord {
+	return &testSuiteRecord{
+		ID:             s.ID,
+		AccountID:      s.AccountID,
+		Name:           s.Name,
+		Description:    s.Description,
+		Prompt:         s.Prompt,
+		Flow:           s.Flow,
+		Cases:          s.Cases,
+		Dataset:        s.Dataset,
+		DatasetVersion: s.DatasetVersion,
+		Assertions:     s.Assertions,
+	}
+}
+
+func (r *testSuiteRecord) toDomain() *domain.TestSuite {
+	return &domain.TestSuite{
+		ID:             r.ID,
+		AccountID:      r.AccountID,
+		Name:           r.Name,
+		Description:    r.Description,
+		Prompt:         r.Prompt,
+		Flow:           r.Flow,
+		Cases:          r.Cases,
+		Dataset:        r.Dataset,
+		DatasetVersion: r.DatasetVersion,
+		Assertions:     r.Assertions,
+	}
+}
+
+func (r *TestSuiteRepository) Create(ctx context.Context, s *domain.TestSuite) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkTestSuite(tx, s); err != nil {
+			return err
+		}
+		return nameTaken(tx.Create(newTestSuiteRecord(s)).Error, "test suite", s.Name)
+	})
+}
+
+func (r *TestSuiteRepository) Get(ctx context.Context, accountID, id uuid.UUID) (*domain.TestSuite, error) {
+	var record testSuiteRecord
+	err := r.db.session(ctx).
+		Where("id = ? AND account_id = ?", id, accountID).
+		Take(&record).Error
+	if err != nil {
+		return nil, translateError(err, "test suite", id)
+	}
+	return record.toDomain(), nil
+}
+
+func (r *TestSuiteRepository) List(ctx context.Context, accountID uuid.UUID) ([]*domain.TestSuite, error) {
+	var records []*testSuiteRecord
+	err := r.db.session(ctx).
+		Where("account_id = ?", accountID).
+		Order("name").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	suites := make([]*domain.TestSuite, 0, len(records))
+	for _, record := range records {
+		suites = append(suites, record.toDomain())
+	}
+	return suites, nil
+}
+
+func (r *TestSuiteRepository) Update(ctx context.Context, s *domain.TestSuite) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkTestSuite(tx, s); err != nil {
+			return err
+		}
+
+		// Updating from the struct keeps the cases column serialized.
+		result := tx.Model(&testSuiteRecord{}).
+			Where("id = ? AND account_id = ?", s.ID, s.AccountID).
+			Select("name", "description", "prompt", "flow", "cases", "dataset", "dataset_version", "assertions").
+			Updates(newTestSuiteRecord(s))
+		if result.Error != nil {
+			return nameTaken(result.Error, "test suite", s.Name)
+		}
+		if result.RowsAffected == 0 {
+			return notFound("test suite", s.ID)
+		}
+		return nil
+	})
+}
+
+// Delete removes the suite with its runs and their case results.
+func (r *TestSuiteRepository) Delete(ctx context.Context, accountID, id uuid.UUID) error {
+	return r.db.session(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND account_id = ?", id, accountID).Delete(&testSuiteRecord{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return notFound("test suite", id)
+		}
+
+		runs := tx.Model(&testSuiteRunRecord{}).Select("id").Where("suite_id = ?", id)
+		if err := tx.Where("run_id IN (?)", runs).Delete(&testCaseResultRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("suite_id = ?", id).Delete(&testSuiteRunRecord{}).Error
+	})
+}
+
+func checkTestSuite(tx *gorm.DB, s *domain.TestSuite) error {
+	field, name, model := "prompt", s.Prompt, any(&promptRecord{})
+	if s.Flow != "" {
+		field, name, model = "flow", s.Flow, &flowRecord{}
+	}
+	existing, err := existingNames(tx, model, s.AccountID, []string{name})
+	if err != nil {
+		return err
+	}
+	if !existing[name] {
+		return &port.ReferenceError{Field: field, Value: name}
+	}
+	if err := checkSuiteDataset(tx, s); err != nil {
+		return err
+	}
+	return checkJudgeModels(tx, s)
+}
+
+// checkSuiteDataset verifies that the dataset of a dataset suite exists,
+// with its pinned version if any.
+func checkSuiteDataset(tx *gorm.DB, s *domain.TestSuite) error {
+	if s.Dataset == "" {
+		return nil
+	}
+
+	var d datasetRecord
+	err := tx.Where("account_id = ? AND name = ?", s.AccountID, s.Dataset).Take(&d).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &port.ReferenceError{Field: "dataset", Value: s.Dataset}
+	}
+	if err != nil {
+		return err
+	}
+	if s.DatasetVersion == 0 {
+		return nil
+	}
+	_, err = takeDatasetVersion(tx, s.AccountID, d.ID, s.DatasetVersion)
+	if errors.Is(err, port.ErrNotFound) {
+		return &port.ReferenceError{Field: "dataset_version", Value: strconv.Itoa(s.DatasetVersion)}
+	}
+	return err
+}
+
+// checkJudgeModels verifies that the judge models of the suite's judge
+// assertions exist.
+func checkJudgeModels(tx *gorm.DB, s *domain.TestSuite) error {
+	existing, err := existingNames(tx, &modelRecord{}, s.AccountID, judgeModels(s))
+	if err != nil {
+		return err
+	}
+
+	for i, a := range s.Assertions {
+		if a.Type == domain.AssertionTypeJudge && !existing[a.Model] {
+			return &port.ReferenceError{Field: fmt.Sprintf("assertions[%d].model", i), Value: a.Model}
+		}
+	}
+	for i, testCase := range s.Cases {
+		for j, a := range testCase.Assertions {
+			if a.Type == domain.AssertionTypeJudge && !existing[a.Model] {
+				return &port.ReferenceError{Field: fmt.Sprintf("cases[%d].assertions[%d].model", i, j), Value: a.Model}
+			}
+		}
+	}
+	return nil
+}
+
+// judgeModels lists the models the judge assertions of s grade with.
+func judgeModels(s *domain.TestSuite) []string {
+	var names []string
+	for _, a := range s.Assertions {
+		if a.Type == domain.AssertionTypeJudge {
+			names = append(names, a.Model)
+		}
+	}
+	for _, testCase := range s.Cases {
+		for _, a := range testCase.Assertions {
+			if a.Type == domain.AssertionTypeJudge {
+				names = append(names, a.Model)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,259 @@
+package database
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TestSuiteRunRepository persists test suite runs and their case results.
+type TestSuiteRunRepository struct {
+	db *Database
+}
+
+func NewTestSuiteRunRepository(db *Database) *TestSuiteRunRepository {
+	return &TestSuiteRunRepository{db: db}
+}
+
+// testSuiteRunRecord is the row of a test suite run.
+type testSuiteRunRecord struct {
+	ID               uuid.UUID
+	AccountID        uuid.UUID
+	SuiteID          uuid.UUID
+	Model            string
+	PromptRevisionID *uuid.UUID
+	PromptRevision   int
+	DatasetVersionID *uuid.UUID
+	DatasetVersion   int
+	Status           domain.RunStatus
+	Error            string
+	Passed           int
+	Failed           int
+	Usage            domain.TokenUsage `gorm:"embedded;embeddedPrefix:usage_"`
+	Cost             domain.Money
+	JudgeUsage       domain.TokenUsage `gorm:"embedded;embeddedPrefix:judge_usage_"`
+	JudgeCost        domain.Money
+	CreatedAt        time.Time
+	StartedAt        *time.Time
+	FinishedAt       *time.Time
+	LeaseExpiresAt   *time.Time
+}
+
+func (testSuiteRunRecord) TableName() string {
+	return "test_suite_runs"
+}
+
+func newTestSuiteRunRecord(run *domain.TestSuiteRun) *testSuiteRunRecord {
+	return &testSuiteRunRecord{
+		ID:               run.ID,
+		AccountID:        run.AccountID,
+		SuiteID:          run.SuiteID,
+		Model:            run.Model,
+		PromptRevisionID: run.PromptRevisionID,
+		PromptRevision:   run.PromptRevision,
+		DatasetVersionID: run.DatasetVersionID,
+		DatasetVersion:   run.DatasetVersion,
+		Status:           run.Status,
+		Error:            run.Error,
+		Passed:           run.Passed,
+		Failed:           run.Failed,
+		Usage:            run.Usage,
+		Cost:             run.Cost,
+		JudgeUsage:       run.JudgeUsage,
+		JudgeCost:        run.JudgeCost,
+		CreatedAt:        run.CreatedAt,
+		StartedAt:        run.StartedAt,
+		FinishedAt:       run.FinishedAt,
+		LeaseExpiresAt:   run.LeaseExpiresAt,
+	}
+}
+
+func (r *testSuiteRunRecord) toDomain() *domain.TestSuiteRun {
+	return &domain.TestSuiteRun{
+		ID:               r.ID,
+		AccountID:        r.AccountID,
+		SuiteID:          r.SuiteID,
+		Model:            r.Model,
+		PromptRevisionID: r.PromptRevisionID,
+		PromptRevision:   r.PromptRevision,
+		DatasetVersionID: r.DatasetVersionID,
+		DatasetVersion:   r.DatasetVersion,
+		Status:           r.Status,
+		Error:            r.Error,
+		Passed:           r.Passed,
+		Failed:           r.Failed,
+		Usage:            r.Usage,
+		Cost:             r.Cost,
+		JudgeUsage:       r.JudgeUsage,
+		JudgeCost:        r.JudgeCost,
+		CreatedAt:        r.CreatedAt,
+		StartedAt:        r.StartedAt,
+		FinishedAt:       r.FinishedAt,
+		LeaseExpiresAt:   r.LeaseExpiresAt,
+	}
+}
+
+// testCaseResultRecord is the row of a test case result. Its output and
+// assertion results are stored as JSON.
+type testCaseResultRecord struct {
+	ID         uuid.UUID
+	RunID      uuid.UUID
+	Position   int
+	Name       string
+	Passed     bool
+	Output     any `gorm:"serializer:json"`
+	Error      string
+	Assertions []domain.AssertionResult `gorm:"serializer:json"`
+	FlowRunID  *uuid.UUID
+	Usage      domain.TokenUsage `gorm:"embedded;embeddedPrefix:usage_"`
+	Cost       domain.Money
+	JudgeUsage domain.TokenUsage `gorm:"embedded;embeddedPrefix:judge_usage_"`
+	JudgeCost  domain.Money
+	LatencyMS  int64
+}
+
+func (testCaseResultRecord) TableName() string {
+	return "test_case_results"
+}
+
+func newTestCaseResultRecord(result *domain.TestCaseResult) *testCaseResultRecord {
+	return &testCaseResultRecord{
+		ID:         result.ID,
+		RunID:      result.RunID,
+		Position:   result.Position,
+		Name:       result.Name,
+		Passed:     result.Passed,
+		Output:     result.Output,
+		Error:      result.Error,
+		Assertions: result.Assertions,
+		FlowRunID:  result.FlowRunID,
+		Usage:      result.Usage,
+		Cost:       result.Cost,
+		JudgeUsage: result.JudgeUsage,
+		JudgeCost:  result.JudgeCost,
+		LatencyMS:  result.LatencyMS,
+	}
+}
+
+func (r *testCaseResultRecord) toDomain() *domain.TestCaseResult {
+	return &domain.TestCaseResult{
+		ID:         r.ID,
+		RunID:      r.RunID,
+		Position:   r.Position,
+		Name:       r.Name,
+		Passed:     r.Passed,
+		Output:     r.Output,
+		Error:      r.Error,
+		Assertions: r.Assertions,
+		FlowRunID:  r.FlowRunID,
+		Usage:      r.Usage,
+		Cost:       r.Cost,
+		JudgeUsage: r.JudgeUsage,
+		JudgeCost:  r.JudgeCost,
+		LatencyMS:  r.LatencyMS,
+	}
+}
+
+func (r *TestSuiteRunRepository) CreateSuiteRun(ctx context.Context, run *domain.TestSuiteRun) error {
+	return r.db.session(ctx).Create(newTestSuiteRunRecord(run)).Error
+}
+
+// UpdateSuiteRun leaves lease_expires_at to ExtendSuiteRunLease. A missing
+// row is reported rather than inserted.
+func (r *TestSuiteRunRepository) UpdateSuiteRun(ctx context.Context, run *domain.TestSuiteRun) error {
+	result := r.db.session(ctx).Select("*").Omit("lease_expires_at").Updates(newTestSuiteRunRecord(run))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return notFound("test suite run", run.ID)
+	}
+	return nil
+}
+
+func (r *TestSuiteRunRepository) CreateCaseResult(ctx context.Context, result *domain.TestCaseResult) error {
+	tx := r.db.session(ctx)
+	// gorm's JSON serializer cannot save a nil interface. A case that failed
+	// before producing output leaves the column NULL instead.
+	if result.Output == nil {
+		tx = tx.Omit("output")
+	}
+	return tx.Create(newTestCaseResultRecord(result)).Error
+}
+
+func (r *TestSuiteRunRepository) GetSuiteRun(
+	ctx context.Context, accountID, suiteID, id uuid.UUID,
+) (*domain.TestSuiteRun, error) {
+	var record testSuiteRunRecord
+	err := r.db.session(ctx).
+		Where("id = ? AND suite_id = ? AND account_id = ?", id, suiteID, accountID).
+		Take(&record).Error
+	if err != nil {
+		return nil, translateError(err, "test suite run", id)
+	}
+	return record.toDomain(), nil
+}
+
+// ListSuiteRuns returns the runs of a suite, newest first.
+func (r *TestSuiteRunRepository) ListSuiteRuns(
+	ctx context.Context, accountID, suiteID uuid.UUID,
+) ([]*domain.TestSuiteRun, error) {
+	var records []*testSuiteRunRecord
+	err := r.db.session(ctx).
+		Where("suite_id = ? AND account_id = ?", suiteID, accountID).
+		Order("created_at DESC").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]*domain.TestSuiteRun, 0, len(records))
+	for _, record := range records {
+		runs = append(runs, record.toDomain())
+	}
+	return runs, nil
+}
+
+func (r *TestSuiteRunRepository) ListCaseResults(
+	ctx context.Context, runID uuid.UUID,
+) ([]*domain.TestCaseResult, error) {
+	var records []*testCaseResultRecord
+	err := r.db.session(ctx).
+		Where("run_id = ?", runID).
+		Order("position").
+		Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*domain.TestCaseResult, 0, len(records))
+	for _, record := range records {
+		results = append(results, record.toDomain())
+	}
+	return results, nil
+}
+
+func (r *TestSuiteRunRepository) ExtendSuiteRunLease(ctx context.Context, id uuid.UUID, until time.Time) error {
+	result := r.db.session(ctx).
+		Model(&testSuiteRunRecord{}).
+		Where("id = ?", id).
+		Update("lease_expires_at", until)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return notFound("test suite run", id)
+	}
+	return nil
+}
+
+func (r *TestSuiteRunRepository) FailExpiredSuiteRuns(ctx context.Context, now time.Time, message string) (int, error) {
+	result := r.db.session(ctx).
+		Model(&testSuiteRunRecord{}).
+		Where("status = ? AND lease_expires_at <= ?", domain.RunStatusRunning, now).
+		Updates(map[string]any{"status": domain.RunStatusFailed, "error": message, "finished_at": now})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
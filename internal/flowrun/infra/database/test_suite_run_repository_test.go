@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"flow-run/internal/flowrun/infra/repotest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestSuiteRunRepositoryFailExpiredSuiteRuns(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := newTestDatabase(t)
+	repo := NewTestSuiteRunRepository(db)
+	flow := repotest.NewFlow(t, uuid.New(), "x")
+	require.NoError(t, NewFlowRepository(db).Create(ctx, flow))
+	suite, err := domain.NewTestSuite(
+		domain.WithTestSuiteID(uuid.New()),
+		domain.WithTestSuiteAccountID(flow.AccountID),
+		domain.WithTestSuiteName("suite"),
+		domain.WithTestSuiteFlow("x"),
+		domain.WithTestSuiteCases(domain.TestCase{
+			Name:       "a",
+			Assertions: []domain.Assertion{{Type: domain.AssertionTypeContains, Value: "x"}},
+		}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, NewTestSuiteRepository(db).Create(ctx, suite))
+
+	now := time.Now()
+	runs := map[string]*domain.TestSuiteRun{}
+	for name, lease := range map[string]time.Time{"expired": now.Add(-time.Second), "live": now.Add(time.Minute)} {
+		run := domain.NewTestSuiteRun(suite, "gpt", now)
+		run.Start(now)
+		run.LeaseExpiresAt = &lease
+		require.NoError(t, repo.CreateSuiteRun(ctx, run))
+		runs[name] = run
+	}
+	runs["pending"] = domain.NewTestSuiteRun(suite, "gpt", now)
+	require.NoError(t, repo.CreateSuiteRun(ctx, runs["pending"]))
+
+	// Updates keep the newest lease.
+	require.NoError(t, repo.ExtendSuiteRunLease(ctx, runs["live"].ID, now.Add(time.Hour)))
+	require.NoError(t, repo.UpdateSuiteRun(ctx, runs["live"]))
+
+	failed, err := repo.FailExpiredSuiteRuns(ctx, now.Add(30*time.Minute), "interrupted")
+	require.NoError(t, err)
+	assert.Equal(t, 1, failed)
+
+	for name, want := range map[string]domain.RunStatus{
+		"expired": domain.RunStatusFailed,
+		"live":    domain.RunStatusRunning,
+		"pending": domain.RunStatusPending,
+	} {
+		stored, err := repo.GetSuiteRun(ctx, suite.AccountID, suite.ID, runs[name].ID)
+		require.NoError(t, err)
+		assert.Equal(t, want, stored.Status, name)
+	}
+
+	require.ErrorIs(t, repo.ExtendSuiteRunLease(ctx, uuid.New(), now), port.ErrNotFound)
+	require.ErrorIs(t, repo.UpdateSuiteRun(ctx, &domain.TestSuiteRun{ID: uuid.New()}), port.ErrNotFound)
+}
@@ -0,0 +1,258 @@
+package database
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UsageRecordRepository is the usage ledger. It only appends and reads: a
+// record, once written, is the billing history of its call and is never
+// changed.
+type UsageRecordRepository struct {
+	db *Database
+}
+
+func NewUsageRecordRepository(db *Database) *UsageRecordRepository {
+	return &UsageRecordRepository{db: db}
+}
+
+// usageRecord is the row of a usage record. Cost reports scan the ledger by
+// time, within an account or across all of them, through the indexes on
+// created_at.
+type usageRecord struct {
+	ID               uuid.UUID
+	AccountID        uuid.UUID
+	ProviderID       uuid.UUID
+	ModelID          uuid.UUID
+	Model            string
+	FlowID           *uuid.UUID
+	RunID            *uuid.UUID
+	StepRunID        *uuid.UUID
+	StepID           string
+	PromptRevisionID *uuid.UUID
+	Purpose          domain.UsagePurpose
+	SuiteRunID       *uuid.UUID
+	CasePosition     *int
+	Usage            domain.TokenUsage `gorm:"embedded;embeddedPrefix:usage_"`
+	InputPrice       domain.Money
+	OutputPrice      domain.Money
+	CachedPrice      domain.Money
+	Cost             domain.Money
+	CreatedAt        time.Time
+}
+
+func (usageRecord) TableName() string {
+	return "usage_records"
+}
+
+func newUsageRecord(record *domain.UsageRecord) *usageRecord {
+	return &usageRecord{
+		ID:               record.ID,
+		AccountID:        record.AccountID,
+		ProviderID:       record.ProviderID,
+		ModelID:          record.ModelID,
+		Model:            record.Model,
+		FlowID:           record.FlowID,
+		RunID:            record.RunID,
+		StepRunID:        record.StepRunID,
+		StepID:           record.StepID,
+		PromptRevisionID: record.PromptRevisionID,
+		Purpose:          record.Purpose,
+		SuiteRunID:       record.SuiteRunID,
+		CasePosition:     record.CasePosition,
+		Usage:            record.Usage,
+		InputPrice:       record.InputPrice,
+		OutputPrice:      record.OutputPrice,
+		CachedPrice:      record.CachedPrice,
+		Cost:             record.Cost,
+		CreatedAt:        record.CreatedAt,
+	}
+}
+
+func (r *usageRecord) toDomain() *domain.UsageRecord {
+	return &domain.UsageRecord{
+		ID:               r.ID,
+		AccountID:        r.AccountID,
+		ProviderID:       r.ProviderID,
+		ModelID:          r.ModelID,
+		Model:            r.Model,
+		FlowID:           r.FlowID,
+		RunID:            r.RunID,
+		StepRunID:        r.StepRunID,
+		StepID:           r.StepID,
+		PromptRevisionID: r.PromptRevisionID,
+		Purpose:          r.Purpose,
+		SuiteRunID:       r.SuiteRunID,
+		CasePosition:     r.CasePosition,
+		Usage:            r.Usage,
+		InputPrice:       r.InputPrice,
+		OutputPrice:      r.OutputPrice,
+		CachedPrice:      r.CachedPrice,
+		Cost:             r.Cost,
+		CreatedAt:        r.CreatedAt,
+	}
+}
+
+func (r *UsageRecordRepository) Append(ctx context.Context, record *domain.UsageRecord) error {
+	row := newUsageRecord(record)
+	if err := r.db.session(ctx).Create(row).Error; err != nil {
+		return err
+	}
+	// gorm fills in a zero creation time.
+	record.CreatedAt = row.CreatedAt
+	return nil
+}
+
+// List returns at most limit records of the account matching filter, oldest
+// first, skipping the first offset.
+func (r *UsageRecordRepository) List(
+	ctx context.Context, accountID uuid.UUID, filter domain.UsageFilter, offset, limit int,
+) ([]*domain.UsageRecord, error) {
+	tx := r.db.session(ctx).Where("account_id = ?", accountID)
+	if filter.FlowID != nil {
+		tx = tx.Where("flow_id = ?", *filter.FlowID)
+	}
+	if filter.RunID != nil {
+		tx = tx.Where("run_id = ?", *filter.RunID)
+	}
+
+	var rows []*usageRecord
+	err := tx.Order("created_at, id").Offset(offset).Limit(limit).Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*domain.UsageRecord, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, row.toDomain())
+	}
+	return records, nil
+}
+
+// costReportRow is a row of the cost report query. Buckets are selected as
+// Unix seconds so they scan the same whatever the column type.
+type costReportRow struct {
+	Bucket           int64
+	AccountID        *uuid.UUID
+	FlowID           *uuid.UUID
+	ModelID          *uuid.UUID
+	Model            string
+	ProviderID       *uuid.UUID
+	PromptID         *uuid.UUID
+	PromptRevision   int
+	Purpose          domain.UsagePurpose
+	Calls            int
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	CacheReadTokens  int
+	CacheWriteTokens int
+	Cost             domain.Money
+}
+
+// Report aggregates the ledger in the database, per time bucket and the
+// groups of q, ordered by bucket. Ranges are scanned through the indexes on
+// created_at.
+func (r *UsageRecordRepository) Report(
+	ctx context.Context, q *domain.CostReportQuery,
+) ([]*domain.CostReportRow, error) {
+	columns := []string{costBucketColumn(r.db.dialect, q.Bucket) + " AS bucket"}
+	groups := []string{"bucket"}
+	group := func(column string) {
+		columns = append(columns, "usage_records."+column)
+		groups = append(groups, "usage_records."+column)
+	}
+	if q.Groups(domain.CostByAccount) {
+		group("account_id")
+	}
+	if q.Groups(domain.CostByFlow) {
+		group("flow_id")
+	}
+	if q.Groups(domain.CostByModel) {
+		group("model_id")
+		columns = append(columns, "MAX(usage_records.model) AS model")
+	}
+	if q.Groups(domain.CostByProvider) {
+		group("provider_id")
+	}
+	if q.Groups(domain.CostByPurpose) {
+		group("purpose")
+	}
+
+	tx := r.db.session(ctx).Table("usage_records")
+	if q.Groups(domain.CostByPromptRevision) {
+		tx = tx.Joins("LEFT JOIN prompt_revisions ON prompt_revisions.id = usage_records.prompt_revision_id")
+		columns = append(columns, "prompt_revisions.prompt_id", "prompt_revisions.number AS prompt_revision")
+		groups = append(groups, "prompt_revisions.prompt_id", "prompt_revisions.number")
+	}
+
+	columns = append(columns,
+		"COUNT(*) AS calls",
+		"CAST(SUM(usage_records.usage_prompt_tokens) AS BIGINT) AS prompt_tokens",
+		"CAST(SUM(usage_records.usage_completion_tokens) AS BIGINT) AS completion_tokens",
+		"CAST(SUM(usage_records.usage_total_tokens) AS BIGINT) AS total_tokens",
+		"CAST(SUM(usage_records.usage_cache_read_tokens) AS BIGINT) AS cache_read_tokens",
+		"CAST(SUM(usage_records.usage_cache_write_tokens) AS BIGINT) AS cache_write_tokens",
+		"CAST(SUM(usage_records.cost) AS BIGINT) AS cost",
+	)
+	tx = tx.Select(strings.Join(columns, ", ")).
+		Where("usage_records.created_at >= ? AND usage_records.created_at < ?", q.From, q.To)
+	if q.AccountID != nil {
+		tx = tx.Where("usage_records.account_id = ?", *q.AccountID)
+	}
+
+	var rows []costReportRow
+	err := tx.Group(strings.Join(groups, ", ")).Order(strings.Join(groups, ", ")).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	report := make([]*domain.CostReportRow, 0, len(rows))
+	for _, row := range rows {
+		report = append(report, &domain.CostReportRow{
+			Bucket:         time.Unix(row.Bucket, 0).UTC(),
+			AccountID:      row.AccountID,
+			FlowID:         row.FlowID,
+			ModelID:        row.ModelID,
+			Model:          row.Model,
+			ProviderID:     row.ProviderID,
+			PromptID:       row.PromptID,
+			PromptRevision: row.PromptRevision,
+			Purpose:        row.Purpose,
+			Calls:          row.Calls,
+			Usage: domain.TokenUsage{
+				PromptTokens:     row.PromptTokens,
+				CompletionTokens: row.CompletionTokens,
+				TotalTokens:      row.TotalTokens,
+				CacheReadTokens:  row.CacheReadTokens,
+				CacheWriteTokens: row.CacheWriteTokens,
+			},
+			Cost: row.Cost,
+		})
+	}
+	return report, nil
+}
+
+// sqliteBucketFormats truncate SQLite times to the start of each bucket.
+var sqliteBucketFormats = map[domain.CostBucket]string{
+	domain.CostBucketHour:  "%Y-%m-%d %H:00:00",
+	domain.CostBucketDay:   "%Y-%m-%d",
+	domain.CostBucketMonth: "%Y-%m-01",
+}
+
+// costBucketColumn truncates created_at to the start of its UTC bucket, in
+// Unix seconds. SQLite stores times as text, which strftime reads in UTC.
+func costBucketColumn(dialect dialect, bucket domain.CostBucket) string {
+	if dialect == dialectSQLite {
+		return fmt.Sprintf(
+			"CAST(strftime('%%s', strftime('%s', usage_records.created_at)) AS INTEGER)", sqliteBucketFormats[bucket],
+		)
+	}
+	return fmt.Sprintf(
+		"CAST(EXTRACT(EPOCH FROM date_trunc('%s', usage_records.created_at AT TIME ZONE 'UTC')) AS BIGINT)", bucket,
+	)
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageRecordRepositoryReport(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := NewUsageRecordRepository(newTestDatabase(t))
+	accountID := uuid.New()
+	// Records are written in whatever zone the process runs in; buckets
+	// and ranges are UTC.
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	for _, createdAt := range []time.Time{
+		time.Date(2026, 3, 1, 1, 30, 0, 0, zone), // 23:30 UTC the day before
+		time.Date(2026, 3, 1, 9, 0, 0, 0, zone),
+		time.Date(2026, 3, 3, 1, 0, 0, 0, zone),     // 23:00 UTC on the 2nd
+		time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), // past the range
+	} {
+		require.NoError(t, repo.Append(ctx, &domain.UsageRecord{
+			ID:        uuid.New(),
+			AccountID: accountID,
+			Model:     "gpt",
+			Usage:     domain.TokenUsage{PromptTokens: 1, TotalTokens: 1},
+			Cost:      10,
+			CreatedAt: createdAt,
+		}))
+	}
+
+	q, err := domain.NewCostReportQuery(&accountID,
+		time.Date(2026, 3, 1, 0, 0, 0, 0, zone), time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC),
+		domain.CostBucketDay, nil)
+	require.NoError(t, err)
+	report, err := repo.Report(ctx, q)
+	require.NoError(t, err)
+
+	require.Len(t, report, 3)
+	for i, bucket := range []time.Time{
+		time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+	} {
+		assert.Equal(t, bucket, report[i].Bucket)
+		assert.Equal(t, 1, report[i].Calls)
+		assert.Equal(t, domain.Money(10), report[i].Cost)
+	}
+}
+
+func TestUsageRecordRepositoryReportByPurpose(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	repo := NewUsageRecordRepository(newTestDatabase(t))
+	accountID, suiteRunID := uuid.New(), uuid.New()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	model := &domain.Model{AccountID: accountID, Name: "gpt", InputPrice: domain.Money(1_000_000)}
+	usage := domain.TokenUsage{PromptTokens: 1000, TotalTokens: 1000}
+	for _, opt := range []domain.UsageRecordOpt{
+		func(*domain.UsageRecord) {},
+		domain.WithUsageRecordSuiteCase(suiteRunID, 0, domain.UsagePurposeTestCase),
+		domain.WithUsageRecordSuiteCase(suiteRunID, 0, domain.UsagePurposeJudge),
+		domain.WithUsageRecordSuiteCase(suiteRunID, 1, domain.UsagePurposeJudge),
+	} {
+		require.NoError(t, repo.Append(ctx, domain.NewUsageRecord(model, usage, now, opt)))
+	}
+
+	q, err := domain.NewCostReportQuery(&accountID, now.Add(-time.Hour), now.Add(time.Hour),
+		domain.CostBucketDay, []domain.CostDimension{domain.CostByPurpose})
+	require.NoError(t, err)
+	report, err := repo.Report(ctx, q)
+	require.NoError(t, err)
+
+	require.Len(t, report, 3)
+	for i, want := range []struct {
+		purpose domain.UsagePurpose
+		calls   int
+	}{
+		{purpose: domain.UsagePurposeFlow, calls: 1},
+		{purpose: domain.UsagePurposeJudge, calls: 2},
+		{purpose: domain.UsagePurposeTestCase, calls: 1},
+	} {
+		assert.Equal(t, want.purpose, report[i].Purpose)
+		assert.Equal(t, want.calls, report[i].Calls)
+		assert.Equal(t, domain.Money(want.calls)*1_000, report[i].Cost)
+	}
+}
+
+func TestUsageRecordRepositoryReportByPromptRevision(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	db := newTestDatabase(t)
+	repo := NewUsageRecordRepository(db)
+	accountID, suiteRunID := uuid.New(), uuid.New()
+	prompt, err := domain.NewPrompt(
+		domain.WithPromptID(uuid.New()),
+		domain.WithPromptAccountID(accountID),
+		domain.WithPromptName("greet"),
+		domain.WithPromptAuthor("alice"),
+		domain.WithPromptTemplate(domain.PromptTemplate{User: "Hi"}),
+	)
+	require.NoError(t, err)
+	prompts := NewPromptRepository(db)
+	require.NoError(t, prompts.Create(ctx, prompt))
+	revision, err := prompts.GetRevision(ctx, accountID, prompt.ID, 1)
+	require.NoError(t, err)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	model := &domain.Model{AccountID: accountID, Name: "gpt", InputPrice: domain.Money(1_000_000)}
+	usage := domain.TokenUsage{PromptTokens: 1000, TotalTokens: 1000}
+	for _, opt := range []domain.UsageRecordOpt{
+		func(*domain.UsageRecord) {},
+		domain.WithUsageRecordPromptRevision(revision.ID),
+		domain.WithUsageRecordPromptRevision(revision.ID),
+	} {
+		record := domain.NewUsageRecord(model, usage, now, opt,
+			domain.WithUsageRecordSuiteCase(suiteRunID, 0, domain.UsagePurposeTestCase))
+		require.NoError(t, repo.Append(ctx, record))
+	}
+
+	q, err := domain.NewCostReportQuery(&accountID, now.Add(-time.Hour), now.Add(time.Hour),
+		domain.CostBucketDay, []domain.CostDimension{domain.CostByPromptRevision})
+	require.NoError(t, err)
+	report, err := repo.Report(ctx, q)
+	require.NoError(t, err)
+
+	require.Len(t, report, 2)
+	var linked *domain.CostReportRow
+	for _, row := range report {
+		if row.PromptID != nil {
+			linked = row
+		}
+	}
+	require.NotNil(t, linked)
+	assert.Equal(t, prompt.ID, *linked.PromptID)
+	assert.Equal(t, 1, linked.PromptRevision)
+	assert.Equal(t, 2, linked.Calls)
+}
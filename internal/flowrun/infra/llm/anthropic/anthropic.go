@@ -0,0 +1,132 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"flow-run/internal/flowrun/infra/llm/llmhttp"
+	"fmt"
+	"net/http"
+)
+
+const (
+	DefaultBaseURL = "https://api.anthropic.com"
+	APIVersion     = "2023-06-01"
+
+	// defaultMaxTokens is sent when the request leaves MaxTokens unset because
+	// the Messages API requires it.
+	defaultMaxTokens = 4096
+
+	statusOverloaded = 529
+)
+
+var ErrProviderType = errors.New("provider is not an Anthropic provider")
+
+// Client speaks the Anthropic Messages API natively: system prompts travel in
+// a separate field, turns are lists of content blocks and usage reports
+// prompt-cache reads and writes separately.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+type Opt func(*Client)
+
+func WithBaseURL(baseURL string) Opt {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) Opt {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+func NewClient(provider *domain.Provider, opts ...Opt) (*Client, error) {
+	if provider.Type != domain.ProviderTypeAnthropic {
+		return nil, fmt.Errorf("%w: %s", ErrProviderType, provider.Type)
+	}
+
+	c := &Client{
+		baseURL:    DefaultBaseURL,
+		apiKey:     provider.ApiKey,
+		httpClient: llmhttp.NewHTTPClient(),
+	}
+	if provider.BaseURL != "" {
+		c.baseURL = provider.BaseURL
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+func (c *Client) ChatCompletion(
+	ctx context.Context, req *domain.CompletionRequest,
+) (*domain.CompletionResponse, error) {
+	messagesReq, err := toMessagesRequest(req)
+	if err != nil {
+		return nil, newError(http.StatusBadRequest, err.Error(), port.ErrLLMInvalidRequest)
+	}
+
+	header := http.Header{}
+	header.Set("X-Api-Key", c.apiKey)
+	header.Set("Anthropic-Version", APIVersion)
+
+	resp, err := llmhttp.PostJSON(ctx, c.httpClient, c.baseURL+"/v1/messages", header, messagesReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.OK() {
+		return nil, toLLMError(resp)
+	}
+
+	var messagesResp messagesResponse
+	if err := json.Unmarshal(resp.Body, &messagesResp); err != nil {
+		return nil, newError(resp.StatusCode, err.Error(), port.ErrLLMInvalidResponse)
+	}
+
+	return messagesResp.toCompletionResponse(), nil
+}
+
+func toLLMError(resp *llmhttp.Response) *port.LLMError {
+	var errResp errorResponse
+	if err := json.Unmarshal(resp.Body, &errResp); err != nil || errResp.Error == nil {
+		return newError(resp.StatusCode, string(resp.Body), errorKind(resp.StatusCode, ""))
+	}
+	return newError(resp.StatusCode, errResp.Error.Message, errorKind(resp.StatusCode, errResp.Error.Type))
+}
+
+// errorKind maps the documented Anthropic error types, falling back to the
+// status for proxies that strip the body.
+func errorKind(status int, errorType string) error {
+	switch errorType {
+	case "authentication_error", "permission_error":
+		return port.ErrLLMAuthentication
+	case "rate_limit_error":
+		return port.ErrLLMRateLimited
+	case "overloaded_error", "api_error":
+		return port.ErrLLMUnavailable
+	case "invalid_request_error", "not_found_error", "request_too_large":
+		return port.ErrLLMInvalidRequest
+	}
+	if status == statusOverloaded {
+		return port.ErrLLMUnavailable
+	}
+	return port.ErrorKindForStatus(status)
+}
+
+func newError(status int, message string, kind error) *port.LLMError {
+	return &port.LLMError{
+		Provider:   domain.ProviderTypeAnthropic,
+		StatusCode: status,
+		Message:    message,
+		Kind:       kind,
+	}
+}
@@ -0,0 +1,243 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatCompletion(t *testing.T) {
+	t.Parallel()
+
+	apiKey := faker.Password()
+	var got messagesRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		assert.Equal(t, apiKey, r.Header.Get("X-Api-Key"))
+		assert.Equal(t, APIVersion, r.Header.Get("Anthropic-Version"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+		_, _ = w.Write([]byte(`{
+			"id": "msg_1",
+			"type": "message",
+			"role": "assistant",
+			"model": "claude-sonnet-4-20250514",
+			"content": [{"type": "text", "text": "Hello"}, {"type": "text", "text": " there"}],
+			"stop_reason": "end_turn",
+			"usage": {
+				"input_tokens": 20,
+				"output_tokens": 5,
+				"cache_creation_input_tokens": 1500,
+				"cache_read_input_tokens": 3000
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, apiKey)
+
+	resp, err := client.ChatCompletion(context.Background(), &domain.CompletionRequest{
+		Model: "claude-sonnet-4-20250514",
+		Messages: []domain.Message{
+			{Role: domain.MessageRoleSystem, Content: "You are terse.", Cacheable: true},
+			{Role: domain.MessageRoleUser, Content: "Hi"},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, got.System, 1)
+	assert.Equal(t, "You are terse.", got.System[0].Text)
+	require.NotNil(t, got.System[0].CacheControl)
+	assert.Equal(t, "ephemeral", got.System[0].CacheControl.Type)
+	require.Len(t, got.Messages, 1)
+	assert.Equal(t, "user", got.Messages[0].Role)
+	assert.Equal(t, defaultMaxTokens, got.MaxTokens)
+
+	assert.Equal(t, "msg_1", resp.ID)
+	assert.Equal(t, "Hello there", resp.Message.Content)
+	assert.Equal(t, domain.FinishReasonStop, resp.FinishReason)
+	assert.Equal(t, domain.TokenUsage{
+		PromptTokens:     4520,
+		CompletionTokens: 5,
+		TotalTokens:      4525,
+		CacheReadTokens:  3000,
+		CacheWriteTokens: 1500,
+	}, resp.Usage)
+}
+
+func TestChatCompletionWithToolUse(t *testing.T) {
+	t.Parallel()
+
+	var got messagesRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+		_, _ = w.Write([]byte(`{
+			"id": "msg_2",
+			"model": "claude-sonnet-4-20250514",
+			"content": [
+				{"type": "text", "text": "Checking Lviv too."},
+				{"type": "tool_use", "id": "toolu_2", "name": "get_weather", "input": {"city": "Lviv"}}
+			],
+			"stop_reason": "tool_use",
+			"usage": {"input_tokens": 50, "output_tokens": 30}
+		}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, faker.Password())
+
+	maxTokens := 256
+	resp, err := client.ChatCompletion(context.Background(), &domain.CompletionRequest{
+		Model: "claude-sonnet-4-20250514",
+		Messages: []domain.Message{
+			{Role: domain.MessageRoleUser, Content: "Weather in Kyiv and Lviv?"},
+			{Role: domain.MessageRoleAssistant, ToolCalls: []domain.ToolCall{
+				{ID: "toolu_1", Name: "get_weather", Arguments: json.RawMessage(`{"city":"Kyiv"}`)},
+			}},
+			{Role: domain.MessageRoleTool, ToolCallID: "toolu_1", Content: `{"temp":21}`},
+			{Role: domain.MessageRoleUser, Content: "And Lviv?"},
+		},
+		Tools: []domain.Tool{
+			{Name: "get_weather", InputSchema: json.RawMessage(`{"type":"object"}`)},
+		},
+		Parameters: domain.CompletionParameters{MaxTokens: &maxTokens},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 256, got.MaxTokens)
+	require.Len(t, got.Tools, 1)
+	assert.JSONEq(t, `{"type":"object"}`, string(got.Tools[0].InputSchema))
+
+	require.Len(t, got.Messages, 3)
+	assert.Equal(t, "assistant", got.Messages[1].Role)
+	require.Len(t, got.Messages[1].Content, 1)
+	assert.Equal(t, "tool_use", got.Messages[1].Content[0].Type)
+	assert.Equal(t, "toolu_1", got.Messages[1].Content[0].ID)
+
+	// The tool result and the follow-up question are merged into one user turn.
+	assert.Equal(t, "user", got.Messages[2].Role)
+	require.Len(t, got.Messages[2].Content, 2)
+	assert.Equal(t, "tool_result", got.Messages[2].Content[0].Type)
+	assert.Equal(t, "toolu_1", got.Messages[2].Content[0].ToolUseID)
+	assert.Equal(t, "text", got.Messages[2].Content[1].Type)
+
+	assert.Equal(t, domain.FinishReasonToolCalls, resp.FinishReason)
+	assert.Equal(t, "Checking Lviv too.", resp.Message.Content)
+	require.Len(t, resp.Message.ToolCalls, 1)
+	assert.Equal(t, "toolu_2", resp.Message.ToolCalls[0].ID)
+	assert.JSONEq(t, `{"city":"Lviv"}`, string(resp.Message.ToolCalls[0].Arguments))
+}
+
+func TestChatCompletionIfToolResultWithoutToolUse(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, "http://127.0.0.1:0", faker.Password())
+
+	_, err := client.ChatCompletion(context.Background(), &domain.CompletionRequest{
+		Model: "claude-sonnet-4-20250514",
+		Messages: []domain.Message{
+			{Role: domain.MessageRoleTool, ToolCallID: "toolu_1", Content: "orphan"},
+		},
+	})
+
+	require.ErrorIs(t, err, port.ErrLLMInvalidRequest)
+}
+
+func TestChatCompletionIfProviderFails(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		kind   error
+	}{
+		{
+			name:   "invalid_api_key",
+			status: http.StatusUnauthorized,
+			body:   `{"type": "error", "error": {"type": "authentication_error", "message": "invalid x-api-key"}}`,
+			kind:   port.ErrLLMAuthentication,
+		},
+		{
+			name:   "rate_limited",
+			status: http.StatusTooManyRequests,
+			body:   `{"type": "error", "error": {"type": "rate_limit_error", "message": "slow down"}}`,
+			kind:   port.ErrLLMRateLimited,
+		},
+		{
+			name:   "overloaded",
+			status: statusOverloaded,
+			body:   `{"type": "error", "error": {"type": "overloaded_error", "message": "Overloaded"}}`,
+			kind:   port.ErrLLMUnavailable,
+		},
+		{
+			name:   "overloaded_without_body",
+			status: statusOverloaded,
+			body:   ``,
+			kind:   port.ErrLLMUnavailable,
+		},
+		{
+			name:   "invalid_request",
+			status: http.StatusBadRequest,
+			body:   `{"type": "error", "error": {"type": "invalid_request_error", "message": "max_tokens: too large"}}`,
+			kind:   port.ErrLLMInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := newTestClient(t, server.URL, faker.Password())
+
+			_, err := client.ChatCompletion(context.Background(), &domain.CompletionRequest{
+				Model:    "claude-sonnet-4-20250514",
+				Messages: []domain.Message{{Role: domain.MessageRoleUser, Content: "Hi"}},
+			})
+
+			require.ErrorIs(t, err, tt.kind)
+
+			var llmErr *port.LLMError
+			require.ErrorAs(t, err, &llmErr)
+			assert.Equal(t, domain.ProviderTypeAnthropic, llmErr.Provider)
+			assert.Equal(t, tt.status, llmErr.StatusCode)
+		})
+	}
+}
+
+func newTestClient(t *testing.T, baseURL, apiKey string) *Client {
+	t.Helper()
+
+	provider, err := domain.NewProvider(
+		domain.WithProviderID(uuid.New()),
+		domain.WithProviderName("anthropic"),
+		domain.WithProviderAccountID(uuid.New()),
+		domain.WithProviderType(domain.ProviderTypeAnthropic),
+		domain.WithProviderApiKey(apiKey),
+		domain.WithProviderBaseURL(baseURL),
+	)
+	require.NoError(t, err)
+
+	client, err := NewClient(provider)
+	require.NoError(t, err)
+
+	return client
+}
@@ -0,0 +1,215 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"errors"
+	"flow-run/internal/core/domain"
+	"strings"
+)
+
+var errToolResultOrder = errors.New("tool result must follow an assistant tool call")
+
+type cacheControl struct {
+	Type string `json:"type"`
+}
+
+var ephemeral = &cacheControl{Type: "ephemeral"}
+
+// contentBlock covers the text, tool_use and tool_result block types. Unused
+// fields are omitted so each block serializes to its documented shape.
+type contentBlock struct {
+	Type         string          `json:"type"`
+	Text         string          `json:"text,omitempty"`
+	ID           string          `json:"id,omitempty"`
+	Name         string          `json:"name,omitempty"`
+	Input        json.RawMessage `json:"input,omitempty"`
+	ToolUseID    string          `json:"tool_use_id,omitempty"`
+	Content      string          `json:"content,omitempty"`
+	CacheControl *cacheControl   `json:"cache_control,omitempty"`
+}
+
+type message struct {
+	Role    string         `json:"role"`
+	Content []contentBlock `json:"content"`
+}
+
+type tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+type messagesRequest struct {
+	Model         string         `json:"model"`
+	System        []contentBlock `json:"system,omitempty"`
+	Messages      []message      `json:"messages"`
+	Tools         []tool         `json:"tools,omitempty"`
+	MaxTokens     int            `json:"max_tokens"`
+	Temperature   *float64       `json:"temperature,omitempty"`
+	TopP          *float64       `json:"top_p,omitempty"`
+	StopSequences []string       `json:"stop_sequences,omitempty"`
+}
+
+type messagesResponse struct {
+	ID         string         `json:"id"`
+	Model      string         `json:"model"`
+	Content    []contentBlock `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      usage          `json:"usage"`
+}
+
+// usage reports input_tokens without the cached prefix, so the cache fields
+// have to be added back to get the full prompt size.
+type usage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+type errorResponse struct {
+	Error *apiError `json:"error"`
+}
+
+type apiError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// toMessagesRequest hoists system messages into the system field and folds
+// tool results into user turns, merging consecutive turns of the same role as
+// the API requires strict user/assistant alternation.
+func toMessagesRequest(req *domain.CompletionRequest) (*messagesRequest, error) {
+	messagesReq := &messagesRequest{
+		Model:         req.Model,
+		MaxTokens:     defaultMaxTokens,
+		Temperature:   req.Parameters.Temperature,
+		TopP:          req.Parameters.TopP,
+		StopSequences: req.Parameters.Stop,
+	}
+	if req.Parameters.MaxTokens != nil {
+		messagesReq.MaxTokens = *req.Parameters.MaxTokens
+	}
+
+	for _, t := range req.Tools {
+		messagesReq.Tools = append(messagesReq.Tools, tool{
+			Name:        t.Name,
+			Description: t.Description,
+			InputSchema: t.InputSchema,
+		})
+	}
+
+	for _, m := range req.Messages {
+		if m.Role == domain.MessageRoleSystem {
+			messagesReq.System = append(messagesReq.System, withCache(
+				[]contentBlock{{Type: "text", Text: m.Content}}, m.Cacheable)...)
+			continue
+		}
+
+		role, blocks := toContentBlocks(m)
+		if m.Role == domain.MessageRoleTool && !afterToolUse(messagesReq.Messages) {
+			return nil, errToolResultOrder
+		}
+		blocks = withCache(blocks, m.Cacheable)
+
+		if n := len(messagesReq.Messages); n > 0 && messagesReq.Messages[n-1].Role == role {
+			messagesReq.Messages[n-1].Content = append(messagesReq.Messages[n-1].Content, blocks...)
+			continue
+		}
+		messagesReq.Messages = append(messagesReq.Messages, message{Role: role, Content: blocks})
+	}
+
+	return messagesReq, nil
+}
+
+func toContentBlocks(m domain.Message) (string, []contentBlock) {
+	switch m.Role {
+	case domain.MessageRoleTool:
+		return "user", []contentBlock{{Type: "tool_result", ToolUseID: m.ToolCallID, Content: m.Content}}
+	case domain.MessageRoleAssistant:
+		var blocks []contentBlock
+		if m.Content != "" {
+			blocks = append(blocks, contentBlock{Type: "text", Text: m.Content})
+		}
+		for _, call := range m.ToolCalls {
+			input := call.Arguments
+			if len(input) == 0 {
+				input = json.RawMessage("{}")
+			}
+			blocks = append(blocks, contentBlock{Type: "tool_use", ID: call.ID, Name: call.Name, Input: input})
+		}
+		return "assistant", blocks
+	default:
+		return "user", []contentBlock{{Type: "text", Text: m.Content}}
+	}
+}
+
+// afterToolUse reports whether the conversation so far ends with an assistant
+// tool_use, or with tool results answering one.
+func afterToolUse(messages []message) bool {
+	if len(messages) == 0 {
+		return false
+	}
+
+	last := messages[len(messages)-1]
+	for _, b := range last.Content {
+		if b.Type == "tool_use" || b.Type == "tool_result" {
+			return true
+		}
+	}
+	return false
+}
+
+// withCache marks the last block as a cache breakpoint.
+func withCache(blocks []contentBlock, cacheable bool) []contentBlock {
+	if cacheable && len(blocks) > 0 {
+		blocks[len(blocks)-1].CacheControl = ephemeral
+	}
+	return blocks
+}
+
+func (r *messagesResponse) toCompletionResponse() *domain.CompletionResponse {
+	var text strings.Builder
+	msg := domain.Message{Role: domain.MessageRoleAssistant}
+
+	for _, b := range r.Content {
+		switch b.Type {
+		case "text":
+			text.WriteString(b.Text)
+		case "tool_use":
+			msg.ToolCalls = append(msg.ToolCalls, domain.ToolCall{ID: b.ID, Name: b.Name, Arguments: b.Input})
+		}
+	}
+	msg.Content = text.String()
+
+	prompt := r.Usage.InputTokens + r.Usage.CacheCreationInputTokens + r.Usage.CacheReadInputTokens
+
+	return &domain.CompletionResponse{
+		ID:           r.ID,
+		Model:        r.Model,
+		Message:      msg,
+		FinishReason: toFinishReason(r.StopReason),
+		Usage: domain.TokenUsage{
+			PromptTokens:     prompt,
+			CompletionTokens: r.Usage.OutputTokens,
+			TotalTokens:      prompt + r.Usage.OutputTokens,
+			CacheReadTokens:  r.Usage.CacheReadInputTokens,
+			CacheWriteTokens: r.Usage.CacheCreationInputTokens,
+		},
+	}
+}
+
+func toFinishReason(reason string) domain.FinishReason {
+	switch reason {
+	case "end_turn", "stop_sequence", "pause_turn":
+		return domain.FinishReasonStop
+	case "max_tokens":
+		return domain.FinishReasonLength
+	case "tool_use":
+		return domain.FinishReasonToolCalls
+	case "refusal":
+		return domain.FinishReasonContentFilter
+	default:
+		return domain.FinishReasonUnknown
+	}
+}
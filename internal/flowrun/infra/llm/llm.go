@@ -0,0 +1,105 @@
+package llm
+
+import (
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"flow-run/internal/flowrun/infra/llm/anthropic"
+	"flow-run/internal/flowrun/infra/llm/mock"
+	"flow-run/internal/flowrun/infra/llm/ollama"
+	"flow-run/internal/flowrun/infra/llm/openai"
+	"flow-run/internal/flowrun/infra/llm/openrouter"
+	"flow-run/internal/lib/envelope"
+	"fmt"
+)
+
+var ErrUnsupportedProvider = errors.New("unsupported provider type")
+
+// Factory builds adapters for stored providers. It is the only place where a
+// sealed API key is opened; the plaintext lives no longer than the adapter.
+type Factory struct {
+	keyring *envelope.Keyring
+}
+
+func NewFactory(keyring *envelope.Keyring) *Factory {
+	return &Factory{keyring: keyring}
+}
+
+func (f *Factory) NewProvider(provider *domain.Provider) (port.LLMProvider, error) {
+	decrypted, err := f.open(provider)
+	if err != nil {
+		return nil, err
+	}
+	return NewProvider(decrypted)
+}
+
+// NewCatalog builds the models catalog of a stored provider. Only OpenRouter
+// publishes one.
+func (f *Factory) NewCatalog(provider *domain.Provider) (port.ModelCatalog, error) {
+	if provider.Type != domain.ProviderTypeOpenRouter {
+		return nil, fmt.Errorf("%w: %s has no models catalog", ErrUnsupportedProvider, provider.Type)
+	}
+	decrypted, err := f.open(provider)
+	if err != nil {
+		return nil, err
+	}
+	client, err := openrouter.NewClient(decrypted)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func (f *Factory) open(provider *domain.Provider) (*domain.Provider, error) {
+	if provider.EncryptedApiKey.IsZero() {
+		return provider, nil
+	}
+
+	apiKey, err := f.keyring.Open(provider.EncryptedApiKey, provider.ID[:])
+	if err != nil {
+		return nil, fmt.Errorf("open api key of provider %s: %w", provider.ID, err)
+	}
+
+	decrypted := *provider
+	decrypted.ApiKey = string(apiKey)
+	decrypted.EncryptedApiKey = nil
+	return &decrypted, nil
+}
+
+// NewProvider builds the adapter for a provider whose ApiKey is plaintext.
+func NewProvider(provider *domain.Provider) (port.LLMProvider, error) {
+	switch provider.Type {
+	case domain.ProviderTypeOpenRouter:
+		client, err := openrouter.NewClient(provider)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case domain.ProviderTypeOpenAI, domain.ProviderTypeOpenAICompatible:
+		client, err := openai.NewClient(provider)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case domain.ProviderTypeAnthropic:
+		client, err := anthropic.NewClient(provider)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case domain.ProviderTypeOllama:
+		client, err := ollama.NewClient(provider)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case domain.ProviderTypeMock:
+		client, err := mock.NewClient(provider)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedProvider, provider.Type)
+	}
+}
@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"encoding/base64"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/llm/anthropic"
+	"flow-run/internal/flowrun/infra/llm/mock"
+	"flow-run/internal/flowrun/infra/llm/ollama"
+	"flow-run/internal/flowrun/infra/llm/openai"
+	"flow-run/internal/flowrun/infra/llm/openrouter"
+	"flow-run/internal/lib/envelope"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProvider(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		providerType domain.ProviderType
+		apiKey       string
+		want         any
+	}{
+		{providerType: domain.ProviderTypeOpenRouter, want: &openrouter.Client{}},
+		{providerType: domain.ProviderTypeOpenAI, want: &openai.Client{}},
+		{providerType: domain.ProviderTypeOpenAICompatible, want: &openai.Client{}},
+		{providerType: domain.ProviderTypeOllama, want: &ollama.Client{}},
+		{providerType: domain.ProviderTypeAnthropic, want: &anthropic.Client{}},
+		{providerType: domain.ProviderTypeMock, apiKey: `{"rules": [{"regex": ".*"}]}`, want: &mock.Client{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.providerType), func(t *testing.T) {
+			t.Parallel()
+
+			provider, err := NewProvider(&domain.Provider{Type: tt.providerType, ApiKey: tt.apiKey})
+
+			require.NoError(t, err)
+			assert.IsType(t, tt.want, provider)
+		})
+	}
+}
+
+func TestNewProviderIfUnsupportedType(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewProvider(&domain.Provider{Type: domain.ProviderType("unknown")})
+
+	require.ErrorIs(t, err, ErrUnsupportedProvider)
+}
+
+func TestFactoryNewProviderOpensSealedKey(t *testing.T) {
+	t.Parallel()
+
+	keyring, err := envelope.NewKeyring(&envelope.KeyringConfig{
+		MasterKeys: "1:" + base64.StdEncoding.EncodeToString(make([]byte, 32)),
+	})
+	require.NoError(t, err)
+
+	provider := &domain.Provider{ID: uuid.New(), Type: domain.ProviderTypeMock}
+	provider.EncryptedApiKey, err = keyring.Seal([]byte(`{"rules": [{"regex": ".", "response": "ok"}]}`), provider.ID[:])
+	require.NoError(t, err)
+
+	llmProvider, err := NewFactory(keyring).NewProvider(provider)
+	require.NoError(t, err)
+	assert.IsType(t, &mock.Client{}, llmProvider)
+	assert.NotNil(t, provider.EncryptedApiKey)
+	assert.Empty(t, provider.ApiKey)
+
+	// A sealed key copied onto another provider does not open.
+	other := &domain.Provider{ID: uuid.New(), Type: domain.ProviderTypeMock, EncryptedApiKey: provider.EncryptedApiKey}
+	_, err = NewFactory(keyring).NewProvider(other)
+	require.ErrorIs(t, err, envelope.ErrDecrypt)
+}
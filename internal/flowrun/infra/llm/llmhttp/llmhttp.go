@@ -0,0 +1,79 @@
+package llmhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+)
+
+const DefaultTimeout = 120 * time.Second
+
+type Response struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (r *Response) OK() bool {
+	return r.StatusCode >= http.StatusOK && r.StatusCode < http.StatusMultipleChoices
+}
+
+// NewHTTPClient returns the client adapters use unless one is injected.
+func NewHTTPClient() *http.Client {
+	return &http.Client{Timeout: DefaultTimeout}
+}
+
+// PostJSON sends payload as JSON and reads the whole response body. Non-2xx
+// statuses are not errors here; each adapter maps them to port.LLMError itself.
+func PostJSON(
+	ctx context.Context, client *http.Client, url string, header http.Header, payload any,
+) (*Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return do(client, req)
+}
+
+// Get reads the whole response body of a GET request. Like PostJSON, it
+// leaves non-2xx statuses to the caller.
+func Get(ctx context.Context, client *http.Client, url string, header http.Header) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+	return do(client, req)
+}
+
+func do(client *http.Client, req *http.Request) (*Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{StatusCode: resp.StatusCode, Body: respBody}, nil
+}
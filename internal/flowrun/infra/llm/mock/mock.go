@@ -0,0 +1,177 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// maxPromptExcerpt bounds how much of an unmatched prompt is echoed back in
+// the error so a long conversation does not flood the logs.
+const maxPromptExcerpt = 200
+
+var (
+	ErrProviderType = errors.New("provider is not a mock provider")
+
+	// ErrUnmatchedPrompt is the kind of the error returned when no rule
+	// answers a request. It wraps port.ErrLLMInvalidRequest so callers that
+	// only know the port sentinels still treat it as non-retryable.
+	ErrUnmatchedPrompt = fmt.Errorf("%w: no mock rule matches prompt", port.ErrLLMInvalidRequest)
+)
+
+// Client answers completions from the provider's MockScript without any
+// network access. Responses, token counts and failures are fully determined
+// by the script, which makes flows reproducible in tests and CI.
+type Client struct {
+	script  *domain.MockScript
+	regexes []*regexp.Regexp
+}
+
+func NewClient(provider *domain.Provider) (*Client, error) {
+	if provider.Type != domain.ProviderTypeMock {
+		return nil, fmt.Errorf("%w: %s", ErrProviderType, provider.Type)
+	}
+
+	script, err := domain.ParseMockScript(provider.ApiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	regexes := make([]*regexp.Regexp, len(script.Rules))
+	for i, rule := range script.Rules {
+		if rule.Regex != "" {
+			// ParseMockScript has already compiled every expression once.
+			regexes[i] = regexp.MustCompile(rule.Regex)
+		}
+	}
+
+	return &Client{script: script, regexes: regexes}, nil
+}
+
+func (c *Client) ChatCompletion(
+	ctx context.Context, req *domain.CompletionRequest,
+) (*domain.CompletionResponse, error) {
+	prompt := domain.MockPrompt(req.Messages)
+	hash := domain.MockPromptHash(req.Messages)
+
+	index := c.match(req.Model, prompt, hash)
+	if index < 0 {
+		return nil, newError(http.StatusBadRequest,
+			fmt.Sprintf("model %q, prompt_sha256 %s: %q", req.Model, hash, excerpt(prompt)), ErrUnmatchedPrompt)
+	}
+	rule := c.script.Rules[index]
+
+	if err := wait(ctx, time.Duration(rule.Latency)); err != nil {
+		return nil, err
+	}
+
+	if rule.Error != nil {
+		return nil, toLLMError(rule.Error)
+	}
+
+	usage := c.script.Usage
+	if rule.Usage != nil {
+		usage = *rule.Usage
+	}
+
+	finishReason := rule.FinishReason
+	if finishReason == "" {
+		finishReason = domain.FinishReasonStop
+		if len(rule.ToolCalls) > 0 {
+			finishReason = domain.FinishReasonToolCalls
+		}
+	}
+
+	return &domain.CompletionResponse{
+		ID:    fmt.Sprintf("mock-%d-%s", index, hash[:12]),
+		Model: req.Model,
+		Message: domain.Message{
+			Role:      domain.MessageRoleAssistant,
+			Content:   rule.Response,
+			ToolCalls: rule.ToolCalls,
+		},
+		FinishReason: finishReason,
+		Usage: domain.TokenUsage{
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
+			TotalTokens:      usage.PromptTokens + usage.CompletionTokens,
+			CacheReadTokens:  usage.CacheReadTokens,
+		},
+	}, nil
+}
+
+// match returns the index of the first rule answering the request, or -1.
+func (c *Client) match(model, prompt, hash string) int {
+	for i, rule := range c.script.Rules {
+		if rule.Model != "" && rule.Model != model {
+			continue
+		}
+		if rule.PromptSHA256 != "" && rule.PromptSHA256 == hash {
+			return i
+		}
+		if c.regexes[i] != nil && c.regexes[i].MatchString(prompt) {
+			return i
+		}
+	}
+	return -1
+}
+
+// wait sleeps for the injected latency, returning early when ctx ends so
+// cancellation behaves like it does for the HTTP adapters.
+func wait(ctx context.Context, latency time.Duration) error {
+	if latency <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func toLLMError(scripted *domain.MockError) *port.LLMError {
+	message := scripted.Message
+	if message == "" {
+		message = "scripted " + string(scripted.Kind) + " error"
+	}
+
+	switch scripted.Kind {
+	case domain.MockErrorKindAuthentication:
+		return newError(http.StatusUnauthorized, message, port.ErrLLMAuthentication)
+	case domain.MockErrorKindRateLimited:
+		return newError(http.StatusTooManyRequests, message, port.ErrLLMRateLimited)
+	case domain.MockErrorKindQuotaExceeded:
+		return newError(http.StatusPaymentRequired, message, port.ErrLLMQuotaExceeded)
+	case domain.MockErrorKindUnavailable:
+		return newError(http.StatusServiceUnavailable, message, port.ErrLLMUnavailable)
+	default:
+		return newError(http.StatusBadRequest, message, port.ErrLLMInvalidRequest)
+	}
+}
+
+func excerpt(prompt string) string {
+	runes := []rune(prompt)
+	if len(runes) <= maxPromptExcerpt {
+		return prompt
+	}
+	return string(runes[:maxPromptExcerpt]) + "..."
+}
+
+func newError(status int, message string, kind error) *port.LLMError {
+	return &port.LLMError{
+		Provider:   domain.ProviderTypeMock,
+		StatusCode: status,
+		Message:    message,
+		Kind:       kind,
+	}
+}
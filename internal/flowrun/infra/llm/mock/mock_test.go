@@ -0,0 +1,209 @@
+package mock
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var greeting = []domain.Message{
+	{Role: domain.MessageRoleSystem, Content: "Be brief"},
+	{Role: domain.MessageRoleUser, Content: "Say hello"},
+}
+
+func TestChatCompletionIfRegexMatches(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, `{
+		"usage": {"prompt_tokens": 10, "completion_tokens": 2},
+		"rules": [
+			{"regex": "capital of France", "response": "Paris"},
+			{"regex": "(?i)hello", "response": "Hello!"}
+		]
+	}`)
+
+	resp, err := client.ChatCompletion(context.Background(), &domain.CompletionRequest{
+		Model:    "gpt-4o-mini",
+		Messages: greeting,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "gpt-4o-mini", resp.Model)
+	assert.Equal(t, domain.MessageRoleAssistant, resp.Message.Role)
+	assert.Equal(t, "Hello!", resp.Message.Content)
+	assert.Equal(t, domain.FinishReasonStop, resp.FinishReason)
+	assert.Equal(t, domain.TokenUsage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12}, resp.Usage)
+}
+
+func TestChatCompletionIfPromptHashMatches(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, `{
+		"rules": [
+			{
+				"prompt_sha256": "`+domain.MockPromptHash(greeting)+`",
+				"response": "Hi",
+				"usage": {"prompt_tokens": 7, "completion_tokens": 1}
+			}
+		]
+	}`)
+
+	resp, err := client.ChatCompletion(context.Background(), &domain.CompletionRequest{
+		Model:    "gpt-4o-mini",
+		Messages: greeting,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hi", resp.Message.Content)
+	assert.Equal(t, domain.TokenUsage{PromptTokens: 7, CompletionTokens: 1, TotalTokens: 8}, resp.Usage)
+}
+
+func TestChatCompletionIfRuleScopedToModel(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, `{
+		"rules": [
+			{"model": "large", "regex": ".", "response": "large answer"},
+			{"regex": ".", "response": "default answer"}
+		]
+	}`)
+
+	resp, err := client.ChatCompletion(context.Background(), &domain.CompletionRequest{
+		Model:    "small",
+		Messages: greeting,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "default answer", resp.Message.Content)
+}
+
+func TestChatCompletionIfToolCallsScripted(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, `{
+		"rules": [
+			{
+				"regex": "weather",
+				"tool_calls": [{"id": "call_1", "name": "get_weather", "arguments": {"city": "Kyiv"}}]
+			}
+		]
+	}`)
+
+	resp, err := client.ChatCompletion(context.Background(), &domain.CompletionRequest{
+		Model:    "m",
+		Messages: []domain.Message{{Role: domain.MessageRoleUser, Content: "What is the weather?"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.FinishReasonToolCalls, resp.FinishReason)
+	require.Len(t, resp.Message.ToolCalls, 1)
+	assert.Equal(t, "get_weather", resp.Message.ToolCalls[0].Name)
+	assert.JSONEq(t, `{"city": "Kyiv"}`, string(resp.Message.ToolCalls[0].Arguments))
+}
+
+func TestChatCompletionIfPromptUnmatched(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, `{"rules": [{"regex": "capital of France", "response": "Paris"}]}`)
+
+	_, err := client.ChatCompletion(context.Background(), &domain.CompletionRequest{
+		Model:    "m",
+		Messages: greeting,
+	})
+
+	require.ErrorIs(t, err, ErrUnmatchedPrompt)
+	require.ErrorIs(t, err, port.ErrLLMInvalidRequest)
+	assert.Contains(t, err.Error(), domain.MockPromptHash(greeting))
+
+	var llmErr *port.LLMError
+	require.ErrorAs(t, err, &llmErr)
+	assert.False(t, llmErr.Retryable())
+}
+
+func TestChatCompletionIfErrorScripted(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		kind string
+		want error
+	}{
+		{kind: "authentication", want: port.ErrLLMAuthentication},
+		{kind: "rate_limited", want: port.ErrLLMRateLimited},
+		{kind: "quota_exceeded", want: port.ErrLLMQuotaExceeded},
+		{kind: "invalid_request", want: port.ErrLLMInvalidRequest},
+		{kind: "unavailable", want: port.ErrLLMUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			t.Parallel()
+
+			client := newTestClient(t, `{"rules": [{"regex": ".", "error": {"kind": "`+tt.kind+`"}}]}`)
+
+			_, err := client.ChatCompletion(context.Background(), &domain.CompletionRequest{
+				Model:    "m",
+				Messages: greeting,
+			})
+
+			require.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestChatCompletionIfLatencyScripted(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, `{"rules": [{"regex": ".", "response": "slow", "latency": "50ms"}]}`)
+
+	start := time.Now()
+	resp, err := client.ChatCompletion(context.Background(), &domain.CompletionRequest{
+		Model:    "m",
+		Messages: greeting,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "slow", resp.Message.Content)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestChatCompletionIfContextCanceledDuringLatency(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, `{"rules": [{"regex": ".", "response": "late", "latency": "1m"}]}`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := client.ChatCompletion(ctx, &domain.CompletionRequest{Model: "m", Messages: greeting})
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestNewClientIfInvalidScript(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewClient(&domain.Provider{Type: domain.ProviderTypeMock, ApiKey: "not a script"})
+
+	require.ErrorIs(t, err, domain.ErrInvalidMockScript)
+}
+
+func TestNewClientIfWrongProviderType(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewClient(&domain.Provider{Type: domain.ProviderTypeOpenAI})
+
+	require.ErrorIs(t, err, ErrProviderType)
+}
+
+func newTestClient(t *testing.T, script string) *Client {
+	t.Helper()
+
+	client, err := NewClient(&domain.Provider{Type: domain.ProviderTypeMock, ApiKey: script})
+	require.NoError(t, err)
+	return client
+}
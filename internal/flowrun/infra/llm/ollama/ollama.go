@@ -0,0 +1,129 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"flow-run/internal/flowrun/infra/llm/llmhttp"
+	"fmt"
+	"net/http"
+)
+
+const DefaultBaseURL = "http://localhost:11434"
+
+var ErrProviderType = errors.New("provider is not an Ollama provider")
+
+// Client speaks the native Ollama /api/chat endpoint, which reports token
+// counts as prompt_eval_count and eval_count instead of an OpenAI usage block.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+type Opt func(*Client)
+
+func WithBaseURL(baseURL string) Opt {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) Opt {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+func NewClient(provider *domain.Provider, opts ...Opt) (*Client, error) {
+	if provider.Type != domain.ProviderTypeOllama {
+		return nil, fmt.Errorf("%w: %s", ErrProviderType, provider.Type)
+	}
+
+	c := &Client{
+		baseURL:    DefaultBaseURL,
+		apiKey:     provider.ApiKey,
+		httpClient: llmhttp.NewHTTPClient(),
+	}
+	if provider.BaseURL != "" {
+		c.baseURL = provider.BaseURL
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+func (c *Client) ChatCompletion(
+	ctx context.Context, req *domain.CompletionRequest,
+) (*domain.CompletionResponse, error) {
+	if len(req.Tools) > 0 {
+		return nil, newError(http.StatusBadRequest, "tool calling is not supported by the ollama adapter",
+			port.ErrLLMInvalidRequest)
+	}
+
+	// Ollama has no authentication of its own, but instances behind a reverse
+	// proxy commonly expect a bearer token.
+	header := http.Header{}
+	if c.apiKey != "" {
+		header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := llmhttp.PostJSON(ctx, c.httpClient, c.baseURL+"/api/chat", header, toChatRequest(req))
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.OK() {
+		return nil, toLLMError(resp)
+	}
+
+	var chatResp chatResponse
+	if err := json.Unmarshal(resp.Body, &chatResp); err != nil {
+		return nil, newError(resp.StatusCode, err.Error(), port.ErrLLMInvalidResponse)
+	}
+	if chatResp.Error != "" {
+		return nil, newError(http.StatusBadGateway, chatResp.Error, port.ErrLLMUnavailable)
+	}
+
+	return &domain.CompletionResponse{
+		Model: chatResp.Model,
+		Message: domain.Message{
+			Role:    domain.MessageRoleAssistant,
+			Content: chatResp.Message.Content,
+		},
+		FinishReason: toFinishReason(chatResp.DoneReason),
+		Usage: domain.TokenUsage{
+			PromptTokens:     chatResp.PromptEvalCount,
+			CompletionTokens: chatResp.EvalCount,
+			TotalTokens:      chatResp.PromptEvalCount + chatResp.EvalCount,
+		},
+	}, nil
+}
+
+// toLLMError maps Ollama failures. A 404 means the model has not been pulled,
+// which is a request problem rather than an outage.
+func toLLMError(resp *llmhttp.Response) *port.LLMError {
+	message := string(resp.Body)
+	var errResp chatResponse
+	if err := json.Unmarshal(resp.Body, &errResp); err == nil && errResp.Error != "" {
+		message = errResp.Error
+	}
+
+	kind := port.ErrorKindForStatus(resp.StatusCode)
+	if resp.StatusCode == http.StatusNotFound {
+		kind = port.ErrLLMInvalidRequest
+	}
+	return newError(resp.StatusCode, message, kind)
+}
+
+func newError(status int, message string, kind error) *port.LLMError {
+	return &port.LLMError{
+		Provider:   domain.ProviderTypeOllama,
+		StatusCode: status,
+		Message:    message,
+		Kind:       kind,
+	}
+}
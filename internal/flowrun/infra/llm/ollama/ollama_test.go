@@ -0,0 +1,139 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatCompletion(t *testing.T) {
+	t.Parallel()
+
+	var got chatRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/chat", r.URL.Path)
+		assert.Empty(t, r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+		_, _ = w.Write([]byte(`{
+			"model": "llama3.2",
+			"message": {"role": "assistant", "content": "Hello"},
+			"done": true,
+			"done_reason": "stop",
+			"prompt_eval_count": 26,
+			"eval_count": 4
+		}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL)
+
+	maxTokens := 32
+	resp, err := client.ChatCompletion(context.Background(), &domain.CompletionRequest{
+		Model:      "llama3.2",
+		Messages:   []domain.Message{{Role: domain.MessageRoleUser, Content: "Hi"}},
+		Parameters: domain.CompletionParameters{MaxTokens: &maxTokens},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "llama3.2", got.Model)
+	assert.False(t, got.Stream)
+	require.NotNil(t, got.Options)
+	require.NotNil(t, got.Options.NumPredict)
+	assert.Equal(t, 32, *got.Options.NumPredict)
+
+	assert.Equal(t, "Hello", resp.Message.Content)
+	assert.Equal(t, domain.FinishReasonStop, resp.FinishReason)
+	assert.Equal(t, domain.TokenUsage{PromptTokens: 26, CompletionTokens: 4, TotalTokens: 30}, resp.Usage)
+}
+
+func TestChatCompletionIfProviderFails(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		kind   error
+	}{
+		{
+			name:   "model_not_pulled",
+			status: http.StatusNotFound,
+			body:   `{"error": "model \"llama3.2\" not found, try pulling it first"}`,
+			kind:   port.ErrLLMInvalidRequest,
+		},
+		{
+			name:   "server_error",
+			status: http.StatusInternalServerError,
+			body:   `{"error": "llama runner process has terminated"}`,
+			kind:   port.ErrLLMUnavailable,
+		},
+		{
+			name:   "error_with_ok_status",
+			status: http.StatusOK,
+			body:   `{"error": "unexpected EOF"}`,
+			kind:   port.ErrLLMUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := newTestClient(t, server.URL)
+
+			_, err := client.ChatCompletion(context.Background(), &domain.CompletionRequest{
+				Model:    "llama3.2",
+				Messages: []domain.Message{{Role: domain.MessageRoleUser, Content: "Hi"}},
+			})
+
+			require.ErrorIs(t, err, tt.kind)
+
+			var llmErr *port.LLMError
+			require.ErrorAs(t, err, &llmErr)
+			assert.Equal(t, domain.ProviderTypeOllama, llmErr.Provider)
+		})
+	}
+}
+
+func TestNewClientIfDefaultBaseURL(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewClient(&domain.Provider{Type: domain.ProviderTypeOllama})
+
+	require.NoError(t, err)
+	assert.Equal(t, DefaultBaseURL, client.baseURL)
+}
+
+func newTestClient(t *testing.T, baseURL string) *Client {
+	t.Helper()
+
+	provider, err := domain.NewProvider(
+		domain.WithProviderID(uuid.New()),
+		domain.WithProviderName("ollama"),
+		domain.WithProviderAccountID(uuid.New()),
+		domain.WithProviderType(domain.ProviderTypeOllama),
+		domain.WithProviderBaseURL(baseURL),
+	)
+	require.NoError(t, err)
+
+	client, err := NewClient(provider)
+	require.NoError(t, err)
+
+	return client
+}
@@ -0,0 +1,69 @@
+package ollama
+
+import "flow-run/internal/core/domain"
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+	Options  *chatOptions  `json:"options,omitempty"`
+}
+
+type chatOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	NumPredict  *int     `json:"num_predict,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
+}
+
+type chatResponse struct {
+	Model           string      `json:"model"`
+	Message         chatMessage `json:"message"`
+	Done            bool        `json:"done"`
+	DoneReason      string      `json:"done_reason"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+	EvalCount       int         `json:"eval_count"`
+	Error           string      `json:"error"`
+}
+
+func toChatRequest(req *domain.CompletionRequest) chatRequest {
+	messages := make([]chatMessage, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		messages = append(messages, chatMessage{Role: string(m.Role), Content: m.Content})
+	}
+
+	chatReq := chatRequest{
+		Model:    req.Model,
+		Messages: messages,
+	}
+
+	params := req.Parameters
+	if params.Temperature != nil || params.TopP != nil || params.MaxTokens != nil || len(params.Stop) > 0 ||
+		params.Seed != nil {
+		chatReq.Options = &chatOptions{
+			Temperature: params.Temperature,
+			TopP:        params.TopP,
+			NumPredict:  params.MaxTokens,
+			Stop:        params.Stop,
+			Seed:        params.Seed,
+		}
+	}
+	return chatReq
+}
+
+func toFinishReason(reason string) domain.FinishReason {
+	switch reason {
+	case "stop":
+		return domain.FinishReasonStop
+	case "length":
+		return domain.FinishReasonLength
+	default:
+		return domain.FinishReasonUnknown
+	}
+}
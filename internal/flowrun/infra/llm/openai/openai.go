@@ -0,0 +1,106 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"flow-run/internal/flowrun/infra/llm/llmhttp"
+	"fmt"
+	"net/http"
+)
+
+const DefaultBaseURL = "https://api.openai.com/v1"
+
+var ErrProviderType = errors.New("provider is not an OpenAI or OpenAI-compatible provider")
+
+// Client speaks the OpenAI Chat Completions API. It serves both the openai
+// type and openai_compatible servers such as vLLM, which only differ in base
+// URL and in whether an API key is sent.
+type Client struct {
+	providerType domain.ProviderType
+	baseURL      string
+	apiKey       string
+	httpClient   *http.Client
+}
+
+type Opt func(*Client)
+
+func WithBaseURL(baseURL string) Opt {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) Opt {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+func NewClient(provider *domain.Provider, opts ...Opt) (*Client, error) {
+	if provider.Type != domain.ProviderTypeOpenAI && provider.Type != domain.ProviderTypeOpenAICompatible {
+		return nil, fmt.Errorf("%w: %s", ErrProviderType, provider.Type)
+	}
+
+	c := &Client{
+		providerType: provider.Type,
+		baseURL:      DefaultBaseURL,
+		apiKey:       provider.ApiKey,
+		httpClient:   llmhttp.NewHTTPClient(),
+	}
+	if provider.BaseURL != "" {
+		c.baseURL = provider.BaseURL
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+func (c *Client) ChatCompletion(
+	ctx context.Context, req *domain.CompletionRequest,
+) (*domain.CompletionResponse, error) {
+	header := http.Header{}
+	if c.apiKey != "" {
+		header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := llmhttp.PostJSON(ctx, c.httpClient, c.baseURL+"/chat/completions", header, ToChatRequest(req))
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.OK() {
+		return nil, c.toLLMError(resp)
+	}
+
+	var chatResp ChatResponse
+	if err := json.Unmarshal(resp.Body, &chatResp); err != nil {
+		return nil, c.newError(resp.StatusCode, err.Error(), port.ErrLLMInvalidResponse)
+	}
+
+	completion, ok := chatResp.ToCompletionResponse()
+	if !ok {
+		return nil, c.newError(resp.StatusCode, "response has no choices", port.ErrLLMInvalidResponse)
+	}
+	return completion, nil
+}
+
+func (c *Client) toLLMError(resp *llmhttp.Response) *port.LLMError {
+	var errResp errorResponse
+	if err := json.Unmarshal(resp.Body, &errResp); err != nil || errResp.Error == nil {
+		return c.newError(resp.StatusCode, string(resp.Body), port.ErrorKindForStatus(resp.StatusCode))
+	}
+	return c.newError(resp.StatusCode, errResp.Error.Message, errResp.Error.kind(resp.StatusCode))
+}
+
+func (c *Client) newError(status int, message string, kind error) *port.LLMError {
+	return &port.LLMError{
+		Provider:   c.providerType,
+		StatusCode: status,
+		Message:    message,
+		Kind:       kind,
+	}
+}
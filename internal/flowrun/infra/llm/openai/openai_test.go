@@ -0,0 +1,243 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatCompletion(t *testing.T) {
+	t.Parallel()
+
+	apiKey := faker.Password()
+	var got ChatRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer "+apiKey, r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+		_, _ = w.Write([]byte(`{
+			"id": "chatcmpl-1",
+			"model": "gpt-4o-mini-2024-07-18",
+			"choices": [{"finish_reason": "stop", "message": {"role": "assistant", "content": "Hello"}}],
+			"usage": {"prompt_tokens": 9, "completion_tokens": 2, "total_tokens": 11}
+		}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, domain.ProviderTypeOpenAI, server.URL+"/v1", apiKey)
+
+	maxTokens := 16
+	resp, err := client.ChatCompletion(context.Background(), &domain.CompletionRequest{
+		Model:      "gpt-4o-mini",
+		Messages:   []domain.Message{{Role: domain.MessageRoleUser, Content: "Hi"}},
+		Parameters: domain.CompletionParameters{MaxTokens: &maxTokens},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "gpt-4o-mini", got.Model)
+	require.NotNil(t, got.MaxTokens)
+	assert.Equal(t, 16, *got.MaxTokens)
+
+	assert.Equal(t, "chatcmpl-1", resp.ID)
+	assert.Equal(t, "gpt-4o-mini-2024-07-18", resp.Model)
+	assert.Equal(t, "Hello", resp.Message.Content)
+	assert.Equal(t, domain.FinishReasonStop, resp.FinishReason)
+	assert.Equal(t, domain.TokenUsage{PromptTokens: 9, CompletionTokens: 2, TotalTokens: 11}, resp.Usage)
+}
+
+func TestChatCompletionWithTools(t *testing.T) {
+	t.Parallel()
+
+	var got ChatRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+		_, _ = w.Write([]byte(`{
+			"id": "chatcmpl-2",
+			"model": "gpt-4o",
+			"choices": [{
+				"finish_reason": "tool_calls",
+				"message": {"role": "assistant", "content": "", "tool_calls": [
+					{"id": "call_2", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\":\"Lviv\"}"}}
+				]}
+			}],
+			"usage": {
+				"prompt_tokens": 1200, "completion_tokens": 20, "total_tokens": 1220,
+				"prompt_tokens_details": {"cached_tokens": 1024}
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, domain.ProviderTypeOpenAI, server.URL, faker.Password())
+
+	resp, err := client.ChatCompletion(context.Background(), &domain.CompletionRequest{
+		Model: "gpt-4o",
+		Messages: []domain.Message{
+			{Role: domain.MessageRoleUser, Content: "Weather in Kyiv and Lviv?"},
+			{Role: domain.MessageRoleAssistant, ToolCalls: []domain.ToolCall{
+				{ID: "call_1", Name: "get_weather", Arguments: json.RawMessage(`{"city":"Kyiv"}`)},
+			}},
+			{Role: domain.MessageRoleTool, ToolCallID: "call_1", Content: `{"temp":21}`},
+		},
+		Tools: []domain.Tool{
+			{Name: "get_weather", Description: "Current weather", InputSchema: json.RawMessage(`{"type":"object"}`)},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, got.Tools, 1)
+	assert.Equal(t, "function", got.Tools[0].Type)
+	assert.Equal(t, "get_weather", got.Tools[0].Function.Name)
+	require.Len(t, got.Messages, 3)
+	require.Len(t, got.Messages[1].ToolCalls, 1)
+	assert.JSONEq(t, `{"city":"Kyiv"}`, got.Messages[1].ToolCalls[0].Function.Arguments)
+	assert.Equal(t, "call_1", got.Messages[2].ToolCallID)
+
+	assert.Equal(t, domain.FinishReasonToolCalls, resp.FinishReason)
+	require.Len(t, resp.Message.ToolCalls, 1)
+	assert.Equal(t, "call_2", resp.Message.ToolCalls[0].ID)
+	assert.JSONEq(t, `{"city":"Lviv"}`, string(resp.Message.ToolCalls[0].Arguments))
+	assert.Equal(t, 1024, resp.Usage.CacheReadTokens)
+}
+
+func TestChatCompletionIfCompatibleServerWithoutAPIKey(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+
+		_, _ = w.Write([]byte(`{
+			"id": "cmpl-vllm",
+			"model": "meta-llama/Llama-3.1-8B-Instruct",
+			"choices": [{"finish_reason": "length", "message": {"role": "assistant", "content": "Hel"}}],
+			"usage": {"prompt_tokens": 5, "completion_tokens": 1}
+		}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, domain.ProviderTypeOpenAICompatible, server.URL, "")
+
+	resp, err := client.ChatCompletion(context.Background(), &domain.CompletionRequest{
+		Model:    "meta-llama/Llama-3.1-8B-Instruct",
+		Messages: []domain.Message{{Role: domain.MessageRoleUser, Content: "Hi"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.FinishReasonLength, resp.FinishReason)
+	assert.Equal(t, domain.TokenUsage{PromptTokens: 5, CompletionTokens: 1, TotalTokens: 6}, resp.Usage)
+}
+
+func TestChatCompletionIfProviderFails(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		kind   error
+	}{
+		{
+			name:   "invalid_api_key",
+			status: http.StatusUnauthorized,
+			body:   `{"error": {"message": "Incorrect API key", "type": "invalid_request_error", "code": "invalid_api_key"}}`,
+			kind:   port.ErrLLMAuthentication,
+		},
+		{
+			name:   "insufficient_quota",
+			status: http.StatusTooManyRequests,
+			body: `{"error": {"message": "You exceeded your quota", "type": "insufficient_quota",
+				"code": "insufficient_quota"}}`,
+			kind: port.ErrLLMQuotaExceeded,
+		},
+		{
+			name:   "rate_limited",
+			status: http.StatusTooManyRequests,
+			body:   `{"error": {"message": "Rate limit reached", "type": "requests", "code": "rate_limit_exceeded"}}`,
+			kind:   port.ErrLLMRateLimited,
+		},
+		{
+			name:   "context_length_exceeded",
+			status: http.StatusBadRequest,
+			body:   `{"error": {"message": "too long", "type": "invalid_request_error", "code": "context_length_exceeded"}}`,
+			kind:   port.ErrLLMInvalidRequest,
+		},
+		{
+			name:   "server_error_without_body",
+			status: http.StatusServiceUnavailable,
+			body:   `upstream connect error`,
+			kind:   port.ErrLLMUnavailable,
+		},
+		{
+			name:   "compatible_server_numeric_code",
+			status: http.StatusNotFound,
+			body:   `{"error": {"message": "model not found", "type": "NotFoundError", "code": 404}}`,
+			kind:   port.ErrLLMInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := newTestClient(t, domain.ProviderTypeOpenAI, server.URL, faker.Password())
+
+			_, err := client.ChatCompletion(context.Background(), &domain.CompletionRequest{
+				Model:    "gpt-4o-mini",
+				Messages: []domain.Message{{Role: domain.MessageRoleUser, Content: "Hi"}},
+			})
+
+			require.ErrorIs(t, err, tt.kind)
+
+			var llmErr *port.LLMError
+			require.ErrorAs(t, err, &llmErr)
+			assert.Equal(t, domain.ProviderTypeOpenAI, llmErr.Provider)
+			assert.Equal(t, tt.status, llmErr.StatusCode)
+		})
+	}
+}
+
+func TestNewClientIfWrongProviderType(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewClient(&domain.Provider{Type: domain.ProviderTypeOllama})
+
+	require.ErrorIs(t, err, ErrProviderType)
+}
+
+func newTestClient(t *testing.T, providerType domain.ProviderType, baseURL, apiKey string) *Client {
+	t.Helper()
+
+	provider, err := domain.NewProvider(
+		domain.WithProviderID(uuid.New()),
+		domain.WithProviderName("openai"),
+		domain.WithProviderAccountID(uuid.New()),
+		domain.WithProviderType(providerType),
+		domain.WithProviderApiKey(apiKey),
+		domain.WithProviderBaseURL(baseURL),
+	)
+	require.NoError(t, err)
+
+	client, err := NewClient(provider)
+	require.NoError(t, err)
+
+	return client
+}
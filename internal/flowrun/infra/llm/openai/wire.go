@@ -0,0 +1,232 @@
+package openai
+
+import (
+	"encoding/json"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+)
+
+// The Chat Completions wire types are exported because OpenRouter speaks the
+// same format and extends it with its own fields.
+
+type ChatMessage struct {
+	Role       string         `json:"role"`
+	Content    string         `json:"content"`
+	ToolCalls  []ChatToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+type ChatToolCall struct {
+	ID       string           `json:"id"`
+	Type     string           `json:"type"`
+	Function ChatFunctionCall `json:"function"`
+}
+
+// ChatFunctionCall carries arguments as a JSON-encoded string, not an object.
+type ChatFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type ChatTool struct {
+	Type     string          `json:"type"`
+	Function ChatFunctionDef `json:"function"`
+}
+
+type ChatFunctionDef struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters"`
+}
+
+type ChatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []ChatMessage `json:"messages"`
+	Tools       []ChatTool    `json:"tools,omitempty"`
+	Temperature *float64      `json:"temperature,omitempty"`
+	TopP        *float64      `json:"top_p,omitempty"`
+	MaxTokens   *int          `json:"max_tokens,omitempty"`
+	Stop        []string      `json:"stop,omitempty"`
+	Seed        *int          `json:"seed,omitempty"`
+}
+
+type ChatResponse struct {
+	ID      string       `json:"id"`
+	Model   string       `json:"model"`
+	Choices []ChatChoice `json:"choices"`
+	Usage   *ChatUsage   `json:"usage"`
+}
+
+type ChatChoice struct {
+	Message      ChatMessage `json:"message"`
+	FinishReason string      `json:"finish_reason"`
+}
+
+type ChatUsage struct {
+	PromptTokens        int                  `json:"prompt_tokens"`
+	CompletionTokens    int                  `json:"completion_tokens"`
+	TotalTokens         int                  `json:"total_tokens"`
+	PromptTokensDetails *PromptTokensDetails `json:"prompt_tokens_details"`
+}
+
+type PromptTokensDetails struct {
+	CachedTokens int `json:"cached_tokens"`
+}
+
+type errorResponse struct {
+	Error *apiError `json:"error"`
+}
+
+// apiError is the OpenAI error object. Code is a string such as
+// "invalid_api_key" and may be absent on compatible servers.
+type apiError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    any    `json:"code"`
+}
+
+func ToChatRequest(req *domain.CompletionRequest) ChatRequest {
+	messages := make([]ChatMessage, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		messages = append(messages, toChatMessage(m))
+	}
+
+	var tools []ChatTool
+	for _, t := range req.Tools {
+		tools = append(tools, ChatTool{
+			Type: "function",
+			Function: ChatFunctionDef{
+				Name:        t.Name,
+				Description: t.Description,
+				Parameters:  t.InputSchema,
+			},
+		})
+	}
+
+	return ChatRequest{
+		Model:       req.Model,
+		Messages:    messages,
+		Tools:       tools,
+		Temperature: req.Parameters.Temperature,
+		TopP:        req.Parameters.TopP,
+		MaxTokens:   req.Parameters.MaxTokens,
+		Stop:        req.Parameters.Stop,
+		Seed:        req.Parameters.Seed,
+	}
+}
+
+func toChatMessage(m domain.Message) ChatMessage {
+	msg := ChatMessage{
+		Role:       string(m.Role),
+		Content:    m.Content,
+		ToolCallID: m.ToolCallID,
+	}
+	for _, call := range m.ToolCalls {
+		arguments := string(call.Arguments)
+		if arguments == "" {
+			arguments = "{}"
+		}
+		msg.ToolCalls = append(msg.ToolCalls, ChatToolCall{
+			ID:       call.ID,
+			Type:     "function",
+			Function: ChatFunctionCall{Name: call.Name, Arguments: arguments},
+		})
+	}
+	return msg
+}
+
+// ToCompletionResponse converts the first choice. It reports false when the
+// response has no choices.
+func (r *ChatResponse) ToCompletionResponse() (*domain.CompletionResponse, bool) {
+	if len(r.Choices) == 0 {
+		return nil, false
+	}
+
+	choice := r.Choices[0]
+	resp := &domain.CompletionResponse{
+		ID:    r.ID,
+		Model: r.Model,
+		Message: domain.Message{
+			Role:    domain.MessageRoleAssistant,
+			Content: choice.Message.Content,
+		},
+		FinishReason: ToFinishReason(choice.FinishReason),
+	}
+	for _, call := range choice.Message.ToolCalls {
+		resp.Message.ToolCalls = append(resp.Message.ToolCalls, domain.ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: toArguments(call.Function.Arguments),
+		})
+	}
+	if r.Usage != nil {
+		resp.Usage = r.Usage.ToTokenUsage()
+	}
+	return resp, true
+}
+
+// ToTokenUsage fills TotalTokens when a compatible server omits it.
+func (u *ChatUsage) ToTokenUsage() domain.TokenUsage {
+	usage := domain.TokenUsage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+	}
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+	if u.PromptTokensDetails != nil {
+		usage.CacheReadTokens = u.PromptTokensDetails.CachedTokens
+	}
+	return usage
+}
+
+// toArguments keeps malformed model output inspectable by wrapping it in a
+// JSON string instead of dropping it.
+func toArguments(arguments string) json.RawMessage {
+	if arguments == "" {
+		return json.RawMessage("{}")
+	}
+	if json.Valid([]byte(arguments)) {
+		return json.RawMessage(arguments)
+	}
+	quoted, _ := json.Marshal(arguments)
+	return quoted
+}
+
+// kind maps OpenAI error codes first because a 429 can mean either a
+// transient rate limit or an exhausted quota that retries will not fix.
+func (e *apiError) kind(status int) error {
+	code, _ := e.Code.(string)
+	switch {
+	case code == "insufficient_quota" || e.Type == "insufficient_quota":
+		return port.ErrLLMQuotaExceeded
+	case code == "invalid_api_key" || e.Type == "authentication_error":
+		return port.ErrLLMAuthentication
+	case code == "rate_limit_exceeded":
+		return port.ErrLLMRateLimited
+	case code == "context_length_exceeded" || e.Type == "invalid_request_error":
+		return port.ErrLLMInvalidRequest
+	case e.Type == "server_error":
+		return port.ErrLLMUnavailable
+	default:
+		return port.ErrorKindForStatus(status)
+	}
+}
+
+func ToFinishReason(reason string) domain.FinishReason {
+	switch reason {
+	case "stop":
+		return domain.FinishReasonStop
+	case "length":
+		return domain.FinishReasonLength
+	case "tool_calls", "function_call":
+		return domain.FinishReasonToolCalls
+	case "content_filter":
+		return domain.FinishReasonContentFilter
+	case "error":
+		return domain.FinishReasonError
+	default:
+		return domain.FinishReasonUnknown
+	}
+}
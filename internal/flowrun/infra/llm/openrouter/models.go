@@ -0,0 +1,114 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"flow-run/internal/flowrun/infra/llm/llmhttp"
+	"fmt"
+	"math/big"
+	"net/http"
+)
+
+// tokensPerPriceUnit converts OpenRouter's per-token dollar prices to Money
+// per million tokens: a million tokens, in nano-dollars.
+var tokensPerPriceUnit = big.NewRat(1_000_000*1_000_000_000, 1)
+
+type modelsResponse struct {
+	Data []modelEntry `json:"data"`
+}
+
+type modelEntry struct {
+	ID            string       `json:"id"`
+	ContextLength int          `json:"context_length"`
+	Pricing       modelPricing `json:"pricing"`
+}
+
+// modelPricing holds decimal dollars per token, e.g. "0.0000025".
+type modelPricing struct {
+	Prompt         string `json:"prompt"`
+	Completion     string `json:"completion"`
+	InputCacheRead string `json:"input_cache_read"`
+}
+
+// ListModels fetches OpenRouter's models list with the prices of each model
+// converted to dollars per million tokens. Routers priced per request, which
+// OpenRouter lists with negative prices, are left out.
+func (c *Client) ListModels(ctx context.Context) ([]domain.CatalogModel, error) {
+	header := http.Header{}
+	header.Set("X-Title", appTitle)
+	if c.apiKey != "" {
+		header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := llmhttp.Get(ctx, c.httpClient, c.baseURL+"/models", header)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.OK() {
+		return nil, newError(resp.StatusCode, string(resp.Body))
+	}
+
+	var decoded modelsResponse
+	if err := json.Unmarshal(resp.Body, &decoded); err != nil {
+		return nil, &port.LLMError{
+			Provider:   domain.ProviderTypeOpenRouter,
+			StatusCode: resp.StatusCode,
+			Message:    err.Error(),
+			Kind:       port.ErrLLMInvalidResponse,
+		}
+	}
+
+	models := make([]domain.CatalogModel, 0, len(decoded.Data))
+	for _, entry := range decoded.Data {
+		model, ok := entry.toCatalogModel()
+		if ok {
+			models = append(models, model)
+		}
+	}
+	return models, nil
+}
+
+func (e modelEntry) toCatalogModel() (domain.CatalogModel, bool) {
+	model := domain.CatalogModel{Name: e.ID, ContextWindow: e.ContextLength}
+	for _, price := range []struct {
+		text   string
+		target *domain.Money
+	}{
+		{e.Pricing.Prompt, &model.InputPrice},
+		{e.Pricing.Completion, &model.OutputPrice},
+		{e.Pricing.InputCacheRead, &model.CachedPrice},
+	} {
+		if price.text == "" {
+			continue
+		}
+		parsed, err := perMillion(price.text)
+		if err != nil || parsed < 0 {
+			return domain.CatalogModel{}, false
+		}
+		*price.target = parsed
+	}
+	return model, true
+}
+
+// perMillion converts a decimal per-token price to Money per million tokens,
+// rounded half away from zero to the nearest nano-dollar.
+func perMillion(text string) (domain.Money, error) {
+	price, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", domain.ErrInvalidMoney, text)
+	}
+	price.Mul(price, tokensPerPriceUnit)
+
+	half := big.NewRat(1, 2)
+	if price.Sign() < 0 {
+		half.Neg(half)
+	}
+	price.Add(price, half)
+	rounded := new(big.Int).Quo(price.Num(), price.Denom())
+	if !rounded.IsInt64() {
+		return 0, fmt.Errorf("%w: %q", domain.ErrInvalidMoney, text)
+	}
+	return domain.Money(rounded.Int64()), nil
+}
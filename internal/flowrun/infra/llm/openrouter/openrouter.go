@@ -0,0 +1,117 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"flow-run/internal/flowrun/infra/llm/llmhttp"
+	"fmt"
+	"net/http"
+)
+
+const (
+	DefaultBaseURL = "https://openrouter.ai/api/v1"
+	appTitle       = "flow-run"
+)
+
+var ErrProviderType = errors.New("provider is not an OpenRouter provider")
+
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+type Opt func(*Client)
+
+func WithBaseURL(baseURL string) Opt {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) Opt {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+func NewClient(provider *domain.Provider, opts ...Opt) (*Client, error) {
+	if provider.Type != domain.ProviderTypeOpenRouter {
+		return nil, fmt.Errorf("%w: %s", ErrProviderType, provider.Type)
+	}
+
+	c := &Client{
+		baseURL:    DefaultBaseURL,
+		apiKey:     provider.ApiKey,
+		httpClient: llmhttp.NewHTTPClient(),
+	}
+	if provider.BaseURL != "" {
+		c.baseURL = provider.BaseURL
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+func (c *Client) ChatCompletion(
+	ctx context.Context, req *domain.CompletionRequest,
+) (*domain.CompletionResponse, error) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+c.apiKey)
+	header.Set("X-Title", appTitle)
+
+	resp, err := llmhttp.PostJSON(ctx, c.httpClient, c.baseURL+"/chat/completions", header, toChatRequest(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var chatResp chatResponse
+	if err := json.Unmarshal(resp.Body, &chatResp); err != nil {
+		if !resp.OK() {
+			return nil, newError(resp.StatusCode, string(resp.Body))
+		}
+		return nil, &port.LLMError{
+			Provider:   domain.ProviderTypeOpenRouter,
+			StatusCode: resp.StatusCode,
+			Message:    err.Error(),
+			Kind:       port.ErrLLMInvalidResponse,
+		}
+	}
+
+	// OpenRouter reports some upstream failures with a 200 and an error body.
+	if chatResp.Error != nil {
+		return nil, chatResp.Error.toLLMError(resp.StatusCode)
+	}
+	if !resp.OK() {
+		return nil, newError(resp.StatusCode, string(resp.Body))
+	}
+
+	completion, ok := chatResp.ToCompletionResponse()
+	if !ok {
+		return nil, &port.LLMError{
+			Provider:   domain.ProviderTypeOpenRouter,
+			StatusCode: resp.StatusCode,
+			Message:    "response has no choices",
+			Kind:       port.ErrLLMInvalidResponse,
+		}
+	}
+	return completion, nil
+}
+
+func newError(status int, message string) *port.LLMError {
+	kind := port.ErrorKindForStatus(status)
+	if status == http.StatusPaymentRequired {
+		kind = port.ErrLLMQuotaExceeded
+	}
+
+	return &port.LLMError{
+		Provider:   domain.ProviderTypeOpenRouter,
+		StatusCode: status,
+		Message:    message,
+		Kind:       kind,
+	}
+}
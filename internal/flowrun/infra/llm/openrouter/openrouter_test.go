@@ -0,0 +1,240 @@
+package openrouter
+
+import (
+	"context"
+	"encoding/json"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatCompletion(t *testing.T) {
+	t.Parallel()
+
+	apiKey := faker.Password()
+	var got chatRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer "+apiKey, r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"id": "gen-1",
+			"model": "openai/gpt-4o-mini",
+			"choices": [{"finish_reason": "length", "message": {"role": "assistant", "content": "Hello"}}],
+			"usage": {"prompt_tokens": 12, "completion_tokens": 3, "total_tokens": 15}
+		}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, apiKey)
+
+	temperature := 0.2
+	req, err := domain.NewCompletionRequest("openai/gpt-4o-mini", []domain.Message{
+		{Role: domain.MessageRoleSystem, Content: "Be brief"},
+		{Role: domain.MessageRoleUser, Content: "Hi"},
+	}, domain.CompletionParameters{Temperature: &temperature})
+	require.NoError(t, err)
+
+	resp, err := client.ChatCompletion(context.Background(), req)
+	require.NoError(t, err)
+
+	assert.Equal(t, "openai/gpt-4o-mini", got.Model)
+	require.Len(t, got.Messages, 2)
+	assert.Equal(t, "system", got.Messages[0].Role)
+	require.NotNil(t, got.Temperature)
+	assert.InDelta(t, 0.2, *got.Temperature, 0.0001)
+
+	assert.Equal(t, "gen-1", resp.ID)
+	assert.Equal(t, "Hello", resp.Message.Content)
+	assert.Equal(t, domain.MessageRoleAssistant, resp.Message.Role)
+	assert.Equal(t, domain.FinishReasonLength, resp.FinishReason)
+	assert.Equal(t, domain.TokenUsage{PromptTokens: 12, CompletionTokens: 3, TotalTokens: 15}, resp.Usage)
+}
+
+func TestChatCompletionIfProviderFails(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		kind      error
+		retryable bool
+	}{
+		{
+			name:   "invalid_api_key",
+			status: http.StatusUnauthorized,
+			body:   `{"error": {"code": 401, "message": "No auth credentials found"}}`,
+			kind:   port.ErrLLMAuthentication,
+		},
+		{
+			name:      "rate_limited",
+			status:    http.StatusTooManyRequests,
+			body:      `{"error": {"code": 429, "message": "Rate limit exceeded"}}`,
+			kind:      port.ErrLLMRateLimited,
+			retryable: true,
+		},
+		{
+			name:      "upstream_error_with_ok_status",
+			status:    http.StatusOK,
+			body:      `{"error": {"message": "Upstream provider error"}}`,
+			kind:      port.ErrLLMUnavailable,
+			retryable: true,
+		},
+		{
+			name:   "insufficient_credits",
+			status: http.StatusPaymentRequired,
+			body:   `{"error": {"code": 402, "message": "Insufficient credits"}}`,
+			kind:   port.ErrLLMQuotaExceeded,
+		},
+		{
+			name:   "bad_request",
+			status: http.StatusBadRequest,
+			body:   `{"error": {"code": 400, "message": "model is required"}}`,
+			kind:   port.ErrLLMInvalidRequest,
+		},
+		{
+			name:      "non_json_gateway_error",
+			status:    http.StatusBadGateway,
+			body:      `<html>Bad Gateway</html>`,
+			kind:      port.ErrLLMUnavailable,
+			retryable: true,
+		},
+		{
+			name:   "no_choices",
+			status: http.StatusOK,
+			body:   `{"id": "gen-2", "choices": []}`,
+			kind:   port.ErrLLMInvalidResponse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := newTestClient(t, server.URL, faker.Password())
+
+			_, err := client.ChatCompletion(context.Background(), &domain.CompletionRequest{
+				Model:    "openai/gpt-4o-mini",
+				Messages: []domain.Message{{Role: domain.MessageRoleUser, Content: "Hi"}},
+			})
+
+			require.ErrorIs(t, err, tt.kind)
+
+			var llmErr *port.LLMError
+			require.ErrorAs(t, err, &llmErr)
+			assert.Equal(t, domain.ProviderTypeOpenRouter, llmErr.Provider)
+			assert.Equal(t, tt.retryable, llmErr.Retryable())
+		})
+	}
+}
+
+func TestChatCompletionIfContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, faker.Password())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.ChatCompletion(ctx, &domain.CompletionRequest{
+		Model:    "openai/gpt-4o-mini",
+		Messages: []domain.Message{{Role: domain.MessageRoleUser, Content: "Hi"}},
+	})
+
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestNewClientIfWrongProviderType(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewClient(&domain.Provider{Type: domain.ProviderType("other")})
+
+	require.ErrorIs(t, err, ErrProviderType)
+}
+
+func newTestClient(t *testing.T, baseURL, apiKey string) *Client {
+	t.Helper()
+
+	provider, err := domain.NewProvider(
+		domain.WithProviderID(uuid.New()),
+		domain.WithProviderName("openrouter"),
+		domain.WithProviderAccountID(uuid.New()),
+		domain.WithProviderType(domain.ProviderTypeOpenRouter),
+		domain.WithProviderApiKey(apiKey),
+	)
+	require.NoError(t, err)
+
+	client, err := NewClient(provider, WithBaseURL(baseURL))
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestListModels(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/models", r.URL.Path)
+		assert.Equal(t, http.MethodGet, r.Method)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": [
+			{
+				"id": "openai/gpt-4o",
+				"context_length": 128000,
+				"pricing": {"prompt": "0.0000025", "completion": "0.00001", "input_cache_read": "0.00000125"}
+			},
+			{"id": "meta/llama-free", "context_length": 8192, "pricing": {"prompt": "0", "completion": "0"}},
+			{"id": "openrouter/auto", "pricing": {"prompt": "-1", "completion": "-1"}}
+		]}`))
+	}))
+	defer server.Close()
+
+	models, err := newTestClient(t, server.URL, faker.Password()).ListModels(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []domain.CatalogModel{
+		{
+			Name:          "openai/gpt-4o",
+			ContextWindow: 128000,
+			InputPrice:    2_500_000_000,
+			OutputPrice:   10_000_000_000,
+			CachedPrice:   1_250_000_000,
+		},
+		{Name: "meta/llama-free", ContextWindow: 8192},
+	}, models)
+}
+
+func TestListModelsIfRequestFails(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(t, server.URL, faker.Password()).ListModels(context.Background())
+	require.ErrorIs(t, err, port.ErrLLMUnavailable)
+}
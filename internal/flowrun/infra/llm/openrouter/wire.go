@@ -0,0 +1,48 @@
+package openrouter
+
+import (
+	"flow-run/internal/core/domain"
+	"flow-run/internal/flowrun/infra/llm/openai"
+	"net/http"
+)
+
+// chatRequest is the OpenAI Chat Completions request plus OpenRouter's usage
+// accounting switch.
+type chatRequest struct {
+	openai.ChatRequest
+	Usage *usageOption `json:"usage,omitempty"`
+}
+
+type usageOption struct {
+	Include bool `json:"include"`
+}
+
+type chatResponse struct {
+	openai.ChatResponse
+	Error *chatError `json:"error"`
+}
+
+type chatError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func toChatRequest(req *domain.CompletionRequest) chatRequest {
+	return chatRequest{
+		ChatRequest: openai.ToChatRequest(req),
+		Usage:       &usageOption{Include: true},
+	}
+}
+
+// toLLMError prefers the code from the error body because OpenRouter may
+// return it with a 200 status. A 200 without a code means the upstream model
+// failed mid-request and is treated as a gateway error.
+func (e *chatError) toLLMError(status int) error {
+	switch {
+	case e.Code != 0:
+		status = e.Code
+	case status == http.StatusOK:
+		status = http.StatusBadGateway
+	}
+	return newError(status, e.Message)
+}
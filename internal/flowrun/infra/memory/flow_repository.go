@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// FlowRepository keeps flows with their parsed definitions, which are shared
+// between the copies it hands out and must not be modified.
+type FlowRepository struct {
+	mu    sync.RWMutex
+	flows map[uuid.UUID]domain.Flow
+}
+
+func NewFlowRepository() *FlowRepository {
+	return &FlowRepository{flows: map[uuid.UUID]domain.Flow{}}
+}
+
+// Create stores a flow, whose name must be unique within its account.
+func (r *FlowRepository) Create(ctx context.Context, f *domain.Flow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.flows[f.ID]; ok {
+		return &port.ConflictError{Entity: "flow", Key: f.ID.String(), Reason: "already exists"}
+	}
+	if err := r.checkName(f); err != nil {
+		return err
+	}
+	r.flows[f.ID] = *f
+	return nil
+}
+
+func (r *FlowRepository) Get(ctx context.Context, accountID, id uuid.UUID) (*domain.Flow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	f, ok := r.flows[id]
+	if !ok || f.AccountID != accountID {
+		return nil, &port.NotFoundError{Entity: "flow", Key: id.String()}
+	}
+	return &f, nil
+}
+
+// GetByName looks a flow up the way sub-flow steps reference it.
+func (r *FlowRepository) GetByName(ctx context.Context, accountID uuid.UUID, name string) (*domain.Flow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, f := range r.flows {
+		if f.AccountID == accountID && f.Name == name {
+			return &f, nil
+		}
+	}
+	return nil, &port.NotFoundError{Entity: "flow", Key: name}
+}
+
+// List returns the flows of an account by name.
+func (r *FlowRepository) List(ctx context.Context, accountID uuid.UUID) ([]*domain.Flow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var flows []*domain.Flow
+	for _, f := range r.flows {
+		if f.AccountID == accountID {
+			flows = append(flows, &f)
+		}
+	}
+	slices.SortFunc(flows, func(a, b *domain.Flow) int { return cmp.Compare(a.Name, b.Name) })
+	return flows, nil
+}
+
+func (r *FlowRepository) Update(ctx context.Context, f *domain.Flow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.flows[f.ID]
+	if !ok || current.AccountID != f.AccountID {
+		return &port.NotFoundError{Entity: "flow", Key: f.ID.String()}
+	}
+	if err := r.checkName(f); err != nil {
+		return err
+	}
+	r.flows[f.ID] = *f
+	return nil
+}
+
+func (r *FlowRepository) Delete(ctx context.Context, accountID, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.flows[id]
+	if !ok || f.AccountID != accountID {
+		return &port.NotFoundError{Entity: "flow", Key: id.String()}
+	}
+	delete(r.flows, id)
+	return nil
+}
+
+func (r *FlowRepository) checkName(f *domain.Flow) error {
+	for _, other := range r.flows {
+		if other.AccountID == f.AccountID && other.Name == f.Name && other.ID != f.ID {
+			return &port.ConflictError{Entity: "flow", Key: f.Name, Reason: "already exists"}
+		}
+	}
+	return nil
+}
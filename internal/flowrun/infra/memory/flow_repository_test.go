@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"flow-run/internal/core/port"
+	"flow-run/internal/flowrun/infra/repotest"
+	"testing"
+)
+
+func TestFlowRepository(t *testing.T) {
+	t.Parallel()
+
+	repotest.FlowRepository(t, func(*testing.T) port.FlowRepository { return NewFlowRepository() })
+}
@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// leaseExpiredMessage is recorded on jobs whose worker stopped extending its
+// lease.
+const leaseExpiredMessage = "lease expired: the worker stopped before it finished"
+
+// JobQueue keeps jobs the way the database queue does, for a single process.
+type JobQueue struct {
+	mu   sync.Mutex
+	jobs map[uuid.UUID]domain.Job
+}
+
+func NewJobQueue() *JobQueue {
+	return &JobQueue{jobs: map[uuid.UUID]domain.Job{}}
+}
+
+func (q *JobQueue) Enqueue(ctx context.Context, job *domain.Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.jobs[job.ID]; ok {
+		return &port.ConflictError{Entity: "job", Key: job.ID.String(), Reason: "already exists"}
+	}
+	q.jobs[job.ID] = *job
+	return nil
+}
+
+func (q *JobQueue) Claim(
+	ctx context.Context, worker string, kinds []domain.JobKind, now, until time.Time,
+) (*domain.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for {
+		var next *domain.Job
+		for _, job := range q.jobs {
+			due := (job.Status == domain.JobStatusQueued && !job.RunAt.After(now)) || job.Expired(now)
+			if !due || !slices.Contains(kinds, job.Kind) {
+				continue
+			}
+			if next == nil || before(&job, next) {
+				next = &job
+			}
+		}
+		if next == nil {
+			return nil, nil
+		}
+
+		if next.Expired(now) {
+			next.Fail(leaseExpiredMessage, now, now)
+		}
+		if next.Status != domain.JobStatusQueued {
+			q.jobs[next.ID] = *next
+			continue
+		}
+		next.Lease(worker, until)
+		q.jobs[next.ID] = *next
+		claimed := *next
+		return &claimed, nil
+	}
+}
+
+// before orders jobs the way they are claimed.
+func before(a, b *domain.Job) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	if !a.RunAt.Equal(b.RunAt) {
+		return a.RunAt.Before(b.RunAt)
+	}
+	return a.ID.String() < b.ID.String()
+}
+
+func (q *JobQueue) Update(ctx context.Context, job *domain.Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stored, ok := q.jobs[job.ID]
+	if !ok || stored.Status != domain.JobStatusRunning || stored.LockedBy != job.LockedBy ||
+		stored.Attempts != job.Attempts {
+		return &port.ConflictError{Entity: "job", Key: job.ID.String(), Reason: "is no longer claimed by " + job.LockedBy}
+	}
+	q.jobs[job.ID] = *job
+	return nil
+}
+
+func (q *JobQueue) Get(ctx context.Context, id uuid.UUID) (*domain.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return nil, &port.NotFoundError{Entity: "job", Key: id.String()}
+	}
+	return &job, nil
+}
@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"flow-run/internal/core/port"
+	"flow-run/internal/flowrun/infra/repotest"
+	"testing"
+)
+
+func TestJobQueue(t *testing.T) {
+	t.Parallel()
+
+	repotest.JobQueue(t, func(*testing.T) port.JobQueue { return NewJobQueue() })
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// ModelRepository keeps models with the prices they were stored with; it has
+// no pricing catalog.
+type ModelRepository struct {
+	mu     sync.RWMutex
+	models map[uuid.UUID]domain.Model
+}
+
+func NewModelRepository() *ModelRepository {
+	return &ModelRepository{models: map[uuid.UUID]domain.Model{}}
+}
+
+// Create stores a model, whose name must be unique within its account.
+func (r *ModelRepository) Create(ctx context.Context, m *domain.Model) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.models[m.ID]; ok {
+		return &port.ConflictError{Entity: "model", Key: m.ID.String(), Reason: "already exists"}
+	}
+	if err := r.checkName(m); err != nil {
+		return err
+	}
+	r.models[m.ID] = *m
+	return nil
+}
+
+func (r *ModelRepository) Get(ctx context.Context, accountID, id uuid.UUID) (*domain.Model, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.models[id]
+	if !ok || m.AccountID != accountID {
+		return nil, &port.NotFoundError{Entity: "model", Key: id.String()}
+	}
+	return &m, nil
+}
+
+func (r *ModelRepository) GetByName(ctx context.Context, accountID uuid.UUID, name string) (*domain.Model, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, m := range r.models {
+		if m.AccountID == accountID && m.Name == name {
+			return &m, nil
+		}
+	}
+	return nil, &port.NotFoundError{Entity: "model", Key: name}
+}
+
+// List returns the models of an account by name.
+func (r *ModelRepository) List(ctx context.Context, accountID uuid.UUID) ([]*domain.Model, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var models []*domain.Model
+	for _, m := range r.models {
+		if m.AccountID == accountID {
+			models = append(models, &m)
+		}
+	}
+	slices.SortFunc(models, func(a, b *domain.Model) int { return cmp.Compare(a.Name, b.Name) })
+	return models, nil
+}
+
+func (r *ModelRepository) Update(ctx context.Context, m *domain.Model) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.models[m.ID]
+	if !ok || current.AccountID != m.AccountID {
+		return &port.NotFoundError{Entity: "model", Key: m.ID.String()}
+	}
+	if err := r.checkName(m); err != nil {
+		return err
+	}
+	r.models[m.ID] = *m
+	return nil
+}
+
+func (r *ModelRepository) Delete(ctx context.Context, accountID, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.models[id]
+	if !ok || m.AccountID != accountID {
+		return &port.NotFoundError{Entity: "model", Key: id.String()}
+	}
+	delete(r.models, id)
+	return nil
+}
+
+func (r *ModelRepository) checkName(m *domain.Model) error {
+	for _, other := range r.models {
+		if other.AccountID == m.AccountID && other.Name == m.Name && other.ID != m.ID {
+			return &port.ConflictError{Entity: "model", Key: m.Name, Reason: "already exists"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Package memory implements the core repositories in memory, for unit tests
+// and for trying flows out without a database. Repositories store copies of
+// the records they are given and hand out copies, like a database would, and
+// report the same typed errors as package database. References between
+// repositories, such as the provider of a model, are not checked.
+package memory
+
+import (
+	"cmp"
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// ProviderRepository keeps providers as given: API keys are not sealed. Like
+// the providers table, it allows several providers of the same name.
+type ProviderRepository struct {
+	mu        sync.RWMutex
+	providers map[uuid.UUID]domain.Provider
+}
+
+func NewProviderRepository() *ProviderRepository {
+	return &ProviderRepository{providers: map[uuid.UUID]domain.Provider{}}
+}
+
+func (r *ProviderRepository) Create(ctx context.Context, p *domain.Provider) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.providers[p.ID]; ok {
+		return &port.ConflictError{Entity: "provider", Key: p.ID.String(), Reason: "already exists"}
+	}
+	r.providers[p.ID] = *p
+	return nil
+}
+
+func (r *ProviderRepository) Get(ctx context.Context, accountID, id uuid.UUID) (*domain.Provider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.providers[id]
+	if !ok || p.AccountID != accountID {
+		return nil, &port.NotFoundError{Entity: "provider", Key: id.String()}
+	}
+	return &p, nil
+}
+
+// List returns the providers of an account by name.
+func (r *ProviderRepository) List(ctx context.Context, accountID uuid.UUID) ([]*domain.Provider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var providers []*domain.Provider
+	for _, p := range r.providers {
+		if p.AccountID == accountID {
+			providers = append(providers, &p)
+		}
+	}
+	slices.SortFunc(providers, func(a, b *domain.Provider) int { return cmp.Compare(a.Name, b.Name) })
+	return providers, nil
+}
+
+func (r *ProviderRepository) Update(ctx context.Context, p *domain.Provider) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.providers[p.ID]
+	if !ok || current.AccountID != p.AccountID {
+		return &port.NotFoundError{Entity: "provider", Key: p.ID.String()}
+	}
+	r.providers[p.ID] = *p
+	return nil
+}
+
+func (r *ProviderRepository) Delete(ctx context.Context, accountID, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.providers[id]
+	if !ok || p.AccountID != accountID {
+		return &port.NotFoundError{Entity: "provider", Key: id.String()}
+	}
+	delete(r.providers, id)
+	return nil
+}
@@ -0,0 +1,185 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RunRepository keeps flow runs and their step runs.
+type RunRepository struct {
+	mu    sync.RWMutex
+	runs  map[uuid.UUID]domain.FlowRun
+	steps map[uuid.UUID]domain.StepRun
+}
+
+func NewRunRepository() *RunRepository {
+	return &RunRepository{runs: map[uuid.UUID]domain.FlowRun{}, steps: map[uuid.UUID]domain.StepRun{}}
+}
+
+func (r *RunRepository) CreateRun(ctx context.Context, run *domain.FlowRun, steps []*domain.StepRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.runs[run.ID]; ok {
+		return &port.ConflictError{Entity: "run", Key: run.ID.String(), Reason: "already exists"}
+	}
+	for _, step := range steps {
+		if _, ok := r.steps[step.ID]; ok {
+			return &port.ConflictError{Entity: "step run", Key: step.ID.String(), Reason: "already exists"}
+		}
+	}
+
+	r.runs[run.ID] = *run
+	for _, step := range steps {
+		r.steps[step.ID] = *step
+	}
+	return nil
+}
+
+// UpdateRun keeps the stored CancelRequestedAt and LeaseExpiresAt, which
+// only CancelRun and ExtendRunLease set.
+func (r *RunRepository) UpdateRun(ctx context.Context, run *domain.FlowRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.runs[run.ID]
+	if !ok {
+		return &port.NotFoundError{Entity: "run", Key: run.ID.String()}
+	}
+	updated := *run
+	updated.CancelRequestedAt = stored.CancelRequestedAt
+	updated.LeaseExpiresAt = stored.LeaseExpiresAt
+	r.runs[run.ID] = updated
+	return nil
+}
+
+func (r *RunRepository) UpdateStepRun(ctx context.Context, step *domain.StepRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.steps[step.ID]; !ok {
+		return &port.NotFoundError{Entity: "step run", Key: step.ID.String()}
+	}
+	r.steps[step.ID] = *step
+	return nil
+}
+
+func (r *RunRepository) GetRun(ctx context.Context, accountID, id uuid.UUID) (*domain.FlowRun, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	run, ok := r.runs[id]
+	if !ok || run.AccountID != accountID {
+		return nil, &port.NotFoundError{Entity: "run", Key: id.String()}
+	}
+	return &run, nil
+}
+
+// ListStepRuns returns the step runs of a run by position.
+func (r *RunRepository) ListStepRuns(ctx context.Context, runID uuid.UUID) ([]*domain.StepRun, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var steps []*domain.StepRun
+	for _, step := range r.steps {
+		if step.RunID == runID {
+			steps = append(steps, &step)
+		}
+	}
+	slices.SortFunc(steps, func(a, b *domain.StepRun) int { return cmp.Compare(a.Position, b.Position) })
+	return steps, nil
+}
+
+func (r *RunRepository) CancelRun(
+	ctx context.Context, accountID, id uuid.UUID, now time.Time,
+) (*domain.FlowRun, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	run, ok := r.runs[id]
+	if !ok || run.AccountID != accountID {
+		return nil, &port.NotFoundError{Entity: "run", Key: id.String()}
+	}
+	if run.Status.Finished() || run.CancelRequestedAt != nil {
+		return &run, nil
+	}
+
+	run.CancelRequestedAt = &now
+	if run.Status == domain.RunStatusPending {
+		run.Status, run.FinishedAt = domain.RunStatusCanceled, &now
+		for stepID, step := range r.steps {
+			if step.RunID == id && step.Status == domain.RunStatusPending {
+				step.Status, step.FinishedAt = domain.RunStatusSkipped, &now
+				r.steps[stepID] = step
+			}
+		}
+	}
+	r.runs[id] = run
+	return &run, nil
+}
+
+func (r *RunRepository) ExtendRunLease(ctx context.Context, id uuid.UUID, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	run, ok := r.runs[id]
+	if !ok {
+		return &port.NotFoundError{Entity: "run", Key: id.String()}
+	}
+	run.LeaseExpiresAt = &until
+	r.runs[id] = run
+	return nil
+}
+
+func (r *RunRepository) FailExpiredRuns(ctx context.Context, now time.Time, message string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	failed := map[uuid.UUID]bool{}
+	for id, run := range r.runs {
+		if run.Status == domain.RunStatusRunning && run.ParentStepRunID == nil &&
+			run.LeaseExpiresAt != nil && !run.LeaseExpiresAt.After(now) {
+			failed[id] = true
+		}
+	}
+	// Sub-flow runs join once the run of their parent step has.
+	for grown := true; grown; {
+		grown = false
+		for id, run := range r.runs {
+			if failed[id] || run.Status.Finished() || run.ParentStepRunID == nil {
+				continue
+			}
+			if parent, ok := r.steps[*run.ParentStepRunID]; ok && failed[parent.RunID] {
+				failed[id], grown = true, true
+			}
+		}
+	}
+
+	for id := range failed {
+		run := r.runs[id]
+		run.Status, run.Error, run.FinishedAt = domain.RunStatusFailed, message, &now
+		r.runs[id] = run
+	}
+	for id, step := range r.steps {
+		if !failed[step.RunID] {
+			continue
+		}
+		switch step.Status {
+		case domain.RunStatusRunning:
+			step.Status, step.Error, step.FinishedAt = domain.RunStatusFailed, message, &now
+		case domain.RunStatusPending:
+			step.Status, step.FinishedAt = domain.RunStatusSkipped, &now
+		default:
+			continue
+		}
+		r.steps[id] = step
+	}
+	return len(failed), nil
+}
@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"flow-run/internal/core/port"
+	"flow-run/internal/flowrun/infra/repotest"
+	"testing"
+)
+
+func TestRunRepository(t *testing.T) {
+	t.Parallel()
+
+	repotest.RunRepository(t, func(*testing.T) port.FlowRunRepository { return NewRunRepository() })
+}
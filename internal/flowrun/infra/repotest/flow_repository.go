@@ -0,0 +1,128 @@
+package repotest
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// FlowRepository runs the contract of port.FlowRepository against the empty
+// repositories newRepo returns, one per subtest.
+func FlowRepository(t *testing.T, newRepo func(t *testing.T) port.FlowRepository) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repo port.FlowRepository)
+	}{
+		{name: "crud", test: flowCRUD},
+		{name: "errors", test: flowErrors},
+		{name: "concurrent_create", test: flowConcurrentCreate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.test(t, newRepo(t))
+		})
+	}
+}
+
+func flowCRUD(t *testing.T, repo port.FlowRepository) {
+	ctx := context.Background()
+	accountID := uuid.New()
+	b, a := NewFlow(t, accountID, "b"), NewFlow(t, accountID, "a")
+	require.NoError(t, repo.Create(ctx, b))
+	require.NoError(t, repo.Create(ctx, a))
+	require.NoError(t, repo.Create(ctx, NewFlow(t, uuid.New(), "a")))
+
+	got, err := repo.GetByName(ctx, accountID, "a")
+	require.NoError(t, err)
+	assert.Equal(t, a, got)
+
+	// Changing what was read leaves the stored flow alone.
+	got.Name = "changed"
+	got, err = repo.Get(ctx, accountID, a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "a", got.Name)
+
+	flows, err := repo.List(ctx, accountID)
+	require.NoError(t, err)
+	assert.Equal(t, []*domain.Flow{a, b}, flows)
+
+	renamed := NewFlow(t, accountID, "c")
+	renamed.ID = b.ID
+	require.NoError(t, repo.Update(ctx, renamed))
+	got, err = repo.Get(ctx, accountID, b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "c", got.Name)
+
+	require.NoError(t, repo.Delete(ctx, accountID, b.ID))
+	_, err = repo.Get(ctx, accountID, b.ID)
+	require.ErrorIs(t, err, port.ErrNotFound)
+}
+
+func flowErrors(t *testing.T, repo port.FlowRepository) {
+	ctx := context.Background()
+	accountID := uuid.New()
+	flow := NewFlow(t, accountID, "a")
+	require.NoError(t, repo.Create(ctx, flow))
+	other := NewFlow(t, accountID, "b")
+	require.NoError(t, repo.Create(ctx, other))
+
+	renamed := *other
+	renamed.Name = "a"
+	foreign := *flow
+	foreign.AccountID = uuid.New()
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "get_other_account", err: get(repo.Get(ctx, uuid.New(), flow.ID)), want: port.ErrNotFound},
+		{name: "get_unknown_name", err: get(repo.GetByName(ctx, accountID, "c")), want: port.ErrNotFound},
+		{name: "create_same_id", err: repo.Create(ctx, flow), want: port.ErrConflict},
+		{name: "create_same_name", err: repo.Create(ctx, NewFlow(t, accountID, "a")), want: port.ErrConflict},
+		{name: "update_to_taken_name", err: repo.Update(ctx, &renamed), want: port.ErrConflict},
+		{name: "update_other_account", err: repo.Update(ctx, &foreign), want: port.ErrNotFound},
+		{name: "delete_unknown", err: repo.Delete(ctx, accountID, uuid.New()), want: port.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.ErrorIs(t, tt.err, tt.want)
+		})
+	}
+
+	var notFound *port.NotFoundError
+	_, err := repo.GetByName(ctx, accountID, "c")
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, &port.NotFoundError{Entity: "flow", Key: "c"}, notFound)
+}
+
+func flowConcurrentCreate(t *testing.T, repo port.FlowRepository) {
+	ctx := context.Background()
+	accountID := uuid.New()
+
+	errs := make(chan error, 8)
+	for range cap(errs) {
+		flow := NewFlow(t, accountID, "a")
+		go func() { errs <- repo.Create(ctx, flow) }()
+	}
+	created := 0
+	for range cap(errs) {
+		if err := <-errs; err == nil {
+			created++
+		} else {
+			require.ErrorIs(t, err, port.ErrConflict)
+		}
+	}
+	assert.Equal(t, 1, created)
+}
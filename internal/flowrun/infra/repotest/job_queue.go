@@ -0,0 +1,129 @@
+package repotest
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// JobQueue runs the contract of port.JobQueue against the empty queues
+// newQueue returns, one per subtest.
+func JobQueue(t *testing.T, newQueue func(t *testing.T) port.JobQueue) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, queue port.JobQueue)
+	}{
+		{name: "claim", test: jobClaim},
+		{name: "claim_if_last_attempt_expired", test: jobClaimIfLastAttemptExpired},
+		{name: "update", test: jobUpdate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.test(t, newQueue(t))
+		})
+	}
+}
+
+func jobClaim(t *testing.T, queue port.JobQueue) {
+	ctx := context.Background()
+	kind := domain.JobKindFlowRun
+	now := time.Now()
+	low := newJob(t, kind, now)
+	high := newJob(t, kind, now, domain.WithJobPriority(10))
+	later := newJob(t, kind, now, domain.WithJobPriority(20), domain.WithJobRunAt(now.Add(time.Hour)))
+	other := newJob(t, domain.JobKind("other"), now, domain.WithJobPriority(30))
+	for _, job := range []*domain.Job{low, high, later, other} {
+		require.NoError(t, queue.Enqueue(ctx, job))
+	}
+	require.ErrorIs(t, queue.Enqueue(ctx, low), port.ErrConflict)
+
+	claimed := claim(t, queue, kind, "a", now)
+	assert.Equal(t, high.ID, claimed.ID)
+	assert.Equal(t, domain.JobStatusRunning, claimed.Status)
+	assert.Equal(t, 1, claimed.Attempts)
+	assert.Equal(t, "a", claimed.LockedBy)
+
+	assert.Equal(t, low.ID, claim(t, queue, kind, "b", now).ID)
+	assert.Nil(t, claim(t, queue, kind, "c", now))
+
+	// A worker that stops heartbeating loses its job.
+	expired := now.Add(2 * time.Minute)
+	reclaimed := claim(t, queue, kind, "c", expired)
+	require.NotNil(t, reclaimed)
+	assert.Contains(t, []uuid.UUID{high.ID, low.ID}, reclaimed.ID)
+	assert.Equal(t, 2, reclaimed.Attempts)
+	assert.NotEmpty(t, reclaimed.LastError)
+}
+
+func jobClaimIfLastAttemptExpired(t *testing.T, queue port.JobQueue) {
+	ctx := context.Background()
+	kind := domain.JobKindFlowRun
+	now := time.Now()
+	job := newJob(t, kind, now, domain.WithJobMaxAttempts(1))
+	require.NoError(t, queue.Enqueue(ctx, job))
+	require.NotNil(t, claim(t, queue, kind, "a", now))
+
+	assert.Nil(t, claim(t, queue, kind, "b", now.Add(2*time.Minute)))
+
+	stored, err := queue.Get(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobStatusDead, stored.Status)
+	assert.NotEmpty(t, stored.LastError)
+	assert.NotNil(t, stored.FinishedAt)
+}
+
+func jobUpdate(t *testing.T, queue port.JobQueue) {
+	ctx := context.Background()
+	kind := domain.JobKindFlowRun
+	now := time.Now()
+	require.NoError(t, queue.Enqueue(ctx, newJob(t, kind, now)))
+	job := claim(t, queue, kind, "a", now)
+
+	job.Extend(now.Add(time.Hour))
+	require.NoError(t, queue.Update(ctx, job))
+	// The extended lease keeps the job from other workers.
+	assert.Nil(t, claim(t, queue, kind, "b", now.Add(2*time.Minute)))
+
+	stale := *job
+	stale.Attempts--
+	stale.Succeed(now)
+	require.ErrorIs(t, queue.Update(ctx, &stale), port.ErrConflict)
+
+	job.Succeed(now)
+	require.NoError(t, queue.Update(ctx, job))
+	require.ErrorIs(t, queue.Update(ctx, job), port.ErrConflict)
+
+	stored, err := queue.Get(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobStatusSucceeded, stored.Status)
+	assert.JSONEq(t, `{"n": 1}`, string(stored.Payload))
+
+	_, err = queue.Get(ctx, uuid.New())
+	require.ErrorIs(t, err, port.ErrNotFound)
+}
+
+func newJob(t *testing.T, kind domain.JobKind, now time.Time, opts ...domain.JobOpt) *domain.Job {
+	t.Helper()
+
+	job, err := domain.NewJob(kind, map[string]int{"n": 1}, now, opts...)
+	require.NoError(t, err)
+	return job
+}
+
+// claim claims a job of kind for worker, leased for a minute.
+func claim(t *testing.T, queue port.JobQueue, kind domain.JobKind, worker string, now time.Time) *domain.Job {
+	t.Helper()
+
+	job, err := queue.Claim(context.Background(), worker, []domain.JobKind{kind}, now, now.Add(time.Minute))
+	require.NoError(t, err)
+	return job
+}
@@ -0,0 +1,35 @@
+// Package repotest is the contract of the core repository ports. The memory
+// and database packages run it against their implementations, so that both
+// behave the same way: what passes against one passes against the other.
+package repotest
+
+import (
+	"flow-run/internal/core/domain"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// NewFlow returns a flow of a single transform step, named name.
+func NewFlow(t *testing.T, accountID uuid.UUID, name string) *domain.Flow {
+	t.Helper()
+
+	return newFlow(t, accountID, "name: "+name+"\nsteps:\n  - {id: a, type: transform, transform: {template: x}}\n")
+}
+
+func newFlow(t *testing.T, accountID uuid.UUID, source string) *domain.Flow {
+	t.Helper()
+
+	flow, err := domain.NewFlow(
+		domain.WithFlowID(uuid.New()),
+		domain.WithFlowAccountID(accountID),
+		domain.WithFlowSource(source, domain.FlowSourceFormatYAML),
+	)
+	require.NoError(t, err)
+	return flow
+}
+
+func get[T any](_ T, err error) error {
+	return err
+}
@@ -0,0 +1,204 @@
+package repotest
+
+import (
+	"context"
+	"flow-run/internal/core/domain"
+	"flow-run/internal/core/port"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunRepository runs the contract of port.FlowRunRepository against the
+// empty repositories newRepo returns, one per subtest.
+func RunRepository(t *testing.T, newRepo func(t *testing.T) port.FlowRunRepository) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repo port.FlowRunRepository)
+	}{
+		{name: "crud", test: runCRUD},
+		{name: "lease", test: runLease},
+		{name: "fail_expired_runs", test: runFailExpiredRuns},
+		{name: "cancel_run", test: runCancelRun},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.test(t, newRepo(t))
+		})
+	}
+}
+
+func runCRUD(t *testing.T, repo port.FlowRunRepository) {
+	ctx := context.Background()
+	flow := NewFlow(t, uuid.New(), "x")
+	run, steps := domain.NewFlowRun(flow, map[string]any{"text": "hi"}, time.Now())
+	require.NoError(t, repo.CreateRun(ctx, run, steps))
+	require.ErrorIs(t, repo.CreateRun(ctx, run, steps), port.ErrConflict)
+
+	// Only what is saved is stored.
+	run.Status = domain.RunStatusSucceeded
+	stored, err := repo.GetRun(ctx, flow.AccountID, run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStatusPending, stored.Status)
+
+	run.Outputs = map[string]any{"a": "x"}
+	require.NoError(t, repo.UpdateRun(ctx, run))
+	steps[0].Status = domain.RunStatusSucceeded
+	steps[0].Output = "x"
+	require.NoError(t, repo.UpdateStepRun(ctx, steps[0]))
+
+	stored, err = repo.GetRun(ctx, flow.AccountID, run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStatusSucceeded, stored.Status)
+	assert.Equal(t, run.Inputs, stored.Inputs)
+	assert.Equal(t, run.Outputs, stored.Outputs)
+	assert.True(t, run.CreatedAt.Equal(stored.CreatedAt))
+
+	storedSteps, err := repo.ListStepRuns(ctx, run.ID)
+	require.NoError(t, err)
+	require.Len(t, storedSteps, 1)
+	assert.Equal(t, domain.RunStatusSucceeded, storedSteps[0].Status)
+	assert.Equal(t, "x", storedSteps[0].Output)
+
+	_, err = repo.GetRun(ctx, uuid.New(), run.ID)
+	require.ErrorIs(t, err, port.ErrNotFound)
+	require.ErrorIs(t, repo.UpdateRun(ctx, &domain.FlowRun{ID: uuid.New()}), port.ErrNotFound)
+	require.ErrorIs(t, repo.UpdateStepRun(ctx, &domain.StepRun{ID: uuid.New()}), port.ErrNotFound)
+}
+
+func runLease(t *testing.T, repo port.FlowRunRepository) {
+	ctx := context.Background()
+	flow := NewFlow(t, uuid.New(), "x")
+	now := time.Now()
+
+	run, steps := domain.NewFlowRun(flow, map[string]any{}, now)
+	run.Start(now)
+	leased := now.Add(time.Minute)
+	run.LeaseExpiresAt = &leased
+	require.NoError(t, repo.CreateRun(ctx, run, steps))
+
+	// Updates keep the newest lease.
+	extended := now.Add(2 * time.Minute)
+	require.NoError(t, repo.ExtendRunLease(ctx, run.ID, extended))
+	require.NoError(t, repo.UpdateRun(ctx, run))
+	stored, err := repo.GetRun(ctx, flow.AccountID, run.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.LeaseExpiresAt)
+	assert.WithinDuration(t, extended, *stored.LeaseExpiresAt, time.Millisecond)
+
+	require.ErrorIs(t, repo.ExtendRunLease(ctx, uuid.New(), extended), port.ErrNotFound)
+}
+
+func runFailExpiredRuns(t *testing.T, repo port.FlowRunRepository) {
+	ctx := context.Background()
+	accountID := uuid.New()
+	flow := newFlow(t, accountID, `
+name: x
+steps:
+  - {id: a, type: transform, transform: {template: x}}
+  - {id: b, type: transform, transform: {template: x}}
+  - {id: c, type: transform, transform: {template: x}}
+`)
+	now := time.Now()
+
+	expired := leasedRun(t, repo, flow, now.Add(-time.Second))
+	steps, err := repo.ListStepRuns(ctx, expired.ID)
+	require.NoError(t, err)
+	steps[0].Status, steps[1].Status = domain.RunStatusSucceeded, domain.RunStatusRunning
+	require.NoError(t, repo.UpdateStepRun(ctx, steps[0]))
+	require.NoError(t, repo.UpdateStepRun(ctx, steps[1]))
+	child, childSteps := domain.NewFlowRun(NewFlow(t, accountID, "child"), map[string]any{}, now,
+		domain.WithFlowRunParentStepRunID(steps[1].ID))
+	child.Start(now)
+	require.NoError(t, repo.CreateRun(ctx, child, childSteps))
+
+	live := leasedRun(t, repo, flow, now.Add(time.Minute))
+	pending, pendingSteps := domain.NewFlowRun(flow, map[string]any{}, now)
+	require.NoError(t, repo.CreateRun(ctx, pending, pendingSteps))
+
+	failed, err := repo.FailExpiredRuns(ctx, now, "interrupted")
+	require.NoError(t, err)
+	assert.Equal(t, 2, failed)
+
+	for _, id := range []uuid.UUID{expired.ID, child.ID} {
+		stored, err := repo.GetRun(ctx, accountID, id)
+		require.NoError(t, err)
+		assert.Equal(t, domain.RunStatusFailed, stored.Status)
+		assert.Equal(t, "interrupted", stored.Error)
+		assert.NotNil(t, stored.FinishedAt)
+	}
+	storedSteps, err := repo.ListStepRuns(ctx, expired.ID)
+	require.NoError(t, err)
+	require.Len(t, storedSteps, 3)
+	assert.Equal(t, domain.RunStatusSucceeded, storedSteps[0].Status)
+	assert.Equal(t, domain.RunStatusFailed, storedSteps[1].Status)
+	assert.Equal(t, "interrupted", storedSteps[1].Error)
+	assert.Equal(t, domain.RunStatusSkipped, storedSteps[2].Status)
+
+	for id, status := range map[uuid.UUID]domain.RunStatus{
+		live.ID:    domain.RunStatusRunning,
+		pending.ID: domain.RunStatusPending,
+	} {
+		stored, err := repo.GetRun(ctx, accountID, id)
+		require.NoError(t, err)
+		assert.Equal(t, status, stored.Status)
+	}
+}
+
+// leasedRun stores a running run of flow leased until until.
+func leasedRun(t *testing.T, repo port.FlowRunRepository, flow *domain.Flow, until time.Time) *domain.FlowRun {
+	t.Helper()
+
+	run, steps := domain.NewFlowRun(flow, map[string]any{}, time.Now())
+	run.Start(time.Now())
+	run.LeaseExpiresAt = &until
+	require.NoError(t, repo.CreateRun(context.Background(), run, steps))
+	return run
+}
+
+func runCancelRun(t *testing.T, repo port.FlowRunRepository) {
+	ctx := context.Background()
+	flow := NewFlow(t, uuid.New(), "x")
+	now := time.Now()
+
+	pending, pendingSteps := domain.NewFlowRun(flow, map[string]any{}, now)
+	require.NoError(t, repo.CreateRun(ctx, pending, pendingSteps))
+	running, runningSteps := domain.NewFlowRun(flow, map[string]any{}, now)
+	running.Start(now)
+	require.NoError(t, repo.CreateRun(ctx, running, runningSteps))
+	finished, finishedSteps := domain.NewFlowRun(flow, map[string]any{}, now)
+	finished.Finish(domain.RunStatusSucceeded, "", now)
+	require.NoError(t, repo.CreateRun(ctx, finished, finishedSteps))
+
+	canceled, err := repo.CancelRun(ctx, flow.AccountID, pending.ID, now)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStatusCanceled, canceled.Status)
+	assert.NotNil(t, canceled.FinishedAt)
+	assert.NotNil(t, canceled.CancelRequestedAt)
+	steps, err := repo.ListStepRuns(ctx, pending.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStatusSkipped, steps[0].Status)
+
+	// A running run keeps running, and its updates keep the request.
+	requested, err := repo.CancelRun(ctx, flow.AccountID, running.ID, now)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStatusRunning, requested.Status)
+	require.NoError(t, repo.UpdateRun(ctx, running))
+	stored, err := repo.GetRun(ctx, flow.AccountID, running.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, stored.CancelRequestedAt)
+
+	unchanged, err := repo.CancelRun(ctx, flow.AccountID, finished.ID, now)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RunStatusSucceeded, unchanged.Status)
+	assert.Nil(t, unchanged.CancelRequestedAt)
+
+	_, err = repo.CancelRun(ctx, uuid.New(), running.ID, now)
+	assert.ErrorIs(t, err, port.ErrNotFound)
+}
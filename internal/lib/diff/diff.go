@@ -0,0 +1,199 @@
+// Package diff computes line-level differences between two texts.
+package diff
+
+import "strings"
+
+type Op string
+
+const (
+	OpEqual  = Op("equal")
+	OpInsert = Op("insert")
+	OpDelete = Op("delete")
+)
+
+// Line is one line of a diff. OldLine and NewLine are 1-based line numbers in
+// the old and new text; the one a line does not appear in is 0.
+type Line struct {
+	Op      Op     `json:"op"`
+	Text    string `json:"text"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+}
+
+// Lines returns a shortest edit script turning a into b. Deletions come
+// before insertions within a changed block. Templates have no size limit, so
+// the script is found with the linear-space form of Myers' algorithm: memory
+// grows with the length of the texts, not with their product.
+func Lines(a, b string) []Line {
+	oldLines, newLines := split(a), split(b)
+	d := newDiffer(oldLines, newLines)
+	d.compare(0, len(d.a), 0, len(d.b))
+
+	lines := make([]Line, 0, max(len(oldLines), len(newLines)))
+	i, j := 0, 0
+	for _, m := range append(d.matches, match{len(oldLines), len(newLines)}) {
+		for ; i < m.old; i++ {
+			lines = append(lines, Line{Op: OpDelete, Text: oldLines[i], OldLine: i + 1})
+		}
+		for ; j < m.new; j++ {
+			lines = append(lines, Line{Op: OpInsert, Text: newLines[j], NewLine: j + 1})
+		}
+		if i < len(oldLines) && j < len(newLines) {
+			lines = append(lines, Line{Op: OpEqual, Text: oldLines[i], OldLine: i + 1, NewLine: j + 1})
+			i++
+			j++
+		}
+	}
+	return lines
+}
+
+// match pairs the indexes of a line common to both texts.
+type match struct {
+	old, new int
+}
+
+// differ finds the lines common to both texts. Lines that only one text
+// has can never be common, so a and b hold the others, numbered so that
+// equal lines compare as equal integers; oldIndex and newIndex map them back
+// to their lines. matches are in the order of both texts.
+type differ struct {
+	a, b               []int
+	oldIndex, newIndex []int
+	matches            []match
+}
+
+func newDiffer(oldLines, newLines []string) *differ {
+	ids := map[string]int{}
+	for _, line := range oldLines {
+		if _, ok := ids[line]; !ok {
+			ids[line] = -1
+		}
+	}
+	common := 0
+	for _, line := range newLines {
+		if id, ok := ids[line]; ok && id < 0 {
+			ids[line] = common
+			common++
+		}
+	}
+
+	d := &differ{}
+	for i, line := range oldLines {
+		if id := ids[line]; id >= 0 {
+			d.a = append(d.a, id)
+			d.oldIndex = append(d.oldIndex, i)
+		}
+	}
+	for j, line := range newLines {
+		if id, ok := ids[line]; ok && id >= 0 {
+			d.b = append(d.b, id)
+			d.newIndex = append(d.newIndex, j)
+		}
+	}
+	return d
+}
+
+// match records that a[i] and b[j] are a common line.
+func (d *differ) match(i, j int) {
+	d.matches = append(d.matches, match{d.oldIndex[i], d.newIndex[j]})
+}
+
+// compare matches a[aLo:aHi] with b[bLo:bHi]: it strips their common prefix
+// and suffix, then splits the rest at a middle snake of a shortest edit
+// script and compares both sides of it.
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.match(aLo, bLo)
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi && bLo < bHi && d.a[aHi-1] == d.b[bHi-1] {
+		aHi--
+		bHi--
+		suffix++
+	}
+
+	// What remains differs at both ends, so it takes at least two edits and
+	// both sides of the snake take fewer.
+	if aLo < aHi && bLo < bHi {
+		x, y, u, v := d.middleSnake(aLo, aHi, bLo, bHi)
+		d.compare(aLo, x, bLo, y)
+		for ; x < u; x, y = x+1, y+1 {
+			d.match(x, y)
+		}
+		d.compare(u, aHi, v, bHi)
+	}
+
+	for i := range suffix {
+		d.match(aHi+i, bHi+i)
+	}
+}
+
+// middleSnake returns the snake from (x, y) to (u, v) in the middle of a
+// shortest edit script of a[aLo:aHi] and b[bLo:bHi]. Paths are searched from
+// both ends at once, keeping only the furthest point reached on each
+// diagonal k = x - y; the backward search runs on the reversed texts.
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (x, y, u, v int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+	limit := (n + m + 1) / 2
+	offset := limit + 1
+	forward := make([]int, 2*limit+3)
+	backward := make([]int, 2*limit+3)
+
+	for edits := 0; edits <= limit; edits++ {
+		for k := -edits; k <= edits; k += 2 {
+			x0, x1 := furthest(forward, offset, k, edits, func(x, y int) bool {
+				return x < n && y < m && d.a[aLo+x] == d.b[bLo+y]
+			})
+			// The backward search reached diagonal delta - k in one edit less.
+			if odd && delta-k >= -(edits-1) && delta-k <= edits-1 && x1+backward[offset+delta-k] >= n {
+				return aLo + x0, bLo + x0 - k, aLo + x1, bLo + x1 - k
+			}
+		}
+		for k := -edits; k <= edits; k += 2 {
+			x0, x1 := furthest(backward, offset, k, edits, func(x, y int) bool {
+				return x < n && y < m && d.a[aHi-1-x] == d.b[bHi-1-y]
+			})
+			if !odd && delta-k >= -edits && delta-k <= edits && x1+forward[offset+delta-k] >= n {
+				return aHi - x1, bHi - x1 + k, aHi - x0, bHi - x0 + k
+			}
+		}
+	}
+	panic("diff: no middle snake")
+}
+
+// furthest extends the furthest path with edits edits on diagonal k from
+// those on its neighbours, then follows equal lines. It returns where the
+// snake of equal lines starts and ends.
+func furthest(reached []int, offset, k, edits int, equal func(x, y int) bool) (start, end int) {
+	x := reached[offset+k-1] + 1
+	if k == -edits || (k != edits && reached[offset+k-1] < reached[offset+k+1]) {
+		x = reached[offset+k+1]
+	}
+	start = x
+	for equal(x, x-k) {
+		x++
+	}
+	reached[offset+k] = x
+	return start, x
+}
+
+// Changed reports whether lines contain anything but equal lines.
+func Changed(lines []Line) bool {
+	for _, line := range lines {
+		if line.Op != OpEqual {
+			return true
+		}
+	}
+	return false
+}
+
+func split(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
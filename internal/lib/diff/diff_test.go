@@ -0,0 +1,114 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLines(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want []Line
+	}{
+		{
+			name: "equal",
+			a:    "one\ntwo",
+			b:    "one\ntwo\n",
+			want: []Line{
+				{Op: OpEqual, Text: "one", OldLine: 1, NewLine: 1},
+				{Op: OpEqual, Text: "two", OldLine: 2, NewLine: 2},
+			},
+		},
+		{
+			name: "changed_line",
+			a:    "Be brief.\nSummarize {{ .text }}\nThanks",
+			b:    "Be brief.\nSummarize {{ .text }} in {{ .language }}\nThanks",
+			want: []Line{
+				{Op: OpEqual, Text: "Be brief.", OldLine: 1, NewLine: 1},
+				{Op: OpDelete, Text: "Summarize {{ .text }}", OldLine: 2},
+				{Op: OpInsert, Text: "Summarize {{ .text }} in {{ .language }}", NewLine: 2},
+				{Op: OpEqual, Text: "Thanks", OldLine: 3, NewLine: 3},
+			},
+		},
+		{
+			// The example of Myers' paper: 5 edits, the fewest.
+			name: "shortest",
+			a:    "a\nb\nc\na\nb\nb\na",
+			b:    "c\nb\na\nb\na\nc",
+			want: []Line{
+				{Op: OpDelete, Text: "a", OldLine: 1},
+				{Op: OpInsert, Text: "c", NewLine: 1},
+				{Op: OpEqual, Text: "b", OldLine: 2, NewLine: 2},
+				{Op: OpDelete, Text: "c", OldLine: 3},
+				{Op: OpEqual, Text: "a", OldLine: 4, NewLine: 3},
+				{Op: OpEqual, Text: "b", OldLine: 5, NewLine: 4},
+				{Op: OpDelete, Text: "b", OldLine: 6},
+				{Op: OpEqual, Text: "a", OldLine: 7, NewLine: 5},
+				{Op: OpInsert, Text: "c", NewLine: 6},
+			},
+		},
+		{
+			name: "from_empty",
+			a:    "",
+			b:    "a\nb",
+			want: []Line{
+				{Op: OpInsert, Text: "a", NewLine: 1},
+				{Op: OpInsert, Text: "b", NewLine: 2},
+			},
+		},
+		{
+			name: "to_empty",
+			a:    "a",
+			b:    "",
+			want: []Line{{Op: OpDelete, Text: "a", OldLine: 1}},
+		},
+		{
+			name: "both_empty",
+			want: []Line{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			lines := Lines(tt.a, tt.b)
+			assert.Equal(t, tt.want, lines)
+			assert.Equal(t, tt.a != tt.b && tt.name != "equal", Changed(lines))
+		})
+	}
+}
+
+func TestLinesIfLong(t *testing.T) {
+	t.Parallel()
+
+	var old, edited, other strings.Builder
+	for i := range 20000 {
+		fmt.Fprintf(&old, "line %d\n", i)
+		fmt.Fprintf(&other, "other %d\n", i)
+		if i == 10000 {
+			fmt.Fprintf(&edited, "changed %d\n", i)
+		} else {
+			fmt.Fprintf(&edited, "line %d\n", i)
+		}
+	}
+
+	lines := Lines(old.String(), edited.String())
+	require.Len(t, lines, 20001)
+	assert.Equal(t, Line{Op: OpDelete, Text: "line 10000", OldLine: 10001}, lines[10000])
+	assert.Equal(t, Line{Op: OpInsert, Text: "changed 10000", NewLine: 10001}, lines[10001])
+	assert.Equal(t, Line{Op: OpEqual, Text: "line 19999", OldLine: 20000, NewLine: 20000}, lines[20000])
+
+	lines = Lines(old.String(), other.String())
+	require.Len(t, lines, 40000)
+	assert.Equal(t, OpDelete, lines[19999].Op)
+	assert.Equal(t, OpInsert, lines[20000].Op)
+}
@@ -0,0 +1,202 @@
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flow-run/internal/lib/validator"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// dataKeySize selects AES-256 for both master and data keys.
+const dataKeySize = 32
+
+var (
+	ErrInvalidMasterKey  = errors.New("invalid master key")
+	ErrUnknownKeyVersion = errors.New("unknown master key version")
+	ErrDecrypt           = errors.New("decryption failed")
+)
+
+// KeyringConfig lists master keys as comma-separated "version:base64" pairs,
+// for example "2:<new key>,1:<old key>". The first entry encrypts new data;
+// the others are kept only to decrypt rows that have not been rotated yet.
+type KeyringConfig struct {
+	MasterKeys string `validate:"required"`
+}
+
+// Sealed is a secret encrypted with its own data key. DataKey is the data key
+// wrapped by the master key of KeyVersion, so rotating the master key only
+// rewraps DataKey and leaves Ciphertext untouched.
+type Sealed struct {
+	KeyVersion int
+	DataKey    []byte
+	Ciphertext []byte
+}
+
+// IsZero reports whether s holds no secret. GORM allocates embedded pointer
+// structs even when all their columns are NULL.
+func (s *Sealed) IsZero() bool {
+	return s == nil || len(s.Ciphertext) == 0
+}
+
+// Keyring performs AES-GCM envelope encryption with a set of versioned
+// master keys.
+type Keyring struct {
+	active int
+	keys   map[int]cipher.AEAD
+}
+
+func NewKeyring(config *KeyringConfig) (*Keyring, error) {
+	validatedConfig, err := validator.Struct(config)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &Keyring{keys: map[int]cipher.AEAD{}}
+	for i, entry := range strings.Split(validatedConfig.MasterKeys, ",") {
+		version, aead, err := parseMasterKey(strings.TrimSpace(entry))
+		if err != nil {
+			return nil, fmt.Errorf("master key entry %d: %w", i, err)
+		}
+		if _, ok := k.keys[version]; ok {
+			return nil, fmt.Errorf("%w: duplicate version %d", ErrInvalidMasterKey, version)
+		}
+		if i == 0 {
+			k.active = version
+		}
+		k.keys[version] = aead
+	}
+	return k, nil
+}
+
+// ActiveVersion is the master key version used by Seal and Rewrap.
+func (k *Keyring) ActiveVersion() int {
+	return k.active
+}
+
+// Seal encrypts plaintext under a fresh data key. aad is bound to both the
+// ciphertext and the wrapped data key, so a sealed value copied to another
+// row (with different aad) fails to open.
+func (k *Keyring) Seal(plaintext, aad []byte) (*Sealed, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := seal(dataAEAD, plaintext, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := seal(k.keys[k.active], dataKey, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sealed{KeyVersion: k.active, DataKey: wrapped, Ciphertext: ciphertext}, nil
+}
+
+func (k *Keyring) Open(s *Sealed, aad []byte) ([]byte, error) {
+	dataKey, err := k.unwrap(s, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return open(dataAEAD, s.Ciphertext, aad)
+}
+
+// Rewrap re-encrypts the data key of s with the active master key. It
+// returns s unchanged when s already uses the active version.
+func (k *Keyring) Rewrap(s *Sealed, aad []byte) (*Sealed, error) {
+	if s.KeyVersion == k.active {
+		return s, nil
+	}
+
+	dataKey, err := k.unwrap(s, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := seal(k.keys[k.active], dataKey, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sealed{KeyVersion: k.active, DataKey: wrapped, Ciphertext: s.Ciphertext}, nil
+}
+
+func (k *Keyring) unwrap(s *Sealed, aad []byte) ([]byte, error) {
+	master, ok := k.keys[s.KeyVersion]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownKeyVersion, s.KeyVersion)
+	}
+	return open(master, s.DataKey, aad)
+}
+
+func parseMasterKey(entry string) (int, cipher.AEAD, error) {
+	rawVersion, encodedKey, ok := strings.Cut(entry, ":")
+	if !ok {
+		return 0, nil, fmt.Errorf(`%w: expected "version:base64 key"`, ErrInvalidMasterKey)
+	}
+
+	version, err := strconv.Atoi(rawVersion)
+	if err != nil || version < 1 {
+		return 0, nil, fmt.Errorf("%w: version %q must be a positive integer", ErrInvalidMasterKey, rawVersion)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: version %d: %w", ErrInvalidMasterKey, version, err)
+	}
+	if len(key) != dataKeySize {
+		return 0, nil, fmt.Errorf("%w: version %d: key must be %d bytes, got %d",
+			ErrInvalidMasterKey, version, dataKeySize, len(key))
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return 0, nil, err
+	}
+	return version, aead, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal prefixes the output with a random nonce.
+func seal(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(aead cipher.AEAD, data, aad []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
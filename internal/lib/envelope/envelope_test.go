@@ -0,0 +1,138 @@
+package envelope
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealAndOpen(t *testing.T) {
+	t.Parallel()
+
+	keyring := newTestKeyring(t, "1:"+newTestKey(t))
+
+	sealed, err := keyring.Seal([]byte("sk-secret"), []byte("row-1"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, sealed.KeyVersion)
+	assert.NotContains(t, string(sealed.Ciphertext), "sk-secret")
+	assert.False(t, sealed.IsZero())
+
+	plaintext, err := keyring.Open(sealed, []byte("row-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "sk-secret", string(plaintext))
+}
+
+func TestSealUsesFreshDataKey(t *testing.T) {
+	t.Parallel()
+
+	keyring := newTestKeyring(t, "1:"+newTestKey(t))
+
+	first, err := keyring.Seal([]byte("sk-secret"), nil)
+	require.NoError(t, err)
+	second, err := keyring.Seal([]byte("sk-secret"), nil)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first.DataKey, second.DataKey)
+	assert.NotEqual(t, first.Ciphertext, second.Ciphertext)
+}
+
+func TestOpenIfTampered(t *testing.T) {
+	t.Parallel()
+
+	keyring := newTestKeyring(t, "1:"+newTestKey(t))
+
+	sealed, err := keyring.Seal([]byte("sk-secret"), []byte("row-1"))
+	require.NoError(t, err)
+
+	_, err = keyring.Open(sealed, []byte("row-2"))
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	corrupted := *sealed
+	corrupted.Ciphertext = append([]byte{}, sealed.Ciphertext...)
+	corrupted.Ciphertext[len(corrupted.Ciphertext)-1] ^= 0xff
+	_, err = keyring.Open(&corrupted, []byte("row-1"))
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	_, err = keyring.Open(&Sealed{KeyVersion: 1, DataKey: []byte("short")}, []byte("row-1"))
+	require.ErrorIs(t, err, ErrDecrypt)
+}
+
+func TestRewrap(t *testing.T) {
+	t.Parallel()
+
+	oldKey := newTestKey(t)
+	oldKeyring := newTestKeyring(t, "1:"+oldKey)
+
+	sealed, err := oldKeyring.Seal([]byte("sk-secret"), []byte("row-1"))
+	require.NoError(t, err)
+
+	rotated := newTestKeyring(t, "2:"+newTestKey(t)+",1:"+oldKey)
+	assert.Equal(t, 2, rotated.ActiveVersion())
+
+	rewrapped, err := rotated.Rewrap(sealed, []byte("row-1"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, rewrapped.KeyVersion)
+	assert.Equal(t, sealed.Ciphertext, rewrapped.Ciphertext)
+
+	plaintext, err := rotated.Open(rewrapped, []byte("row-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "sk-secret", string(plaintext))
+
+	_, err = oldKeyring.Open(rewrapped, []byte("row-1"))
+	require.ErrorIs(t, err, ErrUnknownKeyVersion)
+
+	unchanged, err := rotated.Rewrap(rewrapped, []byte("row-1"))
+	require.NoError(t, err)
+	assert.Same(t, rewrapped, unchanged)
+}
+
+func TestNewKeyringIfInvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	key := newTestKey(t)
+
+	tests := []struct {
+		name       string
+		masterKeys string
+	}{
+		{name: "missing_version", masterKeys: key},
+		{name: "zero_version", masterKeys: "0:" + key},
+		{name: "not_base64", masterKeys: "1:not base64!"},
+		{name: "short_key", masterKeys: "1:" + base64.StdEncoding.EncodeToString([]byte("short"))},
+		{name: "duplicate_version", masterKeys: "1:" + key + ",1:" + key},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			keyring, err := NewKeyring(&KeyringConfig{MasterKeys: tt.masterKeys})
+
+			require.ErrorIs(t, err, ErrInvalidMasterKey)
+			assert.Nil(t, keyring)
+		})
+	}
+
+	_, err := NewKeyring(&KeyringConfig{})
+	require.Error(t, err)
+}
+
+func newTestKey(t *testing.T) string {
+	t.Helper()
+
+	key := make([]byte, dataKeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func newTestKeyring(t *testing.T, masterKeys string) *Keyring {
+	t.Helper()
+
+	keyring, err := NewKeyring(&KeyringConfig{MasterKeys: masterKeys})
+	require.NoError(t, err)
+	return keyring
+}
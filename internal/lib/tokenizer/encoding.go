@@ -0,0 +1,119 @@
+// Package tokenizer counts tokens offline with byte-level BPE encodings in
+// the tiktoken format, the encodings of OpenAI models. Other providers use
+// tokenizers of their own, which these approximate.
+package tokenizer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+var ErrInvalidTable = errors.New("invalid BPE table")
+
+// Encoding maps byte sequences to token ranks. Lower ranks were merged
+// earlier when the encoding was trained, and are merged first when encoding.
+type Encoding struct {
+	name  string
+	ranks map[string]int
+	split func(string) []string
+}
+
+// Parse reads a table in the tiktoken format: one token per line, as its
+// base64 bytes and its rank separated by a space. Every single byte must be
+// a token, so that any text can be encoded. Text is pre-tokenized with the
+// pattern of the encoding called name, or that of cl100k_base when name is
+// not a known encoding.
+func Parse(name string, r io.Reader) (*Encoding, error) {
+	ranks := map[string]int{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := bytes.Fields(scanner.Bytes())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%w %s: line %d: expected token and rank", ErrInvalidTable, name, line)
+		}
+		token, err := base64.StdEncoding.DecodeString(string(fields[0]))
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: line %d: %w", ErrInvalidTable, name, line, err)
+		}
+		rank, err := strconv.Atoi(string(fields[1]))
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: line %d: %w", ErrInvalidTable, name, line, err)
+		}
+		ranks[string(token)] = rank
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for b := range 256 {
+		if _, ok := ranks[string([]byte{byte(b)})]; !ok {
+			return nil, fmt.Errorf("%w %s: byte %#x is not a token", ErrInvalidTable, name, b)
+		}
+	}
+	return &Encoding{name: name, ranks: ranks, split: splitFor(name)}, nil
+}
+
+func (e *Encoding) Name() string {
+	return e.name
+}
+
+// Encode returns the ranks of the tokens of text.
+func (e *Encoding) Encode(text string) []int {
+	var tokens []int
+	for _, piece := range e.split(text) {
+		if rank, ok := e.ranks[piece]; ok {
+			tokens = append(tokens, rank)
+			continue
+		}
+		for _, part := range e.merge(piece) {
+			tokens = append(tokens, e.ranks[part])
+		}
+	}
+	return tokens
+}
+
+// Count returns the number of tokens of text.
+func (e *Encoding) Count(text string) int {
+	n := 0
+	for _, piece := range e.split(text) {
+		if _, ok := e.ranks[piece]; ok {
+			n++
+			continue
+		}
+		n += len(e.merge(piece))
+	}
+	return n
+}
+
+// merge splits piece into bytes and merges the adjacent pair of lowest rank
+// until no pair is a token.
+func (e *Encoding) merge(piece string) []string {
+	parts := make([]string, 0, len(piece))
+	for i := range len(piece) {
+		parts = append(parts, piece[i:i+1])
+	}
+
+	for len(parts) > 1 {
+		best, bestRank := -1, 0
+		for i := range len(parts) - 1 {
+			rank, ok := e.ranks[parts[i]+parts[i+1]]
+			if ok && (best < 0 || rank < bestRank) {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		parts[best] += parts[best+1]
+		parts = append(parts[:best+1], parts[best+2:]...)
+	}
+	return parts
+}
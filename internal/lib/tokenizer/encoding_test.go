@@ -0,0 +1,194 @@
+package tokenizer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "words", text: "Hello world", want: []string{"Hello", " world"}},
+		{name: "contraction", text: "I'm here, they'RE", want: []string{"I", "'m", " here", ",", " they", "'RE"}},
+		{name: "numbers", text: "12345 6", want: []string{"123", "45", " ", "6"}},
+		{name: "spaces", text: "a  b   ", want: []string{"a", " ", " b", "   "}},
+		{name: "punctuation", text: "hi!!\n\nok", want: []string{"hi", "!!\n\n", "ok"}},
+		{name: "newlines", text: "x\n  y", want: []string{"x", "\n", " ", " y"}},
+		{name: "prefixed_word", text: "(héllo)", want: []string{"(héllo", ")"}},
+		{name: "invalid_utf8", text: "a\xffb", want: []string{"a", "\xffb"}},
+		{name: "empty", text: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, splitCL100K(tt.text))
+		})
+	}
+}
+
+func TestSplitO200K(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "words", text: "Hello world", want: []string{"Hello", " world"}},
+		{name: "contraction", text: "I'm here, they'RE", want: []string{"I'm", " here", ",", " they'RE"}},
+		{name: "camel_case", text: "parseHTTPRequest", want: []string{"parse", "HTTPRequest"}},
+		{name: "upper_case", text: "NASA rocks", want: []string{"NASA", " rocks"}},
+		{name: "title_case", text: "HelloWorld", want: []string{"Hello", "World"}},
+		{name: "numbers", text: "12345 6", want: []string{"123", "45", " ", "6"}},
+		{name: "path", text: "a/b//\n", want: []string{"a", "/b", "//\n"}},
+		{name: "prefixed_word", text: "(Héllo)", want: []string{"(Héllo", ")"}},
+		{name: "newlines", text: "x\n  y", want: []string{"x", "\n", " ", " y"}},
+		{name: "empty", text: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, splitO200K(tt.text))
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+
+	// Every byte is a token ranked by its value, then "ll", "he", "hell",
+	// " w" and " world" are merged in that order.
+	e, err := Parse("test", strings.NewReader(testTable("ll", "he", "hell", " w", " world")))
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{258, 'o', 260}, e.Encode("hello world"))
+	assert.Equal(t, 3, e.Count("hello world"))
+	assert.Empty(t, e.Encode(""))
+	assert.Equal(t, []int{259, 'a', 'y'}, e.Encode(" way"))
+	assert.Equal(t, 2, e.Count("!?"))
+}
+
+func TestParseIfInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		table string
+	}{
+		{name: "missing_byte", table: "YQ== 0\n"},
+		{name: "missing_rank", table: testTable() + "YQ==\n"},
+		{name: "invalid_base64", table: testTable() + "!!! 300\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Parse("test", strings.NewReader(tt.table))
+			require.ErrorIs(t, err, ErrInvalidTable)
+		})
+	}
+}
+
+func TestForModel(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, O200KBase, ForModel("gpt-4o-mini"))
+	assert.Equal(t, O200KBase, ForModel("openai/o3-mini"))
+	assert.Equal(t, CL100KBase, ForModel("gpt-4-turbo"))
+	assert.Equal(t, CL100KBase, ForModel("anthropic/claude-sonnet-4"))
+}
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{CL100KBase, O200KBase} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			e, err := Get(name)
+			require.NoError(t, err, "the table of %s must be committed to tables/", name)
+			assert.Equal(t, name, e.Name())
+		})
+	}
+}
+
+// TestEncodeTables checks the embedded tables against the token ranks that
+// tiktoken gives for the same text.
+func TestEncodeTables(t *testing.T) {
+	t.Parallel()
+
+	const sentence = "We know what we are, but know not what we may be."
+	tests := []struct {
+		name     string
+		encoding string
+		text     string
+		want     []int
+	}{
+		{name: "cl100k_words", encoding: CL100KBase, text: "hello world", want: []int{15339, 1917}},
+		{name: "cl100k_spaces", encoding: CL100KBase, text: "hello   world", want: []int{15339, 256, 1917}},
+		{
+			name: "cl100k_merges", encoding: CL100KBase, text: "supercalifragilistic",
+			want: []int{13066, 3035, 278, 333, 4193, 321, 4633},
+		},
+		{
+			name: "cl100k_sentence", encoding: CL100KBase, text: sentence,
+			want: []int{1687, 1440, 1148, 584, 527, 11, 719, 1440, 539, 1148, 584, 1253, 387, 13},
+		},
+		{name: "o200k_words", encoding: O200KBase, text: "hello world", want: []int{24912, 2375}},
+		{name: "o200k_spaces", encoding: O200KBase, text: "hello   world", want: []int{24912, 256, 2375}},
+		{
+			name: "o200k_merges", encoding: O200KBase, text: "supercalifragilistic",
+			want: []int{17789, 5842, 366, 17764, 311, 6207},
+		},
+		{
+			name: "o200k_sentence", encoding: O200KBase, text: sentence,
+			want: []int{2167, 1761, 1412, 581, 553, 11, 889, 1761, 625, 1412, 581, 1340, 413, 13},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e, err := Get(tt.encoding)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, e.Encode(tt.text))
+			assert.Equal(t, len(tt.want), e.Count(tt.text))
+		})
+	}
+}
+
+func TestGetIfUnavailable(t *testing.T) {
+	t.Parallel()
+
+	_, err := Get("p50k_base")
+	require.ErrorIs(t, err, ErrUnavailable)
+}
+
+// testTable returns a table of every single byte, ranked by value, followed
+// by merges ranked from 256 on.
+func testTable(merges ...string) string {
+	var b strings.Builder
+	for i := range 256 {
+		fmt.Fprintf(&b, "%s %d\n", base64.StdEncoding.EncodeToString([]byte{byte(i)}), i)
+	}
+	for i, merge := range merges {
+		fmt.Fprintf(&b, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(merge)), 256+i)
+	}
+	return b.String()
+}
@@ -0,0 +1,231 @@
+package tokenizer
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// splitters are the pre-tokenizers of the encodings whose pattern differs
+// from that of cl100k_base.
+var splitters = map[string]func(string) []string{
+	O200KBase: splitO200K,
+}
+
+// splitFor returns the pre-tokenizer of the encoding called name.
+func splitFor(name string) func(string) []string {
+	if split, ok := splitters[name]; ok {
+		return split
+	}
+	return splitCL100K
+}
+
+// splitCL100K cuts text into the pieces BPE merges within, following the
+// pattern of cl100k_base:
+//
+//	(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}|
+//	 ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+(?!\S)|\s+
+//
+// Go's regexp has no lookahead, so the alternatives are tried by hand, in
+// order, at each position.
+func splitCL100K(text string) []string {
+	var pieces []string
+	for len(text) > 0 {
+		n := contraction(text)
+		if n == 0 {
+			n = word(text)
+		}
+		if n == 0 {
+			n = number(text)
+		}
+		if n == 0 {
+			n = punctuation(text, isNewline)
+		}
+		if n == 0 {
+			n = whitespace(text)
+		}
+		pieces = append(pieces, text[:n])
+		text = text[n:]
+	}
+	return pieces
+}
+
+// splitO200K cuts text following the pattern of o200k_base, which keeps
+// contractions with their word and splits words at case changes:
+//
+//	[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+
+//	(?i:'s|'t|'re|'ve|'m|'ll|'d)?|
+//	[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*
+//	(?i:'s|'t|'re|'ve|'m|'ll|'d)?|
+//	\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n/]*|\s*[\r\n]+|\s+(?!\S)|\s+
+func splitO200K(text string) []string {
+	var pieces []string
+	for len(text) > 0 {
+		n := casedWord(text, lowerWord)
+		if n == 0 {
+			n = casedWord(text, upperWord)
+		}
+		if n == 0 {
+			n = number(text)
+		}
+		if n == 0 {
+			n = punctuation(text, isNewlineOrSlash)
+		}
+		if n == 0 {
+			n = whitespace(text)
+		}
+		pieces = append(pieces, text[:n])
+		text = text[n:]
+	}
+	return pieces
+}
+
+// contraction matches (?i:'s|'t|'re|'ve|'m|'ll|'d).
+func contraction(text string) int {
+	if !strings.HasPrefix(text, "'") {
+		return 0
+	}
+	for _, suffix := range []string{"s", "t", "re", "ve", "m", "ll", "d"} {
+		if len(text) > len(suffix) && strings.EqualFold(text[1:1+len(suffix)], suffix) {
+			return 1 + len(suffix)
+		}
+	}
+	return 0
+}
+
+// word matches [^\r\n\p{L}\p{N}]?\p{L}+.
+func word(text string) int {
+	r, size := utf8.DecodeRuneInString(text)
+	start := 0
+	if !unicode.IsLetter(r) {
+		if r == '\r' || r == '\n' || unicode.IsNumber(r) {
+			return 0
+		}
+		start = size
+	}
+	n := start + span(text[start:], unicode.IsLetter, -1)
+	if n == start {
+		return 0
+	}
+	return n
+}
+
+// number matches \p{N}{1,3}.
+func number(text string) int {
+	return span(text, unicode.IsNumber, 3)
+}
+
+// punctuation matches ` ?[^\s\p{L}\p{N}]+` followed by any number of
+// runes matching trailing.
+func punctuation(text string, trailing func(rune) bool) int {
+	start := 0
+	if strings.HasPrefix(text, " ") {
+		start = 1
+	}
+	n := start + span(text[start:], isSymbol, -1)
+	if n == start {
+		return 0
+	}
+	return n + span(text[n:], trailing, -1)
+}
+
+// casedWord matches [^\r\n\p{L}\p{N}]? followed by body and an optional
+// contraction, preferring a match with the prefix as the regexp does.
+func casedWord(text string, body func(string) int) int {
+	n := 0
+	r, size := utf8.DecodeRuneInString(text)
+	if !unicode.IsLetter(r) && !unicode.IsNumber(r) && !isNewline(r) {
+		n = body(text[size:])
+		if n > 0 {
+			n += size
+		}
+	}
+	if n == 0 {
+		n = body(text)
+	}
+	if n == 0 {
+		return 0
+	}
+	return n + contraction(text[n:])
+}
+
+// lowerWord matches [\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+.
+// The upper run is given back a rune at a time until a lower rune follows.
+func lowerWord(text string) int {
+	upper := span(text, isUpper, -1)
+	for end := upper; ; {
+		if n := span(text[end:], isLower, -1); n > 0 {
+			return end + n
+		}
+		if end == 0 {
+			return 0
+		}
+		_, size := utf8.DecodeLastRuneInString(text[:end])
+		end -= size
+	}
+}
+
+// upperWord matches [\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*.
+func upperWord(text string) int {
+	n := span(text, isUpper, -1)
+	if n == 0 {
+		return 0
+	}
+	return n + span(text[n:], isLower, -1)
+}
+
+// whitespace matches \s*[\r\n]+|\s+(?!\S)|\s+, i.e. a run of whitespace cut
+// after its last newline, else before its last character when more text
+// follows, so that the last space joins the next word.
+func whitespace(text string) int {
+	n := span(text, unicode.IsSpace, -1)
+	if end := strings.LastIndexAny(text[:n], "\r\n"); end >= 0 {
+		return end + 1
+	}
+	if n < len(text) && n > 1 {
+		_, last := utf8.DecodeLastRuneInString(text[:n])
+		return n - last
+	}
+	if n == 0 {
+		// Unreachable: every character is a letter, number, symbol or
+		// space. Guard against looping forever regardless.
+		_, size := utf8.DecodeRuneInString(text)
+		return size
+	}
+	return n
+}
+
+// span returns the length in bytes of the longest prefix of text of at most
+// limit runes matching f, or of any number of runes when limit is negative.
+func span(text string, f func(rune) bool, limit int) int {
+	n := 0
+	for n < len(text) && limit != 0 {
+		r, size := utf8.DecodeRuneInString(text[n:])
+		if !f(r) {
+			break
+		}
+		n += size
+		limit--
+	}
+	return n
+}
+
+func isSymbol(r rune) bool {
+	return !unicode.IsSpace(r) && !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+func isNewline(r rune) bool {
+	return r == '\r' || r == '\n'
+}
+
+func isNewlineOrSlash(r rune) bool {
+	return isNewline(r) || r == '/'
+}
+
+func isUpper(r rune) bool {
+	return unicode.In(r, unicode.Lu, unicode.Lt, unicode.Lm, unicode.Lo, unicode.M)
+}
+
+func isLower(r rune) bool {
+	return unicode.In(r, unicode.Ll, unicode.Lm, unicode.Lo, unicode.M)
+}
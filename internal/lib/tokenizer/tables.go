@@ -0,0 +1,70 @@
+package tokenizer
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+)
+
+const (
+	CL100KBase = "cl100k_base"
+	O200KBase  = "o200k_base"
+)
+
+var ErrUnavailable = errors.New("encoding not available")
+
+//go:embed tables
+var tables embed.FS
+
+var (
+	mu        sync.Mutex
+	encodings = map[string]*Encoding{}
+)
+
+// o200kPrefixes are the model name prefixes of models encoded with
+// o200k_base. Other models use cl100k_base.
+var o200kPrefixes = []string{"gpt-4o", "gpt-4.1", "gpt-4.5", "gpt-5", "chatgpt-4o", "o1", "o3", "o4"}
+
+// ForModel returns the name of the encoding of model. Provider prefixes such
+// as OpenRouter's "openai/" are ignored. Models of other vendors get
+// cl100k_base, which approximates their tokenizers.
+func ForModel(model string) string {
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		model = model[i+1:]
+	}
+	for _, prefix := range o200kPrefixes {
+		if strings.HasPrefix(model, prefix) {
+			return O200KBase
+		}
+	}
+	return CL100KBase
+}
+
+// Get returns the encoding called name, parsing its table the first time. It
+// returns ErrUnavailable when no table of that name is embedded.
+func Get(name string) (*Encoding, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if e, ok := encodings[name]; ok {
+		return e, nil
+	}
+	f, err := tables.Open("tables/" + name + ".tiktoken")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	e, err := Parse(name, f)
+	if err != nil {
+		return nil, err
+	}
+	encodings[name] = e
+	return e, nil
+}
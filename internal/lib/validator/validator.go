@@ -1,8 +1,53 @@
 package validator
 
-import "github.com/go-playground/validator/v10"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 
-var validate = validator.New(validator.WithRequiredStructEnabled())
+	"github.com/go-playground/validator/v10"
+)
+
+var validate = newValidate()
+
+type FieldError struct {
+	Field   string
+	Tag     string
+	Param   string
+	Message string
+}
+
+// Error reports validation failures found by checks that struct tags cannot
+// express. FieldErrors treats it like a tag failure.
+type Error struct {
+	Fields []FieldError
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		messages = append(messages, fmt.Sprintf("%s failed on the '%s' check: %s", f.Field, f.Tag, f.Message))
+	}
+	return strings.Join(messages, "\n")
+}
+
+// NewFieldError returns an *Error for a single field.
+func NewFieldError(field, tag, message string) error {
+	return &Error{Fields: []FieldError{{Field: field, Tag: tag, Message: message}}}
+}
+
+func newValidate() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
 
 func Struct[T any](s T) (T, error) {
 	if err := validate.Struct(s); err != nil {
@@ -11,3 +56,37 @@ func Struct[T any](s T) (T, error) {
 	}
 	return s, nil
 }
+
+// FieldErrors extracts per-field failures from an error returned by Struct.
+// The second result is false when err is not a validation error.
+func FieldErrors(err error) ([]FieldError, bool) {
+	var fieldErr *Error
+	if errors.As(err, &fieldErr) {
+		return fieldErr.Fields, true
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil, false
+	}
+
+	fields := make([]FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields = append(fields, FieldError{
+			Field: fieldPath(fe.Namespace()),
+			Tag:   fe.Tag(),
+			Param: fe.Param(),
+		})
+	}
+	return fields, true
+}
+
+// fieldPath drops the root struct name from a namespace such as
+// "FlowDefinition.steps[0].id", leaving the path a client sent.
+func fieldPath(namespace string) string {
+	_, path, ok := strings.Cut(namespace, ".")
+	if !ok {
+		return namespace
+	}
+	return path
+}
@@ -1,16 +1,147 @@
 package flowrunclient
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"flow-run/pkg/flowrunclient/model"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	providerEndpoint = "/v1/provider"
+	modelEndpoint    = "/v1/model"
+	flowEndpoint     = "/v1/flow"
+	promptEndpoint   = "/v1/prompt"
+	suiteEndpoint    = "/v1/test-suite"
+	datasetEndpoint  = "/v1/dataset"
+	usageEndpoint    = "/v1/usage"
+	costEndpoint     = "/v1/cost"
+	budgetEndpoint   = "/v1/budget"
+	runEndpoint      = "/v1/run"
+)
+
+const (
+	// WaitRun polls a run after waitRunBackoff, doubling the wait after
+	// every poll up to maxWaitRunBackoff.
+	waitRunBackoff    = 100 * time.Millisecond
+	maxWaitRunBackoff = 5 * time.Second
 )
 
 type FlowRunClient interface {
 	GetHealth(ctx context.Context) (*model.HealthResponse, error)
+
+	CreateProvider(ctx context.Context, req model.CreateProviderRequest) (*model.ProviderResponse, error)
+	GetProvider(ctx context.Context, accountID, id uuid.UUID) (*model.ProviderResponse, error)
+	ListProviders(ctx context.Context, accountID uuid.UUID) (*model.ProvidersResponse, error)
+	UpdateProvider(
+		ctx context.Context, accountID, id uuid.UUID, req model.UpdateProviderRequest,
+	) (*model.ProviderResponse, error)
+	DeleteProvider(ctx context.Context, accountID, id uuid.UUID) error
+
+	CreateModel(ctx context.Context, req model.CreateModelRequest) (*model.ModelResponse, error)
+	GetModel(ctx context.Context, accountID, id uuid.UUID) (*model.ModelResponse, error)
+	ListModels(ctx context.Context, accountID uuid.UUID) (*model.ModelsResponse, error)
+	UpdateModel(ctx context.Context, accountID, id uuid.UUID, req model.UpdateModelRequest) (*model.ModelResponse, error)
+	DeleteModel(ctx context.Context, accountID, id uuid.UUID) error
+	ListModelPrices(ctx context.Context, accountID, id uuid.UUID) (*model.ModelPricesResponse, error)
+	AddModelPrice(
+		ctx context.Context, accountID, id uuid.UUID, req model.AddModelPriceRequest,
+	) (*model.ModelPriceResponse, error)
+
+	CreateFlow(ctx context.Context, req model.CreateFlowRequest) (*model.FlowResponse, error)
+	GetFlow(ctx context.Context, accountID, id uuid.UUID) (*model.FlowResponse, error)
+	ListFlows(ctx context.Context, accountID uuid.UUID) (*model.FlowsResponse, error)
+	UpdateFlow(ctx context.Context, accountID, id uuid.UUID, req model.UpdateFlowRequest) (*model.FlowResponse, error)
+	DeleteFlow(ctx context.Context, accountID, id uuid.UUID) error
+	EstimateFlow(
+		ctx context.Context, accountID, id uuid.UUID, req model.EstimateFlowRequest,
+	) (*model.FlowEstimateResponse, error)
+
+	// StartRun queues a run of the flow and returns it while it is pending.
+	StartRun(ctx context.Context, accountID, flowID uuid.UUID, req model.RunFlowRequest) (*model.FlowRunResponse, error)
+	GetRun(ctx context.Context, accountID, id uuid.UUID) (*model.FlowRunResponse, error)
+	// WaitRun polls the run, backing off between polls, until it has
+	// finished or ctx is done.
+	WaitRun(ctx context.Context, accountID, id uuid.UUID) (*model.FlowRunResponse, error)
+	// CancelRun cancels the run. A running run may still be running when it
+	// returns; WaitRun waits until it has stopped.
+	CancelRun(ctx context.Context, accountID, id uuid.UUID) (*model.FlowRunResponse, error)
+
+	CreatePrompt(ctx context.Context, req model.CreatePromptRequest) (*model.PromptResponse, error)
+	GetPrompt(ctx context.Context, accountID, id uuid.UUID) (*model.PromptResponse, error)
+	ListPrompts(ctx context.Context, accountID uuid.UUID) (*model.PromptsResponse, error)
+	UpdatePrompt(
+		ctx context.Context, accountID, id uuid.UUID, req model.UpdatePromptRequest,
+	) (*model.PromptResponse, error)
+	DeletePrompt(ctx context.Context, accountID, id uuid.UUID) error
+	ListPromptRevisions(ctx context.Context, accountID, id uuid.UUID) (*model.PromptRevisionsResponse, error)
+	GetPromptRevision(ctx context.Context, accountID, id uuid.UUID, revision int) (*model.PromptRevisionResponse, error)
+	DiffPromptRevisions(ctx context.Context, accountID, id uuid.UUID, from, to int) (*model.PromptDiffResponse, error)
+	ListPromptLabels(ctx context.Context, accountID, id uuid.UUID) (*model.PromptLabelsResponse, error)
+	SetPromptLabel(
+		ctx context.Context, accountID, id uuid.UUID, label string, req model.SetPromptLabelRequest,
+	) (*model.PromptLabelResponse, error)
+
+	CreateTestSuite(ctx context.Context, req model.CreateTestSuiteRequest) (*model.TestSuiteResponse, error)
+	GetTestSuite(ctx context.Context, accountID, id uuid.UUID) (*model.TestSuiteResponse, error)
+	ListTestSuites(ctx context.Context, accountID uuid.UUID) (*model.TestSuitesResponse, error)
+	UpdateTestSuite(
+		ctx context.Context, accountID, id uuid.UUID, req model.UpdateTestSuiteRequest,
+	) (*model.TestSuiteResponse, error)
+	DeleteTestSuite(ctx context.Context, accountID, id uuid.UUID) error
+	// RunTestSuite queues a run of the suite and returns it while it is
+	// pending.
+	RunTestSuite(
+		ctx context.Context, accountID, id uuid.UUID, req model.RunTestSuiteRequest,
+	) (*model.TestSuiteRunResponse, error)
+	ListTestSuiteRuns(ctx context.Context, accountID, id uuid.UUID) (*model.TestSuiteRunsResponse, error)
+	GetTestSuiteRun(ctx context.Context, accountID, id, runID uuid.UUID) (*model.TestSuiteRunResponse, error)
+	// WaitTestSuiteRun polls the run like WaitRun until it has finished or
+	// ctx is done.
+	WaitTestSuiteRun(ctx context.Context, accountID, id, runID uuid.UUID) (*model.TestSuiteRunResponse, error)
+	// RunRegression queues runs of the suite against the baseline and the
+	// candidate and returns both while they are pending.
+	RunRegression(
+		ctx context.Context, accountID, id uuid.UUID, req model.RunRegressionRequest,
+	) (*model.RegressionRunResponse, error)
+	GetRegressionReport(
+		ctx context.Context, accountID, id, baselineRunID, candidateRunID uuid.UUID,
+	) (*model.RegressionReportResponse, error)
+
+	CreateDataset(ctx context.Context, req model.CreateDatasetRequest) (*model.DatasetResponse, error)
+	GetDataset(ctx context.Context, accountID, id uuid.UUID) (*model.DatasetResponse, error)
+	ListDatasets(ctx context.Context, accountID uuid.UUID) (*model.DatasetsResponse, error)
+	UpdateDataset(
+		ctx context.Context, accountID, id uuid.UUID, req model.UpdateDatasetRequest,
+	) (*model.DatasetResponse, error)
+	DeleteDataset(ctx context.Context, accountID, id uuid.UUID) error
+	ImportDataset(
+		ctx context.Context, accountID, id uuid.UUID, format string, body io.Reader,
+	) (*model.DatasetVersionResponse, error)
+	ListDatasetVersions(ctx context.Context, accountID, id uuid.UUID) (*model.DatasetVersionsResponse, error)
+	ListDatasetRows(
+		ctx context.Context, accountID, id uuid.UUID, version, offset, limit int,
+	) (*model.DatasetRowsResponse, error)
+	ListUsage(ctx context.Context, accountID uuid.UUID, req model.ListUsageRequest) (*model.UsageRecordsResponse, error)
+	GetCostReport(ctx context.Context, req model.CostReportRequest) (*model.CostReportResponse, error)
+
+	CreateBudget(ctx context.Context, req model.CreateBudgetRequest) (*model.BudgetResponse, error)
+	GetBudget(ctx context.Context, accountID, id uuid.UUID) (*model.BudgetResponse, error)
+	ListBudgets(ctx context.Context, accountID uuid.UUID) (*model.BudgetsResponse, error)
+	UpdateBudget(
+		ctx context.Context, accountID, id uuid.UUID, req model.UpdateBudgetRequest,
+	) (*model.BudgetResponse, error)
+	DeleteBudget(ctx context.Context, accountID, id uuid.UUID) error
+	ListBudgetEvents(ctx context.Context, accountID, id uuid.UUID) (*model.BudgetEventsResponse, error)
 }
 
 type flowRunClient struct {
@@ -24,26 +155,473 @@ func NewFlowRunClient(baseURL string) FlowRunClient {
 }
 
 func (c *flowRunClient) GetHealth(ctx context.Context) (*model.HealthResponse, error) {
-	return get[model.HealthResponse](c.baseURL, "/v1/health")
+	return get[model.HealthResponse](ctx, c.baseURL, "/v1/health")
+}
+
+func (c *flowRunClient) CreateProvider(
+	ctx context.Context, req model.CreateProviderRequest,
+) (*model.ProviderResponse, error) {
+	return send[model.ProviderResponse](ctx, http.MethodPost, c.baseURL, providerEndpoint, req)
+}
+
+func (c *flowRunClient) GetProvider(ctx context.Context, accountID, id uuid.UUID) (*model.ProviderResponse, error) {
+	return get[model.ProviderResponse](ctx, c.baseURL, resourceEndpoint(providerEndpoint, accountID, id))
+}
+
+func (c *flowRunClient) ListProviders(ctx context.Context, accountID uuid.UUID) (*model.ProvidersResponse, error) {
+	return get[model.ProvidersResponse](ctx, c.baseURL, providerEndpoint+accountQuery(accountID))
+}
+
+func (c *flowRunClient) UpdateProvider(
+	ctx context.Context, accountID, id uuid.UUID, req model.UpdateProviderRequest,
+) (*model.ProviderResponse, error) {
+	endpoint := resourceEndpoint(providerEndpoint, accountID, id)
+	return send[model.ProviderResponse](ctx, http.MethodPut, c.baseURL, endpoint, req)
+}
+
+func (c *flowRunClient) DeleteProvider(ctx context.Context, accountID, id uuid.UUID) error {
+	_, err := send[struct{}](ctx, http.MethodDelete, c.baseURL, resourceEndpoint(providerEndpoint, accountID, id), nil)
+	return err
+}
+
+func (c *flowRunClient) CreateModel(ctx context.Context, req model.CreateModelRequest) (*model.ModelResponse, error) {
+	return send[model.ModelResponse](ctx, http.MethodPost, c.baseURL, modelEndpoint, req)
+}
+
+func (c *flowRunClient) GetModel(ctx context.Context, accountID, id uuid.UUID) (*model.ModelResponse, error) {
+	return get[model.ModelResponse](ctx, c.baseURL, resourceEndpoint(modelEndpoint, accountID, id))
+}
+
+func (c *flowRunClient) ListModels(ctx context.Context, accountID uuid.UUID) (*model.ModelsResponse, error) {
+	return get[model.ModelsResponse](ctx, c.baseURL, modelEndpoint+accountQuery(accountID))
+}
+
+func (c *flowRunClient) UpdateModel(
+	ctx context.Context, accountID, id uuid.UUID, req model.UpdateModelRequest,
+) (*model.ModelResponse, error) {
+	endpoint := resourceEndpoint(modelEndpoint, accountID, id)
+	return send[model.ModelResponse](ctx, http.MethodPut, c.baseURL, endpoint, req)
+}
+
+func (c *flowRunClient) DeleteModel(ctx context.Context, accountID, id uuid.UUID) error {
+	_, err := send[struct{}](ctx, http.MethodDelete, c.baseURL, resourceEndpoint(modelEndpoint, accountID, id), nil)
+	return err
+}
+
+func (c *flowRunClient) ListModelPrices(
+	ctx context.Context, accountID, id uuid.UUID,
+) (*model.ModelPricesResponse, error) {
+	return get[model.ModelPricesResponse](ctx, c.baseURL, subResourceEndpoint(modelEndpoint, accountID, id, "prices"))
+}
+
+func (c *flowRunClient) AddModelPrice(
+	ctx context.Context, accountID, id uuid.UUID, req model.AddModelPriceRequest,
+) (*model.ModelPriceResponse, error) {
+	endpoint := subResourceEndpoint(modelEndpoint, accountID, id, "prices")
+	return send[model.ModelPriceResponse](ctx, http.MethodPost, c.baseURL, endpoint, req)
+}
+
+func (c *flowRunClient) CreateFlow(ctx context.Context, req model.CreateFlowRequest) (*model.FlowResponse, error) {
+	return send[model.FlowResponse](ctx, http.MethodPost, c.baseURL, flowEndpoint, req)
+}
+
+func (c *flowRunClient) GetFlow(ctx context.Context, accountID, id uuid.UUID) (*model.FlowResponse, error) {
+	return get[model.FlowResponse](ctx, c.baseURL, resourceEndpoint(flowEndpoint, accountID, id))
+}
+
+func (c *flowRunClient) ListFlows(ctx context.Context, accountID uuid.UUID) (*model.FlowsResponse, error) {
+	return get[model.FlowsResponse](ctx, c.baseURL, flowEndpoint+accountQuery(accountID))
+}
+
+func (c *flowRunClient) UpdateFlow(
+	ctx context.Context, accountID, id uuid.UUID, req model.UpdateFlowRequest,
+) (*model.FlowResponse, error) {
+	endpoint := resourceEndpoint(flowEndpoint, accountID, id)
+	return send[model.FlowResponse](ctx, http.MethodPut, c.baseURL, endpoint, req)
+}
+
+func (c *flowRunClient) DeleteFlow(ctx context.Context, accountID, id uuid.UUID) error {
+	_, err := send[struct{}](ctx, http.MethodDelete, c.baseURL, resourceEndpoint(flowEndpoint, accountID, id), nil)
+	return err
+}
+
+func (c *flowRunClient) EstimateFlow(
+	ctx context.Context, accountID, id uuid.UUID, req model.EstimateFlowRequest,
+) (*model.FlowEstimateResponse, error) {
+	endpoint := subResourceEndpoint(flowEndpoint, accountID, id, "estimate")
+	return send[model.FlowEstimateResponse](ctx, http.MethodPost, c.baseURL, endpoint, req)
+}
+
+func (c *flowRunClient) StartRun(
+	ctx context.Context, accountID, flowID uuid.UUID, req model.RunFlowRequest,
+) (*model.FlowRunResponse, error) {
+	endpoint := subResourceEndpoint(flowEndpoint, accountID, flowID, "run")
+	return send[model.FlowRunResponse](ctx, http.MethodPost, c.baseURL, endpoint, req)
+}
+
+func (c *flowRunClient) GetRun(ctx context.Context, accountID, id uuid.UUID) (*model.FlowRunResponse, error) {
+	return get[model.FlowRunResponse](ctx, c.baseURL, resourceEndpoint(runEndpoint, accountID, id))
+}
+
+func (c *flowRunClient) WaitRun(ctx context.Context, accountID, id uuid.UUID) (*model.FlowRunResponse, error) {
+	return wait(ctx, func() (*model.FlowRunResponse, error) {
+		return c.GetRun(ctx, accountID, id)
+	}, (*model.FlowRunResponse).Finished)
+}
+
+// wait calls get, backing off between calls, until finished holds for what
+// it returns or ctx is done.
+func wait[T any](ctx context.Context, get func() (*T, error), finished func(*T) bool) (*T, error) {
+	backoff := waitRunBackoff
+	for {
+		run, err := get()
+		if err != nil {
+			return nil, err
+		}
+		if finished(run) {
+			return run, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxWaitRunBackoff)
+	}
+}
+
+func (c *flowRunClient) CancelRun(ctx context.Context, accountID, id uuid.UUID) (*model.FlowRunResponse, error) {
+	endpoint := subResourceEndpoint(runEndpoint, accountID, id, "cancel")
+	return send[model.FlowRunResponse](ctx, http.MethodPost, c.baseURL, endpoint, nil)
+}
+
+func (c *flowRunClient) CreatePrompt(
+	ctx context.Context, req model.CreatePromptRequest,
+) (*model.PromptResponse, error) {
+	return send[model.PromptResponse](ctx, http.MethodPost, c.baseURL, promptEndpoint, req)
+}
+
+func (c *flowRunClient) GetPrompt(ctx context.Context, accountID, id uuid.UUID) (*model.PromptResponse, error) {
+	return get[model.PromptResponse](ctx, c.baseURL, resourceEndpoint(promptEndpoint, accountID, id))
+}
+
+func (c *flowRunClient) ListPrompts(ctx context.Context, accountID uuid.UUID) (*model.PromptsResponse, error) {
+	return get[model.PromptsResponse](ctx, c.baseURL, promptEndpoint+accountQuery(accountID))
+}
+
+func (c *flowRunClient) UpdatePrompt(
+	ctx context.Context, accountID, id uuid.UUID, req model.UpdatePromptRequest,
+) (*model.PromptResponse, error) {
+	endpoint := resourceEndpoint(promptEndpoint, accountID, id)
+	return send[model.PromptResponse](ctx, http.MethodPut, c.baseURL, endpoint, req)
+}
+
+func (c *flowRunClient) DeletePrompt(ctx context.Context, accountID, id uuid.UUID) error {
+	_, err := send[struct{}](ctx, http.MethodDelete, c.baseURL, resourceEndpoint(promptEndpoint, accountID, id), nil)
+	return err
+}
+
+func (c *flowRunClient) ListPromptRevisions(
+	ctx context.Context, accountID, id uuid.UUID,
+) (*model.PromptRevisionsResponse, error) {
+	endpoint := subResourceEndpoint(promptEndpoint, accountID, id, "revisions")
+	return get[model.PromptRevisionsResponse](ctx, c.baseURL, endpoint)
+}
+
+func (c *flowRunClient) GetPromptRevision(
+	ctx context.Context, accountID, id uuid.UUID, revision int,
+) (*model.PromptRevisionResponse, error) {
+	endpoint := subResourceEndpoint(promptEndpoint, accountID, id, "revisions/"+strconv.Itoa(revision))
+	return get[model.PromptRevisionResponse](ctx, c.baseURL, endpoint)
+}
+
+func (c *flowRunClient) DiffPromptRevisions(
+	ctx context.Context, accountID, id uuid.UUID, from, to int,
+) (*model.PromptDiffResponse, error) {
+	query := url.Values{"from": {strconv.Itoa(from)}, "to": {strconv.Itoa(to)}}
+	endpoint := subResourceEndpoint(promptEndpoint, accountID, id, "diff") + "&" + query.Encode()
+	return get[model.PromptDiffResponse](ctx, c.baseURL, endpoint)
+}
+
+func (c *flowRunClient) ListPromptLabels(
+	ctx context.Context, accountID, id uuid.UUID,
+) (*model.PromptLabelsResponse, error) {
+	endpoint := subResourceEndpoint(promptEndpoint, accountID, id, "labels")
+	return get[model.PromptLabelsResponse](ctx, c.baseURL, endpoint)
+}
+
+func (c *flowRunClient) SetPromptLabel(
+	ctx context.Context, accountID, id uuid.UUID, label string, req model.SetPromptLabelRequest,
+) (*model.PromptLabelResponse, error) {
+	endpoint := subResourceEndpoint(promptEndpoint, accountID, id, "labels/"+url.PathEscape(label))
+	return send[model.PromptLabelResponse](ctx, http.MethodPut, c.baseURL, endpoint, req)
+}
+
+func (c *flowRunClient) CreateTestSuite(
+	ctx context.Context, req model.CreateTestSuiteRequest,
+) (*model.TestSuiteResponse, error) {
+	return send[model.TestSuiteResponse](ctx, http.MethodPost, c.baseURL, suiteEndpoint, req)
 }
 
-func get[T any](baseURL string, endpoint string) (*T, error) {
-	resp, err := http.Get(baseURL + endpoint)
+func (c *flowRunClient) GetTestSuite(ctx context.Context, accountID, id uuid.UUID) (*model.TestSuiteResponse, error) {
+	return get[model.TestSuiteResponse](ctx, c.baseURL, resourceEndpoint(suiteEndpoint, accountID, id))
+}
+
+func (c *flowRunClient) ListTestSuites(ctx context.Context, accountID uuid.UUID) (*model.TestSuitesResponse, error) {
+	return get[model.TestSuitesResponse](ctx, c.baseURL, suiteEndpoint+accountQuery(accountID))
+}
+
+func (c *flowRunClient) UpdateTestSuite(
+	ctx context.Context, accountID, id uuid.UUID, req model.UpdateTestSuiteRequest,
+) (*model.TestSuiteResponse, error) {
+	endpoint := resourceEndpoint(suiteEndpoint, accountID, id)
+	return send[model.TestSuiteResponse](ctx, http.MethodPut, c.baseURL, endpoint, req)
+}
+
+func (c *flowRunClient) DeleteTestSuite(ctx context.Context, accountID, id uuid.UUID) error {
+	_, err := send[struct{}](ctx, http.MethodDelete, c.baseURL, resourceEndpoint(suiteEndpoint, accountID, id), nil)
+	return err
+}
+
+func (c *flowRunClient) RunTestSuite(
+	ctx context.Context, accountID, id uuid.UUID, req model.RunTestSuiteRequest,
+) (*model.TestSuiteRunResponse, error) {
+	endpoint := subResourceEndpoint(suiteEndpoint, accountID, id, "run")
+	return send[model.TestSuiteRunResponse](ctx, http.MethodPost, c.baseURL, endpoint, req)
+}
+
+func (c *flowRunClient) ListTestSuiteRuns(
+	ctx context.Context, accountID, id uuid.UUID,
+) (*model.TestSuiteRunsResponse, error) {
+	endpoint := subResourceEndpoint(suiteEndpoint, accountID, id, "runs")
+	return get[model.TestSuiteRunsResponse](ctx, c.baseURL, endpoint)
+}
+
+func (c *flowRunClient) GetTestSuiteRun(
+	ctx context.Context, accountID, id, runID uuid.UUID,
+) (*model.TestSuiteRunResponse, error) {
+	endpoint := subResourceEndpoint(suiteEndpoint, accountID, id, "runs/"+runID.String())
+	return get[model.TestSuiteRunResponse](ctx, c.baseURL, endpoint)
+}
+
+func (c *flowRunClient) WaitTestSuiteRun(
+	ctx context.Context, accountID, id, runID uuid.UUID,
+) (*model.TestSuiteRunResponse, error) {
+	return wait(ctx, func() (*model.TestSuiteRunResponse, error) {
+		return c.GetTestSuiteRun(ctx, accountID, id, runID)
+	}, (*model.TestSuiteRunResponse).Finished)
+}
+
+func (c *flowRunClient) RunRegression(
+	ctx context.Context, accountID, id uuid.UUID, req model.RunRegressionRequest,
+) (*model.RegressionRunResponse, error) {
+	endpoint := subResourceEndpoint(suiteEndpoint, accountID, id, "regression")
+	return send[model.RegressionRunResponse](ctx, http.MethodPost, c.baseURL, endpoint, req)
+}
+
+// GetRegressionReport compares two finished runs of the suite.
+func (c *flowRunClient) GetRegressionReport(
+	ctx context.Context, accountID, id, baselineRunID, candidateRunID uuid.UUID,
+) (*model.RegressionReportResponse, error) {
+	query := url.Values{"baseline": {baselineRunID.String()}, "candidate": {candidateRunID.String()}}
+	endpoint := subResourceEndpoint(suiteEndpoint, accountID, id, "regression") + "&" + query.Encode()
+	return get[model.RegressionReportResponse](ctx, c.baseURL, endpoint)
+}
+
+func (c *flowRunClient) CreateDataset(
+	ctx context.Context, req model.CreateDatasetRequest,
+) (*model.DatasetResponse, error) {
+	return send[model.DatasetResponse](ctx, http.MethodPost, c.baseURL, datasetEndpoint, req)
+}
+
+func (c *flowRunClient) GetDataset(ctx context.Context, accountID, id uuid.UUID) (*model.DatasetResponse, error) {
+	return get[model.DatasetResponse](ctx, c.baseURL, resourceEndpoint(datasetEndpoint, accountID, id))
+}
+
+func (c *flowRunClient) ListDatasets(ctx context.Context, accountID uuid.UUID) (*model.DatasetsResponse, error) {
+	return get[model.DatasetsResponse](ctx, c.baseURL, datasetEndpoint+accountQuery(accountID))
+}
+
+func (c *flowRunClient) UpdateDataset(
+	ctx context.Context, accountID, id uuid.UUID, req model.UpdateDatasetRequest,
+) (*model.DatasetResponse, error) {
+	endpoint := resourceEndpoint(datasetEndpoint, accountID, id)
+	return send[model.DatasetResponse](ctx, http.MethodPut, c.baseURL, endpoint, req)
+}
+
+func (c *flowRunClient) DeleteDataset(ctx context.Context, accountID, id uuid.UUID) error {
+	_, err := send[struct{}](ctx, http.MethodDelete, c.baseURL, resourceEndpoint(datasetEndpoint, accountID, id), nil)
+	return err
+}
+
+// ImportDataset uploads body, encoded as format (model.DatasetFormatCSV or
+// model.DatasetFormatJSONL), as the next version of the dataset. The body is
+// streamed, so it may be an open file of any size.
+func (c *flowRunClient) ImportDataset(
+	ctx context.Context, accountID, id uuid.UUID, format string, body io.Reader,
+) (*model.DatasetVersionResponse, error) {
+	endpoint := subResourceEndpoint(datasetEndpoint, accountID, id, "versions") + "&" +
+		url.Values{"format": {format}}.Encode()
+	return do[model.DatasetVersionResponse](ctx, http.MethodPost, c.baseURL+endpoint, "application/octet-stream", body)
+}
+
+func (c *flowRunClient) ListDatasetVersions(
+	ctx context.Context, accountID, id uuid.UUID,
+) (*model.DatasetVersionsResponse, error) {
+	endpoint := subResourceEndpoint(datasetEndpoint, accountID, id, "versions")
+	return get[model.DatasetVersionsResponse](ctx, c.baseURL, endpoint)
+}
+
+// ListDatasetRows returns up to limit rows of a dataset version starting at
+// offset. A zero limit uses the server's default page size.
+func (c *flowRunClient) ListDatasetRows(
+	ctx context.Context, accountID, id uuid.UUID, version, offset, limit int,
+) (*model.DatasetRowsResponse, error) {
+	query := url.Values{"offset": {strconv.Itoa(offset)}}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	endpoint := subResourceEndpoint(datasetEndpoint, accountID, id, "versions/"+strconv.Itoa(version)+"/rows") +
+		"&" + query.Encode()
+	return get[model.DatasetRowsResponse](ctx, c.baseURL, endpoint)
+}
+
+// ListUsage pages through the usage ledger of the account.
+func (c *flowRunClient) ListUsage(
+	ctx context.Context, accountID uuid.UUID, req model.ListUsageRequest,
+) (*model.UsageRecordsResponse, error) {
+	query := url.Values{"offset": {strconv.Itoa(req.Offset)}}
+	if req.Limit > 0 {
+		query.Set("limit", strconv.Itoa(req.Limit))
+	}
+	if req.FlowID != nil {
+		query.Set("flow_id", req.FlowID.String())
+	}
+	if req.RunID != nil {
+		query.Set("run_id", req.RunID.String())
+	}
+	return get[model.UsageRecordsResponse](ctx, c.baseURL, usageEndpoint+accountQuery(accountID)+"&"+query.Encode())
+}
+
+// GetCostReport aggregates the usage ledger of the account, or of every
+// account when req.AccountID is nil.
+func (c *flowRunClient) GetCostReport(
+	ctx context.Context, req model.CostReportRequest,
+) (*model.CostReportResponse, error) {
+	query := url.Values{
+		"from": {req.From.Format(time.RFC3339)},
+		"to":   {req.To.Format(time.RFC3339)},
+	}
+	if req.AccountID != nil {
+		query.Set("account_id", req.AccountID.String())
+	}
+	if req.Bucket != "" {
+		query.Set("bucket", req.Bucket)
+	}
+	if len(req.GroupBy) > 0 {
+		query.Set("group_by", strings.Join(req.GroupBy, ","))
+	}
+	return get[model.CostReportResponse](ctx, c.baseURL, costEndpoint+"/report?"+query.Encode())
+}
+
+func (c *flowRunClient) CreateBudget(
+	ctx context.Context, req model.CreateBudgetRequest,
+) (*model.BudgetResponse, error) {
+	return send[model.BudgetResponse](ctx, http.MethodPost, c.baseURL, budgetEndpoint, req)
+}
+
+func (c *flowRunClient) GetBudget(ctx context.Context, accountID, id uuid.UUID) (*model.BudgetResponse, error) {
+	return get[model.BudgetResponse](ctx, c.baseURL, resourceEndpoint(budgetEndpoint, accountID, id))
+}
+
+func (c *flowRunClient) ListBudgets(ctx context.Context, accountID uuid.UUID) (*model.BudgetsResponse, error) {
+	return get[model.BudgetsResponse](ctx, c.baseURL, budgetEndpoint+accountQuery(accountID))
+}
+
+func (c *flowRunClient) UpdateBudget(
+	ctx context.Context, accountID, id uuid.UUID, req model.UpdateBudgetRequest,
+) (*model.BudgetResponse, error) {
+	endpoint := resourceEndpoint(budgetEndpoint, accountID, id)
+	return send[model.BudgetResponse](ctx, http.MethodPut, c.baseURL, endpoint, req)
+}
+
+func (c *flowRunClient) DeleteBudget(ctx context.Context, accountID, id uuid.UUID) error {
+	_, err := send[struct{}](ctx, http.MethodDelete, c.baseURL, resourceEndpoint(budgetEndpoint, accountID, id), nil)
+	return err
+}
+
+func (c *flowRunClient) ListBudgetEvents(
+	ctx context.Context, accountID, id uuid.UUID,
+) (*model.BudgetEventsResponse, error) {
+	endpoint := subResourceEndpoint(budgetEndpoint, accountID, id, "events")
+	return get[model.BudgetEventsResponse](ctx, c.baseURL, endpoint)
+}
+
+func resourceEndpoint(collection string, accountID, id uuid.UUID) string {
+	return collection + "/" + id.String() + accountQuery(accountID)
+}
+
+// subResourceEndpoint addresses path below a resource, e.g. its revisions.
+func subResourceEndpoint(collection string, accountID, id uuid.UUID, path string) string {
+	return collection + "/" + id.String() + "/" + path + accountQuery(accountID)
+}
+
+func accountQuery(accountID uuid.UUID) string {
+	return "?" + url.Values{"account_id": {accountID.String()}}.Encode()
+}
+
+func get[T any](ctx context.Context, baseURL string, endpoint string) (*T, error) {
+	return send[T](ctx, http.MethodGet, baseURL, endpoint, nil)
+}
+
+// send performs a JSON request. A non-2xx status is returned as
+// *model.ErrorResponse so callers can inspect field-level validation errors.
+func send[T any](ctx context.Context, method string, baseURL string, endpoint string, body any) (*T, error) {
+	if body == nil {
+		return do[T](ctx, method, baseURL+endpoint, "", nil)
+	}
+	payload, err := json.Marshal(body)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	return do[T](ctx, method, baseURL+endpoint, "application/json", bytes.NewReader(payload))
+}
+
+// do sends body, of contentType, to target and decodes the JSON response.
+func do[T any](ctx context.Context, method, target, contentType string, body io.Reader) (*T, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		errResp := &model.ErrorResponse{}
+		if err := json.Unmarshal(bodyBytes, errResp); err != nil || errResp.Message == "" {
+			return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		}
+		errResp.StatusCode = resp.StatusCode
+		return nil, errResp
+	}
+
 	var result T
+	if len(bodyBytes) == 0 {
+		return &result, nil
+	}
 	if err := json.Unmarshal(bodyBytes, &result); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateBudgetRequest caps what an account spends per period. Scope is
+// "account", "flow" or "provider"; flow and provider budgets name the flow
+// or provider as TargetID. Period is "daily" or "monthly", starting on UTC
+// boundaries. SoftLimit and HardLimit are decimal dollars, e.g. "25"; an
+// empty limit is no limit, but at least one must be set.
+type CreateBudgetRequest struct {
+	Name      string     `json:"name"`
+	AccountID uuid.UUID  `json:"account_id"`
+	Scope     string     `json:"scope"`
+	TargetID  *uuid.UUID `json:"target_id,omitempty"`
+	Period    string     `json:"period"`
+	SoftLimit string     `json:"soft_limit,omitempty"`
+	HardLimit string     `json:"hard_limit,omitempty"`
+}
+
+type UpdateBudgetRequest struct {
+	Name      string     `json:"name"`
+	Scope     string     `json:"scope"`
+	TargetID  *uuid.UUID `json:"target_id,omitempty"`
+	Period    string     `json:"period"`
+	SoftLimit string     `json:"soft_limit,omitempty"`
+	HardLimit string     `json:"hard_limit,omitempty"`
+}
+
+// BudgetResponse is a budget with what it has Spent in the period starting
+// at PeriodStart, including the estimated cost of calls in flight.
+type BudgetResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	AccountID   uuid.UUID  `json:"account_id"`
+	Scope       string     `json:"scope"`
+	TargetID    *uuid.UUID `json:"target_id,omitempty"`
+	Period      string     `json:"period"`
+	SoftLimit   string     `json:"soft_limit"`
+	HardLimit   string     `json:"hard_limit"`
+	Spent       string     `json:"spent"`
+	PeriodStart time.Time  `json:"period_start"`
+}
+
+type BudgetsResponse struct {
+	Budgets []BudgetResponse `json:"budgets"`
+}
+
+// BudgetEventResponse records that a budget crossed a limit in the period
+// starting at PeriodStart. Type is "soft_limit_crossed" or
+// "hard_limit_reached"; each is recorded at most once per period.
+type BudgetEventResponse struct {
+	ID          uuid.UUID `json:"id"`
+	BudgetID    uuid.UUID `json:"budget_id"`
+	Type        string    `json:"type"`
+	PeriodStart time.Time `json:"period_start"`
+	Spent       string    `json:"spent"`
+	Limit       string    `json:"limit"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type BudgetEventsResponse struct {
+	Events []BudgetEventResponse `json:"events"`
+}
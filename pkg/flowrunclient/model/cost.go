@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CostReportRequest selects the usage a cost report covers: calls made in
+// [From, To) by the account, or by every account when AccountID is nil.
+// Bucket is "hour", "day" or "month" and defaults to "day". GroupBy lists
+// any of "account", "flow", "model", "provider", "prompt_revision" and
+// "purpose".
+type CostReportRequest struct {
+	AccountID *uuid.UUID
+	From      time.Time
+	To        time.Time
+	Bucket    string
+	GroupBy   []string
+}
+
+// CostReportRow aggregates the calls of one UTC time bucket and group. Only
+// the fields of the report's groups are set; calls without a flow or prompt
+// revision form a group of their own.
+type CostReportRow struct {
+	Bucket         time.Time  `json:"bucket"`
+	AccountID      *uuid.UUID `json:"account_id,omitempty"`
+	FlowID         *uuid.UUID `json:"flow_id,omitempty"`
+	ModelID        *uuid.UUID `json:"model_id,omitempty"`
+	Model          string     `json:"model,omitempty"`
+	ProviderID     *uuid.UUID `json:"provider_id,omitempty"`
+	PromptID       *uuid.UUID `json:"prompt_id,omitempty"`
+	PromptRevision int        `json:"prompt_revision,omitempty"`
+	Purpose        string     `json:"purpose,omitempty"`
+	Calls          int        `json:"calls"`
+	Usage          TokenUsage `json:"usage"`
+	Cost           string     `json:"cost"`
+}
+
+// CostReportResponse is a cost report: the totals of the range, and its rows
+// ordered by bucket. Costs are decimal dollars.
+type CostReportResponse struct {
+	AccountID *uuid.UUID      `json:"account_id,omitempty"`
+	From      time.Time       `json:"from"`
+	To        time.Time       `json:"to"`
+	Bucket    string          `json:"bucket"`
+	GroupBy   []string        `json:"group_by"`
+	Calls     int             `json:"calls"`
+	Usage     TokenUsage      `json:"usage"`
+	Cost      string          `json:"cost"`
+	Rows      []CostReportRow `json:"rows"`
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Dataset formats accepted by imports.
+const (
+	// DatasetFormatCSV has a header row naming the columns. Every column is
+	// an input variable but "name" and "expected", which hold the row name
+	// and its expected output.
+	DatasetFormatCSV = "csv"
+	// DatasetFormatJSONL has one JSON object per line with "inputs" and
+	// optional "name" and "expected" members.
+	DatasetFormatJSONL = "jsonl"
+)
+
+// CreateDatasetRequest creates an empty dataset; rows are added by importing
+// versions.
+type CreateDatasetRequest struct {
+	AccountID   uuid.UUID `json:"account_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+}
+
+type UpdateDatasetRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// DatasetResponse is a dataset. Version and RowCount describe its latest
+// version and are zero until the first import.
+type DatasetResponse struct {
+	ID          uuid.UUID `json:"id"`
+	AccountID   uuid.UUID `json:"account_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Version     int       `json:"version"`
+	RowCount    int       `json:"row_count"`
+}
+
+type DatasetsResponse struct {
+	Datasets []DatasetResponse `json:"datasets"`
+}
+
+// DatasetVersionResponse is an immutable snapshot of a dataset's rows.
+type DatasetVersionResponse struct {
+	ID        uuid.UUID `json:"id"`
+	DatasetID uuid.UUID `json:"dataset_id"`
+	Version   int       `json:"version"`
+	Format    string    `json:"format"`
+	RowCount  int       `json:"row_count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type DatasetVersionsResponse struct {
+	Versions []DatasetVersionResponse `json:"versions"`
+}
+
+// DatasetRow is one row of a dataset version. Position counts from 0.
+type DatasetRow struct {
+	Position int            `json:"position"`
+	Name     string         `json:"name,omitempty"`
+	Inputs   map[string]any `json:"inputs"`
+	Expected any            `json:"expected,omitempty"`
+}
+
+// DatasetRowsResponse is a page of rows starting at Offset.
+type DatasetRowsResponse struct {
+	Version int          `json:"version"`
+	Offset  int          `json:"offset"`
+	Rows    []DatasetRow `json:"rows"`
+}
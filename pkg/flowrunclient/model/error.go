@@ -0,0 +1,45 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// ErrorCodeBudgetExceeded is the Code of requests rejected because a hard
+// budget limit is reached.
+const ErrorCodeBudgetExceeded = "budget_exceeded"
+
+// ErrorResponse is a failed request. Code identifies errors clients are
+// expected to handle, such as ErrorCodeBudgetExceeded.
+type ErrorResponse struct {
+	StatusCode int          `json:"-"`
+	Code       string       `json:"code,omitempty"`
+	Message    string       `json:"error"`
+	Fields     []FieldError `json:"fields,omitempty"`
+}
+
+func NewErrorResponse(message string, fields ...FieldError) *ErrorResponse {
+	return &ErrorResponse{
+		Message: message,
+		Fields:  fields,
+	}
+}
+
+func (e *ErrorResponse) Error() string {
+	if len(e.Fields) == 0 {
+		return fmt.Sprintf("%d: %s", e.StatusCode, e.Message)
+	}
+
+	fields := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		fields = append(fields, f.Field)
+	}
+	return fmt.Sprintf("%d: %s (%s)", e.StatusCode, e.Message, strings.Join(fields, ", "))
+}
@@ -0,0 +1,64 @@
+package model
+
+import "github.com/google/uuid"
+
+// CreateFlowRequest carries a flow definition as authored. Format is "yaml"
+// or "json"; the flow name is taken from the definition.
+type CreateFlowRequest struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Format    string    `json:"format"`
+	Source    string    `json:"source"`
+}
+
+type UpdateFlowRequest struct {
+	Format string `json:"format"`
+	Source string `json:"source"`
+}
+
+type FlowResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	AccountID uuid.UUID `json:"account_id"`
+	Format    string    `json:"format"`
+	Source    string    `json:"source"`
+}
+
+type FlowsResponse struct {
+	Flows []FlowResponse `json:"flows"`
+}
+
+// EstimateFlowRequest prices a run of the flow with Inputs without running
+// it. Model overrides the model of every prompt step. Prompt steps are taken
+// to complete with StepCompletionTokens by step path, such as
+// "research.search" for a step of a sub-flow, else with their max_tokens,
+// else with CompletionTokens, 256 by default.
+type EstimateFlowRequest struct {
+	Inputs               map[string]any `json:"inputs,omitempty"`
+	Model                string         `json:"model,omitempty"`
+	CompletionTokens     int            `json:"completion_tokens,omitempty"`
+	StepCompletionTokens map[string]int `json:"step_completion_tokens,omitempty"`
+}
+
+// StepEstimate is the estimated usage of one prompt step, in the order steps
+// would run. Encoding names the tokenizer prompt tokens were counted with,
+// and is empty when they were approximated. Rendered is false when the
+// prompt depends on what earlier steps output and its template was counted
+// instead.
+type StepEstimate struct {
+	StepID   string     `json:"step_id"`
+	Model    string     `json:"model"`
+	Encoding string     `json:"encoding,omitempty"`
+	Rendered bool       `json:"rendered"`
+	Usage    TokenUsage `json:"usage"`
+	Cost     string     `json:"cost"`
+}
+
+// FlowEstimateResponse is the estimated usage and cost of one run. Costs are
+// decimal dollars. Every case of a branch is counted, so branching flows are
+// estimated at most what their costliest path may cost.
+type FlowEstimateResponse struct {
+	FlowID uuid.UUID      `json:"flow_id"`
+	Usage  TokenUsage     `json:"usage"`
+	Cost   string         `json:"cost"`
+	Steps  []StepEstimate `json:"steps"`
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateModelRequest registers a model. InputPrice and OutputPrice are
+// decimal dollars per million prompt and completion tokens, e.g. "0.15";
+// they may be left empty for unpriced models. CachedPrice is the price of
+// prompt tokens read from the provider's cache; empty bills them at
+// InputPrice.
+type CreateModelRequest struct {
+	Name        string    `json:"name"`
+	AccountID   uuid.UUID `json:"account_id"`
+	ProviderID  uuid.UUID `json:"provider_id"`
+	InputPrice  string    `json:"input_price,omitempty"`
+	OutputPrice string    `json:"output_price,omitempty"`
+	CachedPrice string    `json:"cached_price,omitempty"`
+}
+
+// UpdateModelRequest changes a model. Prices left empty keep those in
+// effect; prices that differ from them are added to the model's catalog as
+// a manual override.
+type UpdateModelRequest struct {
+	Name        string    `json:"name"`
+	ProviderID  uuid.UUID `json:"provider_id"`
+	InputPrice  string    `json:"input_price,omitempty"`
+	OutputPrice string    `json:"output_price,omitempty"`
+	CachedPrice string    `json:"cached_price,omitempty"`
+}
+
+// ModelResponse is a model with the prices in effect now. ContextWindow is
+// zero until it is synced from the provider.
+type ModelResponse struct {
+	ID            uuid.UUID `json:"id"`
+	Name          string    `json:"name"`
+	AccountID     uuid.UUID `json:"account_id"`
+	ProviderID    uuid.UUID `json:"provider_id"`
+	InputPrice    string    `json:"input_price"`
+	OutputPrice   string    `json:"output_price"`
+	CachedPrice   string    `json:"cached_price"`
+	ContextWindow int       `json:"context_window"`
+}
+
+type ModelsResponse struct {
+	Models []ModelResponse `json:"models"`
+}
+
+// AddModelPriceRequest sets a manual price for a model, in decimal dollars
+// per million tokens as on CreateModelRequest. Manual prices take precedence
+// over synced ones from EffectiveAt on, which defaults to now and may be in
+// the past or the future.
+type AddModelPriceRequest struct {
+	InputPrice  string     `json:"input_price,omitempty"`
+	OutputPrice string     `json:"output_price,omitempty"`
+	CachedPrice string     `json:"cached_price,omitempty"`
+	EffectiveAt *time.Time `json:"effective_at,omitempty"`
+}
+
+// ModelPriceResponse is an entry of a model's pricing catalog. Source is
+// "manual" or "open_router".
+type ModelPriceResponse struct {
+	ID          uuid.UUID `json:"id"`
+	ModelID     uuid.UUID `json:"model_id"`
+	Source      string    `json:"source"`
+	InputPrice  string    `json:"input_price"`
+	OutputPrice string    `json:"output_price"`
+	CachedPrice string    `json:"cached_price"`
+	EffectiveAt time.Time `json:"effective_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type ModelPricesResponse struct {
+	Prices []ModelPriceResponse `json:"prices"`
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PromptVariable declares a template variable. Type is one of string, number,
+// integer, boolean, object or array.
+type PromptVariable struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required,omitempty"`
+	Description string `json:"description,omitempty"`
+	Default     any    `json:"default,omitempty"`
+}
+
+// PromptTemplate holds Go text/template sources for the system and user
+// messages. Templates may only read declared variables.
+type PromptTemplate struct {
+	System    string           `json:"system,omitempty"`
+	User      string           `json:"user"`
+	Variables []PromptVariable `json:"variables,omitempty"`
+}
+
+// CreatePromptRequest creates a prompt as its revision 1, authored by Author.
+type CreatePromptRequest struct {
+	AccountID   uuid.UUID      `json:"account_id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Author      string         `json:"author"`
+	Template    PromptTemplate `json:"template"`
+}
+
+// UpdatePromptRequest replaces a prompt. A changed template becomes a new
+// revision authored by Author; metadata-only edits keep the current revision.
+type UpdatePromptRequest struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Author      string         `json:"author"`
+	Template    PromptTemplate `json:"template"`
+}
+
+// PromptResponse is a prompt at its latest revision.
+type PromptResponse struct {
+	ID          uuid.UUID      `json:"id"`
+	AccountID   uuid.UUID      `json:"account_id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Revision    int            `json:"revision"`
+	Author      string         `json:"author"`
+	Template    PromptTemplate `json:"template"`
+}
+
+type PromptsResponse struct {
+	Prompts []PromptResponse `json:"prompts"`
+}
+
+// PromptRevisionResponse is an immutable snapshot of a prompt template.
+// ContentHash is the hex SHA-256 of the template.
+type PromptRevisionResponse struct {
+	ID          uuid.UUID      `json:"id"`
+	PromptID    uuid.UUID      `json:"prompt_id"`
+	Revision    int            `json:"revision"`
+	Author      string         `json:"author"`
+	ContentHash string         `json:"content_hash"`
+	Template    PromptTemplate `json:"template"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+type PromptRevisionsResponse struct {
+	Revisions []PromptRevisionResponse `json:"revisions"`
+}
+
+// SetPromptLabelRequest points a label at a revision of the prompt.
+type SetPromptLabelRequest struct {
+	Revision int `json:"revision"`
+}
+
+type PromptLabelResponse struct {
+	Name      string    `json:"name"`
+	Revision  int       `json:"revision"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type PromptLabelsResponse struct {
+	Labels []PromptLabelResponse `json:"labels"`
+}
+
+// DiffLine is one line of a diff. Op is equal, insert or delete; OldLine and
+// NewLine are 1-based and omitted for the side the line is not in.
+type DiffLine struct {
+	Op      string `json:"op"`
+	Text    string `json:"text"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+}
+
+// PromptDiffResponse holds the line-level changes from revision From to To,
+// per message. Variables are compared as indented JSON.
+type PromptDiffResponse struct {
+	From      int        `json:"from"`
+	To        int        `json:"to"`
+	System    []DiffLine `json:"system"`
+	User      []DiffLine `json:"user"`
+	Variables []DiffLine `json:"variables"`
+}
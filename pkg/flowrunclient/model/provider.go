@@ -0,0 +1,33 @@
+package model
+
+import "github.com/google/uuid"
+
+type CreateProviderRequest struct {
+	Name      string    `json:"name"`
+	AccountID uuid.UUID `json:"account_id"`
+	Type      string    `json:"type"`
+	ApiKey    string    `json:"api_key,omitempty"`
+	BaseURL   string    `json:"base_url,omitempty"`
+}
+
+// UpdateProviderRequest replaces the mutable provider fields. An empty ApiKey
+// keeps the stored key as long as Type does not change.
+type UpdateProviderRequest struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	ApiKey  string `json:"api_key,omitempty"`
+	BaseURL string `json:"base_url,omitempty"`
+}
+
+// ProviderResponse never carries the API key.
+type ProviderResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	AccountID uuid.UUID `json:"account_id"`
+	Type      string    `json:"type"`
+	BaseURL   string    `json:"base_url,omitempty"`
+}
+
+type ProvidersResponse struct {
+	Providers []ProviderResponse `json:"providers"`
+}
@@ -0,0 +1,79 @@
+package model
+
+import "github.com/google/uuid"
+
+// RunRegressionRequest runs a suite against Baseline and Candidate, to
+// compare the two runs, e.g. two prompt revisions or two models.
+type RunRegressionRequest struct {
+	Baseline  RunTestSuiteRequest `json:"baseline"`
+	Candidate RunTestSuiteRequest `json:"candidate"`
+}
+
+// RegressionRunResponse is the pair of runs a regression queued. Once both
+// have succeeded, their regression report compares them.
+type RegressionRunResponse struct {
+	SuiteID   uuid.UUID            `json:"suite_id"`
+	Baseline  TestSuiteRunResponse `json:"baseline"`
+	Candidate TestSuiteRunResponse `json:"candidate"`
+}
+
+// RunSummary aggregates one run of a regression report. PassRate and Score
+// are fractions between 0 and 1; a case scores the share of its assertions
+// that passed, judge assertions counting with their score. Cost is in
+// dollars.
+type RunSummary struct {
+	RunID          uuid.UUID  `json:"run_id"`
+	Model          string     `json:"model"`
+	PromptRevision int        `json:"prompt_revision,omitempty"`
+	DatasetVersion int        `json:"dataset_version,omitempty"`
+	Cases          int        `json:"cases"`
+	Passed         int        `json:"passed"`
+	Failed         int        `json:"failed"`
+	PassRate       float64    `json:"pass_rate"`
+	Score          float64    `json:"score"`
+	Usage          TokenUsage `json:"usage"`
+	Cost           string     `json:"cost"`
+	LatencyMS      int64      `json:"latency_ms"`
+	MeanLatencyMS  int64      `json:"mean_latency_ms"`
+}
+
+// RegressionDelta is the candidate's summary minus the baseline's.
+type RegressionDelta struct {
+	Passed        int     `json:"passed"`
+	PassRate      float64 `json:"pass_rate"`
+	Score         float64 `json:"score"`
+	Tokens        int     `json:"tokens"`
+	Cost          string  `json:"cost"`
+	MeanLatencyMS int64   `json:"mean_latency_ms"`
+}
+
+type CaseOutcome struct {
+	Passed    bool     `json:"passed"`
+	Score     float64  `json:"score"`
+	Error     string   `json:"error,omitempty"`
+	Cost      string   `json:"cost"`
+	LatencyMS int64    `json:"latency_ms"`
+	Failures  []string `json:"failures,omitempty"`
+}
+
+// CaseComparison pairs the outcomes of a case in both runs. Change is one of
+// fixed, regressed, passing, failing, added or removed; Baseline or
+// Candidate is nil for cases only the other run has.
+type CaseComparison struct {
+	Position  int          `json:"position"`
+	Name      string       `json:"name"`
+	Change    string       `json:"change"`
+	Baseline  *CaseOutcome `json:"baseline,omitempty"`
+	Candidate *CaseOutcome `json:"candidate,omitempty"`
+}
+
+// RegressionReportResponse compares a candidate run of a suite with a
+// baseline run. Flipped lists the cases that were fixed or regressed.
+type RegressionReportResponse struct {
+	SuiteID   uuid.UUID        `json:"suite_id"`
+	Baseline  RunSummary       `json:"baseline"`
+	Candidate RunSummary       `json:"candidate"`
+	Delta     RegressionDelta  `json:"delta"`
+	Flipped   []CaseComparison `json:"flipped"`
+	Cases     []CaseComparison `json:"cases"`
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RunFlowRequest queues a run of the flow with Inputs. Model overrides the
+// model of every prompt step.
+type RunFlowRequest struct {
+	Inputs map[string]any `json:"inputs,omitempty"`
+	Model  string         `json:"model,omitempty"`
+}
+
+// StepRunResponse is one step of a run, in the order of the definition.
+// ChildRunID is set for sub-flow steps.
+type StepRunResponse struct {
+	StepID     string         `json:"step_id"`
+	Type       string         `json:"type"`
+	Status     string         `json:"status"`
+	Inputs     map[string]any `json:"inputs,omitempty"`
+	Output     any            `json:"output,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	Usage      TokenUsage     `json:"usage"`
+	Cost       string         `json:"cost"`
+	ChildRunID *uuid.UUID     `json:"child_run_id,omitempty"`
+	StartedAt  *time.Time     `json:"started_at,omitempty"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+}
+
+// FlowRunResponse is a run of a flow. Status is pending until a worker starts
+// the run, then running until it succeeds, fails or is canceled; see
+// Finished. Costs are decimal dollars, including those of sub-flows. Steps
+// is only filled in when a single run is requested.
+type FlowRunResponse struct {
+	ID                uuid.UUID         `json:"id"`
+	FlowID            uuid.UUID         `json:"flow_id"`
+	AccountID         uuid.UUID         `json:"account_id"`
+	Model             string            `json:"model,omitempty"`
+	Status            string            `json:"status"`
+	Inputs            map[string]any    `json:"inputs"`
+	Outputs           map[string]any    `json:"outputs,omitempty"`
+	Error             string            `json:"error,omitempty"`
+	Usage             TokenUsage        `json:"usage"`
+	Cost              string            `json:"cost"`
+	CreatedAt         time.Time         `json:"created_at"`
+	StartedAt         *time.Time        `json:"started_at,omitempty"`
+	FinishedAt        *time.Time        `json:"finished_at,omitempty"`
+	CancelRequestedAt *time.Time        `json:"cancel_requested_at,omitempty"`
+	Steps             []StepRunResponse `json:"steps,omitempty"`
+}
+
+// Finished reports whether the run has reached its final status.
+func (r *FlowRunResponse) Finished() bool {
+	switch r.Status {
+	case "succeeded", "failed", "canceled":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,170 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Assertion checks one property of a case's output. Type is one of equals,
+// contains, not_contains, regex, json_schema, json_path, max_tokens,
+// max_latency, max_cost or judge. Value is the operand of the other types
+// but json_schema, which takes Schema, and json_path, which compares the
+// value at Path with Expected. max_latency takes a duration such as "2s" and
+// max_cost a dollar amount such as "0.01".
+//
+// judge has the judge Model grade the output against Rubric with a score
+// between 0 and 1; the assertion passes when the score reaches Threshold,
+// 0.5 when unset.
+type Assertion struct {
+	Type      string          `json:"type"`
+	Value     string          `json:"value,omitempty"`
+	Path      string          `json:"path,omitempty"`
+	Expected  any             `json:"expected,omitempty"`
+	Schema    json.RawMessage `json:"schema,omitempty"`
+	Model     string          `json:"model,omitempty"`
+	Rubric    string          `json:"rubric,omitempty"`
+	Threshold float64         `json:"threshold,omitempty"`
+}
+
+// TestCase binds Inputs to the prompt's variables or the flow's inputs and
+// passes when every assertion holds. equals, contains, not_contains and
+// json_path assertions without an operand compare with Expected.
+type TestCase struct {
+	Name       string         `json:"name"`
+	Inputs     map[string]any `json:"inputs,omitempty"`
+	Expected   any            `json:"expected,omitempty"`
+	Assertions []Assertion    `json:"assertions"`
+}
+
+// CreateTestSuiteRequest creates a suite for either a stored prompt or a
+// flow, referenced by name. The suite has its own Cases or runs over the
+// rows of Dataset, at DatasetVersion or the latest version when zero, and
+// checks every row with Assertions. A dataset suite without assertions is a
+// batch run whose rows pass when they produce an output.
+type CreateTestSuiteRequest struct {
+	AccountID      uuid.UUID   `json:"account_id"`
+	Name           string      `json:"name"`
+	Description    string      `json:"description,omitempty"`
+	Prompt         string      `json:"prompt,omitempty"`
+	Flow           string      `json:"flow,omitempty"`
+	Cases          []TestCase  `json:"cases,omitempty"`
+	Dataset        string      `json:"dataset,omitempty"`
+	DatasetVersion int         `json:"dataset_version,omitempty"`
+	Assertions     []Assertion `json:"assertions,omitempty"`
+}
+
+type UpdateTestSuiteRequest struct {
+	Name           string      `json:"name"`
+	Description    string      `json:"description,omitempty"`
+	Prompt         string      `json:"prompt,omitempty"`
+	Flow           string      `json:"flow,omitempty"`
+	Cases          []TestCase  `json:"cases,omitempty"`
+	Dataset        string      `json:"dataset,omitempty"`
+	DatasetVersion int         `json:"dataset_version,omitempty"`
+	Assertions     []Assertion `json:"assertions,omitempty"`
+}
+
+type TestSuiteResponse struct {
+	ID             uuid.UUID   `json:"id"`
+	AccountID      uuid.UUID   `json:"account_id"`
+	Name           string      `json:"name"`
+	Description    string      `json:"description,omitempty"`
+	Prompt         string      `json:"prompt,omitempty"`
+	Flow           string      `json:"flow,omitempty"`
+	Cases          []TestCase  `json:"cases"`
+	Dataset        string      `json:"dataset,omitempty"`
+	DatasetVersion int         `json:"dataset_version,omitempty"`
+	Assertions     []Assertion `json:"assertions,omitempty"`
+}
+
+type TestSuitesResponse struct {
+	Suites []TestSuiteResponse `json:"suites"`
+}
+
+// RunTestSuiteRequest runs a suite against Model, which replaces the model of
+// every call. Prompt suites run Revision, the revision Label points at, or
+// the latest revision when both are empty. Dataset suites run
+// DatasetVersion, else the version the suite pins, else the latest.
+type RunTestSuiteRequest struct {
+	Model          string `json:"model"`
+	Revision       int    `json:"revision,omitempty"`
+	Label          string `json:"label,omitempty"`
+	DatasetVersion int    `json:"dataset_version,omitempty"`
+}
+
+// TokenUsage counts tokens. PromptTokens includes the cached portion, which
+// CacheReadTokens and CacheWriteTokens break down.
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+	CacheReadTokens  int `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int `json:"cache_write_tokens,omitempty"`
+}
+
+// AssertionResult is the outcome of one assertion. Score and Reasoning are
+// the judge's for judge assertions.
+type AssertionResult struct {
+	Type      string   `json:"type"`
+	Passed    bool     `json:"passed"`
+	Message   string   `json:"message,omitempty"`
+	Score     *float64 `json:"score,omitempty"`
+	Reasoning string   `json:"reasoning,omitempty"`
+}
+
+// TestCaseResultResponse is the outcome of one case. Error is set when the
+// case produced no output; its assertions were then not evaluated. Costs are
+// in dollars; JudgeUsage and JudgeCost are what judge models spent grading
+// the case, apart from the case's own Usage and Cost.
+type TestCaseResultResponse struct {
+	Position   int               `json:"position"`
+	Name       string            `json:"name"`
+	Passed     bool              `json:"passed"`
+	Output     any               `json:"output,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Assertions []AssertionResult `json:"assertions"`
+	FlowRunID  *uuid.UUID        `json:"flow_run_id,omitempty"`
+	Usage      TokenUsage        `json:"usage"`
+	Cost       string            `json:"cost"`
+	JudgeUsage TokenUsage        `json:"judge_usage"`
+	JudgeCost  string            `json:"judge_cost"`
+	LatencyMS  int64             `json:"latency_ms"`
+}
+
+// TestSuiteRunResponse is a run of a suite. Cases is only filled in when a
+// single run is requested.
+type TestSuiteRunResponse struct {
+	ID             uuid.UUID                `json:"id"`
+	SuiteID        uuid.UUID                `json:"suite_id"`
+	Model          string                   `json:"model"`
+	PromptRevision int                      `json:"prompt_revision,omitempty"`
+	DatasetVersion int                      `json:"dataset_version,omitempty"`
+	Status         string                   `json:"status"`
+	Error          string                   `json:"error,omitempty"`
+	Passed         int                      `json:"passed"`
+	Failed         int                      `json:"failed"`
+	Usage          TokenUsage               `json:"usage"`
+	Cost           string                   `json:"cost"`
+	JudgeUsage     TokenUsage               `json:"judge_usage"`
+	JudgeCost      string                   `json:"judge_cost"`
+	CreatedAt      time.Time                `json:"created_at"`
+	StartedAt      *time.Time               `json:"started_at,omitempty"`
+	FinishedAt     *time.Time               `json:"finished_at,omitempty"`
+	Cases          []TestCaseResultResponse `json:"cases,omitempty"`
+}
+
+// Finished reports whether the run has reached its final status.
+func (r *TestSuiteRunResponse) Finished() bool {
+	switch r.Status {
+	case "succeeded", "failed", "canceled":
+		return true
+	default:
+		return false
+	}
+}
+
+type TestSuiteRunsResponse struct {
+	Runs []TestSuiteRunResponse `json:"runs"`
+}
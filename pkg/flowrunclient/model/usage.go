@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ListUsageRequest narrows a usage listing to one flow or run. Limit zero
+// uses the server's default page size.
+type ListUsageRequest struct {
+	FlowID *uuid.UUID
+	RunID  *uuid.UUID
+	Offset int
+	Limit  int
+}
+
+// UsageRecord is the ledger entry of one LLM call. Prices and Cost are
+// decimal dollars; the prices are those the cost was computed with. Flow,
+// run and step are only set for calls made by flow runs, the prompt revision
+// only for those of steps rendering a stored prompt. Purpose is "flow",
+// "test_case" or "judge"; the calls of the last two, and those of flows run
+// by test suites, point at the suite run and case.
+type UsageRecord struct {
+	ID               uuid.UUID  `json:"id"`
+	AccountID        uuid.UUID  `json:"account_id"`
+	ProviderID       uuid.UUID  `json:"provider_id"`
+	ModelID          uuid.UUID  `json:"model_id"`
+	Model            string     `json:"model"`
+	FlowID           *uuid.UUID `json:"flow_id,omitempty"`
+	RunID            *uuid.UUID `json:"run_id,omitempty"`
+	StepRunID        *uuid.UUID `json:"step_run_id,omitempty"`
+	StepID           string     `json:"step_id,omitempty"`
+	PromptRevisionID *uuid.UUID `json:"prompt_revision_id,omitempty"`
+	Purpose          string     `json:"purpose"`
+	SuiteRunID       *uuid.UUID `json:"suite_run_id,omitempty"`
+	CasePosition     *int       `json:"case_position,omitempty"`
+	Usage            TokenUsage `json:"usage"`
+	InputPrice       string     `json:"input_price"`
+	OutputPrice      string     `json:"output_price"`
+	CachedPrice      string     `json:"cached_price"`
+	Cost             string     `json:"cost"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// UsageRecordsResponse is a page of usage records, oldest first, starting at
+// Offset.
+type UsageRecordsResponse struct {
+	Offset  int           `json:"offset"`
+	Records []UsageRecord `json:"records"`
+}